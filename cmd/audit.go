@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/models"
+)
+
+// openAuditLog returns the audit log configured in the audit section, or nil
+// when it is disabled. Entries name the identity of slackClient, which may be
+// nil for commands that do not talk to Slack or read the local store. A log
+// that cannot be opened fails the command rather than letting it run
+// unrecorded.
+func openAuditLog(cfg *config.Config, slackClient *api.SlackClient) (*audit.Log, error) {
+	if cfg.Audit.Disabled {
+		return nil, nil
+	}
+	log, err := audit.Open(cfg.Audit.Path)
+	if err != nil {
+		return nil, models.NewExportError(models.ErrorCategoryIO, "failed to open audit log", err)
+	}
+	if slackClient != nil {
+		log.SetIdentityFunc(func() (*models.ExportIdentity, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			identity, err := slackClient.GetIdentity(ctx)
+			if err != nil {
+				appLogger.Warn("failed to look up the identity for the audit log", "error", err)
+			}
+			return identity, err
+		})
+	}
+	return log, nil
+}
+
+// slackTime converts a Slack timestamp flag to the time recorded in the audit
+// log, or nil when it is unset or invalid
+func slackTime(ts string) *time.Time {
+	if ts == "" {
+		return nil
+	}
+	t, err := models.ParseSlackTimestamp(ts)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// recordAudit writes entry to log and returns err, the outcome of the audited
+// command. A failed write fails a command that succeeded, so no read of Slack
+// data goes unrecorded; next to a failed command it is only logged.
+func recordAudit(log *audit.Log, entry audit.Entry, err error) error {
+	auditErr := log.Record(entry)
+	if auditErr == nil {
+		return err
+	}
+	if err != nil {
+		appLogger.Warn("failed to write audit log", "path", log.Path(), "error", auditErr)
+		return err
+	}
+	return models.NewExportError(models.ErrorCategoryIO, "failed to write audit log", auditErr)
+}
@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Run repeatable backups defined in the configuration",
+	Long: `Run named backup profiles defined in the backups section of ~/.slacker.yaml.
+A profile describes which channels to export, the output format, where the files go,
+and how many exports to keep per channel.
+
+  backups:
+    nightly:
+      channels: [general, random]
+      destination: ./backups/nightly
+      format: json-compact
+      compression: gzip
+      incremental: true
+      keep_last: 14
+      keep_days: 365
+      concurrency: 4         # channels exported in parallel
+    legal:
+      channels: [contracts]
+      destination: /mnt/archive/legal
+      include_threads: true
+      output_template: "{{.Channel}}/{{.Date}}/{{.Channel}}-{{.Timestamp}}.json"
+
+Examples:
+  slacker backup list                     # Show configured profiles
+  slacker backup run nightly              # Execute the 'nightly' profile
+  slacker backup run nightly --parallel-channels 8 --rate-limit 120
+  slacker backup prune nightly --dry-run  # Show which exports retention would delete`,
+}
+
+// backupListCmd represents the backup list command
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured backup profiles",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listBackupProfiles(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// backupRunCmd represents the backup run command
+var backupRunCmd = &cobra.Command{
+	Use:   "run <profile>",
+	Short: "Execute a backup profile",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runBackupProfile(args[0]); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// backupPruneCmd represents the backup prune command
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune <profile>",
+	Short: "Apply a profile's retention policy to existing exports",
+	Long: `Delete the export files of a profile's channels that fall outside its
+retention policy (keep_last, keep_days). The newest export of each channel is
+always kept, together with the full export its incremental exports build on.
+Retention also runs automatically after every successful export.
+
+Examples:
+  slacker backup prune nightly --dry-run   # List what would be deleted
+  slacker backup prune nightly`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := pruneBackupProfile(args[0], backupPruneDryRun); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	backupPruneDryRun      bool
+	backupParallelChannels int
+	backupRateLimit        int
+)
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRunCmd)
+	backupCmd.AddCommand(backupPruneCmd)
+
+	backupPruneCmd.Flags().BoolVar(&backupPruneDryRun, "dry-run", false, "List the exports that would be deleted without deleting them")
+	backupRunCmd.Flags().IntVar(&backupParallelChannels, "parallel-channels", 0, "Channels exported in parallel (default: the profile's concurrency, export.concurrency or 1)")
+	backupRunCmd.Flags().IntVar(&backupRateLimit, "rate-limit", defaultRateLimit, "Slack API requests per minute shared by parallel channels (0 = only honor Retry-After)")
+
+	addNotifyFlags(backupRunCmd)
+	addTelemetryFlags(backupRunCmd)
+}
+
+func listBackupProfiles() error {
+	configManager := config.NewManager()
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Backups) == 0 {
+		printf("No backup profiles configured. Add a 'backups' section to your config file.\n")
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Backups))
+	for name := range cfg.Backups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	printf("Found %d backup profiles:\n\n", len(names))
+	for _, name := range names {
+		profile := cfg.Backups[name]
+		job := backupJobFromProfile(profile, cfg)
+
+		printf("💾 %s\n", name)
+		printf("   Channels: %s\n", strings.Join(job.Channels, ", "))
+		printf("   Destination: %s\n", job.OutputDir)
+		printf("   Format: %s", job.Format)
+		if job.Compression != "" && job.Compression != "none" {
+			printf(" (compressed with %s)", job.Compression)
+		}
+		fmt.Println()
+		if policy := job.Retention(); !policy.Empty() {
+			printf("   Retention: %s\n", policy)
+		}
+		if job.Concurrency > 1 {
+			printf("   Parallel channels: %d\n", job.Concurrency)
+		}
+		if profile.Schedule != "" {
+			printf("   Schedule: %s\n", profile.Schedule)
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func runBackupProfile(name string) error {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+
+	profile, err := configManager.GetBackupProfile(name)
+	if err != nil {
+		return err
+	}
+
+	job := backupJobFromProfile(*profile, cfg)
+	if backupParallelChannels < 0 {
+		return fmt.Errorf("--parallel-channels must not be negative")
+	}
+	if backupParallelChannels > 0 {
+		job.Concurrency = backupParallelChannels
+	}
+	if backupRateLimit < 0 {
+		return fmt.Errorf("--rate-limit must not be negative")
+	}
+
+	startTelemetry()
+	defer flushTelemetry()
+
+	slackClient := newSlackClient(token)
+	if job.Concurrency > 1 {
+		slackClient.SetRateLimit(backupRateLimit)
+	}
+	backupService := usecase.NewBackupService(slackClient, getVersion())
+	backupService.SetWorkspaceClient(slackClient)
+	backupService.SetBotClient(slackClient)
+	for _, hook := range exportHooks(cfg) {
+		backupService.AddHook(hook)
+	}
+	auditLog, err := openAuditLog(cfg, slackClient)
+	if err != nil {
+		return err
+	}
+	backupService.SetAuditLog(auditLog)
+	slackClient.SetLogger(appLogger)
+	backupService.SetLogger(appLogger)
+	notifyService, err := newNotifyService(slackClient)
+	if err != nil {
+		return err
+	}
+	if err := resolveWorkspace(context.Background(), slackClient, &job); err != nil {
+		return err
+	}
+
+	printf("💾 Running backup profile '%s' (%d channels)\n", name, len(job.Channels))
+	printf("📁 Destination: %s\n", job.OutputDir)
+	if job.Concurrency > 1 {
+		printf("👷 Parallel channels: %d\n", job.Concurrency)
+	}
+	fmt.Println()
+
+	// A bar per channel being exported and one for the run
+	bars := newProgressRenderer(len(job.Channels), verboseOutput)
+	if bars != nil {
+		backupService.SetChannelEvents(bars)
+		logger := bars.logger(appLogger)
+		slackClient.SetLogger(logger)
+		backupService.SetLogger(logger)
+	}
+
+	start := time.Now()
+	results, err := backupService.Run(job)
+	bars.Wait()
+	sendNotification(notifyService, backupNotifications(job, results, err))
+	if err != nil && len(results) == 0 {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+			printf("❌ #%s: %s\n", result.Channel, result.Error)
+			continue
+		}
+		printf("✅ #%s: %d messages, %s -> %s\n",
+			result.Channel, result.Messages, usecase.FormatFileSize(result.FileSize), result.OutputFile)
+		if result.Deleted > 0 || result.Edited > 0 {
+			printf("   ✏️  %d edited, %d deleted since the previous run\n", result.Edited, result.Deleted)
+		}
+		for _, removed := range result.Removed {
+			printf("   🗑️  removed %s\n", removed)
+		}
+	}
+
+	printf("\n⏱️  Duration: %s\n", time.Since(start).Round(time.Millisecond))
+	printAPIUsage(slackClient.Usage())
+
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return backupFailure(results, failed)
+	}
+
+	return nil
+}
+
+func pruneBackupProfile(name string, dryRun bool) error {
+	configManager := config.NewManager()
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+	profile, err := configManager.GetBackupProfile(name)
+	if err != nil {
+		return err
+	}
+
+	job := backupJobFromProfile(*profile, cfg)
+	policy := job.Retention()
+	if policy.Empty() {
+		printf("Profile '%s' has no retention policy. Set keep_last or keep_days.\n", name)
+		return nil
+	}
+	pruned, err := usecase.PruneBackup(job, time.Now(), dryRun)
+
+	action := "🗑️  removed"
+	if dryRun {
+		action = "🗑️  would remove"
+	}
+	printf("💾 Profile '%s' (%s)\n", name, policy)
+	total := 0
+	for _, channel := range job.Channels {
+		for _, file := range pruned[strings.TrimPrefix(channel, "#")] {
+			printf("%s %s\n", action, file)
+			total++
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case total == 0:
+		printf("✅ Nothing to prune\n")
+	case dryRun:
+		printf("\n%d exports would be removed. Run without --dry-run to delete them.\n", total)
+	default:
+		printf("\n✅ Removed %d exports\n", total)
+	}
+	return nil
+}
+
+// backupJobFromProfile converts a configured profile into a backup job,
+// falling back to the export defaults for unset values
+func backupJobFromProfile(profile config.BackupProfile, cfg *config.Config) usecase.BackupJob {
+	job := usecase.BackupJob{
+		Channels:       profile.Channels,
+		OutputDir:      profile.Destination,
+		Format:         profile.Format,
+		Compression:    profile.Compression,
+		IncludeThreads: cfg.Export.IncludeThreads,
+		Incremental:    profile.Incremental,
+		Keep:           profile.Keep,
+		KeepDays:       profile.KeepDays,
+		OutputTemplate: profile.OutputTemplate,
+		SubtypePolicy:  cfg.Export.SubtypePolicy,
+		PageSize:       apiConfig.PageSize,
+		ThreadDelay:    apiConfig.ThreadDelay,
+		Location:       displayLocation,
+		Concurrency:    cfg.Export.Concurrency,
+	}
+
+	if profile.KeepLast > 0 {
+		job.Keep = profile.KeepLast
+	}
+	if profile.IncludeThreads != nil {
+		job.IncludeThreads = *profile.IncludeThreads
+	}
+	if profile.Concurrency > 0 {
+		job.Concurrency = profile.Concurrency
+	}
+	if job.OutputDir == "" {
+		job.OutputDir = cfg.Export.DefaultOutputDir
+	}
+	if job.Format == "" {
+		job.Format = "json"
+	}
+
+	return job
+}
+
+// resolveWorkspace looks up the workspace name when the job's output template
+// uses it
+func resolveWorkspace(ctx context.Context, client *api.SlackClient, job *usecase.BackupJob) error {
+	if !usecase.TemplateUsesWorkspace(job.OutputTemplate) {
+		return nil
+	}
+	auth, err := client.TestAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to look up workspace name: %w", err)
+	}
+	job.Workspace = auth.Team
+	return nil
+}
+
+// backupNotifications summarizes a backup run. When the run failed before any
+// channel was exported, every channel of the job is reported with the error.
+func backupNotifications(job usecase.BackupJob, results []usecase.BackupChannelResult, err error) []usecase.ExportNotification {
+	if err != nil && len(results) == 0 {
+		notifications := make([]usecase.ExportNotification, 0, len(job.Channels))
+		for _, channel := range job.Channels {
+			notifications = append(notifications, usecase.ExportNotification{Channel: channel, Error: err.Error()})
+		}
+		return notifications
+	}
+	return usecase.NotificationsFromBackup(results)
+}
+
+// backupFailure categorizes a backup run with failed channels: partial when
+// some channels succeeded, otherwise the shared category of the failures
+func backupFailure(results []usecase.BackupChannelResult, failed int) error {
+	message := fmt.Sprintf("%d of %d channels failed", failed, len(results))
+	if failed < len(results) {
+		return models.NewExportError(models.ErrorCategoryPartialExport, message, nil)
+	}
+
+	category := results[0].ErrorCategory
+	for _, result := range results[1:] {
+		if result.ErrorCategory != category {
+			category = models.ErrorCategoryUnknown
+			break
+		}
+	}
+	return models.NewExportError(category, message, nil)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+)
+
+func TestBackupJobFromProfile(t *testing.T) {
+	cfg := &config.Config{
+		Export: config.ExportConfig{
+			DefaultOutputDir: "./exports",
+			IncludeThreads:   true,
+		},
+	}
+
+	// Unset values fall back to export defaults
+	job := backupJobFromProfile(config.BackupProfile{Channels: []string{"general"}}, cfg)
+	if job.OutputDir != "./exports" {
+		t.Errorf("Expected default output dir './exports', got %s", job.OutputDir)
+	}
+	if job.Format != "json" {
+		t.Errorf("Expected default format 'json', got %s", job.Format)
+	}
+	if !job.IncludeThreads {
+		t.Error("Expected threads to be included by default")
+	}
+
+	// Profile values take precedence
+	noThreads := false
+	profile := config.BackupProfile{
+		Channels:       []string{"general", "random"},
+		Destination:    "/backups",
+		Format:         "json-compact",
+		Compression:    "gzip",
+		IncludeThreads: &noThreads,
+		Incremental:    true,
+		Keep:           5,
+	}
+	job = backupJobFromProfile(profile, cfg)
+	if job.OutputDir != "/backups" || job.Format != "json-compact" || job.Compression != "gzip" {
+		t.Errorf("Expected profile output settings, got %+v", job)
+	}
+	if job.IncludeThreads {
+		t.Error("Expected profile to disable threads")
+	}
+	if !job.Incremental || job.Keep != 5 {
+		t.Errorf("Expected incremental with keep 5, got %+v", job)
+	}
+	profile.KeepLast = 12
+	profile.KeepDays = 365
+	if job = backupJobFromProfile(profile, cfg); job.Retention() != (usecase.RetentionPolicy{KeepLast: 12, KeepDays: 365}) {
+		t.Errorf("Expected keep_last to override keep, got %+v", job.Retention())
+	}
+	if len(job.Channels) != 2 {
+		t.Errorf("Expected 2 channels, got %d", len(job.Channels))
+	}
+}
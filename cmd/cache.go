@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/itcaat/slacker/internal/cache"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the channel and user list cache",
+	Long: `Channel and user lists are cached in ~/.slacker/cache so commands and the TUI
+start quickly on large workspaces. Lifetimes are set with cache.channels_ttl
+(default 15m) and cache.users_ttl (default 1h); use --no-cache on any command
+to bypass the cache.
+
+Examples:
+  slacker cache clear              # Remove all cached lists
+  slacker cache path               # Print the cache directory`,
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached channel and user lists",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := clearCache(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// cachePathCmd represents the cache path command
+var cachePathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the cache directory",
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, err := cache.DefaultDir()
+		if err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+		fmt.Println(dir)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePathCmd)
+}
+
+func clearCache() error {
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return err
+	}
+
+	removed, err := cache.New(dir).Clear()
+	if err != nil {
+		return err
+	}
+	printf("🧹 Removed %d cache entries from %s\n", removed, dir)
+	return nil
+}
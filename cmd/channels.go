@@ -9,7 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/itcaat/slacker/internal/api"
 	"github.com/itcaat/slacker/internal/config"
 	"github.com/itcaat/slacker/models"
 	"github.com/spf13/cobra"
@@ -26,7 +25,7 @@ Examples:
   slacker channels list    # List all available channels
   slacker channels view    # Interactive channel browser (TUI)`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("channels called - TUI interface will be implemented here")
+		printf("channels called - TUI interface will be implemented here\n")
 	},
 }
 
@@ -52,8 +51,8 @@ Examples:
   slacker channels list -v                 # Show detailed information`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := listChannels(cmd); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
 		}
 	},
 }
@@ -65,7 +64,7 @@ var channelsViewCmd = &cobra.Command{
 	Long: `Launch the interactive TUI for browsing channels and viewing messages.
 This provides a full-screen interface for navigating channels and reading conversations.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("channels view called - will implement Bubble Tea TUI")
+		printf("channels view called - will implement Bubble Tea TUI\n")
 	},
 }
 
@@ -79,7 +78,8 @@ func init() {
 	channelsListCmd.Flags().BoolP("private-only", "p", false, "Show only private channels")
 	channelsListCmd.Flags().BoolP("public-only", "u", false, "Show only public channels")
 	channelsListCmd.Flags().StringP("format", "f", "table", "Output format: table, json, csv")
-	channelsListCmd.Flags().BoolP("verbose", "v", false, "Show detailed channel information")
+
+	registerValueCompletion(channelsListCmd, "format", "table", "json", "csv")
 }
 
 func listChannels(cmd *cobra.Command) error {
@@ -88,23 +88,25 @@ func listChannels(cmd *cobra.Command) error {
 	privateOnly, _ := cmd.Flags().GetBool("private-only")
 	publicOnly, _ := cmd.Flags().GetBool("public-only")
 	format, _ := cmd.Flags().GetString("format")
-	verbose, _ := cmd.Flags().GetBool("verbose")
+	verbose := verboseOutput
 
 	// Get token from config
 	configManager := config.NewManager()
-	token, err := configManager.GetToken()
+	token, err := selectToken(configManager, models.TokenTypeUser)
 	if err != nil {
-		return fmt.Errorf("authentication required. Run 'slacker auth <token>' first: %w", err)
+		return err
 	}
 
 	// Create Slack client
-	client := api.NewSlackClient(token, false)
+	client := newSlackClient(token)
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), apiTimeout(30*time.Second))
 	defer cancel()
 
-	fmt.Println("🔄 Fetching channels...")
+	// JSON output keeps stdout for the channel list only
+	messagesOnStderr = format == "json"
+	printf("🔄 Fetching channels...\n")
 
 	// Get channels
 	channels, err := client.GetChannels(ctx)
@@ -115,8 +117,8 @@ func listChannels(cmd *cobra.Command) error {
 	// Filter channels based on flags
 	filteredChannels := filterChannels(channels, includeArchived, privateOnly, publicOnly)
 
-	if len(filteredChannels) == 0 {
-		fmt.Println("No channels found matching the criteria.")
+	if len(filteredChannels) == 0 && format != "json" {
+		printf("No channels found matching the criteria.\n")
 		return nil
 	}
 
@@ -159,6 +161,9 @@ func filterChannels(channels []models.Channel, includeArchived, privateOnly, pub
 
 // outputChannelsJSON outputs channels in JSON format
 func outputChannelsJSON(channels []models.Channel) error {
+	if channels == nil {
+		channels = []models.Channel{}
+	}
 	data, err := json.MarshalIndent(channels, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal channels to JSON: %w", err)
@@ -223,7 +228,7 @@ func outputChannelsCSV(channels []models.Channel, verbose bool) error {
 
 // outputChannelsTable outputs channels in table format
 func outputChannelsTable(channels []models.Channel, verbose bool) error {
-	fmt.Printf("Found %d channels:\n\n", len(channels))
+	printf("Found %d channels:\n\n", len(channels))
 
 	if verbose {
 		// Detailed table format
@@ -233,17 +238,20 @@ func outputChannelsTable(channels []models.Channel, verbose bool) error {
 			if channel.IsArchived {
 				status = " (archived)"
 			}
+			if channel.IsExtShared {
+				status += " 🌐 shared externally"
+			}
 
-			fmt.Printf("📢 #%-20s %s%s\n", channel.Name, channelType, status)
-			fmt.Printf("   ID: %s\n", channel.ID)
-			fmt.Printf("   Members: %d\n", channel.NumMembers)
+			printf("📢 #%-20s %s%s\n", channel.Name, channelType, status)
+			printf("   ID: %s\n", channel.ID)
+			printf("   Members: %d\n", channel.NumMembers)
 			if channel.Topic.Value != "" {
-				fmt.Printf("   Topic: %s\n", channel.Topic.Value)
+				printf("   Topic: %s\n", channel.Topic.Value)
 			}
 			if channel.Purpose.Value != "" {
-				fmt.Printf("   Purpose: %s\n", channel.Purpose.Value)
+				printf("   Purpose: %s\n", channel.Purpose.Value)
 			}
-			fmt.Printf("   Created: %s\n", time.Unix(channel.Created, 0).Format("2006-01-02 15:04:05"))
+			printf("   Created: %s\n", time.Unix(channel.Created, 0).In(displayLocation).Format("2006-01-02 15:04:05"))
 			fmt.Println()
 		}
 	} else {
@@ -254,8 +262,11 @@ func outputChannelsTable(channels []models.Channel, verbose bool) error {
 			if channel.IsArchived {
 				status = " (archived)"
 			}
+			if channel.IsExtShared {
+				status += " 🌐 shared externally"
+			}
 
-			fmt.Printf("📢 #%-20s %-8s %3d members%s\n", channel.Name, channelType, channel.NumMembers, status)
+			printf("📢 #%-20s %-8s %3d members%s\n", channel.Name, channelType, channel.NumMembers, status)
 		}
 	}
 
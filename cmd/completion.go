@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/models"
+)
+
+// completionCmd represents the completion command
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the shell completion script",
+	Long: `Generate a completion script for your shell. Besides commands and flags it
+completes channel names for --channel and --exclude from the cached channel list
+(refreshed by any command that lists channels, e.g. 'slacker channels list'), and
+the values of --format, --compress and similar flags.
+
+Bash (requires bash-completion):
+  source <(slacker completion bash)
+  # permanently, Linux:
+  slacker completion bash > /etc/bash_completion.d/slacker
+  # permanently, macOS:
+  slacker completion bash > $(brew --prefix)/etc/bash_completion.d/slacker
+
+Zsh:
+  slacker completion zsh > "${fpath[1]}/_slacker"
+  # completion must be enabled once with: autoload -U compinit; compinit
+
+Fish:
+  slacker completion fish > ~/.config/fish/completions/slacker.fish
+
+PowerShell:
+  slacker completion powershell | Out-String | Invoke-Expression
+  # permanently: add the line above to your $PROFILE`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := writeCompletion(cmd.Root(), args[0]); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+
+	registerValueCompletion(rootCmd, "log-level", "debug", "info", "warn", "error")
+	registerValueCompletion(rootCmd, "log-format", "text", "json")
+	registerValueCompletion(rootCmd, "token-type", "auto", "bot", "user")
+}
+
+// writeCompletion writes the completion script for shell to stdout
+func writeCompletion(root *cobra.Command, shell string) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		return root.GenZshCompletion(os.Stdout)
+	case "fish":
+		return root.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+	return fmt.Errorf("unsupported shell '%s'. Valid shells: bash, zsh, fish, powershell", shell)
+}
+
+// registerChannelCompletion completes the named flags of cmd with channel
+// names
+func registerChannelCompletion(cmd *cobra.Command, flags ...string) {
+	for _, flag := range flags {
+		_ = cmd.RegisterFlagCompletionFunc(flag, completeChannels)
+	}
+}
+
+// registerValueCompletion completes a flag of cmd with a fixed set of values
+func registerValueCompletion(cmd *cobra.Command, flag string, values ...string) {
+	_ = cmd.RegisterFlagCompletionFunc(flag, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// completeChannels completes channel names from the cached channel lists of
+// the configured tokens. It never calls Slack, so completion stays instant
+// and works offline; nothing is offered until a command has cached the list.
+func completeChannels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	configManager := config.NewManager()
+	prefix := strings.TrimPrefix(toComplete, "#")
+
+	seen := make(map[string]bool)
+	var completions []string
+	for _, preferred := range []models.TokenType{models.TokenTypeBot, models.TokenTypeUser} {
+		token, _, err := configManager.SelectToken(preferred, preferred)
+		if err != nil {
+			continue
+		}
+		channels, ok := newSlackClient(token).CachedChannels()
+		if !ok {
+			continue
+		}
+		for _, channel := range channels {
+			if seen[channel.Name] || channel.IsArchived || !strings.HasPrefix(channel.Name, prefix) {
+				continue
+			}
+			seen[channel.Name] = true
+			completions = append(completions, channel.Name+"\t"+describeChannel(channel))
+		}
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// describeChannel is the completion description of a channel
+func describeChannel(channel models.Channel) string {
+	kind := "public"
+	if channel.IsPrivate {
+		kind = "private"
+	}
+	if channel.NumMembers > 0 {
+		return fmt.Sprintf("%s, %d members", kind, channel.NumMembers)
+	}
+	return kind
+}
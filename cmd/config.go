@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View and change settings in ~/.slacker.yaml",
+	Long: `Manage slacker settings without hand-editing YAML. Values are validated
+before they are written.
+
+Backup profile fields are addressed as backups.<profile>.<field>. Tokens are
+managed with 'slacker auth' instead.
+
+Examples:
+  slacker config list                                  # Show all settings
+  slacker config get export.default_output_dir
+  slacker config set export.default_format json-compact
+  slacker config set ui.theme light
+  slacker config set backups.nightly.channels general,random
+  slacker config edit                                  # Open the file in $EDITOR
+  slacker config doctor                                # Report misconfiguration`,
+}
+
+// configGetCmd represents the config get command
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		value, err := config.NewManager().GetSetting(args[0])
+		if err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+		fmt.Println(value)
+	},
+}
+
+// configSetCmd represents the config set command
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Validate and store a setting",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.NewManager().SetSetting(args[0], args[1]); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+		printf("✅ %s = %s\n", args[0], args[1])
+	},
+}
+
+// configListCmd represents the config list command
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings and their current values",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := listSettings(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// configEditCmd represents the config edit command
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the configuration file in $EDITOR",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := editConfig(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// configPathCmd represents the config path command
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the configuration file path",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(config.NewManager().GetConfigPath())
+	},
+}
+
+// configDoctorCmd represents the config doctor command
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration for problems",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigDoctor(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configDoctorCmd)
+}
+
+func listSettings() error {
+	values, err := config.NewManager().ListSettings()
+	if err != nil {
+		return err
+	}
+
+	width := 0
+	for _, value := range values {
+		if len(value.Key) > width {
+			width = len(value.Key)
+		}
+	}
+	for _, value := range values {
+		display := value.Value
+		if display == "" {
+			display = "-"
+		}
+		printf("%-*s  %s\n", width, value.Key, display)
+	}
+	return nil
+}
+
+func editConfig() error {
+	configManager := config.NewManager()
+	path := configManager.GetConfigPath()
+
+	// Create an empty file so the editor opens the right path
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create config directory: %w", err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			return fmt.Errorf("failed to create config file: %w", err)
+		}
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	editorCmd := exec.Command(editor, path)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+
+	// Check the edited file so mistakes surface immediately
+	return runConfigDoctor()
+}
+
+func runConfigDoctor() error {
+	configManager := config.NewManager()
+	diagnostics := configManager.Doctor()
+	printf("🩺 Checking %s\n", configManager.GetConfigPath())
+
+	if len(diagnostics) == 0 {
+		printf("✅ No problems found\n")
+		return nil
+	}
+
+	fatal := 0
+	for _, diagnostic := range diagnostics {
+		icon := "⚠️ "
+		if diagnostic.Fatal {
+			icon = "❌"
+			fatal++
+		}
+		printf("%s %s: %s\n", icon, diagnostic.Key, diagnostic.Message)
+	}
+
+	if fatal > 0 {
+		return fmt.Errorf("found %d configuration problem(s)", fatal)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// convertCmd represents the convert command
+var convertCmd = &cobra.Command{
+	Use:   "convert <export|->",
+	Short: "Convert an export into another format",
+	Long: `Read an export written by 'slacker export' (optionally gzip-compressed) and
+write it in another format. "-" reads the export from stdin, and without
+--output the result is written to stdout, so convert can sit in a pipeline.
+
+The export is checked against its integrity footer first, because the
+converted output gets a new footer: a modified or truncated export is refused
+unless --force is given.
+
+Examples:
+  slacker convert general-export.json --format pdf --output general.pdf
+  slacker export --channel general --output - | slacker convert - --format llm-jsonl --text-only
+  zcat archive/general.json.gz | slacker convert - --format json-compact | jq '.messages | length'`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConvert(cmd, args[0]); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	convertFormat    string
+	convertOutput    string
+	convertCompress  string
+	convertChunkSize int
+	convertTextOnly  bool
+	convertForce     bool
+)
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().StringVarP(&convertFormat, "format", "f", "json", "Output format: json, json-pretty, json-compact, pdf, llm-jsonl")
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", usecase.StdoutOutput, "Output file path or URL, or - for stdout")
+	convertCmd.Flags().StringVar(&convertCompress, "compress", "", "Compression: none, gzip")
+	convertCmd.Flags().IntVar(&convertChunkSize, "chunk-tokens", 0, "Approximate token limit per llm-jsonl chunk (default 1000)")
+	convertCmd.Flags().BoolVar(&convertTextOnly, "text-only", false, "Leave files, attachments and reactions out of llm-jsonl chunks")
+	convertCmd.Flags().BoolVar(&convertForce, "force", false, "Convert an export that does not match its integrity footer")
+
+	registerValueCompletion(convertCmd, "format", "json", "json-pretty", "json-compact", "pdf", usecase.FormatLLMJSONL)
+	registerValueCompletion(convertCmd, "compress", "none", "gzip")
+}
+
+func runConvert(cmd *cobra.Command, input string) error {
+	switch convertFormat {
+	case "json", "json-pretty", "json-compact", "pdf", usecase.FormatLLMJSONL:
+	default:
+		return fmt.Errorf("invalid format '%s'. Valid formats: json, json-pretty, json-compact, pdf, llm-jsonl", convertFormat)
+	}
+	if convertCompress != "" && convertCompress != "none" && convertCompress != "gzip" {
+		return fmt.Errorf("invalid compression '%s'. Valid compressions: none, gzip", convertCompress)
+	}
+	if (convertChunkSize != 0 || convertTextOnly) && convertFormat != usecase.FormatLLMJSONL {
+		return fmt.Errorf("--chunk-tokens and --text-only require --format llm-jsonl")
+	}
+	if convertChunkSize < 0 {
+		return fmt.Errorf("--chunk-tokens must be positive")
+	}
+
+	cfg, err := config.NewManager().Load()
+	if err != nil {
+		return err
+	}
+	auditLog, err := openAuditLog(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	export, check, err := usecase.ReadVerifiedExport(input)
+	if err != nil {
+		return err
+	}
+	// Converting would sign the modified messages with a fresh footer
+	if check.Status == usecase.IntegrityStatusMismatch {
+		if !convertForce {
+			return fmt.Errorf("%w; pass --force to convert it anyway", integrityMismatch(check))
+		}
+		eprintf("⚠️  %v; converting anyway\n", integrityMismatch(check))
+	}
+
+	service := usecase.NewExportService(nil, getVersion())
+	service.SetLogger(appLogger)
+	outputFile, size, err := service.ConvertExport(cmd.Context(), export, models.ExportOptions{
+		OutputFile:  convertOutput,
+		Format:      convertFormat,
+		Compression: convertCompress,
+		ChunkTokens: convertChunkSize,
+		TextOnly:    convertTextOnly,
+	})
+	entry := audit.Entry{
+		Action:    audit.ActionConvert,
+		ChannelID: export.Channel.ID,
+		Channel:   export.Channel.Name,
+		Output:    outputFile,
+		Messages:  len(export.Messages),
+		Success:   err == nil,
+	}
+	if toStdout(convertOutput) {
+		entry.Output = "stdout"
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		return recordAudit(auditLog, entry, models.NewExportError(models.ErrorCategoryIO, "failed to write converted export", err))
+	}
+	if err := recordAudit(auditLog, entry, nil); err != nil {
+		return err
+	}
+	if outputFile != usecase.StdoutOutput {
+		printf("🔄 Converted #%s (%d messages) to %s (%s)\n", export.Channel.Name, len(export.Messages), outputFile, usecase.FormatFileSize(size))
+	}
+	return nil
+}
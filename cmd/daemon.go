@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/schedule"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run incremental channel exports on a cron schedule",
+	Long: `Run slacker as a long-lived process that exports the configured channels on a
+cron schedule. Each run only exports messages posted since the previous successful run
+of that channel, old export files are rotated, and a summary is logged.
+
+Settings can be given as flags or in the daemon section of ~/.slacker.yaml:
+
+  daemon:
+    schedule: "0 2 * * *"
+    channels: [general, random]
+    output_dir: ./backups
+    format: json
+    compression: gzip
+    keep: 7
+    keep_days: 90
+
+Flags override values from the config file, and those of --profile.
+
+Examples:
+  slacker daemon --schedule "0 2 * * *" --channel general --channel random
+  slacker daemon --schedule @hourly --channel general --keep 24 --output-dir ./backups
+  slacker daemon --profile nightly              # Run a backup profile on its configured schedule
+  slacker daemon --run-now                      # Run once immediately, then follow the schedule`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDaemon(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().String("profile", "", "Backup profile to run on the schedule (see 'slacker backup')")
+	daemonCmd.Flags().String("schedule", "", "Cron expression (minute hour day-of-month month day-of-week) or @daily/@hourly")
+	daemonCmd.Flags().StringSliceP("channel", "c", nil, "Channel name to export (repeatable)")
+	daemonCmd.Flags().StringP("output-dir", "o", "", "Directory for export files (default: export.default_output_dir)")
+	daemonCmd.Flags().StringP("format", "f", "", "Output format: json, json-pretty, json-compact, pdf (default: json)")
+	daemonCmd.Flags().String("compress", "", "Compression: none, gzip")
+	daemonCmd.Flags().Int("keep", 0, "Number of export files to keep per channel (0 = keep all)")
+	daemonCmd.Flags().Int("keep-days", 0, "Remove export files older than this many days (0 = keep all)")
+	daemonCmd.Flags().Bool("full", false, "Export full history on every run instead of incrementally")
+	daemonCmd.Flags().Bool("run-now", false, "Run an export immediately before waiting for the schedule")
+	daemonCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. :9090")
+	addNotifyFlags(daemonCmd)
+	addTelemetryFlags(daemonCmd)
+
+	registerChannelCompletion(daemonCmd, "channel")
+	registerValueCompletion(daemonCmd, "format", "json", "json-pretty", "json-compact", "pdf")
+	registerValueCompletion(daemonCmd, "compress", "none", "gzip")
+}
+
+func runDaemon(cmd *cobra.Command) error {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+
+	// Flags override the backup profile, or the daemon section without one
+	cronSpec := cfg.Daemon.Schedule
+	var job usecase.BackupJob
+	profileName, _ := cmd.Flags().GetString("profile")
+	if profileName != "" {
+		profile, err := configManager.GetBackupProfile(profileName)
+		if err != nil {
+			return err
+		}
+		cronSpec = profile.Schedule
+		job = backupJobFromProfile(*profile, cfg)
+	} else {
+		job = usecase.BackupJob{
+			Channels:       cfg.Daemon.Channels,
+			OutputDir:      cfg.Daemon.OutputDir,
+			Format:         cfg.Daemon.Format,
+			Compression:    cfg.Daemon.Compression,
+			IncludeThreads: cfg.Export.IncludeThreads,
+			Incremental:    true,
+			Keep:           cfg.Daemon.Keep,
+			KeepDays:       cfg.Daemon.KeepDays,
+			SubtypePolicy:  cfg.Export.SubtypePolicy,
+			PageSize:       apiConfig.PageSize,
+			ThreadDelay:    apiConfig.ThreadDelay,
+			Location:       displayLocation,
+			Concurrency:    cfg.Export.Concurrency,
+		}
+	}
+	if cmd.Flags().Changed("schedule") {
+		cronSpec, _ = cmd.Flags().GetString("schedule")
+	}
+	applyDaemonFlags(cmd, &job)
+	if job.OutputDir == "" {
+		job.OutputDir = cfg.Export.DefaultOutputDir
+	}
+	runNow, _ := cmd.Flags().GetBool("run-now")
+
+	if cronSpec == "" {
+		return fmt.Errorf("no schedule configured. Use --schedule or set daemon.schedule")
+	}
+	if len(job.Channels) == 0 && profileName == "" {
+		return fmt.Errorf("no channels configured. Use --channel or set daemon.channels")
+	}
+
+	sched, err := schedule.Parse(cronSpec)
+	if err != nil {
+		return err
+	}
+
+	startTelemetry()
+	if metricsAddr, _ := cmd.Flags().GetString("metrics-addr"); metricsAddr != "" {
+		serveMetrics(metricsAddr)
+	}
+
+	slackClient := newSlackClient(token)
+	if job.Concurrency > 1 {
+		slackClient.SetRateLimit(defaultRateLimit)
+	}
+	backupService := usecase.NewBackupService(slackClient, getVersion())
+	backupService.SetWorkspaceClient(slackClient)
+	backupService.SetBotClient(slackClient)
+	for _, hook := range exportHooks(cfg) {
+		backupService.AddHook(hook)
+	}
+	auditLog, err := openAuditLog(cfg, slackClient)
+	if err != nil {
+		return err
+	}
+	backupService.SetAuditLog(auditLog)
+	notifyService, err := newNotifyService(slackClient)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := resolveWorkspace(ctx, slackClient, &job); err != nil {
+		return err
+	}
+
+	logger := appLogger.With("component", "daemon")
+	slackClient.SetLogger(logger)
+	backupService.SetLogger(logger)
+	logger.Info("daemon started", "schedule", cronSpec, "channels", job.Channels, "output", job.OutputDir, "keep", job.Keep, "keep_days", job.KeepDays, "parallel_channels", job.Concurrency)
+
+	if runNow {
+		runScheduledBackup(logger, backupService, notifyService, job)
+	}
+
+	for {
+		next := sched.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("schedule '%s' never fires", cronSpec)
+		}
+		logger.Info("waiting for next run", "next_run", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Info("daemon stopped")
+			return nil
+		case <-timer.C:
+			runScheduledBackup(logger, backupService, notifyService, job)
+		}
+	}
+}
+
+// runScheduledBackup executes a backup job, logs a per-channel summary and
+// sends it to the configured notification destinations
+func runScheduledBackup(logger *slog.Logger, backupService *usecase.BackupService, notifyService *usecase.NotifyService, job usecase.BackupJob) {
+	start := time.Now()
+	logger.Info("run started", "channels", len(job.Channels))
+
+	results, err := backupService.Run(job)
+	if err != nil {
+		logger.Error("run failed", "error", err)
+	}
+
+	failed := 0
+	totalMessages := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+			logger.Error("channel export failed", "channel", result.Channel, "error", result.Error)
+			continue
+		}
+		totalMessages += result.Messages
+		logger.Info("channel exported", "channel", result.Channel, "messages", result.Messages,
+			"size", usecase.FormatFileSize(result.FileSize), "duration", result.Duration.Round(time.Millisecond), "output", result.OutputFile)
+		if result.Deleted > 0 || result.Edited > 0 {
+			logger.Info("messages changed since previous run", "channel", result.Channel, "edited", result.Edited, "deleted", result.Deleted)
+		}
+		for _, removed := range result.Removed {
+			logger.Info("rotated out export", "channel", result.Channel, "file", removed)
+		}
+	}
+
+	logger.Info("run finished", "duration", time.Since(start).Round(time.Millisecond),
+		"exported", len(results)-failed, "failed", failed, "messages", totalMessages)
+
+	if notifyService.Enabled() {
+		if err := notifyService.Notify(context.Background(), backupNotifications(job, results, err)); err != nil {
+			logger.Warn("notification failed", "error", err)
+		}
+	}
+	flushTelemetry()
+}
+
+// applyDaemonFlags sets the export flags given on the command line on job
+func applyDaemonFlags(cmd *cobra.Command, job *usecase.BackupJob) {
+	flags := cmd.Flags()
+	if flags.Changed("channel") {
+		job.Channels, _ = flags.GetStringSlice("channel")
+	}
+	if flags.Changed("output-dir") {
+		job.OutputDir, _ = flags.GetString("output-dir")
+	}
+	if flags.Changed("format") {
+		job.Format, _ = flags.GetString("format")
+	}
+	if flags.Changed("compress") {
+		job.Compression, _ = flags.GetString("compress")
+	}
+	if flags.Changed("keep") {
+		job.Keep, _ = flags.GetInt("keep")
+	}
+	if flags.Changed("keep-days") {
+		job.KeepDays, _ = flags.GetInt("keep-days")
+	}
+	if flags.Changed("full") {
+		full, _ := flags.GetBool("full")
+		job.Incremental = !full
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-export> <new-export>",
+	Short: "Compare two exports of a channel",
+	Long: `Compare two export files and report messages that were added, removed or
+edited, users that joined, left or changed their profile, and how the export
+statistics changed. Thread replies are compared like top-level messages.
+Gzip-compressed exports and zip archives holding one export are read
+directly. When either export covers a date range, as incremental exports
+do, only messages in the range both cover are compared.
+
+Examples:
+  slacker diff general-export-20240101.json general-export-20240102.json
+  slacker diff old.json.gz new.json.gz --json | jq '.removed'
+  slacker diff old.json new.json --exit-code   # Exit 9 when the exports differ`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDiff(args[0], args[1]); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	diffJSON     bool
+	diffExitCode bool
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Print the differences as JSON to stdout")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "Exit with status 9 when the exports differ")
+}
+
+func runDiff(oldPath, newPath string) error {
+	cfg, err := config.NewManager().Load()
+	if err != nil {
+		return err
+	}
+	auditLog, err := openAuditLog(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	oldExport, err := usecase.ReadExportFile(oldPath)
+	if err != nil {
+		return err
+	}
+	newExport, err := usecase.ReadExportFile(newPath)
+	if err != nil {
+		return err
+	}
+
+	if oldExport.Channel.ID != "" && newExport.Channel.ID != "" && oldExport.Channel.ID != newExport.Channel.ID {
+		eprintf("⚠️  Comparing exports of different channels (#%s and #%s)\n", oldExport.Channel.Name, newExport.Channel.Name)
+	}
+
+	diff := usecase.DiffExports(oldExport, newExport)
+	diff.OldFile = oldPath
+	diff.NewFile = newPath
+
+	// The diff prints messages of both exports
+	if err := recordAudit(auditLog, audit.Entry{
+		Action:    audit.ActionDiff,
+		ChannelID: newExport.Channel.ID,
+		Channel:   newExport.Channel.Name,
+		Output:    "stdout",
+		Messages:  len(diff.Added) + len(diff.Removed) + len(diff.Edited),
+		Success:   true,
+	}, nil); err != nil {
+		return err
+	}
+
+	if diffJSON {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printDiff(diff)
+	}
+
+	if diffExitCode && !diff.Empty() {
+		os.Exit(models.ExitCodeDiffers)
+	}
+	return nil
+}
+
+// printDiff writes a human-readable summary of an export diff
+func printDiff(diff *usecase.ExportDiff) {
+	printf("📊 #%s: %s → %s\n", diff.Channel, diff.OldFile, diff.NewFile)
+	if diff.Window != nil {
+		printf("🔎 Comparing messages from %s to %s, covered by both exports\n", diffBound(diff.Window.From), diffBound(diff.Window.To))
+	}
+	printf("\n")
+
+	if diff.Empty() {
+		printf("✅ No differences in messages or users\n")
+	}
+
+	if len(diff.Added) > 0 {
+		printf("➕ %d added:\n", len(diff.Added))
+		for _, msg := range diff.Added {
+			printf("   %s %s: %s\n", msg.ID, msg.User, diffSnippet(msg.Text))
+		}
+	}
+	if len(diff.Removed) > 0 {
+		printf("➖ %d removed:\n", len(diff.Removed))
+		for _, msg := range diff.Removed {
+			printf("   %s %s: %s\n", msg.ID, msg.User, diffSnippet(msg.Text))
+		}
+	}
+	if len(diff.Edited) > 0 {
+		printf("✏️  %d edited:\n", len(diff.Edited))
+		for _, edit := range diff.Edited {
+			printf("   %s %s:\n", edit.ID, edit.User)
+			printf("     - %s\n", diffSnippet(edit.OldText))
+			printf("     + %s\n", diffSnippet(edit.NewText))
+		}
+	}
+
+	if len(diff.UsersAdded) > 0 {
+		printf("👤 Users added: %s\n", strings.Join(diff.UsersAdded, ", "))
+	}
+	if len(diff.UsersRemoved) > 0 {
+		printf("👤 Users removed: %s\n", strings.Join(diff.UsersRemoved, ", "))
+	}
+	for _, change := range diff.UsersChanged {
+		printf("👤 %s changed: %s\n", change.ID, strings.Join(change.Fields, ", "))
+	}
+
+	stats := diff.Statistics
+	printf("\n📈 Statistics: messages %+d, threads %+d, replies %+d, users %+d, files %+d, reactions %+d\n",
+		stats.Messages, stats.Threads, stats.Replies, stats.Users, stats.Files, stats.Reactions)
+}
+
+// diffBound formats a bound of the compared range, or "…" when it is open
+func diffBound(t *time.Time) string {
+	if t == nil {
+		return "…"
+	}
+	return t.In(displayLocation).Format("2006-01-02 15:04")
+}
+
+// diffSnippet shortens message text to a single line for the text diff
+func diffSnippet(text string) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	if runes := []rune(text); len(runes) > 100 {
+		return string(runes[:97]) + "..."
+	}
+	return text
+}
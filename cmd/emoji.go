@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+)
+
+// emojiCmd represents the emoji command
+var emojiCmd = &cobra.Command{
+	Use:   "emoji [export...]",
+	Short: "Rank the emoji reactions of channels",
+	Long: `Rank the emoji reactions in one or more channels: the most used emoji, the
+users who react the most, and the users whose messages get the most reactions.
+Self-reactions are not counted as received.
+
+Reactions come from existing exports given as arguments ("-" reads one from
+stdin), or from the Slack API with --channel, which can be repeated. --offline
+reads the channels from the local message store instead. --from and --to narrow
+the messages counted.
+
+The table shows every ranking; --by picks one. CSV needs a single ranking and
+defaults to emoji; JSON always holds all three.
+
+Examples:
+  slacker emoji --channel general --channel random --from 2024-01-01
+  slacker emoji exports/general-*.json --by received --limit 10
+  slacker emoji --channel general --format csv --by given -o givers.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runEmoji(cmd, args); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	emojiChannels []string
+	emojiFrom     string
+	emojiTo       string
+	emojiBy       string
+	emojiFormat   string
+	emojiLimit    int
+	emojiOutput   string
+	emojiThreads  bool
+	emojiOffline  bool
+)
+
+func init() {
+	rootCmd.AddCommand(emojiCmd)
+
+	emojiCmd.Flags().StringSliceVarP(&emojiChannels, "channel", "c", nil, "Channel name to fetch (repeatable) instead of reading exports")
+	emojiCmd.Flags().StringVar(&emojiFrom, "from", "", "Only messages posted on or after this date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	emojiCmd.Flags().StringVar(&emojiTo, "to", "", "Only messages posted on or before this date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
+	emojiCmd.Flags().StringVar(&emojiBy, "by", "", "Ranking to show: emoji, given, received (default: all; emoji for CSV)")
+	emojiCmd.Flags().StringVarP(&emojiFormat, "format", "f", "table", "Output format: table, json, csv")
+	emojiCmd.Flags().IntVarP(&emojiLimit, "limit", "n", 20, "Entries per ranking (0 = all)")
+	emojiCmd.Flags().StringVarP(&emojiOutput, "output", "o", "", "Write the leaderboard to this file instead of stdout (- for stdout)")
+	emojiCmd.Flags().BoolVar(&emojiThreads, "threads", true, "Include reactions on thread replies")
+	emojiCmd.Flags().BoolVar(&emojiOffline, "offline", false, "Read the channels from the local message store (see 'slacker sync')")
+
+	registerChannelCompletion(emojiCmd, "channel")
+	registerValueCompletion(emojiCmd, "by", usecase.ReactionsByEmoji, usecase.ReactionsGiven, usecase.ReactionsReceived)
+	registerValueCompletion(emojiCmd, "format", "table", "json", "csv")
+}
+
+func runEmoji(cmd *cobra.Command, args []string) error {
+	switch emojiFormat {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("invalid format '%s'. Valid formats: table, json, csv", emojiFormat)
+	}
+	switch emojiBy {
+	case "", usecase.ReactionsByEmoji, usecase.ReactionsGiven, usecase.ReactionsReceived:
+	default:
+		return fmt.Errorf("invalid --by '%s'. Valid rankings: emoji, given, received", emojiBy)
+	}
+	if len(args) > 0 && (len(emojiChannels) > 0 || emojiOffline) {
+		return fmt.Errorf("export files cannot be combined with --channel or --offline")
+	}
+	if len(args) == 0 && len(emojiChannels) == 0 {
+		return fmt.Errorf("either export files or --channel must be specified")
+	}
+
+	var from, to *time.Time
+	if emojiFrom != "" {
+		parsed, err := parseDate(emojiFrom)
+		if err != nil {
+			return fmt.Errorf("invalid from date '%s': %w", emojiFrom, err)
+		}
+		from = &parsed
+	}
+	if emojiTo != "" {
+		parsed, err := parseDate(emojiTo)
+		if err != nil {
+			return fmt.Errorf("invalid to date '%s': %w", emojiTo, err)
+		}
+		to = &parsed
+	}
+
+	var exports []*models.ChannelExport
+	if len(args) > 0 {
+		for _, input := range args {
+			exportData, err := usecase.ReadExportFile(input)
+			if err != nil {
+				return err
+			}
+			if !emojiThreads {
+				exportData.Messages = withoutReplies(exportData.Messages)
+			}
+			exports = append(exports, exportData)
+		}
+	} else {
+		var err error
+		if exports, err = fetchReactions(cmd.Context(), from, to); err != nil {
+			return err
+		}
+	}
+
+	board := usecase.RankReactions(exports, from, to)
+	board.Truncate(emojiLimit)
+
+	var out io.Writer = os.Stdout
+	if !toStdout(emojiOutput) {
+		file, err := os.Create(emojiOutput)
+		if err != nil {
+			return models.NewExportError(models.ErrorCategoryIO, "failed to create output file", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := writeLeaderboard(out, board); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to write leaderboard", err)
+	}
+	if !toStdout(emojiOutput) {
+		printf("🏆 Wrote the reaction leaderboard to %s\n", emojiOutput)
+	}
+	return nil
+}
+
+// fetchReactions exports each --channel to a temporary file with reactions
+// enabled and reads the exports back, so fetching shares the export's
+// pagination, retries and date handling
+func fetchReactions(ctx context.Context, from, to *time.Time) ([]*models.ChannelExport, error) {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil && !emojiOffline {
+		return nil, err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return nil, err
+	}
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+
+	var source usecase.MessageClientInterface = slackClient
+	auditClient := slackClient
+	if emojiOffline {
+		st, err := openStore(true)
+		if err != nil {
+			return nil, err
+		}
+		defer st.Close()
+		source = st
+		auditClient = nil
+	}
+	auditLog, err := openAuditLog(cfg, auditClient)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "slacker-emoji-")
+	if err != nil {
+		return nil, models.NewExportError(models.ErrorCategoryIO, "failed to create temporary directory", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var exports []*models.ChannelExport
+	for i, name := range emojiChannels {
+		name = strings.TrimPrefix(name, "#")
+		channel, err := source.GetChannelByName(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find channel '%s': %w", name, err)
+		}
+
+		eprintf("🔄 Collecting reactions from #%s...\n", channel.Name)
+		options := models.ExportOptions{
+			ChannelID:        channel.ID,
+			ChannelName:      channel.Name,
+			IncludeThreads:   emojiThreads,
+			IncludeReactions: true,
+			DateFrom:         from,
+			DateTo:           to,
+			OutputFile:       filepath.Join(dir, fmt.Sprintf("export-%d.json", i)),
+			Format:           "json-compact",
+			PageSize:         apiConfig.PageSize,
+			ThreadDelay:      apiConfig.ThreadDelay,
+			Location:         displayLocation,
+		}
+		service := usecase.NewExportService(source, getVersion())
+		service.SetLogger(appLogger)
+		result, err := service.ExportChannel(ctx, options, usecase.NewLogSink(appLogger))
+
+		// The temporary export is an implementation detail; the audit log
+		// names where the leaderboard went
+		entry := audit.ForExport(audit.ActionEmoji, options, result, err)
+		entry.Output = emojiOutput
+		if toStdout(entry.Output) {
+			entry.Output = "stdout"
+		}
+		if err := recordAudit(auditLog, entry, err); err != nil {
+			return nil, err
+		}
+		exportData, err := usecase.ReadExportFile(result.OutputFile)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, exportData)
+	}
+	return exports, nil
+}
+
+// writeLeaderboard writes board in the --format and --by chosen
+func writeLeaderboard(w io.Writer, board *usecase.ReactionLeaderboard) error {
+	switch emojiFormat {
+	case "json":
+		data, err := json.MarshalIndent(board, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case "csv":
+		view := emojiBy
+		if view == "" {
+			view = usecase.ReactionsByEmoji
+		}
+		return usecase.WriteReactionsCSV(w, board, view)
+	}
+
+	channels := make([]string, len(board.Channels))
+	for i, name := range board.Channels {
+		channels[i] = "#" + name
+	}
+	fprintf(w, "🏆 Reactions in %s: %d\n", strings.Join(channels, ", "), board.Total)
+	if emojiBy == "" || emojiBy == usecase.ReactionsByEmoji {
+		fprintf(w, "\n😀 Top Emoji:\n")
+		for i, rank := range board.Emoji {
+			fprintf(w, "   %3d. %-24s %6d  (%d messages, %d users)\n", i+1, ":"+rank.Name+":", rank.Count, rank.Messages, rank.Users)
+		}
+	}
+	if emojiBy == "" || emojiBy == usecase.ReactionsGiven {
+		fprintf(w, "\n👏 Most Reactions Given:\n")
+		printReactionRanks(w, board.Given)
+	}
+	if emojiBy == "" || emojiBy == usecase.ReactionsReceived {
+		fprintf(w, "\n🌟 Most Reactions Received:\n")
+		printReactionRanks(w, board.Received)
+	}
+	return nil
+}
+
+// printReactionRanks prints a ranking of users with their favourite emoji
+func printReactionRanks(w io.Writer, ranks []usecase.UserReactionRank) {
+	for i, rank := range ranks {
+		name := rank.User
+		if rank.UserName != "" {
+			name = "@" + rank.UserName
+		}
+		fprintf(w, "   %3d. %-24s %6d  (mostly :%s:)\n", i+1, name, rank.Count, rank.TopEmoji)
+	}
+}
@@ -1,15 +1,23 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"github.com/itcaat/slacker/internal/api"
 	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/history"
+	"github.com/itcaat/slacker/internal/storage"
 	"github.com/itcaat/slacker/internal/usecase"
 	"github.com/itcaat/slacker/models"
 )
@@ -25,6 +33,9 @@ Examples:
   # Export a channel by name
   slacker export --channel general --output general-export.json
 
+  # Pick the channel from a list (in a terminal)
+  slacker export
+
   # Export with threads and compress with gzip
   slacker export --channel general --threads --compress gzip --output general.json
 
@@ -32,76 +43,278 @@ Examples:
   slacker export --channel general --from 2024-01-01 --to 2024-01-31 --format json-pretty
 
   # Export without files and reactions for smaller output
-  slacker export --channel general --no-files --no-reactions --format json-compact`,
+  slacker export --channel general --no-files --no-reactions --format json-compact
+
+  # Upload the export directly to object storage
+  slacker export --channel general --output s3://my-bucket/slack/ --sse aws:kms --sse-kms-key-id alias/slack
+  slacker export --channel general --output gs://my-bucket/slack/general.json.gz --compress gzip
+  slacker export --channel general --output azblob://backups/slack/general.json
+
+  # Keep going when threads or the user directory cannot be fetched
+  slacker export --channel general --best-effort
+
+  # Only messages and thread replies written by alice or bob
+  slacker export --channel general --user @alice --user @bob
+
+  # Incident discussions only, without join/leave noise
+  slacker export --channel ops --match "(?i)incident|outage" --exclude-subtype channel_join,channel_leave
+
+  # Keep topic changes as compact system events
+  slacker export --channel general --subtype-policy channel_topic=transform,channel_join=exclude
+
+  # One file per month plus general-index.json with overall statistics
+  slacker export --channel general --output general.json --split-by month
+
+  # Slack's own export layout for tools that ingest it; channels accumulate
+  slacker export --channel general --layout slack-native --output slack-export/
+  slacker export --channel random --layout slack-native --output slack-export/
+
+  # Bound an accidental export of a huge channel
+  slacker export --channel random --max-messages 50000 --max-duration 30m
+
+  # Highlights digest: messages with at least 5 reactions
+  slacker export --channel general --min-reactions 5
+
+  # Audit what integrations post
+  slacker export --channel general --only-bots
+
+  # Everything alice wrote plus every thread they took part in, in full
+  slacker export --channel legal --user @alice --participated-threads
+
+  # Only threaded discussions, or replies flat as in Slack's own exports
+  slacker export --channel support --threads-only
+  slacker export --channel general --no-replies-inline
+
+  # Predictable paths for scheduled exports
+  slacker export --channel general --from 2024-01-01 --to 2024-01-31 \
+    --output-template "{{.Channel}}/{{.Date}}/{{.Channel}}-{{.From}}-{{.To}}.json"
+
+  # Paginated transcript with a metadata cover page, e.g. for legal holds
+  slacker export --channel general --from 2024-01-01 --format pdf
+
+  # Token-bounded conversation chunks for RAG ingestion
+  slacker export --channel general --format llm-jsonl --chunk-tokens 500 --text-only
+
+  # Summarize each day with an OpenAI-compatible endpoint (message text is sent to it)
+  slacker export --channel general --summarize --llm-endpoint https://api.openai.com/v1
+
+  # Record checksums and verify the archive later
+  slacker export --channel general --output general.json --manifest
+  slacker verify general.manifest.json
+
+  # Export from the local store kept up to date by 'slacker sync'
+  slacker export --channel general --offline
+
+  # Scripted export: no decorations, result as JSON
+  slacker export --channel general --json | jq .output_file
+
+  # Post a summary to a webhook and a Slack channel when the export finishes
+  slacker export --channel general --notify-webhook https://hooks.example.com/slacker --notify-channel ops`,
 	RunE: runExport,
 }
 
 var (
-	exportChannel   string
-	exportChannelID string
-	exportOutput    string
-	exportFormat    string
-	exportCompress  string
-	exportThreads   bool
-	exportFiles     bool
-	exportReactions bool
-	exportFromDate  string
-	exportToDate    string
-	exportVerbose   bool
+	exportChannel    string
+	exportChannelID  string
+	exportOutput     string
+	exportHeaders    []string
+	exportFormat     string
+	exportCompress   string
+	exportThreads    bool
+	exportFiles      bool
+	exportReactions  bool
+	exportNoThreads  bool
+	exportNoFiles    bool
+	exportNoReact    bool
+	exportFromDate   string
+	exportToDate     string
+	exportSSE        string
+	exportSSEKeyID   string
+	exportQuiet      bool
+	exportJSON       bool
+	exportBestEffort bool
+	exportOffline    bool
+	exportUsers      []string
+	exportMatch      string
+	exportExclude    []string
+	exportSubtypes   []string
+	exportNoBots     bool
+	exportOnlyBots   bool
+	exportNoExternal bool
+	exportMinReact   int
+	exportMaxMsgs    int
+	exportMaxTime    time.Duration
+	exportSplitBy    string
+	exportTemplate   string
+	exportManifest   bool
+	exportEmoji      bool
+	exportAvatars    bool
+	exportFileInfo   bool
+	exportCanvas     bool
+	exportGroups     bool
+	exportPermalinks bool
+	exportLinks      bool
+	exportSnapshots  bool
+	exportSnapshotTo string
+	exportArchiveTo  []string
+	exportTimeline   bool
+	exportTransform  string
+	exportChunkSize  int
+	exportTextOnly   bool
+	exportSummarize  bool
+	exportSummaryBy  string
+	exportLLMURL     string
+	exportLLMModel   string
+	exportResume     string
+	exportThreadOnly bool
+	exportPartThread bool
+	exportFlatReply  bool
+	exportLayout     string
 )
 
 func init() {
 	rootCmd.AddCommand(exportCmd)
 
 	// Channel selection
-	exportCmd.Flags().StringVarP(&exportChannel, "channel", "c", "", "Channel name to export (required)")
+	exportCmd.Flags().StringVarP(&exportChannel, "channel", "c", "", "Channel name to export (prompted for in a terminal when omitted)")
 	exportCmd.Flags().StringVar(&exportChannelID, "channel-id", "", "Channel ID to export (alternative to --channel)")
 
 	// Output options
-	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file path (default: <channel>-export-<timestamp>.json)")
-	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json-pretty", "Output format: json, json-pretty, json-compact")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file path, s3://, gs://, azblob:// or http(s):// URL, or - for stdout (default: <channel>-export-<timestamp>.json in export.default_output_dir)")
+	exportCmd.Flags().StringVar(&exportTemplate, "output-template", "", "Output path template with {{.Channel}}, {{.ChannelID}}, {{.Workspace}}, {{.From}}, {{.To}}, {{.Date}} and {{.Timestamp}}")
+	exportCmd.MarkFlagsMutuallyExclusive("output", "output-template")
+	exportCmd.Flags().StringArrayVar(&exportHeaders, "output-header", nil, "Header sent with an http(s):// output, as 'Name: value' (repeatable, e.g. 'Authorization: Bearer <token>')")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "json-pretty", "Output format: json, json-pretty, json-compact, pdf, llm-jsonl (default from export.default_format)")
+	exportCmd.Flags().IntVar(&exportChunkSize, "chunk-tokens", 0, "Approximate token limit per llm-jsonl chunk (default 1000)")
+	exportCmd.Flags().BoolVar(&exportTextOnly, "text-only", false, "Leave files, attachments and reactions out of llm-jsonl chunks")
 	exportCmd.Flags().StringVar(&exportCompress, "compress", "", "Compression: none, gzip")
+	exportCmd.Flags().StringVar(&exportSplitBy, "split-by", "", "Write one file per month, day or size=<n>MB plus an index file")
+	exportCmd.Flags().StringVar(&exportLayout, "layout", "", "Output layout: slack-native writes users.json, channels.json and <channel>/YYYY-MM-DD.json like Slack's own exports, with --output as the directory")
+	exportCmd.Flags().BoolVar(&exportManifest, "manifest", false, "Write <name>.manifest.json with SHA-256 checksums of the produced files (see 'slacker verify')")
+	exportCmd.Flags().StringVar(&exportSSE, "sse", "", "Server-side encryption for S3 destinations: AES256, aws:kms")
+	exportCmd.Flags().StringVar(&exportSSEKeyID, "sse-kms-key-id", "", "KMS key for remote destinations (S3 KMS key, GCS kmsKeyName, Azure encryption scope)")
 
 	// Content options
-	exportCmd.Flags().BoolVar(&exportThreads, "threads", true, "Include thread replies")
-	exportCmd.Flags().BoolVar(&exportFiles, "files", true, "Include file attachments")
-	exportCmd.Flags().BoolVar(&exportReactions, "reactions", true, "Include message reactions")
-	exportCmd.Flags().BoolVar(&exportThreads, "no-threads", false, "Exclude thread replies")
-	exportCmd.Flags().BoolVar(&exportFiles, "no-files", false, "Exclude file attachments")
-	exportCmd.Flags().BoolVar(&exportReactions, "no-reactions", false, "Exclude message reactions")
+	exportCmd.Flags().BoolVar(&exportThreads, "threads", true, "Include thread replies (default from export.include_threads)")
+	exportCmd.Flags().BoolVar(&exportFiles, "files", true, "Include file attachments (default from export.include_files)")
+	exportCmd.Flags().BoolVar(&exportReactions, "reactions", true, "Include message reactions (default from export.include_reactions)")
+	exportCmd.Flags().BoolVar(&exportEmoji, "include-emoji", false, "Resolve custom emoji and download their images next to the export")
+	exportCmd.Flags().BoolVar(&exportAvatars, "include-avatars", false, "Download user avatars next to the export and refer to them by relative path, so the archive renders offline")
+	exportCmd.Flags().BoolVar(&exportGroups, "include-usergroups", false, "Add the user groups mentioned in messages and resolve their mentions")
+	exportCmd.Flags().BoolVar(&exportCanvas, "include-canvas", false, "Add the channel canvas and shared canvases and posts as Markdown")
+	exportCmd.Flags().BoolVar(&exportFileInfo, "include-files", false, "Fill file metadata (thumbnails, dimensions, permalinks) from files.info and download the files next to the export")
+	exportCmd.Flags().BoolVar(&exportPermalinks, "include-permalinks", false, "Add a permalink to every message and reply, built from the workspace URL")
+	exportCmd.Flags().BoolVar(&exportLinks, "include-links", false, "Add a links section listing the URLs shared in messages (see 'slacker links')")
+	exportCmd.Flags().BoolVar(&exportSnapshots, "snapshot-links", false, "Store the title and description of every shared URL with its message, so the context survives broken links")
+	exportCmd.Flags().StringVar(&exportSnapshotTo, "snapshot-service", "", "Also archive shared URLs of the --archive-domain domains with --snapshot-links through this request URL, e.g. https://web.archive.org/save/{url} (default from export.snapshot_service)")
+	exportCmd.Flags().StringSliceVar(&exportArchiveTo, "archive-domain", nil, "Domain whose shared URLs --snapshot-service archives, subdomains included (repeatable; default from export.archive_domains)")
+	exportCmd.Flags().BoolVar(&exportTimeline, "include-timeline", false, "Add a channel_timeline section with joins, departures and topic, purpose and name changes")
+	exportCmd.Flags().BoolVar(&exportSummarize, "summarize", false, "Send each day's or thread's messages to --llm-endpoint and store the summaries in the export")
+	exportCmd.Flags().StringVar(&exportSummaryBy, "summarize-by", models.SummarizeByDay, "Summary scope: day, thread")
+	exportCmd.Flags().StringVar(&exportLLMURL, "llm-endpoint", "", "OpenAI-compatible API base URL for --summarize (API key from SLACKER_LLM_API_KEY, or OPENAI_API_KEY for https://api.openai.com)")
+	exportCmd.Flags().StringVar(&exportLLMModel, "llm-model", "gpt-4o-mini", "Model used for --summarize")
+	exportCmd.Flags().BoolVar(&exportNoThreads, "no-threads", false, "Exclude thread replies")
+	exportCmd.Flags().BoolVar(&exportNoFiles, "no-files", false, "Exclude file attachments")
+	exportCmd.Flags().BoolVar(&exportNoReact, "no-reactions", false, "Exclude message reactions")
+	exportCmd.Flags().BoolVar(&exportThreadOnly, "threads-only", false, "Only export messages that started a thread, with their replies")
+	exportCmd.Flags().BoolVar(&exportFlatReply, "no-replies-inline", false, "Write thread replies as top-level messages with thread_ts, like Slack's own exports")
 
 	// Date filtering
 	exportCmd.Flags().StringVar(&exportFromDate, "from", "", "Start date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
 	exportCmd.Flags().StringVar(&exportToDate, "to", "", "End date (YYYY-MM-DD or YYYY-MM-DD HH:MM:SS)")
 
+	// Message filters
+	exportCmd.Flags().StringSliceVar(&exportUsers, "user", nil, "Only export messages and replies by this user (@name, display name or ID; repeatable)")
+	exportCmd.Flags().BoolVar(&exportPartThread, "participated-threads", false, "With --user, include every thread the users started or replied to in full, not just their own messages")
+	exportCmd.Flags().StringVar(&exportMatch, "match", "", "Only export messages whose text matches this regular expression")
+	exportCmd.Flags().StringSliceVar(&exportExclude, "exclude-subtype", nil, "Drop messages with these subtypes (e.g. channel_join,bot_message)")
+	exportCmd.Flags().StringVar(&exportTransform, "transform", "", "Starlark script whose transform(msg) function modifies, drops or tags each message")
+	exportCmd.Flags().StringSliceVar(&exportSubtypes, "subtype-policy", nil, "Include, exclude or transform system messages by subtype (e.g. channel_join=exclude,channel_topic=transform)")
+	exportCmd.Flags().BoolVar(&exportNoBots, "no-bots", false, "Drop messages posted by bots and apps")
+	exportCmd.Flags().BoolVar(&exportOnlyBots, "only-bots", false, "Only export messages posted by bots and apps")
+	exportCmd.MarkFlagsMutuallyExclusive("no-bots", "only-bots")
+	exportCmd.Flags().BoolVar(&exportNoExternal, "exclude-external", false, "Drop messages by members of other organizations (Slack Connect) and leave them out of the user directory")
+	exportCmd.Flags().IntVar(&exportMinReact, "min-reactions", 0, "Only export messages with at least this many reactions (thread parents are kept)")
+
+	// Limits
+	exportCmd.Flags().IntVar(&exportMaxMsgs, "max-messages", 0, "Stop after the newest N messages (default from export.max_messages, 0 = no limit)")
+	exportCmd.Flags().DurationVar(&exportMaxTime, "max-duration", 0, "Stop fetching after this long, e.g. 30m, and write what was fetched (0 = no limit)")
+
 	// Other options
-	exportCmd.Flags().BoolVarP(&exportVerbose, "verbose", "v", false, "Verbose output with detailed progress")
+	exportCmd.Flags().BoolVarP(&exportQuiet, "quiet", "q", false, "Suppress banners and progress output")
+	exportCmd.Flags().BoolVar(&exportJSON, "json", false, "Print the export result as JSON to stdout")
+	exportCmd.Flags().BoolVar(&exportBestEffort, "best-effort", false, "Record fetch failures as warnings and write a partial export instead of aborting")
+	exportCmd.Flags().BoolVar(&exportOffline, "offline", false, "Export from the local message store (see 'slacker sync') instead of the Slack API")
+	exportCmd.Flags().StringVar(&exportResume, "resume", "", "Continue an interrupted export from its checkpoint file")
+
+	// Notifications and observability
+	addNotifyFlags(exportCmd)
+	addTelemetryFlags(exportCmd)
 
-	// Mark channel as required (either --channel or --channel-id)
-	exportCmd.MarkFlagRequired("channel")
+	registerChannelCompletion(exportCmd, "channel")
+	registerValueCompletion(exportCmd, "format", "json", "json-pretty", "json-compact", "pdf", "llm-jsonl")
+	registerValueCompletion(exportCmd, "compress", "none", "gzip")
+	registerValueCompletion(exportCmd, "split-by", "month", "day")
+	registerValueCompletion(exportCmd, "layout", usecase.LayoutSlackNative)
+	registerValueCompletion(exportCmd, "summarize-by", models.SummarizeByDay, models.SummarizeByThread)
+	registerValueCompletion(exportCmd, "sse", "AES256", "aws:kms")
 }
 
-func runExport(cmd *cobra.Command, args []string) error {
+func runExport(cmd *cobra.Command, args []string) (err error) {
+	started := time.Now()
+
 	// Load configuration
 	configManager := config.NewManager()
-	token, err := configManager.GetToken()
-	if err != nil {
-		return fmt.Errorf("Slack token not configured. Run 'slacker auth <token>' first")
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil && !exportOffline {
+		return err
 	}
 
-	// Validate channel specification
-	if exportChannel == "" && exportChannelID == "" {
-		return fmt.Errorf("either --channel or --channel-id must be specified")
-	}
+	startTelemetry()
+	defer flushTelemetry()
 
 	// Create Slack client
-	slackClient := api.NewSlackClient(token, exportVerbose)
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
 
-	// Resolve channel ID if channel name was provided
+	// Messages come from the API, or from the local store in offline mode
+	var source usecase.MessageClientInterface = slackClient
+	if exportOffline {
+		st, err := openStore(true)
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		source = st
+	}
+
+	// An interrupted export continues from its checkpoint, which names the
+	// channel when none is given
+	var checkpoint *models.ExportCheckpoint
 	channelID := exportChannelID
 	channelName := exportChannel
-	if channelID == "" {
-		channel, err := slackClient.GetChannelByName(cmd.Context(), exportChannel)
+	if exportResume != "" {
+		if exportOffline {
+			return fmt.Errorf("--resume cannot be used with --offline")
+		}
+		if checkpoint, err = usecase.ReadCheckpoint(exportResume); err != nil {
+			return err
+		}
+		if channelID == "" && channelName == "" {
+			channelID = checkpoint.ChannelID
+		}
+	}
+
+	// Resolve channel ID if channel name was provided, or ask for a channel
+	if channelID == "" && channelName == "" {
+		channel, err := pickChannel(cmd.Context(), source, "one of --channel, --channel-id or --resume must be specified")
+		if err != nil {
+			return err
+		}
+		channelID = channel.ID
+		channelName = channel.Name
+	} else if channelID == "" {
+		channel, err := source.GetChannelByName(cmd.Context(), exportChannel)
 		if err != nil {
 			return fmt.Errorf("failed to find channel '%s': %w", exportChannel, err)
 		}
@@ -109,6 +322,93 @@ func runExport(cmd *cobra.Command, args []string) error {
 		channelName = channel.Name
 	}
 
+	if exportMatch != "" {
+		if _, err := regexp.Compile(exportMatch); err != nil {
+			return fmt.Errorf("invalid --match pattern: %w", err)
+		}
+	}
+
+	if exportGroups && exportOffline {
+		return fmt.Errorf("--include-usergroups is not available with --offline")
+	}
+
+	if exportCanvas && exportOffline {
+		return fmt.Errorf("--include-canvas is not available with --offline")
+	}
+
+	if exportFileInfo && exportOffline {
+		return fmt.Errorf("--include-files is not available with --offline")
+	}
+
+	if exportEmoji && exportOffline {
+		return fmt.Errorf("--include-emoji is not available with --offline")
+	}
+
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+	subtypeOverrides, err := usecase.ParseSubtypePolicy(exportSubtypes)
+	if err != nil {
+		return err
+	}
+	if err := usecase.ValidateSubtypePolicy(cfg.Export.SubtypePolicy); err != nil {
+		return fmt.Errorf("invalid export.subtype_policy: %w", err)
+	}
+	subtypePolicy := usecase.MergeSubtypePolicy(cfg.Export.SubtypePolicy, subtypeOverrides)
+	if !cmd.Flags().Changed("format") && cfg.Export.DefaultFormat != "" {
+		exportFormat = cfg.Export.DefaultFormat
+	}
+
+	if exportPermalinks && exportOffline {
+		return fmt.Errorf("--include-permalinks is not available with --offline")
+	}
+
+	if exportMinReact < 0 {
+		return fmt.Errorf("--min-reactions must not be negative")
+	}
+	if exportMaxMsgs < 0 || exportMaxTime < 0 {
+		return fmt.Errorf("--max-messages and --max-duration must not be negative")
+	}
+	if !cmd.Flags().Changed("max-messages") {
+		exportMaxMsgs = cfg.Export.MaxMessages
+	}
+	if !cmd.Flags().Changed("snapshot-service") {
+		exportSnapshotTo = cfg.Export.SnapshotService
+	}
+	if !cmd.Flags().Changed("archive-domain") {
+		exportArchiveTo = cfg.Export.ArchiveDomains
+	}
+	if cmd.Flags().Changed("snapshot-service") && !exportSnapshots {
+		return fmt.Errorf("--snapshot-service requires --snapshot-links")
+	}
+	if cmd.Flags().Changed("archive-domain") && exportSnapshotTo == "" {
+		return fmt.Errorf("--archive-domain requires --snapshot-service")
+	}
+	if exportSnapshots && exportSnapshotTo != "" && len(exportArchiveTo) == 0 {
+		return fmt.Errorf("--snapshot-service sends URLs to a third party, so it only archives the domains given with --archive-domain (or export.archive_domains)")
+	}
+	if exportSnapshots && exportSnapshotTo != "" && !strings.HasPrefix(exportSnapshotTo, "http://") && !strings.HasPrefix(exportSnapshotTo, "https://") {
+		return fmt.Errorf("invalid snapshot service '%s': expected an http(s) URL", exportSnapshotTo)
+	}
+
+	bots, err := botFilter(exportNoBots, exportOnlyBots)
+	if err != nil {
+		return err
+	}
+
+	// Resolve --user references to user IDs
+	var userIDs []string
+	if len(exportUsers) > 0 {
+		users, err := source.GetUsers(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to fetch users: %w", err)
+		}
+		if userIDs, err = usecase.ResolveUserIDs(users, exportUsers); err != nil {
+			return err
+		}
+	}
+
 	// Parse date filters
 	var fromDate, toDate *time.Time
 	if exportFromDate != "" {
@@ -126,22 +426,61 @@ func runExport(cmd *cobra.Command, args []string) error {
 		toDate = &parsed
 	}
 
-	// Handle negative flags
-	if cmd.Flags().Changed("no-threads") {
-		exportThreads = false
+	// Content flags override the config file, which overrides the defaults
+	if exportThreads, err = includeContent(cmd, "threads", cfg.Export.IncludeThreads); err != nil {
+		return err
+	}
+	if exportFiles, err = includeContent(cmd, "files", cfg.Export.IncludeFiles); err != nil {
+		return err
+	}
+	if exportReactions, err = includeContent(cmd, "reactions", cfg.Export.IncludeReactions); err != nil {
+		return err
+	}
+
+	if exportThreadOnly && !exportThreads {
+		return fmt.Errorf("--threads-only needs thread replies and cannot be used with --no-threads")
+	}
+	if exportPartThread && len(exportUsers) == 0 {
+		return fmt.Errorf("--participated-threads requires --user")
+	}
+	if exportPartThread && !exportThreads {
+		return fmt.Errorf("--participated-threads needs thread replies and cannot be used with --no-threads")
 	}
-	if cmd.Flags().Changed("no-files") {
-		exportFiles = false
+
+	// Look up the workspace once when the output template or permalinks need it
+	var workspaceName, workspaceURL string
+	if exportPermalinks || usecase.TemplateUsesWorkspace(exportTemplate) {
+		if exportOffline {
+			return fmt.Errorf("{{.Workspace}} is not available with --offline")
+		}
+		auth, err := slackClient.TestAuth(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to look up workspace: %w", err)
+		}
+		workspaceName, workspaceURL = auth.Team, auth.URL
 	}
-	if cmd.Flags().Changed("no-reactions") {
-		exportReactions = false
+
+	if checkpoint != nil {
+		if checkpoint.ChannelID != channelID {
+			return fmt.Errorf("checkpoint %s belongs to channel %s, not %s", exportResume, checkpoint.ChannelID, channelID)
+		}
 	}
 
 	// Generate output filename if not specified
 	outputFile := exportOutput
-	if outputFile == "" {
+	if checkpoint != nil && outputFile == "" && exportTemplate == "" {
+		outputFile = checkpoint.OutputFile
+	} else if exportTemplate != "" {
+		data := usecase.NewOutputNameData(channelID, channelName, workspaceName, fromDate, toDate, time.Now())
+		if outputFile, err = usecase.RenderOutputTemplate(exportTemplate, data); err != nil {
+			return err
+		}
+	} else if outputFile == "" || (strings.HasSuffix(outputFile, "/") && exportLayout != usecase.LayoutSlackNative) {
+		if outputFile == "" {
+			outputFile = cfg.Export.DefaultOutputDir
+		}
 		timestamp := time.Now().Format("20060102-150405")
-		outputFile = fmt.Sprintf("%s-export-%s.json", channelName, timestamp)
+		outputFile = storage.Join(outputFile, usecase.ExportFileName(channelName, timestamp, exportFormat))
 	}
 
 	// Validate format
@@ -149,9 +488,51 @@ func runExport(cmd *cobra.Command, args []string) error {
 		"json":         true,
 		"json-pretty":  true,
 		"json-compact": true,
+		"pdf":          true,
+
+		usecase.FormatLLMJSONL: true,
 	}
 	if !validFormats[exportFormat] {
-		return fmt.Errorf("invalid format '%s'. Valid formats: json, json-pretty, json-compact", exportFormat)
+		return fmt.Errorf("invalid format '%s'. Valid formats: json, json-pretty, json-compact, pdf, llm-jsonl", exportFormat)
+	}
+	if (exportFormat == "pdf" || exportFormat == usecase.FormatLLMJSONL) && exportSplitBy != "" {
+		return fmt.Errorf("--split-by is not supported with --format %s", exportFormat)
+	}
+	if (exportChunkSize != 0 || exportTextOnly) && exportFormat != usecase.FormatLLMJSONL {
+		return fmt.Errorf("--chunk-tokens and --text-only require --format llm-jsonl")
+	}
+	if exportChunkSize < 0 {
+		return fmt.Errorf("--chunk-tokens must be positive")
+	}
+	if exportFlatReply && (exportFormat == "pdf" || exportFormat == usecase.FormatLLMJSONL) {
+		return fmt.Errorf("--no-replies-inline is not supported with --format %s", exportFormat)
+	}
+	switch exportLayout {
+	case "":
+	case usecase.LayoutSlackNative:
+		if exportFormat == "pdf" || exportFormat == usecase.FormatLLMJSONL {
+			return fmt.Errorf("--layout %s is not supported with --format %s", exportLayout, exportFormat)
+		}
+		if outputFile == usecase.StdoutOutput || exportSplitBy != "" || exportManifest || (exportCompress != "" && exportCompress != "none") {
+			return fmt.Errorf("--layout %s cannot be used with --output -, --split-by, --manifest or --compress", exportLayout)
+		}
+		if exportFlatReply {
+			return fmt.Errorf("--layout %s always writes replies flat; drop --no-replies-inline", exportLayout)
+		}
+	default:
+		return fmt.Errorf("invalid layout '%s'. Valid layouts: %s", exportLayout, usecase.LayoutSlackNative)
+	}
+	summarizeBy := ""
+	if exportSummarize {
+		if exportLLMURL == "" {
+			return fmt.Errorf("--summarize requires --llm-endpoint")
+		}
+		if exportSummaryBy != models.SummarizeByDay && exportSummaryBy != models.SummarizeByThread {
+			return fmt.Errorf("invalid --summarize-by '%s'. Valid values: day, thread", exportSummaryBy)
+		}
+		summarizeBy = exportSummaryBy
+	} else if exportLLMURL != "" {
+		return fmt.Errorf("--llm-endpoint is only used with --summarize")
 	}
 
 	// Validate compression
@@ -165,6 +546,45 @@ func runExport(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Validate split
+	if exportSplitBy != "" {
+		if _, err := usecase.ParseSplitBy(exportSplitBy); err != nil {
+			return err
+		}
+	}
+
+	// Validate server-side encryption
+	if exportSSE != "" && exportSSE != "AES256" && exportSSE != "aws:kms" {
+		return fmt.Errorf("invalid server-side encryption '%s'. Valid values: AES256, aws:kms", exportSSE)
+	}
+	if outputFile == usecase.StdoutOutput {
+		if exportSplitBy != "" || exportManifest || exportJSON {
+			return fmt.Errorf("--split-by, --manifest and --json cannot be used with --output -")
+		}
+		if exportEmoji {
+			// The emoji images are saved next to the export file
+			return fmt.Errorf("--include-emoji cannot be used with --output -; write the export to a file")
+		}
+		// The export itself is written to stdout, so messages go to stderr
+		messagesOnStderr = true
+	}
+	if exportManifest && !usecase.IsLocalOutput(outputFile) {
+		return fmt.Errorf("--manifest requires a local output path")
+	}
+	if exportAvatars && !usecase.IsLocalOutput(outputFile) {
+		return fmt.Errorf("--include-avatars requires a local output path")
+	}
+	outputHeaders, err := parseOutputHeaders(exportHeaders)
+	if err != nil {
+		return err
+	}
+	if outputHeaders != nil && !strings.HasPrefix(outputFile, "http://") && !strings.HasPrefix(outputFile, "https://") {
+		return fmt.Errorf("--output-header requires an http:// or https:// output")
+	}
+	if (exportSSE != "" || exportSSEKeyID != "") && !storage.IsRemote(outputFile) {
+		return fmt.Errorf("--sse and --sse-kms-key-id require an s3://, gs:// or azblob:// output")
+	}
+
 	// Create export options
 	options := models.ExportOptions{
 		ChannelID:        channelID,
@@ -177,139 +597,471 @@ func runExport(cmd *cobra.Command, args []string) error {
 		OutputFile:       outputFile,
 		Format:           exportFormat,
 		Compression:      exportCompress,
+		MaxMessages:      exportMaxMsgs,
+		MaxDuration:      exportMaxTime,
+
+		ServerSideEncryption: exportSSE,
+		EncryptionKeyID:      exportSSEKeyID,
+		OutputHeaders:        outputHeaders,
+
+		BestEffort: exportBestEffort,
+
+		Users:                  userIDs,
+		Match:                  exportMatch,
+		ExcludeSubtypes:        exportExclude,
+		SubtypePolicy:          subtypePolicy,
+		Bots:                   bots,
+		ExcludeExternal:        exportNoExternal,
+		MinReactions:           exportMinReact,
+		ThreadsOnly:            exportThreadOnly,
+		ParticipatedThreads:    exportPartThread,
+		FlatReplies:            exportFlatReply,
+		SplitBy:                exportSplitBy,
+		Layout:                 exportLayout,
+		ChunkTokens:            exportChunkSize,
+		TextOnly:               exportTextOnly,
+		SummarizeBy:            summarizeBy,
+		Manifest:               exportManifest,
+		ManifestKey:            manifestKey(),
+		IncludeEmoji:           exportEmoji,
+		IncludeAvatars:         exportAvatars,
+		IncludeFileInfo:        exportFileInfo,
+		IncludeCanvas:          exportCanvas,
+		IncludeUserGroups:      exportGroups,
+		IncludeLinks:           exportLinks,
+		SnapshotLinks:          exportSnapshots,
+		SnapshotService:        exportSnapshotTo,
+		SnapshotArchiveDomains: exportArchiveTo,
+		IncludeTimeline:        exportTimeline,
+		WorkspaceURL:           workspaceURL,
+
+		PageSize:    apiConfig.PageSize,
+		ThreadDelay: apiConfig.ThreadDelay,
+		Location:    displayLocation,
 	}
 
+	// Decorative output is suppressed in quiet and JSON modes
+	showOutput := !exportQuiet && !exportJSON
+
 	// Create export service
-	version := viper.GetString("version")
-	if version == "" {
-		version = "1.0.0"
+	exportService := usecase.NewExportService(source, getVersion())
+	exportService.SetLogger(appLogger)
+	if exportEmoji {
+		exportService.SetEmojiClient(slackClient)
 	}
-	exportService := usecase.NewExportService(slackClient, version)
-
-	// Print export information
-	fmt.Printf("🚀 Starting export of channel '%s'\n", channelName)
-	fmt.Printf("📁 Output file: %s\n", outputFile)
-	fmt.Printf("📊 Format: %s", exportFormat)
-	if exportCompress != "" && exportCompress != "none" {
-		fmt.Printf(" (compressed with %s)", exportCompress)
+	if exportAvatars {
+		exportService.SetAvatarClient(slackClient)
 	}
-	fmt.Println()
-
-	if fromDate != nil || toDate != nil {
-		fmt.Printf("📅 Date range: ")
-		if fromDate != nil {
-			fmt.Printf("from %s ", fromDate.Format("2006-01-02"))
+	if exportSnapshots {
+		exportService.SetLinkSnapshotClient(slackClient)
+	}
+	if exportFileInfo {
+		exportService.SetFileClient(slackClient)
+	}
+	if exportCanvas {
+		exportService.SetCanvasClient(slackClient)
+	}
+	if exportGroups {
+		exportService.SetUserGroupClient(slackClient)
+	}
+	// Offline exports record no Slack identity in the audit log
+	auditClient := slackClient
+	if exportOffline {
+		auditClient = nil
+	} else {
+		exportService.SetWorkspaceClient(slackClient)
+		exportService.SetBotClient(slackClient)
+		exportService.SetChannelInfoClient(slackClient)
+	}
+	auditLog, err := openAuditLog(cfg, auditClient)
+	if err != nil {
+		return err
+	}
+	exportService.SetAuditLog(auditLog)
+	for _, hook := range exportHooks(cfg) {
+		exportService.AddHook(hook)
+	}
+	if exportTransform != "" {
+		transform, err := usecase.LoadTransform(exportTransform)
+		if err != nil {
+			return err
 		}
-		if toDate != nil {
-			fmt.Printf("to %s ", toDate.Format("2006-01-02"))
+		transform.SetLogger(appLogger)
+		exportService.SetTransform(transform)
+	}
+	if exportSummarize {
+		// The OpenAI key is only sent to OpenAI; other endpoints take theirs
+		// from SLACKER_LLM_API_KEY
+		apiKey := os.Getenv("SLACKER_LLM_API_KEY")
+		if apiKey == "" && usecase.IsOpenAIEndpoint(exportLLMURL) {
+			apiKey = os.Getenv("OPENAI_API_KEY")
 		}
-		fmt.Println()
+		summaryClient := usecase.NewLLMClient(exportLLMURL, apiKey, exportLLMModel)
+		summaryClient.SetTransport(api.Transport())
+		exportService.SetSummaryClient(summaryClient)
+	}
+	notifyService, err := newNotifyService(slackClient)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("🔧 Options: threads=%v, files=%v, reactions=%v\n",
-		exportThreads, exportFiles, exportReactions)
-	fmt.Println()
-
-	// Progress tracking
-	var lastProgress models.ExportProgress
-	progressCallback := func(progress models.ExportProgress) {
-		if exportVerbose {
-			// Verbose progress with detailed information
-			fmt.Printf("\r🔄 [%s] %s (%.1f%%) - %s",
-				progress.Stage,
-				progress.CurrentStep,
-				progress.Progress*100,
-				progress.ElapsedTime.Round(time.Second))
+	if showOutput {
+		// Print export information
+		printf("🚀 Starting export of channel '%s'\n", channelName)
+		printf("📁 Output file: %s\n", outputFile)
+		printf("📊 Format: %s", exportFormat)
+		if exportCompress != "" && exportCompress != "none" {
+			printf(" (compressed with %s)", exportCompress)
+		}
+		printf("\n")
 
-			if progress.MessagesTotal > 0 {
-				fmt.Printf(" - %d messages", progress.MessagesTotal)
-			}
-			if progress.ThreadsTotal > 0 {
-				fmt.Printf(" - %d/%d threads", progress.ThreadsCurrent, progress.ThreadsTotal)
+		if fromDate != nil || toDate != nil {
+			printf("📅 Date range: ")
+			if fromDate != nil {
+				printf("from %s ", fromDate.Format("2006-01-02"))
 			}
-		} else {
-			// Simple progress bar
-			if progress.Stage != lastProgress.Stage {
-				fmt.Printf("\n%s %s...", getStageEmoji(progress.Stage), getStageDescription(progress.Stage))
+			if toDate != nil {
+				printf("to %s ", toDate.Format("2006-01-02"))
 			}
-
-			// Update progress bar
-			barWidth := 30
-			filled := int(progress.Progress * float64(barWidth))
-			bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
-			fmt.Printf("\r[%s] %.1f%% - %s", bar, progress.Progress*100, progress.ElapsedTime.Round(time.Second))
+			printf("\n")
 		}
 
-		lastProgress = progress
+		printf("🔧 Options: threads=%v, files=%v, reactions=%v\n",
+			exportThreads, exportFiles, exportReactions)
+		printf("\n")
+	}
+
+	// Progress goes to the terminal, every event and warning to the log
+	var bars *progressRenderer
+	logger := appLogger
+	if showOutput {
+		bars = newProgressRenderer(0, verboseOutput)
+		logger = bars.logger(appLogger)
+		exportService.SetLogger(logger)
+		slackClient.SetLogger(logger)
 	}
+	events := usecase.EventSinks{usecase.NewLogSink(logger), bars.ChannelStarted(channelName)}
+
+	// The first interrupt writes what was fetched so far, a second one quits
+	options.Resume = checkpoint
+	options.Stop = watchInterrupts()
+	defer signal.Reset(os.Interrupt, syscall.SIGTERM)
 
 	// Start export
-	result, err := exportService.ExportChannel(options, progressCallback)
+	result, err := exportService.ExportChannel(cmd.Context(), options, events)
+	defer func() { recordJob(cfg, history.ForExport("export", options, result, err), started, err) }()
 
-	// Clear progress line
-	fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
+	// Remove the progress bar; the API usage follows the result
+	if showOutput {
+		bars.ChannelFinished(channelName, err)
+		bars.Wait()
+		defer func() { printAPIUsage(slackClient.Usage()) }()
+	}
+
+	sendNotification(notifyService, []usecase.ExportNotification{usecase.NotificationFromExport(channelName, result, err)})
+
+	if exportJSON {
+		if result == nil {
+			result = &models.ExportResult{Error: err.Error()}
+		}
+		if encodeErr := outputExportResultJSON(result); encodeErr != nil {
+			return encodeErr
+		}
+	}
 
 	if err != nil {
-		fmt.Printf("❌ Export failed: %v\n", err)
+		if showOutput {
+			printf("❌ Export failed: %v\n", err)
+		}
 		return err
 	}
 
 	if !result.Success {
-		fmt.Printf("❌ Export failed: %s\n", result.Error)
+		if showOutput {
+			printf("❌ Export failed: %s\n", result.Error)
+		}
 		return fmt.Errorf("export failed: %s", result.Error)
 	}
 
+	if result.Truncated != nil && result.Truncated.Reason == models.TruncatedByInterrupt {
+		if showOutput {
+			printInterruptedExport(result)
+		}
+		return models.NewExportError(models.ErrorCategoryInterrupted, "export interrupted", nil)
+	}
+
+	if showOutput {
+		printExportSummary(result)
+	}
+
+	if checkpoint != nil {
+		removeCheckpoint(checkpoint, exportResume, result.OutputFile)
+	}
+
+	if result.Partial {
+		return models.NewExportError(models.ErrorCategoryPartialExport,
+			fmt.Sprintf("export is partial: %d warnings", len(result.Warnings)), nil)
+	}
+
+	return nil
+}
+
+// outputExportResultJSON prints the export result as JSON
+func outputExportResultJSON(result *models.ExportResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal export result to JSON: %w", err)
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+// printExportSummary prints the result and statistics of a successful export
+func printExportSummary(result *models.ExportResult) {
 	// Print success information
-	fmt.Printf("✅ Export completed successfully!\n\n")
-	fmt.Printf("📁 Output file: %s\n", result.OutputFile)
-	fmt.Printf("📏 File size: %s\n", formatFileSize(result.FileSize))
-	fmt.Printf("⏱️  Duration: %s\n\n", result.Duration.Round(time.Millisecond))
+	printf("✅ Export completed successfully!\n\n")
+	if exportLayout == usecase.LayoutSlackNative {
+		printf("📁 Output directory: %s (%d files)\n", result.OutputFile, len(result.Parts))
+	} else if len(result.Parts) > 0 {
+		printf("📁 Index file: %s (%d parts)\n", result.OutputFile, len(result.Parts))
+	} else {
+		printf("📁 Output file: %s\n", result.OutputFile)
+	}
+	if result.Manifest != "" {
+		printf("🔏 Manifest: %s\n", result.Manifest)
+	}
+	printf("📏 File size: %s\n", usecase.FormatFileSize(result.FileSize))
+	printf("⏱️  Duration: %s\n\n", result.Duration.Round(time.Millisecond))
 
 	// Print statistics
 	stats := result.Statistics
-	fmt.Printf("📊 Export Statistics:\n")
-	fmt.Printf("   Messages: %d (including %d thread replies)\n", stats.TotalMessages, stats.TotalReplies)
-	fmt.Printf("   Threads: %d\n", stats.TotalThreads)
-	fmt.Printf("   Users: %d\n", stats.TotalUsers)
-	fmt.Printf("   Attachments: %d\n", stats.TotalAttachments)
-	fmt.Printf("   Files: %d\n", stats.TotalFiles)
-	fmt.Printf("   Reactions: %d\n", stats.TotalReactions)
-
-	if len(stats.TopReactions) > 0 {
-		fmt.Printf("\n🎭 Top Reactions:\n")
-		for i, reaction := range stats.TopReactions {
-			if i >= 5 { // Show top 5
-				break
-			}
-			fmt.Printf("   %s: %d\n", reaction.Name, reaction.Count)
+	printStatistics(stats)
+
+	if truncated := result.Truncated; truncated != nil && truncated.Reason != models.TruncatedByInterrupt {
+		printf("\n✂️  Export stopped at --%s %s", strings.ReplaceAll(truncated.Reason, "_", "-"), truncated.Limit)
+		if oldest, err := models.ParseSlackTimestamp(truncated.OldestMessage); err == nil && !oldest.IsZero() {
+			printf("; oldest message from %s", oldest.In(displayLocation).Format("2006-01-02 15:04"))
 		}
+		if truncated.ThreadsSkipped > 0 {
+			printf("; %d threads without replies", truncated.ThreadsSkipped)
+		}
+		printf("\n")
 	}
 
-	if exportVerbose {
-		fmt.Printf("\n⏱️  Processing Times:\n")
-		fmt.Printf("   Channel fetch: %s\n", stats.ProcessingTime.ChannelFetch.Round(time.Millisecond))
-		fmt.Printf("   Message fetch: %s\n", stats.ProcessingTime.MessageFetch.Round(time.Millisecond))
-		fmt.Printf("   Thread fetch: %s\n", stats.ProcessingTime.ThreadFetch.Round(time.Millisecond))
-		fmt.Printf("   User fetch: %s\n", stats.ProcessingTime.UserFetch.Round(time.Millisecond))
-		fmt.Printf("   Data processing: %s\n", stats.ProcessingTime.DataProcessing.Round(time.Millisecond))
-		fmt.Printf("   File generation: %s\n", stats.ProcessingTime.FileGeneration.Round(time.Millisecond))
+	if result.Partial {
+		printf("\n⚠️  Partial export (%d warnings):\n", len(result.Warnings))
+	} else if len(result.Warnings) > 0 {
+		printf("\n⚠️  Export notes (%d):\n", len(result.Warnings))
+	}
+	for _, warning := range result.Warnings {
+		printf("   %s\n", warning)
 	}
 
-	return nil
+	printTopReactions(stats)
+	printFileUsage(stats, nil)
+
+	if verboseOutput {
+		printf("\n⏱️  Processing Times:\n")
+		printf("   Channel fetch: %s\n", stats.ProcessingTime.ChannelFetch.Round(time.Millisecond))
+		printf("   Message fetch: %s\n", stats.ProcessingTime.MessageFetch.Round(time.Millisecond))
+		printf("   Thread fetch: %s\n", stats.ProcessingTime.ThreadFetch.Round(time.Millisecond))
+		printf("   User fetch: %s\n", stats.ProcessingTime.UserFetch.Round(time.Millisecond))
+		printf("   Data processing: %s\n", stats.ProcessingTime.DataProcessing.Round(time.Millisecond))
+		printf("   File generation: %s\n", stats.ProcessingTime.FileGeneration.Round(time.Millisecond))
+	}
+}
+
+// printStatistics prints the message, thread and content counts of an export
+func printStatistics(stats models.ExportStatistics) {
+	printf("📊 Export Statistics:\n")
+	printf("   Messages: %d (including %d thread replies)\n", stats.TotalMessages, stats.TotalReplies)
+	printf("   Threads: %d\n", stats.TotalThreads)
+	printf("   Users: %d\n", stats.TotalUsers)
+	printf("   Attachments: %d\n", stats.TotalAttachments)
+	printf("   Files: %d\n", stats.TotalFiles)
+	printf("   Reactions: %d\n", stats.TotalReactions)
+	if mentions := stats.Mentions; mentions != nil {
+		printf("   Mentions: %d users, %d channels, %d @here/@channel/@everyone\n",
+			mentions.UserMentions, mentions.ChannelReferences, mentions.Broadcasts)
+	}
+	printThreadActivity(stats.ThreadActivity)
+}
+
+// printTopReactions prints the five most used reactions
+func printTopReactions(stats models.ExportStatistics) {
+	if len(stats.TopReactions) == 0 {
+		return
+	}
+	printf("\n🎭 Top Reactions:\n")
+	for i, reaction := range stats.TopReactions {
+		if i >= 5 { // Show top 5
+			break
+		}
+		printf("   %s: %d\n", reaction.Name, reaction.Count)
+	}
+}
+
+// printFileUsage prints the storage used by shared files per type and, when
+// users is given to name them, per sharer. The export summary shows the five
+// largest types; verbose output and 'slacker stats' show all of them.
+func printFileUsage(stats models.ExportStatistics, users map[string]models.ExportUser) {
+	if len(stats.FileTypes) == 0 {
+		return
+	}
+	files := 0
+	for _, fileType := range stats.FileTypes {
+		files += fileType.Count
+	}
+	printf("\n💾 File Storage: %s in %d files\n", usecase.FormatFileSize(stats.TotalFileBytes), files)
+	for i, fileType := range stats.FileTypes {
+		if i >= 5 && users == nil && !verboseOutput {
+			printf("   ... %d more types\n", len(stats.FileTypes)-i)
+			break
+		}
+		printf("   %-12s %5d files %10s\n", fileType.Filetype, fileType.Count, usecase.FormatFileSize(fileType.Bytes))
+	}
+	if users == nil || len(stats.TopFileSharers) == 0 {
+		return
+	}
+	printf("\n📤 Top File Sharers:\n")
+	for _, sharer := range stats.TopFileSharers {
+		name := sharer.User
+		if user, ok := users[sharer.User]; ok && user.Name != "" {
+			name = "@" + user.Name
+		}
+		printf("   %-20s %5d files %10s\n", name, sharer.Files, usecase.FormatFileSize(sharer.Bytes))
+	}
+}
+
+// printThreadActivity prints how messages were answered in threads
+func printThreadActivity(activity *models.ThreadStats) {
+	if activity == nil || (activity.Threads == 0 && activity.Unanswered == 0) {
+		return
+	}
+	printf("\n💬 Thread Activity:\n")
+	printf("   Answered threads: %d of %d\n", activity.Answered, activity.Threads)
+	printf("   Unanswered messages: %d\n", activity.Unanswered)
+	if activity.Answered > 0 {
+		first := activity.FirstReply
+		printf("   Time to first reply: median %s, p90 %s, max %s\n",
+			roundDuration(first.Median), roundDuration(first.P90), roundDuration(first.Max))
+	}
+	if activity.Threads > 0 {
+		duration := activity.Duration
+		printf("   Thread duration: median %s, p90 %s, max %s\n",
+			roundDuration(duration.Median), roundDuration(duration.P90), roundDuration(duration.Max))
+	}
+	if verboseOutput && len(activity.Participants) > 0 {
+		counts := make([]int, 0, len(activity.Participants))
+		for count := range activity.Participants {
+			counts = append(counts, count)
+		}
+		sort.Ints(counts)
+		printf("   Threads by participants:\n")
+		for _, count := range counts {
+			printf("      %d: %d\n", count, activity.Participants[count])
+		}
+	}
+}
+
+// roundDuration rounds d for display: to seconds below an hour, to minutes
+// above
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Hour {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Minute)
+}
+
+// watchInterrupts returns a channel that is closed on the first SIGINT or
+// SIGTERM. A second signal exits immediately.
+func watchInterrupts() <-chan struct{} {
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		eprintf("\n⏸️  Interrupted: saving the messages fetched so far (interrupt again to quit immediately)\n")
+		close(stop)
+		<-signals
+		os.Exit(models.ExitCodeInterrupted)
+	}()
+	return stop
+}
+
+// printInterruptedExport tells where an interrupted export left its data and
+// how to continue it
+func printInterruptedExport(result *models.ExportResult) {
+	printf("⏸️  Export interrupted after %d messages\n\n", result.Statistics.TotalMessages)
+	printf("📁 Partial export: %s\n", result.OutputFile)
+	if result.Checkpoint == "" {
+		return
+	}
+	printf("💾 Checkpoint: %s\n", result.Checkpoint)
+	printf("🔁 Resume with: %s\n", resumeCommand(result.Checkpoint))
+}
+
+// resumeCommand returns the command line that continues this export from
+// checkpoint
+func resumeCommand(checkpoint string) string {
+	return jobCommandLine(append(dropFlag(os.Args[1:], "--resume"), "--resume", checkpoint))
+}
+
+// shellQuote quotes s for a POSIX shell when it needs it
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?[]{}()<>|&;#~!") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// removeCheckpoint deletes the partial export and checkpoint of an export
+// that has now completed
+func removeCheckpoint(checkpoint *models.ExportCheckpoint, path, outputFile string) {
+	if checkpoint.PartialFile != "" && checkpoint.PartialFile != outputFile && usecase.IsLocalOutput(checkpoint.PartialFile) {
+		if err := os.Remove(checkpoint.PartialFile); err != nil && !os.IsNotExist(err) {
+			appLogger.Warn("failed to remove partial export", "file", checkpoint.PartialFile, "error", err)
+		}
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		appLogger.Warn("failed to remove checkpoint", "file", path, "error", err)
+	}
 }
 
-// parseDate parses date strings in various formats
+// includeContent resolves the --<name> and --no-<name> flags of cmd. A flag
+// given on the command line wins; otherwise the configured value applies.
+func includeContent(cmd *cobra.Command, name string, configured bool) (bool, error) {
+	flags := cmd.Flags()
+	include, exclude := flags.Changed(name), flags.Changed("no-"+name)
+	switch {
+	case include && exclude:
+		return false, fmt.Errorf("--%s and --no-%s cannot be used together", name, name)
+	case include:
+		return flags.GetBool(name)
+	case exclude:
+		skip, err := flags.GetBool("no-" + name)
+		return !skip, err
+	}
+	return configured, nil
+}
+
+// parseDate parses date strings in various formats. Dates without an offset
+// are read in the --tz zone, or UTC when no timezone is configured.
 func parseDate(dateStr string) (time.Time, error) {
+	// Dates without a zone are read in dateLocation; RFC 3339 times carry
+	// their own offset (or Z) and are kept as given
+	if t, err := time.Parse(time.RFC3339, dateStr); err == nil {
+		return t, nil
+	}
 	formats := []string{
 		"2006-01-02",
 		"2006-01-02 15:04:05",
 		"2006-01-02T15:04:05",
-		"2006-01-02T15:04:05Z",
-		"2006-01-02T15:04:05-07:00",
 	}
 
 	for _, format := range formats {
-		if t, err := time.Parse(format, dateStr); err == nil {
+		if t, err := time.ParseInLocation(format, dateStr, dateLocation); err == nil {
 			return t, nil
 		}
 	}
@@ -317,6 +1069,16 @@ func parseDate(dateStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse date '%s'. Supported formats: YYYY-MM-DD, YYYY-MM-DD HH:MM:SS", dateStr)
 }
 
+// formatETA describes the estimated time left, or nothing while there is no
+// estimate
+func formatETA(progress models.ExportProgress) string {
+	remaining, ok := progress.Remaining()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" - ETA %s", remaining.Round(time.Second))
+}
+
 // getStageEmoji returns an emoji for each export stage
 func getStageEmoji(stage string) string {
 	switch stage {
@@ -365,16 +1127,19 @@ func getStageDescription(stage string) string {
 	}
 }
 
-// formatFileSize formats file size in human-readable format
-func formatFileSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
+// parseOutputHeaders parses --output-header values of the form 'Name: value'
+func parseOutputHeaders(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
 	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+	headers := make(map[string]string, len(values))
+	for _, value := range values {
+		name, content, ok := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid --output-header '%s': use 'Name: value'", value)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(content)
 	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+	return headers, nil
 }
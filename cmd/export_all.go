@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/history"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// defaultRateLimit is the Slack API budget, in requests per minute, shared by
+// channels exported in parallel
+const defaultRateLimit = 100
+
+// defaultParallelChannels is how many channels export-all exports at once
+// when neither --parallel-channels nor export.concurrency is set
+const defaultParallelChannels = 4
+
+// exportAllCmd represents the export-all command
+var exportAllCmd = &cobra.Command{
+	Use:   "export-all",
+	Short: "Export every channel the token can read with a pool of workers",
+	Long: `Export all channels the token is a member of into one directory. Channels are
+queued and exported --parallel-channels at a time (default: export.concurrency or
+4); all of them share one Slack client and one token-bucket request budget
+(--rate-limit), so exporting more channels at once does not exceed Slack's rate
+limits.
+
+Every run has an ID and records its progress in <output-dir>/.slacker-runs/<id>.json,
+which doubles as the run report. An interrupted or partly failed run can be
+resumed: channels that are already done are skipped and failed ones are retried.
+
+Examples:
+  slacker export-all --output-dir ./archive --parallel-channels 8 --rate-limit 120
+  slacker export-all --output-dir ./archive --exclude random,social --format json-compact --compress gzip
+  slacker export-all --output-dir ./archive --resume-run 20240115-103000`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runExportAll(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	exportAllOutputDir string
+	exportAllFormat    string
+	exportAllCompress  string
+	exportAllThreads   bool
+	exportAllArchived  bool
+	exportAllExclude   []string
+	exportAllWorkers   int
+	exportAllRateLimit int
+	exportAllResume    string
+)
+
+func init() {
+	rootCmd.AddCommand(exportAllCmd)
+
+	exportAllCmd.Flags().StringVarP(&exportAllOutputDir, "output-dir", "o", "", "Directory for the export files and run state (default: export.default_output_dir or .)")
+	exportAllCmd.Flags().StringVarP(&exportAllFormat, "format", "f", "json", "Output format: json, json-pretty, json-compact, pdf, llm-jsonl")
+	exportAllCmd.Flags().StringVar(&exportAllCompress, "compress", "", "Compression: none, gzip")
+	exportAllCmd.Flags().BoolVar(&exportAllThreads, "threads", true, "Include thread replies")
+	exportAllCmd.Flags().BoolVar(&exportAllArchived, "include-archived", false, "Also export archived channels")
+	exportAllCmd.Flags().StringSliceVar(&exportAllExclude, "exclude", nil, "Channel names to skip (repeatable)")
+	exportAllCmd.Flags().IntVar(&exportAllWorkers, "parallel-channels", 0, fmt.Sprintf("Channels exported in parallel (default: export.concurrency or %d)", defaultParallelChannels))
+	exportAllCmd.Flags().IntVar(&exportAllWorkers, "workers", 0, "Channels exported in parallel")
+	exportAllCmd.Flags().MarkDeprecated("workers", "use --parallel-channels instead")
+	exportAllCmd.Flags().IntVar(&exportAllRateLimit, "rate-limit", defaultRateLimit, "Slack API requests per minute shared by all parallel channels (0 = only honor Retry-After)")
+	exportAllCmd.Flags().StringVar(&exportAllResume, "resume-run", "", "Resume the run with this ID instead of starting a new one")
+
+	addTelemetryFlags(exportAllCmd)
+
+	registerChannelCompletion(exportAllCmd, "exclude")
+	registerValueCompletion(exportAllCmd, "format", "json", "json-pretty", "json-compact", "pdf", "llm-jsonl")
+	registerValueCompletion(exportAllCmd, "compress", "none", "gzip")
+}
+
+func runExportAll(cmd *cobra.Command) error {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+
+	if exportAllWorkers < 0 {
+		return fmt.Errorf("--parallel-channels must not be negative")
+	}
+	workers := exportAllWorkers
+	if workers == 0 {
+		workers = cfg.Export.Concurrency
+	}
+	if workers == 0 {
+		workers = defaultParallelChannels
+	}
+	if exportAllRateLimit < 0 {
+		return fmt.Errorf("--rate-limit must not be negative")
+	}
+	outputDir := exportAllOutputDir
+	if outputDir == "" {
+		outputDir = cfg.Export.DefaultOutputDir
+	}
+	if outputDir == "" {
+		outputDir = "."
+	}
+
+	startTelemetry()
+	defer flushTelemetry()
+
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+	slackClient.SetRateLimit(exportAllRateLimit)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var run *usecase.ExportRun
+	if exportAllResume != "" {
+		if run, err = usecase.LoadExportRun(outputDir, exportAllResume); err != nil {
+			return err
+		}
+		done, failed, pending := run.Counts()
+		printf("🔁 Resuming run %s (%d done, %d failed, %d pending)\n", run.ID, done, failed, pending)
+	} else {
+		validFormats := map[string]bool{"json": true, "json-pretty": true, "json-compact": true, "pdf": true, usecase.FormatLLMJSONL: true}
+		if !validFormats[exportAllFormat] {
+			return fmt.Errorf("invalid format '%s'. Valid formats: json, json-pretty, json-compact, pdf, llm-jsonl", exportAllFormat)
+		}
+		if exportAllCompress != "" && exportAllCompress != "none" && exportAllCompress != "gzip" {
+			return fmt.Errorf("invalid compression '%s'. Valid compressions: none, gzip", exportAllCompress)
+		}
+
+		channels, err := slackClient.GetChannels(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get channels: %w", err)
+		}
+		channels = selectExportAllChannels(channels, exportAllArchived, exportAllExclude)
+		if len(channels) == 0 {
+			return fmt.Errorf("no channels to export")
+		}
+
+		if run, err = usecase.NewExportRun(channels, outputDir, exportAllFormat, time.Now()); err != nil {
+			return err
+		}
+		run.Compression = exportAllCompress
+		run.IncludeThreads = exportAllThreads
+		run.SubtypePolicy = cfg.Export.SubtypePolicy
+		printf("📦 Starting run %s (%d channels)\n", run.ID, len(run.Channels))
+	}
+	printf("📁 Destination: %s\n", run.OutputDir)
+	printf("👷 Parallel channels: %d\n\n", workers)
+
+	service := usecase.NewExportAllService(slackClient, getVersion())
+	service.SetLogger(appLogger)
+	service.SetWorkspaceClient(slackClient)
+	service.SetBotClient(slackClient)
+	service.SetPaging(apiConfig.PageSize, apiConfig.ThreadDelay)
+	service.SetLocation(displayLocation)
+	for _, hook := range exportHooks(cfg) {
+		service.AddHook(hook)
+	}
+	auditLog, err := openAuditLog(cfg, slackClient)
+	if err != nil {
+		return err
+	}
+	service.SetAuditLog(auditLog)
+
+	// A bar per channel being exported and one for the run; results and
+	// log records are printed above them
+	_, _, pending := run.Counts()
+	bars := newProgressRenderer(pending, verboseOutput)
+	if bars != nil {
+		service.SetChannelEvents(bars)
+		logger := bars.logger(appLogger)
+		slackClient.SetLogger(logger)
+		service.SetLogger(logger)
+	}
+
+	start := time.Now()
+	messages := 0
+	err = service.Run(ctx, run, workers, func(channel usecase.RunChannel) {
+		messages += channel.Messages
+		if channel.Status == usecase.RunStatusFailed {
+			bars.printf("❌ #%s: %s\n", channel.Channel, channel.Error)
+			return
+		}
+		bars.printf("✅ #%s: %d messages, %s -> %s\n",
+			channel.Channel, channel.Messages, usecase.FormatFileSize(channel.FileSize), channel.OutputFile)
+	})
+	bars.Wait()
+
+	done, failed, pending := run.Counts()
+	printf("\n📊 Run %s: %d done, %d failed, %d pending\n", run.ID, done, failed, pending)
+	printf("📄 Report: %s\n", run.Path())
+	printf("⏱️  Duration: %s\n", time.Since(start).Round(time.Millisecond))
+	printAPIUsage(slackClient.Usage())
+	if failed > 0 || pending > 0 {
+		printf("🔁 Resume with: slacker export-all --output-dir %s --resume-run %s\n", run.OutputDir, run.ID)
+	}
+
+	if err == nil && failed > 0 {
+		err = backupFailure(run.Results(), failed)
+	}
+	recordJob(cfg, &history.Job{Command: "export-all", Output: run.OutputDir, Messages: messages, Success: true}, start, err)
+	return err
+}
+
+// selectExportAllChannels drops archived channels, unless includeArchived is
+// set, and the excluded channel names
+func selectExportAllChannels(channels []models.Channel, includeArchived bool, exclude []string) []models.Channel {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[strings.TrimPrefix(name, "#")] = true
+	}
+
+	var selected []models.Channel
+	for _, channel := range channels {
+		if (channel.IsArchived && !includeArchived) || skip[channel.Name] {
+			continue
+		}
+		selected = append(selected, channel)
+	}
+	return selected
+}
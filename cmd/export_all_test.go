@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestSelectExportAllChannels(t *testing.T) {
+	channels := []models.Channel{
+		{ID: "C1", Name: "general"},
+		{ID: "C2", Name: "random"},
+		{ID: "C3", Name: "old-project", IsArchived: true},
+	}
+
+	selected := selectExportAllChannels(channels, false, []string{"#random"})
+	if len(selected) != 1 || selected[0].Name != "general" {
+		t.Errorf("Expected only #general, got %v", selected)
+	}
+
+	selected = selectExportAllChannels(channels, true, nil)
+	if len(selected) != 3 {
+		t.Errorf("Expected archived channels to be included, got %v", selected)
+	}
+}
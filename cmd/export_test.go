@@ -3,6 +3,8 @@ package cmd
 import (
 	"testing"
 	"time"
+
+	"github.com/spf13/cobra"
 )
 
 func TestParseDate(t *testing.T) {
@@ -71,6 +73,34 @@ func TestParseDate(t *testing.T) {
 	}
 }
 
+func TestParseDateTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	defer func(previous *time.Location) { dateLocation = previous }(dateLocation)
+	dateLocation = loc
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2024-01-15", time.Date(2024, 1, 15, 0, 0, 0, 0, loc)},
+		{"2024-01-15T10:00:00", time.Date(2024, 1, 15, 10, 0, 0, 0, loc)},
+		{"2024-01-15T10:00:00Z", time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{"2024-01-15T10:00:00+02:00", time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		result, err := parseDate(tt.input)
+		if err != nil {
+			t.Fatalf("Unexpected error for input '%s': %v", tt.input, err)
+		}
+		if !result.Equal(tt.expected) {
+			t.Errorf("Expected %s to be %v, got %v", tt.input, tt.expected, result)
+		}
+	}
+}
+
 func TestGetStageEmoji(t *testing.T) {
 	tests := []struct {
 		stage    string
@@ -123,55 +153,65 @@ func TestGetStageDescription(t *testing.T) {
 	}
 }
 
-func TestFormatFileSize(t *testing.T) {
+func TestIncludeContent(t *testing.T) {
 	tests := []struct {
-		name     string
-		bytes    int64
-		expected string
+		name       string
+		args       []string
+		configured bool
+		expected   bool
+		hasError   bool
 	}{
-		{
-			name:     "Bytes",
-			bytes:    512,
-			expected: "512 B",
-		},
-		{
-			name:     "Kilobytes",
-			bytes:    1536, // 1.5 KB
-			expected: "1.5 KB",
-		},
-		{
-			name:     "Megabytes",
-			bytes:    2097152, // 2 MB
-			expected: "2.0 MB",
-		},
-		{
-			name:     "Gigabytes",
-			bytes:    3221225472, // 3 GB
-			expected: "3.0 GB",
-		},
-		{
-			name:     "Zero bytes",
-			bytes:    0,
-			expected: "0 B",
-		},
-		{
-			name:     "One byte",
-			bytes:    1,
-			expected: "1 B",
-		},
-		{
-			name:     "Exactly 1 KB",
-			bytes:    1024,
-			expected: "1.0 KB",
-		},
+		{name: "Default", args: nil, configured: true, expected: true},
+		{name: "Config disables", args: nil, configured: false, expected: false},
+		{name: "Flag overrides config", args: []string{"--threads"}, configured: false, expected: true},
+		{name: "Explicit false flag", args: []string{"--threads=false"}, configured: true, expected: false},
+		{name: "Negative flag overrides config", args: []string{"--no-threads"}, configured: true, expected: false},
+		{name: "Negative flag set to false", args: []string{"--no-threads=false"}, configured: false, expected: true},
+		{name: "Both flags", args: []string{"--threads", "--no-threads"}, configured: true, hasError: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatFileSize(tt.bytes)
+			cmd := &cobra.Command{Use: "export"}
+			var include, exclude bool
+			cmd.Flags().BoolVar(&include, "threads", true, "")
+			cmd.Flags().BoolVar(&exclude, "no-threads", false, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("Failed to parse flags: %v", err)
+			}
+
+			result, err := includeContent(cmd, "threads", tt.configured)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("Expected error for %v, but got none", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
 			if result != tt.expected {
-				t.Errorf("Expected '%s' for %d bytes, got '%s'", tt.expected, tt.bytes, result)
+				t.Errorf("Expected %v for %v with config %v, got %v", tt.expected, tt.args, tt.configured, result)
 			}
 		})
 	}
 }
+
+func TestParseOutputHeaders(t *testing.T) {
+	headers, err := parseOutputHeaders([]string{"authorization: Bearer a:b", "X-Upload-Tag:  nightly "})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if headers["Authorization"] != "Bearer a:b" || headers["X-Upload-Tag"] != "nightly" || len(headers) != 2 {
+		t.Errorf("Unexpected headers %v", headers)
+	}
+
+	for _, value := range []string{"Authorization", ": value", "Bad Name: value"} {
+		if _, err := parseOutputHeaders([]string{value}); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+	if headers, err := parseOutputHeaders(nil); headers != nil || err != nil {
+		t.Errorf("Expected no headers without the flag, got %v, %v", headers, err)
+	}
+}
@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+)
+
+// filesCmd represents the files command
+var filesCmd = &cobra.Command{
+	Use:   "files",
+	Short: "List and download the files shared in a channel",
+	Long: `List and bulk-download the files shared in a channel using files.list,
+without exporting its messages. The token needs the files:read scope.
+
+--type takes files.list types (image, pdf, snippet, zip, gdoc, canvas, post)
+or file types such as png or mp4. --from and --to take a date or a period
+such as 30d, 4w or 12h before now.
+
+Examples:
+  slacker files list --channel general --type image --from 30d
+  slacker files list --channel general --min-size 10MB --format csv
+  slacker files download --channel general --type pdf --dir general-pdfs`,
+}
+
+// filesListCmd represents the files list command
+var filesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the files shared in a channel",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runFilesList(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// filesDownloadCmd represents the files download command
+var filesDownloadCmd = &cobra.Command{
+	Use:   "download",
+	Short: "Download the files shared in a channel",
+	Long: `Download the files shared in a channel that match the filters into a
+directory (default: <channel>-files).
+
+The directory keeps a download queue in .slacker-downloads.json. Rerunning the
+command after an interruption skips the files that are done, retries the
+ones that failed and continues cut-off downloads from their .part files.
+
+Examples:
+  slacker files download --channel general
+  slacker files download --channel general --type image --from 30d --dir photos`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runFilesDownload(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	filesChannel   string
+	filesChannelID string
+	filesTypes     []string
+	filesFrom      string
+	filesTo        string
+	filesMinSize   string
+	filesMaxSize   string
+	filesFormat    string
+	filesOutput    string
+	filesDir       string
+)
+
+func init() {
+	rootCmd.AddCommand(filesCmd)
+	filesCmd.AddCommand(filesListCmd)
+	filesCmd.AddCommand(filesDownloadCmd)
+
+	for _, cmd := range []*cobra.Command{filesListCmd, filesDownloadCmd} {
+		cmd.Flags().StringVarP(&filesChannel, "channel", "c", "", "Channel name")
+		cmd.Flags().StringVar(&filesChannelID, "channel-id", "", "Channel ID (alternative to --channel)")
+		cmd.Flags().StringSliceVarP(&filesTypes, "type", "t", nil, "File type to include, e.g. image, pdf or png (repeatable; default: all)")
+		cmd.Flags().StringVar(&filesFrom, "from", "", "Only files uploaded on or after this date or within this period (YYYY-MM-DD or e.g. 30d)")
+		cmd.Flags().StringVar(&filesTo, "to", "", "Only files uploaded on or before this date, or before this period (YYYY-MM-DD or e.g. 7d)")
+		cmd.Flags().StringVar(&filesMinSize, "min-size", "", "Only files of at least this size, e.g. 500KB or 10MB")
+		cmd.Flags().StringVar(&filesMaxSize, "max-size", "", "Only files of at most this size, e.g. 500KB or 10MB")
+		registerChannelCompletion(cmd, "channel")
+		registerValueCompletion(cmd, "type", "image", "pdf", "snippet", "zip", "gdoc", "canvas", "post")
+	}
+	filesListCmd.Flags().StringVarP(&filesFormat, "format", "f", "table", "Output format: table, json, csv")
+	filesListCmd.Flags().StringVarP(&filesOutput, "output", "o", "", "Write the list to this file instead of stdout (- for stdout)")
+	filesDownloadCmd.Flags().StringVarP(&filesDir, "dir", "d", "", "Directory to download into (default: <channel>-files)")
+
+	registerValueCompletion(filesListCmd, "format", "table", "json", "csv")
+}
+
+// filesSelection resolves the channel and filters shared by the files
+// subcommands
+func filesSelection(ctx context.Context, slackClient *api.SlackClient) (usecase.FileListOptions, string, error) {
+	var opts usecase.FileListOptions
+	var err error
+	if opts.From, err = parseFileTime(filesFrom); err != nil {
+		return opts, "", fmt.Errorf("invalid --from '%s': %w", filesFrom, err)
+	}
+	if opts.To, err = parseFileTime(filesTo); err != nil {
+		return opts, "", fmt.Errorf("invalid --to '%s': %w", filesTo, err)
+	}
+	if opts.MinSize, err = parseByteSize(filesMinSize); err != nil {
+		return opts, "", fmt.Errorf("invalid --min-size '%s': %w", filesMinSize, err)
+	}
+	if opts.MaxSize, err = parseByteSize(filesMaxSize); err != nil {
+		return opts, "", fmt.Errorf("invalid --max-size '%s': %w", filesMaxSize, err)
+	}
+	opts.Types = filesTypes
+
+	channelID, channelName := filesChannelID, filesChannel
+	switch {
+	case channelID == "" && channelName == "":
+		channel, err := pickChannel(ctx, slackClient, "either --channel or --channel-id must be specified")
+		if err != nil {
+			return opts, "", err
+		}
+		channelID, channelName = channel.ID, channel.Name
+	case channelID == "":
+		channel, err := slackClient.GetChannelByName(ctx, channelName)
+		if err != nil {
+			return opts, "", fmt.Errorf("failed to find channel '%s': %w", channelName, err)
+		}
+		channelID, channelName = channel.ID, channel.Name
+	}
+	if channelName == "" {
+		channelName = channelID
+	}
+	opts.ChannelID = channelID
+	return opts, channelName, nil
+}
+
+func runFilesList(cmd *cobra.Command) error {
+	switch filesFormat {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("invalid format '%s'. Valid formats: table, json, csv", filesFormat)
+	}
+
+	token, err := selectToken(config.NewManager(), models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+
+	opts, channelName, err := filesSelection(cmd.Context(), slackClient)
+	if err != nil {
+		return err
+	}
+	service := usecase.NewFileService(slackClient)
+	service.SetLogger(appLogger)
+	files, err := service.ListFiles(cmd.Context(), opts)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if !toStdout(filesOutput) {
+		file, err := os.Create(filesOutput)
+		if err != nil {
+			return models.NewExportError(models.ErrorCategoryIO, "failed to create output file", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch filesFormat {
+	case "json":
+		data, err := json.MarshalIndent(files, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(append(data, '\n'))
+		return err
+	case "csv":
+		return usecase.WriteFilesCSV(out, files)
+	}
+
+	var total int64
+	for _, file := range files {
+		total += int64(file.Size)
+	}
+	fprintf(out, "📎 %d file(s) in #%s, %s\n\n", len(files), channelName, usecase.FormatFileSize(total))
+	for _, file := range files {
+		fprintf(out, "  %s  %9s  %-8s %s\n", file.Timestamp.Format("2006-01-02 15:04"), usecase.FormatFileSize(int64(file.Size)), file.Filetype, file.Name)
+	}
+	return nil
+}
+
+func runFilesDownload(cmd *cobra.Command) error {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+	auditLog, err := openAuditLog(cfg, slackClient)
+	if err != nil {
+		return err
+	}
+
+	// Stop after the current file on Ctrl+C; the queue resumes the rest
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts, channelName, err := filesSelection(ctx, slackClient)
+	if err != nil {
+		return err
+	}
+	dir := filesDir
+	if dir == "" {
+		dir = usecase.SafeFileName(channelName) + "-files"
+	}
+
+	service := usecase.NewFileService(slackClient)
+	service.SetLogger(appLogger)
+	files, err := service.ListFiles(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	printf("⬇️  Downloading %d file(s) from #%s to %s...\n", len(files), channelName, dir)
+	result, err := service.Download(ctx, dir, opts.ChannelID, files, func(file usecase.QueuedFile) {
+		switch file.DownloadStatus {
+		case usecase.DownloadDone:
+			printf("  ✅ %s (%s)\n", file.Path, usecase.FormatFileSize(int64(file.Size)))
+		case usecase.DownloadFailed:
+			printf("  ❌ %s: %s\n", file.Path, file.Error)
+		case usecase.DownloadSkipped:
+			printf("  ⏭️  %s: external file, skipped\n", file.Path)
+		}
+	})
+
+	entry := audit.Entry{
+		Action:    audit.ActionFiles,
+		ChannelID: opts.ChannelID,
+		Channel:   channelName,
+		From:      opts.From,
+		To:        opts.To,
+		Output:    dir,
+		Success:   err == nil && result != nil && result.Failed == 0,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if ctx.Err() != nil {
+		printf("⏸️  Download interrupted; run the command again to resume\n")
+		return recordAudit(auditLog, entry, nil)
+	}
+	if err := recordAudit(auditLog, entry, err); err != nil {
+		return err
+	}
+	printf("📁 %d downloaded (%s, %d resumed), %d already present, %d skipped, %d failed\n",
+		result.Downloaded, usecase.FormatFileSize(result.Bytes), result.Resumed, result.Existing, result.Skipped, result.Failed)
+	if result.Failed > 0 {
+		return fmt.Errorf("%d file(s) failed to download; run the command again to retry", result.Failed)
+	}
+	return nil
+}
+
+// parseFileTime parses a --from or --to value: a date, or a period such as
+// 30d that counts back from now. Empty means no bound.
+func parseFileTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if date, err := parseDate(value); err == nil {
+		return &date, nil
+	}
+	period, err := parsePeriod(value)
+	if err != nil {
+		return nil, fmt.Errorf("expected a date (YYYY-MM-DD) or a period such as 30d, 4w or 12h")
+	}
+	since := time.Now().Add(-period)
+	return &since, nil
+}
+
+// parseByteSize parses a size such as 500, 500KB, 10MB or 1.5GB (powers of
+// 1024). Empty means no bound.
+func parseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+	number, unit := value, int64(1)
+	for i, suffix := range []string{"KB", "MB", "GB", "TB"} {
+		if cut, ok := strings.CutSuffix(value, suffix); ok {
+			number, unit = cut, int64(1)<<(10*(i+1))
+			break
+		}
+	}
+	number = strings.TrimSpace(strings.TrimSuffix(number, "B"))
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("expected a size such as 500KB or 10MB")
+	}
+	return int64(size * float64(unit)), nil
+}
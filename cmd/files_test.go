@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"", 0},
+		{"500", 500},
+		{"500B", 500},
+		{"10kb", 10 << 10},
+		{"10MB", 10 << 20},
+		{"1.5GB", 3 << 29},
+	}
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.value)
+		if err != nil {
+			t.Errorf("parseByteSize(%q): unexpected error %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseByteSize(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"MB", "-1KB", "ten"} {
+		if _, err := parseByteSize(value); err == nil {
+			t.Errorf("parseByteSize(%q): expected an error", value)
+		}
+	}
+}
+
+func TestParseFileTime(t *testing.T) {
+	date, err := parseFileTime("2024-01-15")
+	if err != nil || date.Format("2006-01-02") != "2024-01-15" {
+		t.Errorf("Expected the date, got %v, %v", date, err)
+	}
+	since, err := parseFileTime("30d")
+	if err != nil || time.Since(*since) < 30*24*time.Hour-time.Minute {
+		t.Errorf("Expected 30 days ago, got %v, %v", since, err)
+	}
+	if none, err := parseFileTime(""); none != nil || err != nil {
+		t.Errorf("Expected no bound, got %v, %v", none, err)
+	}
+	if _, err := parseFileTime("last month"); err == nil {
+		t.Error("Expected an error")
+	}
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+)
+
+// exportHooks returns the export hooks configured in the hooks section
+func exportHooks(cfg *config.Config) []usecase.ExportHook {
+	hooks := cfg.Hooks
+	if hooks.OnMessageFetched == "" && hooks.OnBeforeWrite == "" && hooks.OnComplete == "" {
+		return nil
+	}
+	return []usecase.ExportHook{&usecase.ShellHook{
+		MessageFetched: hooks.OnMessageFetched,
+		BeforeWrite:    hooks.OnBeforeWrite,
+		Complete:       hooks.OnComplete,
+		Timeout:        hooks.Timeout,
+	}}
+}
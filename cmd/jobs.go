@@ -0,0 +1,542 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/history"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// jobsCmd represents the jobs command
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "List and re-run past exports",
+	Long: `Every run of 'slacker export' and 'slacker export-all' is recorded in
+~/.slacker/history with its command line, settings, duration and result.
+history.keep sets how many jobs are kept (default 200); history.disabled turns
+recording off.
+
+Examples:
+  slacker jobs list                    # Show recent exports
+  slacker jobs show 20240115-103000    # Details of one export
+  slacker jobs rerun 20240115-103000   # Run it again with the same settings`,
+}
+
+// jobsListCmd represents the jobs list command
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded export jobs, newest first",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runJobsList(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// jobsShowCmd represents the jobs show command
+var jobsShowCmd = &cobra.Command{
+	Use:               "show <id>",
+	Short:             "Show the settings and result of an export job",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeJobIDs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runJobsShow(args[0]); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// jobsRerunCmd represents the jobs rerun command
+var jobsRerunCmd = &cobra.Command{
+	Use:   "rerun <id> [-- flags...]",
+	Short: "Run an export job again with identical settings",
+	Long: `Run a recorded job again, in the directory it ran in. An export runs with
+the settings it resolved, including those that came from the config file, so
+a changed config file does not change it; other jobs run their recorded
+command line.
+
+Secrets such as --notify-webhook and URL passwords are not recorded. Their
+flags are left out of the re-run; pass them again after --:
+
+  slacker jobs rerun 20240115-103000 -- --notify-webhook https://hooks.example.com/slacker
+
+The exit code is that of the re-run export.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeJobIDs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runJobsRerun(args[0], args[1:]); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				// The export printed its own error
+				os.Exit(exitErr.ExitCode())
+			}
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	jobsLimit int
+	jobsJSON  bool
+)
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsListCmd)
+	jobsCmd.AddCommand(jobsShowCmd)
+	jobsCmd.AddCommand(jobsRerunCmd)
+
+	jobsListCmd.Flags().IntVarP(&jobsLimit, "limit", "n", 20, "Number of jobs to show (0 = all)")
+	jobsListCmd.Flags().BoolVar(&jobsJSON, "json", false, "Print the jobs as JSON")
+	jobsShowCmd.Flags().BoolVar(&jobsJSON, "json", false, "Print the job as JSON")
+}
+
+// openHistory returns the job history configured in the history section, or
+// nil when it is disabled
+func openHistory(cfg *config.Config) *history.Store {
+	if cfg.History.Disabled {
+		return nil
+	}
+	store, err := history.Open(cfg.History.Path)
+	if err != nil {
+		appLogger.Warn("job history disabled", "error", err)
+		return nil
+	}
+	return store
+}
+
+// recordJob completes job with the command line and the final error of the
+// run that started at start, and saves it. Failures are logged and never
+// fail the export.
+func recordJob(cfg *config.Config, job *history.Job, start time.Time, err error) {
+	store := openHistory(cfg)
+	if store == nil {
+		return
+	}
+	job.Args = redactArgs(os.Args[1:])
+	if job.Options != nil {
+		options := *job.Options
+		options.OutputFile = redactURL(options.OutputFile)
+		options.SnapshotService = redactURL(options.SnapshotService)
+		job.Options = &options
+	}
+	job.Dir, _ = os.Getwd()
+	job.StartedAt = start
+	job.Duration = time.Since(start)
+	job.ExitCode = models.ExitCodeOf(err)
+	if err != nil {
+		job.Success = false
+		job.Error = err.Error()
+	}
+	if recordErr := store.Record(job); recordErr != nil {
+		appLogger.Warn("failed to record job", "path", store.Dir(), "error", recordErr)
+		return
+	}
+	if _, pruneErr := store.Prune(cfg.History.Keep); pruneErr != nil {
+		appLogger.Warn("failed to prune job history", "path", store.Dir(), "error", pruneErr)
+	}
+}
+
+// loadHistory opens the configured job history for the jobs commands
+func loadHistory() (*history.Store, error) {
+	cfg, err := config.NewManager().Load()
+	if err != nil {
+		return nil, err
+	}
+	return history.Open(cfg.History.Path)
+}
+
+func runJobsList() error {
+	store, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	jobs, err := store.List()
+	if err != nil {
+		return err
+	}
+	total := len(jobs)
+	if jobsLimit > 0 && len(jobs) > jobsLimit {
+		jobs = jobs[:jobsLimit]
+	}
+
+	if jobsJSON {
+		if jobs == nil {
+			jobs = []history.Job{}
+		}
+		data, err := json.MarshalIndent(jobs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal jobs to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if total == 0 {
+		printf("No export jobs recorded yet.\n")
+		return nil
+	}
+	printf("📜 %d of %d jobs:\n\n", len(jobs), total)
+	for _, job := range jobs {
+		status := "✅"
+		if !job.Success {
+			status = "❌"
+		}
+		printf("%s %-17s %-10s %-22s %7d messages %9s  %s\n", status, job.ID, job.Command, jobTarget(job),
+			job.Messages, job.Duration.Round(100*time.Millisecond), job.Output)
+	}
+	return nil
+}
+
+func runJobsShow(id string) error {
+	store, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	job, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if jobsJSON {
+		data, err := json.MarshalIndent(job, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal job to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printf("🗂️  Job %s\n", job.ID)
+	printf("   Command: %s\n", jobCommandLine(job.Args))
+	printf("   Directory: %s\n", job.Dir)
+	printf("   Started: %s\n", job.StartedAt.In(displayLocation).Format("2006-01-02 15:04:05"))
+	printf("   Duration: %s\n", job.Duration.Round(time.Millisecond))
+	if job.Success {
+		printf("   Result: success\n")
+	} else {
+		printf("   Result: failed (exit code %d): %s\n", job.ExitCode, job.Error)
+	}
+	if job.Channel != "" {
+		printf("   Channel: #%s\n", job.Channel)
+	}
+	if job.Output != "" {
+		printf("   Output: %s\n", job.Output)
+	}
+	printf("   Messages: %d\n", job.Messages)
+	if job.FileSize > 0 {
+		printf("   File size: %s\n", usecase.FormatFileSize(job.FileSize))
+	}
+	return nil
+}
+
+func runJobsRerun(id string, extra []string) error {
+	store, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	job, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+	if len(job.Args) == 0 {
+		return fmt.Errorf("job '%s' has no command line to re-run", id)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the slacker executable: %w", err)
+	}
+	// A checkpoint or run ID applied to the original run only
+	args := dropFlag(dropFlag(job.Args, "--resume"), "--resume-run")
+	if job.Command == "export" && job.Options != nil {
+		args = append(dropFlags(args, exportOptionFlags), exportOptionArgs(*job.Options)...)
+	}
+	args, unrecorded := dropRedacted(args)
+	for _, flag := range unrecorded {
+		eprintf("⚠️  %s was not recorded; pass it again after --\n", flag)
+	}
+	args = append(args, extra...)
+
+	printf("🔁 Re-running job %s: %s\n\n", job.ID, jobCommandLine(args))
+	rerun := exec.Command(executable, args...)
+	if info, err := os.Stat(job.Dir); err == nil && info.IsDir() {
+		rerun.Dir = job.Dir
+	}
+	rerun.Stdin, rerun.Stdout, rerun.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return rerun.Run()
+}
+
+// jobTarget names what a job exported
+func jobTarget(job history.Job) string {
+	if job.Channel == "" {
+		return "-"
+	}
+	return "#" + job.Channel
+}
+
+// jobCommandLine renders args as a slacker command line
+func jobCommandLine(args []string) string {
+	words := []string{"slacker"}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// dropFlag removes the string flag name and its value from args
+func dropFlag(args []string, name string) []string {
+	var kept []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == name:
+			i++
+		case strings.HasPrefix(args[i], name+"="):
+		default:
+			kept = append(kept, args[i])
+		}
+	}
+	return kept
+}
+
+// redactedValue replaces secrets in recorded jobs
+const redactedValue = "REDACTED"
+
+// secretFlags are the flags whose whole value is a secret
+var secretFlags = []string{"--notify-webhook"}
+
+// redactArgs replaces the values of secretFlags and the passwords of URLs in
+// args, so the job history holds no secrets
+func redactArgs(args []string) []string {
+	redacted := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if slices.Contains(secretFlags, arg) && i+1 < len(args) {
+			redacted = append(redacted, arg, redactedValue)
+			i++
+			continue
+		}
+		if name, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(name, "-") {
+			if slices.Contains(secretFlags, name) {
+				value = redactedValue
+			}
+			redacted = append(redacted, name+"="+redactURL(value))
+			continue
+		}
+		redacted = append(redacted, redactURL(arg))
+	}
+	return redacted
+}
+
+// redactURL replaces the password of a URL with user info
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redactedValue)
+	} else {
+		u.User = url.User(redactedValue)
+	}
+	return u.String()
+}
+
+// dropRedacted removes the flags with redacted values from args and returns
+// their names
+func dropRedacted(args []string) ([]string, []string) {
+	var kept, dropped []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, "-") && strings.Contains(arg, redactedValue):
+			name, _, _ := strings.Cut(arg, "=")
+			dropped = append(dropped, name)
+		case strings.HasPrefix(arg, "-") && i+1 < len(args) && strings.Contains(args[i+1], redactedValue):
+			dropped = append(dropped, arg)
+			i++
+		default:
+			kept = append(kept, arg)
+		}
+	}
+	return kept, dropped
+}
+
+// exportOptionFlags are the export flags exportOptionArgs sets from the
+// recorded options. The output path stays as it was given, so an export
+// named after its start time gets a new name.
+var exportOptionFlags = map[string]bool{
+	"channel": true, "channel-id": true, "format": true, "compress": true,
+	"threads": true, "files": true, "reactions": true,
+	"no-threads": true, "no-files": true, "no-reactions": true,
+	"from": true, "to": true, "max-messages": true, "max-duration": true,
+	"sse": true, "sse-kms-key-id": true, "best-effort": true,
+	"page-size": true, "thread-delay": true,
+	"user": true, "match": true, "exclude-subtype": true, "subtype-policy": true,
+	"no-bots": true, "only-bots": true, "exclude-external": true, "min-reactions": true,
+	"threads-only": true, "participated-threads": true, "no-replies-inline": true,
+	"split-by": true, "layout": true, "chunk-tokens": true, "text-only": true,
+	"summarize": true, "summarize-by": true, "manifest": true,
+	"include-emoji": true, "include-avatars": true, "include-files": true,
+	"include-canvas": true, "include-usergroups": true, "include-links": true,
+	"include-permalinks": true, "snapshot-links": true, "snapshot-service": true,
+	"archive-domain": true, "include-timeline": true,
+}
+
+// exportOptionArgs renders the settings of an export as export flags
+func exportOptionArgs(options models.ExportOptions) []string {
+	args := []string{
+		"--channel-id", options.ChannelID,
+		"--format", options.Format,
+		"--threads=" + strconv.FormatBool(options.IncludeThreads),
+		"--files=" + strconv.FormatBool(options.IncludeFiles),
+		"--reactions=" + strconv.FormatBool(options.IncludeReactions),
+	}
+	value := func(flag, value string) {
+		if value != "" {
+			args = append(args, flag, value)
+		}
+	}
+	number := func(flag string, n int) {
+		if n != 0 {
+			args = append(args, flag, strconv.Itoa(n))
+		}
+	}
+	duration := func(flag string, d time.Duration) {
+		if d != 0 {
+			args = append(args, flag, d.String())
+		}
+	}
+	set := func(flag string, on bool) {
+		if on {
+			args = append(args, flag)
+		}
+	}
+	date := func(flag string, t *time.Time) {
+		if t != nil {
+			args = append(args, flag, t.Format("2006-01-02T15:04:05-07:00"))
+		}
+	}
+
+	value("--compress", options.Compression)
+	date("--from", options.DateFrom)
+	date("--to", options.DateTo)
+	number("--max-messages", options.MaxMessages)
+	duration("--max-duration", options.MaxDuration)
+	value("--sse", options.ServerSideEncryption)
+	value("--sse-kms-key-id", options.EncryptionKeyID)
+	set("--best-effort", options.BestEffort)
+	number("--page-size", options.PageSize)
+	if options.ThreadDelay == models.NoThreadDelay {
+		args = append(args, "--thread-delay", "0s")
+	}
+	duration("--thread-delay", max(options.ThreadDelay, 0))
+	for _, user := range options.Users {
+		value("--user", user)
+	}
+	value("--match", options.Match)
+	for _, subtype := range options.ExcludeSubtypes {
+		value("--exclude-subtype", subtype)
+	}
+	subtypes := slices.Sorted(maps.Keys(options.SubtypePolicy))
+	for _, subtype := range subtypes {
+		value("--subtype-policy", subtype+"="+options.SubtypePolicy[subtype])
+	}
+	switch options.Bots {
+	case models.BotFilterExclude:
+		set("--no-bots", true)
+	case models.BotFilterOnly:
+		set("--only-bots", true)
+	}
+	set("--exclude-external", options.ExcludeExternal)
+	number("--min-reactions", options.MinReactions)
+	set("--threads-only", options.ThreadsOnly)
+	set("--participated-threads", options.ParticipatedThreads)
+	set("--no-replies-inline", options.FlatReplies)
+	value("--split-by", options.SplitBy)
+	value("--layout", options.Layout)
+	number("--chunk-tokens", options.ChunkTokens)
+	set("--text-only", options.TextOnly)
+	if options.SummarizeBy != "" {
+		args = append(args, "--summarize", "--summarize-by", options.SummarizeBy)
+	}
+	set("--manifest", options.Manifest)
+	set("--include-emoji", options.IncludeEmoji)
+	set("--include-avatars", options.IncludeAvatars)
+	set("--include-files", options.IncludeFileInfo)
+	set("--include-canvas", options.IncludeCanvas)
+	set("--include-usergroups", options.IncludeUserGroups)
+	set("--include-links", options.IncludeLinks)
+	set("--include-permalinks", options.WorkspaceURL != "")
+	set("--snapshot-links", options.SnapshotLinks)
+	value("--snapshot-service", options.SnapshotService)
+	for _, domain := range options.SnapshotArchiveDomains {
+		value("--archive-domain", domain)
+	}
+	set("--include-timeline", options.IncludeTimeline)
+	return args
+}
+
+// dropFlags removes the export and global flags in names, with their
+// values, from args
+func dropFlags(args []string, names map[string]bool) []string {
+	flags := exportCmd.Flags()
+	flags.AddFlagSet(rootCmd.PersistentFlags())
+
+	var kept []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			return append(kept, args[i:]...)
+		}
+		name, _, inline := strings.Cut(args[i], "=")
+		flag := flags.Lookup(strings.TrimPrefix(name, "--"))
+		if !strings.HasPrefix(name, "--") && len(name) == 2 && name[0] == '-' {
+			flag = flags.ShorthandLookup(name[1:])
+		}
+		if !strings.HasPrefix(name, "-") || flag == nil || !names[flag.Name] {
+			kept = append(kept, args[i])
+			continue
+		}
+		// A separate value follows unless the flag is boolean
+		if !inline && flag.NoOptDefVal == "" {
+			i++
+		}
+	}
+	return kept
+}
+
+// completeJobIDs completes the IDs of recorded jobs
+func completeJobIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	store, err := loadHistory()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	jobs, _ := store.List()
+	var ids []string
+	for _, job := range jobs {
+		if strings.HasPrefix(job.ID, toComplete) {
+			ids = append(ids, fmt.Sprintf("%s\t%s %s", job.ID, job.Command, jobTarget(job)))
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
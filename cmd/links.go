@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+)
+
+// linksCmd represents the links command
+var linksCmd = &cobra.Command{
+	Use:   "links",
+	Short: "List the URLs shared in a channel",
+	Long: `List every http(s) link shared in a channel's messages and thread replies,
+with who shared it, when, and how many reactions the message got. Useful to
+track the resources a community passes around.
+
+Links come from the Slack API, from the local message store with --offline, or
+from an existing export with --input. Without --output the list is printed to
+stdout.
+
+Examples:
+  slacker links --channel general --last 90d
+  slacker links --channel general --last 4w --format json --output links.json
+  slacker links --input general-export.json --format csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runLinks(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	linksChannel   string
+	linksChannelID string
+	linksLast      string
+	linksFormat    string
+	linksOutput    string
+	linksInput     string
+	linksThreads   bool
+	linksOffline   bool
+)
+
+func init() {
+	rootCmd.AddCommand(linksCmd)
+
+	linksCmd.Flags().StringVarP(&linksChannel, "channel", "c", "", "Channel name")
+	linksCmd.Flags().StringVar(&linksChannelID, "channel-id", "", "Channel ID (alternative to --channel)")
+	linksCmd.Flags().StringVar(&linksLast, "last", "30d", "Only links shared within this period, e.g. 90d, 4w or 12h (0 = all history)")
+	linksCmd.Flags().StringVarP(&linksFormat, "format", "f", "csv", "Output format: csv, json")
+	linksCmd.Flags().StringVarP(&linksOutput, "output", "o", "", "Write the links to this file instead of stdout (- for stdout)")
+	linksCmd.Flags().StringVar(&linksInput, "input", "", "Read links from this export file (- for stdin) instead of fetching the channel")
+	linksCmd.Flags().BoolVar(&linksThreads, "threads", true, "Include links shared in thread replies")
+	linksCmd.Flags().BoolVar(&linksOffline, "offline", false, "Read the channel from the local message store (see 'slacker sync')")
+
+	registerChannelCompletion(linksCmd, "channel")
+	registerValueCompletion(linksCmd, "format", "csv", "json")
+}
+
+func runLinks(cmd *cobra.Command) error {
+	if linksFormat != "csv" && linksFormat != "json" {
+		return fmt.Errorf("invalid format '%s'. Valid formats: csv, json", linksFormat)
+	}
+	period, err := parsePeriod(linksLast)
+	if err != nil {
+		return fmt.Errorf("invalid --last '%s': %w", linksLast, err)
+	}
+	var since *time.Time
+	if period > 0 {
+		from := time.Now().Add(-period)
+		since = &from
+	}
+
+	var links []models.ExportLink
+	if linksInput != "" {
+		if linksChannel != "" || linksChannelID != "" || linksOffline {
+			return fmt.Errorf("--input cannot be combined with --channel, --channel-id or --offline")
+		}
+		exportData, err := usecase.ReadExportFile(linksInput)
+		if err != nil {
+			return err
+		}
+		messages := exportData.Messages
+		if !linksThreads {
+			messages = withoutReplies(messages)
+		}
+		links = usecase.ExtractLinks(messages, exportData.Users)
+		// Exports cover their own date range; --last only narrows it when given
+		if since != nil && cmd.Flags().Changed("last") {
+			links = linksSince(links, *since)
+		}
+	} else {
+		if links, err = fetchLinks(cmd.Context(), since); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if !toStdout(linksOutput) {
+		file, err := os.Create(linksOutput)
+		if err != nil {
+			return models.NewExportError(models.ErrorCategoryIO, "failed to create output file", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := writeLinks(out, links, linksFormat); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to write links", err)
+	}
+	if !toStdout(linksOutput) {
+		printf("🔗 Wrote %d links to %s\n", len(links), linksOutput)
+	}
+	return nil
+}
+
+// fetchLinks exports the channel to a temporary file with the links section
+// enabled and returns the links, so fetching shares the export's pagination,
+// retries and date handling
+func fetchLinks(ctx context.Context, since *time.Time) ([]models.ExportLink, error) {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil && !linksOffline {
+		return nil, err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return nil, err
+	}
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+
+	var source usecase.MessageClientInterface = slackClient
+	auditClient := slackClient
+	if linksOffline {
+		st, err := openStore(true)
+		if err != nil {
+			return nil, err
+		}
+		defer st.Close()
+		source = st
+		auditClient = nil
+	}
+	auditLog, err := openAuditLog(cfg, auditClient)
+	if err != nil {
+		return nil, err
+	}
+
+	channelID, channelName := linksChannelID, linksChannel
+	if channelID == "" && channelName == "" {
+		channel, err := pickChannel(ctx, source, "either --channel, --channel-id or --input must be specified")
+		if err != nil {
+			return nil, err
+		}
+		channelID, channelName = channel.ID, channel.Name
+	} else if channelID == "" {
+		channel, err := source.GetChannelByName(ctx, channelName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find channel '%s': %w", channelName, err)
+		}
+		channelID, channelName = channel.ID, channel.Name
+	}
+
+	// Permalinks need the workspace URL, which only the API knows
+	var workspaceURL string
+	if !linksOffline {
+		auth, err := slackClient.TestAuth(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up workspace: %w", err)
+		}
+		workspaceURL = auth.URL
+	}
+
+	dir, err := os.MkdirTemp("", "slacker-links-")
+	if err != nil {
+		return nil, models.NewExportError(models.ErrorCategoryIO, "failed to create temporary directory", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if channelName == "" {
+		channelName = channelID
+	}
+	eprintf("🔄 Collecting links from #%s...\n", channelName)
+	options := models.ExportOptions{
+		ChannelID:        channelID,
+		ChannelName:      channelName,
+		IncludeThreads:   linksThreads,
+		IncludeReactions: true,
+		IncludeLinks:     true,
+		DateFrom:         since,
+		OutputFile:       filepath.Join(dir, "export.json"),
+		Format:           "json-compact",
+		WorkspaceURL:     workspaceURL,
+		PageSize:         apiConfig.PageSize,
+		ThreadDelay:      apiConfig.ThreadDelay,
+		Location:         displayLocation,
+	}
+	service := usecase.NewExportService(source, getVersion())
+	service.SetLogger(appLogger)
+	result, err := service.ExportChannel(ctx, options, usecase.NewLogSink(appLogger))
+
+	// The temporary export is an implementation detail; the audit log names
+	// where the links went
+	entry := audit.ForExport(audit.ActionLinks, options, result, err)
+	entry.Output = linksOutput
+	if toStdout(entry.Output) {
+		entry.Output = "stdout"
+	}
+	if err := recordAudit(auditLog, entry, err); err != nil {
+		return nil, err
+	}
+	exportData, err := usecase.ReadExportFile(result.OutputFile)
+	if err != nil {
+		return nil, err
+	}
+	if exportData.Links == nil {
+		return []models.ExportLink{}, nil
+	}
+	return exportData.Links, nil
+}
+
+// writeLinks writes links as CSV or as an indented JSON array
+func writeLinks(w io.Writer, links []models.ExportLink, format string) error {
+	if format == "csv" {
+		return usecase.WriteLinksCSV(w, links)
+	}
+	data, err := json.MarshalIndent(links, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// withoutReplies returns messages with their thread replies removed
+func withoutReplies(messages []models.ExportMessage) []models.ExportMessage {
+	result := make([]models.ExportMessage, len(messages))
+	for i, msg := range messages {
+		msg.Replies = nil
+		result[i] = msg
+	}
+	return result
+}
+
+// linksSince returns the links shared at or after since
+func linksSince(links []models.ExportLink, since time.Time) []models.ExportLink {
+	filtered := []models.ExportLink{}
+	for _, link := range links {
+		if !link.Timestamp.Before(since) {
+			filtered = append(filtered, link)
+		}
+	}
+	return filtered
+}
+
+// parsePeriod parses a look-back period such as 90d, 4w or 12h. Days and
+// weeks are added to the units time.ParseDuration knows; "0" means no limit.
+func parsePeriod(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(value, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(value, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("expected a period such as 90d, 4w or 12h")
+		}
+		return time.Duration(n) * unit, nil
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil || period < 0 {
+		return 0, fmt.Errorf("expected a period such as 90d, 4w or 12h")
+	}
+	return period, nil
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"90d", 90 * 24 * time.Hour},
+		{"4w", 28 * 24 * time.Hour},
+		{"12h", 12 * time.Hour},
+		{"1h30m", 90 * time.Minute},
+		{"0", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got, err := parsePeriod(tt.value)
+		if err != nil {
+			t.Errorf("parsePeriod(%q): unexpected error %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parsePeriod(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"d", "-3d", "ninety days", "-1h"} {
+		if _, err := parsePeriod(value); err == nil {
+			t.Errorf("parsePeriod(%q): expected an error", value)
+		}
+	}
+}
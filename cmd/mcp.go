@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/mcp"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// mcpCmd represents the mcp command
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve Slack history to AI assistants over the Model Context Protocol",
+	Long: `Run a Model Context Protocol (MCP) server on stdin/stdout so AI assistants and
+LLM agents can read Slack history through slacker. The server offers these tools:
+
+  list_channels   List the channels slacker can read
+  fetch_messages  Fetch recent messages of a channel, optionally with threads
+  search_export   Search export files in the archive directory
+  export_channel  Export a channel to a JSON file in the archive directory
+
+With --offline the tools read from the local message store (see 'slacker sync')
+instead of calling the Slack API. Export files are only read from and written to
+--archive-dir. Logs go to stderr; stdout carries the protocol.
+
+Example client configuration:
+  {"mcpServers": {"slack": {"command": "slacker", "args": ["mcp", "--archive-dir", "/srv/slack-archive"]}}}`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runMCP(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	mcpOffline    bool
+	mcpArchiveDir string
+)
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+
+	mcpCmd.Flags().BoolVar(&mcpOffline, "offline", false, "Read channels and messages from the local message store instead of the Slack API")
+	mcpCmd.Flags().StringVar(&mcpArchiveDir, "archive-dir", "", "Directory searched by search_export and written by export_channel (default: export.default_output_dir or .)")
+}
+
+func runMCP() error {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil && !mcpOffline {
+		return err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+
+	archiveDir := mcpArchiveDir
+	if archiveDir == "" {
+		archiveDir = cfg.Export.DefaultOutputDir
+	}
+	if archiveDir == "" {
+		archiveDir = "."
+	}
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to create archive directory", err)
+	}
+
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+
+	var source usecase.MessageClientInterface = slackClient
+	if mcpOffline {
+		st, err := openStore(true)
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+		source = st
+	}
+
+	messageService := usecase.NewMessageService(source)
+	messageService.SetLogger(appLogger)
+	messageService.SetPaging(apiConfig.PageSize, apiConfig.ThreadDelay)
+
+	exportService := usecase.NewExportService(source, getVersion())
+	exportService.SetLogger(appLogger)
+	auditClient := slackClient
+	if mcpOffline {
+		auditClient = nil
+	} else {
+		exportService.SetWorkspaceClient(slackClient)
+		exportService.SetBotClient(slackClient)
+	}
+	auditLog, err := openAuditLog(cfg, auditClient)
+	if err != nil {
+		return err
+	}
+	exportService.SetAuditLog(auditLog)
+
+	tools := mcp.NewTools(source, messageService, exportService, archiveDir)
+	tools.SetAuditLog(auditLog)
+	tools.SetLocation(displayLocation)
+	server := mcp.NewServer(tools, getVersion())
+	server.SetLogger(appLogger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	appLogger.Info("mcp server started", "offline", mcpOffline, "archive_dir", archiveDir)
+	return server.Serve(ctx, os.Stdin, os.Stdout)
+}
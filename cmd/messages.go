@@ -5,12 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/audit"
 	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
 	"github.com/itcaat/slacker/models"
 	"github.com/spf13/cobra"
 )
@@ -25,11 +30,23 @@ browse recent messages, search through history, and view threaded conversations.
 Examples:
   slacker messages --channel general           # View recent messages from #general
   slacker messages --channel general --limit 50  # View last 50 messages
-  slacker messages --channel general --threads   # Include thread replies`,
+  slacker messages --channel general --threads   # Include thread replies
+  slacker messages --channel general --user @alice  # Only messages by alice
+  slacker messages                             # Pick the channel from a list
+  slacker messages --channel general --follow    # Keep printing new messages, like tail -f
+
+With --since-cursor-file the newest message shown is remembered in a file, and the
+next run with the same file only prints messages posted after it. Scripts can poll a
+channel this way without ever seeing a message twice:
+
+  slacker messages --channel alerts --since-cursor-file alerts.cursor --format json | ./handle.sh
+
+With --follow or --since-cursor-file, messages are printed oldest first, --format json
+writes one JSON object per line, and status output goes to stderr.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := viewMessages(cmd); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
 		}
 	},
 }
@@ -38,19 +55,27 @@ func init() {
 	rootCmd.AddCommand(messagesCmd)
 
 	// Channel selection
-	messagesCmd.Flags().StringP("channel", "c", "", "Channel name to view messages from (required)")
-	messagesCmd.MarkFlagRequired("channel")
+	messagesCmd.Flags().StringP("channel", "c", "", "Channel name to view messages from (prompted for in a terminal when omitted)")
 
 	// Message retrieval options
 	messagesCmd.Flags().IntP("limit", "l", 20, "Number of messages to retrieve (default: 20)")
 	messagesCmd.Flags().BoolP("threads", "t", false, "Include thread replies")
 	messagesCmd.Flags().StringP("before", "b", "", "Show messages before this timestamp")
 	messagesCmd.Flags().StringP("after", "a", "", "Show messages after this timestamp")
+	messagesCmd.Flags().Bool("follow", false, "Keep running and print new messages as they are posted")
+	messagesCmd.Flags().Duration("interval", 5*time.Second, "Polling interval for --follow")
+	messagesCmd.Flags().String("since-cursor-file", "", "Only show messages newer than the cursor in this file, and save the newest shown")
+	messagesCmd.Flags().StringSlice("user", nil, "Only show messages and replies by this user (@name, display name or ID; repeatable)")
+	messagesCmd.Flags().Bool("no-bots", false, "Hide messages posted by bots and apps")
+	messagesCmd.Flags().Bool("only-bots", false, "Only show messages posted by bots and apps")
+	messagesCmd.MarkFlagsMutuallyExclusive("no-bots", "only-bots")
 
 	// Output options
 	messagesCmd.Flags().StringP("format", "f", "text", "Output format: text, json")
-	messagesCmd.Flags().BoolP("verbose", "v", false, "Show detailed message information")
 	messagesCmd.Flags().BoolP("no-format", "n", false, "Disable text formatting and colors")
+
+	registerChannelCompletion(messagesCmd, "channel")
+	registerValueCompletion(messagesCmd, "format", "text", "json")
 }
 
 func viewMessages(cmd *cobra.Command) error {
@@ -60,69 +85,164 @@ func viewMessages(cmd *cobra.Command) error {
 	includeThreads, _ := cmd.Flags().GetBool("threads")
 	before, _ := cmd.Flags().GetString("before")
 	after, _ := cmd.Flags().GetString("after")
+	userRefs, _ := cmd.Flags().GetStringSlice("user")
+	noBots, _ := cmd.Flags().GetBool("no-bots")
+	onlyBots, _ := cmd.Flags().GetBool("only-bots")
 	format, _ := cmd.Flags().GetString("format")
-	verbose, _ := cmd.Flags().GetBool("verbose")
+	verbose := verboseOutput
 	noFormat, _ := cmd.Flags().GetBool("no-format")
+	follow, _ := cmd.Flags().GetBool("follow")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	cursorFile, _ := cmd.Flags().GetString("since-cursor-file")
 
 	// Validate limit
 	if limit <= 0 || limit > 1000 {
 		return fmt.Errorf("limit must be between 1 and 1000")
 	}
 
+	// Incremental and JSON output keep stdout for messages only
+	incremental := follow || cursorFile != ""
+	status := os.Stdout
+	if incremental || format == "json" {
+		status = os.Stderr
+	}
+	if incremental && before != "" {
+		return fmt.Errorf("--before cannot be used with --follow or --since-cursor-file")
+	}
+
+	bots, err := botFilter(noBots, onlyBots)
+	if err != nil {
+		return err
+	}
+
 	// Get token from config
 	configManager := config.NewManager()
-	token, err := configManager.GetToken()
+	token, err := selectToken(configManager, models.TokenTypeUser)
 	if err != nil {
-		return fmt.Errorf("authentication required. Run 'slacker auth <token>' first: %w", err)
+		return err
+	}
+
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
 	}
 
 	// Create Slack client
-	client := api.NewSlackClient(token, false)
+	client := newSlackClient(token)
+	auditLog, err := openAuditLog(cfg, client)
+	if err != nil {
+		return err
+	}
+
+	// Ask for a channel before the timeout starts
+	var channel *models.Channel
+	if channelName == "" {
+		if channel, err = pickChannel(context.Background(), client, `required flag(s) "channel" not set`); err != nil {
+			return err
+		}
+	}
+
+	// Stop following on Ctrl+C
+	baseCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(baseCtx, apiTimeout(60*time.Second))
 	defer cancel()
 
 	// Find channel by name
-	fmt.Printf("🔄 Finding channel #%s...\n", channelName)
-	channel, err := client.GetChannelByName(ctx, channelName)
+	if channel == nil {
+		fprintf(status, "🔄 Finding channel #%s...\n", channelName)
+		if channel, err = client.GetChannelByName(ctx, channelName); err != nil {
+			return fmt.Errorf("failed to find channel: %w", err)
+		}
+	}
+
+	fprintf(status, "📢 Found channel: #%s (%s)\n", channel.Name, channel.ID)
+
+	// Get user information for --user and for better display
+	fprintf(status, "🔄 Fetching user information...\n")
+	users, err := client.GetUsers(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to find channel: %w", err)
+		return fmt.Errorf("failed to get users: %w", err)
 	}
 
-	fmt.Printf("📢 Found channel: #%s (%s)\n", channel.Name, channel.ID)
+	userIDs, err := usecase.ResolveUserIDs(users, userRefs)
+	if err != nil {
+		return err
+	}
+
+	userMap := make(map[string]models.User)
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+
+	entry := audit.Entry{
+		Action:    audit.ActionMessages,
+		ChannelID: channel.ID,
+		Channel:   channel.Name,
+		From:      slackTime(after),
+		To:        slackTime(before),
+		Output:    "stdout",
+	}
+	if cursorFile != "" {
+		entry.Output = cursorFile
+	}
+
+	if incremental {
+		err := followMessages(baseCtx, ctx, client, channel, followOptions{
+			limit:      limit,
+			after:      after,
+			threads:    includeThreads,
+			userIDs:    userIDs,
+			bots:       bots,
+			follow:     follow,
+			interval:   interval,
+			cursorFile: cursorFile,
+			format:     format,
+			verbose:    verbose,
+			noFormat:   noFormat,
+		}, userMap)
+		entry.Success = err == nil
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		return recordAudit(auditLog, entry, err)
+	}
 
 	// Get message history
-	fmt.Printf("🔄 Fetching message history (limit: %d)...\n", limit)
-	messages, err := getChannelMessages(ctx, client, channel.ID, limit, before, after)
+	fprintf(status, "🔄 Fetching message history (limit: %d)...\n", limit)
+	messages, _, err := getChannelMessages(ctx, client, channel.ID, limit, before, after, userIDs, bots, includeThreads)
 	if err != nil {
-		return fmt.Errorf("failed to get messages: %w", err)
+		entry.Error = err.Error()
+		return recordAudit(auditLog, entry, fmt.Errorf("failed to get messages: %w", err))
 	}
 
 	if len(messages) == 0 {
-		fmt.Println("No messages found in the specified range.")
+		entry.Success = true
+		if err := recordAudit(auditLog, entry, nil); err != nil {
+			return err
+		}
+		if format == "json" {
+			return outputMessagesJSON([]models.Message{}, userMap)
+		}
+		printf("No messages found in the specified range.\n")
 		return nil
 	}
 
 	// Get thread replies if requested
 	if includeThreads {
-		fmt.Println("🔄 Fetching thread replies...")
+		fprintf(status, "🔄 Fetching thread replies...\n")
 		messages, err = enrichWithThreads(ctx, client, channel.ID, messages)
 		if err != nil {
-			return fmt.Errorf("failed to get thread replies: %w", err)
+			entry.Error = err.Error()
+			return recordAudit(auditLog, entry, fmt.Errorf("failed to get thread replies: %w", err))
 		}
+		messages = filterMessages(messages, userIDs, bots, false)
 	}
-
-	// Get user information for better display
-	fmt.Println("🔄 Fetching user information...")
-	users, err := client.GetUsers(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get users: %w", err)
-	}
-
-	userMap := make(map[string]models.User)
-	for _, user := range users {
-		userMap[user.ID] = user
+	entry.Messages, entry.Success = len(messages), true
+	if err := recordAudit(auditLog, entry, nil); err != nil {
+		return err
 	}
 
 	// Output messages
@@ -136,33 +256,171 @@ func viewMessages(cmd *cobra.Command) error {
 	}
 }
 
-// getChannelMessages retrieves messages from a channel with pagination
-func getChannelMessages(ctx context.Context, client *api.SlackClient, channelID string, limit int, before, after string) ([]models.Message, error) {
+// followOptions are the flags of an incremental messages run
+type followOptions struct {
+	limit      int
+	after      string
+	threads    bool
+	userIDs    []string
+	bots       string
+	follow     bool
+	interval   time.Duration
+	cursorFile string
+	format     string
+	verbose    bool
+	noFormat   bool
+}
+
+// followMessages prints the messages posted since the saved cursor, or the
+// latest --limit messages on the first run, oldest first. With --follow it
+// then polls for new messages until ctx is cancelled. The cursor file is
+// updated after every printed message.
+func followMessages(ctx, fetchCtx context.Context, client *api.SlackClient, channel *models.Channel, opts followOptions, userMap map[string]models.User) error {
+	cursor := &usecase.MessageCursor{ChannelID: channel.ID}
+	if opts.cursorFile != "" {
+		saved, err := usecase.LoadMessageCursor(opts.cursorFile)
+		if err != nil {
+			return err
+		}
+		if saved != nil {
+			if saved.ChannelID != channel.ID {
+				return fmt.Errorf("cursor file '%s' belongs to channel %s, not #%s (%s)", opts.cursorFile, saved.ChannelID, channel.Name, channel.ID)
+			}
+			cursor = saved
+		}
+	}
+
+	var messages []models.Message
+	var err error
+	if cursor.Latest != "" {
+		eprintf("🔄 Fetching messages since cursor %s...\n", cursor.Latest)
+		if messages, err = client.GetMessagesSince(fetchCtx, channel.ID, cursor.Latest); err != nil {
+			return fmt.Errorf("failed to get messages: %w", err)
+		}
+		for _, msg := range messages {
+			cursor.Advance(msg.Timestamp)
+		}
+		messages = filterMessages(messages, opts.userIDs, opts.bots, opts.threads)
+	} else {
+		eprintf("🔄 Fetching message history (limit: %d)...\n", opts.limit)
+		// The cursor starts at the newest message Slack returned, filtered
+		// out or not, so messages posted during the fetch are picked up by
+		// the next poll whatever the local clock says
+		var newest string
+		if messages, newest, err = getChannelMessages(fetchCtx, client, channel.ID, opts.limit, "", opts.after, opts.userIDs, opts.bots, opts.threads); err != nil {
+			return fmt.Errorf("failed to get messages: %w", err)
+		}
+		cursor.Advance(newest)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Timestamp < messages[j].Timestamp
+	})
+
+	if opts.threads {
+		if messages, err = enrichWithThreads(fetchCtx, client, channel.ID, messages); err != nil {
+			return fmt.Errorf("failed to get thread replies: %w", err)
+		}
+		messages = filterMessages(messages, opts.userIDs, opts.bots, false)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	show := func(msg models.Message) error {
+		if opts.format == "json" {
+			return encoder.Encode(msg)
+		}
+		return displayMessage(msg, userMap, opts.verbose, opts.noFormat, 0)
+	}
+	for _, msg := range messages {
+		if err := show(msg); err != nil {
+			return err
+		}
+	}
+	if opts.cursorFile != "" {
+		if err := cursor.Save(opts.cursorFile); err != nil {
+			return err
+		}
+	}
+	if !opts.follow {
+		return nil
+	}
+
+	eprintf("👀 Following #%s, polling every %s (Ctrl+C to stop)...\n", channel.Name, opts.interval)
+
+	// Stop following when a message cannot be written, e.g. a closed pipe
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var followErr error
+	fail := func(err error) {
+		if err != nil && followErr == nil {
+			followErr = err
+			cancel()
+		}
+	}
+
+	// An empty channel has no cursor yet; every message is new to it
+	since := cursor.Latest
+	if since == "" {
+		since = "0"
+	}
+	watchService := usecase.NewWatchService(client)
+	err = watchService.Watch(watchCtx, usecase.WatchOptions{
+		ChannelID: channel.ID,
+		Interval:  opts.interval,
+		Since:     since,
+	}, func(msg models.Message) {
+		if followErr != nil || !cursor.Advance(msg.Timestamp) {
+			return
+		}
+		if len(filterMessages([]models.Message{msg}, opts.userIDs, opts.bots, false)) > 0 {
+			if fail(show(msg)); followErr != nil {
+				return
+			}
+		}
+		if opts.cursorFile != "" {
+			fail(cursor.Save(opts.cursorFile))
+		}
+	})
+	if followErr != nil {
+		return followErr
+	}
+	return err
+}
+
+// getChannelMessages retrieves messages from a channel with pagination. Only
+// messages passing the user and bot filters count towards the limit; with
+// threads, bot thread parents are kept for their replies. It also returns the
+// timestamp of the newest message fetched, before any filtering.
+func getChannelMessages(ctx context.Context, client *api.SlackClient, channelID string, limit int, before, after string, userIDs []string, bots string, threads bool) ([]models.Message, string, error) {
 	var allMessages []models.Message
+	var newest string
 	cursor := ""
 	remaining := limit
 
 	for remaining > 0 {
-		// Calculate batch size (max 200 per API call)
+		// Calculate batch size (at most one page per API call)
 		batchSize := remaining
-		if batchSize > 200 {
-			batchSize = 200
+		if pageSize := historyPageSize(); batchSize > pageSize {
+			batchSize = pageSize
 		}
 
-		messages, nextCursor, err := client.GetChannelHistory(ctx, channelID, batchSize, cursor)
+		page, err := client.GetChannelHistory(ctx, channelID, batchSize, cursor)
 		if err != nil {
-			return nil, err
+			return nil, "", err
+		}
+		messages := page.Messages
+		for _, msg := range messages {
+			newest = max(newest, msg.Timestamp)
 		}
 
 		// Filter messages by time range if specified
-		filteredMessages := filterMessagesByTime(messages, before, after)
+		filteredMessages := filterMessages(filterMessagesByTime(messages, before, after), userIDs, bots, threads)
 		allMessages = append(allMessages, filteredMessages...)
 
 		remaining -= len(filteredMessages)
-		cursor = nextCursor
+		cursor = page.NextCursor
 
 		// Stop if no more messages or no cursor for next page
-		if nextCursor == "" || len(messages) == 0 {
+		if page.Last() || len(messages) == 0 {
 			break
 		}
 
@@ -177,7 +435,7 @@ func getChannelMessages(ctx context.Context, client *api.SlackClient, channelID
 		allMessages = allMessages[:limit]
 	}
 
-	return allMessages, nil
+	return allMessages, newest, nil
 }
 
 // filterMessagesByTime filters messages based on before/after timestamps
@@ -215,6 +473,35 @@ func filterMessagesByTime(messages []models.Message, before, after string) []mod
 	return filtered
 }
 
+// filterMessages applies the --user, --no-bots and --only-bots filters. With
+// threads, bot thread parents whose replies are still to be fetched are kept
+// as omitted placeholders.
+func filterMessages(messages []models.Message, userIDs []string, bots string, threads bool) []models.Message {
+	messages = usecase.FilterMessagesByUser(messages, userIDs)
+	switch {
+	case bots == models.BotFilterExclude && threads:
+		messages = usecase.ExcludeBotsBeforeThreads(messages)
+	case bots == models.BotFilterExclude:
+		messages = usecase.ExcludeBots(messages)
+	case bots == models.BotFilterOnly:
+		messages = usecase.FilterMessages(messages, models.Message.IsBot)
+	}
+	return messages
+}
+
+// botFilter converts the --no-bots and --only-bots flags into a bot filter
+func botFilter(noBots, onlyBots bool) (string, error) {
+	switch {
+	case noBots && onlyBots:
+		return "", fmt.Errorf("--no-bots and --only-bots cannot be combined")
+	case noBots:
+		return models.BotFilterExclude, nil
+	case onlyBots:
+		return models.BotFilterOnly, nil
+	}
+	return "", nil
+}
+
 // enrichWithThreads fetches thread replies for messages that have them
 func enrichWithThreads(ctx context.Context, client *api.SlackClient, channelID string, messages []models.Message) ([]models.Message, error) {
 	for i, msg := range messages {
@@ -222,10 +509,13 @@ func enrichWithThreads(ctx context.Context, client *api.SlackClient, channelID s
 			replies, err := client.GetThreadReplies(ctx, channelID, msg.ThreadTS)
 			if err != nil {
 				// Log error but continue with other messages
-				fmt.Fprintf(os.Stderr, "Warning: Failed to get replies for message %s: %v\n", msg.Timestamp, err)
+				eprintf("Warning: Failed to get replies for message %s: %v\n", msg.Timestamp, err)
 				continue
 			}
 			messages[i].Thread = replies
+
+			// Optional pause between thread requests (--thread-delay)
+			time.Sleep(apiConfig.ThreadDelay)
 		}
 	}
 	return messages, nil
@@ -255,7 +545,7 @@ func outputMessagesJSON(messages []models.Message, userMap map[string]models.Use
 
 // outputMessagesText outputs messages in human-readable text format
 func outputMessagesText(messages []models.Message, userMap map[string]models.User, verbose, noFormat bool) error {
-	fmt.Printf("\n📝 Found %d messages:\n\n", len(messages))
+	printf("\n📝 Found %d messages:\n\n", len(messages))
 
 	for _, msg := range messages {
 		if err := displayMessage(msg, userMap, verbose, noFormat, 0); err != nil {
@@ -280,12 +570,13 @@ func displayMessage(msg models.Message, userMap map[string]models.User, verbose,
 		}
 	}
 
-	// Parse timestamp
-	timestamp, err := strconv.ParseFloat(msg.Timestamp, 64)
+	// Parse timestamp in the --tz zone
+	timestamp, err := models.ParseSlackTimestamp(msg.Timestamp)
 	if err != nil {
-		timestamp = 0
+		timestamp = time.Unix(0, 0)
 	}
-	timeStr := time.Unix(int64(timestamp), 0).Format("2006-01-02 15:04:05")
+	timestamp = timestamp.In(displayLocation)
+	timeStr := timestamp.Format("2006-01-02 15:04:05")
 
 	// Create indentation for threads
 	indentStr := strings.Repeat("  ", indent)
@@ -293,27 +584,27 @@ func displayMessage(msg models.Message, userMap map[string]models.User, verbose,
 	// Format message header
 	if !noFormat {
 		if indent > 0 {
-			fmt.Printf("%s↳ ", indentStr)
+			printf("%s↳ ", indentStr)
 		}
-		fmt.Printf("👤 \033[1m%s\033[0m", userName)
+		printf("👤 \033[1m%s\033[0m", userName)
 		if verbose {
-			fmt.Printf(" (\033[90m%s\033[0m)", msg.User)
+			printf(" (\033[90m%s\033[0m)", msg.User)
 		}
-		fmt.Printf(" \033[90m%s\033[0m", timeStr)
+		printf(" \033[90m%s\033[0m", timeStr)
 		if msg.Edited != nil {
-			fmt.Printf(" \033[93m(edited)\033[0m")
+			printf(" \033[93m(edited)\033[0m")
 		}
 		fmt.Println()
 	} else {
 		if indent > 0 {
-			fmt.Printf("%s> ", indentStr)
+			printf("%s> ", indentStr)
 		}
-		fmt.Printf("[%s] %s", timeStr, userName)
+		printf("[%s] %s", timeStr, userName)
 		if verbose {
-			fmt.Printf(" (%s)", msg.User)
+			printf(" (%s)", msg.User)
 		}
 		if msg.Edited != nil {
-			fmt.Printf(" (edited)")
+			printf(" (edited)")
 		}
 		fmt.Println()
 	}
@@ -326,6 +617,9 @@ func displayMessage(msg models.Message, userMap map[string]models.User, verbose,
 	if text == "" && len(msg.Files) > 0 {
 		text = "[File]"
 	}
+	if msg.Omitted {
+		text = "[Omitted by filters]"
+	}
 	if text == "" {
 		text = "[No text content]"
 	}
@@ -333,15 +627,15 @@ func displayMessage(msg models.Message, userMap map[string]models.User, verbose,
 	// Display message text with indentation
 	lines := strings.Split(text, "\n")
 	for _, line := range lines {
-		fmt.Printf("%s  %s\n", indentStr, line)
+		printf("%s  %s\n", indentStr, line)
 	}
 
 	// Display attachments if verbose
 	if verbose && len(msg.Attachments) > 0 {
 		for _, att := range msg.Attachments {
-			fmt.Printf("%s  📎 %s\n", indentStr, att.Title)
+			printf("%s  📎 %s\n", indentStr, att.Title)
 			if att.Text != "" {
-				fmt.Printf("%s     %s\n", indentStr, att.Text)
+				printf("%s     %s\n", indentStr, att.Text)
 			}
 		}
 	}
@@ -349,7 +643,7 @@ func displayMessage(msg models.Message, userMap map[string]models.User, verbose,
 	// Display files if verbose
 	if verbose && len(msg.Files) > 0 {
 		for _, file := range msg.Files {
-			fmt.Printf("%s  📁 %s (%s)\n", indentStr, file.Name, file.Filetype)
+			printf("%s  📁 %s (%s)\n", indentStr, file.Name, file.Filetype)
 		}
 	}
 
@@ -359,12 +653,12 @@ func displayMessage(msg models.Message, userMap map[string]models.User, verbose,
 		for _, reaction := range msg.Reactions {
 			reactions = append(reactions, fmt.Sprintf(":%s: %d", reaction.Name, reaction.Count))
 		}
-		fmt.Printf("%s  👍 %s\n", indentStr, strings.Join(reactions, " "))
+		printf("%s  👍 %s\n", indentStr, strings.Join(reactions, " "))
 	}
 
 	// Display thread replies
 	if len(msg.Thread) > 0 {
-		fmt.Printf("%s  💬 %d replies:\n", indentStr, len(msg.Thread))
+		printf("%s  💬 %d replies:\n", indentStr, len(msg.Thread))
 		for _, reply := range msg.Thread {
 			if err := displayMessage(reply, userMap, verbose, noFormat, indent+1); err != nil {
 				return err
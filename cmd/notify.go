@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/usecase"
+)
+
+var (
+	notifyWebhook string
+	notifyChannel string
+)
+
+// addNotifyFlags registers the completion notification flags on a command
+func addNotifyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&notifyWebhook, "notify-webhook", "", "POST a JSON summary to this URL when the export completes or fails")
+	cmd.Flags().StringVar(&notifyChannel, "notify-channel", "", "Post a summary to this Slack channel when the export completes or fails")
+}
+
+// newNotifyService builds a notify service from the notification flags,
+// resolving --notify-channel to a channel ID
+func newNotifyService(slackClient *api.SlackClient) (*usecase.NotifyService, error) {
+	channelID := ""
+	if notifyChannel != "" {
+		channel, err := slackClient.GetChannelByName(context.Background(), strings.TrimPrefix(notifyChannel, "#"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid notify channel: %w", err)
+		}
+		channelID = channel.ID
+	}
+
+	service := usecase.NewNotifyService(slackClient, notifyWebhook, channelID)
+	service.SetTransport(api.Transport())
+	return service, nil
+}
+
+// sendNotification delivers a summary, reporting delivery problems without
+// changing the outcome of the export itself
+func sendNotification(notifyService *usecase.NotifyService, notifications []usecase.ExportNotification) {
+	if !notifyService.Enabled() {
+		return
+	}
+	if err := notifyService.Notify(context.Background(), notifications); err != nil {
+		appLogger.Warn("failed to send notification", "error", err)
+	}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/itcaat/slacker/internal/i18n"
+	"github.com/itcaat/slacker/internal/usecase"
+)
+
+// messagesOnStderr moves printf output to stderr for commands whose stdout
+// carries data, such as exports to "-"
+var messagesOnStderr bool
+
+// messageWriter returns where printf writes
+func messageWriter() io.Writer {
+	if messagesOnStderr {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// toStdout reports whether an --output value selects stdout: empty or "-"
+func toStdout(output string) bool {
+	return output == "" || output == usecase.StdoutOutput
+}
+
+// printf prints a message for people to stdout, translated into the --lang
+// language and without emoji when --no-emoji is set. Machine-readable output
+// such as JSON goes through fmt directly.
+func printf(format string, args ...interface{}) {
+	fmt.Fprint(messageWriter(), i18n.Sprintf(format, args...))
+}
+
+// eprintf is printf for status messages and errors on stderr
+func eprintf(format string, args ...interface{}) {
+	fmt.Fprint(os.Stderr, i18n.Sprintf(format, args...))
+}
+
+// fprintf is printf for messages written to w
+func fprintf(w io.Writer, format string, args ...interface{}) {
+	fmt.Fprint(w, i18n.Sprintf(format, args...))
+}
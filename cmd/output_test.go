@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/itcaat/slacker/internal/i18n"
+)
+
+// mojibake are the sequences UTF-8 emoji and punctuation turn into when they
+// are decoded as Latin-1 or Windows-1252 somewhere on the way
+var mojibake = []string{"ðŸ", "â€", "Ã", "Â", "�"}
+
+// TestOutputStrings checks every string literal of the commands and the TUI:
+// it must be valid UTF-8 without mojibake, and the ASCII fallback must know
+// every symbol it uses
+func TestOutputStrings(t *testing.T) {
+	for _, dir := range []string{".", "../internal/ui"} {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			checkStrings(t, file)
+		}
+	}
+}
+
+func checkStrings(t *testing.T, file string) {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, file, nil, 0)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", file, err)
+	}
+	ast.Inspect(parsed, func(node ast.Node) bool {
+		lit, ok := node.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return true
+		}
+		pos := fset.Position(lit.Pos())
+		if !utf8.ValidString(s) {
+			t.Errorf("%s: invalid UTF-8 in %q", pos, s)
+			return true
+		}
+		for _, bad := range mojibake {
+			if strings.Contains(s, bad) {
+				t.Errorf("%s: mojibake %q in %q", pos, bad, s)
+			}
+		}
+		if rendered := i18n.ToASCII(s); strings.Count(rendered, "?") > strings.Count(s, "?") {
+			t.Errorf("%s: the ASCII fallback has no replacement for a symbol in %q (%q)", pos, s, rendered)
+		}
+		return true
+	})
+}
+
+func TestPrintfASCII(t *testing.T) {
+	defer i18n.SetASCII(false)
+	i18n.SetASCII(true)
+
+	if got := i18n.Sprintf("✅ #%s: %d messages, %s -> %s\n", "general", 4, "1 KB", "a.json"); got != "[ok] #general: 4 messages, 1 KB -> a.json\n" {
+		t.Errorf("Unexpected ASCII output %q", got)
+	}
+	if got := i18n.Sprintf("\n⏱️  Duration: %s\n", "2s"); got != "\nDuration: 2s\n" {
+		t.Errorf("Unexpected ASCII output %q", got)
+	}
+}
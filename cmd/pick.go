@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/itcaat/slacker/internal/ui"
+	"github.com/itcaat/slacker/models"
+	"github.com/mattn/go-isatty"
+)
+
+// channelLister is the part of a message source the channel picker needs
+type channelLister interface {
+	GetChannels(ctx context.Context) ([]models.Channel, error)
+}
+
+// pickChannel lets the user choose a channel interactively when the command
+// line names none. Without a terminal on stdin and stdout it fails with
+// missing, as before, so scripts keep getting an error.
+func pickChannel(ctx context.Context, source channelLister, missing string) (*models.Channel, error) {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return nil, errors.New(missing)
+	}
+
+	channels, err := source.GetChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+	var active []models.Channel
+	for _, channel := range channels {
+		if !channel.IsArchived {
+			active = append(active, channel)
+		}
+	}
+
+	return ui.PickChannel(active)
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+type fakeChannelLister struct {
+	called bool
+}
+
+func (f *fakeChannelLister) GetChannels(ctx context.Context) ([]models.Channel, error) {
+	f.called = true
+	return []models.Channel{{ID: "C1", Name: "general"}}, nil
+}
+
+func TestPickChannelWithoutTerminal(t *testing.T) {
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	os.Stdin = r
+
+	lister := &fakeChannelLister{}
+	_, err = pickChannel(context.Background(), lister, "either --channel or --channel-id must be specified")
+	if err == nil || err.Error() != "either --channel or --channel-id must be specified" {
+		t.Errorf("Expected the missing flag error without a terminal, got %v", err)
+	}
+	if lister.called {
+		t.Error("Expected no channel lookup without a terminal")
+	}
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vbauerster/mpb/v8"
+	"github.com/vbauerster/mpb/v8/decor"
+
+	"github.com/itcaat/slacker/internal/i18n"
+	"github.com/itcaat/slacker/internal/logging"
+	"github.com/itcaat/slacker/internal/usecase"
+)
+
+// barTotal is the total of a channel bar; export progress is a fraction
+const barTotal = 1000
+
+// progressRenderer draws export progress on the terminal: a bar per channel
+// being exported and, for batch runs, an overall bar below them. Lines
+// printed through it appear above the bars, so warnings and results printed
+// mid-run do not tear them. A nil renderer draws nothing and prints as
+// printf does, which is what non-terminal output gets.
+type progressRenderer struct {
+	progress *mpb.Progress
+	overall  *mpb.Bar
+	verbose  bool
+
+	failed atomic.Int32
+
+	mu   sync.Mutex
+	bars map[string]*channelBar
+	done bool
+}
+
+// newProgressRenderer starts a renderer on the message output, with an
+// overall bar when channels is above zero. It returns nil unless the output
+// is a terminal.
+func newProgressRenderer(channels int, verbose bool) *progressRenderer {
+	out, ok := messageWriter().(*os.File)
+	if !ok || !isTerminal(out) {
+		return nil
+	}
+	r := &progressRenderer{
+		progress: mpb.New(mpb.WithOutput(out), mpb.WithWidth(30), mpb.WithRefreshRate(150*time.Millisecond)),
+		verbose:  verbose,
+		bars:     make(map[string]*channelBar),
+	}
+	if channels > 0 {
+		r.overall = r.progress.New(int64(channels), barStyle(),
+			mpb.BarPriority(math.MaxInt),
+			mpb.PrependDecorators(decor.Name(i18n.Sprintf("📦 All channels"), decor.WCSyncSpaceR)),
+			mpb.AppendDecorators(
+				decor.CountersNoUnit("%d/%d", decor.WCSyncSpace),
+				decor.Any(func(decor.Statistics) string {
+					if failed := r.failed.Load(); failed > 0 {
+						return i18n.Sprintf("  %d failed", failed)
+					}
+					return ""
+				}),
+			),
+		)
+	}
+	return r
+}
+
+// barStyle draws bars like the single-channel progress line did
+func barStyle() mpb.BarStyleComposer {
+	return mpb.BarStyle().Lbound("[").Filler(i18n.Text("█")).Tip(i18n.Text("█")).Padding(i18n.Text("░")).Rbound("]")
+}
+
+// ChannelStarted implements usecase.ChannelEvents: it adds a bar for the
+// channel
+func (r *progressRenderer) ChannelStarted(channel string) usecase.EventSink {
+	if r == nil {
+		return nil
+	}
+	b := &channelBar{renderer: r}
+	b.status = stageStatus("initializing")
+	b.bar = r.progress.New(barTotal, barStyle(),
+		mpb.BarRemoveOnComplete(),
+		mpb.PrependDecorators(decor.Name("#"+channel, decor.WCSyncSpaceR)),
+		mpb.AppendDecorators(
+			decor.Percentage(decor.WCSyncSpace),
+			decor.Any(func(decor.Statistics) string { return "  " + b.text() }),
+		),
+	)
+	r.mu.Lock()
+	r.bars[channel] = b
+	r.mu.Unlock()
+	return b
+}
+
+// ChannelFinished implements usecase.ChannelEvents: it removes the bar of
+// the channel and counts it in the overall bar
+func (r *progressRenderer) ChannelFinished(channel string, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	b := r.bars[channel]
+	delete(r.bars, channel)
+	r.mu.Unlock()
+
+	if err != nil {
+		r.failed.Add(1)
+	}
+	if b != nil {
+		if err != nil {
+			b.bar.Abort(true)
+		} else {
+			b.bar.SetCurrent(barTotal)
+		}
+	}
+	if r.overall != nil {
+		r.overall.Increment()
+	}
+}
+
+// Wait removes the bars left, e.g. by an interrupted run, and returns once
+// the output is flushed. Output printed afterwards goes to the terminal
+// directly.
+func (r *progressRenderer) Wait() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	left := r.bars
+	r.bars = nil
+	r.done = true
+	r.mu.Unlock()
+	for _, b := range left {
+		b.bar.Abort(true)
+	}
+	if r.overall != nil {
+		r.overall.Abort(true)
+	}
+	r.progress.Wait()
+}
+
+// printf prints a message above the bars, or with printf once they are gone
+func (r *progressRenderer) printf(format string, args ...interface{}) {
+	if r == nil || !r.write([]byte(i18n.Sprintf(format, args...))) {
+		printf(format, args...)
+	}
+}
+
+// Write implements io.Writer for log records: above the bars, or on stderr
+// once they are gone
+func (r *progressRenderer) Write(p []byte) (int, error) {
+	if r.write(p) {
+		return len(p), nil
+	}
+	return os.Stderr.Write(p)
+}
+
+// write writes p above the bars and reports whether they were still drawn
+func (r *progressRenderer) write(p []byte) bool {
+	r.mu.Lock()
+	done := r.done
+	r.mu.Unlock()
+	if done {
+		return false
+	}
+	_, err := r.progress.Write(p)
+	return !errors.Is(err, mpb.ErrDone)
+}
+
+// logger returns fallback writing above the bars while they are drawn, when
+// the log goes to the same terminal
+func (r *progressRenderer) logger(fallback *slog.Logger) *slog.Logger {
+	if r == nil || logFile != "" || !isTerminal(os.Stderr) {
+		return fallback
+	}
+	logger, err := logging.New(io.Writer(r), logLevel, logFormat)
+	if err != nil {
+		return fallback
+	}
+	return logger
+}
+
+// channelBar renders the events of one channel export on its bar
+type channelBar struct {
+	renderer *progressRenderer
+	bar      *mpb.Bar
+
+	mu     sync.Mutex
+	status string
+}
+
+// HandleEvent implements usecase.EventSink. Warnings are left to the log
+// sink, whose records are printed above the bars.
+func (b *channelBar) HandleEvent(event usecase.ExportEvent) {
+	progress := event.Snapshot()
+	var status string
+	switch e := event.(type) {
+	case usecase.Warning:
+		return
+	case usecase.RateLimited:
+		status = i18n.Sprintf("⏳ Rate limited by Slack, retrying in %s", e.Wait.Round(time.Second))
+	default:
+		status = stageStatus(progress.Stage)
+		if b.renderer.verbose {
+			if progress.MessagesTotal > 0 {
+				status += i18n.Sprintf(" - %d messages", progress.MessagesTotal)
+			}
+			if progress.ThreadsTotal > 0 {
+				status += i18n.Sprintf(" - %d/%d threads", progress.ThreadsCurrent, progress.ThreadsTotal)
+			}
+		}
+		status += formatETA(progress)
+		// The bar completes when the export is reported finished
+		b.bar.SetCurrent(min(int64(progress.Progress*barTotal), barTotal-1))
+	}
+	b.mu.Lock()
+	b.status = status
+	b.mu.Unlock()
+}
+
+// stageStatus describes an export stage after its bar
+func stageStatus(stage string) string {
+	return i18n.Sprintf("%s %s", getStageEmoji(stage), i18n.Sprintf(getStageDescription(stage)))
+}
+
+// text returns the status shown after the bar
+func (b *channelBar) text() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.status
+}
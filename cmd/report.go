@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Build reports across the channels of the workspace",
+	Long: `Build reports across all channels the token is a member of, for cleanup
+campaigns and reviews.
+
+Examples:
+  slacker report inactive-channels --older-than 180d
+  slacker report inactive-channels --older-than 52w --format csv -o stale.csv
+  slacker report membership --view matrix --format csv -o access-review.csv`,
+}
+
+// reportInactiveCmd represents the report inactive-channels command
+var reportInactiveCmd = &cobra.Command{
+	Use:   "inactive-channels",
+	Short: "List the channels without messages for a while",
+	Long: `List the channels whose last message is older than --older-than, the
+longest idle first. Each channel costs one request for the newest page of its
+history; joins and departures do not count as activity. Channels without any
+message count from their creation.
+
+Archived channels are skipped unless --include-archived is set. Channels whose
+history cannot be read are listed separately and left out of the CSV.
+
+Examples:
+  slacker report inactive-channels --older-than 180d
+  slacker report inactive-channels --older-than 52w --format csv -o stale.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runReportInactive(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// reportMembershipCmd represents the report membership command
+var reportMembershipCmd = &cobra.Command{
+	Use:   "membership",
+	Short: "List the members of channels for access reviews",
+	Long: `List who is a member of which channel using conversations.members, for
+access reviews. --view picks the layout: "users" lists the channels of each
+user, "channels" the members of each channel, and "matrix" has a row per user
+and a column per channel. JSON always holds both lists.
+
+All channels the token is a member of are reported, archived ones only with
+--include-archived, unless --channel names the channels. Deactivated users and
+bots are included and marked.
+
+Examples:
+  slacker report membership
+  slacker report membership --channel finance --channel payroll --view channels
+  slacker report membership --view matrix --format csv -o access-review.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runReportMembership(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	reportOlderThan string
+	reportArchived  bool
+	reportFormat    string
+	reportOutput    string
+	reportChannels  []string
+	reportView      string
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.AddCommand(reportInactiveCmd)
+	reportCmd.AddCommand(reportMembershipCmd)
+
+	reportInactiveCmd.Flags().StringVar(&reportOlderThan, "older-than", "180d", "List channels quiet for longer than this period, e.g. 180d, 26w or 720h")
+	reportInactiveCmd.Flags().BoolVar(&reportArchived, "include-archived", false, "Also check archived channels")
+	reportInactiveCmd.Flags().StringVarP(&reportFormat, "format", "f", "table", "Output format: table, json, csv")
+	reportInactiveCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Write the report to this file instead of stdout (- for stdout)")
+
+	registerValueCompletion(reportInactiveCmd, "format", "table", "json", "csv")
+
+	reportMembershipCmd.Flags().StringSliceVarP(&reportChannels, "channel", "c", nil, "Channel name to report (repeatable; default: all)")
+	reportMembershipCmd.Flags().BoolVar(&reportArchived, "include-archived", false, "Also report archived channels")
+	reportMembershipCmd.Flags().StringVar(&reportView, "view", usecase.MembershipByUser, "Layout: users, channels, matrix")
+	reportMembershipCmd.Flags().StringVarP(&reportFormat, "format", "f", "table", "Output format: table, json, csv")
+	reportMembershipCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Write the report to this file instead of stdout (- for stdout)")
+
+	registerChannelCompletion(reportMembershipCmd, "channel")
+	registerValueCompletion(reportMembershipCmd, "view", usecase.MembershipByUser, usecase.MembershipByChannel, usecase.MembershipMatrix)
+	registerValueCompletion(reportMembershipCmd, "format", "table", "json", "csv")
+}
+
+func runReportInactive(cmd *cobra.Command) error {
+	switch reportFormat {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("invalid format '%s'. Valid formats: table, json, csv", reportFormat)
+	}
+	olderThan, err := parsePeriod(reportOlderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than '%s': %w", reportOlderThan, err)
+	}
+
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+	auditLog, err := openAuditLog(cfg, slackClient)
+	if err != nil {
+		return err
+	}
+
+	service := usecase.NewReportService(slackClient)
+	service.SetLogger(appLogger)
+	report, err := service.InactiveChannels(cmd.Context(), usecase.InactiveOptions{
+		OlderThan:       olderThan,
+		IncludeArchived: reportArchived,
+		Now:             time.Now(),
+	}, func(done, total int) {
+		eprintf("\r🔍 Checked %d of %d channels", done, total)
+		if done == total {
+			eprintf("\n")
+		}
+	})
+	if err != nil {
+		return recordAudit(auditLog, reportAuditEntry(audit.ActionInactive, 0, err), err)
+	}
+	if err := recordAudit(auditLog, reportAuditEntry(audit.ActionInactive, len(report.Failed), nil), nil); err != nil {
+		return err
+	}
+	for _, channel := range report.Failed {
+		eprintf("⚠️  #%s: %s\n", channel.Name, channel.Error)
+	}
+
+	out, closeOut, err := reportWriter()
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	if err := writeInactiveReport(out, report); err != nil {
+		return err
+	}
+	return reportFailure(len(report.Failed), report.Checked)
+}
+
+// writeInactiveReport writes an inactive-channels report in --format
+func writeInactiveReport(out io.Writer, report *usecase.InactiveReport) error {
+	switch reportFormat {
+	case "json":
+		return writeReportJSON(out, report)
+	case "csv":
+		return usecase.WriteInactiveCSV(out, report)
+	}
+
+	fprintf(out, "💤 %d of %d channels quiet for more than %s\n\n", len(report.Channels), report.Checked, reportOlderThan)
+	for _, channel := range report.Channels {
+		last := "never"
+		if channel.LastMessage != nil {
+			last = channel.LastMessage.In(displayLocation).Format("2006-01-02")
+		}
+		fprintf(out, "  #%-30s %6d days  last %-10s  %4d members\n", channel.Name, channel.IdleDays, last, channel.Members)
+	}
+	return nil
+}
+
+// reportFailure makes a report with channels that could not be read exit
+// as a partial export, so scripts do not take it for a complete one
+func reportFailure(failed, total int) error {
+	if failed == 0 {
+		return nil
+	}
+	return models.NewExportError(models.ErrorCategoryPartialExport,
+		fmt.Sprintf("report is partial: %d of %d channels could not be read", failed, total), nil)
+}
+
+func runReportMembership(cmd *cobra.Command) error {
+	switch reportFormat {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("invalid format '%s'. Valid formats: table, json, csv", reportFormat)
+	}
+	switch reportView {
+	case usecase.MembershipByUser, usecase.MembershipByChannel, usecase.MembershipMatrix:
+	default:
+		return fmt.Errorf("invalid --view '%s'. Valid views: users, channels, matrix", reportView)
+	}
+
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+	auditLog, err := openAuditLog(cfg, slackClient)
+	if err != nil {
+		return err
+	}
+
+	service := usecase.NewReportService(slackClient)
+	service.SetLogger(appLogger)
+	report, err := service.Membership(cmd.Context(), usecase.MembershipOptions{
+		Channels:        reportChannels,
+		IncludeArchived: reportArchived,
+	}, func(done, total int) {
+		eprintf("\r👥 Read the members of %d of %d channels", done, total)
+		if done == total {
+			eprintf("\n")
+		}
+	})
+	if err != nil {
+		return recordAudit(auditLog, reportAuditEntry(audit.ActionMembers, 0, err), err)
+	}
+	if err := recordAudit(auditLog, reportAuditEntry(audit.ActionMembers, len(report.Failed), nil), nil); err != nil {
+		return err
+	}
+	for _, channel := range report.Failed {
+		eprintf("⚠️  #%s: %s\n", channel.Name, channel.Error)
+	}
+
+	out, closeOut, err := reportWriter()
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	if err := writeMembershipReport(out, report); err != nil {
+		return err
+	}
+	return reportFailure(len(report.Failed), len(report.Channels)+len(report.Failed))
+}
+
+// writeMembershipReport writes a membership report in --format and --view
+func writeMembershipReport(out io.Writer, report *usecase.MembershipReport) error {
+	switch reportFormat {
+	case "json":
+		return writeReportJSON(out, report)
+	case "csv":
+		return usecase.WriteMembershipCSV(out, report, reportView)
+	}
+
+	names := make(map[string]string, len(report.Users))
+	for _, user := range report.Users {
+		names[user.ID] = memberName(user)
+	}
+	fprintf(out, "👥 %d users in %d channels\n\n", len(report.Users), len(report.Channels))
+	switch reportView {
+	case usecase.MembershipByChannel:
+		for _, channel := range report.Channels {
+			members := make([]string, len(channel.Members))
+			for i, id := range channel.Members {
+				members[i] = names[id]
+			}
+			fprintf(out, "  #%s (%d): %s\n", channel.Name, len(channel.Members), strings.Join(members, ", "))
+		}
+	case usecase.MembershipMatrix:
+		fmt.Fprintf(out, "  %-24s", "")
+		for i := range report.Channels {
+			fmt.Fprintf(out, " %3d", i+1)
+		}
+		fmt.Fprintln(out)
+		for _, user := range report.Users {
+			member := make(map[string]bool, len(user.Channels))
+			for _, name := range user.Channels {
+				member[name] = true
+			}
+			fmt.Fprintf(out, "  %-24s", names[user.ID])
+			for _, channel := range report.Channels {
+				cell := "."
+				if member[channel.Name] {
+					cell = "x"
+				}
+				fmt.Fprintf(out, " %3s", cell)
+			}
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out)
+		for i, channel := range report.Channels {
+			fmt.Fprintf(out, "  %3d  #%s\n", i+1, channel.Name)
+		}
+	default:
+		for _, user := range report.Users {
+			fprintf(out, "  %-24s %3d  #%s\n", names[user.ID], len(user.Channels), strings.Join(user.Channels, ", #"))
+		}
+	}
+	return nil
+}
+
+// memberName names a user of a membership report, marking bots and
+// deactivated accounts
+func memberName(user usecase.MembershipUser) string {
+	name := user.ID
+	if user.Name != "" {
+		name = "@" + user.Name
+	}
+	switch {
+	case user.Deleted:
+		name += " (deactivated)"
+	case user.IsBot:
+		name += " (bot)"
+	}
+	return name
+}
+
+// reportAuditEntry describes a report run over all its channels for the
+// audit log
+func reportAuditEntry(action string, failed int, err error) audit.Entry {
+	entry := audit.Entry{Action: action, Output: reportOutput, Success: err == nil && failed == 0}
+	if toStdout(entry.Output) {
+		entry.Output = "stdout"
+	}
+	switch {
+	case err != nil:
+		entry.Error = err.Error()
+	case failed > 0:
+		entry.Error = fmt.Sprintf("%d channels could not be read", failed)
+	}
+	return entry
+}
+
+// reportWriter opens --output, or stdout without it. The returned function
+// closes the file.
+func reportWriter() (io.Writer, func(), error) {
+	if toStdout(reportOutput) {
+		return os.Stdout, func() {}, nil
+	}
+	file, err := os.Create(reportOutput)
+	if err != nil {
+		return nil, nil, models.NewExportError(models.ErrorCategoryIO, "failed to create output file", err)
+	}
+	return file, func() { file.Close() }, nil
+}
+
+// writeReportJSON writes a report as indented JSON
+func writeReportJSON(w io.Writer, report interface{}) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -2,13 +2,68 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/cache"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/i18n"
+	"github.com/itcaat/slacker/internal/logging"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
 )
 
-var cfgFile string
+var (
+	cfgFile       string
+	logLevel      string
+	logFormat     string
+	logFile       string
+	tokenTypeFlag string
+
+	proxyURL           string
+	caCertFile         string
+	insecureSkipVerify bool
+
+	// apiConfig holds the API timeout and pagination settings from the
+	// config file and the --api-timeout, --page-size and --thread-delay flags
+	apiConfig config.APIConfig
+
+	// noCache disables the channel and user list cache (--no-cache)
+	noCache     bool
+	cacheConfig config.CacheConfig
+
+	// tokenType is the parsed --token-type override for token selection
+	tokenType = models.TokenTypeAuto
+
+	// timezone is the --tz zone name; dateLocation is the zone --from/--to
+	// dates are read in (UTC unless a timezone is configured) and
+	// displayLocation the zone timestamps are rendered in
+	timezone        string
+	dateLocation    = time.UTC
+	displayLocation = time.Local
+
+	// appLogger is the structured logger configured by the --log-* flags
+	appLogger = slog.Default()
+
+	// lang, noEmoji and asciiOutput are the --lang, --no-emoji and --ascii
+	// output options
+	lang        string
+	noEmoji     bool
+	asciiOutput bool
+
+	// verboseOutput, debugMode and traceFile are the --verbose, --debug and
+	// --trace-file diagnostics options shared by all commands
+	verboseOutput bool
+	debugMode     bool
+	traceFile     string
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -23,6 +78,28 @@ Features:
 - Export channel histories to structured JSON
 - Thread-aware message organization
 - Secure OAuth2 authentication`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		if tokenType, err = models.ParseTokenType(tokenTypeFlag); err != nil {
+			return err
+		}
+		if err := setupDebug(cmd); err != nil {
+			return err
+		}
+		if err := setupLogging(); err != nil {
+			return err
+		}
+		if err := setupAPI(cmd); err != nil {
+			return err
+		}
+		if err := setupTimezone(); err != nil {
+			return err
+		}
+		if err := setupLanguage(); err != nil {
+			return err
+		}
+		return setupNetwork(cmd)
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
@@ -42,6 +119,24 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.slacker.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: text, json")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append logs to this file instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&tokenTypeFlag, "token-type", "auto", "Slack token to use: auto, bot, user")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "HTTP or SOCKS5 proxy URL for Slack connections (default: network.proxy or HTTPS_PROXY)")
+	rootCmd.PersistentFlags().StringVar(&caCertFile, "ca-cert", "", "PEM bundle of additional trusted root CAs")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "Disable TLS certificate verification (unsafe)")
+	rootCmd.PersistentFlags().Duration("api-timeout", 0, "Timeout for Slack API operations (default: api.timeout or 10s-60s per command)")
+	rootCmd.PersistentFlags().Int("page-size", 0, "Messages requested per history page, 1-1000 (default: api.page_size)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Always fetch channel and user lists from Slack instead of the disk cache")
+	rootCmd.PersistentFlags().Duration("thread-delay", 0, "Pause between paginated and thread requests (default: api.thread_delay)")
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", "", fmt.Sprintf("Language of command output: %s (default: ui.lang or $LANG)", strings.Join(i18n.Languages(), ", ")))
+	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Leave emoji out of command output (default: ui.emoji)")
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii", false, "Use only ASCII symbols in command output and the TUI, e.g. [ok] for ✅ (default: ui.ascii)")
+	rootCmd.PersistentFlags().BoolVarP(&verboseOutput, "verbose", "v", false, "Show detailed progress and output")
+	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Log at debug level, print Slack API debug output and imply --verbose (default: debug setting)")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", "", "Append every Slack API request and response, with tokens redacted, to this file")
+	rootCmd.PersistentFlags().StringVar(&timezone, "tz", "", "Timezone for displayed and exported timestamps, e.g. UTC or Europe/Berlin (default: timezone setting or local)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -71,3 +166,196 @@ func initConfig() {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
+
+// setupDebug applies --debug, or the debug setting, and --trace-file. Debug
+// mode lowers the log level to debug unless --log-level is given and turns
+// on verbose output.
+func setupDebug(cmd *cobra.Command) error {
+	if !cmd.Flags().Changed("debug") && viper.GetBool("debug") {
+		debugMode = true
+	}
+	if debugMode {
+		verboseOutput = true
+		if !cmd.Flags().Changed("log-level") {
+			logLevel = "debug"
+		}
+	}
+
+	if traceFile != "" {
+		f, err := os.OpenFile(traceFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open trace file: %w", err)
+		}
+		api.SetTrace(f)
+	}
+	return nil
+}
+
+// setupLogging configures the shared logger from the --log-* flags and
+// installs it as the slog default used by the Slack client and services
+func setupLogging() error {
+	var w io.Writer = os.Stderr
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		w = f
+	}
+
+	logger, err := logging.New(w, logLevel, logFormat)
+	if err != nil {
+		return err
+	}
+
+	appLogger = logger
+	slog.SetDefault(logger)
+	return nil
+}
+
+// setupNetwork applies proxy and TLS settings from flags and the network
+// section of the config file to Slack connections and to the uploads,
+// webhooks and other services slacker talks to
+func setupNetwork(cmd *cobra.Command) error {
+	var network config.NetworkConfig
+	if cfg, err := config.NewManager().Load(); err == nil {
+		network = cfg.Network
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("proxy") {
+		network.Proxy = proxyURL
+	}
+	if flags.Changed("ca-cert") {
+		network.CACert = caCertFile
+	}
+	if flags.Changed("insecure-skip-verify") {
+		network.InsecureSkipVerify = insecureSkipVerify
+	}
+
+	if network.InsecureSkipVerify {
+		eprintf("⚠️  WARNING: TLS certificate verification is DISABLED. Slack traffic and tokens can be intercepted.\n")
+		appLogger.Warn("TLS certificate verification disabled")
+	}
+
+	if err := api.ConfigureNetwork(api.NetworkConfig{
+		ProxyURL:           network.Proxy,
+		CACertFile:         network.CACert,
+		InsecureSkipVerify: network.InsecureSkipVerify,
+	}); err != nil {
+		return err
+	}
+	usecase.RegisterNetworkSinks(api.Transport())
+	return nil
+}
+
+// setupAPI resolves API timeout, pagination and cache settings from the
+// config file and flags
+func setupAPI(cmd *cobra.Command) error {
+	if cfg, err := config.NewManager().Load(); err == nil {
+		apiConfig = cfg.API
+		cacheConfig = cfg.Cache
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("api-timeout") {
+		apiConfig.Timeout, _ = flags.GetDuration("api-timeout")
+	}
+	if flags.Changed("page-size") {
+		apiConfig.PageSize, _ = flags.GetInt("page-size")
+	}
+	if flags.Changed("thread-delay") {
+		apiConfig.ThreadDelay, _ = flags.GetDuration("thread-delay")
+	}
+
+	if apiConfig.Timeout < 0 {
+		return fmt.Errorf("api timeout must not be negative")
+	}
+	if apiConfig.PageSize < 0 || apiConfig.PageSize > 1000 {
+		return fmt.Errorf("page size must be between 1 and 1000")
+	}
+	if apiConfig.ThreadDelay < 0 {
+		return fmt.Errorf("thread delay must not be negative")
+	}
+	// An unset delay uses the defaults; --thread-delay 0 turns it off
+	if flags.Changed("thread-delay") && apiConfig.ThreadDelay == 0 {
+		apiConfig.ThreadDelay = models.NoThreadDelay
+	}
+	return nil
+}
+
+// setupTimezone applies --tz, falling back to the timezone setting, to all
+// rendered timestamps and to --from/--to dates
+func setupTimezone() error {
+	name := timezone
+	if name == "" {
+		name = viper.GetString("timezone")
+	}
+	if name == "" {
+		return nil
+	}
+
+	loc, err := models.LoadTimezone(name)
+	if err != nil {
+		return err
+	}
+	displayLocation = loc
+	dateLocation = loc
+	return nil
+}
+
+// setupLanguage selects the language of command output from --lang, ui.lang
+// or the environment, turns emoji off for --no-emoji or ui.emoji: false and
+// the ASCII fallback on for --ascii or ui.ascii
+func setupLanguage() error {
+	name := lang
+	if name == "" {
+		name = viper.GetString("ui.lang")
+	}
+	language := i18n.Detect()
+	if name != "" {
+		var err error
+		if language, err = i18n.Parse(name); err != nil {
+			return err
+		}
+	}
+	i18n.SetLanguage(language)
+	// ui.emoji defaults to true; its default is not registered yet
+	i18n.SetEmoji(!noEmoji && (!viper.IsSet("ui.emoji") || viper.GetBool("ui.emoji")))
+	i18n.SetASCII(asciiOutput || viper.GetBool("ui.ascii"))
+	return nil
+}
+
+// historyPageSize returns the configured page size for interactive history
+// requests, defaulting to 200
+func historyPageSize() int {
+	if apiConfig.PageSize > 0 {
+		return apiConfig.PageSize
+	}
+	return 200
+}
+
+// newSlackClient creates a Slack client with the configured page size,
+// slack-go debug output in --debug mode and, unless --no-cache is given, the
+// channel and user list cache
+func newSlackClient(token string) *api.SlackClient {
+	client := api.NewSlackClient(token, debugMode)
+	// setupAPI has checked the page size already
+	if err := client.SetPageSize(apiConfig.PageSize); err != nil {
+		appLogger.Warn("ignoring page size", "error", err)
+	}
+	if !noCache {
+		if dir, err := cache.DefaultDir(); err == nil {
+			client.SetCache(cache.New(dir), cacheConfig.ChannelsTTL, cacheConfig.UsersTTL)
+		}
+	}
+	return client
+}
+
+// apiTimeout returns the configured API timeout, or def when none is set
+func apiTimeout(def time.Duration) time.Duration {
+	if apiConfig.Timeout > 0 {
+		return apiConfig.Timeout
+	}
+	return def
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/schema"
+	"github.com/itcaat/slacker/models"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of export files",
+	Long: `Print the JSON Schema (draft 2020-12) of slacker export files, generated from the
+export data structures. Use it to validate exports or to generate typed clients in
+other languages. Every export names its schema in export_info.schema_url and
+export_info.schema_version.
+
+Examples:
+  slacker schema > channel-export.schema.json
+  slacker schema --type index --output export-index.schema.json
+  npx ajv-cli validate -s channel-export.schema.json -d general-export.json --spec=draft2020`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSchema(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	schemaType   string
+	schemaOutput string
+)
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().StringVar(&schemaType, "type", "export", "Schema to print: export (channel export files), index (split export index files)")
+	schemaCmd.Flags().StringVarP(&schemaOutput, "output", "o", "", "Write the schema to this file instead of stdout (- for stdout)")
+
+	registerValueCompletion(schemaCmd, "type", "export", "index")
+}
+
+func runSchema() error {
+	data, err := generateSchema(schemaType)
+	if err != nil {
+		return err
+	}
+	if toStdout(schemaOutput) {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(schemaOutput, data, 0644); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to write schema", err)
+	}
+	printf("📐 Wrote %s schema to %s\n", schemaType, schemaOutput)
+	return nil
+}
+
+// generateSchema returns the indented JSON Schema of the export file kind
+func generateSchema(kind string) ([]byte, error) {
+	var doc *schema.Schema
+	switch kind {
+	case "export":
+		doc = schema.Generate(models.ChannelExport{}, models.ExportSchemaURL, "Slacker channel export",
+			"A Slack channel history exported by slacker, schema version "+models.ExportSchemaVersion)
+	case "index":
+		doc = schema.Generate(models.ExportIndex{}, models.IndexSchemaURL, "Slacker export index",
+			"The index file of a split slacker export, schema version "+models.ExportSchemaVersion)
+	default:
+		return nil, fmt.Errorf("invalid schema type '%s'. Valid types: export, index", kind)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
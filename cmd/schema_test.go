@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGenerateSchemaMatchesPublished(t *testing.T) {
+	published := map[string]string{
+		"export": "../schema/channel-export.schema.json",
+		"index":  "../schema/export-index.schema.json",
+	}
+	for kind, path := range published {
+		generated, err := generateSchema(kind)
+		if err != nil {
+			t.Fatalf("Expected no error for %s, got %v", kind, err)
+		}
+		committed, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(generated, committed) {
+			t.Errorf("%s is out of date; run `slacker schema --type %s -o %s`", path, kind, path[3:])
+		}
+	}
+
+	if _, err := generateSchema("channels"); err == nil {
+		t.Error("Expected an error for an unknown schema type")
+	}
+}
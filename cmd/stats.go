@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/i18n"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats <export|->",
+	Short: "Show the statistics of an export",
+	Long: `Print the statistics of an export written by 'slacker export' (optionally
+gzip-compressed): message, thread and reaction counts, mentions, thread
+activity, and the storage used by shared files per file type and per user. File
+and mention statistics are recomputed from the messages, so they are also shown
+for older exports. "-" reads the export from stdin.
+
+--dot writes who mentions whom as a Graphviz graph.
+
+Examples:
+  slacker stats exports/general-export-20240115-103000.json
+  slacker stats archive/general.json.gz --json | jq '.file_types'
+  slacker stats general.json --dot - | dot -Tsvg > mentions.svg`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runStats(args[0]); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// statsCompareCmd represents the stats compare command
+var statsCompareCmd = &cobra.Command{
+	Use:   "compare [export...]",
+	Short: "Compare the activity of channels side by side",
+	Long: `Compare two or more channels side by side: message volume, active users,
+how fast and how often threads are answered, and how many participants each
+pair of channels shares. Useful to decide whether channels should be merged.
+
+Channels come from the Slack API with --channel, which is repeated once per
+channel, from the local message store with --offline, or from existing
+exports given as arguments. --last bounds the period compared; for exports it
+only applies when given.
+
+Examples:
+  slacker stats compare --channel support --channel help --last 90d
+  slacker stats compare --channel eng --channel dev --channel tech --json
+  slacker stats compare exports/support.json exports/help.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runStatsCompare(cmd, args); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	statsJSON bool
+	statsDOT  string
+
+	compareChannels []string
+	compareLast     string
+	compareThreads  bool
+	compareOffline  bool
+	compareOutput   string
+)
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.AddCommand(statsCompareCmd)
+
+	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the statistics as JSON")
+	statsCmd.Flags().StringVar(&statsDOT, "dot", "", "Write the mention graph in Graphviz DOT format to this file (- for stdout)")
+	statsCmd.MarkFlagsMutuallyExclusive("json", "dot")
+
+	statsCompareCmd.Flags().StringSliceVarP(&compareChannels, "channel", "c", nil, "Channel name to compare (repeatable) instead of reading exports")
+	statsCompareCmd.Flags().StringVar(&compareLast, "last", "90d", "Only messages posted within this period, e.g. 90d, 4w or 12h (0 = all history)")
+	statsCompareCmd.Flags().BoolVar(&compareThreads, "threads", true, "Include thread replies; without them response times are not measured")
+	statsCompareCmd.Flags().BoolVar(&compareOffline, "offline", false, "Read the channels from the local message store (see 'slacker sync')")
+	statsCompareCmd.Flags().BoolVar(&statsJSON, "json", false, "Print the comparison as JSON")
+	statsCompareCmd.Flags().StringVarP(&compareOutput, "output", "o", "", "Write the comparison to this file instead of stdout (- for stdout)")
+
+	registerChannelCompletion(statsCompareCmd, "channel")
+}
+
+func runStats(input string) error {
+	export, err := usecase.ReadExportFile(input)
+	if err != nil {
+		return err
+	}
+	usecase.ApplyFileStatistics(export)
+	usecase.ApplyMentionStatistics(export)
+	stats := export.Statistics
+
+	if statsDOT != "" {
+		if err := writeMentionGraph(statsDOT, export); err != nil {
+			return err
+		}
+		if toStdout(statsDOT) {
+			return nil
+		}
+	}
+
+	if statsJSON {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal statistics to JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printf("📢 #%s, exported %s\n\n", export.Channel.Name, export.ExportInfo.ExportedAt.In(displayLocation).Format("2006-01-02 15:04"))
+	printStatistics(stats)
+	printTopReactions(stats)
+	printMentions(stats.Mentions, export.Users)
+	printFileUsage(stats, export.Users)
+	if statsDOT != "" && stats.Mentions != nil {
+		printf("\n🕸️  Wrote the mention graph (%d edges) to %s\n", len(stats.Mentions.Graph), statsDOT)
+	}
+	return nil
+}
+
+// writeMentionGraph writes the mention graph of export to output, a file or
+// stdout
+func writeMentionGraph(output string, export *models.ChannelExport) error {
+	var out io.Writer = os.Stdout
+	if !toStdout(output) {
+		file, err := os.Create(output)
+		if err != nil {
+			return models.NewExportError(models.ErrorCategoryIO, "failed to create output file", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := usecase.WriteMentionGraph(out, export.Statistics.Mentions, export.Users); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to write mention graph", err)
+	}
+	return nil
+}
+
+// printMentions prints the most mentioned users and the most frequent
+// mentions between two users
+func printMentions(mentions *models.MentionStats, users map[string]models.ExportUser) {
+	if mentions == nil {
+		return
+	}
+	name := func(id string) string {
+		if user, ok := users[id]; ok && user.Name != "" {
+			return "@" + user.Name
+		}
+		return id
+	}
+
+	type mentioned struct {
+		id    string
+		count int
+	}
+	var ranked []mentioned
+	for id, byUser := range mentions.ByUser {
+		if byUser.MentionedBy > 0 {
+			ranked = append(ranked, mentioned{id, byUser.MentionedBy})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].count != ranked[j].count {
+			return ranked[i].count > ranked[j].count
+		}
+		return ranked[i].id < ranked[j].id
+	})
+	if len(ranked) > 0 {
+		printf("\n📣 Most Mentioned:\n")
+		for i, user := range ranked {
+			if i >= 10 {
+				break
+			}
+			printf("   %-20s %5d\n", name(user.id), user.count)
+		}
+	}
+
+	if len(mentions.Graph) > 0 {
+		printf("\n🔀 Top Mentions:\n")
+		for i, edge := range mentions.Graph {
+			if i >= 10 {
+				break
+			}
+			printf("   %s → %s: %d\n", name(edge.From), name(edge.To), edge.Count)
+		}
+	}
+}
+
+func runStatsCompare(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 && (len(compareChannels) > 0 || compareOffline) {
+		return fmt.Errorf("export files cannot be combined with --channel or --offline")
+	}
+	if len(args)+len(compareChannels) < 2 {
+		return fmt.Errorf("at least two channels are needed: repeat --channel or give two export files")
+	}
+	period, err := parsePeriod(compareLast)
+	if err != nil {
+		return fmt.Errorf("invalid --last '%s': %w", compareLast, err)
+	}
+	now := time.Now().In(displayLocation)
+	var since *time.Time
+	if period > 0 {
+		from := now.Add(-period)
+		since = &from
+	}
+
+	var exports []*models.ChannelExport
+	var usage []api.MethodUsage
+	if len(args) > 0 {
+		for _, input := range args {
+			exportData, err := usecase.ReadExportFile(input)
+			if err != nil {
+				return err
+			}
+			if !compareThreads {
+				exportData.Messages = withoutReplies(exportData.Messages)
+			}
+			exports = append(exports, exportData)
+		}
+		// Exports cover their own date range; --last only narrows it when given
+		if !cmd.Flags().Changed("last") {
+			since = nil
+		}
+	} else if exports, usage, err = fetchComparison(cmd.Context(), since); err != nil {
+		return err
+	}
+	comparison := usecase.CompareChannels(exports, since, now)
+
+	var out io.Writer = os.Stdout
+	if !toStdout(compareOutput) {
+		file, err := os.Create(compareOutput)
+		if err != nil {
+			return models.NewExportError(models.ErrorCategoryIO, "failed to create output file", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if statsJSON {
+		data, err := json.MarshalIndent(comparison, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal comparison to JSON: %w", err)
+		}
+		if _, err := out.Write(append(data, '\n')); err != nil {
+			return err
+		}
+		// The usage must not end up in the JSON on stdout
+		messagesOnStderr = toStdout(compareOutput)
+	} else {
+		writeComparison(out, comparison)
+	}
+	printAPIUsage(usage)
+	return nil
+}
+
+// fetchComparison exports each --channel to a temporary file and reads the
+// exports back, so fetching shares the export's pagination, retries and
+// date handling. It also returns the Slack API calls made.
+func fetchComparison(ctx context.Context, since *time.Time) ([]*models.ChannelExport, []api.MethodUsage, error) {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil && !compareOffline {
+		return nil, nil, err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+
+	var source usecase.MessageClientInterface = slackClient
+	auditClient := slackClient
+	if compareOffline {
+		st, err := openStore(true)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer st.Close()
+		source = st
+		auditClient = nil
+	}
+	auditLog, err := openAuditLog(cfg, auditClient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "slacker-compare-")
+	if err != nil {
+		return nil, nil, models.NewExportError(models.ErrorCategoryIO, "failed to create temporary directory", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var exports []*models.ChannelExport
+	for i, name := range compareChannels {
+		name = strings.TrimPrefix(name, "#")
+		channel, err := source.GetChannelByName(ctx, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find channel '%s': %w", name, err)
+		}
+
+		eprintf("🔄 Collecting messages from #%s...\n", channel.Name)
+		options := models.ExportOptions{
+			ChannelID:      channel.ID,
+			ChannelName:    channel.Name,
+			IncludeThreads: compareThreads,
+			DateFrom:       since,
+			OutputFile:     filepath.Join(dir, fmt.Sprintf("export-%d.json", i)),
+			Format:         "json-compact",
+			PageSize:       apiConfig.PageSize,
+			ThreadDelay:    apiConfig.ThreadDelay,
+			Location:       displayLocation,
+		}
+		service := usecase.NewExportService(source, getVersion())
+		service.SetLogger(appLogger)
+		result, err := service.ExportChannel(ctx, options, usecase.NewLogSink(appLogger))
+
+		// The temporary export is an implementation detail; the audit log
+		// names where the comparison went
+		entry := audit.ForExport(audit.ActionStats, options, result, err)
+		entry.Output = compareOutput
+		if toStdout(entry.Output) {
+			entry.Output = "stdout"
+		}
+		if err := recordAudit(auditLog, entry, err); err != nil {
+			return nil, nil, err
+		}
+		exportData, err := usecase.ReadExportFile(result.OutputFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		exports = append(exports, exportData)
+	}
+	return exports, slackClient.Usage(), nil
+}
+
+// writeComparison prints the channels of comparison as columns, followed by
+// the participant overlap of each pair
+func writeComparison(w io.Writer, comparison *usecase.ChannelComparison) {
+	if comparison.From != nil {
+		fprintf(w, "📊 Channel comparison since %s\n\n", comparison.From.In(displayLocation).Format("2006-01-02"))
+	} else {
+		fprintf(w, "📊 Channel comparison\n\n")
+	}
+
+	row := func(label string, value func(usecase.ChannelActivity) string) {
+		fmt.Fprintf(w, "   %-24s", i18n.Sprintf(label))
+		for _, channel := range comparison.Channels {
+			fmt.Fprintf(w, " %16s", value(channel))
+		}
+		fmt.Fprintln(w)
+	}
+	row("", func(c usecase.ChannelActivity) string { return "#" + c.Channel })
+	row("Messages", func(c usecase.ChannelActivity) string { return fmt.Sprint(c.Messages) })
+	row("Thread replies", func(c usecase.ChannelActivity) string { return fmt.Sprint(c.Replies) })
+	row("Messages per day", func(c usecase.ChannelActivity) string { return fmt.Sprintf("%.1f", c.MessagesPerDay) })
+	row("Active days", func(c usecase.ChannelActivity) string { return fmt.Sprintf("%d/%d", c.ActiveDays, c.Days) })
+	row("Active users", func(c usecase.ChannelActivity) string { return fmt.Sprint(c.Participants) })
+	row("Answered threads", func(c usecase.ChannelActivity) string { return fmt.Sprintf("%d/%d", c.Answered, c.Threads) })
+	row("Median first reply", func(c usecase.ChannelActivity) string { return compareDuration(c, c.FirstReply.Median) })
+	row("P90 first reply", func(c usecase.ChannelActivity) string { return compareDuration(c, c.FirstReply.P90) })
+
+	if len(comparison.Overlap) > 0 {
+		fprintf(w, "\n👥 Participant Overlap:\n")
+	}
+	for _, overlap := range comparison.Overlap {
+		fprintf(w, "   #%s ↔ #%s: %d shared (%.0f%%), %d only in #%s, %d only in #%s\n",
+			overlap.Channels[0], overlap.Channels[1], overlap.Shared, overlap.Similarity*100,
+			overlap.OnlyFirst, overlap.Channels[0], overlap.OnlySecond, overlap.Channels[1])
+	}
+}
+
+// compareDuration formats a first-reply time of channel, or "-" when none of
+// its threads were answered
+func compareDuration(channel usecase.ChannelActivity, d time.Duration) string {
+	if channel.Answered == 0 {
+		return "-"
+	}
+	return roundDuration(d).String()
+}
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/store"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Keep a local copy of channel history up to date",
+	Long: `Sync channel history into a local message store (~/.slacker/messages.db).
+The first run fetches the full history of each channel; later runs fetch only
+messages posted since the previous sync. Commands that support --offline then
+read from the store instead of calling the Slack API.
+
+New replies to threads that were already synced are not picked up by a delta
+sync; run with --full to refresh them.
+
+With --follow, sync keeps running after the fetch and applies new, edited and
+deleted messages and added or removed reactions as Slack reports them over
+Socket Mode, which requires an app-level token (slack.app_token or
+SLACKER_SLACK_APP_TOKEN). The version a message had before an edit or deletion
+is kept in the store; list it with 'slacker sync revisions'.
+
+Examples:
+  slacker sync --channel general --channel random   # Sync two channels
+  slacker sync --channel general --threads          # Include thread replies
+  slacker sync --channel general --full             # Refetch the whole history
+  slacker sync --channel general --follow          # Keep following edits and deletions
+  slacker sync status                               # Show what is stored
+  slacker sync revisions --channel general          # Show edited and deleted messages
+  slacker export --channel general --offline        # Export from the store`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSync(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// syncStatusCmd represents the sync status command
+var syncStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the channels in the local message store",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := showSyncStatus(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+// syncRevisionsCmd represents the sync revisions command
+var syncRevisionsCmd = &cobra.Command{
+	Use:   "revisions",
+	Short: "Show earlier versions of edited and deleted messages",
+	Long: `Show the versions of messages recorded by 'slacker sync --follow' before they
+were edited or deleted, oldest message first.
+
+Examples:
+  slacker sync revisions --channel general
+  slacker sync revisions --channel general --ts 1704067200.123456
+  slacker sync revisions --channel general --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := showSyncRevisions(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	syncChannels []string
+	syncThreads  bool
+	syncFull     bool
+	syncJSON     bool
+	syncFollow   bool
+	syncTS       string
+)
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+	syncCmd.AddCommand(syncStatusCmd)
+	syncCmd.AddCommand(syncRevisionsCmd)
+
+	syncCmd.Flags().StringSliceVarP(&syncChannels, "channel", "c", nil, "Channel name to sync (repeatable; default: channels already in the store)")
+	syncCmd.Flags().BoolVar(&syncThreads, "threads", false, "Include thread replies")
+	syncCmd.Flags().BoolVar(&syncFull, "full", false, "Refetch the whole history instead of only new messages")
+	syncCmd.Flags().BoolVar(&syncJSON, "json", false, "Print the sync results as JSON to stdout")
+	syncCmd.Flags().BoolVar(&syncFollow, "follow", false, "Keep running and apply message edits, deletions and reactions via Socket Mode")
+
+	syncStatusCmd.Flags().BoolVar(&syncJSON, "json", false, "Print the store status as JSON to stdout")
+
+	syncRevisionsCmd.Flags().StringSliceVarP(&syncChannels, "channel", "c", nil, "Channel name to show revisions of (required)")
+	syncRevisionsCmd.Flags().StringVar(&syncTS, "ts", "", "Show only the revisions of the message with this timestamp")
+	syncRevisionsCmd.Flags().BoolVar(&syncJSON, "json", false, "Print the revisions as JSON to stdout")
+
+	registerChannelCompletion(syncCmd, "channel")
+	registerChannelCompletion(syncRevisionsCmd, "channel")
+}
+
+// openStore opens the local message store at its default path
+func openStore(readOnly bool) (*store.Store, error) {
+	path, err := store.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return store.Open(path, readOnly)
+}
+
+func runSync(cmd *cobra.Command) error {
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+	appToken := configManager.GetAppToken()
+	if syncFollow && appToken == "" {
+		return fmt.Errorf("--follow requires an app-level token for Socket Mode; set slack.app_token or SLACKER_SLACK_APP_TOKEN")
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+
+	st, err := openStore(false)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	// Without --channel, refresh every channel that was synced before
+	channels := syncChannels
+	if len(channels) == 0 {
+		states, err := st.States()
+		if err != nil {
+			return fmt.Errorf("failed to read store: %w", err)
+		}
+		for _, state := range states {
+			channels = append(channels, state.Channel)
+		}
+		if len(channels) == 0 {
+			return fmt.Errorf("the local store is empty; specify channels with --channel")
+		}
+	}
+
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+	auditLog, err := openAuditLog(cfg, slackClient)
+	if err != nil {
+		return err
+	}
+
+	syncService := usecase.NewSyncService(slackClient, st)
+	syncService.SetLogger(appLogger)
+
+	if !syncJSON {
+		printf("🔄 Syncing %d channel(s)...\n", len(channels))
+	}
+
+	results, err := syncService.Sync(cmd.Context(), usecase.SyncOptions{
+		Channels:       channels,
+		IncludeThreads: syncThreads,
+		Full:           syncFull,
+		ThreadDelay:    apiConfig.ThreadDelay,
+	})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+		entry := audit.Entry{
+			Action:   audit.ActionSync,
+			Channel:  result.Channel,
+			Output:   st.Path(),
+			Messages: result.NewMessages,
+			Success:  result.Error == "",
+			Error:    result.Error,
+		}
+		if err := recordAudit(auditLog, entry, nil); err != nil {
+			return err
+		}
+	}
+
+	if syncJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal results: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, result := range results {
+			if result.Error != "" {
+				printf("❌ #%s: %s\n", result.Channel, result.Error)
+				continue
+			}
+			printf("✅ #%s: %d new, %d stored (%v)\n", result.Channel, result.NewMessages, result.Total, result.Duration.Round(time.Millisecond))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d channel(s) failed to sync", failed, len(results))
+	}
+	if syncFollow {
+		return followSync(syncService, slackClient, appToken, channels, auditLog, st.Path())
+	}
+	return nil
+}
+
+// followSync applies message events of channels to the store until Ctrl+C.
+// The audit log gets the number of events applied to each channel once it
+// stops.
+func followSync(syncService *usecase.SyncService, stream usecase.EventStreamInterface, appToken string, channels []string, auditLog *audit.Log, storePath string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	applied := make(map[string]int)
+	var order []string
+
+	encoder := json.NewEncoder(os.Stdout)
+	if !syncJSON {
+		eprintf("👀 Following %d channel(s) via Socket Mode (Ctrl+C to stop)...\n", len(channels))
+	}
+	err := syncService.Follow(ctx, stream, appToken, channels, func(event models.MessageEvent, revised bool) {
+		if _, ok := applied[event.ChannelID]; !ok {
+			order = append(order, event.ChannelID)
+		}
+		applied[event.ChannelID]++
+		if syncJSON {
+			if err := encoder.Encode(event); err != nil {
+				eprintf("Warning: Failed to encode event %s: %v\n", event.TS, err)
+			}
+			return
+		}
+		switch event.Kind {
+		case models.EventMessagePosted:
+			printf("➕ %s new message %s\n", event.At.Local().Format("15:04:05"), event.TS)
+		case models.EventMessageChanged:
+			if revised {
+				printf("✏️  %s edited message %s\n", event.At.Local().Format("15:04:05"), event.TS)
+			}
+		case models.EventMessageDeleted:
+			printf("🗑️  %s deleted message %s\n", event.At.Local().Format("15:04:05"), event.TS)
+		case models.EventReactionAdded:
+			printf("👍 %s :%s: added to %s\n", event.At.Local().Format("15:04:05"), event.Reaction, event.TS)
+		case models.EventReactionRemoved:
+			printf("👎 %s :%s: removed from %s\n", event.At.Local().Format("15:04:05"), event.Reaction, event.TS)
+		}
+	})
+	for _, channelID := range order {
+		entry := audit.Entry{
+			Action:    audit.ActionSync,
+			ChannelID: channelID,
+			Output:    storePath,
+			Messages:  applied[channelID],
+			Success:   err == nil,
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		err = recordAudit(auditLog, entry, err)
+	}
+	return err
+}
+
+func showSyncRevisions(cmd *cobra.Command) error {
+	if len(syncChannels) != 1 {
+		return fmt.Errorf("specify one channel with --channel")
+	}
+
+	st, err := openStore(true)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	channel, err := st.GetChannelByName(cmd.Context(), syncChannels[0])
+	if err != nil {
+		return err
+	}
+	revisions, err := st.Revisions(channel.ID, syncTS)
+	if err != nil {
+		return fmt.Errorf("failed to read store: %w", err)
+	}
+
+	if syncJSON {
+		if revisions == nil {
+			revisions = []store.Revision{}
+		}
+		data, err := json.MarshalIndent(revisions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal revisions: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(revisions) == 0 {
+		printf("No edited or deleted messages recorded for #%s. Run 'slacker sync --follow' to record them.\n", channel.Name)
+		return nil
+	}
+
+	printf("📝 %d revision(s) in #%s:\n\n", len(revisions), channel.Name)
+	for _, revision := range revisions {
+		printf("  %s  %-7s %s\n", revision.TS, revision.Kind, revision.At.Local().Format("2006-01-02 15:04:05"))
+		printf("    %s\n", revision.Message.Text)
+	}
+	return nil
+}
+
+func showSyncStatus(cmd *cobra.Command) error {
+	st, err := openStore(true)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	states, err := st.States()
+	if err != nil {
+		return fmt.Errorf("failed to read store: %w", err)
+	}
+
+	if syncJSON {
+		data, err := json.MarshalIndent(states, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal status: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(states) == 0 {
+		printf("The local store is empty. Run 'slacker sync --channel <name>' first.\n")
+		return nil
+	}
+
+	printf("📦 %d channel(s) in the local store:\n\n", len(states))
+	for _, state := range states {
+		printf("  #%-24s %8d messages  synced %s\n", state.Channel, state.Messages, state.SyncedAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/telemetry"
+)
+
+var (
+	metricsPush  string
+	otelEndpoint string
+)
+
+// addTelemetryFlags registers the metrics and tracing flags on a command
+func addTelemetryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&metricsPush, "metrics-push", "", "Push Prometheus metrics to this Pushgateway URL after each run")
+	cmd.Flags().StringVar(&otelEndpoint, "otel-endpoint", "", "Export OpenTelemetry traces to this OTLP/HTTP endpoint (default: $OTEL_EXPORTER_OTLP_ENDPOINT)")
+}
+
+// startTelemetry logs metric errors to the application log and installs the
+// tracer configured by flags or the standard OTEL_* environment variables
+func startTelemetry() {
+	telemetry.Default.SetLogger(appLogger)
+
+	endpoint := otelEndpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint == "" {
+		return
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "slacker"
+	}
+
+	tracer := telemetry.NewTracer(endpoint, serviceName, parseOTelHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")))
+	tracer.SetTransport(api.Transport())
+	telemetry.SetTracer(tracer)
+}
+
+// serveMetrics exposes the Prometheus metrics endpoint at addr in the background
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", telemetry.Default.Handler())
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			appLogger.Error("metrics server stopped", "addr", addr, "error", err)
+		}
+	}()
+}
+
+// flushTelemetry pushes metrics and exports pending spans. Failures are
+// reported as warnings so they never fail the export itself.
+func flushTelemetry() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if metricsPush != "" {
+		if err := telemetry.Default.Push(ctx, metricsPush, "slacker", api.Transport()); err != nil {
+			appLogger.Warn("failed to push metrics", "error", err)
+		}
+	}
+	if err := telemetry.Flush(ctx); err != nil {
+		appLogger.Warn("failed to export traces", "error", err)
+	}
+}
+
+// parseOTelHeaders parses the key=value,key=value format of OTEL_EXPORTER_OTLP_HEADERS
+func parseOTelHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		name, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	}
+	return headers
+}
+
+// printAPIUsage prints the Slack API calls of a run per method with the
+// busiest minute against the method's rate limit tier, so concurrency and
+// --rate-limit can be tuned before Slack starts rejecting calls
+func printAPIUsage(usage []api.MethodUsage) {
+	if len(usage) == 0 {
+		return
+	}
+
+	calls := 0
+	var retryWait, limiterWait time.Duration
+	for _, method := range usage {
+		calls += method.Calls
+		retryWait += method.RetryWait
+		limiterWait += method.LimiterWait
+	}
+
+	printf("\n📡 Slack API usage: %d calls\n", calls)
+	for _, method := range usage {
+		printf("   %-24s %5d calls, peak %d/min", method.Method, method.Calls, method.PeakPerMinute)
+		if limit := api.TierLimit(method.Tier); limit > 0 {
+			if method.Headroom < 0 {
+				printf(" (tier %d: %d/min, over the limit)", method.Tier, limit)
+			} else {
+				printf(" (tier %d: %d/min, %.0f%% headroom)", method.Tier, limit, method.Headroom*100)
+			}
+		}
+		if method.RateLimited > 0 {
+			printf(", %d rate limited", method.RateLimited)
+		}
+		printf("\n")
+	}
+	if retryWait > 0 || limiterWait > 0 {
+		printf("⏳ Waited %s on Slack rate limits and %s on --rate-limit\n", retryWait.Round(time.Second), limiterWait.Round(time.Second))
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// threadDocCmd represents the thread-to-doc command
+var threadDocCmd = &cobra.Command{
+	Use:   "thread-to-doc",
+	Short: "Turn a single Slack thread into a Markdown or text document",
+	Long: `Fetch the thread a message link points to and convert it into a clean document,
+e.g. to turn a decision thread into a wiki page. The document starts with a title
+taken from the first message and a summary (channel, author, participants, source
+link), followed by the discussion with user mentions resolved to names and a list
+of the attached files. Quoted lines that only repeat an earlier message of the
+thread are left out.
+
+Copy the link with "Copy link" on any message of the thread; links to replies
+fetch the whole thread. Without --output the document is printed to stdout.
+
+Examples:
+  slacker thread-to-doc --permalink https://acme.slack.com/archives/C123/p1705312800123456
+  slacker thread-to-doc --permalink "<url>" --format markdown --output decisions/db-migration.md`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runThreadDoc(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var (
+	threadDocPermalink string
+	threadDocFormat    string
+	threadDocOutput    string
+)
+
+func init() {
+	rootCmd.AddCommand(threadDocCmd)
+
+	threadDocCmd.Flags().StringVar(&threadDocPermalink, "permalink", "", "Link to a message of the thread (required)")
+	threadDocCmd.Flags().StringVarP(&threadDocFormat, "format", "f", usecase.DocFormatMarkdown, "Document format: markdown, text")
+	threadDocCmd.Flags().StringVarP(&threadDocOutput, "output", "o", "", "Write the document to this file instead of stdout (- for stdout)")
+	_ = threadDocCmd.MarkFlagRequired("permalink")
+
+	registerValueCompletion(threadDocCmd, "format", usecase.DocFormatMarkdown, usecase.DocFormatText)
+}
+
+func runThreadDoc() error {
+	if threadDocFormat != usecase.DocFormatMarkdown && threadDocFormat != usecase.DocFormatText {
+		return fmt.Errorf("invalid format '%s'. Valid formats: markdown, text", threadDocFormat)
+	}
+	if _, _, _, err := models.ParsePermalink(threadDocPermalink); err != nil {
+		return err
+	}
+
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+
+	slackClient := newSlackClient(token)
+	slackClient.SetLogger(appLogger)
+	auditLog, err := openAuditLog(cfg, slackClient)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	service := usecase.NewThreadDocService(slackClient)
+	service.SetLogger(appLogger)
+	service.SetLocation(displayLocation)
+	doc, err := service.FetchThread(ctx, threadDocPermalink)
+	entry := audit.Entry{Action: audit.ActionThreadDoc, Output: threadDocOutput}
+	if toStdout(entry.Output) {
+		entry.Output = "stdout"
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		return recordAudit(auditLog, entry, err)
+	}
+	entry.ChannelID, entry.Channel = doc.Channel.ID, doc.Channel.Name
+	entry.Messages, entry.Success = len(doc.Messages), true
+	if err := recordAudit(auditLog, entry, nil); err != nil {
+		return err
+	}
+	data, err := usecase.RenderThreadDoc(doc, threadDocFormat)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if toStdout(threadDocOutput) {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(threadDocOutput, data, 0644); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to write document", err)
+	}
+	printf("📄 Wrote thread from #%s (%d messages) to %s\n", doc.Channel.Name, len(doc.Messages), threadDocOutput)
+	return nil
+}
@@ -1,10 +1,10 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/itcaat/slacker/internal/ui"
+	"github.com/itcaat/slacker/models"
 	"github.com/spf13/cobra"
 )
 
@@ -29,19 +29,24 @@ Keyboard shortcuts:
 - q or Ctrl+C: Quit
 
 Examples:
-  slacker tui                    # Launch the TUI interface`,
+  slacker tui                    # Launch the TUI interface
+  slacker tui --offline          # Browse the local store kept by 'slacker sync'`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := runTUI(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
 		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(tuiCmd)
+
+	tuiCmd.Flags().BoolVar(&tuiOffline, "offline", false, "Browse the local message store (see 'slacker sync') instead of the Slack API")
 }
 
+var tuiOffline bool
+
 func runTUI() error {
-	return ui.RunTUI()
+	return ui.RunTUI(tuiOffline, getVersion(), displayLocation)
 }
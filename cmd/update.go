@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/update"
+	"github.com/itcaat/slacker/models"
+)
+
+// updateCmd represents the update command
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update slacker to the latest release",
+	Long: `Download the latest slacker release from GitHub for this platform and replace
+the running binary with it.
+
+The archive is checked against the SHA-256 checksums published with the release.
+Official builds also verify the Ed25519 signature of the checksums file and
+refuse unsigned releases. Installations managed by a package manager should be
+updated with that package manager instead.
+
+Examples:
+  slacker version --check   # Only check for a newer version
+  slacker update
+  slacker update --force    # Reinstall even when already up to date`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runUpdate(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var updateForce bool
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().BoolVar(&updateForce, "force", false, "Install the latest release even if it is not newer")
+}
+
+func runUpdate() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	client := update.NewClient(update.DefaultRepository)
+	client.SetTransport(api.Transport())
+	if releaseKey != "" {
+		if err := client.SetPublicKey(releaseKey); err != nil {
+			return err
+		}
+	}
+
+	release, err := client.LatestRelease(ctx)
+	if err != nil {
+		return err
+	}
+	if !updateForce && !update.Newer(release.Version(), getVersion()) {
+		printf("✅ slacker %s is up to date\n", getVersion())
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the slacker binary: %w", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("failed to locate the slacker binary: %w", err)
+	}
+
+	if !client.Verifies() {
+		printf("⚠️  This build has no release signing key; only the checksum is verified.\n")
+	}
+	printf("⬇️  Downloading slacker %s for %s/%s...\n", release.Version(), runtime.GOOS, runtime.GOARCH)
+	binary, err := client.Download(ctx, release, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	if err := update.ReplaceExecutable(executable, binary); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", executable, err)
+	}
+
+	printf("✅ Updated slacker from %s to %s (%s)\n", getVersion(), release.Version(), executable)
+	return nil
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:     "verify <manifest|export>",
+	Aliases: []string{"validate"},
+	Short:   "Check an export against its manifest or integrity footer",
+	Long: `Recompute the SHA-256 checksums of the files listed in a manifest written by
+'slacker export --manifest' and report files that are missing or were modified.
+File paths are resolved relative to the manifest. The checksums only detect
+corruption; to detect deliberate changes, export with $SLACKER_MANIFEST_KEY set
+so the manifest is signed with an HMAC, and verify with the same key set.
+
+Given a JSON export (optionally .gz) instead of a *.manifest.json file, check the
+message count and hash in its integrity footer. Truncated files fail to parse
+and are reported as invalid; exports written before the footer existed are
+reported as unsigned.
+
+Examples:
+  slacker verify general.manifest.json
+  slacker verify archive/general.manifest.json --json
+  slacker validate general-export-20240115-103000.json.gz`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runVerify(args[0]); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var verifyJSON bool
+
+// manifestKeyEnv names the environment variable with the key that signs
+// manifests; it is not a flag so the key stays out of shell and job history
+const manifestKeyEnv = "SLACKER_MANIFEST_KEY"
+
+// manifestKey returns the manifest signing key, or nil when none is set
+func manifestKey() []byte {
+	if key := os.Getenv(manifestKeyEnv); key != "" {
+		return []byte(key)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().BoolVar(&verifyJSON, "json", false, "Print the verification results as JSON to stdout")
+}
+
+func runVerify(path string) error {
+	if !strings.HasSuffix(path, ".manifest.json") {
+		return runVerifyExport(path)
+	}
+
+	manifest, checks, err := usecase.VerifyManifest(path, manifestKey())
+	if err != nil {
+		return err
+	}
+	if manifest.HMAC != "" && manifestKey() == nil {
+		eprintf("⚠️  The manifest signature was not checked; set %s to check it\n", manifestKeyEnv)
+	}
+
+	failed := 0
+	for _, check := range checks {
+		if check.Status != usecase.ManifestStatusOK {
+			failed++
+		}
+	}
+
+	if verifyJSON {
+		data, err := json.MarshalIndent(checks, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal verification results: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printf("🔏 #%s exported %s by slacker %s\n\n",
+			manifest.Channel.Name, manifest.CreatedAt.In(displayLocation).Format("2006-01-02 15:04:05"), manifest.SlackerVersion)
+		for _, check := range checks {
+			switch check.Status {
+			case usecase.ManifestStatusOK:
+				printf("✅ %s\n", check.File)
+			case usecase.ManifestStatusMissing:
+				printf("❌ %s: missing\n", check.File)
+			default:
+				printf("❌ %s: checksum mismatch\n", check.File)
+			}
+		}
+		for _, warning := range manifest.Warnings {
+			printf("⚠️  %s\n", warning)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed verification", failed, len(checks))
+	}
+	if !verifyJSON {
+		printf("\n✅ All %d files verified\n", len(checks))
+		if manifest.HMAC != "" && manifestKey() != nil {
+			printf("🔏 Manifest signature verified\n")
+		}
+	}
+	return nil
+}
+
+// runVerifyExport checks an export file against its integrity footer
+func runVerifyExport(path string) error {
+	check, err := usecase.VerifyExport(path)
+	if err != nil {
+		return err
+	}
+
+	if verifyJSON {
+		data, err := json.MarshalIndent(check, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal verification results: %w", err)
+		}
+		fmt.Println(string(data))
+	}
+
+	switch check.Status {
+	case usecase.IntegrityStatusOK:
+		if !verifyJSON {
+			printf("✅ %s: %d messages, sha256 %s\n", check.File, check.Messages, check.Actual)
+		}
+		return nil
+	case usecase.IntegrityStatusUnsigned:
+		return fmt.Errorf("%s has no integrity footer; it was written by an older slacker or another tool", check.File)
+	case usecase.IntegrityStatusInvalid:
+		return fmt.Errorf("%s is not a complete export: %s", check.File, check.Error)
+	}
+	return integrityMismatch(check)
+}
+
+// integrityMismatch describes how an export differs from its integrity footer
+func integrityMismatch(check *usecase.IntegrityCheck) error {
+	if check.Messages != check.ExpectedMessages {
+		return fmt.Errorf("%s has %d messages, expected %d", check.File, check.Messages, check.ExpectedMessages)
+	}
+	return fmt.Errorf("%s messages were modified (sha256 %s, expected %s)", check.File, check.Actual, check.Expected)
+}
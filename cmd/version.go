@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/update"
+	"github.com/itcaat/slacker/models"
+)
+
+// Build information, set at build time with
+//
+//	go build -ldflags "-X github.com/itcaat/slacker/cmd.version=1.2.3 -X github.com/itcaat/slacker/cmd.commit=abc1234 -X github.com/itcaat/slacker/cmd.buildDate=2024-01-15"
+//
+// releaseKey is the base64 Ed25519 public key release checksums are signed
+// with; builds without it verify checksums only.
+var (
+	version    = "dev"
+	commit     = ""
+	buildDate  = ""
+	releaseKey = ""
+)
+
+// versionCmd represents the version command
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the slacker version",
+	Long: `Print the slacker version, commit and build date.
+
+With --check, also look up the latest release on GitHub and tell whether a newer
+version is available ('slacker update' installs it).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runVersion(); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+var versionCheck bool
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.Version = getVersion()
+
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Check GitHub for a newer release")
+}
+
+// getVersion returns the slacker version recorded in export metadata
+func getVersion() string {
+	return version
+}
+
+func runVersion() error {
+	printf("slacker %s", getVersion())
+	if commit != "" {
+		printf(" (commit %s", commit)
+		if buildDate != "" {
+			printf(", built %s", buildDate)
+		}
+		fmt.Print(")")
+	}
+	printf(" %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	if !versionCheck {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client := update.NewClient(update.DefaultRepository)
+	client.SetTransport(api.Transport())
+	release, err := client.LatestRelease(ctx)
+	if err != nil {
+		return err
+	}
+	if update.Newer(release.Version(), getVersion()) {
+		printf("⚠️  A newer version is available: %s (%s)\n", release.Version(), release.HTMLURL)
+		printf("   Run 'slacker update' to install it.\n")
+	} else {
+		printf("✅ slacker is up to date\n")
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/cobra"
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Follow a channel and print new messages as they appear",
+	Long: `Follow a Slack channel and print new messages as they are posted, similar to tail -f.
+
+By default the channel history is polled at a fixed interval. When an app-level token
+is configured (slack.app_token or SLACKER_SLACK_APP_TOKEN), Socket Mode is used instead
+and messages are delivered in real time.
+
+With --format json every message is written to stdout as a single JSON line, which makes
+it easy to feed into notification scripts or other tools. Status output goes to stderr.
+
+Examples:
+  slacker watch --channel general                      # Follow #general
+  slacker watch --channel general --interval 30s       # Poll every 30 seconds
+  slacker watch --channel general --format json | jq .text`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := watchChannel(cmd); err != nil {
+			eprintf("Error: %v\n", err)
+			os.Exit(models.ExitCodeOf(err))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	// Channel selection
+	watchCmd.Flags().StringP("channel", "c", "", "Channel name to watch")
+	watchCmd.Flags().String("channel-id", "", "Channel ID to watch (alternative to --channel)")
+
+	// Watch options
+	watchCmd.Flags().DurationP("interval", "i", 5*time.Second, "Polling interval when Socket Mode is not used")
+	watchCmd.Flags().Bool("no-socket", false, "Always poll, even when an app-level token is configured")
+
+	// Output options
+	watchCmd.Flags().StringP("format", "f", "text", "Output format: text, json")
+	watchCmd.Flags().BoolP("no-format", "n", false, "Disable text formatting and colors")
+
+	registerChannelCompletion(watchCmd, "channel")
+	registerValueCompletion(watchCmd, "format", "text", "json")
+}
+
+func watchChannel(cmd *cobra.Command) error {
+	// Get flags
+	channelName, _ := cmd.Flags().GetString("channel")
+	channelID, _ := cmd.Flags().GetString("channel-id")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	noSocket, _ := cmd.Flags().GetBool("no-socket")
+	format, _ := cmd.Flags().GetString("format")
+	noFormat, _ := cmd.Flags().GetBool("no-format")
+
+	if channelName == "" && channelID == "" {
+		return fmt.Errorf("either --channel or --channel-id must be specified")
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid format '%s'. Valid formats: text, json", format)
+	}
+
+	// Get tokens from config
+	configManager := config.NewManager()
+	token, err := selectToken(configManager, models.TokenTypeBot)
+	if err != nil {
+		return err
+	}
+	appToken := configManager.GetAppToken()
+	cfg, err := configManager.Load()
+	if err != nil {
+		return err
+	}
+
+	// Create Slack client
+	client := newSlackClient(token)
+	auditLog, err := openAuditLog(cfg, client)
+	if err != nil {
+		return err
+	}
+
+	// Stop watching on Ctrl+C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Resolve channel and users
+	setupCtx, cancel := context.WithTimeout(ctx, apiTimeout(60*time.Second))
+	defer cancel()
+
+	if channelID == "" {
+		channel, err := client.GetChannelByName(setupCtx, channelName)
+		if err != nil {
+			return fmt.Errorf("failed to find channel: %w", err)
+		}
+		channelID = channel.ID
+		channelName = channel.Name
+	}
+
+	userMap := make(map[string]models.User)
+	if format == "text" {
+		users, err := client.GetUsers(setupCtx)
+		if err != nil {
+			return fmt.Errorf("failed to get users: %w", err)
+		}
+		for _, user := range users {
+			userMap[user.ID] = user
+		}
+	}
+
+	// Print each new message
+	encoder := json.NewEncoder(os.Stdout)
+	received := 0
+	handler := func(msg models.Message) {
+		received++
+		if format == "json" {
+			if err := encoder.Encode(msg); err != nil {
+				eprintf("Warning: Failed to encode message %s: %v\n", msg.Timestamp, err)
+			}
+			return
+		}
+		displayMessage(msg, userMap, false, noFormat, 0)
+	}
+
+	label := channelName
+	if label == "" {
+		label = channelID
+	}
+
+	if appToken != "" && !noSocket {
+		eprintf("👀 Watching #%s via Socket Mode (Ctrl+C to stop)...\n", label)
+		err = client.StreamMessages(ctx, appToken, channelID, handler)
+	} else {
+		eprintf("👀 Watching #%s, polling every %s (Ctrl+C to stop)...\n", label, interval)
+		watchService := usecase.NewWatchService(client)
+		err = watchService.Watch(ctx, usecase.WatchOptions{
+			ChannelID: channelID,
+			Interval:  interval,
+		}, handler)
+	}
+
+	// The messages printed are recorded once the watch stops
+	entry := audit.Entry{
+		Action:    audit.ActionWatch,
+		ChannelID: channelID,
+		Channel:   channelName,
+		Output:    "stdout",
+		Messages:  received,
+		Success:   err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return recordAudit(auditLog, entry, err)
+}
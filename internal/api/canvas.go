@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+	"github.com/slack-go/slack"
+)
+
+// canvasFileTypes are the files.list types of canvases and legacy posts
+const canvasFileTypes = "canvas,spaces"
+
+// GetChannelCanvases returns the channel canvas and the canvases and posts
+// shared in a channel, with their content converted to Markdown. A canvas
+// that cannot be read is left out with a warning; the error is for a channel
+// whose canvases cannot be listed.
+func (sc *SlackClient) GetChannelCanvases(ctx context.Context, channelID string) ([]models.ExportCanvas, []string, error) {
+	info, err := sc.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{ChannelID: channelID})
+	if err != nil {
+		return nil, nil, wrapError("failed to get channel info", err)
+	}
+
+	var ids []string
+	channelCanvas := ""
+	if info.Properties != nil && info.Properties.Canvas.FileId != "" && !info.Properties.Canvas.IsEmpty {
+		channelCanvas = info.Properties.Canvas.FileId
+		ids = append(ids, channelCanvas)
+	}
+
+	params := slack.ListFilesParameters{Channel: channelID, Types: canvasFileTypes, Limit: sc.pageSize}
+	for {
+		files, next, err := sc.client.ListFilesContext(ctx, params)
+		if err != nil {
+			return nil, nil, wrapError("failed to list canvases", err)
+		}
+		for _, file := range files {
+			if file.ID != channelCanvas {
+				ids = append(ids, file.ID)
+			}
+		}
+		if next == nil || next.Cursor == "" {
+			break
+		}
+		params = *next
+	}
+
+	canvases := make([]models.ExportCanvas, 0, len(ids))
+	var warnings []string
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return canvases, warnings, err
+		}
+		file, _, _, err := sc.client.GetFileInfoContext(ctx, id, 0, 0)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("canvas %s: failed to get info: %v", id, err))
+			continue
+		}
+		content, err := sc.downloadPrivate(ctx, file.URLPrivateDownload)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("canvas %s: failed to download: %v", id, err))
+			continue
+		}
+
+		created := file.Created.Time()
+		canvases = append(canvases, models.ExportCanvas{
+			ID:            file.ID,
+			Title:         file.Title,
+			Filetype:      file.Filetype,
+			User:          file.User,
+			Created:       &created,
+			Permalink:     file.Permalink,
+			ChannelCanvas: file.ID == channelCanvas,
+			Markdown:      htmlToMarkdown(string(content)),
+		})
+	}
+
+	sc.logger.Debug("fetched canvases", "channel_id", channelID, "count", len(canvases), "failed", len(warnings))
+	return canvases, warnings, nil
+}
+
+// downloadPrivate fetches a url_private file with the token
+func (sc *SlackClient) downloadPrivate(ctx context.Context, url string) ([]byte, error) {
+	if url == "" {
+		return nil, fmt.Errorf("no download URL")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+sc.token)
+
+	resp, err := sc.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+var (
+	htmlTag        = regexp.MustCompile(`(?s)<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>|<!--.*?-->`)
+	htmlAttr       = regexp.MustCompile(`([a-zA-Z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	htmlSpace      = regexp.MustCompile(`[ \t\r\n]+`)
+	markdownBlanks = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToMarkdown converts the HTML of a canvas download to Markdown. It
+// handles the elements canvases use: headings, paragraphs, lists and
+// checklists, links, emphasis, code and block quotes.
+func htmlToMarkdown(source string) string {
+	var out strings.Builder
+	var lists []int // item counter per open list; -1 for bullet lists
+	var links []string
+	skip := 0
+	pre := false
+
+	newline := func() {
+		if s := out.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			out.WriteByte('\n')
+		}
+	}
+	block := func() {
+		newline()
+		if s := out.String(); s != "" && !strings.HasSuffix(s, "\n\n") {
+			out.WriteByte('\n')
+		}
+	}
+
+	last := 0
+	for _, m := range htmlTag.FindAllStringSubmatchIndex(source, -1) {
+		if skip == 0 {
+			text := html.UnescapeString(source[last:m[0]])
+			if !pre {
+				text = htmlSpace.ReplaceAllString(text, " ")
+				if strings.HasSuffix(out.String(), "\n") || out.Len() == 0 {
+					text = strings.TrimLeft(text, " ")
+				}
+			}
+			out.WriteString(text)
+		}
+		last = m[1]
+		if m[4] < 0 {
+			continue // comment
+		}
+
+		closing := source[m[2]:m[3]] == "/"
+		tag := strings.ToLower(source[m[4]:m[5]])
+		attrs := source[m[6]:m[7]]
+
+		switch tag {
+		case "head", "style", "script", "title":
+			if closing {
+				skip--
+			} else {
+				skip++
+			}
+		}
+		if skip > 0 {
+			continue
+		}
+
+		switch tag {
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			block()
+			if !closing {
+				out.WriteString(strings.Repeat("#", int(tag[1]-'0')) + " ")
+			}
+		case "p", "div":
+			if closing || len(lists) == 0 {
+				block()
+			}
+		case "br":
+			out.WriteByte('\n')
+		case "ul", "ol":
+			if closing {
+				if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+				}
+				if len(lists) == 0 {
+					block()
+				}
+				continue
+			}
+			newline()
+			if tag == "ol" {
+				lists = append(lists, 0)
+			} else {
+				lists = append(lists, -1)
+			}
+		case "li":
+			if closing {
+				continue
+			}
+			newline()
+			depth := max(len(lists)-1, 0)
+			out.WriteString(strings.Repeat("  ", depth))
+			if len(lists) > 0 && lists[len(lists)-1] >= 0 {
+				lists[len(lists)-1]++
+				fmt.Fprintf(&out, "%d. ", lists[len(lists)-1])
+			} else {
+				out.WriteString("- ")
+			}
+		case "input":
+			if strings.Contains(attrs, "checkbox") {
+				if strings.Contains(attrs, "checked") {
+					out.WriteString("[x] ")
+				} else {
+					out.WriteString("[ ] ")
+				}
+			}
+		case "b", "strong":
+			out.WriteString("**")
+		case "i", "em":
+			out.WriteString("_")
+		case "s", "del", "strike":
+			out.WriteString("~~")
+		case "code":
+			if !pre {
+				out.WriteString("`")
+			}
+		case "pre":
+			if closing {
+				newline()
+				out.WriteString("```")
+				block()
+			} else {
+				block()
+				out.WriteString("```\n")
+			}
+			pre = !closing
+		case "blockquote":
+			block()
+			if !closing {
+				out.WriteString("> ")
+			}
+		case "a":
+			if !closing {
+				links = append(links, htmlAttrValue(attrs, "href"))
+				out.WriteString("[")
+				continue
+			}
+			href := ""
+			if len(links) > 0 {
+				href, links = links[len(links)-1], links[:len(links)-1]
+			}
+			out.WriteString("](" + href + ")")
+		}
+	}
+	if skip == 0 {
+		out.WriteString(html.UnescapeString(htmlSpace.ReplaceAllString(source[last:], " ")))
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(markdownBlanks.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// htmlAttrValue returns the value of an attribute in a tag's attribute text
+func htmlAttrValue(attrs, name string) string {
+	for _, m := range htmlAttr.FindAllStringSubmatch(attrs, -1) {
+		if strings.EqualFold(m[1], name) {
+			return html.UnescapeString(m[2] + m[3])
+		}
+	}
+	return ""
+}
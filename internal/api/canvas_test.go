@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	source := `<html><head><title>Notes</title><style>p{}</style></head><body>
+<h1>Team &amp; notes</h1>
+<p>Read the <a href="https://example.com/runbook">runbook</a> and <b>ask</b> in <i>#help</i>.</p>
+<ul><li>First</li><li>Second<ul><li>Nested</li></ul></li></ul>
+<ol><li>One</li><li>Two</li></ol>
+<ul><li><input type="checkbox" checked>Done</li><li><input type="checkbox">Todo</li></ul>
+<pre><code>make test
+make lint</code></pre>
+<blockquote>Quoted</blockquote>
+</body></html>`
+
+	want := "# Team & notes\n\n" +
+		"Read the [runbook](https://example.com/runbook) and **ask** in _#help_.\n\n" +
+		"- First\n- Second\n  - Nested\n\n" +
+		"1. One\n2. Two\n\n" +
+		"- [x] Done\n- [ ] Todo\n\n" +
+		"```\nmake test\nmake lint\n```\n\n" +
+		"> Quoted"
+
+	if got := htmlToMarkdown(source); got != want {
+		t.Errorf("htmlToMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSlackClient_GetChannelCanvases(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/conversations.info":
+			w.Write([]byte(`{"ok":true,"channel":{"id":"C1","name":"general","properties":{"canvas":{"file_id":"F1","is_empty":false}}}}`))
+		case "/files.list":
+			w.Write([]byte(`{"ok":true,"files":[{"id":"F1"},{"id":"F3"},{"id":"F2"}],"response_metadata":{"next_cursor":""}}`))
+		case "/files.info":
+			id := r.Form.Get("file")
+			if id == "F3" {
+				w.Write([]byte(`{"ok":false,"error":"file_not_found"}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"file":{"id":"` + id + `","title":"Doc ` + id + `","filetype":"quip","created":1704067200,"url_private_download":"` + server.URL + `/download/` + id + `"}}`))
+		case "/download/F1", "/download/F2":
+			if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<h2>Agenda</h2><p>Standup</p>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client:     slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		httpClient: server.Client(),
+		token:      "xoxb-test",
+		logger:     slog.Default(),
+		pageSize:   100,
+	}
+
+	canvases, warnings, err := client.GetChannelCanvases(context.Background(), "C1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(canvases) != 2 {
+		t.Fatalf("Expected the channel canvas and the readable shared canvas, got %+v", canvases)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "F3") {
+		t.Errorf("Expected a warning for the unreadable canvas, got %v", warnings)
+	}
+	if !canvases[0].ChannelCanvas || canvases[0].ID != "F1" || canvases[1].ChannelCanvas {
+		t.Errorf("Expected F1 to be the channel canvas, got %+v", canvases)
+	}
+	if canvases[1].Markdown != "## Agenda\n\nStandup" || canvases[1].Title != "Doc F2" {
+		t.Errorf("Unexpected canvas content %+v", canvases[1])
+	}
+}
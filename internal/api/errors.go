@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/itcaat/slacker/models"
+	"github.com/slack-go/slack"
+)
+
+// authErrorCodes are Slack API error codes caused by an invalid or expired token
+var authErrorCodes = map[string]bool{
+	"not_authed":       true,
+	"invalid_auth":     true,
+	"account_inactive": true,
+	"token_revoked":    true,
+	"token_expired":    true,
+	"no_permission":    true,
+	"missing_scope":    true,
+}
+
+// wrapError categorizes a Slack API error and prefixes it with message
+func wrapError(message string, err error) error {
+	return models.NewExportError(classifyError(err), message, err)
+}
+
+// classifyError maps Slack API and network errors to error categories
+func classifyError(err error) models.ErrorCategory {
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return models.ErrorCategoryRateLimited
+	}
+
+	var slackErr slack.SlackErrorResponse
+	if errors.As(err, &slackErr) {
+		switch {
+		case authErrorCodes[slackErr.Err]:
+			return models.ErrorCategoryAuth
+		case slackErr.Err == "channel_not_found":
+			return models.ErrorCategoryChannelNotFound
+		case slackErr.Err == "ratelimited":
+			return models.ErrorCategoryRateLimited
+		}
+	}
+
+	return models.ErrorCategoryUnknown
+}
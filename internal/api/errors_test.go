@@ -0,0 +1,30 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+	"github.com/slack-go/slack"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected models.ErrorCategory
+	}{
+		{slack.SlackErrorResponse{Err: "invalid_auth"}, models.ErrorCategoryAuth},
+		{slack.SlackErrorResponse{Err: "token_expired"}, models.ErrorCategoryAuth},
+		{slack.SlackErrorResponse{Err: "channel_not_found"}, models.ErrorCategoryChannelNotFound},
+		{&slack.RateLimitedError{}, models.ErrorCategoryRateLimited},
+		{fmt.Errorf("request: %w", slack.SlackErrorResponse{Err: "ratelimited"}), models.ErrorCategoryRateLimited},
+		{errors.New("connection refused"), models.ErrorCategoryUnknown},
+	}
+
+	for _, tt := range tests {
+		if category := classifyError(tt.err); category != tt.expected {
+			t.Errorf("Expected %s for %v, got %s", tt.expected, tt.err, category)
+		}
+	}
+}
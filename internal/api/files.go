@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+	"github.com/slack-go/slack"
+)
+
+// ListFiles returns the files shared in a channel from files.list, newest
+// first. types is a comma-separated list of files.list types such as
+// "images,pdfs" ("" = all); from and to bound the upload time.
+func (sc *SlackClient) ListFiles(ctx context.Context, channelID, types string, from, to *time.Time) ([]models.ExportFile, error) {
+	params := slack.NewGetFilesParameters()
+	params.Channel = channelID
+	params.Count = sc.pageSize
+	if types != "" {
+		params.Types = types
+	}
+	if from != nil {
+		params.TimestampFrom = slack.JSONTime(from.Unix())
+	}
+	if to != nil {
+		params.TimestampTo = slack.JSONTime(to.Unix())
+	}
+
+	var files []models.ExportFile
+	for {
+		page, paging, err := sc.client.GetFilesContext(ctx, params)
+		if err != nil {
+			return nil, wrapError("failed to list files", err)
+		}
+		for i := range page {
+			files = append(files, convertFile(&page[i]))
+		}
+		if paging == nil || paging.Page >= paging.Pages {
+			break
+		}
+		params.Page = paging.Page + 1
+	}
+
+	sc.logger.Debug("listed files", "channel_id", channelID, "types", types, "count", len(files))
+	return files, nil
+}
+
+// OpenDownload starts downloading a url_private file with the token, from
+// byte offset on. It returns the body and the offset it starts at, which is
+// 0 when the server does not support resuming.
+func (sc *SlackClient) OpenDownload(ctx context.Context, url string, offset int64) (io.ReadCloser, int64, error) {
+	if url == "" {
+		return nil, 0, fmt.Errorf("no download URL")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create download request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+sc.token)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := sc.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("download failed: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, 0, nil
+	case http.StatusPartialContent:
+		return resp.Body, offset, nil
+	}
+	resp.Body.Close()
+	return nil, 0, fmt.Errorf("download returned status %d", resp.StatusCode)
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"github.com/itcaat/slacker/models"
+)
+
+// huddleRoom is the room object of a huddle message, which the Slack client
+// library does not decode
+type huddleRoom struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	CreatedBy          string   `json:"created_by"`
+	DateStart          int64    `json:"date_start"`
+	DateEnd            int64    `json:"date_end"`
+	Participants       []string `json:"participants"`
+	ParticipantHistory []string `json:"participant_history"`
+}
+
+// call converts the room to the call details of a message
+func (room *huddleRoom) call() *models.Call {
+	participants := room.ParticipantHistory
+	if len(participants) == 0 {
+		participants = room.Participants
+	}
+	return &models.Call{
+		ID:           room.ID,
+		Name:         room.Name,
+		CreatedBy:    room.CreatedBy,
+		DateStart:    room.DateStart,
+		DateEnd:      room.DateEnd,
+		Participants: participants,
+	}
+}
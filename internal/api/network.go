@@ -0,0 +1,97 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/gorilla/websocket"
+)
+
+// NetworkConfig controls how slacker connects to Slack, e.g. through a
+// corporate proxy that re-signs TLS traffic with its own CA
+type NetworkConfig struct {
+	// ProxyURL is an http://, https:// or socks5:// proxy. Empty uses the
+	// HTTP_PROXY/HTTPS_PROXY environment variables.
+	ProxyURL string
+	// CACertFile is a PEM bundle trusted in addition to the system roots
+	CACertFile string
+	// InsecureSkipVerify disables TLS certificate verification
+	InsecureSkipVerify bool
+}
+
+// baseTransport is the transport used by Slack clients created afterwards
+var baseTransport http.RoundTripper = http.DefaultTransport
+
+// wsDialer is the dialer used for Socket Mode connections
+var wsDialer *websocket.Dialer
+
+// ConfigureNetwork applies proxy and TLS settings to Slack API calls and
+// Socket Mode connections of clients created after the call
+func ConfigureNetwork(cfg NetworkConfig) error {
+	transport, dialer, err := newNetworkTransport(cfg)
+	if err != nil {
+		return err
+	}
+	baseTransport = transport
+	wsDialer = dialer
+	return nil
+}
+
+// Transport returns the transport configured by ConfigureNetwork, for
+// clients of other services (webhooks, uploads, telemetry) that must go
+// through the same proxy and trust the same CAs as Slack calls
+func Transport() http.RoundTripper {
+	return baseTransport
+}
+
+// newNetworkTransport builds an HTTP transport and matching websocket dialer
+// for cfg
+func newNetworkTransport(cfg NetworkConfig) (*http.Transport, *websocket.Dialer, error) {
+	proxy := http.ProxyFromEnvironment
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid proxy URL '%s': %w", cfg.ProxyURL, err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, nil, fmt.Errorf("unsupported proxy scheme '%s'. Supported: http, https, socks5", proxyURL.Scheme)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CACertFile != "" {
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificates found in CA bundle '%s'", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = tlsConfig
+
+	dialer := &websocket.Dialer{
+		Proxy:            proxy,
+		TLSClientConfig:  tlsConfig,
+		HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+	}
+	return transport, dialer, nil
+}
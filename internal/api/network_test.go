@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewNetworkTransport_Proxy(t *testing.T) {
+	transport, dialer, err := newNetworkTransport(NetworkConfig{ProxyURL: "socks5://proxy.internal:1080"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://slack.com/api/auth.test", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.internal:1080" {
+		t.Errorf("Expected requests to use the proxy, got %v (%v)", proxy, err)
+	}
+	if dialer.Proxy == nil {
+		t.Error("Expected socket mode dialer to use the proxy")
+	}
+
+	if _, _, err := newNetworkTransport(NetworkConfig{ProxyURL: "ftp://proxy.internal"}); err == nil {
+		t.Error("Expected error for unsupported proxy scheme")
+	}
+}
+
+func TestNewNetworkTransport_CACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	transport, _, err := newNetworkTransport(NetworkConfig{CACertFile: caFile})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected server signed by the custom CA to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+
+	badFile := filepath.Join(dir, "bad.pem")
+	os.WriteFile(badFile, []byte("not a certificate"), 0600)
+	if _, _, err := newNetworkTransport(NetworkConfig{CACertFile: badFile}); err == nil {
+		t.Error("Expected error for a bundle without certificates")
+	}
+}
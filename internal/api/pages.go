@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// maxPageBytes caps how much of a page is read for its title and description;
+// they are in the head, which comes first
+const maxPageBytes = 1 << 20
+
+// pageTitle matches the title element of a page
+var pageTitle = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// newPageClient returns the client shared pages are fetched with. Anyone in
+// a channel can share a link, so it only connects to public addresses: a
+// link must not reach services on the exporting machine or its network.
+func newPageClient(base http.RoundTripper) *http.Client {
+	if transport, ok := base.(*http.Transport); ok {
+		transport = transport.Clone()
+		// Proxies are configured by the user, so only direct connections
+		// are checked
+		proxyDialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		directDialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: publicDialControl}
+		transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			if ctx.Value(directDialKey{}) == nil {
+				return proxyDialer.DialContext(ctx, network, address)
+			}
+			return directDialer.DialContext(ctx, network, address)
+		}
+		base = transport
+	}
+	return &http.Client{Transport: &publicTransport{base: base, resolver: net.DefaultResolver}}
+}
+
+// directDialKey marks the context of a request that connects to its host
+// directly rather than through a proxy
+type directDialKey struct{}
+
+// publicTransport refuses requests to hosts that resolve to loopback,
+// private, link-local or other non-public addresses. The client sends every
+// redirect through it, so a redirect is checked like the first request.
+// Direct connections are checked again when dialing, so a host cannot
+// resolve to a public address for the check and a private one for the
+// connection.
+type publicTransport struct {
+	base     http.RoundTripper
+	resolver *net.Resolver
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *publicTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkPublicHost(req.Context(), t.resolver, req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	if transport, ok := t.base.(*http.Transport); ok {
+		if !usesProxy(transport, req) {
+			req = req.WithContext(context.WithValue(req.Context(), directDialKey{}, true))
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// usesProxy reports whether transport sends req through a proxy
+func usesProxy(transport *http.Transport, req *http.Request) bool {
+	if transport.Proxy == nil {
+		return false
+	}
+	proxy, err := transport.Proxy(req)
+	return err != nil || proxy != nil
+}
+
+// checkPublicHost resolves host and fails unless all of its addresses are
+// public
+func checkPublicHost(ctx context.Context, resolver *net.Resolver, host string) error {
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("refusing to fetch %s: it resolves to the non-public address %s", host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// publicDialControl refuses connections to non-public addresses
+func publicDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return fmt.Errorf("refusing to connect to the non-public address %s", ip)
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip is a public unicast address
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// FetchLinkSnapshot fetches a shared URL and returns its title, description
+// and site name from the title element and the Open Graph and description
+// meta tags. Pages that are not HTML are returned with only their content
+// type. The token is not sent, and hosts that resolve to non-public
+// addresses are refused.
+func (sc *SlackClient) FetchLinkSnapshot(ctx context.Context, url string) (*models.ExportLinkSnapshot, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create page request: %w", err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.5")
+
+	resp, err := sc.pageClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("page request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("page returned status %d", resp.StatusCode)
+	}
+	snapshot := &models.ExportLinkSnapshot{URL: url, FetchedAt: time.Now().UTC()}
+	if final := resp.Request.URL.String(); final != url {
+		snapshot.FinalURL = final
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	snapshot.ContentType = mediaType
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return snapshot, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page: %w", err)
+	}
+	parsePageMeta(string(data), snapshot)
+	sc.logger.Debug("fetched link snapshot", "url", url, "title", snapshot.Title)
+	return snapshot, nil
+}
+
+// parsePageMeta fills the title, description and site name of snapshot from
+// a page, preferring the Open Graph tags that unfurls use
+func parsePageMeta(page string, snapshot *models.ExportLinkSnapshot) {
+	var title, description string
+	for _, m := range htmlTag.FindAllStringSubmatch(page, -1) {
+		if m[1] != "" || !strings.EqualFold(m[2], "meta") {
+			continue
+		}
+		key := htmlAttrValue(m[3], "property")
+		if key == "" {
+			key = htmlAttrValue(m[3], "name")
+		}
+		content := pageText(htmlAttrValue(m[3], "content"))
+		switch strings.ToLower(key) {
+		case "og:title":
+			snapshot.Title = content
+		case "og:description":
+			snapshot.Description = content
+		case "og:site_name":
+			snapshot.SiteName = content
+		case "twitter:title":
+			title = content
+		case "description", "twitter:description":
+			if description == "" {
+				description = content
+			}
+		}
+	}
+	if snapshot.Title == "" {
+		snapshot.Title = title
+	}
+	if snapshot.Title == "" {
+		if m := pageTitle.FindStringSubmatch(page); m != nil {
+			snapshot.Title = pageText(html.UnescapeString(m[1]))
+		}
+	}
+	if snapshot.Description == "" {
+		snapshot.Description = description
+	}
+}
+
+// pageText collapses the whitespace of a title or description
+func pageText(s string) string {
+	return strings.TrimSpace(htmlSpace.ReplaceAllString(s, " "))
+}
+
+// ArchivePage asks a web archive to snapshot a page by requesting
+// requestURL, such as https://web.archive.org/save/<url>, and returns the
+// URL of the snapshot: the Content-Location the archive reports, or the URL
+// the request was redirected to
+func (sc *SlackClient) ArchivePage(ctx context.Context, requestURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive request: %w", err)
+	}
+
+	resp, err := sc.webClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("archive request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxPageBytes))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("archive returned status %d", resp.StatusCode)
+	}
+	if location := resp.Header.Get("Content-Location"); location != "" {
+		if ref, err := resp.Request.URL.Parse(location); err == nil {
+			return ref.String(), nil
+		}
+	}
+	return resp.Request.URL.String(), nil
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestParsePageMeta(t *testing.T) {
+	page := `<html><head>
+<title>
+  Fallback &amp; title
+</title>
+<meta name="description" content="Plain description">
+<meta property="og:site_name" content="Example">
+</head><body><meta property="og:title" content="Open Graph title"></body></html>`
+
+	var snapshot models.ExportLinkSnapshot
+	parsePageMeta(page, &snapshot)
+	// Tags are read wherever they are; og:title wins over the title element
+	if snapshot.Title != "Open Graph title" || snapshot.Description != "Plain description" || snapshot.SiteName != "Example" {
+		t.Errorf("Unexpected snapshot: %+v", snapshot)
+	}
+
+	snapshot = models.ExportLinkSnapshot{}
+	parsePageMeta(`<title>Fallback &amp; title</title>`, &snapshot)
+	if snapshot.Title != "Fallback & title" {
+		t.Errorf("Expected the title element, got %q", snapshot.Title)
+	}
+}
+
+func TestSlackClient_LinkSnapshots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/post", http.StatusMovedPermanently)
+		case "/post":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<head><meta property="og:title" content="Release notes"><meta property="og:description" content="What changed"></head>`))
+		case "/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4"))
+		case "/save/https://example.com/post":
+			w.Header().Set("Content-Location", "/web/20240101000000/https://example.com/post")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &SlackClient{pageClient: server.Client(), webClient: server.Client(), token: "xoxb-test", logger: slog.Default()}
+	ctx := context.Background()
+
+	snapshot, err := client.FetchLinkSnapshot(ctx, server.URL+"/old")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if snapshot.Title != "Release notes" || snapshot.Description != "What changed" || snapshot.FinalURL != server.URL+"/post" || snapshot.ContentType != "text/html" {
+		t.Errorf("Unexpected snapshot: %+v", snapshot)
+	}
+
+	snapshot, err = client.FetchLinkSnapshot(ctx, server.URL+"/report.pdf")
+	if err != nil || snapshot.ContentType != "application/pdf" || snapshot.Title != "" {
+		t.Errorf("Expected only the content type of a PDF, got %+v, %v", snapshot, err)
+	}
+
+	if _, err := client.FetchLinkSnapshot(ctx, server.URL+"/gone"); err == nil {
+		t.Error("Expected an error for a missing page")
+	}
+
+	archived, err := client.ArchivePage(ctx, server.URL+"/save/https://example.com/post")
+	if err != nil || archived != server.URL+"/web/20240101000000/https://example.com/post" {
+		t.Errorf("Expected the Content-Location of the archive, got %q, %v", archived, err)
+	}
+}
+
+func TestPageClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<title>Internal</title>`))
+	}))
+	defer server.Close()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	client := &SlackClient{pageClient: newPageClient(transport), logger: slog.Default()}
+	if _, err := client.FetchLinkSnapshot(context.Background(), server.URL); err == nil || !strings.Contains(err.Error(), "non-public") {
+		t.Errorf("Expected a loopback page to be refused, got %v", err)
+	}
+
+	for _, address := range []string{"127.0.0.1:80", "10.1.2.3:443", "169.254.169.254:80", "[::1]:80", "[fe80::1]:80", "0.0.0.0:80"} {
+		if err := publicDialControl("tcp", address, nil); err == nil {
+			t.Errorf("Expected a connection to %s to be refused", address)
+		}
+	}
+	if err := publicDialControl("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("Expected a public address to be allowed, got %v", err)
+	}
+}
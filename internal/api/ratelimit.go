@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// tokenBucket limits the request rate shared by all users of a client. Each
+// request takes a token; tokens refill at a fixed rate up to the burst size.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64 // Tokens per second
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+// newTokenBucket allows perMinute requests per minute with bursts of up to burst requests
+func newTokenBucket(perMinute, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:     float64(perMinute) / 60,
+		capacity: float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done. Tokens are reserved
+// in call order, so concurrent callers are spaced out evenly.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// SetRateLimit caps the Slack API calls of this client, across all goroutines
+// that share it, to perMinute requests per minute. Zero removes the limit.
+func (sc *SlackClient) SetRateLimit(perMinute int) {
+	transport, ok := sc.httpClient.Transport.(*instrumentedTransport)
+	if !ok {
+		return
+	}
+	if perMinute <= 0 {
+		transport.limiter = nil
+		return
+	}
+	// Allow a few seconds' worth of requests at once
+	transport.limiter = newTokenBucket(perMinute, perMinute/20)
+}
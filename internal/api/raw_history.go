@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// replyUsersCap is the number of users Slack lists in reply_users; the count
+// of a thread with more repliers is only in reply_users_count
+const replyUsersCap = 5
+
+// rawMessage holds the fields of a conversations.history message that the
+// Slack client library does not decode
+type rawMessage struct {
+	Timestamp       string      `json:"ts"`
+	Room            *huddleRoom `json:"room"`
+	ReplyUsersCount int         `json:"reply_users_count"`
+}
+
+// addRawFields fills in the fields of messages the Slack client library does
+// not decode: the room of huddle messages, and the reply_users_count of
+// thread parents whose reply_users list is full, where it can be larger than
+// the list. Both are read with one conversations.history lookup spanning
+// their timestamps, paged only when the span holds more messages than a
+// page. It returns a warning for each message whose fields could not be
+// read; huddles are then left without call details and threads with the
+// size of reply_users as their count.
+func (sc *SlackClient) addRawFields(ctx context.Context, channelID string, messages []models.Message) []string {
+	wanted := make(map[string]int)
+	var oldest, latest string
+	for i := range messages {
+		if !models.IsHuddle(messages[i].Subtype) && len(messages[i].ReplyUsers) < replyUsersCap {
+			continue
+		}
+		ts := messages[i].Timestamp
+		wanted[ts] = i
+		if oldest == "" || ts < oldest {
+			oldest = ts
+		}
+		if ts > latest {
+			latest = ts
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	found, err := sc.fetchRawMessages(ctx, channelID, oldest, latest, wanted)
+	timestamps := make([]string, 0, len(wanted))
+	for ts := range wanted {
+		timestamps = append(timestamps, ts)
+	}
+	sort.Strings(timestamps)
+
+	var warnings []string
+	for _, ts := range timestamps {
+		msg := &messages[wanted[ts]]
+		raw, ok := found[ts]
+		if ok && raw.ReplyUsersCount > msg.ReplyUsersCount {
+			msg.ReplyUsersCount = raw.ReplyUsersCount
+		}
+		if !models.IsHuddle(msg.Subtype) {
+			if !ok && err != nil {
+				warnings = append(warnings, fmt.Sprintf("thread %s: reply_users_count unavailable: %v", ts, err))
+			}
+			continue
+		}
+		switch {
+		case raw.Room != nil:
+			msg.Call = raw.Room.call()
+		case err != nil:
+			warnings = append(warnings, fmt.Sprintf("huddle %s: details unavailable: %v", ts, err))
+		default:
+			warnings = append(warnings, fmt.Sprintf("huddle %s: details unavailable: Slack returned no room", ts))
+		}
+	}
+	return warnings
+}
+
+// fetchRawMessages reads the messages at the given timestamps between oldest
+// and latest from the raw conversations.history responses. Messages read
+// before an error are returned with it.
+func (sc *SlackClient) fetchRawMessages(ctx context.Context, channelID, oldest, latest string, wanted map[string]int) (map[string]rawMessage, error) {
+	found := make(map[string]rawMessage, len(wanted))
+	cursor := ""
+	for {
+		form := url.Values{
+			"channel":   {channelID},
+			"latest":    {latest},
+			"oldest":    {oldest},
+			"inclusive": {"true"},
+			"limit":     {strconv.Itoa(max(sc.pageSize, len(wanted)))},
+		}
+		if cursor != "" {
+			form.Set("cursor", cursor)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, sc.apiURL+"conversations.history", strings.NewReader(form.Encode()))
+		if err != nil {
+			return found, fmt.Errorf("failed to build conversations.history request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+sc.token)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		resp, err := sc.httpClient.Do(req)
+		if err != nil {
+			return found, wrapError("failed to fetch message details", err)
+		}
+		var response struct {
+			OK               bool         `json:"ok"`
+			Error            string       `json:"error"`
+			HasMore          bool         `json:"has_more"`
+			Messages         []rawMessage `json:"messages"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			return found, fmt.Errorf("failed to parse conversations.history response: %w", err)
+		}
+		if !response.OK {
+			return found, fmt.Errorf("conversations.history failed: %s", response.Error)
+		}
+
+		for _, msg := range response.Messages {
+			if _, ok := wanted[msg.Timestamp]; ok {
+				found[msg.Timestamp] = msg
+			}
+		}
+		cursor = response.ResponseMetadata.NextCursor
+		if len(found) == len(wanted) || !response.HasMore || cursor == "" {
+			return found, nil
+		}
+	}
+}
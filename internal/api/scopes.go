@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// ScopeRequirement describes an OAuth scope and the slacker features that depend on it
+type ScopeRequirement struct {
+	Scope    string
+	Features []string
+	Optional bool
+}
+
+// RequiredScopes lists the bot token scopes slacker uses
+var RequiredScopes = []ScopeRequirement{
+	{Scope: "channels:read", Features: []string{"listing public channels", "resolving channel names", "membership reports (slacker report membership)"}},
+	{Scope: "groups:read", Features: []string{"listing private channels"}},
+	{Scope: "channels:history", Features: []string{"exporting public channels", "viewing public channel messages", "scheduled backups", "inactivity reports (slacker report)"}},
+	{Scope: "groups:history", Features: []string{"exporting private channels", "viewing private channel messages"}},
+	{Scope: "users:read", Features: []string{"user names in exports and the message view"}},
+	{Scope: "files:read", Features: []string{"file details in exports (--include-files)", "canvases in exports (--include-canvas)", "listing and downloading files (slacker files)"}, Optional: true},
+	{Scope: "chat:write", Features: []string{"completion notifications (--notify-channel)"}, Optional: true},
+	{Scope: "usergroups:read", Features: []string{"user group mentions in exports (--include-usergroups)"}, Optional: true},
+	{Scope: "team:read", Features: []string{"workspace domain in export metadata"}, Optional: true},
+	{Scope: "emoji:read", Features: []string{"custom emoji in exports (--include-emoji)"}, Optional: true},
+}
+
+// MissingScopes returns the requirements whose scope is not in granted
+func MissingScopes(granted []string) []ScopeRequirement {
+	have := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		have[scope] = true
+	}
+
+	var missing []ScopeRequirement
+	for _, requirement := range RequiredScopes {
+		if !have[requirement.Scope] {
+			missing = append(missing, requirement)
+		}
+	}
+	return missing
+}
+
+// GetGrantedScopes returns the OAuth scopes granted to the token, as reported
+// by Slack in the X-OAuth-Scopes header of an auth.test response
+func (sc *SlackClient) GetGrantedScopes(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sc.apiURL+"auth.test", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build auth.test request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+sc.token)
+
+	resp, err := sc.httpClient.Do(req)
+	if err != nil {
+		return nil, wrapError("failed to inspect token scopes", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, models.NewExportError(models.ErrorCategoryUnknown, fmt.Sprintf("failed to inspect token scopes: auth.test returned %s", resp.Status), nil)
+	}
+
+	header := resp.Header.Get("X-OAuth-Scopes")
+	if header == "" {
+		return nil, fmt.Errorf("slack did not report the token's scopes")
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+
+	sc.logger.Debug("token scopes", "scopes", scopes)
+	return scopes, nil
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMissingScopes(t *testing.T) {
+	missing := MissingScopes([]string{"channels:read", "channels:history", "users:read", "chat:write"})
+
+	var scopes []string
+	for _, requirement := range missing {
+		scopes = append(scopes, requirement.Scope)
+	}
+
+	expected := []string{"groups:read", "groups:history", "files:read", "usergroups:read", "team:read", "emoji:read"}
+	if len(scopes) != len(expected) {
+		t.Fatalf("Expected missing scopes %v, got %v", expected, scopes)
+	}
+	for i := range expected {
+		if scopes[i] != expected[i] {
+			t.Errorf("Expected missing scope %s at %d, got %s", expected[i], i, scopes[i])
+		}
+	}
+}
+
+func TestSlackClient_GetGrantedScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/auth.test" {
+			t.Errorf("Expected auth.test request, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+			t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("X-OAuth-Scopes", "users:read, channels:history,channels:read")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		httpClient: server.Client(),
+		apiURL:     server.URL + "/api/",
+		token:      "xoxb-test",
+		logger:     slog.Default(),
+	}
+
+	scopes, err := client.GetGrantedScopes(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"channels:history", "channels:read", "users:read"}
+	if len(scopes) != len(expected) {
+		t.Fatalf("Expected scopes %v, got %v", expected, scopes)
+	}
+	for i := range expected {
+		if scopes[i] != expected[i] {
+			t.Errorf("Expected scope %s at %d, got %s", expected[i], i, scopes[i])
+		}
+	}
+}
+
+func TestSlackClient_GetGrantedScopesMissingHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := &SlackClient{httpClient: server.Client(), apiURL: server.URL + "/api/", logger: slog.Default()}
+	if _, err := client.GetGrantedScopes(context.Background()); err == nil {
+		t.Error("Expected error when Slack does not report scopes")
+	}
+}
@@ -1,177 +1,644 @@
 package api
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
-	"log"
-
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itcaat/slacker/internal/cache"
 	"github.com/itcaat/slacker/models"
 	"github.com/slack-go/slack"
 )
 
 // SlackClient wraps the Slack API client with our custom functionality
 type SlackClient struct {
-	client *slack.Client
-	token  string
-	debug  bool
+	client     *slack.Client
+	httpClient *http.Client
+	// webClient fetches URLs outside the Slack API, such as emoji images and
+	// shared pages. It shares the proxy and TLS settings but not the rate
+	// limit, retries and usage records of Slack calls.
+	webClient *http.Client
+	// pageClient fetches shared pages and only connects to public addresses
+	pageClient *http.Client
+	apiURL     string
+	token      string
+	debug      bool
+	logger     *slog.Logger
+	pageSize   int
+
+	// cache holds channel and user lists between runs; nil disables caching
+	cache       *cache.Store
+	channelsTTL time.Duration
+	usersTTL    time.Duration
+
+	// listPageSize is the configured page size of channel and member lists;
+	// zero requests Slack's maximum, since those lists carry no content
+	listPageSize int
+
+	// bots holds the bots looked up so far, loaded from the cache on first use
+	botsMu sync.Mutex
+	bots   map[string]models.Bot
+
+	// identity is the token's auth.test result, which cannot change while the
+	// client is in use, so batch exports look it up once
+	identityMu sync.Mutex
+	identity   *models.ExportIdentity
 }
 
 // NewSlackClient creates a new Slack API client
 func NewSlackClient(token string, debug bool) *SlackClient {
-	var client *slack.Client
+	httpClient := &http.Client{Transport: newInstrumentedTransport(newTraceTransport(baseTransport))}
+	options := []slack.Option{
+		slack.OptionHTTPClient(httpClient),
+	}
 	if debug {
-		client = slack.New(token, slack.OptionDebug(true))
-	} else {
-		client = slack.New(token)
+		options = append(options, slack.OptionDebug(true))
 	}
+	client := slack.New(token, options...)
 
 	return &SlackClient{
-		client: client,
-		token:  token,
-		debug:  debug,
+		client:     client,
+		httpClient: httpClient,
+		webClient:  &http.Client{Transport: baseTransport},
+		pageClient: newPageClient(baseTransport),
+		apiURL:     slack.APIURL,
+		token:      token,
+		debug:      debug,
+		logger:     slog.Default(),
+		pageSize:   200,
+	}
+}
+
+// SetLogger sets the logger used for API diagnostics
+func (sc *SlackClient) SetLogger(logger *slog.Logger) {
+	sc.logger = logger
+}
+
+// SetCache enables the disk cache for channel and user lists with the given
+// time-to-live per resource. A nil store disables caching.
+func (sc *SlackClient) SetCache(store *cache.Store, channelsTTL, usersTTL time.Duration) {
+	sc.cache = store
+	sc.channelsTTL = channelsTTL
+	sc.usersTTL = usersTTL
+}
+
+// InvalidateCache drops the cached channel, user, user group and bot lists
+// so the next calls refetch them
+func (sc *SlackClient) InvalidateCache() {
+	sc.botsMu.Lock()
+	sc.bots = nil
+	sc.botsMu.Unlock()
+	for _, resource := range []string{"channels", "users", "usergroups", "workspace", "bots"} {
+		if err := sc.cache.Delete(sc.cacheKey(resource)); err != nil {
+			sc.logger.Debug("failed to invalidate cache", "resource", resource, "error", err)
+		}
+	}
+}
+
+// cacheKey scopes a cache entry to the token so workspaces never share entries
+func (sc *SlackClient) cacheKey(resource string) string {
+	return cache.Namespace(sc.token) + "-" + resource
+}
+
+// SetPageSize sets how many items paginated list calls request per page. Zero
+// keeps the defaults; other values outside 1-1000 are an error.
+func (sc *SlackClient) SetPageSize(pageSize int) error {
+	if pageSize < 0 || pageSize > maxListPageSize {
+		return fmt.Errorf("page size must be between 1 and 1000, got %d", pageSize)
 	}
+	if pageSize > 0 {
+		sc.pageSize = pageSize
+		sc.listPageSize = pageSize
+	}
+	return nil
+}
+
+// maxListPageSize is the largest page Slack's list methods return
+const maxListPageSize = 1000
+
+// listLimit returns the page size of channel and member lists
+func (sc *SlackClient) listLimit() int {
+	return cmp.Or(sc.listPageSize, maxListPageSize)
 }
 
 // TestAuth tests the authentication with Slack API
 func (sc *SlackClient) TestAuth(ctx context.Context) (*slack.AuthTestResponse, error) {
-	if sc.debug {
-		log.Println("Testing Slack authentication...")
-	}
+	sc.logger.Debug("testing Slack authentication")
 
 	response, err := sc.client.AuthTestContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("authentication failed: %w", err)
+		return nil, wrapError("authentication failed", err)
 	}
 
-	if sc.debug {
-		log.Printf("Authentication successful - User: %s, Team: %s", response.User, response.Team)
-	}
+	sc.logger.Debug("authentication successful", "user", response.User, "team", response.Team)
 
 	return response, nil
 }
 
-// GetChannels retrieves all channels the user is a member of
+// GetChannels retrieves all channels the user is a member of, using the disk
+// cache when it is enabled and fresh
 func (sc *SlackClient) GetChannels(ctx context.Context) ([]models.Channel, error) {
-	if sc.debug {
-		log.Println("Fetching channels...")
+	var cached []models.Channel
+	if sc.cache.Get(sc.cacheKey("channels"), sc.channelsTTL, &cached) {
+		sc.logger.Debug("using cached channels", "count", len(cached))
+		return cached, nil
 	}
 
-	// Get public channels
-	channels, _, err := sc.client.GetConversationsContext(ctx, &slack.GetConversationsParameters{
-		Types: []string{"public_channel", "private_channel"},
-		Limit: 1000,
-	})
+	channels, err := sc.fetchChannels(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get channels: %w", err)
+		return nil, err
 	}
+	if err := sc.cache.Set(sc.cacheKey("channels"), channels); err != nil {
+		sc.logger.Debug("failed to cache channels", "error", err)
+	}
+	return channels, nil
+}
+
+// CachedChannels returns the channel list from the disk cache, however old,
+// without calling Slack. It reports false when nothing is cached.
+func (sc *SlackClient) CachedChannels() ([]models.Channel, bool) {
+	var cached []models.Channel
+	ok := sc.cache.Get(sc.cacheKey("channels"), time.Duration(math.MaxInt64), &cached)
+	return cached, ok
+}
+
+// fetchChannels retrieves the channel list from the Slack API, following the
+// cursor through every page
+func (sc *SlackClient) fetchChannels(ctx context.Context) ([]models.Channel, error) {
+	sc.logger.Debug("fetching channels")
 
+	params := &slack.GetConversationsParameters{
+		Types: []string{"public_channel", "private_channel"},
+		Limit: sc.listLimit(),
+	}
 	var result []models.Channel
-	for _, ch := range channels {
-		// Only include channels the user is a member of
-		if ch.IsMember {
-			channel := models.Channel{
-				ID:         ch.ID,
-				Name:       ch.Name,
-				IsChannel:  ch.IsChannel,
-				IsGroup:    ch.IsGroup,
-				IsIM:       ch.IsIM,
-				IsMember:   ch.IsMember,
-				IsPrivate:  ch.IsPrivate,
-				IsArchived: ch.IsArchived,
-				NumMembers: ch.NumMembers,
-				Created:    int64(ch.Created),
-				Creator:    ch.Creator,
-				Topic: models.Topic{
-					Value:   ch.Topic.Value,
-					Creator: ch.Topic.Creator,
-					LastSet: int64(ch.Topic.LastSet),
-				},
-				Purpose: models.Topic{
-					Value:   ch.Purpose.Value,
-					Creator: ch.Purpose.Creator,
-					LastSet: int64(ch.Purpose.LastSet),
-				},
+	for {
+		channels, cursor, err := sc.client.GetConversationsContext(ctx, params)
+		if err != nil {
+			return nil, wrapError("failed to get channels", err)
+		}
+		for _, ch := range channels {
+			// Only include channels the user is a member of
+			if ch.IsMember {
+				result = append(result, convertChannel(ch))
 			}
-			result = append(result, channel)
 		}
+		if cursor == "" {
+			break
+		}
+		params.Cursor = cursor
 	}
 
-	if sc.debug {
-		log.Printf("Found %d channels", len(result))
-	}
+	sc.logger.Debug("fetched channels", "count", len(result))
 
 	return result, nil
 }
 
-// GetChannelHistory retrieves message history for a specific channel
-func (sc *SlackClient) GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) ([]models.Message, string, error) {
-	if sc.debug {
-		log.Printf("Fetching history for channel %s (limit: %d, cursor: %s)", channelID, limit, cursor)
+// GetChannelInfo describes a single channel using conversations.info. Unlike
+// the channel list it finds archived channels and channels the token is not
+// a member of.
+func (sc *SlackClient) GetChannelInfo(ctx context.Context, channelID string) (*models.Channel, error) {
+	sc.logger.Debug("fetching channel info", "channel_id", channelID)
+
+	ch, err := sc.client.GetConversationInfoContext(ctx, &slack.GetConversationInfoInput{
+		ChannelID:         channelID,
+		IncludeNumMembers: true,
+	})
+	if err != nil {
+		return nil, wrapError(fmt.Sprintf("failed to get channel %s", channelID), err)
+	}
+	channel := convertChannel(*ch)
+	return &channel, nil
+}
+
+// GetChannelMembers returns the IDs of the members of a channel using
+// conversations.members
+func (sc *SlackClient) GetChannelMembers(ctx context.Context, channelID string) ([]string, error) {
+	params := &slack.GetUsersInConversationParameters{ChannelID: channelID, Limit: sc.listLimit()}
+	var members []string
+	for {
+		page, cursor, err := sc.client.GetUsersInConversationContext(ctx, params)
+		if err != nil {
+			return nil, wrapError(fmt.Sprintf("failed to get members of channel %s", channelID), err)
+		}
+		members = append(members, page...)
+		if cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+
+	sc.logger.Debug("fetched channel members", "channel_id", channelID, "count", len(members))
+	return members, nil
+}
+
+// convertChannel converts a Slack conversation to our channel model
+func convertChannel(ch slack.Channel) models.Channel {
+	return models.Channel{
+		ID:         ch.ID,
+		Name:       ch.Name,
+		IsChannel:  ch.IsChannel,
+		IsGroup:    ch.IsGroup,
+		IsIM:       ch.IsIM,
+		IsMember:   ch.IsMember,
+		IsPrivate:  ch.IsPrivate,
+		IsArchived: ch.IsArchived,
+		NumMembers: ch.NumMembers,
+		Created:    int64(ch.Created),
+		Creator:    ch.Creator,
+
+		IsExtShared:      ch.IsExtShared,
+		ConnectedTeamIDs: ch.ConnectedTeamIDs,
+		Topic: models.Topic{
+			Value:   ch.Topic.Value,
+			Creator: ch.Topic.Creator,
+			LastSet: int64(ch.Topic.LastSet),
+		},
+		Purpose: models.Topic{
+			Value:   ch.Purpose.Value,
+			Creator: ch.Purpose.Creator,
+			LastSet: int64(ch.Purpose.LastSet),
+		},
 	}
+}
+
+// GetChannelHistory retrieves a page of message history for a specific channel
+func (sc *SlackClient) GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) (*models.HistoryPage, error) {
+	return sc.GetChannelHistoryRange(ctx, channelID, limit, cursor, "", "")
+}
+
+// GetChannelHistoryRange retrieves the messages of a channel posted between
+// the Slack timestamps oldest and latest, both inclusive. Slack applies the
+// range, so pages only hold matching messages; an empty bound is open.
+func (sc *SlackClient) GetChannelHistoryRange(ctx context.Context, channelID string, limit int, cursor, oldest, latest string) (*models.HistoryPage, error) {
+	sc.logger.Debug("fetching channel history", "channel_id", channelID, "limit", limit, "cursor", cursor, "oldest", oldest, "latest", latest)
 
 	params := &slack.GetConversationHistoryParameters{
 		ChannelID: channelID,
 		Limit:     limit,
 		Cursor:    cursor,
+		Oldest:    oldest,
+		Latest:    latest,
+		Inclusive: oldest != "" || latest != "",
 	}
 
+	start := time.Now()
 	response, err := sc.client.GetConversationHistoryContext(ctx, params)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get channel history: %w", err)
+		return nil, wrapError("failed to get channel history", err)
 	}
+	latency := time.Since(start)
 
 	var messages []models.Message
 	for _, msg := range response.Messages {
 		message := sc.convertSlackMessage(msg)
 		messages = append(messages, message)
 	}
+	warnings := sc.addRawFields(ctx, channelID, messages)
+
+	page := models.NewHistoryPage(messages, response.ResponseMetaData.NextCursor, response.HasMore)
+	page.Latency = latency
+	page.Warnings = warnings
+	sc.logger.Debug("fetched channel history", "channel_id", channelID, "count", len(messages),
+		"has_more", page.HasMore, "oldest", page.Oldest, "latest", page.Latest, "latency", latency)
+
+	return page, nil
+}
+
+// GetMessagesSince retrieves all messages posted to a channel after the given
+// Slack timestamp, ordered oldest first
+func (sc *SlackClient) GetMessagesSince(ctx context.Context, channelID, oldest string) ([]models.Message, error) {
+	sc.logger.Debug("fetching new messages", "channel_id", channelID, "oldest", oldest)
+
+	var messages []models.Message
+	cursor := ""
+	for {
+		params := &slack.GetConversationHistoryParameters{
+			ChannelID: channelID,
+			Oldest:    oldest,
+			Limit:     sc.pageSize,
+			Cursor:    cursor,
+		}
+
+		response, err := sc.client.GetConversationHistoryContext(ctx, params)
+		if err != nil {
+			return nil, wrapError("failed to get channel history", err)
+		}
+
+		for _, msg := range response.Messages {
+			messages = append(messages, sc.convertSlackMessage(msg))
+		}
+
+		cursor = response.ResponseMetaData.NextCursor
+		if cursor == "" || !response.HasMore {
+			break
+		}
+	}
 
-	if sc.debug {
-		log.Printf("Retrieved %d messages", len(messages))
+	for _, warning := range sc.addRawFields(ctx, channelID, messages) {
+		sc.logger.Warn("incomplete message details", "channel_id", channelID, "warning", warning)
 	}
 
-	return messages, response.ResponseMetaData.NextCursor, nil
+	// The history API returns newest messages first
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Timestamp < messages[j].Timestamp
+	})
+
+	sc.logger.Debug("fetched new messages", "channel_id", channelID, "count", len(messages))
+
+	return messages, nil
 }
 
 // GetThreadReplies retrieves replies for a threaded message
 func (sc *SlackClient) GetThreadReplies(ctx context.Context, channelID, threadTS string) ([]models.Message, error) {
-	if sc.debug {
-		log.Printf("Fetching thread replies for %s in channel %s", threadTS, channelID)
+	messages, err := sc.GetThread(ctx, channelID, threadTS)
+	if err != nil || len(messages) == 0 {
+		return nil, err
 	}
+	// Skip the first message as it's the parent message
+	return messages[1:], nil
+}
+
+// GetThread retrieves the parent message of a thread followed by all of its
+// replies
+func (sc *SlackClient) GetThread(ctx context.Context, channelID, threadTS string) ([]models.Message, error) {
+	sc.logger.Debug("fetching thread replies", "channel_id", channelID, "thread_ts", threadTS)
 
+	// Long threads span several pages; without a cursor loop they would be
+	// cut off after the first
 	params := &slack.GetConversationRepliesParameters{
 		ChannelID: channelID,
 		Timestamp: threadTS,
+		Limit:     sc.pageSize,
+	}
+	if params.Limit == 0 {
+		params.Limit = 200
 	}
 
-	messages, _, _, err := sc.client.GetConversationRepliesContext(ctx, params)
+	var thread []models.Message
+	seen := make(map[string]bool)
+	for {
+		messages, hasMore, cursor, err := sc.client.GetConversationRepliesContext(ctx, params)
+		if err != nil {
+			return nil, wrapError("failed to get thread replies", err)
+		}
+		// Every page repeats the parent message
+		for _, msg := range messages {
+			if seen[msg.Timestamp] {
+				continue
+			}
+			seen[msg.Timestamp] = true
+			thread = append(thread, sc.convertSlackMessage(msg))
+		}
+		if !hasMore || cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+
+	sc.logger.Debug("fetched thread replies", "channel_id", channelID, "thread_ts", threadTS, "count", len(thread))
+
+	return thread, nil
+}
+
+// GetUsers retrieves user information for the workspace, using the disk
+// cache when it is enabled and fresh
+func (sc *SlackClient) GetUsers(ctx context.Context) ([]models.User, error) {
+	var cached []models.User
+	if sc.cache.Get(sc.cacheKey("users"), sc.usersTTL, &cached) {
+		sc.logger.Debug("using cached users", "count", len(cached))
+		return cached, nil
+	}
+
+	users, err := sc.fetchUsers(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get thread replies: %w", err)
+		return nil, err
+	}
+	if err := sc.cache.Set(sc.cacheKey("users"), users); err != nil {
+		sc.logger.Debug("failed to cache users", "error", err)
 	}
+	return users, nil
+}
 
-	var replies []models.Message
-	// Skip the first message as it's the parent message
-	for i := 1; i < len(messages); i++ {
-		reply := sc.convertSlackMessage(messages[i])
-		replies = append(replies, reply)
+// GetUserGroups retrieves the user groups of the workspace with their
+// members, including disabled groups so old mentions still resolve. It uses
+// the disk cache with the user list lifetime.
+func (sc *SlackClient) GetUserGroups(ctx context.Context) ([]models.UserGroup, error) {
+	var cached []models.UserGroup
+	if sc.cache.Get(sc.cacheKey("usergroups"), sc.usersTTL, &cached) {
+		sc.logger.Debug("using cached user groups", "count", len(cached))
+		return cached, nil
 	}
 
-	if sc.debug {
-		log.Printf("Retrieved %d thread replies", len(replies))
+	groups, err := sc.client.GetUserGroupsContext(ctx,
+		slack.GetUserGroupsOptionIncludeUsers(true),
+		slack.GetUserGroupsOptionIncludeDisabled(true))
+	if err != nil {
+		return nil, wrapError("failed to get user groups", err)
 	}
 
-	return replies, nil
+	result := make([]models.UserGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, models.UserGroup{
+			ID:          group.ID,
+			Handle:      group.Handle,
+			Name:        group.Name,
+			Description: group.Description,
+			Users:       group.Users,
+			Deleted:     group.DateDelete > 0,
+		})
+	}
+	sc.logger.Debug("fetched user groups", "count", len(result))
+
+	if err := sc.cache.Set(sc.cacheKey("usergroups"), result); err != nil {
+		sc.logger.Debug("failed to cache user groups", "error", err)
+	}
+	return result, nil
 }
 
-// GetUsers retrieves user information for the workspace
-func (sc *SlackClient) GetUsers(ctx context.Context) ([]models.User, error) {
-	if sc.debug {
-		log.Println("Fetching users...")
+// GetBotInfo describes a bot using bots.info; see GetBots
+func (sc *SlackClient) GetBotInfo(ctx context.Context, botID string) (*models.Bot, error) {
+	bots, err := sc.GetBots(ctx, []string{botID})
+	if err != nil {
+		return nil, err
+	}
+	bot := bots[botID]
+	return &bot, nil
+}
+
+// GetBots describes bots using bots.info. Bots are cached on disk with the
+// user list lifetime, so each is looked up once, and the cache is written
+// once per call. Lookups stop at the first failure; the bots found until
+// then are returned with the error. A token without bots:read fails with
+// models.ErrMissingScope.
+func (sc *SlackClient) GetBots(ctx context.Context, botIDs []string) (map[string]models.Bot, error) {
+	bots := make(map[string]models.Bot, len(botIDs))
+	var missing []string
+	sc.botsMu.Lock()
+	sc.loadBotsLocked()
+	for _, id := range botIDs {
+		if bot, ok := sc.bots[id]; ok {
+			bots[id] = bot
+		} else if !slices.Contains(missing, id) {
+			missing = append(missing, id)
+		}
+	}
+	sc.botsMu.Unlock()
+
+	// The lock is not held during requests, so other exports sharing the
+	// client can read the cache meanwhile
+	fetched := make(map[string]models.Bot, len(missing))
+	var err error
+	for _, id := range missing {
+		var bot models.Bot
+		if bot, err = sc.fetchBot(ctx, id); err != nil {
+			break
+		}
+		bots[id] = bot
+		fetched[id] = bot
+	}
+	if len(fetched) == 0 {
+		return bots, err
+	}
+
+	sc.botsMu.Lock()
+	defer sc.botsMu.Unlock()
+	sc.loadBotsLocked()
+	maps.Copy(sc.bots, fetched)
+	if cacheErr := sc.cache.Set(sc.cacheKey("bots"), sc.bots); cacheErr != nil {
+		sc.logger.Debug("failed to cache bots", "error", cacheErr)
+	}
+	return bots, err
+}
+
+// loadBotsLocked reads the bot cache on first use; botsMu must be held
+func (sc *SlackClient) loadBotsLocked() {
+	if sc.bots != nil {
+		return
+	}
+	sc.bots = make(map[string]models.Bot)
+	if !sc.cache.Get(sc.cacheKey("bots"), sc.usersTTL, &sc.bots) {
+		sc.bots = make(map[string]models.Bot)
+	}
+}
+
+// fetchBot looks up one bot with bots.info
+func (sc *SlackClient) fetchBot(ctx context.Context, botID string) (models.Bot, error) {
+	info, err := sc.client.GetBotInfoContext(ctx, slack.GetBotInfoParameters{Bot: botID})
+	if err != nil {
+		var slackErr slack.SlackErrorResponse
+		if errors.As(err, &slackErr) && slackErr.Err == "missing_scope" {
+			return models.Bot{}, models.NewExportError(models.ErrorCategoryAuth, "bots.info requires the bots:read scope", models.ErrMissingScope)
+		}
+		return models.Bot{}, wrapError("failed to get bot info", err)
+	}
+	bot := models.Bot{
+		ID:      info.ID,
+		Name:    info.Name,
+		AppID:   info.AppID,
+		UserID:  info.UserID,
+		Deleted: info.Deleted,
+		Image36: info.Icons.Image36,
+		Image48: info.Icons.Image48,
+		Image72: info.Icons.Image72,
+	}
+	if bot.ID == "" {
+		bot.ID = botID
+	}
+	sc.logger.Debug("fetched bot", "bot_id", botID, "name", bot.Name)
+	return bot, nil
+}
+
+// GetIdentity returns the user or bot the token authenticates as. The first
+// successful lookup is kept for the life of the client.
+func (sc *SlackClient) GetIdentity(ctx context.Context) (*models.ExportIdentity, error) {
+	sc.identityMu.Lock()
+	defer sc.identityMu.Unlock()
+	if sc.identity == nil {
+		auth, err := sc.TestAuth(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sc.identity = &models.ExportIdentity{
+			UserID:       auth.UserID,
+			User:         auth.User,
+			BotID:        auth.BotID,
+			TeamID:       auth.TeamID,
+			Team:         auth.Team,
+			EnterpriseID: auth.EnterpriseID,
+		}
+	}
+	identity := *sc.identity
+	return &identity, nil
+}
+
+// GetWorkspace describes the workspace of the token using team.info. Tokens
+// without the team:read scope fall back to the name and URL from auth.test.
+func (sc *SlackClient) GetWorkspace(ctx context.Context) (*models.ExportWorkspace, error) {
+	var cached models.ExportWorkspace
+	if sc.cache.Get(sc.cacheKey("workspace"), sc.channelsTTL, &cached) {
+		sc.logger.Debug("using cached workspace", "team", cached.ID)
+		return &cached, nil
+	}
+
+	auth, err := sc.TestAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspace := &models.ExportWorkspace{
+		ID:     auth.TeamID,
+		Name:   auth.Team,
+		Domain: workspaceDomain(auth.URL),
+		URL:    auth.URL,
+	}
+
+	team, err := sc.client.GetTeamInfoContext(ctx)
+	var slackErr slack.SlackErrorResponse
+	switch {
+	case err == nil:
+		workspace.ID, workspace.Name, workspace.Domain = team.ID, team.Name, team.Domain
+	case errors.As(err, &slackErr) && slackErr.Err == "missing_scope":
+		sc.logger.Debug("team.info requires team:read, using auth.test", "team", auth.TeamID)
+	default:
+		return nil, wrapError("failed to get workspace info", err)
 	}
 
+	if err := sc.cache.Set(sc.cacheKey("workspace"), workspace); err != nil {
+		sc.logger.Debug("failed to cache workspace", "error", err)
+	}
+	return workspace, nil
+}
+
+// workspaceDomain extracts "acme" from a workspace URL such as
+// https://acme.slack.com/
+func workspaceDomain(url string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	host = strings.TrimSuffix(host, "/")
+	domain, _, found := strings.Cut(host, ".")
+	if !found {
+		return ""
+	}
+	return domain
+}
+
+// fetchUsers retrieves the user list from the Slack API
+func (sc *SlackClient) fetchUsers(ctx context.Context) ([]models.User, error) {
+	sc.logger.Debug("fetching users")
+
 	users, err := sc.client.GetUsersContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get users: %w", err)
+		return nil, wrapError("failed to get users", err)
 	}
 
 	var result []models.User
@@ -182,6 +649,9 @@ func (sc *SlackClient) GetUsers(ctx context.Context) ([]models.User, error) {
 			RealName: user.RealName,
 			IsBot:    user.IsBot,
 			Deleted:  user.Deleted,
+
+			TeamID:     user.TeamID,
+			IsStranger: user.IsStranger,
 			Profile: models.Profile{
 				DisplayName: user.Profile.DisplayName,
 				RealName:    user.Profile.RealName,
@@ -197,13 +667,106 @@ func (sc *SlackClient) GetUsers(ctx context.Context) ([]models.User, error) {
 		result = append(result, u)
 	}
 
-	if sc.debug {
-		log.Printf("Retrieved %d users", len(result))
-	}
+	sc.logger.Debug("fetched users", "count", len(result))
 
 	return result, nil
 }
 
+// GetCustomEmoji returns the workspace's custom emoji as a map of name to
+// image URL. Aliases have values of the form "alias:<name>".
+func (sc *SlackClient) GetCustomEmoji(ctx context.Context) (map[string]string, error) {
+	sc.logger.Debug("fetching custom emoji")
+
+	emoji, err := sc.client.GetEmojiContext(ctx)
+	if err != nil {
+		return nil, wrapError("failed to get custom emoji", err)
+	}
+
+	sc.logger.Debug("fetched custom emoji", "count", len(emoji))
+
+	return emoji, nil
+}
+
+// Download fetches a public URL such as a custom emoji image. The token is
+// not sent.
+func (sc *SlackClient) Download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download request: %w", err)
+	}
+
+	resp, err := sc.webClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download: %w", err)
+	}
+	return data, nil
+}
+
+// GetFileInfo returns the full metadata of a file from files.info. Files that
+// were deleted or are no longer visible to the token are returned with
+// FileStatusDeleted instead of an error.
+func (sc *SlackClient) GetFileInfo(ctx context.Context, fileID string) (*models.ExportFile, error) {
+	file, _, _, err := sc.client.GetFileInfoContext(ctx, fileID, 0, 0)
+	if err != nil {
+		var slackErr slack.SlackErrorResponse
+		if errors.As(err, &slackErr) && (slackErr.Err == "file_not_found" || slackErr.Err == "file_deleted") {
+			return &models.ExportFile{ID: fileID, Status: models.FileStatusDeleted}, nil
+		}
+		return nil, wrapError("failed to get file info", err)
+	}
+
+	converted := convertFile(file)
+	return &converted, nil
+}
+
+// convertFile converts a slack.File to our models.ExportFile
+func convertFile(file *slack.File) models.ExportFile {
+	return models.ExportFile{
+		ID:                 file.ID,
+		Name:               file.Name,
+		Title:              file.Title,
+		Mimetype:           file.Mimetype,
+		Filetype:           file.Filetype,
+		PrettyType:         file.PrettyType,
+		User:               file.User,
+		Mode:               file.Mode,
+		Editable:           file.Editable,
+		IsExternal:         file.IsExternal,
+		ExternalType:       file.ExternalType,
+		Size:               file.Size,
+		URLPrivate:         file.URLPrivate,
+		URLPrivateDownload: file.URLPrivateDownload,
+		Permalink:          file.Permalink,
+		PermalinkPublic:    file.PermalinkPublic,
+		Timestamp:          file.Timestamp.Time(),
+		IsPublic:           file.IsPublic,
+		PublicURLShared:    file.PublicURLShared,
+		Thumb64:            file.Thumb64,
+		Thumb80:            file.Thumb80,
+		Thumb160:           file.Thumb160,
+		Thumb360:           file.Thumb360,
+		Thumb480:           file.Thumb480,
+		Thumb720:           file.Thumb720,
+		Thumb960:           file.Thumb960,
+		Thumb1024:          file.Thumb1024,
+		ImageExifRotation:  file.ImageExifRotation,
+		OriginalW:          file.OriginalW,
+		OriginalH:          file.OriginalH,
+		ThumbW:             file.Thumb360W,
+		ThumbH:             file.Thumb360H,
+		Status:             models.FileStatusOK,
+	}
+}
+
 // convertSlackMessage converts a slack.Message to our models.Message
 func (sc *SlackClient) convertSlackMessage(msg slack.Message) models.Message {
 	message := models.Message{
@@ -216,18 +779,50 @@ func (sc *SlackClient) convertSlackMessage(msg slack.Message) models.Message {
 		BotID:      msg.BotID,
 		Username:   msg.Username,
 		Subtype:    msg.SubType,
+		Team:       msg.Team,
+		Topic:      msg.Topic,
+		Purpose:    msg.Purpose,
+		Name:       msg.Name,
+		OldName:    msg.OldName,
+		Inviter:    msg.Inviter,
+
+		ParentUserID: msg.ParentUserId,
+		ReplyUsers:   msg.ReplyUsers,
+		LatestReply:  msg.LatestReply,
+		// Exact below Slack's cap on reply_users; addRawFields reads the
+		// count of larger threads
+		ReplyUsersCount: len(msg.ReplyUsers),
 	}
 
 	// Convert attachments
 	for _, att := range msg.Attachments {
 		attachment := models.Attachment{
-			ID:       att.ID,
-			Color:    att.Color,
-			Fallback: att.Fallback,
-			Title:    att.Title,
-			Text:     att.Text,
-			ImageURL: att.ImageURL,
-			ThumbURL: att.ThumbURL,
+			ID:          att.ID,
+			Color:       att.Color,
+			Fallback:    att.Fallback,
+			Pretext:     att.Pretext,
+			AuthorName:  att.AuthorName,
+			AuthorLink:  att.AuthorLink,
+			AuthorIcon:  att.AuthorIcon,
+			Title:       att.Title,
+			TitleLink:   att.TitleLink,
+			Text:        att.Text,
+			ImageURL:    att.ImageURL,
+			ThumbURL:    att.ThumbURL,
+			ServiceName: att.ServiceName,
+			ServiceIcon: att.ServiceIcon,
+			FromURL:     att.FromURL,
+			OriginalURL: att.OriginalURL,
+			Footer:      att.Footer,
+			FooterIcon:  att.FooterIcon,
+			Timestamp:   att.Ts.String(),
+		}
+		for _, field := range att.Fields {
+			attachment.Fields = append(attachment.Fields, models.AttachmentField{
+				Title: field.Title,
+				Value: field.Value,
+				Short: field.Short,
+			})
 		}
 		message.Attachments = append(message.Attachments, attachment)
 	}
@@ -264,21 +859,87 @@ func (sc *SlackClient) convertSlackMessage(msg slack.Message) models.Message {
 		}
 	}
 
+	convertStructuredContent(msg, &message)
+
 	return message
 }
 
-// GetChannelByName finds a channel by name
+// convertStructuredContent fills in the call, workflow and canvas details of
+// a message. Huddle rooms are not decoded by the Slack client and are fetched
+// separately by addRawFields. Only bot messages with workflow metadata get
+// workflow details; other bot messages keep their bot ID and username, which
+// falls back to the name of the bot profile.
+func convertStructuredContent(msg slack.Message, message *models.Message) {
+	for _, block := range msg.Blocks.BlockSet {
+		if call, ok := block.(*slack.CallBlock); ok {
+			message.Call = &models.Call{ID: call.CallID}
+			break
+		}
+	}
+
+	isBot := msg.BotID != "" || msg.SubType == slack.MsgSubTypeBotMessage
+	if isBot && message.Username == "" && msg.BotProfile != nil {
+		message.Username = msg.BotProfile.Name
+	}
+	if isBot && msg.Metadata.EventType != "" {
+		workflow := &models.Workflow{
+			Name:      msg.Username,
+			BotID:     msg.BotID,
+			EventType: msg.Metadata.EventType,
+		}
+		if msg.BotProfile != nil {
+			workflow.AppID = msg.BotProfile.AppID
+			if workflow.Name == "" {
+				workflow.Name = msg.BotProfile.Name
+			}
+		}
+		message.Workflow = workflow
+	}
+
+	if msg.SubType == models.SubtypeChannelCanvas {
+		for _, file := range msg.Files {
+			message.Canvas = &models.Canvas{FileID: file.ID, Title: file.Title}
+			if file.Filetype == "canvas" || file.Filetype == "quip" {
+				break
+			}
+		}
+	}
+}
+
+// GetChannelByName finds a channel by name, with or without a leading "#"
+// and ignoring case, using the cached channel list. Unknown names fail with
+// suggestions of similar channels.
 func (sc *SlackClient) GetChannelByName(ctx context.Context, channelName string) (*models.Channel, error) {
 	channels, err := sc.GetChannels(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if channel := models.FindChannel(channels, channelName); channel != nil {
+		return channel, nil
+	}
 
-	for _, channel := range channels {
-		if channel.Name == channelName {
-			return &channel, nil
+	// The channel may have been created or joined since the list was cached
+	if sc.cache != nil {
+		channels, err = sc.fetchChannels(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := sc.cache.Set(sc.cacheKey("channels"), channels); err != nil {
+			sc.logger.Debug("failed to cache channels", "error", err)
+		}
+		if channel := models.FindChannel(channels, channelName); channel != nil {
+			return channel, nil
 		}
 	}
 
-	return nil, fmt.Errorf("channel '%s' not found", channelName)
+	return nil, models.NewChannelNotFoundError(channels, channelName, "")
+}
+
+// PostMessage posts a plain text message to a channel
+func (sc *SlackClient) PostMessage(ctx context.Context, channelID, text string) error {
+	_, _, err := sc.client.PostMessageContext(ctx, channelID, slack.MsgOptionText(text, false))
+	if err != nil {
+		return wrapError("failed to post message", err)
+	}
+	return nil
 }
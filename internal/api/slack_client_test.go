@@ -0,0 +1,602 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/internal/cache"
+	"github.com/itcaat/slacker/models"
+	"github.com/slack-go/slack"
+)
+
+func TestSlackClient_GetUsersCached(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"members":[{"id":"U1","name":"alice"}],"response_metadata":{"next_cursor":""}}`))
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		token:  "xoxb-test",
+		logger: slog.Default(),
+	}
+	client.SetCache(cache.New(t.TempDir()), time.Hour, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		users, err := client.GetUsers(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(users) != 1 || users[0].Name != "alice" {
+			t.Fatalf("Expected cached user alice, got %v", users)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 API call with a warm cache, got %d", calls)
+	}
+
+	client.InvalidateCache()
+	if _, err := client.GetUsers(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected invalidation to force a refetch, got %d calls", calls)
+	}
+}
+
+func TestSlackClient_GetCustomEmojiAndDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/emoji.list":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true,"emoji":{"party-blob":"https://emoji.example/party-blob.gif","blob":"alias:party-blob"}}`))
+		case "/party-blob.gif":
+			if r.Header.Get("Authorization") != "" {
+				t.Error("Expected downloads without the Slack token")
+			}
+			w.Write([]byte("GIF89a"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client:    slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		webClient: server.Client(),
+		token:     "xoxb-test",
+		logger:    slog.Default(),
+	}
+
+	emoji, err := client.GetCustomEmoji(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if emoji["blob"] != "alias:party-blob" || len(emoji) != 2 {
+		t.Errorf("Unexpected emoji %v", emoji)
+	}
+
+	data, err := client.Download(context.Background(), server.URL+"/party-blob.gif")
+	if err != nil || string(data) != "GIF89a" {
+		t.Errorf("Expected image data, got %q, %v", data, err)
+	}
+	if _, err := client.Download(context.Background(), server.URL+"/missing.png"); err == nil {
+		t.Error("Expected error for missing image")
+	}
+}
+
+func TestSlackClient_convertSlackMessageThreadMetadata(t *testing.T) {
+	client := &SlackClient{logger: slog.Default()}
+
+	parent := client.convertSlackMessage(slack.Message{Msg: slack.Msg{
+		Timestamp:       "1704067200.000000",
+		ThreadTimestamp: "1704067200.000000",
+		ReplyCount:      3,
+		ReplyUsers:      []string{"U2", "U3"},
+		LatestReply:     "1704067500.000000",
+	}})
+	if len(parent.ReplyUsers) != 2 || parent.LatestReply != "1704067500.000000" {
+		t.Errorf("Expected reply users and latest reply on the parent, got %+v", parent)
+	}
+
+	reply := client.convertSlackMessage(slack.Message{Msg: slack.Msg{
+		Timestamp:       "1704067300.000000",
+		ThreadTimestamp: "1704067200.000000",
+		ParentUserId:    "U1",
+	}})
+	if reply.ParentUserID != "U1" {
+		t.Errorf("Expected parent user U1 on the reply, got %q", reply.ParentUserID)
+	}
+}
+
+func TestSlackClient_GetChannelHistoryStructuredMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		if r.Form.Get("latest") == "1704067200.000100" {
+			w.Write([]byte(`{"ok":true,"messages":[{"type":"message","subtype":"huddle_thread","ts":"1704067200.000100",
+				"room":{"id":"R1","created_by":"U1","date_start":1704067200,"date_end":1704067950,"participants":[],"participant_history":["U1","U2"]}}]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"has_more":true,"response_metadata":{"next_cursor":"bmV4dA=="},"messages":[
+			{"type":"message","subtype":"huddle_thread","ts":"1704067200.000100","user":"U1"},
+			{"type":"message","subtype":"bot_message","ts":"1704067300.000100","bot_id":"B1","username":"Standup reminder","bot_profile":{"app_id":"A1","name":"Workflow Builder"},
+				"metadata":{"event_type":"standup_posted","event_payload":{}}},
+			{"type":"message","subtype":"bot_message","ts":"1704067350.000100","bot_id":"B2","bot_profile":{"app_id":"A2","name":"deploybot"}},
+			{"type":"message","subtype":"channel_canvas","ts":"1704067400.000100","files":[{"id":"F1","title":"Team notes","filetype":"quip"}]}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client:     slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		httpClient: server.Client(),
+		apiURL:     server.URL + "/",
+		token:      "xoxb-test",
+		logger:     slog.Default(),
+	}
+
+	page, err := client.GetChannelHistory(context.Background(), "C1", 100, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	messages := page.Messages
+	if len(messages) != 4 {
+		t.Fatalf("Expected 4 messages, got %d", len(messages))
+	}
+	if !page.HasMore || page.NextCursor != "bmV4dA==" || page.Last() {
+		t.Errorf("Expected more pages, got has_more=%v cursor=%q", page.HasMore, page.NextCursor)
+	}
+	if page.Oldest != "1704067200.000100" || page.Latest != "1704067400.000100" || page.Latency <= 0 {
+		t.Errorf("Expected page boundaries and latency, got %s-%s in %v", page.Oldest, page.Latest, page.Latency)
+	}
+
+	call := messages[0].Call
+	if call == nil || call.ID != "R1" || call.DateEnd-call.DateStart != 750 || len(call.Participants) != 2 {
+		t.Errorf("Expected huddle room details, got %+v", call)
+	}
+	if workflow := messages[1].Workflow; workflow == nil || workflow.Name != "Standup reminder" || workflow.AppID != "A1" || workflow.EventType != "standup_posted" {
+		t.Errorf("Expected workflow details, got %+v", workflow)
+	}
+	if bot := messages[2]; bot.Workflow != nil || bot.BotID != "B2" || bot.Username != "deploybot" {
+		t.Errorf("Expected a plain bot message without workflow details, got %+v", bot)
+	}
+	if canvas := messages[3].Canvas; canvas == nil || canvas.FileID != "F1" || canvas.Title != "Team notes" {
+		t.Errorf("Expected canvas details, got %+v", canvas)
+	}
+}
+
+func TestSlackClient_HuddleRoomLookup(t *testing.T) {
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		if r.Form.Get("oldest") != "" {
+			lookups++
+			if r.Form.Get("oldest") != "1704067200.000100" || r.Form.Get("latest") != "1704067400.000100" {
+				t.Errorf("Expected one lookup spanning the huddles, got %s-%s", r.Form.Get("oldest"), r.Form.Get("latest"))
+			}
+			w.Write([]byte(`{"ok":true,"messages":[
+				{"type":"message","subtype":"huddle_thread","ts":"1704067400.000100","room":{"id":"R2","participants":["U2"]}},
+				{"type":"message","ts":"1704067300.000100"},
+				{"type":"message","subtype":"huddle_thread","ts":"1704067200.000100"}
+			]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"messages":[
+			{"type":"message","subtype":"huddle_thread","ts":"1704067400.000100","user":"U2"},
+			{"type":"message","ts":"1704067300.000100","user":"U1"},
+			{"type":"message","subtype":"huddle_thread","ts":"1704067200.000100","user":"U1"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client:     slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		httpClient: server.Client(),
+		apiURL:     server.URL + "/",
+		token:      "xoxb-test",
+		logger:     slog.Default(),
+	}
+
+	page, err := client.GetChannelHistory(context.Background(), "C1", 100, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lookups != 1 {
+		t.Errorf("Expected a single room lookup for both huddles, got %d", lookups)
+	}
+	if call := page.Messages[0].Call; call == nil || call.ID != "R2" {
+		t.Errorf("Expected the room of the second huddle, got %+v", call)
+	}
+	if page.Messages[2].Call != nil {
+		t.Errorf("Expected no call details without a room, got %+v", page.Messages[2].Call)
+	}
+	if len(page.Warnings) != 1 || !strings.Contains(page.Warnings[0], "1704067200.000100") {
+		t.Errorf("Expected a warning for the huddle without a room, got %v", page.Warnings)
+	}
+}
+
+func TestSlackClient_ReplyUsersCountLookup(t *testing.T) {
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		if r.Form.Get("oldest") != "" {
+			lookups++
+			if r.Form.Get("oldest") != "1704067200.000100" || r.Form.Get("latest") != "1704067200.000100" {
+				t.Errorf("Expected a lookup of the full thread only, got %s-%s", r.Form.Get("oldest"), r.Form.Get("latest"))
+			}
+			w.Write([]byte(`{"ok":true,"messages":[{"type":"message","ts":"1704067200.000100","reply_users_count":9}]}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true,"messages":[
+			{"type":"message","ts":"1704067300.000100","user":"U1","thread_ts":"1704067300.000100","reply_count":2,"reply_users":["U2","U3"]},
+			{"type":"message","ts":"1704067200.000100","user":"U1","thread_ts":"1704067200.000100","reply_count":20,"reply_users":["U2","U3","U4","U5","U6"]}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client:     slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		httpClient: server.Client(),
+		apiURL:     server.URL + "/",
+		token:      "xoxb-test",
+		logger:     slog.Default(),
+	}
+
+	page, err := client.GetChannelHistory(context.Background(), "C1", 100, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lookups != 1 {
+		t.Errorf("Expected a single lookup, got %d", lookups)
+	}
+	if page.Messages[0].ReplyUsersCount != 2 || page.Messages[1].ReplyUsersCount != 9 {
+		t.Errorf("Expected 2 and 9 repliers, got %d and %d", page.Messages[0].ReplyUsersCount, page.Messages[1].ReplyUsersCount)
+	}
+	if len(page.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", page.Warnings)
+	}
+}
+
+func TestSlackClient_GetFileInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		switch r.Form.Get("file") {
+		case "F1":
+			w.Write([]byte(`{"ok":true,"file":{"id":"F1","name":"chart.png","filetype":"png","original_w":800,"original_h":600,"thumb_360":"https://files.example/F1-360.png","permalink":"https://acme.slack.com/files/U1/F1/chart.png"}}`))
+		case "F2":
+			w.Write([]byte(`{"ok":false,"error":"file_deleted"}`))
+		default:
+			w.Write([]byte(`{"ok":false,"error":"internal_error"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		token:  "xoxb-test",
+		logger: slog.Default(),
+	}
+
+	file, err := client.GetFileInfo(context.Background(), "F1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if file.Status != models.FileStatusOK || file.OriginalW != 800 || file.Permalink == "" {
+		t.Errorf("Unexpected file info %+v", file)
+	}
+
+	deleted, err := client.GetFileInfo(context.Background(), "F2")
+	if err != nil || deleted.Status != models.FileStatusDeleted {
+		t.Errorf("Expected deleted status without error, got %+v, %v", deleted, err)
+	}
+
+	if _, err := client.GetFileInfo(context.Background(), "F3"); err == nil {
+		t.Error("Expected error for a failed lookup")
+	}
+}
+
+func TestSlackClient_GetWorkspaceAndIdentity(t *testing.T) {
+	teamScope := true
+	authTests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth.test":
+			authTests++
+			w.Write([]byte(`{"ok":true,"url":"https://acme-corp.slack.com/","team":"Acme","team_id":"T1","user":"bot","user_id":"U1"}`))
+		case "/team.info":
+			if !teamScope {
+				w.Write([]byte(`{"ok":false,"error":"missing_scope"}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"team":{"id":"T1","name":"Acme Corp","domain":"acme"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		token:  "xoxb-test",
+		logger: slog.Default(),
+	}
+
+	workspace, err := client.GetWorkspace(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := models.ExportWorkspace{ID: "T1", Name: "Acme Corp", Domain: "acme", URL: "https://acme-corp.slack.com/"}
+	if *workspace != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *workspace)
+	}
+
+	identity, err := client.GetIdentity(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if identity.User != "bot" || identity.UserID != "U1" || identity.TeamID != "T1" {
+		t.Errorf("Unexpected identity %+v", identity)
+	}
+	// The identity is looked up once per client
+	before := authTests
+	if again, err := client.GetIdentity(context.Background()); err != nil || *again != *identity {
+		t.Errorf("Expected the same identity, got %+v, %v", again, err)
+	}
+	if authTests != before {
+		t.Errorf("Expected the identity to be cached, got %d more auth.test calls", authTests-before)
+	}
+
+	// Without team:read the auth.test details are used
+	teamScope = false
+	workspace, err = client.GetWorkspace(context.Background())
+	if err != nil {
+		t.Fatalf("Expected fallback without error, got %v", err)
+	}
+	expected = models.ExportWorkspace{ID: "T1", Name: "Acme", Domain: "acme-corp", URL: "https://acme-corp.slack.com/"}
+	if *workspace != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *workspace)
+	}
+}
+
+func TestSlackClient_GetThreadRepliesPaginates(t *testing.T) {
+	const replies = 250
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		requests++
+		limit, _ := strconv.Atoi(r.Form.Get("limit"))
+		start, _ := strconv.Atoi(r.Form.Get("cursor"))
+
+		// Every page starts with the parent, like the Slack API
+		messages := []string{`{"type":"message","ts":"1704067200.000000","thread_ts":"1704067200.000000","reply_count":250}`}
+		end := start + limit - 1
+		if end > replies {
+			end = replies
+		}
+		for i := start; i < end; i++ {
+			messages = append(messages, fmt.Sprintf(`{"type":"message","user":"U1","ts":"1704067201.%06d","thread_ts":"1704067200.000000"}`, i))
+		}
+		hasMore := end < replies
+		cursor := ""
+		if hasMore {
+			cursor = strconv.Itoa(end)
+		}
+		fmt.Fprintf(w, `{"ok":true,"has_more":%t,"response_metadata":{"next_cursor":%q},"messages":[%s]}`,
+			hasMore, cursor, strings.Join(messages, ","))
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client:     slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		httpClient: server.Client(),
+		apiURL:     server.URL + "/",
+		token:      "xoxb-test",
+		logger:     slog.Default(),
+		pageSize:   100,
+	}
+
+	thread, err := client.GetThreadReplies(context.Background(), "C1", "1704067200.000000")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(thread) != replies {
+		t.Fatalf("Expected %d replies, got %d", replies, len(thread))
+	}
+	if requests < 3 {
+		t.Errorf("Expected several pages, got %d requests", requests)
+	}
+	seen := make(map[string]bool)
+	for _, reply := range thread {
+		if reply.Timestamp == "1704067200.000000" || seen[reply.Timestamp] {
+			t.Fatalf("Expected unique replies without the parent, got %s twice", reply.Timestamp)
+		}
+		seen[reply.Timestamp] = true
+	}
+}
+
+func TestSlackClient_GetBotInfoCached(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		requests++
+		w.Write([]byte(`{"ok":true,"bot":{"id":"B1","name":"deploybot","app_id":"A1","user_id":"U9","icons":{"image_48":"https://example.com/48.png"}}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	newClient := func() *SlackClient {
+		client := &SlackClient{
+			client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+			token:  "xoxb-test",
+			logger: slog.Default(),
+		}
+		client.SetCache(cache.New(dir), time.Hour, time.Hour)
+		return client
+	}
+
+	client := newClient()
+	for i := 0; i < 2; i++ {
+		bot, err := client.GetBotInfo(context.Background(), "B1")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if bot.Name != "deploybot" || bot.AppID != "A1" || bot.Image48 == "" {
+			t.Errorf("Unexpected bot %+v", bot)
+		}
+	}
+	// A new client reads the disk cache
+	if _, err := newClient().GetBotInfo(context.Background(), "B1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected one bots.info request, got %d", requests)
+	}
+}
+
+func TestSlackClient_GetBots(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		requests++
+		r.ParseForm()
+		switch bot := r.Form.Get("bot"); bot {
+		case "B3":
+			w.Write([]byte(`{"ok":false,"error":"missing_scope"}`))
+		default:
+			w.Write([]byte(`{"ok":true,"bot":{"id":"` + bot + `","name":"bot-` + bot + `"}}`))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &SlackClient{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		token:  "xoxb-test",
+		logger: slog.Default(),
+	}
+	client.SetCache(cache.New(dir), time.Hour, time.Hour)
+
+	bots, err := client.GetBots(context.Background(), []string{"B1", "B2", "B1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(bots) != 2 || bots["B2"].Name != "bot-B2" || requests != 2 {
+		t.Errorf("Expected each bot looked up once, got %v after %d requests", bots, requests)
+	}
+	var cached map[string]models.Bot
+	if !cache.New(dir).Get(client.cacheKey("bots"), time.Hour, &cached) || len(cached) != 2 {
+		t.Errorf("Expected both bots in the disk cache, got %v", cached)
+	}
+
+	bots, err = client.GetBots(context.Background(), []string{"B1", "B3"})
+	if !errors.Is(err, models.ErrMissingScope) {
+		t.Errorf("Expected a missing scope error, got %v", err)
+	}
+	if len(bots) != 1 || requests != 3 {
+		t.Errorf("Expected the cached bot with the error after one request, got %v after %d requests", bots, requests)
+	}
+}
+
+func TestSlackClient_FetchChannelsPaginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		if r.Form.Get("cursor") == "" {
+			w.Write([]byte(`{"ok":true,"channels":[{"id":"C1","name":"general","is_member":true},{"id":"C2","name":"random"}],
+				"response_metadata":{"next_cursor":"page2"}}`))
+			return
+		}
+		if r.Form.Get("cursor") != "page2" {
+			t.Errorf("Expected the cursor of the first page, got %q", r.Form.Get("cursor"))
+		}
+		w.Write([]byte(`{"ok":true,"channels":[{"id":"C3","name":"ops","is_member":true}]}`))
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		logger: slog.Default(),
+	}
+	channels, err := client.fetchChannels(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(channels) != 2 || channels[0].ID != "C1" || channels[1].ID != "C3" {
+		t.Errorf("Expected the member channels of both pages, got %+v", channels)
+	}
+}
+
+func TestSlackClient_SetPageSize(t *testing.T) {
+	client := &SlackClient{pageSize: 200}
+	if err := client.SetPageSize(0); err != nil || client.pageSize != 200 {
+		t.Errorf("Expected zero to keep the default, got %d, %v", client.pageSize, err)
+	}
+	if err := client.SetPageSize(500); err != nil || client.pageSize != 500 {
+		t.Errorf("Expected page size 500, got %d, %v", client.pageSize, err)
+	}
+	for _, size := range []int{-1, 1001} {
+		if err := client.SetPageSize(size); err == nil || client.pageSize != 500 {
+			t.Errorf("Expected an error for page size %d, got %v", size, err)
+		}
+	}
+}
+
+func TestSlackClient_ListCallsUsePageSize(t *testing.T) {
+	limits := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		r.ParseForm()
+		limits[r.URL.Path] = r.Form.Get("limit")
+		switch r.URL.Path {
+		case "/conversations.list":
+			w.Write([]byte(`{"ok":true,"channels":[]}`))
+		case "/conversations.members":
+			w.Write([]byte(`{"ok":true,"members":["U1"]}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &SlackClient{
+		client: slack.New("xoxb-test", slack.OptionAPIURL(server.URL+"/"), slack.OptionHTTPClient(server.Client())),
+		logger: slog.Default(),
+	}
+	for _, tt := range []struct {
+		pageSize int
+		want     string
+	}{
+		{0, "1000"},
+		{50, "50"},
+	} {
+		if err := client.SetPageSize(tt.pageSize); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := client.fetchChannels(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := client.GetChannelMembers(context.Background(), "C1"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, path := range []string{"/conversations.list", "/conversations.members"} {
+			if limits[path] != tt.want {
+				t.Errorf("Expected %s with limit %s for page size %d, got %q", path, tt.want, tt.pageSize, limits[path])
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+// StreamMessages connects to Slack using Socket Mode and calls handler for every
+// new message posted to the given channel. It blocks until ctx is cancelled or
+// the connection fails.
+func (sc *SlackClient) StreamMessages(ctx context.Context, appToken, channelID string, handler func(models.Message)) error {
+	return sc.runSocketMode(ctx, appToken, func(eventsAPIEvent slackevents.EventsAPIEvent) {
+		ev, ok := eventsAPIEvent.InnerEvent.Data.(*slackevents.MessageEvent)
+		if !ok || ev.Channel != channelID {
+			return
+		}
+
+		// Edits and deletions are delivered as message subtypes; only new messages are streamed
+		if ev.SubType == "message_changed" || ev.SubType == "message_deleted" {
+			return
+		}
+
+		handler(sc.convertMessageEvent(ev))
+	})
+}
+
+// StreamMessageEvents connects to Slack using Socket Mode and calls handler
+// for every new, edited and deleted message and every added or removed
+// reaction in the given channels. It blocks until ctx is cancelled or the
+// connection fails.
+func (sc *SlackClient) StreamMessageEvents(ctx context.Context, appToken string, channelIDs []string, handler func(models.MessageEvent)) error {
+	followed := make(map[string]bool, len(channelIDs))
+	for _, id := range channelIDs {
+		followed[id] = true
+	}
+
+	return sc.runSocketMode(ctx, appToken, func(eventsAPIEvent slackevents.EventsAPIEvent) {
+		var ev *slackevents.MessageEvent
+		switch data := eventsAPIEvent.InnerEvent.Data.(type) {
+		case *slackevents.MessageEvent:
+			ev = data
+		case *slackevents.ReactionAddedEvent:
+			if event, ok := reactionEvent(models.EventReactionAdded, data.User, data.Reaction, data.Item, data.EventTimestamp); ok && followed[event.ChannelID] {
+				handler(event)
+			}
+			return
+		case *slackevents.ReactionRemovedEvent:
+			if event, ok := reactionEvent(models.EventReactionRemoved, data.User, data.Reaction, data.Item, data.EventTimestamp); ok && followed[event.ChannelID] {
+				handler(event)
+			}
+			return
+		}
+		if ev == nil || !followed[ev.Channel] {
+			return
+		}
+
+		event := models.MessageEvent{ChannelID: ev.Channel, At: eventTime(ev.EventTimeStamp)}
+		if ev.PreviousMessage != nil {
+			previous := sc.convertSlackMessage(slack.Message{Msg: *ev.PreviousMessage})
+			event.Previous = &previous
+		}
+		switch ev.SubType {
+		case "message_changed":
+			if ev.Message == nil {
+				return
+			}
+			message := sc.convertSlackMessage(slack.Message{Msg: *ev.Message})
+			event.Kind = models.EventMessageChanged
+			event.Message = &message
+			event.TS, event.ThreadTS = message.Timestamp, message.ThreadTS
+		case "message_deleted":
+			event.Kind = models.EventMessageDeleted
+			event.TS = ev.DeletedTimeStamp
+			if event.Previous != nil {
+				event.ThreadTS = event.Previous.ThreadTS
+			}
+		default:
+			message := sc.convertMessageEvent(ev)
+			event.Kind = models.EventMessagePosted
+			event.Message = &message
+			event.TS, event.ThreadTS = message.Timestamp, message.ThreadTS
+		}
+		handler(event)
+	})
+}
+
+// reactionEvent converts a reaction_added or reaction_removed event; only
+// reactions to messages are reported
+func reactionEvent(kind, user, reaction string, item slackevents.Item, eventTS string) (models.MessageEvent, bool) {
+	if item.Type != "message" {
+		return models.MessageEvent{}, false
+	}
+	return models.MessageEvent{
+		Kind:      kind,
+		ChannelID: item.Channel,
+		At:        eventTime(eventTS),
+		TS:        item.Timestamp,
+		Reaction:  reaction,
+		User:      user,
+	}, true
+}
+
+// runSocketMode connects to Slack using Socket Mode and calls handle for
+// every Events API event until ctx is cancelled or the connection fails
+func (sc *SlackClient) runSocketMode(ctx context.Context, appToken string, handle func(slackevents.EventsAPIEvent)) error {
+	if appToken == "" {
+		return fmt.Errorf("socket mode requires an app-level token")
+	}
+
+	var options []socketmode.Option
+	if wsDialer != nil {
+		options = append(options, socketmode.OptionDialer(wsDialer))
+	}
+	client := socketmode.New(slack.New(sc.token,
+		slack.OptionAppLevelToken(appToken),
+		slack.OptionDebug(sc.debug),
+		slack.OptionHTTPClient(sc.httpClient),
+	), options...)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- client.RunContext(ctx)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-runErr:
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("socket mode connection failed: %w", err)
+		case evt := <-client.Events:
+			switch evt.Type {
+			case socketmode.EventTypeConnected:
+				sc.logger.Debug("connected to Slack with Socket Mode")
+			case socketmode.EventTypeInvalidAuth:
+				return models.NewExportError(models.ErrorCategoryAuth, "socket mode authentication failed: check the app-level token", nil)
+			case socketmode.EventTypeEventsAPI:
+				eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+				if !ok {
+					continue
+				}
+				if evt.Request != nil {
+					client.Ack(*evt.Request)
+				}
+				handle(eventsAPIEvent)
+			}
+		}
+	}
+}
+
+// eventTime parses the event_ts of an event, falling back to now
+func eventTime(ts string) time.Time {
+	seconds, err := strconv.ParseFloat(ts, 64)
+	if err != nil || seconds <= 0 {
+		return time.Now()
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// convertMessageEvent converts an Events API message event to our models.Message
+func (sc *SlackClient) convertMessageEvent(ev *slackevents.MessageEvent) models.Message {
+	if ev.Message != nil {
+		return sc.convertSlackMessage(slack.Message{Msg: *ev.Message})
+	}
+
+	return sc.convertSlackMessage(slack.Message{Msg: slack.Msg{
+		Type:            ev.Type,
+		User:            ev.User,
+		Text:            ev.Text,
+		Timestamp:       ev.TimeStamp,
+		ThreadTimestamp: ev.ThreadTimeStamp,
+		SubType:         ev.SubType,
+		BotID:           ev.BotID,
+		Username:        ev.Username,
+	}})
+}
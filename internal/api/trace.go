@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTraceBody is how much of a request or response body a trace records
+const maxTraceBody = 64 * 1024
+
+var (
+	traceMu     sync.Mutex
+	traceOutput io.Writer
+)
+
+// tokenPattern matches Slack tokens in URLs and bodies
+var tokenPattern = regexp.MustCompile(`(xox[a-z]|xapp)-[A-Za-z0-9-]+`)
+
+// SetTrace writes a transcript of every Slack API request and response made
+// by clients created afterwards to w. Tokens are redacted. A nil w turns
+// tracing off.
+func SetTrace(w io.Writer) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	traceOutput = w
+}
+
+// traceTransport copies requests and responses to the trace output
+type traceTransport struct {
+	base http.RoundTripper
+	w    io.Writer
+}
+
+// newTraceTransport wraps base with tracing when a trace output is set
+func newTraceTransport(base http.RoundTripper) http.RoundTripper {
+	traceMu.Lock()
+	w := traceOutput
+	traceMu.Unlock()
+	if w == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &traceTransport{base: base, w: w}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		requestBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "%s --> %s %s\n", start.UTC().Format(time.RFC3339Nano), req.Method, redactTokens(req.URL.String()))
+	writeTraceBody(&entry, req.Header.Get("Content-Type"), requestBody)
+	if err != nil {
+		fmt.Fprintf(&entry, "<-- error (%s): %v\n\n", elapsed, err)
+		t.write(entry.String())
+		return nil, err
+	}
+
+	fmt.Fprintf(&entry, "<-- %s (%s)\n", resp.Status, elapsed)
+	if isTextContent(resp.Header.Get("Content-Type")) {
+		data, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		if readErr != nil {
+			fmt.Fprintf(&entry, "[failed to read body: %v]\n", readErr)
+		}
+		writeTraceBody(&entry, resp.Header.Get("Content-Type"), data)
+	} else if resp.ContentLength > 0 {
+		fmt.Fprintf(&entry, "[%d bytes of %s]\n", resp.ContentLength, resp.Header.Get("Content-Type"))
+	}
+	entry.WriteString("\n")
+	t.write(entry.String())
+	return resp, nil
+}
+
+// write appends one entry; concurrent requests never interleave
+func (t *traceTransport) write(entry string) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	io.WriteString(t.w, entry)
+}
+
+// writeTraceBody appends a redacted, truncated body to entry
+func writeTraceBody(entry *strings.Builder, contentType string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	if !isTextContent(contentType) {
+		fmt.Fprintf(entry, "[%d bytes of %s]\n", len(body), contentType)
+		return
+	}
+	text := string(body)
+	if len(text) > maxTraceBody {
+		text = text[:maxTraceBody] + fmt.Sprintf("... [%d bytes truncated]", len(body)-maxTraceBody)
+	}
+	entry.WriteString(redactTokens(text))
+	entry.WriteString("\n")
+}
+
+// isTextContent reports whether a body of contentType is worth tracing
+func isTextContent(contentType string) bool {
+	return contentType == "" ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "text/") ||
+		strings.Contains(contentType, "x-www-form-urlencoded")
+}
+
+// redactTokens replaces Slack tokens in s
+func redactTokens(s string) string {
+	return tokenPattern.ReplaceAllString(s, "$1-[REDACTED]")
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/telemetry"
+)
+
+// maxRateLimitRetries is how many times a rate-limited call is retried
+const maxRateLimitRetries = 3
+
+// instrumentedTransport records metrics and spans for Slack Web API calls and
+// waits out HTTP 429 responses using the Retry-After header. With a limiter
+// set, every attempt first waits for its share of the request budget. Calls
+// and waits are also recorded in usage for the end-of-run summary.
+type instrumentedTransport struct {
+	base    http.RoundTripper
+	limiter *tokenBucket
+	usage   *Usage
+}
+
+func newInstrumentedTransport(base http.RoundTripper) *instrumentedTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &instrumentedTransport{base: base, usage: newUsage()}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := apiMethod(req)
+	ctx, span := telemetry.StartSpan(req.Context(), "slack."+method, "slack.method", method)
+	req = req.WithContext(ctx)
+
+	for attempt := 0; ; attempt++ {
+		if t.limiter != nil {
+			waitStart := time.Now()
+			if err := t.limiter.Wait(req.Context()); err != nil {
+				span.End(err)
+				return nil, err
+			}
+			t.usage.recordLimiterWait(method, time.Since(waitStart))
+		}
+
+		start := time.Now()
+		t.usage.recordCall(method, start)
+		resp, err := t.base.RoundTrip(req)
+		telemetry.APIDuration.Observe(time.Since(start).Seconds(), method)
+		if err != nil {
+			telemetry.APICalls.Inc(method, "error")
+			span.End(err)
+			return nil, err
+		}
+		telemetry.APICalls.Inc(method, strconv.Itoa(resp.StatusCode))
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries || !canRetry(req) {
+			span.SetAttribute("http.status_code", strconv.Itoa(resp.StatusCode))
+			span.End(nil)
+			return resp, nil
+		}
+
+		wait := retryAfter(resp)
+		resp.Body.Close()
+
+		t.usage.recordRetry(method, wait)
+		telemetry.RateLimitWaits.Inc(method)
+		telemetry.RateLimitWaitSeconds.Add(wait.Seconds(), method)
+		telemetry.ObserveRateLimit(req.Context(), method, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			span.End(req.Context().Err())
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				span.End(err)
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		telemetry.APIRetries.Inc(method)
+	}
+}
+
+// apiMethod extracts the Web API method name, e.g. conversations.history
+func apiMethod(req *http.Request) string {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// canRetry reports whether the request body can be replayed
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryAfter returns the wait requested by a rate-limited response
+func retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Second
+}
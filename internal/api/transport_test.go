@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/internal/telemetry"
+)
+
+func TestInstrumentedTransport_RetriesRateLimit(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		r.ParseForm()
+		if r.Form.Get("channel") != "C123" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	var observed []string
+	ctx := telemetry.WithRateLimitObserver(context.Background(), func(method string, wait time.Duration) {
+		observed = append(observed, method)
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/api/conversations.history", strings.NewReader("channel=C123"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{Transport: newInstrumentedTransport(nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 after retry, got %d", resp.StatusCode)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+	if len(observed) != 1 || observed[0] != "conversations.history" {
+		t.Errorf("Expected the wait reported for conversations.history, got %v", observed)
+	}
+}
+
+func TestAPIMethod(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://slack.com/api/conversations.replies", nil)
+	if method := apiMethod(req); method != "conversations.replies" {
+		t.Errorf("Expected conversations.replies, got %s", method)
+	}
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	bucket := newTokenBucket(600, 2) // one token every 100ms after a burst of 2
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := bucket.Wait(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected requests beyond the burst to wait, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := bucket.Wait(ctx); err == nil {
+		t.Error("Expected a cancelled context to stop waiting")
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// methodTiers maps the Web API methods slacker calls to their Slack rate
+// limit tier
+var methodTiers = map[string]int{
+	"auth.test":             4,
+	"bots.info":             3,
+	"chat.getPermalink":     4,
+	"conversations.history": 3,
+	"conversations.info":    3,
+	"conversations.list":    2,
+	"conversations.members": 4,
+	"conversations.replies": 3,
+	"emoji.list":            2,
+	"files.info":            4,
+	"search.messages":       2,
+	"team.info":             3,
+	"usergroups.list":       2,
+	"users.info":            4,
+	"users.list":            2,
+}
+
+// tierLimits is the documented minimum number of requests per minute of each
+// rate limit tier
+var tierLimits = map[int]int{1: 1, 2: 20, 3: 50, 4: 100}
+
+// TierLimit returns the documented requests per minute of a rate limit tier,
+// or 0 for an unknown tier
+func TierLimit(tier int) int {
+	return tierLimits[tier]
+}
+
+// Usage records the Slack API calls of one client during a run
+type Usage struct {
+	mu      sync.Mutex
+	methods map[string]*methodUsage
+}
+
+type methodUsage struct {
+	calls       []time.Time
+	rateLimited int
+	retryWait   time.Duration
+	limiterWait time.Duration
+}
+
+// MethodUsage summarizes the calls of one Web API method
+type MethodUsage struct {
+	Method      string `json:"method"`
+	Calls       int    `json:"calls"`
+	RateLimited int    `json:"rate_limited"`
+	// RetryWait is the time spent waiting out HTTP 429 responses
+	RetryWait time.Duration `json:"retry_wait"`
+	// LimiterWait is the time spent waiting for the client's own --rate-limit
+	LimiterWait time.Duration `json:"limiter_wait"`
+	// Tier is the Slack rate limit tier of the method, 0 when unknown
+	Tier int `json:"tier,omitempty"`
+	// PeakPerMinute is the most calls made within any 60 seconds
+	PeakPerMinute int `json:"peak_per_minute"`
+	// Headroom is the share of the tier's per-minute limit left unused in the
+	// busiest minute; negative when the run went over it
+	Headroom float64 `json:"headroom"`
+}
+
+func newUsage() *Usage {
+	return &Usage{methods: make(map[string]*methodUsage)}
+}
+
+// method returns the usage of method; the caller holds u.mu
+func (u *Usage) method(method string) *methodUsage {
+	m, ok := u.methods[method]
+	if !ok {
+		m = &methodUsage{}
+		u.methods[method] = m
+	}
+	return m
+}
+
+// recordCall counts one request attempt
+func (u *Usage) recordCall(method string, at time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	m := u.method(method)
+	m.calls = append(m.calls, at)
+}
+
+// recordRetry counts a rate-limited response and the wait it caused
+func (u *Usage) recordRetry(method string, wait time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	m := u.method(method)
+	m.rateLimited++
+	m.retryWait += wait
+}
+
+// recordLimiterWait adds time spent in the client's own rate limiter
+func (u *Usage) recordLimiterWait(method string, wait time.Duration) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.method(method).limiterWait += wait
+}
+
+// Summary returns the usage per method, busiest first
+func (u *Usage) Summary() []MethodUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	summary := make([]MethodUsage, 0, len(u.methods))
+	for name, m := range u.methods {
+		entry := MethodUsage{
+			Method:        name,
+			Calls:         len(m.calls),
+			RateLimited:   m.rateLimited,
+			RetryWait:     m.retryWait,
+			LimiterWait:   m.limiterWait,
+			Tier:          methodTiers[name],
+			PeakPerMinute: peakPerMinute(m.calls),
+		}
+		if limit := tierLimits[entry.Tier]; limit > 0 {
+			entry.Headroom = 1 - float64(entry.PeakPerMinute)/float64(limit)
+		}
+		summary = append(summary, entry)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		if summary[i].Calls != summary[j].Calls {
+			return summary[i].Calls > summary[j].Calls
+		}
+		return summary[i].Method < summary[j].Method
+	})
+	return summary
+}
+
+// peakPerMinute returns the most calls within any 60 second window. Calls
+// from goroutines sharing a client can be recorded slightly out of order.
+func peakPerMinute(calls []time.Time) int {
+	sorted := append([]time.Time(nil), calls...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	peak, start := 0, 0
+	for end := range sorted {
+		for sorted[end].Sub(sorted[start]) >= time.Minute {
+			start++
+		}
+		if n := end - start + 1; n > peak {
+			peak = n
+		}
+	}
+	return peak
+}
+
+// Usage returns the API calls this client made so far, per method
+func (sc *SlackClient) Usage() []MethodUsage {
+	transport, ok := sc.httpClient.Transport.(*instrumentedTransport)
+	if !ok {
+		return nil
+	}
+	return transport.usage.Summary()
+}
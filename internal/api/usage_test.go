@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPeakPerMinute(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(seconds ...int) []time.Time {
+		var calls []time.Time
+		for _, s := range seconds {
+			calls = append(calls, start.Add(time.Duration(s)*time.Second))
+		}
+		return calls
+	}
+
+	tests := []struct {
+		name  string
+		calls []time.Time
+		want  int
+	}{
+		{"none", nil, 0},
+		{"one minute", at(0, 10, 59), 3},
+		{"window slides", at(0, 50, 55, 70, 100, 115), 4},
+		{"out of order", at(55, 0, 50), 3},
+		{"minute apart", at(0, 60, 120), 1},
+	}
+	for _, tt := range tests {
+		if got := peakPerMinute(tt.calls); got != tt.want {
+			t.Errorf("%s: peakPerMinute = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInstrumentedTransport_RecordsUsage(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.URL.Path == "/api/conversations.history" && attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	transport := newInstrumentedTransport(http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+	for _, method := range []string{"conversations.history", "users.list", "emoji.custom"} {
+		resp, err := client.Get(server.URL + "/api/" + method)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	usage := transport.usage.Summary()
+	if len(usage) != 3 {
+		t.Fatalf("Expected 3 methods, got %+v", usage)
+	}
+	history := usage[0]
+	if history.Method != "conversations.history" || history.Calls != 2 || history.RateLimited != 1 {
+		t.Errorf("Expected 2 calls and 1 rate limit for conversations.history, got %+v", history)
+	}
+	if history.Tier != 3 || history.PeakPerMinute != 2 || history.Headroom != 1-2.0/50 {
+		t.Errorf("Expected tier 3 with 96%% headroom, got %+v", history)
+	}
+	for _, method := range usage[1:] {
+		if method.Method == "emoji.custom" && (method.Tier != 0 || method.Headroom != 0) {
+			t.Errorf("Expected no tier for an unknown method, got %+v", method)
+		}
+	}
+}
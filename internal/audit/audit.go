@@ -0,0 +1,197 @@
+// Package audit keeps an append-only log of slacker's own operations: which
+// Slack data was read, by whom, and where it was written. Organizations using
+// slacker for compliance exports need this provenance of the exports
+// themselves.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// DefaultFile is the audit log below the user's home directory
+const DefaultFile = ".slacker/audit.log"
+
+// Actions recorded in the log
+const (
+	ActionExport    = "export"
+	ActionMessages  = "messages"
+	ActionLinks     = "links"
+	ActionThreadDoc = "thread_doc"
+	ActionSearch    = "search"
+	ActionIndex     = "index"
+	ActionEmoji     = "emoji"
+	ActionFiles     = "files"
+	ActionStats     = "stats"
+	ActionSync      = "sync"
+	ActionWatch     = "watch"
+	ActionDiff      = "diff"
+	ActionConvert   = "convert"
+	ActionInactive  = "report_inactive_channels"
+	ActionMembers   = "report_membership"
+)
+
+// Entry is one line of the audit log. User fields come from auth.test for
+// the token used; LocalUser and Host identify who ran slacker where.
+type Entry struct {
+	Time      time.Time  `json:"time"`
+	Action    string     `json:"action"`
+	User      string     `json:"user,omitempty"`
+	UserID    string     `json:"user_id,omitempty"`
+	Team      string     `json:"team,omitempty"`
+	TeamID    string     `json:"team_id,omitempty"`
+	LocalUser string     `json:"local_user,omitempty"`
+	Host      string     `json:"host,omitempty"`
+	ChannelID string     `json:"channel_id,omitempty"`
+	Channel   string     `json:"channel,omitempty"`
+	From      *time.Time `json:"from,omitempty"`
+	To        *time.Time `json:"to,omitempty"`
+	Query     string     `json:"query,omitempty"`
+	Output    string     `json:"output,omitempty"`
+	Messages  int        `json:"messages,omitempty"`
+	Success   bool       `json:"success"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Log appends entries to a file as JSON lines. A nil Log records nothing.
+// It is safe for concurrent use.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	identity *models.ExportIdentity
+	resolve  func() (*models.ExportIdentity, error)
+	local    string
+	host     string
+}
+
+// New returns a log writing to path
+func New(path string) *Log {
+	log := &Log{path: path}
+	if current, err := user.Current(); err == nil {
+		log.local = current.Username
+	}
+	log.host, _ = os.Hostname()
+	return log
+}
+
+// Open returns a log writing to path, or to DefaultPath when path is empty
+func Open(path string) (*Log, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return New(path), nil
+}
+
+// DefaultPath returns ~/.slacker/audit.log
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, DefaultFile), nil
+}
+
+// Path returns the file the log writes to
+func (l *Log) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// SetIdentity sets the Slack identity recorded with every entry
+func (l *Log) SetIdentity(identity *models.ExportIdentity) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.identity = identity
+}
+
+// SetIdentityFunc looks up the Slack identity when the first entry is
+// recorded, so commands that fail early make no extra API call. Entries are
+// recorded without an identity when the lookup fails.
+func (l *Log) SetIdentityFunc(resolve func() (*models.ExportIdentity, error)) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resolve = resolve
+}
+
+// Record appends entry, filling in the time, the identity and the host
+func (l *Log) Record(entry Entry) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	if l.identity == nil && l.resolve != nil {
+		l.identity, _ = l.resolve()
+		l.resolve = nil
+	}
+	if l.identity != nil && entry.UserID == "" {
+		entry.User, entry.UserID = l.identity.User, l.identity.UserID
+		entry.Team, entry.TeamID = l.identity.Team, l.identity.TeamID
+	}
+	entry.LocalUser, entry.Host = l.local, l.host
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	// O_APPEND keeps earlier entries intact even with several slacker
+	// processes writing at once
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return file.Close()
+}
+
+// ForExport describes an export of a channel with its outcome
+func ForExport(action string, options models.ExportOptions, result *models.ExportResult, err error) Entry {
+	entry := Entry{
+		Action:    action,
+		ChannelID: options.ChannelID,
+		Channel:   options.ChannelName,
+		From:      options.DateFrom,
+		To:        options.DateTo,
+		Output:    options.OutputFile,
+		Success:   err == nil && result != nil && result.Success,
+	}
+	if result != nil {
+		if result.OutputFile != "" {
+			entry.Output = result.OutputFile
+		}
+		entry.Messages = result.Statistics.TotalMessages
+		entry.Error = result.Error
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Invalid audit line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLog_RecordAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.log")
+	log := New(path)
+	lookups := 0
+	log.SetIdentityFunc(func() (*models.ExportIdentity, error) {
+		lookups++
+		return &models.ExportIdentity{UserID: "U1", User: "alice", TeamID: "T1", Team: "Acme"}, nil
+	})
+
+	if err := log.Record(Entry{Action: ActionExport, Channel: "general", Success: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// A second log on the same file must not truncate it
+	if err := New(path).Record(Entry{Action: ActionSearch, Query: "deploy", Success: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := log.Record(Entry{Action: ActionMessages, Error: "boom"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := readEntries(t, path)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].UserID != "U1" || entries[0].Team != "Acme" || entries[0].Time.IsZero() {
+		t.Errorf("Expected identity and time on the first entry, got %+v", entries[0])
+	}
+	if entries[1].Action != ActionSearch || entries[1].UserID != "" {
+		t.Errorf("Expected an anonymous search entry, got %+v", entries[1])
+	}
+	if entries[2].UserID != "U1" || entries[2].Success {
+		t.Errorf("Expected a failed entry with identity, got %+v", entries[2])
+	}
+	if lookups != 1 {
+		t.Errorf("Expected the identity to be looked up once, got %d", lookups)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestLog_Nil(t *testing.T) {
+	var log *Log
+	log.SetIdentity(&models.ExportIdentity{UserID: "U1"})
+	if err := log.Record(Entry{Action: ActionExport}); err != nil {
+		t.Errorf("Expected a nil log to record nothing, got %v", err)
+	}
+}
+
+func TestForExport(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	options := models.ExportOptions{ChannelID: "C1", ChannelName: "general", DateFrom: &from, OutputFile: "general.json"}
+
+	entry := ForExport(ActionExport, options, &models.ExportResult{
+		Success:    true,
+		OutputFile: "general.json.gz",
+		Statistics: models.ExportStatistics{TotalMessages: 42},
+	}, nil)
+	if !entry.Success || entry.Output != "general.json.gz" || entry.Messages != 42 || entry.From != &from {
+		t.Errorf("Unexpected entry for a successful export: %+v", entry)
+	}
+
+	entry = ForExport(ActionExport, options, nil, errors.New("channel_not_found"))
+	if entry.Success || entry.Error != "channel_not_found" || entry.Output != "general.json" {
+		t.Errorf("Unexpected entry for a failed export: %+v", entry)
+	}
+}
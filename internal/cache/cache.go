@@ -0,0 +1,145 @@
+// Package cache stores Slack API responses on disk so that repeated commands
+// do not refetch slowly changing resources such as channel and user lists.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DirName is the cache directory below the user's home directory
+const DirName = ".slacker/cache"
+
+// Store is a directory of JSON cache entries. A nil Store disables caching.
+type Store struct {
+	dir string
+}
+
+// entry is the on-disk format of a cached value
+type entry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// New returns a store rooted at dir
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultDir returns ~/.slacker/cache
+func DefaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, DirName), nil
+}
+
+// Dir returns the directory the store writes to
+func (s *Store) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// Namespace returns a key prefix derived from secret, so that caches of
+// different workspaces or tokens never mix without storing the secret itself
+func Namespace(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Get decodes the entry for key into v. It reports false when the entry is
+// missing, unreadable or older than ttl.
+func (s *Store) Get(key string, ttl time.Duration, v interface{}) bool {
+	if s == nil || ttl <= 0 {
+		return false
+	}
+
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return false
+	}
+	if time.Since(e.StoredAt) > ttl {
+		return false
+	}
+	return json.Unmarshal(e.Data, v) == nil
+}
+
+// Set stores v under key
+func (s *Store) Set(key string, v interface{}) error {
+	if s == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	encoded, err := json.Marshal(entry{StoredAt: time.Now(), Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial entry
+	tmp := s.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, encoded, 0600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, s.path(key)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the entry for key
+func (s *Store) Delete(key string) error {
+	if s == nil {
+		return nil
+	}
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cache entry and returns how many were removed
+func (s *Store) Clear() (int, error) {
+	if s == nil {
+		return 0, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return removed, fmt.Errorf("failed to delete cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// path returns the file for key
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
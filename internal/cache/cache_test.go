@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStore_GetSet(t *testing.T) {
+	store := New(t.TempDir())
+
+	var got []string
+	if store.Get("channels", time.Hour, &got) {
+		t.Fatal("Expected miss for an empty cache")
+	}
+
+	if err := store.Set("channels", []string{"general", "random"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !store.Get("channels", time.Hour, &got) {
+		t.Fatal("Expected hit after Set")
+	}
+	if len(got) != 2 || got[0] != "general" {
+		t.Errorf("Expected cached channels, got %v", got)
+	}
+
+	if store.Get("channels", 0, &got) {
+		t.Error("Expected a zero TTL to disable the cache")
+	}
+}
+
+func TestStore_Expired(t *testing.T) {
+	dir := t.TempDir()
+	store := New(dir)
+	old := `{"stored_at":"2020-01-01T00:00:00Z","data":["general"]}`
+	if err := os.WriteFile(filepath.Join(dir, "channels.json"), []byte(old), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	if store.Get("channels", time.Hour, &got) {
+		t.Error("Expected expired entry to miss")
+	}
+}
+
+func TestStore_Clear(t *testing.T) {
+	store := New(t.TempDir())
+	store.Set("a", 1)
+	store.Set("b", 2)
+
+	removed, err := store.Clear()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected 2 entries removed, got %d", removed)
+	}
+
+	var got int
+	if store.Get("a", time.Hour, &got) {
+		t.Error("Expected cache to be empty after Clear")
+	}
+}
+
+func TestStore_Nil(t *testing.T) {
+	var store *Store
+	var got int
+	if store.Get("a", time.Hour, &got) {
+		t.Error("Expected nil store to miss")
+	}
+	if err := store.Set("a", 1); err != nil {
+		t.Errorf("Expected nil store to ignore Set, got %v", err)
+	}
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/itcaat/slacker/models"
 	"github.com/spf13/viper"
@@ -16,17 +19,134 @@ const (
 
 // Config represents the application configuration
 type Config struct {
-	Slack  models.SlackConfig `mapstructure:"slack"`
-	Debug  bool               `mapstructure:"debug"`
-	Export ExportConfig       `mapstructure:"export"`
+	Slack   models.SlackConfig       `mapstructure:"slack"`
+	Debug   bool                     `mapstructure:"debug"`
+	Export  ExportConfig             `mapstructure:"export"`
+	Daemon  DaemonConfig             `mapstructure:"daemon"`
+	UI      UIConfig                 `mapstructure:"ui"`
+	Network NetworkConfig            `mapstructure:"network"`
+	API     APIConfig                `mapstructure:"api"`
+	Cache   CacheConfig              `mapstructure:"cache"`
+	Backups map[string]BackupProfile `mapstructure:"backups"`
+	Hooks   HooksConfig              `mapstructure:"hooks"`
+	Audit   AuditConfig              `mapstructure:"audit"`
+	History HistoryConfig            `mapstructure:"history"`
+
+	// Timezone for rendered timestamps (IANA name; empty = local)
+	Timezone string `mapstructure:"timezone"`
 }
 
 // ExportConfig represents export-specific configuration
 type ExportConfig struct {
 	DefaultOutputDir string `mapstructure:"default_output_dir"`
 	IncludeThreads   bool   `mapstructure:"include_threads"`
+	IncludeFiles     bool   `mapstructure:"include_files"`
+	IncludeReactions bool   `mapstructure:"include_reactions"`
 	IncludeUsers     bool   `mapstructure:"include_users"`
 	MaxMessages      int    `mapstructure:"max_messages"`
+	DefaultFormat    string `mapstructure:"default_format"`
+	Concurrency      int    `mapstructure:"concurrency"`
+	// SubtypePolicy maps message subtypes to include, exclude or transform
+	SubtypePolicy map[string]string `mapstructure:"subtype_policy"`
+	// SnapshotService is the archive request URL of --snapshot-links, with
+	// {url} standing for the shared URL
+	SnapshotService string `mapstructure:"snapshot_service"`
+	// ArchiveDomains lists the domains whose URLs SnapshotService archives
+	ArchiveDomains []string `mapstructure:"archive_domains"`
+}
+
+// NetworkConfig represents proxy and TLS settings for Slack connections
+type NetworkConfig struct {
+	Proxy              string `mapstructure:"proxy"`
+	CACert             string `mapstructure:"ca_cert"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// APIConfig tunes Slack API timeouts and pagination. Zero values keep the
+// built-in defaults.
+type APIConfig struct {
+	Timeout     time.Duration `mapstructure:"timeout"`
+	PageSize    int           `mapstructure:"page_size"`
+	ThreadDelay time.Duration `mapstructure:"thread_delay"`
+}
+
+// Default cache lifetimes for channel and user lists
+const (
+	DefaultChannelsTTL = 15 * time.Minute
+	DefaultUsersTTL    = time.Hour
+)
+
+// CacheConfig sets how long channel and user lists are cached on disk.
+// A zero TTL disables caching of that resource.
+type CacheConfig struct {
+	ChannelsTTL time.Duration `mapstructure:"channels_ttl"`
+	UsersTTL    time.Duration `mapstructure:"users_ttl"`
+}
+
+// HooksConfig holds shell commands run at the stages of every export (see
+// usecase.ShellHook for their input and output). Empty commands are skipped.
+type HooksConfig struct {
+	OnMessageFetched string        `mapstructure:"on_message_fetched"`
+	OnBeforeWrite    string        `mapstructure:"on_before_write"`
+	OnComplete       string        `mapstructure:"on_complete"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+}
+
+// AuditConfig sets where slacker records its own exports and other reads of
+// Slack data. An empty path means ~/.slacker/audit.log.
+type AuditConfig struct {
+	Path     string `mapstructure:"path"`
+	Disabled bool   `mapstructure:"disabled"`
+}
+
+// DefaultHistoryKeep is how many export jobs the history keeps by default
+const DefaultHistoryKeep = 200
+
+// HistoryConfig sets where slacker records its export runs for 'slacker
+// jobs'. An empty path means ~/.slacker/history.
+type HistoryConfig struct {
+	Path     string `mapstructure:"path"`
+	Disabled bool   `mapstructure:"disabled"`
+	// Keep is how many of the newest jobs are kept (0 = keep all)
+	Keep int `mapstructure:"keep"`
+}
+
+// UIConfig represents TUI appearance settings
+type UIConfig struct {
+	Theme string `mapstructure:"theme"`
+	// Lang is the language of command output (empty = from $LANG)
+	Lang string `mapstructure:"lang"`
+	// Emoji turns the emoji in command output on or off
+	Emoji bool `mapstructure:"emoji"`
+	// ASCII renders command output and the TUI with ASCII symbols only
+	ASCII bool `mapstructure:"ascii"`
+}
+
+// DaemonConfig represents scheduled export configuration
+type DaemonConfig struct {
+	Schedule    string   `mapstructure:"schedule"`
+	Channels    []string `mapstructure:"channels"`
+	OutputDir   string   `mapstructure:"output_dir"`
+	Format      string   `mapstructure:"format"`
+	Compression string   `mapstructure:"compression"`
+	Keep        int      `mapstructure:"keep"`
+	KeepDays    int      `mapstructure:"keep_days"`
+}
+
+// BackupProfile describes a named, repeatable backup of a set of channels
+type BackupProfile struct {
+	Channels       []string `mapstructure:"channels"`
+	Destination    string   `mapstructure:"destination"`
+	Format         string   `mapstructure:"format"`
+	Compression    string   `mapstructure:"compression"`
+	IncludeThreads *bool    `mapstructure:"include_threads"`
+	Incremental    bool     `mapstructure:"incremental"`
+	Keep           int      `mapstructure:"keep"`
+	KeepLast       int      `mapstructure:"keep_last"`
+	KeepDays       int      `mapstructure:"keep_days"`
+	Schedule       string   `mapstructure:"schedule"`
+	OutputTemplate string   `mapstructure:"output_template"`
+	Concurrency    int      `mapstructure:"concurrency"`
 }
 
 // Manager handles configuration loading and saving
@@ -45,8 +165,14 @@ func (m *Manager) Load() (*Config, error) {
 	viper.SetDefault("debug", false)
 	viper.SetDefault("export.default_output_dir", "./exports")
 	viper.SetDefault("export.include_threads", true)
+	viper.SetDefault("export.include_files", true)
+	viper.SetDefault("export.include_reactions", true)
 	viper.SetDefault("export.include_users", true)
 	viper.SetDefault("export.max_messages", 0) // 0 = no limit
+	viper.SetDefault("ui.emoji", true)
+	viper.SetDefault("history.keep", DefaultHistoryKeep)
+	viper.SetDefault("cache.channels_ttl", DefaultChannelsTTL)
+	viper.SetDefault("cache.users_ttl", DefaultUsersTTL)
 
 	// Find home directory
 	home, err := os.UserHomeDir()
@@ -96,10 +222,16 @@ func (m *Manager) Save(config *Config) error {
 	viper.Set("debug", config.Debug)
 	viper.Set("export.default_output_dir", config.Export.DefaultOutputDir)
 	viper.Set("export.include_threads", config.Export.IncludeThreads)
+	viper.Set("export.include_files", config.Export.IncludeFiles)
+	viper.Set("export.include_reactions", config.Export.IncludeReactions)
 	viper.Set("export.include_users", config.Export.IncludeUsers)
 	viper.Set("export.max_messages", config.Export.MaxMessages)
 
-	// Write config file
+	return m.writeConfig()
+}
+
+// writeConfig writes the current viper settings to the configuration file
+func (m *Manager) writeConfig() error {
 	if err := viper.WriteConfig(); err != nil {
 		// If config file doesn't exist, create it
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
@@ -111,36 +243,160 @@ func (m *Manager) Save(config *Config) error {
 	return nil
 }
 
-// SetToken sets the Slack token and saves the configuration
+// SetToken stores the Slack token in the OS keyring and removes any
+// plaintext copy from the configuration file. Bot (xoxb-) and user (xoxp-)
+// tokens are stored separately so both can be registered at once.
 func (m *Manager) SetToken(token string) error {
+	slot := slotForToken(token)
+	if err := writeKeyring(slot.keyringKey, token); err != nil {
+		return err
+	}
+
 	config, err := m.Load()
 	if err != nil {
 		// If config doesn't exist, create a new one
 		config = &Config{}
 	}
 
-	config.Slack.Token = token
+	if *slot.field(&config.Slack) == "" {
+		return nil
+	}
+	*slot.field(&config.Slack) = ""
 	return m.Save(config)
 }
 
-// GetToken retrieves the Slack token from configuration or environment
-func (m *Manager) GetToken() (string, error) {
-	// First check environment variable
-	if token := os.Getenv("SLACKER_SLACK_TOKEN"); token != "" {
-		return token, nil
+// SetPlaintextToken stores the Slack token in cleartext in the configuration
+// file. Only used when the OS keyring is explicitly bypassed.
+func (m *Manager) SetPlaintextToken(token string) error {
+	config, err := m.Load()
+	if err != nil {
+		// If config doesn't exist, create a new one
+		config = &Config{}
+	}
+
+	slot := slotForToken(token)
+	*slot.field(&config.Slack) = token
+	if err := m.Save(config); err != nil {
+		return err
+	}
+
+	// Drop any keyring copy so it does not shadow the new token. The keyring
+	// may be unavailable here, which is usually why this path is taken.
+	if err := deleteKeyring(slot.keyringKey); err != nil {
+		slog.Debug("could not clear keyring token", "error", err)
+	}
+	return nil
+}
+
+// MigrateTokens moves plaintext tokens from the configuration file into the
+// OS keyring. It returns the names of the migrated tokens.
+func (m *Manager) MigrateTokens() ([]string, error) {
+	config, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []string
+	for _, slot := range tokenSlots {
+		field := slot.field(&config.Slack)
+		if *field == "" {
+			continue
+		}
+		if err := writeKeyring(slot.keyringKey, *field); err != nil {
+			return nil, err
+		}
+		*field = ""
+		migrated = append(migrated, slot.keyringKey)
+	}
+
+	if len(migrated) == 0 {
+		return nil, nil
 	}
+	return migrated, m.Save(config)
+}
 
-	// Then check config file
+// HasPlaintextTokens reports whether the configuration file still holds tokens in cleartext
+func (m *Manager) HasPlaintextTokens() bool {
 	config, err := m.Load()
 	if err != nil {
-		return "", err
+		return false
+	}
+	for _, slot := range tokenSlots {
+		if *slot.field(&config.Slack) != "" {
+			return true
+		}
 	}
+	return false
+}
+
+// GetToken retrieves the default Slack token, preferring a bot token
+func (m *Manager) GetToken() (string, error) {
+	token, _, err := m.SelectToken(models.TokenTypeAuto, models.TokenTypeBot)
+	return token, err
+}
 
-	if config.Slack.Token == "" {
-		return "", fmt.Errorf("no Slack token found. Set SLACKER_SLACK_TOKEN environment variable or run 'slacker auth'")
+// SelectToken picks the token for an operation. An explicit requested type
+// must be configured; with TokenTypeAuto the preferred type is used when
+// available, falling back to any other configured token.
+func (m *Manager) SelectToken(requested, preferred models.TokenType) (string, models.TokenType, error) {
+	if requested != models.TokenTypeAuto {
+		if token := m.lookupToken(requested); token != "" {
+			return token, requested, nil
+		}
+		return "", "", models.NewExportError(models.ErrorCategoryAuth, fmt.Sprintf("no %s token found. Set SLACKER_SLACK_%s_TOKEN or run 'slacker auth' with a %s token", requested, strings.ToUpper(string(requested)), requested), nil)
 	}
 
-	return config.Slack.Token, nil
+	for _, tokenType := range []models.TokenType{preferred, models.TokenTypeBot, models.TokenTypeUser} {
+		if token := m.lookupToken(tokenType); token != "" {
+			return token, tokenType, nil
+		}
+	}
+
+	return "", "", models.NewExportError(models.ErrorCategoryAuth, "no Slack token found. Set SLACKER_SLACK_TOKEN environment variable or run 'slacker auth'", nil)
+}
+
+// lookupToken returns the token of the given type from its dedicated slot, or
+// from the generic token slot when that token's prefix matches the type
+func (m *Manager) lookupToken(tokenType models.TokenType) string {
+	if tokenType == models.TokenTypeBot || tokenType == models.TokenTypeUser {
+		if token := m.readSlot(tokenSlotFor(tokenType)); token != "" {
+			return token
+		}
+	}
+
+	token := m.readSlot(tokenSlots[0])
+	if token == "" {
+		return ""
+	}
+	// Tokens with an unrecognized prefix are treated as bot tokens
+	detected := models.DetectTokenType(token)
+	if detected == tokenType || (detected == "" && tokenType == models.TokenTypeBot) {
+		return token
+	}
+	return ""
+}
+
+// readSlot reads a token from the environment, the OS keyring or the
+// configuration file, in that order
+func (m *Manager) readSlot(slot tokenSlot) string {
+	if token := os.Getenv(slot.envVar); token != "" {
+		return token
+	}
+
+	if token := readKeyring(slot.keyringKey); token != "" {
+		return token
+	}
+
+	config, err := m.Load()
+	if err != nil {
+		return ""
+	}
+	return *slot.field(&config.Slack)
+}
+
+// GetAppToken retrieves the optional app-level token used for Socket Mode
+func (m *Manager) GetAppToken() string {
+	return m.readSlot(appTokenSlot)
 }
 
 // createDefaultConfig creates a default configuration file
@@ -150,9 +406,16 @@ func (m *Manager) createDefaultConfig() (*Config, error) {
 		Export: ExportConfig{
 			DefaultOutputDir: "./exports",
 			IncludeThreads:   true,
+			IncludeFiles:     true,
+			IncludeReactions: true,
 			IncludeUsers:     true,
 			MaxMessages:      0,
 		},
+		Cache: CacheConfig{
+			ChannelsTTL: DefaultChannelsTTL,
+			UsersTTL:    DefaultUsersTTL,
+		},
+		History: HistoryConfig{Keep: DefaultHistoryKeep},
 	}
 
 	// Create config directory if it doesn't exist
@@ -164,8 +427,32 @@ func (m *Manager) createDefaultConfig() (*Config, error) {
 	return config, nil
 }
 
+// GetBackupProfile returns the named backup profile from the configuration
+func (m *Manager) GetBackupProfile(name string) (*BackupProfile, error) {
+	config, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// Viper lower-cases map keys
+	profile, ok := config.Backups[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("backup profile '%s' not found in configuration", name)
+	}
+
+	return &profile, nil
+}
+
 // GetConfigPath returns the path to the configuration file
 func (m *Manager) GetConfigPath() string {
+	if used := viper.ConfigFileUsed(); used != "" {
+		return used
+	}
+	if m.configPath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			m.configPath = filepath.Join(home, ConfigFileName+"."+ConfigFileType)
+		}
+	}
 	return m.configPath
 }
 
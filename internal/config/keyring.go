@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/itcaat/slacker/models"
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name tokens are stored under in the OS keyring
+const keyringService = "slacker"
+
+// tokenSlot describes where one kind of token is read from and stored
+type tokenSlot struct {
+	envVar     string
+	keyringKey string
+	field      func(*models.SlackConfig) *string
+}
+
+// tokenSlots lists every stored token, starting with the generic token
+var tokenSlots = []tokenSlot{
+	{envVar: "SLACKER_SLACK_TOKEN", keyringKey: "slack.token", field: func(c *models.SlackConfig) *string { return &c.Token }},
+	{envVar: "SLACKER_SLACK_BOT_TOKEN", keyringKey: "slack.bot_token", field: func(c *models.SlackConfig) *string { return &c.BotToken }},
+	{envVar: "SLACKER_SLACK_USER_TOKEN", keyringKey: "slack.user_token", field: func(c *models.SlackConfig) *string { return &c.UserToken }},
+	appTokenSlot,
+}
+
+// appTokenSlot holds the app-level token used for Socket Mode
+var appTokenSlot = tokenSlot{envVar: "SLACKER_SLACK_APP_TOKEN", keyringKey: "slack.app_token", field: func(c *models.SlackConfig) *string { return &c.AppToken }}
+
+// tokenSlotFor returns the dedicated slot for a bot or user token
+func tokenSlotFor(tokenType models.TokenType) tokenSlot {
+	if tokenType == models.TokenTypeUser {
+		return tokenSlots[2]
+	}
+	return tokenSlots[1]
+}
+
+// slotForToken picks the slot a new token is saved to based on its prefix
+func slotForToken(token string) tokenSlot {
+	switch models.DetectTokenType(token) {
+	case models.TokenTypeBot, models.TokenTypeUser:
+		return tokenSlotFor(models.DetectTokenType(token))
+	}
+	return tokenSlots[0]
+}
+
+// readKeyring returns the secret stored under key, or "" if there is none or
+// the OS keyring is unavailable
+func readKeyring(key string) string {
+	secret, err := keyring.Get(keyringService, key)
+	if err != nil {
+		if !errors.Is(err, keyring.ErrNotFound) {
+			slog.Debug("OS keyring unavailable", "key", key, "error", err)
+		}
+		return ""
+	}
+	return secret
+}
+
+// writeKeyring stores a secret in the OS keyring
+func writeKeyring(key, secret string) error {
+	if err := keyring.Set(keyringService, key, secret); err != nil {
+		return fmt.Errorf("failed to store %s in the OS keyring (use --insecure-config to store it in the config file instead): %w", key, err)
+	}
+	return nil
+}
+
+// deleteKeyring removes a secret from the OS keyring, ignoring missing entries
+func deleteKeyring(key string) error {
+	if err := keyring.Delete(keyringService, key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to remove %s from the OS keyring: %w", key, err)
+	}
+	return nil
+}
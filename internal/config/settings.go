@@ -0,0 +1,351 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/i18n"
+	"github.com/itcaat/slacker/internal/schedule"
+	"github.com/itcaat/slacker/models"
+	"github.com/spf13/viper"
+)
+
+// Setting describes a configuration key that can be managed with 'slacker config'
+type Setting struct {
+	Key         string
+	Description string
+	// Allowed lists the accepted values; empty means any value of the kind
+	Allowed []string
+	Kind    SettingKind
+}
+
+// SettingKind is the value type of a setting
+type SettingKind string
+
+// Supported setting kinds
+const (
+	KindString   SettingKind = "string"
+	KindBool     SettingKind = "bool"
+	KindInt      SettingKind = "int"
+	KindList     SettingKind = "list"
+	KindDuration SettingKind = "duration"
+)
+
+// exportFormats, compressions and themes are the allowed values shared by
+// several settings. themes must match the built-in TUI themes.
+var (
+	exportFormats = []string{"json", "json-pretty", "json-compact", "pdf", "llm-jsonl"}
+	compressions  = []string{"none", "gzip"}
+	themes        = []string{"default", "high-contrast", "light"}
+)
+
+// maxConcurrency caps export.concurrency to stay within Slack rate limits
+const maxConcurrency = 16
+
+// maxPageSize is the largest page the Slack history API returns
+const maxPageSize = 1000
+
+// Settings lists the top-level configuration keys
+var Settings = []Setting{
+	{Key: "debug", Kind: KindBool, Description: "Enable debug logging and Slack API debug output (like --debug)"},
+	{Key: "export.default_output_dir", Kind: KindString, Description: "Directory for export files"},
+	{Key: "export.default_format", Kind: KindString, Allowed: exportFormats, Description: "Output format used when --format is not given"},
+	{Key: "export.include_threads", Kind: KindBool, Description: "Include thread replies in exports"},
+	{Key: "export.include_files", Kind: KindBool, Description: "Include file attachments in exports"},
+	{Key: "export.include_reactions", Kind: KindBool, Description: "Include message reactions in exports"},
+	{Key: "export.include_users", Kind: KindBool, Description: "Include user information in exports"},
+	{Key: "export.max_messages", Kind: KindInt, Description: "Maximum messages per export (0 = no limit)"},
+	{Key: "export.snapshot_service", Kind: KindString, Description: "Web archive request URL for --snapshot-links, e.g. https://web.archive.org/save/{url}"},
+	{Key: "export.archive_domains", Kind: KindList, Description: "Domains whose shared URLs export.snapshot_service archives"},
+	{Key: "export.concurrency", Kind: KindInt, Description: fmt.Sprintf("Channels exported in parallel (1-%d)", maxConcurrency)},
+	{Key: "ui.theme", Kind: KindString, Allowed: themes, Description: "TUI color theme"},
+	{Key: "ui.lang", Kind: KindString, Allowed: i18n.Languages(), Description: "Language of command output (default: from $LANG)"},
+	{Key: "ui.emoji", Kind: KindBool, Description: "Show emoji in command output"},
+	{Key: "ui.ascii", Kind: KindBool, Description: "Use only ASCII symbols in command output and the TUI"},
+	{Key: "timezone", Kind: KindString, Description: "Timezone for timestamps in output, exports and date statistics, e.g. UTC (default: local)"},
+	{Key: "api.timeout", Kind: KindDuration, Description: "Timeout for interactive commands, e.g. 2m (default: 10s-60s per command)"},
+	{Key: "api.page_size", Kind: KindInt, Description: "Messages requested per history page (1-1000)"},
+	{Key: "api.thread_delay", Kind: KindDuration, Description: "Pause between paginated and thread requests, e.g. 250ms"},
+	{Key: "cache.channels_ttl", Kind: KindDuration, Description: "How long the channel list is cached, 0 to disable"},
+	{Key: "cache.users_ttl", Kind: KindDuration, Description: "How long the user list is cached, 0 to disable"},
+	{Key: "network.proxy", Kind: KindString, Description: "HTTP or SOCKS5 proxy URL for Slack connections"},
+	{Key: "network.ca_cert", Kind: KindString, Description: "PEM bundle of additional trusted root CAs"},
+	{Key: "network.insecure_skip_verify", Kind: KindBool, Description: "Disable TLS certificate verification (unsafe)"},
+	{Key: "daemon.schedule", Kind: KindString, Description: "Cron expression for 'slacker daemon'"},
+	{Key: "daemon.channels", Kind: KindList, Description: "Channels exported by 'slacker daemon'"},
+	{Key: "daemon.output_dir", Kind: KindString, Description: "Directory for daemon export files"},
+	{Key: "daemon.format", Kind: KindString, Allowed: exportFormats, Description: "Daemon output format"},
+	{Key: "daemon.compression", Kind: KindString, Allowed: compressions, Description: "Daemon compression"},
+	{Key: "daemon.keep", Kind: KindInt, Description: "Daemon export files kept per channel (0 = keep all)"},
+	{Key: "daemon.keep_days", Kind: KindInt, Description: "Remove daemon export files older than this many days (0 = keep all)"},
+	{Key: "hooks.on_message_fetched", Kind: KindString, Description: "Shell command run per message with its JSON on stdin; prints a replacement, null to drop, or nothing"},
+	{Key: "hooks.on_before_write", Kind: KindString, Description: "Shell command run with the export JSON on stdin before writing; prints a replacement or nothing"},
+	{Key: "hooks.on_complete", Kind: KindString, Description: "Shell command run with the export result JSON on stdin"},
+	{Key: "hooks.timeout", Kind: KindDuration, Description: "Time limit per hook command run (default 1m)"},
+	{Key: "audit.path", Kind: KindString, Description: "Append-only audit log of exports and reads (default ~/.slacker/audit.log)"},
+	{Key: "audit.disabled", Kind: KindBool, Description: "Do not write the audit log"},
+	{Key: "history.path", Kind: KindString, Description: "Directory of the export job history (default ~/.slacker/history)"},
+	{Key: "history.disabled", Kind: KindBool, Description: "Do not record export jobs"},
+	{Key: "history.keep", Kind: KindInt, Description: fmt.Sprintf("Export jobs kept in the history (0 = keep all, default %d)", DefaultHistoryKeep)},
+}
+
+// profileSettings lists the fields of a backup profile, set as backups.<name>.<field>
+var profileSettings = []Setting{
+	{Key: "channels", Kind: KindList, Description: "Channels to back up"},
+	{Key: "destination", Kind: KindString, Description: "Output directory or s3://, gs://, azblob:// prefix"},
+	{Key: "format", Kind: KindString, Allowed: exportFormats, Description: "Output format"},
+	{Key: "compression", Kind: KindString, Allowed: compressions, Description: "Compression"},
+	{Key: "include_threads", Kind: KindBool, Description: "Include thread replies"},
+	{Key: "incremental", Kind: KindBool, Description: "Only export messages since the previous run"},
+	{Key: "keep", Kind: KindInt, Description: "Export files kept per channel (0 = keep all); alias of keep_last"},
+	{Key: "keep_last", Kind: KindInt, Description: "Export files kept per channel (0 = keep all)"},
+	{Key: "keep_days", Kind: KindInt, Description: "Remove export files older than this many days (0 = keep all)"},
+	{Key: "schedule", Kind: KindString, Description: "Cron expression used by 'slacker daemon --profile'"},
+}
+
+// LookupSetting returns the setting for key, including backup profile fields
+func LookupSetting(key string) (Setting, error) {
+	key = strings.ToLower(key)
+	for _, setting := range Settings {
+		if setting.Key == key {
+			return setting, nil
+		}
+	}
+
+	if parts := strings.Split(key, "."); len(parts) == 3 && parts[0] == "backups" && parts[1] != "" {
+		for _, setting := range profileSettings {
+			if setting.Key == parts[2] {
+				setting.Key = key
+				return setting, nil
+			}
+		}
+	}
+
+	if strings.HasPrefix(key, "slack.") {
+		return Setting{}, fmt.Errorf("tokens cannot be managed with 'slacker config'. Use 'slacker auth' instead")
+	}
+	return Setting{}, fmt.Errorf("unknown setting '%s'. Run 'slacker config list' to see available settings", key)
+}
+
+// Parse validates value and converts it to the setting's type
+func (s Setting) Parse(value string) (interface{}, error) {
+	var parsed interface{}
+	switch s.Kind {
+	case KindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", s.Key)
+		}
+		parsed = b
+	case KindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", s.Key)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("%s must not be negative", s.Key)
+		}
+		if s.Key == "export.concurrency" && (n < 1 || n > maxConcurrency) {
+			return nil, fmt.Errorf("%s must be between 1 and %d", s.Key, maxConcurrency)
+		}
+		if s.Key == "api.page_size" && (n < 1 || n > maxPageSize) {
+			return nil, fmt.Errorf("%s must be between 1 and %d", s.Key, maxPageSize)
+		}
+		parsed = n
+	case KindDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a duration such as 30s or 250ms", s.Key)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("%s must not be negative", s.Key)
+		}
+		parsed = d.String()
+	case KindList:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		parsed = items
+	default:
+		// An empty value resets the setting to its default
+		if value != "" && len(s.Allowed) > 0 && !contains(s.Allowed, value) {
+			return nil, fmt.Errorf("invalid value '%s' for %s. Allowed: %s", value, s.Key, strings.Join(s.Allowed, ", "))
+		}
+		if s.Key == "timezone" {
+			if _, err := models.LoadTimezone(value); err != nil {
+				return nil, err
+			}
+		}
+		if strings.HasSuffix(s.Key, "schedule") && value != "" {
+			if _, err := schedule.Parse(value); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", s.Key, err)
+			}
+		}
+		parsed = value
+	}
+	return parsed, nil
+}
+
+// GetSetting returns the current value of a setting formatted for display
+func (m *Manager) GetSetting(key string) (string, error) {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+	if _, err := m.Load(); err != nil {
+		return "", err
+	}
+	return formatSetting(viper.Get(setting.Key)), nil
+}
+
+// SetSetting validates value and writes it to the configuration file
+func (m *Manager) SetSetting(key, value string) error {
+	setting, err := LookupSetting(key)
+	if err != nil {
+		return err
+	}
+	parsed, err := setting.Parse(value)
+	if err != nil {
+		return err
+	}
+	if _, err := m.Load(); err != nil {
+		return err
+	}
+
+	viper.Set(setting.Key, parsed)
+	return m.writeConfig()
+}
+
+// SettingValue is a configured key and its display value
+type SettingValue struct {
+	Key   string
+	Value string
+}
+
+// ListSettings returns the known settings and any configured backup profile
+// fields with their current values
+func (m *Manager) ListSettings() ([]SettingValue, error) {
+	if _, err := m.Load(); err != nil {
+		return nil, err
+	}
+
+	var values []SettingValue
+	for _, setting := range Settings {
+		values = append(values, SettingValue{Key: setting.Key, Value: formatSetting(viper.Get(setting.Key))})
+	}
+
+	var profileKeys []string
+	for _, key := range viper.AllKeys() {
+		if strings.HasPrefix(key, "backups.") {
+			profileKeys = append(profileKeys, key)
+		}
+	}
+	sort.Strings(profileKeys)
+	for _, key := range profileKeys {
+		values = append(values, SettingValue{Key: key, Value: formatSetting(viper.Get(key))})
+	}
+	return values, nil
+}
+
+// Diagnostic is a problem found by Doctor
+type Diagnostic struct {
+	Key     string
+	Message string
+	// Fatal diagnostics prevent slacker from working; others are warnings
+	Fatal bool
+}
+
+// Doctor checks the configuration for invalid values, unknown keys, missing
+// tokens and incomplete backup profiles
+func (m *Manager) Doctor() []Diagnostic {
+	var diagnostics []Diagnostic
+
+	if _, err := m.Load(); err != nil {
+		return []Diagnostic{{Key: m.GetConfigPath(), Message: err.Error(), Fatal: true}}
+	}
+
+	if _, err := m.GetToken(); err != nil {
+		diagnostics = append(diagnostics, Diagnostic{Key: "slack.token", Message: "no Slack token configured. Run 'slacker auth <token>'", Fatal: true})
+	}
+	if m.HasPlaintextTokens() {
+		diagnostics = append(diagnostics, Diagnostic{Key: "slack", Message: "tokens are stored in cleartext. Run 'slacker auth migrate'"})
+	}
+
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.HasPrefix(key, "slack.") {
+			continue
+		}
+		setting, err := LookupSetting(key)
+		if err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Key: key, Message: "unknown setting, it is ignored"})
+			continue
+		}
+		value := viper.Get(key)
+		if value == nil {
+			continue
+		}
+		if _, err := setting.Parse(formatSetting(value)); err != nil {
+			diagnostics = append(diagnostics, Diagnostic{Key: key, Message: err.Error(), Fatal: true})
+		}
+	}
+
+	if dir := viper.GetString("export.default_output_dir"); dir != "" {
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			diagnostics = append(diagnostics, Diagnostic{Key: "export.default_output_dir", Message: fmt.Sprintf("%s is not a directory", dir), Fatal: true})
+		}
+	}
+
+	profiles := viper.GetStringMap("backups")
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if len(viper.GetStringSlice("backups."+name+".channels")) == 0 {
+			diagnostics = append(diagnostics, Diagnostic{Key: "backups." + name + ".channels", Message: "backup profile has no channels", Fatal: true})
+		}
+	}
+
+	if viper.GetString("daemon.schedule") != "" && len(viper.GetStringSlice("daemon.channels")) == 0 {
+		diagnostics = append(diagnostics, Diagnostic{Key: "daemon.channels", Message: "daemon schedule is set but no channels are configured"})
+	}
+
+	return diagnostics
+}
+
+// formatSetting renders a setting value for display and re-validation
+func formatSetting(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ",")
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// contains reports whether values includes value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
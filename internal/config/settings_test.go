@@ -0,0 +1,65 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookupSetting(t *testing.T) {
+	if _, err := LookupSetting("export.default_format"); err != nil {
+		t.Errorf("Expected export.default_format to be known, got %v", err)
+	}
+
+	setting, err := LookupSetting("backups.Nightly.keep")
+	if err != nil {
+		t.Fatalf("Expected backup profile field to be known, got %v", err)
+	}
+	if setting.Key != "backups.nightly.keep" || setting.Kind != KindInt {
+		t.Errorf("Expected int setting backups.nightly.keep, got %s (%s)", setting.Key, setting.Kind)
+	}
+
+	for _, key := range []string{"export.colour", "backups.nightly.owner", "slack.token"} {
+		if _, err := LookupSetting(key); err == nil {
+			t.Errorf("Expected error for %s", key)
+		}
+	}
+}
+
+func TestSetting_Parse(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    interface{}
+		wantErr bool
+	}{
+		{key: "export.include_threads", value: "false", want: false},
+		{key: "export.include_threads", value: "maybe", wantErr: true},
+		{key: "export.max_messages", value: "500", want: 500},
+		{key: "export.max_messages", value: "-1", wantErr: true},
+		{key: "export.concurrency", value: "4", want: 4},
+		{key: "export.concurrency", value: "0", wantErr: true},
+		{key: "export.default_format", value: "json-compact", want: "json-compact"},
+		{key: "export.default_format", value: "xml", wantErr: true},
+		{key: "ui.theme", value: "", want: ""},
+		{key: "daemon.channels", value: "general, random,", want: []string{"general", "random"}},
+		{key: "daemon.schedule", value: "@daily", want: "@daily"},
+		{key: "backups.nightly.schedule", value: "every night", wantErr: true},
+		{key: "timezone", value: "UTC", want: "UTC"},
+		{key: "timezone", value: "Mars/Olympus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		setting, err := LookupSetting(tt.key)
+		if err != nil {
+			t.Fatalf("LookupSetting(%s) failed: %v", tt.key, err)
+		}
+		got, err := setting.Parse(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%s=%q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%s=%q) = %#v, want %#v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
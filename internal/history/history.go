@@ -0,0 +1,246 @@
+// Package history keeps a record of every export run: the command line it
+// was started with, its settings and its outcome. 'slacker jobs' lists the
+// records and runs past exports again with identical settings.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// DefaultDir is the history directory below the user's home directory
+const DefaultDir = ".slacker/history"
+
+// idLayout formats job IDs from their start time
+const idLayout = "20060102-150405"
+
+// Job is the record of one export run
+type Job struct {
+	ID string `json:"id"`
+	// Command is the slacker command that ran, e.g. "export"
+	Command string `json:"command"`
+	// Args is the command line after "slacker", with secrets redacted,
+	// and Dir the working directory it ran in
+	Args      []string      `json:"args"`
+	Dir       string        `json:"dir"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	// Options are the resolved export settings, including those that came
+	// from the config file
+	Options  *models.ExportOptions `json:"options,omitempty"`
+	Channel  string                `json:"channel,omitempty"`
+	Output   string                `json:"output,omitempty"`
+	Messages int                   `json:"messages"`
+	FileSize int64                 `json:"file_size,omitempty"`
+	Success  bool                  `json:"success"`
+	Error    string                `json:"error,omitempty"`
+	ExitCode int                   `json:"exit_code"`
+}
+
+// Store is a directory with one JSON file per job. A nil Store records
+// nothing.
+type Store struct {
+	dir string
+}
+
+// New returns a store rooted at dir
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Open returns a store rooted at dir, or at DefaultPath when dir is empty
+func Open(dir string) (*Store, error) {
+	if dir == "" {
+		var err error
+		if dir, err = DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return New(dir), nil
+}
+
+// DefaultPath returns ~/.slacker/history
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, DefaultDir), nil
+}
+
+// Dir returns the directory the store writes to
+func (s *Store) Dir() string {
+	if s == nil {
+		return ""
+	}
+	return s.dir
+}
+
+// Record saves job. A job without an ID gets one from its start time,
+// suffixed when several jobs started within the same second.
+func (s *Store) Record(job *Job) error {
+	if s == nil {
+		return nil
+	}
+	if job.StartedAt.IsZero() {
+		job.StartedAt = time.Now()
+	}
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	if job.ID != "" {
+		return s.write(job.ID, data, os.O_TRUNC)
+	}
+	base := job.StartedAt.Format(idLayout)
+	for n := 1; ; n++ {
+		job.ID = base
+		if n > 1 {
+			job.ID = fmt.Sprintf("%s-%d", base, n)
+		}
+		if data, err = json.MarshalIndent(job, "", "  "); err != nil {
+			return err
+		}
+		// O_EXCL claims the ID even with several slacker processes
+		// finishing at once
+		err = s.write(job.ID, data, os.O_EXCL)
+		if !errors.Is(err, os.ErrExist) {
+			return err
+		}
+	}
+}
+
+// write stores the record of job id, opened with the extra flag
+func (s *Store) write(id string, data []byte, flag int) error {
+	file, err := os.OpenFile(s.path(id), os.O_WRONLY|os.O_CREATE|flag, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return err
+		}
+		return fmt.Errorf("failed to write job history: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write job history: %w", err)
+	}
+	return file.Close()
+}
+
+// Get returns the job with id
+func (s *Store) Get(id string) (*Job, error) {
+	if s == nil || id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("job '%s' not found", id)
+	}
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("job '%s' not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job %s: %w", id, err)
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// List returns all recorded jobs, newest first. Unreadable records are
+// skipped.
+func (s *Store) List() ([]Job, error) {
+	if s == nil {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job history: %w", err)
+	}
+
+	var jobs []Job
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		job, err := s.Get(id)
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, *job)
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		if !jobs[i].StartedAt.Equal(jobs[j].StartedAt) {
+			return jobs[i].StartedAt.After(jobs[j].StartedAt)
+		}
+		return jobs[i].ID > jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// Prune removes all but the newest keep jobs and returns how many it
+// removed. keep <= 0 keeps all jobs.
+func (s *Store) Prune(keep int) (int, error) {
+	if s == nil || keep <= 0 {
+		return 0, nil
+	}
+	jobs, err := s.List()
+	if err != nil || len(jobs) <= keep {
+		return 0, err
+	}
+	removed := 0
+	for _, job := range jobs[keep:] {
+		if err := os.Remove(s.path(job.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("failed to remove job %s: %w", job.ID, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// path returns the file of job id
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// ForExport describes a run of command that exported options.ChannelID with
+// its outcome
+func ForExport(command string, options models.ExportOptions, result *models.ExportResult, err error) *Job {
+	job := &Job{
+		Command: command,
+		Options: &options,
+		Channel: options.ChannelName,
+		Output:  options.OutputFile,
+		Success: err == nil && result != nil && result.Success,
+	}
+	if job.Channel == "" {
+		job.Channel = options.ChannelID
+	}
+	if result != nil {
+		if result.OutputFile != "" {
+			job.Output = result.OutputFile
+		}
+		job.Messages = result.Statistics.TotalMessages
+		job.FileSize = result.FileSize
+		job.Error = result.Error
+	}
+	if err != nil {
+		job.Error = err.Error()
+	}
+	job.ExitCode = models.ExitCodeOf(err)
+	return job
+}
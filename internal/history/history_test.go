@@ -0,0 +1,92 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestStore_RecordAndList(t *testing.T) {
+	store := New(filepath.Join(t.TempDir(), "history"))
+	start := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	first := &Job{Command: "export", Args: []string{"export", "--channel", "general"}, StartedAt: start, Success: true}
+	second := &Job{Command: "export", StartedAt: start}
+	third := &Job{Command: "export-all", StartedAt: start.Add(time.Hour)}
+	for _, job := range []*Job{first, second, third} {
+		if err := store.Record(job); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	// Jobs started within the same second get distinct IDs
+	if first.ID != "20240115-103000" || second.ID != "20240115-103000-2" {
+		t.Errorf("Expected IDs from the start time, got %s and %s", first.ID, second.ID)
+	}
+
+	jobs, err := store.List()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(jobs) != 3 || jobs[0].ID != third.ID || jobs[1].ID != second.ID {
+		t.Fatalf("Expected the newest job first, got %+v", jobs)
+	}
+
+	job, err := store.Get(first.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !job.Success || len(job.Args) != 3 || job.Args[2] != "general" {
+		t.Errorf("Expected the recorded job, got %+v", job)
+	}
+	if _, err := store.Get("../audit"); err == nil {
+		t.Error("Expected IDs with path separators to be rejected")
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	store := New(t.TempDir())
+	start := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		if err := store.Record(&Job{Command: "export", StartedAt: start.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := store.Prune(2)
+	if err != nil || removed != 3 {
+		t.Fatalf("Expected 3 jobs removed, got %d, %v", removed, err)
+	}
+	jobs, _ := store.List()
+	if len(jobs) != 2 || jobs[1].ID != "20240115-103300" {
+		t.Errorf("Expected the 2 newest jobs to remain, got %+v", jobs)
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	var store *Store
+	if err := store.Record(&Job{}); err != nil {
+		t.Errorf("Expected a nil store to record nothing, got %v", err)
+	}
+	if jobs, err := store.List(); err != nil || jobs != nil {
+		t.Errorf("Expected no jobs, got %v, %v", jobs, err)
+	}
+}
+
+func TestForExport(t *testing.T) {
+	options := models.ExportOptions{ChannelID: "C1", ChannelName: "general", OutputFile: "general.json", Format: "json"}
+	result := &models.ExportResult{Success: true, OutputFile: "general.json.gz", FileSize: 42}
+	result.Statistics.TotalMessages = 7
+
+	job := ForExport("export", options, result, nil)
+	if !job.Success || job.Channel != "general" || job.Output != "general.json.gz" || job.Messages != 7 || job.Options.Format != "json" {
+		t.Errorf("Unexpected job %+v", job)
+	}
+
+	job = ForExport("export", options, nil, models.NewExportError(models.ErrorCategoryAuth, "token expired", errors.New("invalid_auth")))
+	if job.Success || job.ExitCode != models.ExitCodeAuth || job.Error == "" {
+		t.Errorf("Expected a failed job, got %+v", job)
+	}
+}
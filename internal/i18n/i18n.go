@@ -0,0 +1,135 @@
+// Package i18n translates slacker's command-line output. Messages are looked
+// up by their English format string, so untranslated messages fall back to
+// English, and emoji can be left out for terminals that render them poorly.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Supported languages
+const (
+	English = "en"
+	Russian = "ru"
+)
+
+// catalogs maps a language to its translations, keyed by the English format
+// string. English needs no catalog.
+var catalogs = map[string]map[string]string{
+	Russian: russian,
+}
+
+var (
+	language = English
+	emoji    = true
+)
+
+// Languages returns the supported language codes
+func Languages() []string {
+	languages := []string{English}
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages[1:])
+	return languages
+}
+
+// Parse returns the language code of a name such as "ru", "ru_RU.UTF-8" or
+// "en-US"
+func Parse(name string) (string, error) {
+	code := strings.ToLower(name)
+	if i := strings.IndexAny(code, "_-.@"); i >= 0 {
+		code = code[:i]
+	}
+	if code == English {
+		return English, nil
+	}
+	if _, ok := catalogs[code]; ok {
+		return code, nil
+	}
+	return "", fmt.Errorf("unsupported language '%s'. Supported languages: %s", name, strings.Join(Languages(), ", "))
+}
+
+// Detect returns the language of the LC_ALL, LC_MESSAGES or LANG environment
+// variable, in that order, or English when none names a supported language
+func Detect() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		if lang, err := Parse(value); err == nil {
+			return lang
+		}
+		// The first variable set decides, like in the C library
+		return English
+	}
+	return English
+}
+
+// SetLanguage sets the language of translated output
+func SetLanguage(lang string) {
+	language = lang
+}
+
+// Language returns the language of translated output
+func Language() string {
+	return language
+}
+
+// SetEmoji turns emoji in translated output on or off
+func SetEmoji(enabled bool) {
+	emoji = enabled
+}
+
+// Sprintf translates format into the current language and formats it like
+// fmt.Sprintf, applying the emoji settings (see Text)
+func Sprintf(format string, args ...interface{}) string {
+	if translated, ok := catalogs[language][format]; ok {
+		format = translated
+	}
+	return Text(fmt.Sprintf(format, args...))
+}
+
+// StripEmoji removes emoji, and the spaces that separate them from the
+// following text, from s
+func StripEmoji(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	skipSpaces := false
+	for _, r := range s {
+		if isEmoji(r) {
+			skipSpaces = true
+			continue
+		}
+		if skipSpaces && r == ' ' {
+			continue
+		}
+		skipSpaces = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isEmoji reports whether r is a pictograph, a symbol drawn as emoji, or a
+// joiner or variation selector that is part of an emoji sequence
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // Pictographs, emoticons, transport, flags
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2300 && r <= 0x23FF: // Technical symbols such as ⏱ and ⌛
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // Arrows and shapes such as ⭐
+		return true
+	case r == 0x200D || r == 0xFE0F || r == 0x20E3: // Joiner, emoji variation selector, keycap
+		return true
+	case r == 0x2139 || r == 0x2122 || r == 0x00A9 || r == 0x00AE: // ℹ ™ © ®
+		return true
+	}
+	return false
+}
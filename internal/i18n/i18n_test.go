@@ -0,0 +1,131 @@
+package i18n
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "en", want: English},
+		{name: "ru", want: Russian},
+		{name: "ru_RU.UTF-8", want: Russian},
+		{name: "en-US", want: English},
+		{name: "RU", want: Russian},
+		{name: "de_DE", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Parse(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "ru_RU.UTF-8")
+	if got := Detect(); got != Russian {
+		t.Errorf("Expected Russian from LANG, got %s", got)
+	}
+
+	// LC_ALL takes precedence, even when it names an unsupported language
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	if got := Detect(); got != English {
+		t.Errorf("Expected English for an unsupported LC_ALL, got %s", got)
+	}
+}
+
+func TestSprintf(t *testing.T) {
+	defer SetLanguage(English)
+	defer SetEmoji(true)
+
+	SetLanguage(Russian)
+	if got := Sprintf("📏 File size: %s\n", "1.2 MB"); got != "📏 Размер файла: 1.2 MB\n" {
+		t.Errorf("Unexpected translation %q", got)
+	}
+	if got := Sprintf("untranslated %d\n", 3); got != "untranslated 3\n" {
+		t.Errorf("Expected untranslated messages in English, got %q", got)
+	}
+
+	SetLanguage(English)
+	SetEmoji(false)
+	if got := Sprintf("⏱️  Duration: %s\n", "2s"); got != "Duration: 2s\n" {
+		t.Errorf("Expected the emoji and its spaces to be removed, got %q", got)
+	}
+}
+
+func TestStripEmoji(t *testing.T) {
+	tests := map[string]string{
+		"✅ #general: 4 messages":  "#general: 4 messages",
+		"   🗑️  removed a.json":   "   removed a.json",
+		"\n📊 Run 1: 2 done":       "\nRun 1: 2 done",
+		"👨‍💻 dev":                 "dev",
+		"no emoji, 100% -> plain": "no emoji, 100% -> plain",
+		"Ошибка: нет файла":       "Ошибка: нет файла",
+	}
+	for in, want := range tests {
+		if got := StripEmoji(in); got != want {
+			t.Errorf("StripEmoji(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// verb matches the fmt verbs of a format string
+var verb = regexp.MustCompile(`%[-+# 0*]*[0-9]*(?:\.[0-9]+)?[a-zA-Z%]`)
+
+func TestCatalogsKeepVerbs(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for en, translated := range catalog {
+			want := strings.Join(verb.FindAllString(en, -1), " ")
+			if got := strings.Join(verb.FindAllString(translated, -1), " "); got != want {
+				t.Errorf("%s: %q uses verbs %q, want %q from %q", lang, translated, got, want, en)
+			}
+			if strings.HasSuffix(en, "\n") != strings.HasSuffix(translated, "\n") {
+				t.Errorf("%s: %q must keep the line ending of %q", lang, translated, en)
+			}
+		}
+	}
+}
+
+func TestToASCII(t *testing.T) {
+	tests := map[string]string{
+		"✅ done":                     "[ok] done",
+		"❌ #general: failed":         "[x] #general: failed",
+		"⚠️  2 warnings":             "[!]  2 warnings",
+		"📊 #general: a → b":          "#general: a -> b",
+		"↑/↓: navigate • q: quit":    "^/v: navigate * q: quit",
+		"[████░░]":                   "[####..]",
+		"Привет 👋":                   "Привет ",
+		"§ unknown":                  "? unknown",
+		"plain text, 100% and more.": "plain text, 100% and more.",
+	}
+	for in, want := range tests {
+		if got := ToASCII(in); got != want {
+			t.Errorf("ToASCII(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	defer SetEmoji(true)
+	defer SetASCII(false)
+
+	if got := Text(IconOK + " ok"); got != "✅ ok" {
+		t.Errorf("Expected emoji by default, got %q", got)
+	}
+	SetEmoji(false)
+	if got := Text(IconOK + " ok"); got != "ok" {
+		t.Errorf("Expected emoji to be left out, got %q", got)
+	}
+	SetASCII(true)
+	if got := Text(IconOK + " ok"); got != "[ok] ok" {
+		t.Errorf("Expected the ASCII marker to win over --no-emoji, got %q", got)
+	}
+}
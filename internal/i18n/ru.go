@@ -0,0 +1,261 @@
+package i18n
+
+// russian translates slacker's command-line output into Russian
+var russian = map[string]string{
+	"Error: %v\n":                          "Ошибка: %v\n",
+	"Setting and testing Slack token...\n": "Сохранение и проверка токена Slack...\n",
+	"⚠️  %s token saved to configuration file in cleartext\n": "⚠️  Токен %s сохранён в файле конфигурации в открытом виде\n",
+	"✅ %s token saved to OS keyring\n":                        "✅ Токен %s сохранён в хранилище ключей ОС\n",
+	"Testing existing Slack authentication...\n":              "Проверка текущей авторизации Slack...\n",
+	"⚠️  Tokens are stored in cleartext in the config file. Run 'slacker auth migrate' to move them to the OS keyring\n": "⚠️  Токены хранятся в файле конфигурации в открытом виде. Выполните 'slacker auth migrate', чтобы перенести их в хранилище ключей ОС\n",
+	"No plaintext tokens found in the config file\n":   "В файле конфигурации нет токенов в открытом виде\n",
+	"✅ Moved %s to the OS keyring\n":                   "✅ %s перенесён в хранилище ключей ОС\n",
+	"🔄 Testing Slack API connection...\n":              "🔄 Проверка подключения к Slack API...\n",
+	"✅ Authentication successful!\n":                   "✅ Авторизация прошла успешно!\n",
+	"   User: %s\n":                                    "   Пользователь: %s\n",
+	"   Team: %s\n":                                    "   Рабочее пространство: %s\n",
+	"   Token type: %s\n":                              "   Тип токена: %s\n",
+	"🔄 Checking token scopes...\n":                     "🔄 Проверка прав токена...\n",
+	"🔄 Testing channel access...\n":                    "🔄 Проверка доступа к каналам...\n",
+	"✅ Channel access successful! Found %d channels\n": "✅ Доступ к каналам есть! Найдено каналов: %d\n",
+	"   Sample channels:\n":                            "   Примеры каналов:\n",
+	"   - #%s (%d members)\n":                          "   - #%s (участников: %d)\n",
+	"   ... and %d more\n":                             "   ... и ещё %d\n",
+	"⚠️  Could not inspect token scopes: %v\n":         "⚠️  Не удалось проверить права токена: %v\n",
+	"✅ All required scopes granted (%s)\n":             "✅ Все нужные права выданы (%s)\n",
+	"%s Missing scope %s - disables %s\n":              "%s Нет права %s - недоступно: %s\n",
+	"   Add the missing scopes under OAuth & Permissions and reinstall the app to your workspace\n": "   Добавьте недостающие права в разделе OAuth & Permissions и переустановите приложение в рабочее пространство\n",
+	"No backup profiles configured. Add a 'backups' section to your config file.\n":                 "Профили резервного копирования не настроены. Добавьте раздел 'backups' в файл конфигурации.\n",
+	"Found %d backup profiles:\n\n":                         "Найдено профилей резервного копирования: %d\n\n",
+	"   Channels: %s\n":                                     "   Каналы: %s\n",
+	"   Destination: %s\n":                                  "   Назначение: %s\n",
+	"   Format: %s":                                         "   Формат: %s",
+	" (compressed with %s)":                                 " (сжатие %s)",
+	"   Retention: %s\n":                                    "   Хранение: %s\n",
+	"   Parallel channels: %d\n":                            "   Параллельных каналов: %d\n",
+	"   Schedule: %s\n":                                     "   Расписание: %s\n",
+	"💾 Running backup profile '%s' (%d channels)\n":         "💾 Запуск профиля резервного копирования '%s' (каналов: %d)\n",
+	"📁 Destination: %s\n":                                   "📁 Назначение: %s\n",
+	"👷 Parallel channels: %d\n":                             "👷 Параллельных каналов: %d\n",
+	"✅ #%s: %d messages, %s -> %s\n":                        "✅ #%s: сообщений: %d, %s -> %s\n",
+	"   ✏️  %d edited, %d deleted since the previous run\n": "   ✏️  С прошлого запуска изменено: %d, удалено: %d\n",
+	"   🗑️  removed %s\n":                                   "   🗑️  удалён %s\n",
+	"\n⏱️  Duration: %s\n":                                  "\n⏱️  Длительность: %s\n",
+	"Profile '%s' has no retention policy. Set keep_last or keep_days.\n": "У профиля '%s' нет политики хранения. Задайте keep_last или keep_days.\n",
+	"💾 Profile '%s' (%s)\n": "💾 Профиль '%s' (%s)\n",
+	"✅ Nothing to prune\n":  "✅ Удалять нечего\n",
+	"\n%d exports would be removed. Run without --dry-run to delete them.\n": "\nБудет удалено экспортов: %d. Запустите без --dry-run, чтобы удалить их.\n",
+	"\n✅ Removed %d exports\n":                   "\n✅ Удалено экспортов: %d\n",
+	"🧹 Removed %d cache entries from %s\n":       "🧹 Удалено записей кэша: %d из %s\n",
+	"🔄 Fetching channels...\n":                   "🔄 Получение списка каналов...\n",
+	"No channels found matching the criteria.\n": "Подходящих каналов не найдено.\n",
+	"Found %d channels:\n\n":                     "Найдено каналов: %d\n\n",
+	"   Members: %d\n":                           "   Участников: %d\n",
+	"   Topic: %s\n":                             "   Тема: %s\n",
+	"   Purpose: %s\n":                           "   Назначение канала: %s\n",
+	"   Created: %s\n":                           "   Создан: %s\n",
+	"📢 #%-20s %-8s %3d members%s\n":              "📢 #%-20s %-8s %3d участн.%s\n",
+	"🩺 Checking %s\n":                            "🩺 Проверка %s\n",
+	"✅ No problems found\n":                      "✅ Проблем не найдено\n",
+	"⚠️  %v; converting anyway\n":                "⚠️  %v; преобразование всё равно выполняется\n",
+	"⚠️  Comparing exports of different channels (#%s and #%s)\n":   "⚠️  Сравниваются экспорты разных каналов (#%s и #%s)\n",
+	"✅ No differences in messages or users\n":                       "✅ Сообщения и пользователи не различаются\n",
+	"🔎 Comparing messages from %s to %s, covered by both exports\n": "🔎 Сравниваются сообщения с %s по %s, охваченные обоими экспортами\n",
+	"➕ %d added:\n":         "➕ Добавлено: %d\n",
+	"➖ %d removed:\n":       "➖ Удалено: %d\n",
+	"✏️  %d edited:\n":      "✏️  Изменено: %d\n",
+	"👤 Users added: %s\n":   "👤 Добавлены пользователи: %s\n",
+	"👤 Users removed: %s\n": "👤 Удалены пользователи: %s\n",
+	"👤 %s changed: %s\n":    "👤 %s изменён: %s\n",
+	"\n📈 Statistics: messages %+d, threads %+d, replies %+d, users %+d, files %+d, reactions %+d\n": "\n📈 Статистика: сообщения %+d, треды %+d, ответы %+d, пользователи %+d, файлы %+d, реакции %+d\n",
+	"🚀 Starting export of channel '%s'\n":                                                           "🚀 Начат экспорт канала '%s'\n",
+	"📁 Output file: %s\n":                                                                           "📁 Выходной файл: %s\n",
+	"📊 Format: %s":                                                                                  "📊 Формат: %s",
+	"📅 Date range: ":                                                                                "📅 Период: ",
+	"from %s ":                                                                                      "с %s ",
+	"to %s ":                                                                                        "по %s ",
+	"🔧 Options: threads=%v, files=%v, reactions=%v\n":                                               "🔧 Параметры: треды=%v, файлы=%v, реакции=%v\n",
+	"❌ Export failed: %v\n":                                                                         "❌ Экспорт не удался: %v\n",
+	"❌ Export failed: %s\n":                                                                         "❌ Экспорт не удался: %s\n",
+	"✅ Export completed successfully!\n\n":                                                          "✅ Экспорт успешно завершён!\n\n",
+	"📁 Index file: %s (%d parts)\n":                                                                 "📁 Файл индекса: %s (частей: %d)\n",
+	"🔏 Manifest: %s\n":                                                                              "🔏 Манифест: %s\n",
+	"📏 File size: %s\n":                                                                             "📏 Размер файла: %s\n",
+	"⏱️  Duration: %s\n\n":                                                                          "⏱️  Длительность: %s\n\n",
+	"📊 Export Statistics:\n":                                                                        "📊 Статистика экспорта:\n",
+	"   Messages: %d (including %d thread replies)\n":                                               "   Сообщений: %d (включая ответов в тредах: %d)\n",
+	"   Threads: %d\n":                                                                              "   Тредов: %d\n",
+	"   Users: %d\n":                                                                                "   Пользователей: %d\n",
+	"   Attachments: %d\n":                                                                          "   Вложений: %d\n",
+	"   Files: %d\n":                                                                                "   Файлов: %d\n",
+	"   Reactions: %d\n":                                                                            "   Реакций: %d\n",
+	"\n✂️  Export stopped at --%s %s":                                                               "\n✂️  Экспорт остановлен по --%s %s",
+	"; oldest message from %s":                                                                      "; самое старое сообщение от %s",
+	"; %d threads without replies":                                                                  "; тредов без ответов: %d",
+	"\n⚠️  Partial export (%d warnings):\n":                                                         "\n⚠️  Неполный экспорт (предупреждений: %d):\n",
+	"\n⚠️  Export notes (%d):\n":                                                                    "\n⚠️  Примечания к экспорту (%d):\n",
+	"\n🎭 Top Reactions:\n":                                                                          "\n🎭 Популярные реакции:\n",
+	"\n⏱️  Processing Times:\n":                                                                     "\n⏱️  Время обработки:\n",
+	"   Channel fetch: %s\n":                                                                        "   Получение канала: %s\n",
+	"   Message fetch: %s\n":                                                                        "   Получение сообщений: %s\n",
+	"   Thread fetch: %s\n":                                                                         "   Получение тредов: %s\n",
+	"   User fetch: %s\n":                                                                           "   Получение пользователей: %s\n",
+	"   Data processing: %s\n":                                                                      "   Обработка данных: %s\n",
+	"   File generation: %s\n":                                                                      "   Создание файла: %s\n",
+	" - %d messages":                                                                                " - сообщений: %d",
+	" - %d/%d threads":                                                                              " - тредов: %d/%d",
+	"🔁 Resuming run %s (%d done, %d failed, %d pending)\n":                                          "🔁 Продолжение запуска %s (готово: %d, с ошибкой: %d, в очереди: %d)\n",
+	"📦 Starting run %s (%d channels)\n":                                                             "📦 Запуск %s (каналов: %d)\n",
+	"👷 Parallel channels: %d\n\n":                                                                   "👷 Параллельных каналов: %d\n\n",
+	"\n📊 Run %s: %d done, %d failed, %d pending\n":                                                  "\n📊 Запуск %s: готово: %d, с ошибкой: %d, в очереди: %d\n",
+	"📄 Report: %s\n":                                                                                "📄 Отчёт: %s\n",
+	"⏱️  Duration: %s\n":                                                                            "⏱️  Длительность: %s\n",
+	"🔁 Resume with: slacker export-all --output-dir %s --resume-run %s\n": "🔁 Продолжить: slacker export-all --output-dir %s --resume-run %s\n",
+	"🆕 Created index %s\n":                                                        "🆕 Создан индекс %s\n",
+	"✅ %s: %d documents indexed into %s in %s\n":                                  "✅ %s: проиндексировано документов: %d в %s за %s\n",
+	"🔗 Wrote %d links to %s\n":                                                    "🔗 Записано ссылок: %d в %s\n",
+	"🔄 Collecting links from #%s...\n":                                            "🔄 Сбор ссылок из #%s...\n",
+	"🔄 Finding channel #%s...\n":                                                  "🔄 Поиск канала #%s...\n",
+	"📢 Found channel: #%s (%s)\n":                                                 "📢 Найден канал: #%s (%s)\n",
+	"🔄 Fetching user information...\n":                                            "🔄 Получение данных пользователей...\n",
+	"🔄 Fetching message history (limit: %d)...\n":                                 "🔄 Получение истории сообщений (лимит: %d)...\n",
+	"No messages found in the specified range.\n":                                 "В указанном диапазоне сообщений нет.\n",
+	"🔄 Fetching thread replies...\n":                                              "🔄 Получение ответов в тредах...\n",
+	"🔄 Fetching messages since cursor %s...\n":                                    "🔄 Получение сообщений после курсора %s...\n",
+	"👀 Following #%s, polling every %s (Ctrl+C to stop)...\n":                     "👀 Слежение за #%s, опрос каждые %s (Ctrl+C для остановки)...\n",
+	"Warning: Failed to get replies for message %s: %v\n":                         "Предупреждение: не удалось получить ответы на сообщение %s: %v\n",
+	"\n📝 Found %d messages:\n\n":                                                  "\n📝 Найдено сообщений: %d\n\n",
+	" \033[93m(edited)\033[0m":                                                    " \033[93m(изменено)\033[0m",
+	" (edited)":                                                                   " (изменено)",
+	"%s  💬 %d replies:\n":                                                         "%s  💬 Ответов: %d\n",
+	"📐 Wrote %s schema to %s\n":                                                   "📐 Схема %s записана в %s\n",
+	"🔄 Syncing %d channel(s)...\n":                                                "🔄 Синхронизация каналов: %d...\n",
+	"✅ #%s: %d new, %d stored (%v)\n":                                             "✅ #%s: новых: %d, сохранено: %d (%v)\n",
+	"The local store is empty. Run 'slacker sync --channel <name>' first.\n":      "Локальное хранилище пусто. Сначала выполните 'slacker sync --channel <имя>'.\n",
+	"📦 %d channel(s) in the local store:\n\n":                                     "📦 Каналов в локальном хранилище: %d\n\n",
+	"  #%-24s %8d messages  synced %s\n":                                          "  #%-24s %8d сообщ.  синхронизировано %s\n",
+	"\n📡 Slack API usage: %d calls\n":                                             "\n📡 Вызовы Slack API: %d\n",
+	"   %-24s %5d calls, peak %d/min":                                             "   %-24s вызовов: %5d, пик %d/мин",
+	" (tier %d: %d/min, over the limit)":                                          " (уровень %d: %d/мин, лимит превышен)",
+	" (tier %d: %d/min, %.0f%% headroom)":                                         " (уровень %d: %d/мин, запас %.0f%%)",
+	", %d rate limited":                                                           ", ограничено: %d",
+	"⏳ Waited %s on Slack rate limits and %s on --rate-limit\n":                   "⏳ Ожидание: %s из-за лимитов Slack и %s из-за --rate-limit\n",
+	"🔄 Converted #%s (%d messages) to %s (%s)\n":                                  "🔄 #%s (сообщений: %d) сконвертирован в %s (%s)\n",
+	"📄 Wrote thread from #%s (%d messages) to %s\n":                               "📄 Тред из #%s (сообщений: %d) записан в %s\n",
+	"✅ slacker %s is up to date\n":                                                "✅ slacker %s - последняя версия\n",
+	"⚠️  This build has no release signing key; only the checksum is verified.\n": "⚠️  В этой сборке нет ключа подписи релизов; проверяется только контрольная сумма.\n",
+	"⬇️  Downloading slacker %s for %s/%s...\n":                                   "⬇️  Загрузка slacker %s для %s/%s...\n",
+	"✅ Updated slacker from %s to %s (%s)\n":                                      "✅ slacker обновлён с %s до %s (%s)\n",
+	"🔏 #%s exported %s by slacker %s\n\n":                                         "🔏 #%s экспортирован %s программой slacker %s\n\n",
+	"❌ %s: missing\n":                                                             "❌ %s: отсутствует\n",
+	"❌ %s: checksum mismatch\n":                                                   "❌ %s: контрольная сумма не совпадает\n",
+	"⚠️  The manifest signature was not checked; set %s to check it\n":            "⚠️  Подпись манифеста не проверена; задайте %s, чтобы проверить её\n",
+	"🔏 Manifest signature verified\n":                                             "🔏 Подпись манифеста проверена\n",
+	"\n✅ All %d files verified\n":                                                 "\n✅ Все файлы проверены: %d\n",
+	"✅ %s: %d messages, sha256 %s\n":                                              "✅ %s: сообщений: %d, sha256 %s\n",
+	" (commit %s":                                                                 " (коммит %s",
+	", built %s":                                                                  ", собран %s",
+	"⚠️  A newer version is available: %s (%s)\n":                                 "⚠️  Доступна новая версия: %s (%s)\n",
+	"   Run 'slacker update' to install it.\n":                                    "   Выполните 'slacker update', чтобы установить её.\n",
+	"✅ slacker is up to date\n":                                                   "✅ slacker - последняя версия\n",
+	"Warning: Failed to encode message %s: %v\n":                                  "Предупреждение: не удалось закодировать сообщение %s: %v\n",
+	"👀 Watching #%s via Socket Mode (Ctrl+C to stop)...\n":                        "👀 Слежение за #%s через Socket Mode (Ctrl+C для остановки)...\n",
+	"👀 Watching #%s, polling every %s (Ctrl+C to stop)...\n":                      "👀 Слежение за #%s, опрос каждые %s (Ctrl+C для остановки)...\n",
+	"\n⏸️  Interrupted: saving the messages fetched so far (interrupt again to quit immediately)\n": "\n⏸️  Прервано: сохраняются уже загруженные сообщения (прервите ещё раз для немедленного выхода)\n",
+	"⏸️  Export interrupted after %d messages\n\n":                                                  "⏸️  Экспорт прерван после %d сообщений\n\n",
+	"📁 Partial export: %s\n":                                            "📁 Частичный экспорт: %s\n",
+	"💾 Checkpoint: %s\n":                                                "💾 Контрольная точка: %s\n",
+	"🔁 Resume with: %s\n":                                               "🔁 Продолжить: %s\n",
+	"No export jobs recorded yet.\n":                                    "Задания экспорта ещё не записаны.\n",
+	"📜 %d of %d jobs:\n\n":                                              "📜 Заданий: %d из %d:\n\n",
+	"%s %-17s %-10s %-22s %7d messages %9s  %s\n":                       "%s %-17s %-10s %-22s %7d сообщений %9s  %s\n",
+	"🗂️  Job %s\n":                                                      "🗂️  Задание %s\n",
+	"   Command: %s\n":                                                  "   Команда: %s\n",
+	"   Directory: %s\n":                                                "   Каталог: %s\n",
+	"   Started: %s\n":                                                  "   Начато: %s\n",
+	"   Duration: %s\n":                                                 "   Длительность: %s\n",
+	"   Result: success\n":                                              "   Результат: успешно\n",
+	"   Result: failed (exit code %d): %s\n":                            "   Результат: ошибка (код выхода %d): %s\n",
+	"   Channel: #%s\n":                                                 "   Канал: #%s\n",
+	"   Output: %s\n":                                                   "   Вывод: %s\n",
+	"   Messages: %d\n":                                                 "   Сообщений: %d\n",
+	"   File size: %s\n":                                                "   Размер файла: %s\n",
+	"🔁 Re-running job %s: %s\n\n":                                       "🔁 Повторный запуск задания %s: %s\n\n",
+	"⚠️  %s was not recorded; pass it again after --\n":                 "⚠️  %s не сохраняется в истории; передайте его снова после --\n",
+	"\n💬 Thread Activity:\n":                                            "\n💬 Активность в тредах:\n",
+	"   Answered threads: %d of %d\n":                                   "   Тредов с ответом: %d из %d\n",
+	"   Unanswered messages: %d\n":                                      "   Сообщений без ответа: %d\n",
+	"   Time to first reply: median %s, p90 %s, max %s\n":               "   Время до первого ответа: медиана %s, p90 %s, максимум %s\n",
+	"   Thread duration: median %s, p90 %s, max %s\n":                   "   Длительность треда: медиана %s, p90 %s, максимум %s\n",
+	"   Threads by participants:\n":                                     "   Треды по числу участников:\n",
+	"\n💾 File Storage: %s in %d files\n":                                "\n💾 Хранилище файлов: %s, файлов: %d\n",
+	"   ... %d more types\n":                                            "   ... ещё типов: %d\n",
+	"   %-12s %5d files %10s\n":                                         "   %-12s %5d файлов %10s\n",
+	"\n📤 Top File Sharers:\n":                                           "\n📤 Больше всего файлов:\n",
+	"   %-20s %5d files %10s\n":                                         "   %-20s %5d файлов %10s\n",
+	"📢 #%s, exported %s\n\n":                                            "📢 #%s, экспорт от %s\n\n",
+	"   Mentions: %d users, %d channels, %d @here/@channel/@everyone\n": "   Упоминания: пользователей %d, каналов %d, @here/@channel/@everyone %d\n",
+	"\n📣 Most Mentioned:\n":                                             "\n📣 Чаще всего упоминаются:\n",
+	"\n🔀 Top Mentions:\n":                                               "\n🔀 Кто кого упоминает:\n",
+	"\n🕸️  Wrote the mention graph (%d edges) to %s\n":                  "\n🕸️  Граф упоминаний (связей: %d) записан в %s\n",
+	"🔄 Collecting reactions from #%s...\n":                              "🔄 Собираем реакции из #%s...\n",
+	"🏆 Wrote the reaction leaderboard to %s\n":                          "🏆 Рейтинг реакций записан в %s\n",
+	"🏆 Reactions in %s: %d\n":                                           "🏆 Реакций в %s: %d\n",
+	"\n😀 Top Emoji:\n":                                                  "\n😀 Популярные эмодзи:\n",
+	"   %3d. %-24s %6d  (%d messages, %d users)\n":                      "   %3d. %-24s %6d  (сообщений: %d, пользователей: %d)\n",
+	"\n👏 Most Reactions Given:\n":                                       "\n👏 Чаще всех ставят реакции:\n",
+	"\n🌟 Most Reactions Received:\n":                                    "\n🌟 Больше всех получают реакций:\n",
+	"   %3d. %-24s %6d  (mostly :%s:)\n":                                "   %3d. %-24s %6d  (чаще всего :%s:)\n",
+	"📁 Output directory: %s (%d files)\n":                               "📁 Каталог экспорта: %s (файлов: %d)\n",
+	"👀 Following %d channel(s) via Socket Mode (Ctrl+C to stop)...\n":   "👀 Отслеживание каналов через Socket Mode: %d (Ctrl+C для остановки)...\n",
+	"Warning: Failed to encode event %s: %v\n":                          "Предупреждение: не удалось закодировать событие %s: %v\n",
+	"➕ %s new message %s\n":                                             "➕ %s новое сообщение %s\n",
+	"✏️  %s edited message %s\n":                                        "✏️  %s изменено сообщение %s\n",
+	"🗑️  %s deleted message %s\n":                                       "🗑️  %s удалено сообщение %s\n",
+	"No edited or deleted messages recorded for #%s. Run 'slacker sync --follow' to record them.\n": "Для #%s нет записанных изменённых или удалённых сообщений. Запустите 'slacker sync --follow', чтобы их записывать.\n",
+	"📝 %d revision(s) in #%s:\n\n":                                                  "📝 Ревизий: %d в #%s:\n\n",
+	"👍 %s :%s: added to %s\n":                                                       "👍 %s :%s: добавлена к %s\n",
+	"👎 %s :%s: removed from %s\n":                                                   "👎 %s :%s: убрана с %s\n",
+	"📎 %d file(s) in #%s, %s\n\n":                                                   "📎 Файлов: %d в #%s, %s\n\n",
+	"⬇️  Downloading %d file(s) from #%s to %s...\n":                                "⬇️  Загрузка файлов (%d) из #%s в %s...\n",
+	"  ⏭️  %s: external file, skipped\n":                                            "  ⏭️  %s: внешний файл, пропущен\n",
+	"⏸️  Download interrupted; run the command again to resume\n":                   "⏸️  Загрузка прервана; запустите команду снова, чтобы продолжить\n",
+	"📁 %d downloaded (%s, %d resumed), %d already present, %d skipped, %d failed\n": "📁 Загружено: %d (%s, продолжено: %d), уже были: %d, пропущено: %d, ошибок: %d\n",
+	"📊 Channel comparison since %s\n\n":                                             "📊 Сравнение каналов с %s\n\n",
+	"📊 Channel comparison\n\n":                                                      "📊 Сравнение каналов\n\n",
+	"Messages":                                                                      "Сообщения",
+	"Thread replies":                                                                "Ответы в тредах",
+	"Messages per day":                                                              "Сообщений в день",
+	"Active days":                                                                   "Активные дни",
+	"Active users":                                                                  "Активные участники",
+	"Answered threads":                                                              "Тредов с ответом",
+	"Median first reply":                                                            "Медиана первого ответа",
+	"P90 first reply":                                                               "P90 первого ответа",
+	"\n👥 Participant Overlap:\n":                                                    "\n👥 Пересечение участников:\n",
+	"   #%s ↔ #%s: %d shared (%.0f%%), %d only in #%s, %d only in #%s\n": "   #%s ↔ #%s: общих %d (%.0f%%), %d только в #%s, %d только в #%s\n",
+	"🔄 Collecting messages from #%s...\n":                                "🔄 Сбор сообщений из #%s...\n",
+	"\r🔍 Checked %d of %d channels":                                      "\r🔍 Проверено каналов: %d из %d",
+	"💤 %d of %d channels quiet for more than %s\n\n":                     "💤 %d из %d каналов без сообщений дольше %s\n\n",
+	"  #%-30s %6d days  last %-10s  %4d members\n":                       "  #%-30s %6d дн.  последнее %-10s  участников: %4d\n",
+	"\r👥 Read the members of %d of %d channels":                          "\r👥 Прочитаны участники каналов: %d из %d",
+	"👥 %d users in %d channels\n\n":                                      "👥 Пользователей: %d, каналов: %d\n\n",
+	"📦 All channels":                                                     "📦 Все каналы",
+	"  %d failed":                                                        "  с ошибкой: %d",
+	"⏳ Rate limited by Slack, retrying in %s":                            "⏳ Slack ограничил частоту запросов, повтор через %s",
+	"Initializing export":                                                "Подготовка экспорта",
+	"Fetching channel information":                                       "Получение информации о канале",
+	"Fetching messages":                                                  "Получение сообщений",
+	"Fetching thread replies":                                            "Получение ответов в тредах",
+	"Fetching user information":                                          "Получение информации о пользователях",
+	"Processing data":                                                    "Обработка данных",
+	"Generating output file":                                             "Создание выходного файла",
+	"Export complete":                                                    "Экспорт завершён",
+	"Processing":                                                         "Обработка",
+	"Starting export":                                                    "Запуск экспорта",
+	"Fetching channel messages":                                          "Получение сообщений канала",
+	"Processing and structuring data":                                    "Обработка и структурирование данных",
+	"Export completed successfully":                                      "Экспорт успешно завершён",
+	"Fetched %d messages (%d pages)":                                     "Получено сообщений: %d (страниц: %d)",
+	"Fetched replies for %d/%d threads":                                  "Получены ответы для тредов: %d из %d",
+	"⚠️  WARNING: TLS certificate verification is DISABLED. Slack traffic and tokens can be intercepted.\n": "⚠️  ВНИМАНИЕ: проверка TLS-сертификатов ОТКЛЮЧЕНА. Трафик Slack и токены могут быть перехвачены.\n",
+}
@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Icons used by the TUI and for the reports of long-running commands. CLI
+// messages spell them out in their format strings so that the catalogs stay
+// keyed by readable text.
+const (
+	IconOK          = "✅"
+	IconFailed      = "❌"
+	IconWarning     = "⚠️"
+	IconProgress    = "🔄"
+	IconRateLimited = "⏳"
+	IconChannel     = "📢"
+	IconArchived    = "📦"
+	IconPrivate     = "🔒"
+	IconUser        = "👤"
+	IconBot         = "🤖"
+	IconAttachment  = "📎"
+	IconFile        = "📁"
+	IconReaction    = "👍"
+	IconThread      = "💬"
+	IconExport      = "📤"
+)
+
+var ascii bool
+
+// SetASCII turns the ASCII fallback on or off. With it, output contains only
+// ASCII symbols: status emoji become markers such as [ok], arrows and
+// bullets become ASCII and other emoji are left out.
+func SetASCII(enabled bool) {
+	ascii = enabled
+}
+
+// ASCII reports whether the ASCII fallback is on
+func ASCII() bool {
+	return ascii
+}
+
+// asciiSymbols replaces emoji and symbols in the ASCII fallback. Letters,
+// such as Cyrillic ones, are never replaced.
+var asciiSymbols = strings.NewReplacer(
+	IconOK, "[ok]",
+	IconFailed, "[x]",
+	IconWarning, "[!]",
+	"⚠", "[!]",
+	IconRateLimited, "[wait]",
+	"→", "->",
+	"←", "<-",
+	"↔", "<->",
+	"↳", "\\_",
+	"↑", "^",
+	"↓", "v",
+	"•", "*",
+	"…", "...",
+	"—", "-",
+	"–", "-",
+	"█", "#",
+	"░", ".",
+	"✓", "[ok]",
+)
+
+// Text applies the emoji settings to s without translating it. It is meant
+// for text that is not a catalog message, such as TUI labels.
+func Text(s string) string {
+	switch {
+	case ascii:
+		return ToASCII(s)
+	case !emoji:
+		return StripEmoji(s)
+	}
+	return s
+}
+
+// ToASCII renders s for terminals that only display ASCII symbols: known
+// symbols become ASCII, remaining emoji are removed and any other non-letter
+// symbol becomes "?"
+func ToASCII(s string) string {
+	s = StripEmoji(asciiSymbols.Replace(s))
+	return strings.Map(func(r rune) rune {
+		if r < 0x80 || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsSpace(r) || unicode.IsMark(r) {
+			return r
+		}
+		return '?'
+	}, s)
+}
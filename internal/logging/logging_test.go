@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"", slog.LevelInfo},
+		{"INFO", slog.LevelInfo},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		level, err := ParseLevel(tt.input)
+		if err != nil {
+			t.Errorf("Unexpected error for '%s': %v", tt.input, err)
+		}
+		if level != tt.expected {
+			t.Errorf("Expected %v for '%s', got %v", tt.expected, tt.input, level)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected error for invalid level")
+	}
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", "json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("failed to fetch thread replies", "thread_ts", "1234.5678")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 log line, got %d: %s", len(lines), buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected JSON log line, got %s", lines[0])
+	}
+	if entry["level"] != "WARN" || entry["thread_ts"] != "1234.5678" {
+		t.Errorf("Unexpected log entry: %v", entry)
+	}
+
+	if _, err := New(&buf, "info", "xml"); err == nil {
+		t.Error("Expected error for invalid format")
+	}
+}
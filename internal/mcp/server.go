@@ -0,0 +1,195 @@
+// Package mcp serves slacker's channel, message and export operations over the
+// Model Context Protocol so AI assistants can query Slack history
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// protocolVersion is the MCP revision the server implements. Clients that
+// request a newer supported revision get it echoed back.
+const protocolVersion = "2024-11-05"
+
+// supportedVersions lists the protocol revisions the server accepts
+var supportedVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+// maxMessageSize bounds a single JSON-RPC message read from the client
+const maxMessageSize = 16 << 20
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// request is a JSON-RPC request or, without an ID, a notification
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error member of a JSON-RPC response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server answers MCP requests read line by line from a stdio transport
+type Server struct {
+	tools   *Tools
+	version string
+	logger  *slog.Logger
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewServer creates an MCP server for the given tools
+func NewServer(tools *Tools, version string) *Server {
+	return &Server{
+		tools:   tools,
+		version: version,
+		logger:  slog.Default(),
+	}
+}
+
+// SetLogger sets the logger used for protocol diagnostics. It must not write
+// to the transport's output.
+func (s *Server) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// Serve handles newline-delimited JSON-RPC messages from r and writes the
+// responses to w until r is exhausted or ctx is done
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.out = w
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := s.handle(ctx, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers a single message. Only write failures are returned.
+func (s *Server) handle(ctx context.Context, line []byte) error {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return s.write(response{ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error"}})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return s.write(response{ID: idOrNull(req.ID), Error: &rpcError{Code: codeInvalidRequest, Message: "invalid request"}})
+	}
+
+	s.logger.Debug("mcp request", "method", req.Method)
+	result, rpcErr := s.dispatch(ctx, req)
+
+	// Notifications are not answered
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if rpcErr != nil {
+		return s.write(response{ID: req.ID, Error: rpcErr})
+	}
+	return s.write(response{ID: req.ID, Result: result})
+}
+
+// dispatch runs a method and returns its result
+func (s *Server) dispatch(ctx context.Context, req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		version := protocolVersion
+		if supportedVersions[params.ProtocolVersion] {
+			version = params.ProtocolVersion
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": "slacker", "version": s.version},
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "tools/list":
+		return map[string]interface{}{"tools": s.tools.Definitions()}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "tools/call requires a tool name"}
+		}
+		if !s.tools.Has(params.Name) {
+			return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool '%s'", params.Name)}
+		}
+		return s.tools.Call(ctx, params.Name, params.Arguments), nil
+
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	}
+
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method '%s' not found", req.Method)}
+}
+
+// write sends one response line
+func (s *Server) write(resp response) error {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.out.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+	return nil
+}
+
+// idOrNull returns the request ID, or null when the request had none
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
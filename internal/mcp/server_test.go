@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+)
+
+// mockSource is an in-memory message source with one channel
+type mockSource struct{}
+
+func (mockSource) GetChannels(ctx context.Context) ([]models.Channel, error) {
+	return []models.Channel{
+		{ID: "C0123456789", Name: "general", NumMembers: 3},
+		{ID: "C0987654321", Name: "old", IsArchived: true},
+	}, nil
+}
+
+func (mockSource) GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) (*models.HistoryPage, error) {
+	return models.NewHistoryPage([]models.Message{{Type: "message", User: "U1", Text: "deploy finished", Timestamp: "1704067200.000100"}}, "", false), nil
+}
+
+func (m mockSource) GetChannelHistoryRange(ctx context.Context, channelID string, limit int, cursor, oldest, latest string) (*models.HistoryPage, error) {
+	return m.GetChannelHistory(ctx, channelID, limit, cursor)
+}
+
+func (mockSource) GetThreadReplies(ctx context.Context, channelID, threadTS string) ([]models.Message, error) {
+	return nil, nil
+}
+
+func (mockSource) GetUsers(ctx context.Context) ([]models.User, error) {
+	return []models.User{{ID: "U1", Name: "alice", Profile: models.Profile{DisplayName: "Alice"}}}, nil
+}
+
+func (m mockSource) GetChannelByName(ctx context.Context, name string) (*models.Channel, error) {
+	channels, _ := m.GetChannels(ctx)
+	for _, channel := range channels {
+		if channel.Name == name {
+			return &channel, nil
+		}
+	}
+	return nil, fmt.Errorf("channel '%s' not found", name)
+}
+
+func newTestServer(archiveDir string) *Server {
+	source := mockSource{}
+	tools := NewTools(source, usecase.NewMessageService(source), usecase.NewExportService(source, "1.0.0-test"), archiveDir)
+	return NewServer(tools, "1.0.0-test")
+}
+
+// exchange sends requests to a server and decodes one response per line
+func exchange(t *testing.T, server *Server, requests ...string) []response {
+	t.Helper()
+	var out bytes.Buffer
+	if err := server.Serve(context.Background(), strings.NewReader(strings.Join(requests, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	var responses []response
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp response
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("Invalid response %q: %v", line, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// toolText extracts the text content of a tools/call response
+func toolText(t *testing.T, resp response) (string, bool) {
+	t.Helper()
+	data, _ := json.Marshal(resp.Result)
+	var result ToolResult
+	if err := json.Unmarshal(data, &result); err != nil || len(result.Content) == 0 {
+		t.Fatalf("Expected a tool result, got %s (%v)", data, resp.Error)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func TestServer_InitializeAndListTools(t *testing.T) {
+	responses := exchange(t, newTestServer(t.TempDir()),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`not json`,
+	)
+	if len(responses) != 4 {
+		t.Fatalf("Expected 4 responses (none for the notification), got %d", len(responses))
+	}
+
+	initResult := responses[0].Result.(map[string]interface{})
+	if initResult["protocolVersion"] != "2025-03-26" {
+		t.Errorf("Expected the client's protocol version, got %v", initResult["protocolVersion"])
+	}
+
+	tools := responses[1].Result.(map[string]interface{})["tools"].([]interface{})
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.(map[string]interface{})["name"].(string))
+	}
+	if strings.Join(names, ",") != "list_channels,fetch_messages,search_export,export_channel" {
+		t.Errorf("Unexpected tools %v", names)
+	}
+
+	if responses[2].Error == nil || responses[2].Error.Code != codeMethodNotFound {
+		t.Errorf("Expected method not found, got %+v", responses[2])
+	}
+	if responses[3].Error == nil || responses[3].Error.Code != codeParseError {
+		t.Errorf("Expected parse error, got %+v", responses[3])
+	}
+}
+
+func TestServer_Tools(t *testing.T) {
+	dir := t.TempDir()
+	export := models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C0123456789", Name: "general"},
+		Messages: []models.ExportMessage{
+			{ID: "1704067200.000100", User: "U1", Text: "The Deploy failed", Timestamp: time.Unix(1704067200, 0).UTC()},
+			{ID: "1704067300.000100", User: "U1", Text: "lunch?", Timestamp: time.Unix(1704067300, 0).UTC()},
+		},
+	}
+	data, _ := json.Marshal(export)
+	if err := os.WriteFile(filepath.Join(dir, "general-export.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := exchange(t, newTestServer(dir),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_channels","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"fetch_messages","arguments":{"channel":"#general","limit":5}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"search_export","arguments":{"query":"deploy"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"search_export","arguments":{"query":"deploy","path":"../../etc"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"fetch_messages","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"drop_tables"}}`,
+	)
+	if len(responses) != 6 {
+		t.Fatalf("Expected 6 responses, got %d", len(responses))
+	}
+
+	if text, _ := toolText(t, responses[0]); !strings.Contains(text, `"general"`) || strings.Contains(text, `"old"`) {
+		t.Errorf("Expected only unarchived channels, got %s", text)
+	}
+	if text, _ := toolText(t, responses[1]); !strings.Contains(text, "deploy finished") || !strings.Contains(text, `"user_name": "Alice"`) {
+		t.Errorf("Expected messages with user names, got %s", text)
+	}
+	if text, _ := toolText(t, responses[2]); !strings.Contains(text, "The Deploy failed") || strings.Contains(text, "lunch") {
+		t.Errorf("Expected the case-insensitive hit only, got %s", text)
+	}
+	if _, isError := toolText(t, responses[3]); !isError {
+		t.Error("Expected ../ paths to resolve inside the archive directory")
+	}
+	if text, isError := toolText(t, responses[4]); !isError || !strings.Contains(text, "channel is required") {
+		t.Errorf("Expected a tool error for missing arguments, got %s", text)
+	}
+	if responses[5].Error == nil || responses[5].Error.Code != codeInvalidParams {
+		t.Errorf("Expected unknown tools to be rejected, got %+v", responses[5])
+	}
+}
@@ -0,0 +1,538 @@
+package mcp
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/usecase"
+	"github.com/itcaat/slacker/models"
+)
+
+// Limits for the number of messages and search hits a tool returns
+const (
+	defaultToolLimit = 50
+	maxToolLimit     = 1000
+)
+
+// Tool describes a tool in tools/list
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// ToolResult is the result of tools/call. Failures are reported with IsError
+// so the assistant can see and react to them.
+type ToolResult struct {
+	Content []ToolContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// ToolContent is a content block of a tool result
+type ToolContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Tools implements the slacker tools on top of the message and export
+// services. Export files are read from and written to archiveDir only.
+type Tools struct {
+	source     usecase.MessageClientInterface
+	messages   *usecase.MessageService
+	exports    *usecase.ExportService
+	archiveDir string
+	auditLog   *audit.Log
+	location   *time.Location
+}
+
+// NewTools creates the tools for a message source, which is the Slack API
+// client or the local message store
+func NewTools(source usecase.MessageClientInterface, messages *usecase.MessageService, exports *usecase.ExportService, archiveDir string) *Tools {
+	if archiveDir == "" {
+		archiveDir = "."
+	}
+	return &Tools{
+		source:     source,
+		messages:   messages,
+		exports:    exports,
+		archiveDir: archiveDir,
+	}
+}
+
+// SetAuditLog records the messages fetched and the searches run by the tools
+// in log. Exports are recorded by the export service.
+func (t *Tools) SetAuditLog(log *audit.Log) {
+	t.auditLog = log
+}
+
+// SetLocation sets the zone message times are reported in and dates are read
+// in; nil selects the machine's zone
+func (t *Tools) SetLocation(loc *time.Location) {
+	t.location = loc
+}
+
+// audit writes entry to the audit log and returns err, the outcome of the
+// tool call. A failed write fails a call that succeeded, so its data is not
+// handed out unrecorded.
+func (t *Tools) audit(entry audit.Entry, err error) error {
+	if auditErr := t.auditLog.Record(entry); auditErr != nil && err == nil {
+		return fmt.Errorf("failed to write audit log: %w", auditErr)
+	}
+	return err
+}
+
+// toolHandlers maps tool names to their implementation
+var toolHandlers = map[string]func(t *Tools, ctx context.Context, args json.RawMessage) (interface{}, error){
+	"list_channels":  (*Tools).listChannels,
+	"fetch_messages": (*Tools).fetchMessages,
+	"search_export":  (*Tools).searchExport,
+	"export_channel": (*Tools).exportChannel,
+}
+
+// Definitions returns the tools advertised by tools/list
+func (t *Tools) Definitions() []Tool {
+	return []Tool{
+		{
+			Name:        "list_channels",
+			Description: "List the Slack channels slacker can read, with their IDs, topics and member counts.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"include_archived": property("boolean", "Also list archived channels"),
+			}),
+		},
+		{
+			Name:        "fetch_messages",
+			Description: "Fetch the most recent messages of a channel, newest first, with author names and optionally thread replies.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"channel":         property("string", "Channel name (with or without #) or channel ID"),
+				"limit":           property("integer", fmt.Sprintf("Number of messages (default %d, at most %d)", defaultToolLimit, maxToolLimit)),
+				"include_threads": property("boolean", "Include thread replies"),
+			}, "channel"),
+		},
+		{
+			Name:        "search_export",
+			Description: "Search the messages and thread replies of export files in the archive directory for a text, case-insensitively.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"query": property("string", "Text to search for"),
+				"path":  property("string", "Export file or directory, relative to the archive directory (default: all exports)"),
+				"limit": property("integer", fmt.Sprintf("Maximum number of hits (default %d)", defaultToolLimit)),
+			}, "query"),
+		},
+		{
+			Name:        "export_channel",
+			Description: "Export a channel's history to a JSON file in the archive directory and return a summary with the file path.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"channel":         property("string", "Channel name (with or without #) or channel ID"),
+				"from":            property("string", "Start date (YYYY-MM-DD)"),
+				"to":              property("string", "End date (YYYY-MM-DD)"),
+				"include_threads": property("boolean", "Include thread replies (default true)"),
+			}, "channel"),
+		},
+	}
+}
+
+// Has reports whether name is a known tool
+func (t *Tools) Has(name string) bool {
+	_, ok := toolHandlers[name]
+	return ok
+}
+
+// Call runs a tool and wraps its output, or its error, in a tool result
+func (t *Tools) Call(ctx context.Context, name string, args json.RawMessage) ToolResult {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	output, err := toolHandlers[name](t, ctx, args)
+	if err != nil {
+		return ToolResult{Content: []ToolContent{{Type: "text", Text: err.Error()}}, IsError: true}
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return ToolResult{Content: []ToolContent{{Type: "text", Text: fmt.Sprintf("failed to encode result: %v", err)}}, IsError: true}
+	}
+	return ToolResult{Content: []ToolContent{{Type: "text", Text: string(data)}}}
+}
+
+// channelSummary is a channel as returned by list_channels
+type channelSummary struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsPrivate  bool   `json:"is_private,omitempty"`
+	IsArchived bool   `json:"is_archived,omitempty"`
+	NumMembers int    `json:"num_members,omitempty"`
+	Topic      string `json:"topic,omitempty"`
+	Purpose    string `json:"purpose,omitempty"`
+}
+
+func (t *Tools) listChannels(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		IncludeArchived bool `json:"include_archived"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	channels, err := t.source.GetChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+
+	summaries := make([]channelSummary, 0, len(channels))
+	for _, channel := range channels {
+		if channel.IsArchived && !params.IncludeArchived {
+			continue
+		}
+		summaries = append(summaries, channelSummary{
+			ID:         channel.ID,
+			Name:       channel.Name,
+			IsPrivate:  channel.IsPrivate,
+			IsArchived: channel.IsArchived,
+			NumMembers: channel.NumMembers,
+			Topic:      channel.Topic.Value,
+			Purpose:    channel.Purpose.Value,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries, nil
+}
+
+// messageSummary is a message as returned by fetch_messages
+type messageSummary struct {
+	TS         string           `json:"ts"`
+	Time       string           `json:"time"`
+	User       string           `json:"user,omitempty"`
+	UserName   string           `json:"user_name,omitempty"`
+	Text       string           `json:"text"`
+	Subtype    string           `json:"subtype,omitempty"`
+	ReplyCount int              `json:"reply_count,omitempty"`
+	Replies    []messageSummary `json:"replies,omitempty"`
+}
+
+func (t *Tools) fetchMessages(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Channel        string `json:"channel"`
+		Limit          int    `json:"limit"`
+		IncludeThreads bool   `json:"include_threads"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if params.Channel == "" {
+		return nil, fmt.Errorf("channel is required")
+	}
+
+	opts := usecase.MessageRetrievalOptions{
+		Limit:          clampLimit(params.Limit),
+		IncludeThreads: params.IncludeThreads,
+		IncludeUsers:   true,
+	}
+	if isChannelID(params.Channel) {
+		opts.ChannelID = params.Channel
+	} else {
+		opts.ChannelName = strings.TrimPrefix(params.Channel, "#")
+	}
+	result, err := t.messages.GetChannelMessages(ctx, opts)
+	if err != nil {
+		return nil, t.audit(audit.Entry{Action: audit.ActionMessages, ChannelID: opts.ChannelID, Channel: opts.ChannelName, Error: err.Error()}, err)
+	}
+	if err := t.audit(audit.Entry{
+		Action:    audit.ActionMessages,
+		ChannelID: result.Channel.ID,
+		Channel:   result.Channel.Name,
+		Output:    "mcp",
+		Messages:  len(result.Messages),
+		Success:   true,
+	}, nil); err != nil {
+		return nil, err
+	}
+
+	summarize := func(msg models.Message) messageSummary {
+		summary := messageSummary{
+			TS:         msg.Timestamp,
+			User:       msg.User,
+			UserName:   userName(msg.User, result.Users),
+			Text:       msg.Text,
+			Subtype:    msg.Subtype,
+			ReplyCount: msg.ReplyCount,
+		}
+		if ts, err := models.ParseSlackTimestamp(msg.Timestamp); err == nil {
+			summary.Time = ts.In(models.OrLocal(t.location)).Format(time.RFC3339)
+		}
+		return summary
+	}
+
+	messages := make([]messageSummary, 0, len(result.Messages))
+	for _, msg := range result.Messages {
+		summary := summarize(msg)
+		for _, reply := range msg.Thread {
+			if reply.Timestamp != msg.Timestamp {
+				summary.Replies = append(summary.Replies, summarize(reply))
+			}
+		}
+		messages = append(messages, summary)
+	}
+
+	response := map[string]interface{}{
+		"channel":  result.Channel.Name,
+		"messages": messages,
+	}
+	if len(result.Warnings) > 0 {
+		response["warnings"] = result.Warnings
+	}
+	return response, nil
+}
+
+// searchHit is a matching message as returned by search_export
+type searchHit struct {
+	File      string `json:"file"`
+	Channel   string `json:"channel"`
+	Time      string `json:"time"`
+	User      string `json:"user,omitempty"`
+	Text      string `json:"text"`
+	MessageID string `json:"message_id"`
+	ThreadTS  string `json:"thread_ts,omitempty"`
+	IsReply   bool   `json:"is_reply,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+}
+
+func (t *Tools) searchExport(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	var params struct {
+		Query string `json:"query"`
+		Path  string `json:"path"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(params.Query) == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	limit := clampLimit(params.Limit)
+
+	files, err := t.exportFiles(t.archivePath(params.Path))
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.ToLower(params.Query)
+	hits := []searchHit{}
+	searched := 0
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		export, err := usecase.ReadExportFile(file)
+		if err != nil {
+			continue
+		}
+		searched++
+
+		rel, _ := filepath.Rel(t.archiveDir, file)
+		for _, doc := range usecase.MessageDocuments(export) {
+			if !strings.Contains(strings.ToLower(doc.Text), query) {
+				continue
+			}
+			hits = append(hits, searchHit{
+				File:      rel,
+				Channel:   doc.ChannelName,
+				Time:      doc.Timestamp.Format(time.RFC3339),
+				User:      cmp.Or(doc.UserName, doc.User),
+				Text:      doc.Text,
+				MessageID: doc.MessageID,
+				ThreadTS:  doc.ThreadTS,
+				IsReply:   doc.IsReply,
+				Permalink: doc.Permalink,
+			})
+			if len(hits) >= limit {
+				break
+			}
+		}
+		if len(hits) >= limit {
+			break
+		}
+	}
+
+	if err := t.audit(audit.Entry{
+		Action:   audit.ActionSearch,
+		Query:    params.Query,
+		Output:   "mcp",
+		Messages: len(hits),
+		Success:  true,
+	}, nil); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"files_searched": searched,
+		"hits":           hits,
+	}, nil
+}
+
+// exportFiles lists the JSON export files at path, which is a file or a
+// directory searched recursively. Hidden files and directories are skipped.
+func (t *Tools) exportFiles(path string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if file != path && strings.HasPrefix(name, ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() && (strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")) && !strings.HasSuffix(name, ".manifest.json") {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (t *Tools) exportChannel(ctx context.Context, args json.RawMessage) (interface{}, error) {
+	params := struct {
+		Channel        string `json:"channel"`
+		From           string `json:"from"`
+		To             string `json:"to"`
+		IncludeThreads bool   `json:"include_threads"`
+	}{IncludeThreads: true}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if params.Channel == "" {
+		return nil, fmt.Errorf("channel is required")
+	}
+
+	channel, err := t.resolveChannel(ctx, params.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	options := models.ExportOptions{
+		ChannelID:        channel.ID,
+		ChannelName:      channel.Name,
+		IncludeThreads:   params.IncludeThreads,
+		IncludeFiles:     true,
+		IncludeReactions: true,
+		Format:           "json-pretty",
+		OutputFile:       filepath.Join(t.archiveDir, usecase.ExportFileName(channel.Name, time.Now().Format("20060102-150405"), "json")),
+		Location:         t.location,
+	}
+	if options.DateFrom, err = parseDay(params.From, t.location); err != nil {
+		return nil, err
+	}
+	if options.DateTo, err = parseDay(params.To, t.location); err != nil {
+		return nil, err
+	}
+	if options.DateTo != nil {
+		end := options.DateTo.Add(24*time.Hour - time.Nanosecond)
+		options.DateTo = &end
+	}
+
+	result, err := t.exports.ExportChannel(ctx, options, nil)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"output_file": result.OutputFile,
+		"file_size":   result.FileSize,
+		"messages":    result.Statistics.TotalMessages,
+		"threads":     result.Statistics.TotalThreads,
+		"users":       result.Statistics.TotalUsers,
+		"partial":     result.Partial,
+		"warnings":    result.Warnings,
+	}, nil
+}
+
+// resolveChannel finds a channel by ID or by name
+func (t *Tools) resolveChannel(ctx context.Context, ref string) (*models.Channel, error) {
+	if !isChannelID(ref) {
+		return t.source.GetChannelByName(ctx, strings.TrimPrefix(ref, "#"))
+	}
+	channels, err := t.source.GetChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+	for _, channel := range channels {
+		if channel.ID == ref {
+			return &channel, nil
+		}
+	}
+	return nil, models.NewExportError(models.ErrorCategoryChannelNotFound, fmt.Sprintf("channel with ID '%s' not found", ref), nil)
+}
+
+// archivePath resolves a tool-supplied path inside the archive directory.
+// Paths cannot escape it.
+func (t *Tools) archivePath(path string) string {
+	return filepath.Join(t.archiveDir, filepath.Clean("/"+path))
+}
+
+// isChannelID reports whether ref looks like a Slack channel ID (C…, G… or D…)
+func isChannelID(ref string) bool {
+	if len(ref) < 9 || strings.ToUpper(ref) != ref {
+		return false
+	}
+	return strings.ContainsAny(ref[:1], "CGD")
+}
+
+// parseDay parses an optional YYYY-MM-DD date in loc
+func parseDay(value string, loc *time.Location) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, models.OrLocal(loc))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date '%s', expected YYYY-MM-DD", value)
+	}
+	return &day, nil
+}
+
+// clampLimit applies the default and maximum number of returned items
+func clampLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return defaultToolLimit
+	case limit > maxToolLimit:
+		return maxToolLimit
+	}
+	return limit
+}
+
+// userName returns the display name of a user ID, if known
+func userName(id string, users map[string]models.User) string {
+	user, ok := users[id]
+	if !ok {
+		return ""
+	}
+	return cmp.Or(user.Profile.DisplayName, user.RealName, user.Name)
+}
+
+// objectSchema builds the JSON schema of a tool's arguments
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// property describes a single argument
+func property(kind, description string) map[string]string {
+	return map[string]string{"type": kind, "description": description}
+}
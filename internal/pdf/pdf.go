@@ -0,0 +1,276 @@
+// Package pdf writes paginated text documents using the standard Helvetica
+// fonts. Text is encoded as WinAnsi; a document holding characters outside
+// it cannot be rendered.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxReported caps the unsupported characters listed in an error
+const maxReported = 10
+
+// A4 page size and margins in points
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	margin     = 56.0
+	footerSize = 8.0
+)
+
+// Style controls how a block of text is laid out
+type Style struct {
+	Size   float64 // font size in points (default 10)
+	Bold   bool
+	Muted  bool    // gray text
+	Indent float64 // left indent in points
+	Center bool
+}
+
+// Document is a flowing text document that starts new pages as needed
+type Document struct {
+	title       string
+	pages       []*bytes.Buffer
+	y           float64
+	unsupported map[rune]bool
+}
+
+// New creates a document with one empty page. The title is stored in the
+// document info and printed in every page footer.
+func New(title string) *Document {
+	d := &Document{title: title, unsupported: make(map[rune]bool)}
+	d.AddPage()
+	return d
+}
+
+// AddPage starts a new page
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+// Pages returns the number of pages
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+// Space adds vertical space, starting a new page when it reaches the bottom
+func (d *Document) Space(points float64) {
+	d.y -= points
+	if d.y < margin+footerSize {
+		d.AddPage()
+	}
+}
+
+// Text writes text wrapped to the page width. Newlines start new lines.
+func (d *Document) Text(text string, style Style) {
+	if style.Size == 0 {
+		style.Size = 10
+	}
+	leading := style.Size * 1.3
+	width := pageWidth - 2*margin - style.Indent
+
+	for _, line := range wrap(d.encode(text), width, style) {
+		if d.y-leading < margin+footerSize {
+			d.AddPage()
+		}
+		d.y -= leading
+
+		x := margin + style.Indent
+		if style.Center {
+			x = (pageWidth - textWidth(line, style)) / 2
+		}
+		draw(d.pages[len(d.pages)-1], x, d.y, line, style)
+	}
+}
+
+// Bytes renders the document, adding page numbers to the footers. It fails
+// when text written to the document holds characters the standard fonts
+// cannot show, rather than replacing them.
+func (d *Document) Bytes() ([]byte, error) {
+	title := d.encode(d.title)
+	if len(d.unsupported) > 0 {
+		return nil, d.unsupportedError()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Fixed objects: catalog, page tree, fonts and info; pages follow
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (slacker) >>", escape(title)))
+
+	for i, page := range d.pages {
+		var content bytes.Buffer
+		content.Write(page.Bytes())
+		footer := Style{Size: footerSize, Muted: true}
+		line := fmt.Sprintf("%s - Page %d of %d", title, i+1, len(d.pages))
+		draw(&content, (pageWidth-textWidth(line, footer))/2, margin/2, line, footer)
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes(), nil
+}
+
+// unsupportedError lists the characters that could not be encoded
+func (d *Document) unsupportedError() error {
+	chars := make([]rune, 0, len(d.unsupported))
+	for r := range d.unsupported {
+		chars = append(chars, r)
+	}
+	sort.Slice(chars, func(i, j int) bool { return chars[i] < chars[j] })
+
+	quoted := make([]string, 0, maxReported)
+	for _, r := range chars[:min(len(chars), maxReported)] {
+		quoted = append(quoted, fmt.Sprintf("%q (%U)", r, r))
+	}
+	list := strings.Join(quoted, ", ")
+	if len(chars) > maxReported {
+		list += fmt.Sprintf(" and %d more", len(chars)-maxReported)
+	}
+	return fmt.Errorf("text contains characters the standard PDF fonts cannot show: %s", list)
+}
+
+// draw writes one line of encoded text at the given position
+func draw(buf *bytes.Buffer, x, y float64, line string, style Style) {
+	font := "F1"
+	if style.Bold {
+		font = "F2"
+	}
+	gray := 0.0
+	if style.Muted {
+		gray = 0.45
+	}
+	fmt.Fprintf(buf, "BT /%s %.1f Tf %.2f g %.2f %.2f Td (%s) Tj ET\n", font, style.Size, gray, x, y, escape(line))
+}
+
+// wrap splits encoded text into lines that fit width
+func wrap(text string, width float64, style Style) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Split(paragraph, " ") {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if textWidth(candidate, style) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Break words longer than a line, such as URLs
+			for textWidth(word, style) > width {
+				n := 1
+				for n < len(word) && textWidth(word[:n+1], style) <= width {
+					n++
+				}
+				lines = append(lines, word[:n])
+				word = word[n:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textWidth measures encoded text in points. Bold glyphs are estimated from
+// the regular widths.
+func textWidth(text string, style Style) float64 {
+	size := style.Size
+	if size == 0 {
+		size = 10
+	}
+	units := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c >= 32 && c <= 126 {
+			units += helveticaWidths[c-32]
+		} else {
+			units += 556
+		}
+	}
+	width := float64(units) * size / 1000
+	if style.Bold {
+		width *= 1.08
+	}
+	return width
+}
+
+// winAnsi maps the characters of WinAnsiEncoding that differ from Latin-1
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'‰': 0x89, '‹': 0x8b, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99, '›': 0x9b,
+}
+
+// encode converts text to WinAnsi bytes. Tabs become spaces and other
+// control characters are dropped. Characters outside WinAnsi are left out and
+// recorded, so that Bytes fails instead of printing them wrongly.
+func (d *Document) encode(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			b.WriteByte('\n')
+		case r == '\t':
+			b.WriteString("    ")
+		case r < 32 || r == 127:
+		case r < 127 || (r >= 0xa0 && r <= 0xff):
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsi[r]; ok {
+				b.WriteByte(c)
+			} else {
+				d.unsupported[r] = true
+			}
+		}
+	}
+	return b.String()
+}
+
+// escape quotes an encoded string for a PDF literal
+func escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(text)
+}
+
+// helveticaWidths are the Helvetica glyph widths for ASCII 32-126 in
+// thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
@@ -0,0 +1,83 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocumentBytes(t *testing.T) {
+	doc := New("#general")
+	for i := 0; i < 200; i++ {
+		doc.Text(fmt.Sprintf("Line %d (with parentheses) and a backslash \\", i), Style{})
+	}
+	if doc.Pages() < 2 {
+		t.Fatalf("Expected text to flow onto several pages, got %d", doc.Pages())
+	}
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatal("Expected PDF header and trailer")
+	}
+	if !bytes.Contains(data, []byte(`Line 0 \(with parentheses\) and a backslash \\`)) {
+		t.Error("Expected escaped text in content stream")
+	}
+	if !bytes.Contains(data, []byte(fmt.Sprintf("Page %d of %d", doc.Pages(), doc.Pages()))) {
+		t.Error("Expected page numbers in footer")
+	}
+
+	// Every xref entry must point at the start of its object
+	xref := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(data)
+	start, _ := strconv.Atoi(string(xref[1]))
+	lines := strings.Split(string(data[start:]), "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		offset, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if want := fmt.Sprintf("%d 0 obj", i); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Fatalf("xref entry %d does not point at '%s'", i, want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	style := Style{Size: 10}
+	lines := wrap("short line\n\n"+strings.Repeat("x", 200), 100, style)
+	if lines[0] != "short line" || lines[1] != "" {
+		t.Errorf("Expected paragraphs to be kept, got %q", lines[:2])
+	}
+	for _, line := range lines {
+		if textWidth(line, style) > 100 {
+			t.Errorf("Line %q exceeds the width", line)
+		}
+	}
+	if strings.Join(lines[2:], "") != strings.Repeat("x", 200) {
+		t.Error("Expected long words to be broken without losing characters")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	doc := New("title")
+	if got := doc.encode("café “quoted”\tend"); got != "caf\xe9 \x93quoted\x94    end" {
+		t.Errorf("Unexpected encoding %q", got)
+	}
+	if _, err := doc.Bytes(); err != nil {
+		t.Errorf("Expected WinAnsi text to render, got %v", err)
+	}
+
+	doc.Text("party 🎉 время", Style{})
+	_, err := doc.Bytes()
+	if err == nil {
+		t.Fatal("Expected an error for characters outside WinAnsi")
+	}
+	for _, want := range []string{"U+1F389", "U+0432"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %s in error, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		hasError bool
+	}{
+		{name: "Every minute", expr: "* * * * *"},
+		{name: "Nightly", expr: "0 2 * * *"},
+		{name: "Ranges and steps", expr: "*/15 9-17 * * 1-5"},
+		{name: "Lists", expr: "0 0,12 1,15 * *"},
+		{name: "Macro", expr: "@daily"},
+		{name: "Too few fields", expr: "0 2 * *", hasError: true},
+		{name: "Out of range", expr: "60 * * * *", hasError: true},
+		{name: "Invalid step", expr: "*/0 * * * *", hasError: true},
+		{name: "Garbage", expr: "a b c d e", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if tt.hasError && err == nil {
+				t.Errorf("Expected error for expression '%s', but got none", tt.expr)
+			}
+			if !tt.hasError && err != nil {
+				t.Errorf("Unexpected error for expression '%s': %v", tt.expr, err)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		name     string
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		{
+			name:     "Every minute",
+			expr:     "* * * * *",
+			from:     base,
+			expected: time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC),
+		},
+		{
+			name:     "Nightly at 2am",
+			expr:     "0 2 * * *",
+			from:     base,
+			expected: time.Date(2024, 1, 16, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Every 15 minutes",
+			expr:     "*/15 * * * *",
+			from:     base,
+			expected: time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name:     "Weekly on Sunday",
+			expr:     "@weekly",
+			from:     base,
+			expected: time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Sunday written as 7",
+			expr:     "0 0 * * 7",
+			from:     base,
+			expected: time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "First of month",
+			expr:     "0 0 1 * *",
+			from:     base,
+			expected: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Leap day",
+			expr:     "0 0 29 2 *",
+			from:     base,
+			expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Stepped day of month and day of week",
+			expr:     "0 0 */2 * 1",
+			from:     base,
+			expected: time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Day of month or day of week",
+			expr:     "0 0 20 * 3",
+			from:     base,
+			expected: time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC), // Wednesday comes before the 20th
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			result := s.Next(tt.from)
+			if !result.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Package schema generates JSON Schemas (draft 2020-12) from Go structs,
+// following the encoding/json rules for field names and omitempty.
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	rawType      = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// Schema is a JSON Schema document or subschema
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Types is the "type" keyword: a single type name or a list of them
+type Types []string
+
+// MarshalJSON writes a single type as a string
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// typeOf returns the Types of the named JSON types
+func typeOf(names ...string) Types {
+	return Types(names)
+}
+
+// Generate returns the schema of v's type. Named struct types become $defs
+// referenced by name, so recursive types such as thread replies work.
+func Generate(v interface{}, id, title, description string) *Schema {
+	g := &generator{defs: make(map[string]*Schema)}
+	root := g.schemaFor(reflect.TypeOf(v))
+
+	doc := &Schema{Schema: Draft, ID: id, Title: title, Description: description, Ref: root.Ref}
+	if root.Ref == "" {
+		doc.Type, doc.Properties, doc.Required = root.Type, root.Properties, root.Required
+	}
+	if len(g.defs) > 0 {
+		doc.Defs = g.defs
+	}
+	return doc
+}
+
+// generator collects the definitions of the struct types it meets
+type generator struct {
+	defs map[string]*Schema
+}
+
+// schemaFor returns the schema of values of type t
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: typeOf("string"), Format: "date-time"}
+	case durationType:
+		return &Schema{Type: typeOf("integer"), Description: "Duration in nanoseconds"}
+	case rawType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: typeOf("boolean")}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: typeOf("integer")}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: typeOf("number")}
+	case reflect.String:
+		return &Schema{Type: typeOf("string")}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: typeOf("string"), Format: "byte"}
+		}
+		// encoding/json writes nil slices and maps as null
+		return &Schema{Type: typeOf("array", "null"), Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: typeOf("object", "null"), AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Register first so that recursive references resolve
+			g.defs[t.Name()] = &Schema{}
+			*g.defs[t.Name()] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/$defs/" + t.Name()}
+	}
+	// Interfaces and anything else accept any value
+	return &Schema{}
+}
+
+// structSchema describes the JSON object encoding/json produces for t
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: typeOf("object"), Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the exported fields of t to s, inlining embedded structs
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schemaFor(field.Type)
+		switch {
+		case field.Type.Kind() == reflect.Pointer && !strings.Contains(options, "omitempty"):
+			// nil pointers are written as null
+			property = &Schema{AnyOf: []*Schema{property, {Type: typeOf("null")}}}
+		case !strings.Contains(options, "omitempty"):
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = property
+	}
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testNode struct {
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Parent   *testNode         `json:"parent"`
+	Children []testNode        `json:"children,omitempty"`
+	Labels   map[string]string `json:"labels"`
+	Created  time.Time         `json:"created"`
+	Hidden   string            `json:"-"`
+	internal string
+	testEmbedded
+}
+
+type testEmbedded struct {
+	Count int `json:"count"`
+}
+
+func TestGenerate(t *testing.T) {
+	doc := Generate(testNode{}, "https://example.com/node.json", "Node", "")
+
+	if doc.Schema != Draft || doc.Ref != "#/$defs/testNode" {
+		t.Fatalf("Expected a draft 2020-12 document referencing testNode, got %+v", doc)
+	}
+	node := doc.Defs["testNode"]
+	if node == nil {
+		t.Fatal("Expected a testNode definition")
+	}
+
+	wantRequired := []string{"name", "labels", "created", "count"}
+	if !reflect.DeepEqual(node.Required, wantRequired) {
+		t.Errorf("Expected required %v, got %v", wantRequired, node.Required)
+	}
+	for _, name := range []string{"Hidden", "-", "internal", "testEmbedded"} {
+		if _, ok := node.Properties[name]; ok {
+			t.Errorf("Expected no %q property", name)
+		}
+	}
+
+	if got := node.Properties["created"]; got.Format != "date-time" {
+		t.Errorf("Expected created to be a date-time, got %+v", got)
+	}
+	if got := node.Properties["children"]; got.Items == nil || got.Items.Ref != "#/$defs/testNode" {
+		t.Errorf("Expected children to reference testNode, got %+v", got)
+	}
+	if got := node.Properties["parent"]; len(got.AnyOf) != 2 || got.AnyOf[0].Ref != "#/$defs/testNode" {
+		t.Errorf("Expected parent to be a nullable testNode, got %+v", got)
+	}
+
+	data, err := json.Marshal(node.Properties["labels"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"type":["object","null"],"additionalProperties":{"type":"string"}}` {
+		t.Errorf("Unexpected labels schema: %s", data)
+	}
+}
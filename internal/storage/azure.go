@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// azureAPIVersion is the Blob service REST API version used for requests
+const azureAPIVersion = "2021-08-06"
+
+// azureWriter uploads to Azure Blob Storage as a block blob, staging one block
+// per part and committing the block list on Close
+type azureWriter struct {
+	ctx        context.Context
+	opts       Options
+	account    string
+	accountKey []byte
+	sasToken   string
+	baseURL    string
+	container  string
+	blob       string
+	buf        bytes.Buffer
+	blockIDs   []string
+	closed     bool
+}
+
+// newAzureWriter creates a writer for azblob://container/blob. The storage
+// account and credentials come from AZURE_STORAGE_CONNECTION_STRING or
+// AZURE_STORAGE_ACCOUNT with AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN.
+func newAzureWriter(ctx context.Context, container, blob string, opts Options) (*azureWriter, error) {
+	w := &azureWriter{
+		ctx:       ctx,
+		opts:      opts,
+		account:   os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		sasToken:  strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?"),
+		container: container,
+		blob:      blob,
+	}
+	key := os.Getenv("AZURE_STORAGE_KEY")
+
+	if connStr := os.Getenv("AZURE_STORAGE_CONNECTION_STRING"); connStr != "" {
+		for _, part := range strings.Split(connStr, ";") {
+			name, value, ok := strings.Cut(part, "=")
+			if !ok {
+				continue
+			}
+			switch name {
+			case "AccountName":
+				w.account = value
+			case "AccountKey":
+				key = value
+			case "SharedAccessSignature":
+				w.sasToken = value
+			case "BlobEndpoint":
+				w.baseURL = strings.TrimSuffix(value, "/")
+			}
+		}
+	}
+
+	if w.account == "" {
+		return nil, fmt.Errorf("no Azure storage account configured. Set AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_CONNECTION_STRING")
+	}
+	if key == "" && w.sasToken == "" {
+		return nil, fmt.Errorf("no Azure credentials found. Set AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN")
+	}
+	if key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Azure storage key: %w", err)
+		}
+		w.accountKey = decoded
+	}
+	if w.baseURL == "" {
+		w.baseURL = fmt.Sprintf("https://%s.blob.core.windows.net", w.account)
+	}
+
+	return w, nil
+}
+
+// Write buffers data and stages full blocks as they become available
+func (w *azureWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("write to closed Azure writer")
+	}
+
+	n, _ := w.buf.Write(p)
+	for w.buf.Len() >= w.opts.PartSize {
+		if err := w.putBlock(w.buf.Next(w.opts.PartSize)); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Close stages the remaining data and commits the block list
+func (w *azureWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if w.buf.Len() > 0 || len(w.blockIDs) == 0 {
+		if err := w.putBlock(w.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"BlockList"`
+		Latest  []string `xml:"Latest"`
+	}{Latest: w.blockIDs})
+	if err != nil {
+		return fmt.Errorf("failed to encode block list: %w", err)
+	}
+
+	headers := map[string]string{}
+	if w.opts.KMSKeyID != "" {
+		headers["x-ms-encryption-scope"] = w.opts.KMSKeyID
+	}
+	return w.do(http.MethodPut, url.Values{"comp": {"blocklist"}}, body, headers, "Azure commit block list")
+}
+
+// Abort discards the upload. Uncommitted blocks are garbage collected by Azure.
+func (w *azureWriter) Abort() error {
+	w.closed = true
+	w.blockIDs = nil
+	return nil
+}
+
+func (w *azureWriter) putBlock(data []byte) error {
+	// Block IDs must all have the same length
+	blockID := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("block-%08d", len(w.blockIDs))))
+	query := url.Values{
+		"comp":    {"block"},
+		"blockid": {blockID},
+	}
+
+	headers := map[string]string{}
+	if w.opts.KMSKeyID != "" {
+		headers["x-ms-encryption-scope"] = w.opts.KMSKeyID
+	}
+	if err := w.do(http.MethodPut, query, data, headers, fmt.Sprintf("Azure put block %d", len(w.blockIDs)+1)); err != nil {
+		return err
+	}
+
+	w.blockIDs = append(w.blockIDs, blockID)
+	return nil
+}
+
+// do sends an authorized request for the blob
+func (w *azureWriter) do(method string, query url.Values, body []byte, headers map[string]string, action string) error {
+	rawQuery := query.Encode()
+	if w.sasToken != "" && w.accountKey == nil {
+		rawQuery += "&" + w.sasToken
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/%s?%s", w.baseURL, w.container, escapeBlobPath(w.blob), rawQuery)
+	req, err := http.NewRequestWithContext(w.ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Azure request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	if w.accountKey != nil {
+		req.Header.Set("Authorization", "SharedKey "+w.account+":"+signAzureRequest(req, w.account, w.accountKey))
+	}
+
+	resp, err := w.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, action)
+}
+
+// signAzureRequest computes the Shared Key signature for a Blob service request
+func signAzureRequest(req *http.Request, account string, key []byte) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	// Canonicalized x-ms-* headers, sorted by name
+	var msHeaders []string
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower+":"+strings.TrimSpace(strings.Join(values, ",")))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	// Canonicalized resource: /account/path followed by sorted query parameters
+	resource := "/" + account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date is sent as x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		resource,
+	}, "\n")
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// escapeBlobPath escapes each segment of a blob name
+func escapeBlobPath(blob string) string {
+	segments := strings.Split(blob, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,318 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// gcsChunkAlignment is the granularity required for resumable upload chunks
+const gcsChunkAlignment = 256 * 1024
+
+// gcsWriter uploads to Google Cloud Storage using a resumable upload session
+type gcsWriter struct {
+	ctx        context.Context
+	opts       Options
+	token      string
+	baseURL    string
+	bucket     string
+	object     string
+	sessionURL string
+	buf        bytes.Buffer
+	offset     int64
+	closed     bool
+}
+
+func newGCSWriter(ctx context.Context, bucket, object string, opts Options) (*gcsWriter, error) {
+	token, err := loadGCSToken(ctx, opts.HTTPClient)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := os.Getenv("STORAGE_EMULATOR_HOST")
+	if baseURL == "" {
+		baseURL = "https://storage.googleapis.com"
+	} else if !strings.HasPrefix(baseURL, "http") {
+		baseURL = "http://" + baseURL
+	}
+
+	// Chunks must be a multiple of 256 KiB
+	opts.PartSize = (opts.PartSize + gcsChunkAlignment - 1) / gcsChunkAlignment * gcsChunkAlignment
+
+	return &gcsWriter{
+		ctx:     ctx,
+		opts:    opts,
+		token:   token,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		bucket:  bucket,
+		object:  object,
+	}, nil
+}
+
+// Write buffers data and uploads full chunks as they become available
+func (w *gcsWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("write to closed GCS writer")
+	}
+
+	n, _ := w.buf.Write(p)
+	for w.buf.Len() > w.opts.PartSize {
+		if err := w.uploadChunk(w.buf.Next(w.opts.PartSize), false); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Close uploads the final chunk and finalizes the object
+func (w *gcsWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if err := w.uploadChunk(w.buf.Bytes(), true); err != nil {
+		w.Abort()
+		return err
+	}
+	return nil
+}
+
+// Abort cancels the resumable upload session
+func (w *gcsWriter) Abort() error {
+	w.closed = true
+	if w.sessionURL == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodDelete, w.sessionURL, nil)
+	if err != nil {
+		return err
+	}
+	w.sessionURL = ""
+
+	resp, err := w.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to cancel GCS upload: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// startSession initiates a resumable upload and records the session URL
+func (w *gcsWriter) startSession() error {
+	query := url.Values{
+		"uploadType": {"resumable"},
+		"name":       {w.object},
+	}
+	if w.opts.KMSKeyID != "" {
+		query.Set("kmsKeyName", w.opts.KMSKeyID)
+	}
+
+	endpoint := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", w.baseURL, url.PathEscape(w.bucket), query.Encode())
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create GCS request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+w.token)
+
+	resp, err := w.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GCS start upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp, "GCS start upload"); err != nil {
+		return err
+	}
+
+	w.sessionURL = resp.Header.Get("Location")
+	if w.sessionURL == "" {
+		return fmt.Errorf("GCS start upload returned no session URL")
+	}
+	return nil
+}
+
+// uploadChunk sends a chunk of the object; the final chunk declares the total size
+func (w *gcsWriter) uploadChunk(data []byte, final bool) error {
+	if w.sessionURL == "" {
+		if err := w.startSession(); err != nil {
+			return err
+		}
+	}
+
+	total := "*"
+	if final {
+		total = fmt.Sprintf("%d", w.offset+int64(len(data)))
+	}
+	contentRange := fmt.Sprintf("bytes */%s", total)
+	if len(data) > 0 {
+		contentRange = fmt.Sprintf("bytes %d-%d/%s", w.offset, w.offset+int64(len(data))-1, total)
+	}
+
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPut, w.sessionURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create GCS request: %w", err)
+	}
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Range", contentRange)
+
+	resp, err := w.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GCS upload chunk failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 308 Resume Incomplete acknowledges an intermediate chunk
+	if !(resp.StatusCode == http.StatusPermanentRedirect && !final) {
+		if err := checkResponse(resp, "GCS upload chunk"); err != nil {
+			return err
+		}
+	}
+
+	w.offset += int64(len(data))
+	return nil
+}
+
+// loadGCSToken obtains an OAuth2 access token using the standard Google
+// credential sources: GOOGLE_OAUTH_ACCESS_TOKEN, GOOGLE_APPLICATION_CREDENTIALS,
+// or the gcloud application default credentials file
+func loadGCSToken(ctx context.Context, client *http.Client) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		path = gcloudADCPath()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("no Google credentials found. Set GOOGLE_APPLICATION_CREDENTIALS or run 'gcloud auth application-default login': %w", err)
+	}
+
+	var creds struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKey   string `json:"private_key"`
+		TokenURI     string `json:"token_uri"`
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("failed to parse Google credentials %s: %w", path, err)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	var form url.Values
+	switch creds.Type {
+	case "service_account":
+		assertion, err := signServiceAccountJWT(creds.ClientEmail, creds.PrivateKey, creds.TokenURI, time.Now())
+		if err != nil {
+			return "", err
+		}
+		form = url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}
+	case "authorized_user":
+		form = url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {creds.ClientID},
+			"client_secret": {creds.ClientSecret},
+			"refresh_token": {creds.RefreshToken},
+		}
+	default:
+		return "", fmt.Errorf("unsupported Google credentials type '%s'", creds.Type)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain Google access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp, "Google token exchange"); err != nil {
+		return "", err
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode Google token response: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// gcloudADCPath returns the location of the application default credentials
+// written by 'gcloud auth application-default login'
+func gcloudADCPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// signServiceAccountJWT builds the RS256-signed assertion used to exchange
+// service account credentials for an access token
+func signServiceAccountJWT(email, privateKeyPEM, audience string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return "", fmt.Errorf("invalid service account private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("service account private key is not an RSA key")
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   email,
+		"scope": "https://www.googleapis.com/auth/devstorage.read_write",
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign service account assertion: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,471 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// s3Credentials holds AWS access keys
+type s3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// s3Writer uploads to S3 (or an S3-compatible service) using multipart upload
+// once more than one part of data has been written
+type s3Writer struct {
+	ctx      context.Context
+	opts     Options
+	creds    s3Credentials
+	region   string
+	endpoint *url.URL
+	bucket   string
+	key      string
+	buf      bytes.Buffer
+	uploadID string
+	parts    []s3CompletedPart
+	closed   bool
+}
+
+// s3CompletedPart is an entry of a CompleteMultipartUpload request
+type s3CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func newS3Writer(ctx context.Context, bucket, key string, opts Options) (*s3Writer, error) {
+	creds, err := loadS3Credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	// Custom endpoints (MinIO, localstack, ...) use path-style addressing
+	var endpoint *url.URL
+	if custom := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); custom != "" {
+		endpoint, err = url.Parse(strings.TrimSuffix(custom, "/") + "/" + bucket)
+	} else {
+		endpoint, err = url.Parse(fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+
+	// S3 requires parts of at least 5 MiB (except the last one)
+	if opts.PartSize < 5*1024*1024 && os.Getenv("AWS_ENDPOINT_URL_S3") == "" && os.Getenv("AWS_ENDPOINT_URL") == "" {
+		opts.PartSize = 5 * 1024 * 1024
+	}
+
+	return &s3Writer{
+		ctx:      ctx,
+		opts:     opts,
+		creds:    creds,
+		region:   region,
+		endpoint: endpoint,
+		bucket:   bucket,
+		key:      key,
+	}, nil
+}
+
+// Write buffers data and uploads full parts as they become available
+func (w *s3Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, fmt.Errorf("write to closed S3 writer")
+	}
+
+	n, _ := w.buf.Write(p)
+	for w.buf.Len() >= w.opts.PartSize {
+		if w.uploadID == "" {
+			if err := w.createMultipartUpload(); err != nil {
+				return n, err
+			}
+		}
+		if err := w.uploadPart(w.buf.Next(w.opts.PartSize)); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Close uploads the remaining data and completes the upload
+func (w *s3Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	// Small objects are uploaded in a single request
+	if w.uploadID == "" {
+		req, err := w.newRequest(http.MethodPut, nil, w.buf.Bytes(), w.encryptionHeaders())
+		if err != nil {
+			return err
+		}
+		return w.do(req, "S3 upload", nil)
+	}
+
+	if w.buf.Len() > 0 {
+		if err := w.uploadPart(w.buf.Bytes()); err != nil {
+			w.Abort()
+			return err
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name          `xml:"CompleteMultipartUpload"`
+		Parts   []s3CompletedPart `xml:"Part"`
+	}{Parts: w.parts})
+	if err != nil {
+		return fmt.Errorf("failed to encode multipart completion: %w", err)
+	}
+
+	req, err := w.newRequest(http.MethodPost, url.Values{"uploadId": {w.uploadID}}, body, nil)
+	if err != nil {
+		return err
+	}
+	if err := w.do(req, "S3 complete multipart upload", nil); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return nil
+}
+
+// Abort cancels an in-progress multipart upload
+func (w *s3Writer) Abort() error {
+	w.closed = true
+	if w.uploadID == "" {
+		return nil
+	}
+
+	req, err := w.newRequest(http.MethodDelete, url.Values{"uploadId": {w.uploadID}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	uploadID := w.uploadID
+	w.uploadID = ""
+	if err := w.do(req, "S3 abort multipart upload", nil); err != nil {
+		return fmt.Errorf("failed to abort upload %s: %w", uploadID, err)
+	}
+	return nil
+}
+
+func (w *s3Writer) createMultipartUpload() error {
+	req, err := w.newRequest(http.MethodPost, url.Values{"uploads": {""}}, nil, w.encryptionHeaders())
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := w.do(req, "S3 create multipart upload", &result); err != nil {
+		return err
+	}
+	if result.UploadID == "" {
+		return fmt.Errorf("S3 create multipart upload returned no upload ID")
+	}
+
+	w.uploadID = result.UploadID
+	return nil
+}
+
+func (w *s3Writer) uploadPart(data []byte) error {
+	partNumber := len(w.parts) + 1
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {w.uploadID},
+	}
+
+	req, err := w.newRequest(http.MethodPut, query, data, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3 upload part %d failed: %w", partNumber, err)
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp, fmt.Sprintf("S3 upload part %d", partNumber)); err != nil {
+		return err
+	}
+
+	w.parts = append(w.parts, s3CompletedPart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+	return nil
+}
+
+// encryptionHeaders returns the server-side encryption headers for new objects
+func (w *s3Writer) encryptionHeaders() map[string]string {
+	if w.opts.ServerSideEncryption == "" {
+		return nil
+	}
+
+	headers := map[string]string{"x-amz-server-side-encryption": w.opts.ServerSideEncryption}
+	if w.opts.KMSKeyID != "" {
+		headers["x-amz-server-side-encryption-aws-kms-key-id"] = w.opts.KMSKeyID
+	}
+	return headers
+}
+
+// newRequest builds a signed request for the object
+func (w *s3Writer) newRequest(method string, query url.Values, body []byte, headers map[string]string) (*http.Request, error) {
+	u := *w.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + w.key
+	u.RawPath = s3EscapePath(u.Path)
+	// The query is sent in its canonical form, so the signature covers
+	// exactly what S3 receives
+	u.RawQuery = s3CanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(w.ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	payloadHash := sha256.Sum256(body)
+	signS3Request(req, w.creds, w.region, hex.EncodeToString(payloadHash[:]), time.Now().UTC())
+
+	return req, nil
+}
+
+// do executes a request and optionally decodes an XML response body
+func (w *s3Writer) do(req *http.Request, action string, result interface{}) error {
+	resp, err := w.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, action); err != nil {
+		return err
+	}
+	if result != nil {
+		if err := xml.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("%s: failed to decode response: %w", action, err)
+		}
+	}
+	return nil
+}
+
+// signS3Request adds AWS Signature Version 4 headers to req
+func signS3Request(req *http.Request, creds s3Credentials, region, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", creds.SessionToken)
+	}
+
+	// Canonical headers: host plus every x-amz-*, range and content-type header
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "range" || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// s3CanonicalQuery encodes query parameters as required by SigV4
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EscapePath URI-encodes each path segment
+func s3EscapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return s3Escape(path, false)
+}
+
+// s3Escape percent-encodes everything except unreserved characters (and '/'
+// in paths) as required by SigV4
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// loadS3Credentials reads credentials from the standard AWS environment
+// variables or the shared credentials file
+func loadS3Credentials() (s3Credentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return s3Credentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return s3Credentials{}, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	creds, err := readSharedCredentials(path, profile)
+	if err != nil {
+		return s3Credentials{}, fmt.Errorf("no AWS credentials found. Set AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY or configure %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// readSharedCredentials parses a profile from an AWS credentials INI file
+func readSharedCredentials(path, profile string) (s3Credentials, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return s3Credentials{}, err
+	}
+	defer file.Close()
+
+	var creds s3Credentials
+	inProfile := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inProfile = strings.TrimSpace(line[1:len(line)-1]) == profile
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return s3Credentials{}, err
+	}
+
+	if creds.AccessKeyID == "" {
+		return s3Credentials{}, fmt.Errorf("profile '%s' has no aws_access_key_id", profile)
+	}
+	return creds, nil
+}
+
+// firstEnv returns the value of the first non-empty environment variable
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultPartSize is the amount of data buffered before a part/chunk is uploaded
+const DefaultPartSize = 8 * 1024 * 1024
+
+// Options configures remote uploads
+type Options struct {
+	// ServerSideEncryption selects the encryption mode: "AES256" or "aws:kms" for S3.
+	ServerSideEncryption string
+	// KMSKeyID is the KMS key for S3 aws:kms, the Cloud KMS key name for GCS,
+	// or the encryption scope for Azure.
+	KMSKeyID string
+	// PartSize overrides DefaultPartSize
+	PartSize int
+	// HTTPClient overrides the HTTP client used for uploads
+	HTTPClient *http.Client
+}
+
+// Writer streams data to a destination. Data is committed when Close returns
+// without error; Abort discards anything uploaded so far.
+type Writer interface {
+	io.WriteCloser
+	Abort() error
+}
+
+// remoteSchemes lists the supported object storage URL schemes
+var remoteSchemes = []string{"s3://", "gs://", "azblob://"}
+
+// IsRemote reports whether path refers to an object storage destination
+func IsRemote(path string) bool {
+	for _, scheme := range remoteSchemes {
+		if strings.HasPrefix(path, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Join appends name to a remote destination prefix
+func Join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + name
+}
+
+// NewWriter opens a streaming writer for an s3://, gs:// or azblob:// URL
+func NewWriter(ctx context.Context, rawURL string, opts Options) (Writer, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination '%s': %w", rawURL, err)
+	}
+
+	bucket := u.Host
+	key := strings.TrimPrefix(u.Path, "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return nil, fmt.Errorf("destination '%s' must include a bucket and object name", rawURL)
+	}
+
+	if opts.PartSize <= 0 {
+		opts.PartSize = DefaultPartSize
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: 5 * time.Minute}
+	}
+
+	switch u.Scheme {
+	case "s3":
+		return newS3Writer(ctx, bucket, key, opts)
+	case "gs":
+		return newGCSWriter(ctx, bucket, key, opts)
+	case "azblob":
+		return newAzureWriter(ctx, bucket, key, opts)
+	default:
+		return nil, fmt.Errorf("unsupported destination scheme '%s'", u.Scheme)
+	}
+}
+
+// checkResponse returns an error describing a non-2xx HTTP response
+func checkResponse(resp *http.Response, action string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("%s failed: %s: %s", action, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"slices"
+
+	"github.com/itcaat/slacker/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// AddReaction records that user reacted with the emoji name to the stored
+// message or thread reply ts. It returns false when the message is not
+// stored; the reaction is then left to the next full sync.
+func (s *Store) AddReaction(channelID, ts, name, user string) (bool, error) {
+	return s.updateReactions(channelID, ts, func(message *models.Message) {
+		for i := range message.Reactions {
+			reaction := &message.Reactions[i]
+			if reaction.Name != name {
+				continue
+			}
+			for _, u := range reaction.Users {
+				if u == user {
+					return
+				}
+			}
+			reaction.Users = append(reaction.Users, user)
+			reaction.Count++
+			return
+		}
+		message.Reactions = append(message.Reactions, models.Reaction{Name: name, Users: []string{user}, Count: 1})
+	})
+}
+
+// RemoveReaction removes the reaction name of user from the stored message
+// or thread reply ts, dropping the reaction when nobody is left. The count
+// drops even when user is not listed, since Slack lists only some of the
+// users of popular reactions. It returns false when the message is not
+// stored.
+func (s *Store) RemoveReaction(channelID, ts, name, user string) (bool, error) {
+	return s.updateReactions(channelID, ts, func(message *models.Message) {
+		for i := range message.Reactions {
+			reaction := &message.Reactions[i]
+			if reaction.Name != name {
+				continue
+			}
+			if j := slices.Index(reaction.Users, user); j >= 0 {
+				reaction.Users = slices.Delete(reaction.Users, j, j+1)
+			}
+			reaction.Count--
+			if reaction.Count <= 0 {
+				message.Reactions = append(message.Reactions[:i], message.Reactions[i+1:]...)
+			}
+			return
+		}
+	})
+}
+
+// updateReactions applies update to the stored message ts, which is looked
+// up among the top-level messages and then in the thread index, since
+// reaction events do not name the thread
+func (s *Store) updateReactions(channelID, ts string, update func(*models.Message)) (bool, error) {
+	found := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(messagesBucket).Bucket([]byte(channelID))
+		if bucket == nil {
+			return nil
+		}
+
+		message, err := getMessage(bucket, ts)
+		if err != nil {
+			return err
+		}
+		if message != nil {
+			found = true
+			update(message)
+			return putJSON(bucket, []byte(ts), message)
+		}
+
+		parentTS := threadParent(tx, channelID, ts)
+		if parentTS == "" {
+			return nil
+		}
+		parent, err := getMessage(bucket, parentTS)
+		if err != nil || parent == nil {
+			return err
+		}
+		if i := replyIndex(parent, ts); i >= 0 {
+			found = true
+			update(&parent.Thread[i])
+			return putJSON(bucket, []byte(parentTS), parent)
+		}
+		return nil
+	})
+	return found, err
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestStore_AddAndRemoveReactions(t *testing.T) {
+	st := openTestStore(t)
+	ctx := context.Background()
+	channel := models.Channel{ID: "C1", Name: "general"}
+
+	err := st.SaveMessages(channel, []models.Message{
+		{Timestamp: "1704067200.000100", Text: "first", Reactions: []models.Reaction{{Name: "tada", Users: []string{"U1"}, Count: 1}}},
+		{Timestamp: "1704067300.000100", Text: "question", ThreadTS: "1704067300.000100", ReplyCount: 1,
+			Thread: []models.Message{{Timestamp: "1704067400.000100", ThreadTS: "1704067300.000100", Text: "answer"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, user := range []string{"U2", "U2"} {
+		if found, err := st.AddReaction("C1", "1704067200.000100", "tada", user); err != nil || !found {
+			t.Fatalf("Expected the message to be found, got %v, %v", found, err)
+		}
+	}
+	if found, _ := st.AddReaction("C1", "1704067400.000100", "thumbsup", "U1"); !found {
+		t.Error("Expected the thread reply to be found")
+	}
+	if found, _ := st.AddReaction("C1", "1704067999.000100", "thumbsup", "U1"); found {
+		t.Error("Expected an unknown message not to be found")
+	}
+
+	page, _ := st.GetChannelHistory(ctx, "C1", 0, "")
+	first := page.Messages[1]
+	if len(first.Reactions) != 1 || first.Reactions[0].Count != 2 || len(first.Reactions[0].Users) != 2 {
+		t.Errorf("Expected a second tada, counted once, got %+v", first.Reactions)
+	}
+	replies, _ := st.GetThreadReplies(ctx, "C1", "1704067300.000100")
+	if len(replies) != 1 || len(replies[0].Reactions) != 1 || replies[0].Reactions[0].Name != "thumbsup" {
+		t.Errorf("Expected a thumbsup on the reply, got %+v", replies)
+	}
+
+	for _, user := range []string{"U1", "U2", "U3"} {
+		if _, err := st.RemoveReaction("C1", "1704067200.000100", "tada", user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	page, _ = st.GetChannelHistory(ctx, "C1", 0, "")
+	if len(page.Messages[1].Reactions) != 0 {
+		t.Errorf("Expected the tada to be gone, got %+v", page.Messages[1].Reactions)
+	}
+}
+
+func TestStore_RemoveReactionOfUnlistedUser(t *testing.T) {
+	st := openTestStore(t)
+	channel := models.Channel{ID: "C1", Name: "general"}
+
+	// Slack lists only some users of a popular reaction
+	err := st.SaveMessages(channel, []models.Message{
+		{Timestamp: "1704067200.000100", Reactions: []models.Reaction{{Name: "tada", Users: []string{"U1"}, Count: 3}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.RemoveReaction("C1", "1704067200.000100", "tada", "U9"); err != nil {
+		t.Fatal(err)
+	}
+
+	page, _ := st.GetChannelHistory(context.Background(), "C1", 0, "")
+	if reactions := page.Messages[0].Reactions; len(reactions) != 1 || reactions[0].Count != 2 || len(reactions[0].Users) != 1 {
+		t.Errorf("Expected the count to drop to 2 with U1 still listed, got %+v", reactions)
+	}
+}
+
+func TestStore_ReactionOnIndexedReply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.db")
+	st, err := Open(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel := models.Channel{ID: "C1", Name: "general"}
+	err = st.SaveMessages(channel, []models.Message{
+		{Timestamp: "1704067300.000100", ThreadTS: "1704067300.000100", ReplyCount: 1,
+			Thread: []models.Message{{Timestamp: "1704067400.000100", ThreadTS: "1704067300.000100"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := st.SaveReply("C1", models.Message{Timestamp: "1704067500.000100", ThreadTS: "1704067300.000100"}); err != nil || !saved {
+		t.Fatalf("Expected the reply saved, got %v, %v", saved, err)
+	}
+	if found, _ := st.AddReaction("C1", "1704067500.000100", "eyes", "U1"); !found {
+		t.Error("Expected the saved reply to be found through the thread index")
+	}
+
+	// A store written before the index gets it built when opened
+	if err := st.db.Update(func(tx *bolt.Tx) error { return tx.DeleteBucket(threadsBucket) }); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	if st, err = Open(path, false); err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if found, _ := st.AddReaction("C1", "1704067400.000100", "eyes", "U1"); !found {
+		t.Error("Expected the reply to be found after the index was rebuilt")
+	}
+}
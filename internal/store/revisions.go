@@ -0,0 +1,285 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Kinds of Revision
+const (
+	RevisionEdited  = "edited"
+	RevisionDeleted = "deleted"
+)
+
+// Revision is an earlier version of a stored message, recorded when sync
+// follows a channel's edits and deletions
+type Revision struct {
+	ChannelID string    `json:"channel_id"`
+	TS        string    `json:"ts"`
+	ThreadTS  string    `json:"thread_ts,omitempty"`
+	Kind      string    `json:"kind"`
+	At        time.Time `json:"at"`
+	// Message is the version before the edit or deletion
+	Message models.Message `json:"message"`
+}
+
+// SaveReply adds a new reply to the stored thread of its parent. It returns
+// false when the parent is not stored; the reply is then left to the next
+// full sync.
+func (s *Store) SaveReply(channelID string, reply models.Message) (bool, error) {
+	saved := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(messagesBucket).Bucket([]byte(channelID))
+		if bucket == nil {
+			return nil
+		}
+		parent, err := getMessage(bucket, reply.ThreadTS)
+		if err != nil || parent == nil {
+			return err
+		}
+		if replyIndex(parent, reply.Timestamp) >= 0 {
+			saved = true
+			return nil
+		}
+		parent.Thread = append(parent.Thread, reply)
+		sort.SliceStable(parent.Thread, func(i, j int) bool {
+			return parent.Thread[i].Timestamp < parent.Thread[j].Timestamp
+		})
+		parent.ReplyCount++
+		if reply.Timestamp > parent.LatestReply {
+			parent.LatestReply = reply.Timestamp
+		}
+		saved = true
+		if err := putJSON(bucket, []byte(parent.Timestamp), parent); err != nil {
+			return err
+		}
+		return indexThread(tx, channelID, *parent)
+	})
+	return saved, err
+}
+
+// EditMessage replaces a stored message or thread reply with its edited
+// version. When the text or files changed, the stored version is kept as a
+// revision; previous, the version Slack sent with the edit, stands in for
+// messages that are not stored. It returns whether a revision was recorded.
+func (s *Store) EditMessage(channelID string, message models.Message, previous *models.Message, at time.Time) (bool, error) {
+	recorded := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(messagesBucket).CreateBucketIfNotExists([]byte(channelID))
+		if err != nil {
+			return err
+		}
+
+		if isReply(message) {
+			parent, err := getMessage(bucket, message.ThreadTS)
+			if err != nil {
+				return err
+			}
+			if parent != nil {
+				if i := replyIndex(parent, message.Timestamp); i >= 0 {
+					stored := parent.Thread[i]
+					previous = &stored
+					parent.Thread[i] = message
+					if err := putJSON(bucket, []byte(parent.Timestamp), parent); err != nil {
+						return err
+					}
+				}
+			}
+		} else {
+			stored, err := getMessage(bucket, message.Timestamp)
+			if err != nil {
+				return err
+			}
+			if stored != nil {
+				previous = stored
+				message.Thread = stored.Thread
+				if err := putJSON(bucket, []byte(message.Timestamp), message); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Slack also reports unfurls and reply counts as changes
+		if previous == nil || (previous.Text == message.Text && len(previous.Files) == len(message.Files)) {
+			return nil
+		}
+		recorded = true
+		return putRevision(tx, Revision{
+			ChannelID: channelID,
+			TS:        message.Timestamp,
+			ThreadTS:  threadOf(message),
+			Kind:      RevisionEdited,
+			At:        at,
+			Message:   *previous,
+		})
+	})
+	return recorded, err
+}
+
+// DeleteMessage removes a stored message or thread reply and keeps its last
+// version as a revision; previous stands in for messages that are not
+// stored. It returns whether a revision was recorded.
+func (s *Store) DeleteMessage(channelID, ts, threadTS string, previous *models.Message, at time.Time) (bool, error) {
+	recorded := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(messagesBucket).CreateBucketIfNotExists([]byte(channelID))
+		if err != nil {
+			return err
+		}
+
+		if threadTS != "" && threadTS != ts {
+			parent, err := getMessage(bucket, threadTS)
+			if err != nil {
+				return err
+			}
+			if parent != nil {
+				if i := replyIndex(parent, ts); i >= 0 {
+					stored := parent.Thread[i]
+					previous = &stored
+					parent.Thread = append(parent.Thread[:i], parent.Thread[i+1:]...)
+					if parent.ReplyCount > 0 {
+						parent.ReplyCount--
+					}
+					if err := putJSON(bucket, []byte(parent.Timestamp), parent); err != nil {
+						return err
+					}
+				}
+			}
+		} else {
+			stored, err := getMessage(bucket, ts)
+			if err != nil {
+				return err
+			}
+			if stored != nil {
+				previous = stored
+				if err := bucket.Delete([]byte(ts)); err != nil {
+					return err
+				}
+				if err := countMessages(tx, channelID, -1); err != nil {
+					return err
+				}
+			}
+		}
+
+		if previous == nil {
+			return nil
+		}
+		recorded = true
+		return putRevision(tx, Revision{
+			ChannelID: channelID,
+			TS:        ts,
+			ThreadTS:  threadTS,
+			Kind:      RevisionDeleted,
+			At:        at,
+			Message:   *previous,
+		})
+	})
+	return recorded, err
+}
+
+// Revisions returns the recorded revisions of the message ts in a channel,
+// or of all its messages when ts is empty, oldest message first
+func (s *Store) Revisions(channelID, ts string) ([]Revision, error) {
+	var revisions []Revision
+	err := s.db.View(func(tx *bolt.Tx) error {
+		root := tx.Bucket(revisionsBucket)
+		if root == nil {
+			return nil
+		}
+		bucket := root.Bucket([]byte(channelID))
+		if bucket == nil {
+			return nil
+		}
+		prefix := []byte(ts + "/")
+		c := bucket.Cursor()
+		k, v := c.First()
+		if ts != "" {
+			k, v = c.Seek(prefix)
+		}
+		for ; k != nil && (ts == "" || bytes.HasPrefix(k, prefix)); k, v = c.Next() {
+			var revision Revision
+			if err := json.Unmarshal(v, &revision); err != nil {
+				return err
+			}
+			revisions = append(revisions, revision)
+		}
+		return nil
+	})
+	return revisions, err
+}
+
+// putRevision stores revision below its channel, keyed by message timestamp
+// and revision time
+func putRevision(tx *bolt.Tx, revision Revision) error {
+	root, err := tx.CreateBucketIfNotExists(revisionsBucket)
+	if err != nil {
+		return err
+	}
+	bucket, err := root.CreateBucketIfNotExists([]byte(revision.ChannelID))
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s/%020d", revision.TS, revision.At.UnixNano())
+	return putJSON(bucket, []byte(key), revision)
+}
+
+// countMessages adjusts the stored message count of a channel's sync state
+func countMessages(tx *bolt.Tx, channelID string, delta int) error {
+	data := tx.Bucket(stateBucket).Get([]byte(channelID))
+	if data == nil {
+		return nil
+	}
+	var state SyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	state.Messages += delta
+	if state.Messages < 0 {
+		state.Messages = 0
+	}
+	return putJSON(tx.Bucket(stateBucket), []byte(channelID), state)
+}
+
+// getMessage returns the stored top-level message ts, or nil
+func getMessage(bucket *bolt.Bucket, ts string) (*models.Message, error) {
+	data := bucket.Get([]byte(ts))
+	if data == nil {
+		return nil, nil
+	}
+	var message models.Message
+	if err := json.Unmarshal(data, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// replyIndex returns the index of the reply ts in the thread of parent, or -1
+func replyIndex(parent *models.Message, ts string) int {
+	for i, reply := range parent.Thread {
+		if reply.Timestamp == ts {
+			return i
+		}
+	}
+	return -1
+}
+
+// isReply reports whether message is a thread reply rather than a top-level
+// message or thread parent
+func isReply(message models.Message) bool {
+	return message.ThreadTS != "" && message.ThreadTS != message.Timestamp
+}
+
+// threadOf returns the thread timestamp of a reply, or "" for top-level
+// messages
+func threadOf(message models.Message) string {
+	if isReply(message) {
+		return message.ThreadTS
+	}
+	return ""
+}
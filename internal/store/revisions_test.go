@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestStore_EditAndDeleteMessages(t *testing.T) {
+	st := openTestStore(t)
+	ctx := context.Background()
+	channel := models.Channel{ID: "C1", Name: "general"}
+	at := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	err := st.SaveMessages(channel, []models.Message{
+		{Timestamp: "1704067200.000100", Text: "first"},
+		{Timestamp: "1704067300.000100", Text: "question", ThreadTS: "1704067300.000100", ReplyCount: 1,
+			Thread: []models.Message{{Timestamp: "1704067400.000100", ThreadTS: "1704067300.000100", Text: "answer"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// New replies join their thread; replies to unknown threads are skipped
+	saved, err := st.SaveReply("C1", models.Message{Timestamp: "1704067450.000100", ThreadTS: "1704067300.000100", Text: "thanks"})
+	if err != nil || !saved {
+		t.Fatalf("Expected the reply to be saved, got %v, %v", saved, err)
+	}
+	if saved, _ := st.SaveReply("C1", models.Message{Timestamp: "1704067460.000100", ThreadTS: "1704060000.000100"}); saved {
+		t.Error("Expected a reply to an unknown thread not to be saved")
+	}
+
+	recorded, err := st.EditMessage("C1", models.Message{Timestamp: "1704067300.000100", ThreadTS: "1704067300.000100", Text: "question (edited)", ReplyCount: 2}, nil, at)
+	if err != nil || !recorded {
+		t.Fatalf("Expected an edit revision, got %v, %v", recorded, err)
+	}
+	// Reply count updates are not revisions
+	if recorded, _ := st.EditMessage("C1", models.Message{Timestamp: "1704067300.000100", ThreadTS: "1704067300.000100", Text: "question (edited)", ReplyCount: 2}, nil, at); recorded {
+		t.Error("Expected no revision for an unchanged text")
+	}
+	if _, err := st.EditMessage("C1", models.Message{Timestamp: "1704067400.000100", ThreadTS: "1704067300.000100", Text: "better answer"}, nil, at.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	replies, err := st.GetThreadReplies(ctx, "C1", "1704067300.000100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 2 || replies[0].Text != "better answer" || replies[1].Text != "thanks" {
+		t.Errorf("Expected the edited thread to be kept, got %v", replies)
+	}
+
+	if _, err := st.DeleteMessage("C1", "1704067200.000100", "", nil, at.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.DeleteMessage("C1", "1704067450.000100", "1704067300.000100", nil, at.Add(3*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	// Messages that were never stored are recorded from the version Slack sends
+	if recorded, _ := st.DeleteMessage("C1", "1704067600.000100", "", &models.Message{Timestamp: "1704067600.000100", Text: "late"}, at); !recorded {
+		t.Error("Expected the previous version of an unstored message to be recorded")
+	}
+
+	state, _ := st.State("C1")
+	if state.Messages != 1 {
+		t.Errorf("Expected 1 stored message after the deletion, got %d", state.Messages)
+	}
+	page, _ := st.GetChannelHistory(ctx, "C1", 0, "")
+	if len(page.Messages) != 1 || page.Messages[0].ReplyCount != 1 || len(page.Messages[0].Thread) != 1 {
+		t.Errorf("Expected the thread parent with one reply left, got %v", page.Messages)
+	}
+
+	revisions, err := st.Revisions("C1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ ts, kind, text string }{
+		{"1704067200.000100", RevisionDeleted, "first"},
+		{"1704067300.000100", RevisionEdited, "question"},
+		{"1704067400.000100", RevisionEdited, "answer"},
+		{"1704067450.000100", RevisionDeleted, "thanks"},
+		{"1704067600.000100", RevisionDeleted, "late"},
+	}
+	if len(revisions) != len(want) {
+		t.Fatalf("Expected %d revisions, got %v", len(want), revisions)
+	}
+	for i, w := range want {
+		if revisions[i].TS != w.ts || revisions[i].Kind != w.kind || revisions[i].Message.Text != w.text {
+			t.Errorf("Expected %v at %d, got %+v", w, i, revisions[i])
+		}
+	}
+	if revisions[1].ThreadTS != "" || revisions[2].ThreadTS != "1704067300.000100" {
+		t.Errorf("Expected only replies to carry a thread, got %q and %q", revisions[1].ThreadTS, revisions[2].ThreadTS)
+	}
+
+	revisions, _ = st.Revisions("C1", "1704067300.000100")
+	if len(revisions) != 1 || revisions[0].Message.Text != "question" {
+		t.Errorf("Expected the revision of one message, got %v", revisions)
+	}
+}
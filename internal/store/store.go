@@ -0,0 +1,403 @@
+// Package store keeps a local copy of Slack channel history in a BoltDB file
+// so that exports and the TUI can work without calling the Slack API.
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// FileName is the default store file below ~/.slacker
+const FileName = ".slacker/messages.db"
+
+// Top-level buckets. Messages live in one nested bucket per channel below
+// messagesBucket, keyed by Slack timestamp so keys sort chronologically.
+// Earlier versions of edited and deleted messages live below revisionsBucket
+// in the same way, keyed by message timestamp and revision time. Below
+// threadsBucket, the timestamps of thread replies map to that of their
+// parent, since reaction events do not name the thread.
+var (
+	channelsBucket  = []byte("channels")
+	stateBucket     = []byte("sync_state")
+	usersBucket     = []byte("users")
+	messagesBucket  = []byte("messages")
+	revisionsBucket = []byte("revisions")
+	threadsBucket   = []byte("threads")
+)
+
+// ErrLocked is returned when another slacker process has the store open
+var ErrLocked = errors.New("message store is in use by another slacker process")
+
+// SyncState records the progress of a channel's sync
+type SyncState struct {
+	ChannelID string    `json:"channel_id"`
+	Channel   string    `json:"channel"`
+	LatestTS  string    `json:"latest_ts"`
+	Messages  int       `json:"messages"`
+	SyncedAt  time.Time `json:"synced_at"`
+}
+
+// Store is a local message store backed by BoltDB
+type Store struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns ~/.slacker/messages.db
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, FileName), nil
+}
+
+// Open opens or creates the store at path. Read-only stores can be shared by
+// several processes; a writable store is exclusive.
+func Open(path string, readOnly bool) (*Store, error) {
+	if readOnly {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, fmt.Errorf("no local message store at %s. Run 'slacker sync' first", path)
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: readOnly})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("failed to open message store: %w", err)
+	}
+
+	if !readOnly {
+		err = db.Update(func(tx *bolt.Tx) error {
+			for _, name := range [][]byte{channelsBucket, stateBucket, usersBucket, messagesBucket, revisionsBucket} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+			// Stores written before the thread index get it built once
+			if tx.Bucket(threadsBucket) == nil {
+				return indexAllThreads(tx)
+			}
+			return nil
+		})
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize message store: %w", err)
+		}
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Path returns the file of the store
+func (s *Store) Path() string {
+	return s.db.Path()
+}
+
+// SaveChannel stores channel metadata
+func (s *Store) SaveChannel(channel models.Channel) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(channelsBucket), []byte(channel.ID), channel)
+	})
+}
+
+// SaveUsers replaces the stored user list
+func (s *Store) SaveUsers(users []models.User) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(usersBucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		bucket, err := tx.CreateBucket(usersBucket)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if err := putJSON(bucket, []byte(user.ID), user); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SaveMessages inserts or replaces messages of a channel and updates its sync
+// state. Existing messages with the same timestamp are overwritten.
+func (s *Store) SaveMessages(channel models.Channel, messages []models.Message) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(messagesBucket).CreateBucketIfNotExists([]byte(channel.ID))
+		if err != nil {
+			return err
+		}
+
+		state := SyncState{ChannelID: channel.ID}
+		if data := tx.Bucket(stateBucket).Get([]byte(channel.ID)); data != nil {
+			if err := json.Unmarshal(data, &state); err != nil {
+				return err
+			}
+		}
+
+		for _, message := range messages {
+			key := []byte(message.Timestamp)
+			if bucket.Get(key) == nil {
+				state.Messages++
+			}
+			if err := putJSON(bucket, key, message); err != nil {
+				return err
+			}
+			if err := indexThread(tx, channel.ID, message); err != nil {
+				return err
+			}
+		}
+
+		state.Channel = channel.Name
+		state.SyncedAt = time.Now()
+		if last, _ := bucket.Cursor().Last(); last != nil {
+			state.LatestTS = string(last)
+		}
+		return putJSON(tx.Bucket(stateBucket), []byte(channel.ID), state)
+	})
+}
+
+// State returns the sync state of a channel, or nil if it was never synced
+func (s *Store) State(channelID string) (*SyncState, error) {
+	var state *SyncState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(stateBucket).Get([]byte(channelID))
+		if data == nil {
+			return nil
+		}
+		state = &SyncState{}
+		return json.Unmarshal(data, state)
+	})
+	return state, err
+}
+
+// States returns the sync state of every synced channel
+func (s *Store) States() ([]SyncState, error) {
+	var states []SyncState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(stateBucket).ForEach(func(_, data []byte) error {
+			var state SyncState
+			if err := json.Unmarshal(data, &state); err != nil {
+				return err
+			}
+			states = append(states, state)
+			return nil
+		})
+	})
+	return states, err
+}
+
+// GetChannels returns the stored channels
+func (s *Store) GetChannels(ctx context.Context) ([]models.Channel, error) {
+	var channels []models.Channel
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(channelsBucket).ForEach(func(_, data []byte) error {
+			var channel models.Channel
+			if err := json.Unmarshal(data, &channel); err != nil {
+				return err
+			}
+			channels = append(channels, channel)
+			return nil
+		})
+	})
+	return channels, err
+}
+
+// GetChannelByName finds a stored channel by name, with or without a
+// leading "#" and ignoring case
+func (s *Store) GetChannelByName(ctx context.Context, channelName string) (*models.Channel, error) {
+	channels, err := s.GetChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if channel := models.FindChannel(channels, channelName); channel != nil {
+		return channel, nil
+	}
+	name := strings.TrimPrefix(channelName, "#")
+	return nil, models.NewChannelNotFoundError(channels, name, fmt.Sprintf("Run 'slacker sync --channel %s' to add it to the local store", name))
+}
+
+// GetChannelHistory returns stored messages newest first, like the Slack
+// history API. The cursor is the timestamp of the last message returned.
+func (s *Store) GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) (*models.HistoryPage, error) {
+	return s.GetChannelHistoryRange(ctx, channelID, limit, cursor, "", "")
+}
+
+// GetChannelHistoryRange returns the stored messages between the timestamps
+// oldest and latest (inclusive; empty = open) newest first
+func (s *Store) GetChannelHistoryRange(ctx context.Context, channelID string, limit int, cursor, oldest, latest string) (*models.HistoryPage, error) {
+	start := time.Now()
+	var messages []models.Message
+	nextCursor := ""
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(messagesBucket).Bucket([]byte(channelID))
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		var k, v []byte
+		if cursor == "" {
+			k, v = c.Last()
+		} else {
+			// Position at the cursor and step past it
+			k, v = c.Seek([]byte(cursor))
+			if k == nil {
+				k, v = c.Last()
+			}
+			for k != nil && bytes.Compare(k, []byte(cursor)) >= 0 {
+				k, v = c.Prev()
+			}
+		}
+
+		for ; k != nil; k, v = c.Prev() {
+			if latest != "" && bytes.Compare(k, []byte(latest)) > 0 {
+				continue
+			}
+			if oldest != "" && bytes.Compare(k, []byte(oldest)) < 0 {
+				break
+			}
+			if limit > 0 && len(messages) == limit {
+				nextCursor = messages[len(messages)-1].Timestamp
+				break
+			}
+			var message models.Message
+			if err := json.Unmarshal(v, &message); err != nil {
+				return err
+			}
+			messages = append(messages, message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	page := models.NewHistoryPage(messages, nextCursor, nextCursor != "")
+	page.Latency = time.Since(start)
+	return page, nil
+}
+
+// GetThreadReplies returns the stored replies of a thread, without the parent
+func (s *Store) GetThreadReplies(ctx context.Context, channelID, threadTS string) ([]models.Message, error) {
+	var replies []models.Message
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(messagesBucket).Bucket([]byte(channelID))
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get([]byte(threadTS))
+		if data == nil {
+			return nil
+		}
+		var parent models.Message
+		if err := json.Unmarshal(data, &parent); err != nil {
+			return err
+		}
+		replies = parent.Thread
+		return nil
+	})
+	return replies, err
+}
+
+// GetUsers returns the stored users
+func (s *Store) GetUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(_, data []byte) error {
+			var user models.User
+			if err := json.Unmarshal(data, &user); err != nil {
+				return err
+			}
+			users = append(users, user)
+			return nil
+		})
+	})
+	return users, err
+}
+
+// indexThread records the replies of parent in the thread index of its
+// channel
+func indexThread(tx *bolt.Tx, channelID string, parent models.Message) error {
+	if len(parent.Thread) == 0 {
+		return nil
+	}
+	root, err := tx.CreateBucketIfNotExists(threadsBucket)
+	if err != nil {
+		return err
+	}
+	bucket, err := root.CreateBucketIfNotExists([]byte(channelID))
+	if err != nil {
+		return err
+	}
+	for _, reply := range parent.Thread {
+		if err := bucket.Put([]byte(reply.Timestamp), []byte(parent.Timestamp)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexAllThreads builds the thread index from every stored message
+func indexAllThreads(tx *bolt.Tx) error {
+	if _, err := tx.CreateBucketIfNotExists(threadsBucket); err != nil {
+		return err
+	}
+	channels := tx.Bucket(messagesBucket)
+	return channels.ForEach(func(channelID, _ []byte) error {
+		bucket := channels.Bucket(channelID)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, data []byte) error {
+			var message models.Message
+			if err := json.Unmarshal(data, &message); err != nil {
+				return err
+			}
+			return indexThread(tx, string(channelID), message)
+		})
+	})
+}
+
+// threadParent returns the timestamp of the parent of the thread reply ts,
+// or "" when the reply is not indexed. Entries of deleted replies are kept,
+// so callers check that the parent still holds the reply.
+func threadParent(tx *bolt.Tx, channelID, ts string) string {
+	root := tx.Bucket(threadsBucket)
+	if root == nil {
+		return ""
+	}
+	bucket := root.Bucket([]byte(channelID))
+	if bucket == nil {
+		return ""
+	}
+	return string(bucket.Get([]byte(ts)))
+}
+
+// putJSON stores v as JSON under key
+func putJSON(bucket *bolt.Bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(key, data)
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	st, err := Open(filepath.Join(t.TempDir(), "messages.db"), false)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func TestStore_SaveMessagesAndHistory(t *testing.T) {
+	st := openTestStore(t)
+	ctx := context.Background()
+	channel := models.Channel{ID: "C1", Name: "general"}
+
+	if err := st.SaveChannel(channel); err != nil {
+		t.Fatal(err)
+	}
+	err := st.SaveMessages(channel, []models.Message{
+		{Timestamp: "1704067200.000100", Text: "first"},
+		{Timestamp: "1704067300.000100", Text: "second", ThreadTS: "1704067300.000100", ReplyCount: 1,
+			Thread: []models.Message{{Timestamp: "1704067400.000100", Text: "reply"}}},
+		{Timestamp: "1704067500.000100", Text: "third"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	state, err := st.State("C1")
+	if err != nil || state == nil {
+		t.Fatalf("Expected sync state, got %v (%v)", state, err)
+	}
+	if state.LatestTS != "1704067500.000100" || state.Messages != 3 {
+		t.Errorf("Unexpected sync state %+v", state)
+	}
+
+	// Page through newest first
+	page, err := st.GetChannelHistory(ctx, "C1", 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Messages) != 2 || page.Messages[0].Text != "third" || page.Messages[1].Text != "second" || !page.HasMore {
+		t.Fatalf("Unexpected first page %+v", page)
+	}
+	if page.Oldest != page.Messages[1].Timestamp || page.Latest != page.Messages[0].Timestamp {
+		t.Errorf("Expected page boundaries, got %s-%s", page.Oldest, page.Latest)
+	}
+	page, err = st.GetChannelHistory(ctx, "C1", 2, page.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Messages) != 1 || page.Messages[0].Text != "first" || !page.Last() {
+		t.Fatalf("Unexpected second page %+v", page)
+	}
+
+	replies, err := st.GetThreadReplies(ctx, "C1", "1704067300.000100")
+	if err != nil || len(replies) != 1 || replies[0].Text != "reply" {
+		t.Errorf("Expected stored reply, got %v (%v)", replies, err)
+	}
+
+	found, err := st.GetChannelByName(ctx, "general")
+	if err != nil || found.ID != "C1" {
+		t.Errorf("Expected channel C1, got %v (%v)", found, err)
+	}
+	if _, err := st.GetChannelByName(ctx, "random"); models.ErrorCategoryOf(err) != models.ErrorCategoryChannelNotFound {
+		t.Errorf("Expected channel_not_found, got %v", err)
+	}
+}
+
+func TestStore_GetChannelHistoryRange(t *testing.T) {
+	st := openTestStore(t)
+	channel := models.Channel{ID: "C1", Name: "general"}
+	st.SaveMessages(channel, []models.Message{
+		{Timestamp: "1704067200.000100", Text: "first"},
+		{Timestamp: "1704067300.000100", Text: "second"},
+		{Timestamp: "1704067400.000100", Text: "third"},
+		{Timestamp: "1704067500.000100", Text: "fourth"},
+	})
+
+	page, err := st.GetChannelHistoryRange(context.Background(), "C1", 0, "", "1704067300.000100", "1704067400.000100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := page.Messages
+	if len(messages) != 2 || messages[0].Text != "third" || messages[1].Text != "second" || page.HasMore {
+		t.Errorf("Expected third and second, got %v (has_more %v)", messages, page.HasMore)
+	}
+}
+
+func TestStore_SaveMessagesReplaces(t *testing.T) {
+	st := openTestStore(t)
+	channel := models.Channel{ID: "C1", Name: "general"}
+
+	st.SaveMessages(channel, []models.Message{{Timestamp: "1704067200.000100", Text: "draft"}})
+	st.SaveMessages(channel, []models.Message{{Timestamp: "1704067200.000100", Text: "edited"}})
+
+	page, err := st.GetChannelHistory(context.Background(), "C1", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Messages) != 1 || page.Messages[0].Text != "edited" {
+		t.Errorf("Expected the message to be replaced, got %v", page.Messages)
+	}
+}
+
+func TestStore_Users(t *testing.T) {
+	st := openTestStore(t)
+	st.SaveUsers([]models.User{{ID: "U1", Name: "alice"}, {ID: "U2", Name: "bob"}})
+	st.SaveUsers([]models.User{{ID: "U1", Name: "alice"}})
+
+	users, err := st.GetUsers(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 {
+		t.Errorf("Expected the user list to be replaced, got %v", users)
+	}
+}
+
+func TestOpen_ReadOnlyMissing(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.db"), true); err == nil {
+		t.Error("Expected error opening a missing store read-only")
+	}
+}
@@ -0,0 +1,248 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric types in the Prometheus text exposition format
+const (
+	typeCounter = "counter"
+	typeGauge   = "gauge"
+	typeSummary = "summary"
+)
+
+// Registry holds metric families and renders them in the Prometheus text format
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+	logger   *slog.Logger
+}
+
+type family struct {
+	mu         *sync.Mutex
+	registry   *Registry
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	series     map[string]*series
+	// mismatched is set once an update with the wrong label count was logged
+	mismatched bool
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	sum         float64
+	count       uint64
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family), logger: slog.Default()}
+}
+
+// SetLogger sets the logger told about dropped updates
+func (r *Registry) SetLogger(logger *slog.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logger = logger
+}
+
+// Counter is a monotonically increasing metric
+type Counter struct{ f *family }
+
+// Gauge is a metric that can be set to arbitrary values
+type Gauge struct{ f *family }
+
+// Summary tracks the count and sum of observations
+type Summary struct{ f *family }
+
+// register returns the family with the given name, creating it if needed
+func (r *Registry) register(name, help, kind string, labelNames []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		return f
+	}
+	f := &family{
+		mu:         &r.mu,
+		registry:   r,
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		series:     make(map[string]*series),
+	}
+	r.families[name] = f
+	return f
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{f: r.register(name, help, typeCounter, labelNames)}
+}
+
+// NewGauge registers a gauge with the given label names
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{f: r.register(name, help, typeGauge, labelNames)}
+}
+
+// NewSummary registers a summary with the given label names
+func (r *Registry) NewSummary(name, help string, labelNames ...string) *Summary {
+	return &Summary{f: r.register(name, help, typeSummary, labelNames)}
+}
+
+// Add increases the counter for the given label values
+func (c *Counter) Add(value float64, labelValues ...string) {
+	c.f.update(labelValues, func(s *series) { s.value += value })
+}
+
+// Inc increases the counter by one for the given label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Set sets the gauge for the given label values
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.f.update(labelValues, func(s *series) { s.value = value })
+}
+
+// Observe records an observation for the given label values
+func (s *Summary) Observe(value float64, labelValues ...string) {
+	s.f.update(labelValues, func(s *series) {
+		s.sum += value
+		s.count++
+	})
+}
+
+// update applies fn to the series of labelValues. An update with the wrong
+// number of label values is a bug in the caller; it is dropped and logged
+// once per metric rather than taking the export down with it.
+func (f *family) update(labelValues []string, fn func(*series)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(labelValues) != len(f.labelNames) {
+		if !f.mismatched {
+			f.mismatched = true
+			f.registry.logger.Error("dropped metric update with wrong label values",
+				"metric", f.name, "expected", len(f.labelNames), "got", len(labelValues))
+		}
+		return
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		f.series[key] = s
+	}
+	fn(s)
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s := f.series[key]
+			labels := formatLabels(f.labelNames, s.labelValues)
+			if f.kind == typeSummary {
+				fmt.Fprintf(&b, "%s_sum%s %s\n", f.name, labels, formatValue(s.sum))
+				fmt.Fprintf(&b, "%s_count%s %d\n", f.name, labels, s.count)
+				continue
+			}
+			fmt.Fprintf(&b, "%s%s %s\n", f.name, labels, formatValue(s.value))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler returns an HTTP handler serving the metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// Push replaces the metrics of job on a Prometheus Pushgateway, connecting
+// through transport (http.DefaultTransport when nil)
+func (r *Registry) Push(ctx context.Context, gatewayURL, job string, transport http.RoundTripper) error {
+	var body bytes.Buffer
+	if err := r.WriteText(&body); err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(gatewayURL, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create pushgateway request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: 30 * time.Second, Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pushgateway returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%q", name, values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
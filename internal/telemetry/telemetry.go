@@ -0,0 +1,73 @@
+// Package telemetry provides Prometheus metrics and OpenTelemetry tracing for
+// slacker's export pipeline and Slack API calls.
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Default is the registry holding slacker's built-in metrics
+var Default = NewRegistry()
+
+// Built-in metrics
+var (
+	ExportsTotal     = Default.NewCounter("slacker_exports_total", "Channel exports by outcome.", "status")
+	ExportDuration   = Default.NewSummary("slacker_export_duration_seconds", "Total duration of channel exports.")
+	ExportedMessages = Default.NewCounter("slacker_exported_messages_total", "Messages written to exports.")
+	LastExportTime   = Default.NewGauge("slacker_last_export_success_timestamp_seconds", "Unix time of the last successful export.")
+	StageDuration    = Default.NewSummary("slacker_export_stage_duration_seconds", "Duration of export pipeline stages.", "stage")
+
+	APICalls             = Default.NewCounter("slacker_slack_api_calls_total", "Slack Web API calls by method and HTTP status.", "method", "code")
+	APIDuration          = Default.NewSummary("slacker_slack_api_call_duration_seconds", "Duration of Slack Web API calls.", "method")
+	RateLimitWaits       = Default.NewCounter("slacker_slack_rate_limit_waits_total", "Times a Slack API call was rate limited and waited.", "method")
+	RateLimitWaitSeconds = Default.NewCounter("slacker_slack_rate_limit_wait_seconds_total", "Time spent waiting for Slack rate limits.", "method")
+	APIRetries           = Default.NewCounter("slacker_slack_api_retries_total", "Slack API calls retried after a rate limit.", "method")
+)
+
+var (
+	tracerMu sync.RWMutex
+	tracer   *Tracer
+)
+
+// SetTracer installs the tracer used by StartSpan. A nil tracer disables tracing.
+func SetTracer(t *Tracer) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	tracer = t
+}
+
+// StartSpan begins a span using the installed tracer
+func StartSpan(ctx context.Context, name string, attributes ...string) (context.Context, *Span) {
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+
+	return t.Start(ctx, name, attributes...)
+}
+
+// Flush exports finished spans using the installed tracer
+func Flush(ctx context.Context) error {
+	tracerMu.RLock()
+	t := tracer
+	tracerMu.RUnlock()
+
+	return t.Flush(ctx)
+}
+
+// rateLimitObserverKey carries the function told about rate limit waits
+type rateLimitObserverKey struct{}
+
+// WithRateLimitObserver returns a context whose Slack API calls report every
+// rate limit wait to observe before waiting
+func WithRateLimitObserver(ctx context.Context, observe func(method string, wait time.Duration)) context.Context {
+	return context.WithValue(ctx, rateLimitObserverKey{}, observe)
+}
+
+// ObserveRateLimit reports a rate limit wait to the observer of ctx, if any
+func ObserveRateLimit(ctx context.Context, method string, wait time.Duration) {
+	if observe, ok := ctx.Value(rateLimitObserverKey{}).(func(string, time.Duration)); ok {
+		observe(method, wait)
+	}
+}
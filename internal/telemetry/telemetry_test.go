@@ -0,0 +1,155 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+	calls := r.NewCounter("test_calls_total", "Calls made.", "method")
+	duration := r.NewSummary("test_duration_seconds", "Duration.")
+	last := r.NewGauge("test_last_timestamp", "Last run.")
+
+	calls.Inc("users.list")
+	calls.Add(2, "conversations.history")
+	duration.Observe(1.5)
+	duration.Observe(0.5)
+	last.Set(42)
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	output := b.String()
+
+	expected := []string{
+		"# TYPE test_calls_total counter",
+		`test_calls_total{method="conversations.history"} 2`,
+		`test_calls_total{method="users.list"} 1`,
+		"# TYPE test_duration_seconds summary",
+		"test_duration_seconds_sum 2",
+		"test_duration_seconds_count 2",
+		"test_last_timestamp 42",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain %q, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestRegistry_Push(t *testing.T) {
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.Method + " " + r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	r := NewRegistry()
+	r.NewCounter("test_total", "Test.").Inc()
+
+	if err := r.Push(context.Background(), server.URL, "slacker", nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if path != "PUT /metrics/job/slacker" {
+		t.Errorf("Expected PUT /metrics/job/slacker, got %s", path)
+	}
+	if !strings.Contains(body, "test_total 1") {
+		t.Errorf("Expected pushed metrics, got:\n%s", body)
+	}
+}
+
+func TestTracer_Flush(t *testing.T) {
+	var request map[string]any
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&request)
+	}))
+	defer server.Close()
+
+	tracer := NewTracer(server.URL, "slacker-test", nil)
+	ctx, parent := tracer.Start(context.Background(), "export.channel", "slack.channel_id", "C123")
+	_, child := tracer.Start(ctx, "export.message_fetch")
+	child.End(errors.New("boom"))
+	parent.End(nil)
+
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if path != "/v1/traces" {
+		t.Errorf("Expected /v1/traces, got %s", path)
+	}
+
+	resourceSpans := request["resourceSpans"].([]any)[0].(map[string]any)
+	spans := resourceSpans["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	childSpan := spans[0].(map[string]any)
+	parentSpan := spans[1].(map[string]any)
+	if childSpan["traceId"] != parentSpan["traceId"] {
+		t.Error("Expected child span to share the parent trace ID")
+	}
+	if childSpan["parentSpanId"] != parentSpan["spanId"] {
+		t.Error("Expected child span to reference the parent span")
+	}
+	if childSpan["status"] == nil {
+		t.Error("Expected failed span to carry an error status")
+	}
+
+	// Flushing again sends nothing
+	path = ""
+	tracer.Flush(context.Background())
+	if path != "" {
+		t.Error("Expected no request when there are no spans")
+	}
+}
+
+func TestTracer_Disabled(t *testing.T) {
+	tracer := NewTracer("", "slacker", nil)
+	ctx, span := tracer.Start(context.Background(), "noop")
+	span.SetAttribute("key", "value")
+	span.End(nil)
+
+	if ctx == nil {
+		t.Error("Expected context to be returned")
+	}
+	if err := tracer.Flush(context.Background()); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestRegistry_LabelMismatch(t *testing.T) {
+	r := NewRegistry()
+	var logged bytes.Buffer
+	r.SetLogger(slog.New(slog.NewTextHandler(&logged, nil)))
+	calls := r.NewCounter("test_calls_total", "Calls made.", "method")
+
+	calls.Inc()
+	calls.Inc("users.list", "200")
+	calls.Inc("users.list")
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(b.String(), `test_calls_total{method="users.list"} 1`+"\n") {
+		t.Errorf("Expected only the valid update counted, got:\n%s", b.String())
+	}
+	if count := strings.Count(logged.String(), "dropped metric update"); count != 1 {
+		t.Errorf("Expected the mismatch logged once, got %d times:\n%s", count, logged.String())
+	}
+}
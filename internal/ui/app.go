@@ -3,12 +3,17 @@ package ui
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/itcaat/slacker/internal/api"
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/cache"
 	"github.com/itcaat/slacker/internal/config"
+	"github.com/itcaat/slacker/internal/i18n"
+	"github.com/itcaat/slacker/internal/store"
 	"github.com/itcaat/slacker/internal/usecase"
 	"github.com/itcaat/slacker/models"
 )
@@ -31,14 +36,27 @@ type App struct {
 	width           int
 	height          int
 	slackClient     *api.SlackClient
+	source          usecase.MessageClientInterface
+	store           *store.Store
 	messageService  *usecase.MessageService
+	auditLog        *audit.Log
+	apiTimeout      time.Duration
+	version         string
+	location        *time.Location
 	channels        []models.Channel
 	selectedChannel *models.Channel
 	messages        []models.Message
+	messageWarnings []string
 	users           map[string]models.User
 	error           error
 	loading         bool
 
+	// Progress of the running export and the channel its updates arrive on
+	exportProgress models.ExportProgress
+	exportNotice   string
+	exportWarnings int
+	exportUpdates  chan tea.Msg
+
 	// UI components
 	channelList *ChannelListModel
 	messageView *MessageViewModel
@@ -62,23 +80,75 @@ type Styles struct {
 	Timestamp  lipgloss.Style
 }
 
-// NewApp creates a new TUI application
-func NewApp() (*App, error) {
+// NewApp creates a new TUI application. An offline app reads channels and
+// messages from the local message store instead of the Slack API.
+func NewApp(offline bool) (*App, error) {
 	// Get configuration
 	configManager := config.NewManager()
 	token, err := configManager.GetToken()
-	if err != nil {
+	if err != nil && !offline {
 		return nil, fmt.Errorf("authentication required. Run 'slacker auth <token>' first: %w", err)
 	}
 
+	var apiConfig config.APIConfig
+	var auditConfig config.AuditConfig
+	cacheConfig := config.CacheConfig{ChannelsTTL: config.DefaultChannelsTTL, UsersTTL: config.DefaultUsersTTL}
+	if cfg, err := configManager.Load(); err == nil {
+		if err := SetTheme(cfg.UI.Theme); err != nil {
+			return nil, err
+		}
+		apiConfig = cfg.API
+		cacheConfig = cfg.Cache
+		auditConfig = cfg.Audit
+	}
+
 	// Create Slack client
 	slackClient := api.NewSlackClient(token, false)
-	messageService := usecase.NewMessageService(slackClient)
+	if err := slackClient.SetPageSize(apiConfig.PageSize); err != nil {
+		return nil, err
+	}
+	if dir, err := cache.DefaultDir(); err == nil {
+		slackClient.SetCache(cache.New(dir), cacheConfig.ChannelsTTL, cacheConfig.UsersTTL)
+	}
+
+	var source usecase.MessageClientInterface = slackClient
+	var st *store.Store
+	if offline {
+		path, err := store.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		if st, err = store.Open(path, true); err != nil {
+			return nil, err
+		}
+		source = st
+	}
+
+	messageService := usecase.NewMessageService(source)
+	messageService.SetPaging(apiConfig.PageSize, apiConfig.ThreadDelay)
+
+	var auditLog *audit.Log
+	if !auditConfig.Disabled {
+		if auditLog, err = audit.Open(auditConfig.Path); err != nil {
+			return nil, err
+		}
+		if !offline {
+			auditLog.SetIdentityFunc(func() (*models.ExportIdentity, error) {
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+				return slackClient.GetIdentity(ctx)
+			})
+		}
+	}
 
 	app := &App{
 		state:          StateLoading,
 		slackClient:    slackClient,
+		source:         source,
+		store:          st,
 		messageService: messageService,
+		auditLog:       auditLog,
+		apiTimeout:     apiConfig.Timeout,
 		loading:        true,
 		styles:         createStyles(),
 	}
@@ -90,53 +160,69 @@ func NewApp() (*App, error) {
 	return app, nil
 }
 
+// timeout returns the configured API timeout, or def when none is set
+func (a *App) timeout(def time.Duration) time.Duration {
+	if a.apiTimeout > 0 {
+		return a.apiTimeout
+	}
+	return def
+}
+
+// border returns the rounded border, or an ASCII one in the ASCII fallback
+func border() lipgloss.Border {
+	if i18n.ASCII() {
+		return lipgloss.ASCIIBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
 // createStyles initializes the application styles
 func createStyles() Styles {
 	return Styles{
 		Header: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
+			Foreground(theme.Text).
+			Background(theme.Primary).
 			Padding(0, 1),
 
 		Footer: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#A49FA5")).
-			Background(lipgloss.Color("#2B2B2B")).
+			Foreground(theme.Muted).
+			Background(theme.Surface).
 			Padding(0, 1),
 
 		Error: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF5F87")).
+			Foreground(theme.Error).
 			Bold(true),
 
 		Loading: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4")).
+			Foreground(theme.Primary).
 			Bold(true),
 
 		Border: lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#874BFD")),
+			Border(border()).
+			BorderForeground(theme.Border),
 
 		Selected: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
+			Foreground(theme.Text).
+			Background(theme.Primary).
 			Bold(true),
 
 		Unselected: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#A49FA5")),
+			Foreground(theme.Muted),
 
 		Message: lipgloss.NewStyle().
 			Padding(0, 1),
 
 		Thread: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#A49FA5")).
+			Foreground(theme.Muted).
 			MarginLeft(2),
 
 		Username: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4")).
+			Foreground(theme.Primary).
 			Bold(true),
 
 		Timestamp: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262")),
+			Foreground(theme.Subtle),
 	}
 }
 
@@ -180,6 +266,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Refresh data
 			if a.state == StateChannelList {
 				a.loading = true
+				if a.store == nil {
+					a.slackClient.InvalidateCache()
+				}
 				return a, a.loadChannels()
 			} else if a.state == StateMessageView && a.selectedChannel != nil {
 				a.loading = true
@@ -189,6 +278,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Export channel
 			if a.selectedChannel != nil && (a.state == StateChannelList || a.state == StateMessageView) {
 				a.state = StateExporting
+				a.exportProgress = models.ExportProgress{}
+				a.exportNotice = ""
+				a.exportWarnings = 0
 				return a, a.exportChannel(a.selectedChannel)
 			}
 		}
@@ -203,6 +295,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case messagesLoadedMsg:
 		a.loading = false
 		a.messages = msg.messages
+		a.messageWarnings = msg.warnings
 		a.state = StateMessageView
 		a.messageView.SetMessages(a.messages, a.users)
 
@@ -221,11 +314,20 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.state = StateChannelList
 		// Show success message (in a real implementation, we might want to show this in the UI)
 		if msg.result.Success {
-			fmt.Printf("\n✅ Export completed: %s (%s)\n", msg.result.OutputFile, formatFileSize(msg.result.FileSize))
+			fmt.Print(i18n.Text(fmt.Sprintf("\n%s Export completed: %s (%s)\n", i18n.IconOK, msg.result.OutputFile, usecase.FormatFileSize(msg.result.FileSize))))
 		}
 
-	case exportProgressMsg:
-		// Update export progress (could be displayed in UI)
+	case exportEventMsg:
+		a.exportProgress = msg.event.Snapshot()
+		switch event := msg.event.(type) {
+		case usecase.RateLimited:
+			a.exportNotice = fmt.Sprintf("%s Rate limited by Slack, retrying in %s", i18n.IconRateLimited, event.Wait.Round(time.Second))
+		case usecase.Warning:
+			a.exportWarnings++
+		default:
+			a.exportNotice = ""
+		}
+		return a, waitForExport(a.exportUpdates)
 	}
 
 	// Update current component
@@ -276,7 +378,7 @@ func (a *App) View() string {
 	default:
 		footer = "q: quit"
 	}
-	footerView := a.styles.Footer.Width(a.width).Render(footer)
+	footerView := a.styles.Footer.Width(a.width).Render(i18n.Text(footer))
 
 	// Content area height
 	contentHeight := a.height - 2 // Subtract header and footer
@@ -303,7 +405,7 @@ func (a *App) View() string {
 
 // renderLoading renders the loading state
 func (a *App) renderLoading(height int) string {
-	loading := a.styles.Loading.Render("🔄 Loading...")
+	loading := a.styles.Loading.Render(i18n.Text(i18n.IconProgress + " Loading..."))
 	return lipgloss.Place(a.width, height, lipgloss.Center, lipgloss.Center, loading)
 }
 
@@ -313,7 +415,7 @@ func (a *App) renderChannelList(height int) string {
 		return ""
 	}
 
-	title := lipgloss.NewStyle().Bold(true).Render("📢 Channels")
+	title := lipgloss.NewStyle().Bold(true).Render(i18n.Text(i18n.IconChannel + " Channels"))
 	channelView := a.channelList.View()
 
 	content := lipgloss.JoinVertical(lipgloss.Left, title, channelView)
@@ -326,7 +428,11 @@ func (a *App) renderMessageView(height int) string {
 		return ""
 	}
 
-	title := lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("💬 #%s", a.selectedChannel.Name))
+	title := lipgloss.NewStyle().Bold(true).Render(i18n.Text(fmt.Sprintf("%s #%s", i18n.IconThread, a.selectedChannel.Name)))
+	if len(a.messageWarnings) > 0 {
+		notice := fmt.Sprintf("%s  %d threads could not be loaded", i18n.IconWarning, len(a.messageWarnings))
+		title += "  " + a.styles.Error.Render(i18n.Text(notice))
+	}
 	messageView := a.messageView.View()
 
 	content := lipgloss.JoinVertical(lipgloss.Left, title, messageView)
@@ -335,7 +441,7 @@ func (a *App) renderMessageView(height int) string {
 
 // renderError renders the error state
 func (a *App) renderError(height int) string {
-	errorText := a.styles.Error.Render(fmt.Sprintf("❌ Error: %v", a.error))
+	errorText := a.styles.Error.Render(i18n.Text(fmt.Sprintf("%s Error: %v", i18n.IconFailed, a.error)))
 	return lipgloss.Place(a.width, height, lipgloss.Center, lipgloss.Center, errorText)
 }
 
@@ -345,22 +451,42 @@ func (a *App) renderExporting(height int) string {
 		return ""
 	}
 
-	exportText := a.styles.Loading.Render(fmt.Sprintf("📤 Exporting channel #%s...", a.selectedChannel.Name))
+	lines := []string{fmt.Sprintf("%s Exporting channel #%s...", i18n.IconExport, a.selectedChannel.Name)}
+	if progress := a.exportProgress; progress.Stage != "" {
+		const barWidth = 30
+		filled := int(progress.Progress * barWidth)
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+		lines = append(lines, "", progress.CurrentStep, fmt.Sprintf("[%s] %.0f%%", bar, progress.Progress*100))
+
+		timing := fmt.Sprintf("Elapsed %s", progress.ElapsedTime.Round(time.Second))
+		if remaining, ok := progress.Remaining(); ok {
+			timing += fmt.Sprintf(" • ETA %s", remaining.Round(time.Second))
+		}
+		lines = append(lines, timing)
+	}
+	if a.exportNotice != "" {
+		lines = append(lines, a.exportNotice)
+	}
+	if a.exportWarnings > 0 {
+		lines = append(lines, fmt.Sprintf("%s  %d warnings, the export will be partial", i18n.IconWarning, a.exportWarnings))
+	}
+
+	exportText := a.styles.Loading.Render(i18n.Text(strings.Join(lines, "\n")))
 	return lipgloss.Place(a.width, height, lipgloss.Center, lipgloss.Center, exportText)
 }
 
 // loadChannels loads the channel list
 func (a *App) loadChannels() tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), a.timeout(30*time.Second))
 		defer cancel()
 
-		channels, err := a.slackClient.GetChannels(ctx)
+		channels, err := a.source.GetChannels(ctx)
 		if err != nil {
 			return errorMsg{error: err}
 		}
 
-		users, err := a.slackClient.GetUsers(ctx)
+		users, err := a.source.GetUsers(ctx)
 		if err != nil {
 			return errorMsg{error: err}
 		}
@@ -380,7 +506,7 @@ func (a *App) loadChannels() tea.Cmd {
 // loadMessages loads messages for a channel
 func (a *App) loadMessages(channelID string) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), a.timeout(60*time.Second))
 		defer cancel()
 
 		opts := usecase.MessageRetrievalOptions{
@@ -394,9 +520,43 @@ func (a *App) loadMessages(channelID string) tea.Cmd {
 		if err != nil {
 			return errorMsg{error: err}
 		}
+		if a.store == nil {
+			a.nameBots(ctx, result.Messages)
+		}
 
 		return messagesLoadedMsg{
 			messages: result.Messages,
+			warnings: result.Warnings,
+		}
+	}
+}
+
+// nameBots fills in the app name of bot messages that carry only a bot ID,
+// looking the bots up with one call. Names are optional, so the bots that
+// could not be looked up stay unnamed.
+func (a *App) nameBots(ctx context.Context, messages []models.Message) {
+	var unnamed []*models.Message
+	var ids []string
+	var collect func([]models.Message)
+	collect = func(msgs []models.Message) {
+		for i := range msgs {
+			if msgs[i].BotID != "" && msgs[i].Username == "" {
+				unnamed = append(unnamed, &msgs[i])
+				ids = append(ids, msgs[i].BotID)
+			}
+			collect(msgs[i].Thread)
+		}
+	}
+	collect(messages)
+	if len(ids) == 0 {
+		return
+	}
+
+	bots, _ := a.slackClient.GetBots(ctx, ids)
+	for _, msg := range unnamed {
+		msg.Username = bots[msg.BotID].Name
+		if msg.Workflow != nil && msg.Workflow.Name == "" {
+			msg.Workflow.Name = msg.Username
 		}
 	}
 }
@@ -409,6 +569,7 @@ type channelsLoadedMsg struct {
 
 type messagesLoadedMsg struct {
 	messages []models.Message
+	warnings []string
 }
 
 type errorMsg struct {
@@ -419,23 +580,32 @@ type channelSelectedMsg struct {
 	channel models.Channel
 }
 
-type exportProgressMsg struct {
-	progress models.ExportProgress
+type exportEventMsg struct {
+	event usecase.ExportEvent
 }
 
 type exportCompletedMsg struct {
 	result *models.ExportResult
 }
 
-// exportChannel starts the export process for a channel
+// exportChannel starts the export of a channel in the background. Progress
+// updates and the result arrive through a.exportUpdates.
 func (a *App) exportChannel(channel *models.Channel) tea.Cmd {
-	return func() tea.Msg {
+	updates := make(chan tea.Msg, 1)
+	a.exportUpdates = updates
+
+	go func() {
 		// Create export service
-		exportService := usecase.NewExportService(a.slackClient, "1.0.0")
+		exportService := usecase.NewExportService(a.source, a.version)
+		exportService.SetAuditLog(a.auditLog)
+		if a.store == nil {
+			exportService.SetWorkspaceClient(a.slackClient)
+			exportService.SetBotClient(a.slackClient)
+		}
 
 		// Generate output filename
 		timestamp := time.Now().Format("20060102-150405")
-		outputFile := fmt.Sprintf("%s-export-%s.json", channel.Name, timestamp)
+		outputFile := usecase.ExportFileName(channel.Name, timestamp, "json")
 
 		// Create export options
 		options := models.ExportOptions{
@@ -447,44 +617,60 @@ func (a *App) exportChannel(channel *models.Channel) tea.Cmd {
 			OutputFile:       outputFile,
 			Format:           "json-pretty",
 			Compression:      "",
-		}
-
-		// Progress callback (could be used to update UI)
-		progressCallback := func(progress models.ExportProgress) {
-			// For now, we'll just ignore progress updates in TUI
-			// In a more advanced implementation, we could send progress messages
+			Location:         a.location,
 		}
 
 		// Start export
-		result, err := exportService.ExportChannel(options, progressCallback)
+		result, err := exportService.ExportChannel(context.Background(), options, exportEventSink(updates))
 		if err != nil {
-			return errorMsg{error: err}
+			updates <- errorMsg{error: err}
+			return
 		}
+		updates <- exportCompletedMsg{result: result}
+	}()
 
-		return exportCompletedMsg{result: result}
-	}
+	return waitForExport(updates)
 }
 
-// formatFileSize formats file size in human-readable format
-func formatFileSize(bytes int64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
+// exportEventSink forwards export events to the TUI as messages. Progress
+// events are dropped while the UI is still busy with the previous one;
+// warnings and rate limit waits are always delivered.
+type exportEventSink chan tea.Msg
+
+// HandleEvent implements usecase.EventSink
+func (updates exportEventSink) HandleEvent(event usecase.ExportEvent) {
+	switch event.(type) {
+	case usecase.RateLimited, usecase.Warning:
+		updates <- exportEventMsg{event: event}
+	default:
+		select {
+		case updates <- exportEventMsg{event: event}:
+		default:
+		}
 	}
-	div, exp := int64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
+}
+
+// waitForExport waits for the next update of a running export
+func waitForExport(updates chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
 	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// RunTUI starts the TUI application
-func RunTUI() error {
-	app, err := NewApp()
+// RunTUI starts the TUI application, optionally on the local message store.
+// version is recorded in the metadata of exports, and times are shown and
+// exported in loc.
+func RunTUI(offline bool, version string, loc *time.Location) error {
+	app, err := NewApp(offline)
 	if err != nil {
 		return err
 	}
+	app.version = version
+	app.location = loc
+	app.messageView.location = loc
+	if app.store != nil {
+		defer app.store.Close()
+	}
 
 	p := tea.NewProgram(app, tea.WithAltScreen())
 	_, err = p.Run()
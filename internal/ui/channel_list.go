@@ -6,6 +6,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/itcaat/slacker/internal/i18n"
 	"github.com/itcaat/slacker/models"
 )
 
@@ -42,23 +43,23 @@ func NewChannelListModel() *ChannelListModel {
 func createChannelListStyles() ChannelListStyles {
 	return ChannelListStyles{
 		Selected: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
+			Foreground(theme.Text).
+			Background(theme.Primary).
 			Bold(true).
 			Padding(0, 1),
 
 		Unselected: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#A49FA5")).
+			Foreground(theme.Muted).
 			Padding(0, 1),
 
 		Public: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4")),
+			Foreground(theme.Primary),
 
 		Private: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF8C00")),
+			Foreground(theme.Warning),
 
 		Archived: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262")).
+			Foreground(theme.Subtle).
 			Strikethrough(true),
 	}
 }
@@ -159,10 +160,10 @@ func (m *ChannelListModel) View() string {
 		var nameStyle lipgloss.Style
 
 		if channel.IsArchived {
-			icon = "📦"
+			icon = i18n.IconArchived
 			nameStyle = m.styles.Archived
 		} else if channel.IsPrivate {
-			icon = "🔒"
+			icon = i18n.IconPrivate
 			nameStyle = m.styles.Private
 		} else {
 			icon = "#"
@@ -179,7 +180,7 @@ func (m *ChannelListModel) View() string {
 		}
 
 		// Create the full item text
-		itemText := fmt.Sprintf("%s %s%s", icon, name, memberInfo)
+		itemText := i18n.Text(fmt.Sprintf("%s %s%s", icon, name, memberInfo))
 
 		// Apply selection styling
 		if i == m.cursor {
@@ -196,10 +197,10 @@ func (m *ChannelListModel) View() string {
 
 	// Add scroll indicators
 	if m.scrollTop > 0 {
-		content = "↑ More above\n" + content
+		content = i18n.Text("↑ More above\n") + content
 	}
 	if m.scrollTop+m.viewport < len(m.channels) {
-		content = content + "\n↓ More below"
+		content = content + i18n.Text("\n↓ More below")
 	}
 
 	return content
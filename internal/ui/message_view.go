@@ -2,12 +2,12 @@ package ui
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/itcaat/slacker/internal/i18n"
 	"github.com/itcaat/slacker/models"
 )
 
@@ -21,6 +21,8 @@ type MessageViewModel struct {
 	viewport  int
 	scrollTop int
 	styles    MessageViewStyles
+	// location is the zone times are shown in; nil selects the machine's zone
+	location *time.Location
 }
 
 // MessageViewStyles contains styling for the message view
@@ -53,30 +55,30 @@ func createMessageViewStyles() MessageViewStyles {
 			MarginBottom(1),
 
 		Thread: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#A49FA5")).
+			Foreground(theme.Muted).
 			MarginLeft(2).
 			Padding(0, 1),
 
 		Username: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4")).
+			Foreground(theme.Primary).
 			Bold(true),
 
 		Timestamp: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262")),
+			Foreground(theme.Subtle),
 
 		Selected: lipgloss.NewStyle().
-			Background(lipgloss.Color("#3C3C3C")).
+			Background(theme.SurfaceAlt).
 			Padding(0, 1),
 
 		Unselected: lipgloss.NewStyle().
 			Padding(0, 1),
 
 		Attachment: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF8C00")).
+			Foreground(theme.Warning).
 			Italic(true),
 
 		Reaction: lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFD700")),
+			Foreground(theme.Highlight),
 	}
 }
 
@@ -185,10 +187,10 @@ func (m *MessageViewModel) View() string {
 
 	// Add scroll indicators
 	if m.scrollTop > 0 {
-		content = "↑ More above\n" + content
+		content = i18n.Text("↑ More above\n") + content
 	}
 	if m.scrollTop+m.viewport < len(m.messages) {
-		content = content + "\n↓ More below"
+		content = content + i18n.Text("\n↓ More below")
 	}
 
 	return content
@@ -198,9 +200,13 @@ func (m *MessageViewModel) View() string {
 func (m *MessageViewModel) formatMessage(message models.Message, indent int, selected bool) string {
 	var parts []string
 
-	// Get user info
+	// Get user info; bot messages name their app
 	userName := message.User
-	if user, exists := m.users[message.User]; exists {
+	icon := i18n.IconUser
+	if name := botName(message); name != "" {
+		userName = name
+		icon = i18n.IconBot
+	} else if user, exists := m.users[message.User]; exists {
 		if user.Profile.DisplayName != "" {
 			userName = user.Profile.DisplayName
 		} else if user.RealName != "" {
@@ -210,12 +216,13 @@ func (m *MessageViewModel) formatMessage(message models.Message, indent int, sel
 		}
 	}
 
-	// Parse timestamp
-	timestamp, err := strconv.ParseFloat(message.Timestamp, 64)
+	// Parse timestamp in the configured zone
+	timestamp, err := models.ParseSlackTimestamp(message.Timestamp)
 	if err != nil {
-		timestamp = 0
+		timestamp = time.Unix(0, 0)
 	}
-	timeStr := time.Unix(int64(timestamp), 0).Format("15:04")
+	timestamp = timestamp.In(models.OrLocal(m.location))
+	timeStr := timestamp.Format("15:04")
 
 	// Create indentation for threads
 	indentStr := strings.Repeat("  ", indent)
@@ -223,13 +230,15 @@ func (m *MessageViewModel) formatMessage(message models.Message, indent int, sel
 	// Format header
 	var header string
 	if indent > 0 {
-		header = fmt.Sprintf("%s↳ %s %s",
+		header = fmt.Sprintf("%s%s %s %s",
 			indentStr,
+			i18n.Text("↳"),
 			m.styles.Username.Render(userName),
 			m.styles.Timestamp.Render(timeStr))
 	} else {
-		header = fmt.Sprintf("%s👤 %s %s",
+		header = fmt.Sprintf("%s%s %s %s",
 			indentStr,
+			i18n.Text(icon),
 			m.styles.Username.Render(userName),
 			m.styles.Timestamp.Render(timeStr))
 	}
@@ -240,8 +249,8 @@ func (m *MessageViewModel) formatMessage(message models.Message, indent int, sel
 
 	parts = append(parts, header)
 
-	// Format message text
-	text := message.Text
+	// Format message text for the terminal's symbols
+	text := i18n.Text(message.Text)
 	if text == "" && len(message.Attachments) > 0 {
 		text = m.styles.Attachment.Render("[Attachment]")
 	}
@@ -262,7 +271,7 @@ func (m *MessageViewModel) formatMessage(message models.Message, indent int, sel
 	// Add attachments
 	if len(message.Attachments) > 0 {
 		for _, att := range message.Attachments {
-			attText := m.styles.Attachment.Render(fmt.Sprintf("📎 %s", att.Title))
+			attText := m.styles.Attachment.Render(i18n.Text(fmt.Sprintf("%s %s", i18n.IconAttachment, att.Title)))
 			parts = append(parts, fmt.Sprintf("%s  %s", indentStr, attText))
 		}
 	}
@@ -270,7 +279,7 @@ func (m *MessageViewModel) formatMessage(message models.Message, indent int, sel
 	// Add files
 	if len(message.Files) > 0 {
 		for _, file := range message.Files {
-			fileText := m.styles.Attachment.Render(fmt.Sprintf("📁 %s (%s)", file.Name, file.Filetype))
+			fileText := m.styles.Attachment.Render(i18n.Text(fmt.Sprintf("%s %s (%s)", i18n.IconFile, file.Name, file.Filetype)))
 			parts = append(parts, fmt.Sprintf("%s  %s", indentStr, fileText))
 		}
 	}
@@ -282,12 +291,12 @@ func (m *MessageViewModel) formatMessage(message models.Message, indent int, sel
 			reactions = append(reactions, fmt.Sprintf(":%s: %d", reaction.Name, reaction.Count))
 		}
 		reactionText := m.styles.Reaction.Render(strings.Join(reactions, " "))
-		parts = append(parts, fmt.Sprintf("%s  👍 %s", indentStr, reactionText))
+		parts = append(parts, fmt.Sprintf("%s  %s %s", indentStr, i18n.Text(i18n.IconReaction), reactionText))
 	}
 
 	// Add thread replies
 	if len(message.Thread) > 0 {
-		threadHeader := m.styles.Thread.Render(fmt.Sprintf("💬 %d replies:", len(message.Thread)))
+		threadHeader := m.styles.Thread.Render(i18n.Text(fmt.Sprintf("%s %d replies:", i18n.IconThread, len(message.Thread))))
 		parts = append(parts, fmt.Sprintf("%s  %s", indentStr, threadHeader))
 
 		// Show first few thread replies
@@ -314,6 +323,18 @@ func (m *MessageViewModel) formatMessage(message models.Message, indent int, sel
 	return m.styles.Unselected.Render(messageContent)
 }
 
+// botName returns the name a bot message is shown under: its workflow name,
+// or the username it was posted as. Other messages have none.
+func botName(message models.Message) string {
+	if !message.IsBot() {
+		return ""
+	}
+	if message.Workflow != nil && message.Workflow.Name != "" {
+		return message.Workflow.Name
+	}
+	return message.Username
+}
+
 // wrapText wraps text to the specified width
 func (m *MessageViewModel) wrapText(text string, width int) string {
 	if width <= 0 {
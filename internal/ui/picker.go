@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/itcaat/slacker/internal/i18n"
+	"github.com/itcaat/slacker/models"
+)
+
+// ErrPickerCancelled is returned by PickChannel when the user quits without
+// choosing a channel
+var ErrPickerCancelled = errors.New("no channel selected")
+
+// pickerHeight is the number of list rows shown by the channel picker
+const pickerHeight = 12
+
+// ChannelPickerModel is a fuzzy-filtered channel list for choosing a single
+// channel
+type ChannelPickerModel struct {
+	channels []models.Channel
+	query    string
+	list     *ChannelListModel
+	selected *models.Channel
+	styles   Styles
+}
+
+// NewChannelPickerModel creates a picker for channels
+func NewChannelPickerModel(channels []models.Channel) *ChannelPickerModel {
+	m := &ChannelPickerModel{
+		channels: channels,
+		list:     NewChannelListModel(),
+		styles:   createStyles(),
+	}
+	m.list.SetSize(60, pickerHeight+2)
+	m.list.SetChannels(channels)
+	return m
+}
+
+// Init implements tea.Model
+func (m *ChannelPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model. Typed characters filter the list; the arrow
+// keys, enter, home and end are handled by the channel list.
+func (m *ChannelPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, pickerHeight+2)
+		return m, nil
+
+	case channelSelectedMsg:
+		m.selected = &msg.channel
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyBackspace:
+			if m.query != "" {
+				runes := []rune(m.query)
+				m.setQuery(string(runes[:len(runes)-1]))
+			}
+			return m, nil
+		case tea.KeyRunes:
+			m.setQuery(m.query + string(msg.Runes))
+			return m, nil
+		case tea.KeySpace:
+			// Channel names have no spaces; the list would select on space
+			return m, nil
+		}
+	}
+
+	_, cmd := m.list.Update(msg)
+	return m, cmd
+}
+
+// setQuery filters the channels by query, best matches first
+func (m *ChannelPickerModel) setQuery(query string) {
+	m.query = query
+	m.list.SetChannels(FilterChannels(m.channels, query))
+}
+
+// View implements tea.Model
+func (m *ChannelPickerModel) View() string {
+	if m.selected != nil {
+		return ""
+	}
+	prompt := m.styles.Username.Render("Channel: ") + m.query + i18n.Text("█")
+	help := m.styles.Timestamp.Render(i18n.Text(fmt.Sprintf("type to filter • ↑/↓: navigate • enter: select • esc: cancel • %d/%d channels",
+		m.list.GetChannelCount(), len(m.channels))))
+	return lipgloss.JoinVertical(lipgloss.Left, prompt, "", m.list.View(), "", help) + "\n"
+}
+
+// Selected returns the chosen channel, or nil when the picker was cancelled
+func (m *ChannelPickerModel) Selected() *models.Channel {
+	return m.selected
+}
+
+// FilterChannels returns the channels whose names contain the letters of
+// query in order, ranked by how closely they match: exact names, then
+// prefixes, then substrings, then scattered matches. An empty query keeps
+// all channels in their original order.
+func FilterChannels(channels []models.Channel, query string) []models.Channel {
+	query = strings.ToLower(strings.TrimPrefix(query, "#"))
+	if query == "" {
+		return channels
+	}
+
+	type match struct {
+		channel models.Channel
+		score   int
+	}
+	var matches []match
+	for _, channel := range channels {
+		if score, ok := fuzzyScore(strings.ToLower(channel.Name), query); ok {
+			matches = append(matches, match{channel: channel, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	filtered := make([]models.Channel, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.channel
+	}
+	return filtered
+}
+
+// fuzzyScore rates how well name matches query; higher is better
+func fuzzyScore(name, query string) (int, bool) {
+	switch {
+	case name == query:
+		return 4000, true
+	case strings.HasPrefix(name, query):
+		return 3000 - len(name), true
+	case strings.Contains(name, query):
+		return 2000 - len(name), true
+	}
+
+	// Every query character must appear in order; gaps cost points
+	score, pos := 1000-len(name), 0
+	for _, r := range query {
+		i := strings.IndexRune(name[pos:], r)
+		if i < 0 {
+			return 0, false
+		}
+		score -= i
+		pos += i + len(string(r))
+	}
+	return score, true
+}
+
+// PickChannel lets the user choose one of channels in the terminal. It
+// returns ErrPickerCancelled when the user quits.
+func PickChannel(channels []models.Channel) (*models.Channel, error) {
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels available")
+	}
+
+	model := NewChannelPickerModel(channels)
+	if _, err := tea.NewProgram(model).Run(); err != nil {
+		return nil, fmt.Errorf("channel picker failed: %w", err)
+	}
+	if model.Selected() == nil {
+		return nil, ErrPickerCancelled
+	}
+	return model.Selected(), nil
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/itcaat/slacker/models"
+)
+
+func TestFilterChannels(t *testing.T) {
+	channels := []models.Channel{
+		{ID: "C1", Name: "eng-backend-alerts"},
+		{ID: "C2", Name: "general"},
+		{ID: "C3", Name: "backend"},
+		{ID: "C4", Name: "backend-oncall"},
+		{ID: "C5", Name: "random"},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"eng-backend-alerts", "general", "backend", "backend-oncall", "random"}},
+		{"backend", []string{"backend", "backend-oncall", "eng-backend-alerts"}},
+		{"#BACK", []string{"backend", "backend-oncall", "eng-backend-alerts"}},
+		{"bkoc", []string{"backend-oncall"}},
+		{"xyz", nil},
+	}
+	for _, tt := range tests {
+		got := FilterChannels(channels, tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("FilterChannels(%q) returned %d channels, want %v", tt.query, len(got), tt.want)
+			continue
+		}
+		for i, channel := range got {
+			if channel.Name != tt.want[i] {
+				t.Errorf("FilterChannels(%q)[%d] = %s, want %s", tt.query, i, channel.Name, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestChannelPickerModel_Select(t *testing.T) {
+	model := NewChannelPickerModel([]models.Channel{
+		{ID: "C1", Name: "general"},
+		{ID: "C2", Name: "random"},
+		{ID: "C3", Name: "release-notes"},
+	})
+
+	// Letters filter instead of navigating; down moves within the matches
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if model.list.GetChannelCount() != 2 {
+		t.Fatalf("Expected 2 channels matching 'e', got %d", model.list.GetChannelCount())
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected enter to select a channel")
+	}
+	if _, cmd = model.Update(cmd()); cmd == nil || model.Selected() == nil {
+		t.Fatal("Expected the selection to quit the picker")
+	}
+	if model.Selected().ID != "C3" {
+		t.Errorf("Expected release-notes, got %s", model.Selected().Name)
+	}
+}
+
+func TestChannelPickerModel_Cancel(t *testing.T) {
+	model := NewChannelPickerModel([]models.Channel{{ID: "C1", Name: "general"}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("gx")})
+	model.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if model.query != "g" || model.list.GetChannelCount() != 1 {
+		t.Errorf("Expected backspace to widen the filter, got query %q with %d channels", model.query, model.list.GetChannelCount())
+	}
+	if _, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd == nil || model.Selected() != nil {
+		t.Error("Expected esc to quit without a selection")
+	}
+}
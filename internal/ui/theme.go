@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the colors used by the TUI styles
+type Theme struct {
+	Text       lipgloss.Color
+	Primary    lipgloss.Color
+	Border     lipgloss.Color
+	Muted      lipgloss.Color
+	Subtle     lipgloss.Color
+	Surface    lipgloss.Color
+	SurfaceAlt lipgloss.Color
+	Error      lipgloss.Color
+	Warning    lipgloss.Color
+	Highlight  lipgloss.Color
+}
+
+// Themes are the built-in color themes selectable with ui.theme
+var Themes = map[string]Theme{
+	"default": {
+		Text:       lipgloss.Color("#FAFAFA"),
+		Primary:    lipgloss.Color("#7D56F4"),
+		Border:     lipgloss.Color("#874BFD"),
+		Muted:      lipgloss.Color("#A49FA5"),
+		Subtle:     lipgloss.Color("#626262"),
+		Surface:    lipgloss.Color("#2B2B2B"),
+		SurfaceAlt: lipgloss.Color("#3C3C3C"),
+		Error:      lipgloss.Color("#FF5F87"),
+		Warning:    lipgloss.Color("#FF8C00"),
+		Highlight:  lipgloss.Color("#FFD700"),
+	},
+	"light": {
+		Text:       lipgloss.Color("#FFFFFF"),
+		Primary:    lipgloss.Color("#5A3FC0"),
+		Border:     lipgloss.Color("#6B4FD8"),
+		Muted:      lipgloss.Color("#5C5C5C"),
+		Subtle:     lipgloss.Color("#8A8A8A"),
+		Surface:    lipgloss.Color("#E4E4E4"),
+		SurfaceAlt: lipgloss.Color("#D0D0D0"),
+		Error:      lipgloss.Color("#C0143C"),
+		Warning:    lipgloss.Color("#B35900"),
+		Highlight:  lipgloss.Color("#8A6D00"),
+	},
+	"high-contrast": {
+		Text:       lipgloss.Color("#FFFFFF"),
+		Primary:    lipgloss.Color("#0000FF"),
+		Border:     lipgloss.Color("#FFFFFF"),
+		Muted:      lipgloss.Color("#FFFFFF"),
+		Subtle:     lipgloss.Color("#C0C0C0"),
+		Surface:    lipgloss.Color("#000000"),
+		SurfaceAlt: lipgloss.Color("#303030"),
+		Error:      lipgloss.Color("#FF0000"),
+		Warning:    lipgloss.Color("#FFFF00"),
+		Highlight:  lipgloss.Color("#00FFFF"),
+	},
+}
+
+// theme is the active theme used when styles are created
+var theme = Themes["default"]
+
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTheme selects the theme used for styles created afterwards. An empty
+// name selects the default theme.
+func SetTheme(name string) error {
+	if name == "" {
+		name = "default"
+	}
+	selected, ok := Themes[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown theme '%s'. Supported: %s", name, strings.Join(ThemeNames(), ", "))
+	}
+	theme = selected
+	return nil
+}
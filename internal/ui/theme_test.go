@@ -0,0 +1,25 @@
+package ui
+
+import "testing"
+
+func TestSetTheme(t *testing.T) {
+	defer SetTheme("default")
+
+	if err := SetTheme("light"); err != nil {
+		t.Fatalf("Expected light theme to exist, got %v", err)
+	}
+	if theme != Themes["light"] {
+		t.Error("Expected light theme to be active")
+	}
+
+	if err := SetTheme(""); err != nil {
+		t.Fatalf("Expected empty name to select the default theme, got %v", err)
+	}
+	if theme != Themes["default"] {
+		t.Error("Expected default theme to be active")
+	}
+
+	if err := SetTheme("neon"); err == nil {
+		t.Error("Expected error for unknown theme")
+	}
+}
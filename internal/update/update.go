@@ -0,0 +1,357 @@
+// Package update finds slacker releases on GitHub, verifies the downloaded
+// archive against the release checksums and replaces the running binary.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRepository is the GitHub repository releases are published to
+const DefaultRepository = "itcaat/slacker"
+
+// Release artifacts besides the archives
+const (
+	checksumsFile = "checksums.txt"
+	signatureFile = "checksums.txt.sig"
+)
+
+// maxDownloadSize bounds archive downloads
+const maxDownloadSize = 200 << 20
+
+// Release is a published GitHub release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release version without the "v" prefix
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// asset returns the release file called name
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Client looks up and downloads releases of a GitHub repository
+type Client struct {
+	httpClient *http.Client
+	apiURL     string
+	repository string
+	publicKey  ed25519.PublicKey
+}
+
+// NewClient creates a client for the releases of repository ("owner/name")
+func NewClient(repository string) *Client {
+	return &Client{
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		apiURL:     "https://api.github.com",
+		repository: repository,
+	}
+}
+
+// SetTransport sets the transport GitHub requests and downloads go through
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+// SetPublicKey makes Download require a valid signature of the checksums
+// file by the base64-encoded Ed25519 key
+func (c *Client) SetPublicKey(key string) error {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid release signing key")
+	}
+	c.publicKey = decoded
+	return nil
+}
+
+// Verifies reports whether downloads are checked against a signature
+func (c *Client) Verifies() bool {
+	return c.publicKey != nil
+}
+
+// LatestRelease returns the newest published release
+func (c *Client) LatestRelease(ctx context.Context) (*Release, error) {
+	data, err := c.get(ctx, fmt.Sprintf("%s/repos/%s/releases/latest", c.apiURL, c.repository), 4<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the latest release: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("invalid release response: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("invalid release response: no tag")
+	}
+	return &release, nil
+}
+
+// Download fetches the archive of release for goos/goarch, checks it against
+// the release checksums (and their signature when a public key is set) and
+// returns the slacker binary it contains
+func (c *Client) Download(ctx context.Context, release *Release, goos, goarch string) ([]byte, error) {
+	name := ArchiveName(release.Version(), goos, goarch)
+	archive, ok := release.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no build for %s/%s (%s)", release.TagName, goos, goarch, name)
+	}
+	checksumsAsset, ok := release.asset(checksumsFile)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s", release.TagName, checksumsFile)
+	}
+
+	checksums, err := c.get(ctx, checksumsAsset.URL, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download checksums: %w", err)
+	}
+	if c.publicKey != nil {
+		signatureAsset, ok := release.asset(signatureFile)
+		if !ok {
+			return nil, fmt.Errorf("release %s is not signed", release.TagName)
+		}
+		signature, err := c.get(ctx, signatureAsset.URL, 4<<10)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download signature: %w", err)
+		}
+		if err := verifySignature(c.publicKey, checksums, signature); err != nil {
+			return nil, err
+		}
+	}
+	expected, err := findChecksum(checksums, name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.get(ctx, archive.URL, maxDownloadSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != expected {
+		return nil, fmt.Errorf("checksum mismatch for %s", name)
+	}
+
+	return extractBinary(data, name, BinaryName(goos))
+}
+
+// get downloads url, reading at most limit bytes
+func (c *Client) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json, application/octet-stream")
+	req.Header.Set("User-Agent", "slacker-update")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("GET %s: response too large", url)
+	}
+	return data, nil
+}
+
+// ArchiveName is the name of the release archive for goos/goarch
+func ArchiveName(version, goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("slacker_%s_%s_%s.%s", version, goos, goarch, ext)
+}
+
+// BinaryName is the file name of the slacker binary on goos
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return "slacker.exe"
+	}
+	return "slacker"
+}
+
+// verifySignature checks the base64 or raw Ed25519 signature of data
+func verifySignature(key ed25519.PublicKey, data, signature []byte) error {
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("invalid signature of %s", checksumsFile)
+	}
+	return nil
+}
+
+// findChecksum returns the SHA-256 of name from a sha256sum-style list
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// extractBinary returns the file called binary from a .tar.gz or .zip archive
+func extractBinary(data []byte, archiveName, binary string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", archiveName, err)
+		}
+		for _, file := range reader.File {
+			if filepath.Base(file.Name) != binary {
+				continue
+			}
+			rc, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(io.LimitReader(rc, maxDownloadSize))
+		}
+		return nil, fmt.Errorf("%s not found in %s", binary, archiveName)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive %s: %w", archiveName, err)
+	}
+	defer gz.Close()
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s not found in %s", binary, archiveName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive %s: %w", archiveName, err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binary {
+			return io.ReadAll(io.LimitReader(reader, maxDownloadSize))
+		}
+	}
+}
+
+// Newer reports whether version latest is newer than current. Versions are
+// compared as dotted numbers; a current version that is not one, such as a
+// development build, is always older.
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses "v1.2.3" into its numbers, ignoring any pre-release or
+// build suffix
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	fields := strings.Split(version, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// ReplaceExecutable atomically replaces the file at path with binary,
+// keeping its permissions. The running program keeps working, as it still
+// holds the old file.
+func ReplaceExecutable(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".slacker-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0111); err != nil {
+		return err
+	}
+
+	// Windows cannot overwrite a running executable but can rename it
+	old := path + ".old"
+	os.Remove(old)
+	if err := os.Rename(path, old); err != nil {
+		return fmt.Errorf("failed to move the current binary: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Rename(old, path)
+		return fmt.Errorf("failed to install the new binary: %w", err)
+	}
+	os.Remove(old)
+	return nil
+}
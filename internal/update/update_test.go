@@ -0,0 +1,191 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest   string
+		current  string
+		expected bool
+	}{
+		{latest: "1.2.0", current: "1.1.9", expected: true},
+		{latest: "v1.10.0", current: "v1.9.0", expected: true},
+		{latest: "1.2.0", current: "1.2.0", expected: false},
+		{latest: "1.2.0", current: "1.3.0", expected: false},
+		{latest: "2.0.0", current: "2.0.0-rc1", expected: false},
+		{latest: "1.0.0", current: "dev", expected: true},
+		{latest: "nightly", current: "1.0.0", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.latest+"/"+tt.current, func(t *testing.T) {
+			if got := Newer(tt.latest, tt.current); got != tt.expected {
+				t.Errorf("Expected Newer(%q, %q) = %v, got %v", tt.latest, tt.current, tt.expected, got)
+			}
+		})
+	}
+}
+
+// releaseServer serves a release with a tar.gz archive of binary for
+// linux/amd64, its checksums and, when key is set, their signature
+func releaseServer(t *testing.T, binary []byte, key ed25519.PrivateKey, tamper bool) *httptest.Server {
+	t.Helper()
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "slacker", Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg})
+	tw.Write(binary)
+	tw.Close()
+	gz.Close()
+
+	name := ArchiveName("1.2.0", "linux", "amd64")
+	sum := sha256.Sum256(archive.Bytes())
+	checksums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+	if tamper {
+		archive.WriteString("tampered")
+	}
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/repos/itcaat/slacker/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		assets := []Asset{
+			{Name: name, URL: server.URL + "/download/" + name},
+			{Name: checksumsFile, URL: server.URL + "/download/" + checksumsFile},
+		}
+		if key != nil {
+			assets = append(assets, Asset{Name: signatureFile, URL: server.URL + "/download/" + signatureFile})
+		}
+		json.NewEncoder(w).Encode(Release{TagName: "v1.2.0", Assets: assets})
+	})
+	mux.HandleFunc("/download/"+name, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive.Bytes())
+	})
+	mux.HandleFunc("/download/"+checksumsFile, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(checksums))
+	})
+	mux.HandleFunc("/download/"+signatureFile, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(checksums)))))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testClient(server *httptest.Server) *Client {
+	client := NewClient(DefaultRepository)
+	client.httpClient = server.Client()
+	client.apiURL = server.URL
+	return client
+}
+
+func TestClient_Download(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := []byte("#!/bin/sh\necho slacker 1.2.0\n")
+
+	tests := []struct {
+		name    string
+		key     ed25519.PrivateKey
+		verify  ed25519.PublicKey
+		tamper  bool
+		wantErr string
+	}{
+		{name: "Checksum only", key: nil},
+		{name: "Signed", key: private, verify: public},
+		{name: "Tampered archive", key: nil, tamper: true, wantErr: "checksum mismatch"},
+		{name: "Unsigned release", key: nil, verify: public, wantErr: "not signed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := testClient(releaseServer(t, binary, tt.key, tt.tamper))
+			if tt.verify != nil {
+				if err := client.SetPublicKey(base64.StdEncoding.EncodeToString(tt.verify)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			release, err := client.LatestRelease(context.Background())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if release.Version() != "1.2.0" {
+				t.Errorf("Expected version 1.2.0, got %s", release.Version())
+			}
+
+			got, err := client.Download(context.Background(), release, "linux", "amd64")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !bytes.Equal(got, binary) {
+				t.Errorf("Expected the archived binary, got %q", got)
+			}
+		})
+	}
+}
+
+func TestClient_DownloadWrongSignature(t *testing.T) {
+	_, private, _ := ed25519.GenerateKey(nil)
+	other, _, _ := ed25519.GenerateKey(nil)
+
+	client := testClient(releaseServer(t, []byte("binary"), private, false))
+	client.SetPublicKey(base64.StdEncoding.EncodeToString(other))
+	release, err := client.LatestRelease(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Download(context.Background(), release, "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("Expected an invalid signature error, got %v", err)
+	}
+	if _, err := client.Download(context.Background(), release, "windows", "arm64"); err == nil {
+		t.Error("Expected an error for a platform without a build")
+	}
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slacker")
+	if err := os.WriteFile(path, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ReplaceExecutable(path, []byte("new")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new" {
+		t.Errorf("Expected the new binary, got %q (%v)", data, err)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected the binary to stay executable, got %v", info.Mode())
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("Expected no leftover files, got %d entries", len(entries))
+	}
+}
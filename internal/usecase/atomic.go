@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// tempSuffix marks a local export that is still being written. The file is
+// renamed to its final name only once it is complete.
+const tempSuffix = ".tmp"
+
+// staleTempAge is how old a temp file must be before it is treated as left
+// over from a failed run, so exports in progress in other processes keep
+// theirs
+const staleTempAge = time.Hour
+
+// writeFileAtomic writes filename through write. The data goes to
+// <filename>.tmp and is renamed into place once it is complete and synced,
+// so a crash mid-write never leaves a truncated file that looks finished.
+// It returns the size of the written file.
+func writeFileAtomic(filename string, write func(io.Writer) error) (int64, error) {
+	sink, err := newFileSink(filename)
+	if err != nil {
+		return 0, err
+	}
+	if err := write(sink); err != nil {
+		sink.Abort()
+		return 0, err
+	}
+	return sink.Commit()
+}
+
+// RemoveStaleTempFiles deletes the temp files that failed or killed exports
+// left in dir and returns their paths. Only files of the export formats
+// older than an hour are removed.
+func RemoveStaleTempFiles(dir string, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, entry := range entries {
+		if entry.IsDir() || !isExportTempFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < staleTempAge {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// isExportTempFile reports whether name is the temp file of an export
+func isExportTempFile(name string) bool {
+	name, ok := strings.CutSuffix(name, tempSuffix)
+	if !ok {
+		return false
+	}
+	name = strings.TrimSuffix(name, ".gz")
+	for _, ext := range []string{".json", ".jsonl", ".pdf"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
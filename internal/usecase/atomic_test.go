@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "general-export.json")
+	if err := os.WriteFile(filename, []byte(`{"old":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed write keeps the previous file and removes the temp file
+	_, err := writeFileAtomic(filename, func(w io.Writer) error {
+		w.Write([]byte(`{"truncated":`))
+		return errors.New("disk full")
+	})
+	if err == nil {
+		t.Fatal("Expected the write error")
+	}
+	if data, _ := os.ReadFile(filename); string(data) != `{"old":true}` {
+		t.Errorf("Expected the previous file to be kept, got %s", data)
+	}
+	if _, err := os.Stat(filename + tempSuffix); !os.IsNotExist(err) {
+		t.Errorf("Expected the temp file to be removed, got %v", err)
+	}
+
+	size, err := writeFileAtomic(filename, func(w io.Writer) error {
+		_, err := w.Write([]byte(`{"new":true}`))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != `{"new":true}` || size != int64(len(data)) {
+		t.Errorf("Expected the new file of %d bytes, got %s", size, data)
+	}
+	if _, err := os.Stat(filename + tempSuffix); !os.IsNotExist(err) {
+		t.Errorf("Expected no temp file after the rename, got %v", err)
+	}
+}
+
+func TestRemoveStaleTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-2 * staleTempAge)
+
+	files := map[string]time.Time{
+		"general-export.json.tmp":    old, // failed run
+		"general-export.json.gz.tmp": old, // failed compressed run
+		"random-export.json.tmp":     now, // still being written
+		"general-export.json":        old,
+		"notes.txt.tmp":              old, // not an export
+	}
+	for name, modTime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := RemoveStaleTempFiles(dir, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Expected 2 removed temp files, got %v", removed)
+	}
+	for name := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		gone := os.IsNotExist(err)
+		if want := name == "general-export.json.tmp" || name == "general-export.json.gz.tmp"; gone != want {
+			t.Errorf("%s: expected removed=%v, got %v", name, want, gone)
+		}
+	}
+
+	if removed, err := RemoveStaleTempFiles(filepath.Join(dir, "missing"), now); err != nil || removed != nil {
+		t.Errorf("Expected nothing to do for a missing directory, got %v, %v", removed, err)
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// AvatarClientInterface defines the download needed for
+// ExportOptions.IncludeAvatars. Avatar URLs are public, so the token is not
+// sent.
+type AvatarClientInterface interface {
+	Download(ctx context.Context, url string) ([]byte, error)
+}
+
+// SetAvatarClient enables ExportOptions.IncludeAvatars
+func (s *ExportService) SetAvatarClient(client AvatarClientInterface) {
+	s.avatarClient = client
+}
+
+// avatarDir returns the directory avatars of a local export are saved to
+// and the prefix of the paths that refer to them: <name>-avatars/ next to
+// an export file, or avatars/ inside a slack-native export directory
+func avatarDir(options models.ExportOptions) (string, string) {
+	if options.Layout == LayoutSlackNative {
+		root := strings.TrimSuffix(strings.TrimSuffix(options.OutputFile, "/"), ".json")
+		return filepath.Join(root, "avatars"), "avatars/"
+	}
+	base, _ := splitExportExt(options.OutputFile)
+	return base + "-avatars", filepath.Base(base) + "-avatars/"
+}
+
+// collectAvatars downloads the profile images of users next to a local
+// export and points the image fields of their profiles at the local copies,
+// so the archive renders without network access. An image used by several
+// sizes or users is saved once. Failed downloads keep their URL and are
+// returned as warnings.
+func (s *ExportService) collectAvatars(ctx context.Context, users map[string]models.ExportUser, options models.ExportOptions) ([]string, error) {
+	if s.avatarClient == nil {
+		return nil, fmt.Errorf("no avatar client configured")
+	}
+	if !IsLocalOutput(options.OutputFile) {
+		return nil, nil
+	}
+
+	dir, prefix := avatarDir(options)
+	ids := make([]string, 0, len(users))
+	for id := range users {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var warnings []string
+	saved := make(map[string]string)
+	for _, id := range ids {
+		user := users[id]
+		for _, image := range avatarFields(&user.Profile) {
+			source := image.url
+			if !strings.HasPrefix(*source, "http://") && !strings.HasPrefix(*source, "https://") {
+				continue
+			}
+			local, ok := saved[*source]
+			if !ok {
+				data, err := s.avatarClient.Download(ctx, *source)
+				if ctx.Err() != nil {
+					return warnings, ctx.Err()
+				}
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("failed to download avatar %s of %s: %v", image.size, id, err))
+					saved[*source] = ""
+					continue
+				}
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					return warnings, fmt.Errorf("failed to create avatar directory: %w", err)
+				}
+				file := SafeFileName(id+"-"+image.size) + avatarExtension(*source, data)
+				if err := os.WriteFile(filepath.Join(dir, file), data, 0644); err != nil {
+					return warnings, fmt.Errorf("failed to write avatar of %s: %w", id, err)
+				}
+				local = prefix + file
+				saved[*source] = local
+			}
+			if local != "" {
+				*source = local
+			}
+		}
+		users[id] = user
+	}
+	return warnings, nil
+}
+
+// avatarImage is one size of a profile image
+type avatarImage struct {
+	size string
+	url  *string
+}
+
+// avatarFields returns the image fields of profile
+func avatarFields(profile *models.ExportProfile) []avatarImage {
+	return []avatarImage{
+		{"24", &profile.Image24},
+		{"32", &profile.Image32},
+		{"48", &profile.Image48},
+		{"72", &profile.Image72},
+		{"192", &profile.Image192},
+		{"512", &profile.Image512},
+	}
+}
+
+// avatarExtension returns the file extension of an avatar: the one of its
+// URL, or one matching its content for URLs without one such as Gravatar's
+func avatarExtension(source string, data []byte) string {
+	if u, err := url.Parse(source); err == nil {
+		if ext := strings.ToLower(path.Ext(u.Path)); ext == ".png" || ext == ".jpg" || ext == ".jpeg" || ext == ".gif" || ext == ".webp" {
+			return ext
+		}
+	}
+	switch http.DetectContentType(data) {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ".jpg"
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_IncludeAvatars(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	// Two sizes share one image; Bob's avatar has no extension
+	mockClient.users[0].Profile.Image24 = "https://example.com/alice72.png"
+	mockClient.users[1].Profile.Image72 = "https://gravatar.example/avatar/abc?s=72"
+
+	service := NewExportService(mockClient, "1.0.0-test")
+	downloads := &MockEmojiClient{images: map[string][]byte{
+		"https://example.com/alice72.png":          []byte("\x89PNG\r\n\x1a\n"),
+		"https://example.com/alice192.png":         []byte("\x89PNG\r\n\x1a\n"),
+		"https://gravatar.example/avatar/abc?s=72": []byte("GIF89a"),
+	}}
+	service.SetAvatarClient(downloads)
+
+	dir := t.TempDir()
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(dir, "general.json"),
+		Format:         "json",
+		IncludeAvatars: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	alice, bob := export.Users["U123456"].Profile, export.Users["U789012"].Profile
+	if alice.Image24 != "general-avatars/U123456-24.png" || alice.Image72 != alice.Image24 || alice.Image192 != "general-avatars/U123456-192.png" {
+		t.Errorf("Expected Alice's images to point at local copies, got %+v", alice)
+	}
+	if bob.Image72 != "general-avatars/U789012-72.gif" {
+		t.Errorf("Expected Bob's avatar typed by its content, got %q", bob.Image72)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "general-avatars", "U123456-72.png")); !os.IsNotExist(err) {
+		t.Errorf("Expected a shared image to be saved once, got %v", err)
+	}
+	for _, file := range []string{"U123456-24.png", "U123456-192.png", "U789012-72.gif"} {
+		if _, err := os.Stat(filepath.Join(dir, "general-avatars", file)); err != nil {
+			t.Errorf("Expected downloaded avatar: %v", err)
+		}
+	}
+
+	// Failed downloads keep their URL and are reported as warnings
+	if alice.Image512 != "https://example.com/alice512.png" || len(result.Warnings) != 3 {
+		t.Errorf("Expected the 32, 48 and 512 downloads to fail, got %q and %v", alice.Image512, result.Warnings)
+	}
+}
+
+func TestAvatarDir(t *testing.T) {
+	dir, prefix := avatarDir(models.ExportOptions{OutputFile: "out/general.json.gz"})
+	if dir != "out/general-avatars" || prefix != "general-avatars/" {
+		t.Errorf("Expected avatars next to the export, got %s and %s", dir, prefix)
+	}
+	dir, prefix = avatarDir(models.ExportOptions{OutputFile: "slack-export/", Layout: LayoutSlackNative})
+	if dir != filepath.Join("slack-export", "avatars") || prefix != "avatars/" {
+		t.Errorf("Expected avatars inside the export directory, got %s and %s", dir, prefix)
+	}
+}
@@ -0,0 +1,540 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/storage"
+	"github.com/itcaat/slacker/models"
+)
+
+// stateFileName is the file, relative to the output directory, that records
+// when each channel was last exported
+const stateFileName = ".slacker-state.json"
+
+// historyFilePrefix names the per-channel files, relative to the output
+// directory, that record message versions for change tracking
+const historyFilePrefix = ".slacker-history-"
+
+// BackupJob describes a repeatable export of a set of channels
+type BackupJob struct {
+	Channels       []string
+	OutputDir      string
+	Format         string
+	Compression    string
+	IncludeThreads bool
+	// Incremental exports only messages posted since the previous successful run
+	Incremental bool
+	// Keep is the number of export files retained per channel (0 = keep all)
+	// and KeepDays removes exports older than this many days. Retention only
+	// applies to local directories; exports named by OutputTemplate are
+	// only pruned when a backup run recorded them.
+	Keep     int
+	KeepDays int
+	// OutputTemplate names export files relative to OutputDir (see
+	// OutputNameData); Workspace fills its {{.Workspace}} variable
+	OutputTemplate string
+	Workspace      string
+	// SubtypePolicy includes, excludes or transforms system messages
+	SubtypePolicy map[string]string
+	// PageSize and ThreadDelay tune Slack API pagination (0 = defaults)
+	PageSize    int
+	ThreadDelay time.Duration
+	// Concurrency is the number of channels exported at once (0 = 1). The
+	// exports share the service's Slack client and its rate limit.
+	Concurrency int
+	// Location is the zone export timestamps are rendered in (nil = local)
+	Location *time.Location
+}
+
+// Retention returns the job's retention policy
+func (job BackupJob) Retention() RetentionPolicy {
+	return RetentionPolicy{KeepLast: job.Keep, KeepDays: job.KeepDays}
+}
+
+// BackupChannelResult summarizes the export of a single channel within a backup run
+type BackupChannelResult struct {
+	Channel    string        `json:"channel"`
+	OutputFile string        `json:"output_file,omitempty"`
+	Messages   int           `json:"messages"`
+	FileSize   int64         `json:"file_size"`
+	Duration   time.Duration `json:"duration"`
+	Removed    []string      `json:"removed,omitempty"`
+	Deleted    int           `json:"deleted,omitempty"`
+	Edited     int           `json:"edited,omitempty"`
+	Error      string        `json:"error,omitempty"`
+
+	ErrorCategory models.ErrorCategory `json:"error_category,omitempty"`
+}
+
+// BackupService runs backup jobs on top of the export service
+type BackupService struct {
+	slackClient   SlackClientInterface
+	exportService *ExportService
+	logger        *slog.Logger
+	channelEvents ChannelEvents
+	now           func() time.Time
+}
+
+// NewBackupService creates a new backup service
+func NewBackupService(slackClient SlackClientInterface, version string) *BackupService {
+	return &BackupService{
+		slackClient:   slackClient,
+		exportService: NewExportService(slackClient, version),
+		logger:        slog.Default(),
+		now:           time.Now,
+	}
+}
+
+// backupState is persisted between runs to support incremental exports
+type backupState struct {
+	Channels map[string]time.Time `json:"channels"`
+	// Exports lists the files each run wrote, per channel name, so that
+	// retention can tell incremental exports from the full ones they extend
+	Exports map[string][]backupExport `json:"exports,omitempty"`
+}
+
+// backupExport is an export file written by a backup run. A delta holds only
+// the messages posted since the export before it.
+type backupExport struct {
+	File  string `json:"file"`
+	Delta bool   `json:"delta,omitempty"`
+}
+
+// record adds an export of channel to the state
+func (st *backupState) record(channel string, export backupExport) {
+	if st.Exports == nil {
+		st.Exports = make(map[string][]backupExport)
+	}
+	st.Exports[channel] = append(st.Exports[channel], export)
+}
+
+// forget removes the exports of channel at the given paths, relative to dir
+func (st *backupState) forget(dir, channel string, removed []string) {
+	gone := make(map[string]bool, len(removed))
+	for _, file := range removed {
+		gone[filepath.Clean(file)] = true
+	}
+	kept := st.Exports[channel][:0]
+	for _, export := range st.Exports[channel] {
+		if !gone[filepath.Join(dir, export.File)] {
+			kept = append(kept, export)
+		}
+	}
+	st.Exports[channel] = kept
+}
+
+// messageHistory records the message versions seen by the previous
+// incremental export of a channel
+type messageHistory struct {
+	TakenAt  time.Time                        `json:"taken_at"`
+	Messages map[string]models.MessageVersion `json:"messages"`
+}
+
+// SetLogger sets the logger used for export warnings and by the underlying
+// export service
+func (s *BackupService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+	s.exportService.SetLogger(logger)
+}
+
+// SetWorkspaceClient records the workspace in the metadata of every export
+func (s *BackupService) SetWorkspaceClient(client WorkspaceClientInterface) {
+	s.exportService.SetWorkspaceClient(client)
+}
+
+// SetAuditLog records the export of every channel in log
+func (s *BackupService) SetAuditLog(log *audit.Log) {
+	s.exportService.SetAuditLog(log)
+}
+
+// SetBotClient adds the bots that posted messages to every export
+func (s *BackupService) SetBotClient(client BotClientInterface) {
+	s.exportService.SetBotClient(client)
+}
+
+// AddHook adds a hook to the export of every channel
+func (s *BackupService) AddHook(hook ExportHook) {
+	s.exportService.AddHook(hook)
+}
+
+// SetChannelEvents reports the start, events and end of every channel
+// export to events, e.g. to draw a progress bar per channel
+func (s *BackupService) SetChannelEvents(events ChannelEvents) {
+	s.channelEvents = events
+}
+
+// Run exports every channel of the job, job.Concurrency channels at a time.
+// A failing channel does not stop the run; its error is recorded in the
+// returned results, which follow the order of job.Channels.
+func (s *BackupService) Run(job BackupJob) ([]BackupChannelResult, error) {
+	if len(job.Channels) == 0 {
+		return nil, fmt.Errorf("no channels configured")
+	}
+	if job.OutputDir == "" {
+		job.OutputDir = "."
+	}
+	if job.Format == "" {
+		job.Format = "json"
+	}
+	if err := ValidateSubtypePolicy(job.SubtypePolicy); err != nil {
+		return nil, err
+	}
+
+	// Incremental state and rotation are only tracked for local directories
+	remote := storage.IsRemote(job.OutputDir)
+	if !remote {
+		if err := os.MkdirAll(job.OutputDir, 0755); err != nil {
+			return nil, models.NewExportError(models.ErrorCategoryIO, "failed to create output directory", err)
+		}
+	}
+
+	channels, err := s.slackClient.GetChannels(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channels: %w", err)
+	}
+	channelsByName := make(map[string]models.Channel)
+	for _, channel := range channels {
+		channelsByName[channel.Name] = channel
+	}
+
+	state := &backupState{Channels: make(map[string]time.Time)}
+	if !remote {
+		state = s.loadState(job.OutputDir)
+	}
+
+	workers := job.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		fatal   error
+		results = make([]BackupChannelResult, len(job.Channels))
+		done    = make([]bool, len(job.Channels))
+		jobs    = make(chan int)
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				name := strings.TrimPrefix(job.Channels[index], "#")
+				result, err := s.backupChannel(job, channelsByName, state, &mu, remote, name, channelStarted(s.channelEvents, name))
+				switch {
+				case err != nil:
+					channelFinished(s.channelEvents, name, err)
+				case result.Error != "":
+					channelFinished(s.channelEvents, name, errors.New(result.Error))
+				default:
+					channelFinished(s.channelEvents, name, nil)
+				}
+				mu.Lock()
+				if err != nil && fatal == nil {
+					fatal = err
+				}
+				results[index], done[index] = result, err == nil
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for index := range job.Channels {
+		mu.Lock()
+		stop := fatal != nil
+		mu.Unlock()
+		if stop {
+			break
+		}
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Report results in the order of the job's channels
+	finished := results[:0]
+	for index, result := range results {
+		if done[index] {
+			finished = append(finished, result)
+		}
+	}
+	results = finished
+	if fatal != nil {
+		return results, fatal
+	}
+
+	if !remote {
+		if err := s.saveState(job.OutputDir, state); err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// backupChannel exports one channel of a job. Channel failures are recorded
+// in the result; the returned error aborts the whole run. mu guards state,
+// which all workers share; events receives the events of the export.
+func (s *BackupService) backupChannel(job BackupJob, channelsByName map[string]models.Channel, state *backupState, mu *sync.Mutex, remote bool, name string, events EventSink) (BackupChannelResult, error) {
+	name = strings.TrimPrefix(name, "#")
+	result := BackupChannelResult{Channel: name}
+
+	channel, ok := channelsByName[name]
+	if !ok {
+		result.Error = fmt.Sprintf("channel '%s' not found", name)
+		result.ErrorCategory = models.ErrorCategoryChannelNotFound
+		return result, nil
+	}
+
+	runStart := s.now()
+	fileName := ExportFileName(channel.Name, runStart.Format("20060102-150405"), job.Format)
+	if job.OutputTemplate != "" {
+		data := NewOutputNameData(channel.ID, channel.Name, job.Workspace, nil, nil, runStart)
+		var err error
+		if fileName, err = RenderOutputTemplate(job.OutputTemplate, data); err != nil {
+			return result, err
+		}
+	}
+	outputFile := filepath.Join(job.OutputDir, fileName)
+	if remote {
+		outputFile = storage.Join(job.OutputDir, fileName)
+	}
+	options := models.ExportOptions{
+		ChannelID:        channel.ID,
+		ChannelName:      channel.Name,
+		IncludeThreads:   job.IncludeThreads,
+		IncludeFiles:     true,
+		IncludeReactions: true,
+		OutputFile:       outputFile,
+		Format:           job.Format,
+		Compression:      job.Compression,
+		SubtypePolicy:    job.SubtypePolicy,
+		PageSize:         job.PageSize,
+		ThreadDelay:      job.ThreadDelay,
+		Location:         job.Location,
+	}
+	if job.Incremental {
+		mu.Lock()
+		last, ok := state.Channels[channel.ID]
+		exports := state.Exports[channel.Name]
+		mu.Unlock()
+		// A chain that reached the retention policy is closed with a new
+		// full export, so the old chain can be pruned
+		if ok && (remote || !chainComplete(job.OutputDir, channel.Name, exports, job.Retention(), runStart)) {
+			options.DateFrom = &last
+		}
+	}
+
+	// Repeated incremental runs report edits and deletions of messages
+	// exported earlier
+	trackChanges := job.Incremental && !remote
+	if trackChanges {
+		options.TrackChanges = true
+		if history := s.loadHistory(job.OutputDir, channel.ID); history != nil {
+			options.Baseline = history.Messages
+			options.BaselineTaken = history.TakenAt
+		}
+	}
+
+	exportResult, err := s.exportService.ExportChannel(context.Background(), options, EventSinks{NewLogSink(s.logger.With("channel", name)), events})
+	if err != nil {
+		result.Error = err.Error()
+		result.ErrorCategory = models.ErrorCategoryOf(err)
+		return result, nil
+	}
+
+	result.OutputFile = exportResult.OutputFile
+	result.Messages = exportResult.Statistics.TotalMessages
+	result.FileSize = exportResult.FileSize
+	result.Duration = exportResult.Duration
+	if exportResult.Changes != nil {
+		result.Deleted = len(exportResult.Changes.Deleted)
+		result.Edited = len(exportResult.Changes.Edited)
+	}
+	if trackChanges && exportResult.Snapshot != nil {
+		history := &messageHistory{TakenAt: runStart, Messages: exportResult.Snapshot}
+		if err := s.saveHistory(job.OutputDir, channel.ID, history); err != nil {
+			result.Error = err.Error()
+			result.ErrorCategory = models.ErrorCategoryIO
+		}
+	}
+
+	mu.Lock()
+	state.Channels[channel.ID] = runStart
+	if !remote {
+		if file, err := filepath.Rel(job.OutputDir, exportResult.OutputFile); err == nil {
+			state.record(channel.Name, backupExport{File: file, Delta: options.DateFrom != nil})
+		}
+	}
+	exports := append([]backupExport(nil), state.Exports[channel.Name]...)
+	mu.Unlock()
+
+	if policy := job.Retention(); !policy.Empty() && !remote {
+		removed, err := job.prune(channel.Name, policy, runStart, false, exports)
+		if err != nil {
+			result.Error = fmt.Sprintf("rotation failed: %v", err)
+			result.ErrorCategory = models.ErrorCategoryIO
+		}
+		result.Removed = removed
+		mu.Lock()
+		state.forget(job.OutputDir, channel.Name, removed)
+		mu.Unlock()
+	}
+
+	return result, nil
+}
+
+// loadState reads the incremental export state, returning an empty state if none exists
+func (s *BackupService) loadState(dir string) *backupState {
+	return readBackupState(dir)
+}
+
+// readBackupState reads the backup state of dir, returning an empty state if
+// none exists
+func readBackupState(dir string) *backupState {
+	state := &backupState{Channels: make(map[string]time.Time)}
+
+	data, err := os.ReadFile(filepath.Join(dir, stateFileName))
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, state); err != nil || state.Channels == nil {
+		state.Channels = make(map[string]time.Time)
+	}
+
+	return state
+}
+
+// saveState persists the incremental export state
+func (s *BackupService) saveState(dir string, state *backupState) error {
+	return writeBackupState(dir, state)
+}
+
+// writeBackupState persists the backup state of dir
+func writeBackupState(dir string, state *backupState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup state: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, stateFileName), data, 0644); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to write backup state", err)
+	}
+
+	return nil
+}
+
+// loadHistory reads the message versions recorded for a channel, returning
+// nil if the channel was not tracked before
+func (s *BackupService) loadHistory(dir, channelID string) *messageHistory {
+	data, err := os.ReadFile(filepath.Join(dir, historyFilePrefix+channelID+".json"))
+	if err != nil {
+		return nil
+	}
+	var history messageHistory
+	if err := json.Unmarshal(data, &history); err != nil || history.Messages == nil {
+		return nil
+	}
+	return &history
+}
+
+// saveHistory persists the message versions of a channel for the next run
+func (s *BackupService) saveHistory(dir, channelID string, history *messageHistory) error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message history: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, historyFilePrefix+channelID+".json"), data, 0600); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to write message history", err)
+	}
+
+	return nil
+}
+
+// RotateExports removes the oldest export files of a channel so that at most
+// keep files remain, returning the paths that were removed
+func RotateExports(dir, channelName string, keep int) ([]string, error) {
+	return PruneExports(dir, channelName, RetentionPolicy{KeepLast: keep}, time.Now(), false)
+}
+
+// PruneBackup applies the job's retention policy to the existing exports of
+// every channel without running an export. With dryRun set nothing is removed.
+func PruneBackup(job BackupJob, now time.Time, dryRun bool) (map[string][]string, error) {
+	if storage.IsRemote(job.OutputDir) {
+		return nil, fmt.Errorf("retention only applies to local destinations")
+	}
+	if job.OutputDir == "" {
+		job.OutputDir = "."
+	}
+
+	state := readBackupState(job.OutputDir)
+	pruned := make(map[string][]string)
+	var pruneErr error
+	for _, name := range job.Channels {
+		name = strings.TrimPrefix(name, "#")
+		files, err := job.prune(name, job.Retention(), now, dryRun, state.Exports[name])
+		if len(files) > 0 {
+			pruned[name] = files
+			state.forget(job.OutputDir, name, files)
+		}
+		if err != nil {
+			pruneErr = models.NewExportError(models.ErrorCategoryIO, "", err)
+			break
+		}
+	}
+	if !dryRun && len(pruned) > 0 {
+		if err := writeBackupState(job.OutputDir, state); err != nil && pruneErr == nil {
+			pruneErr = err
+		}
+	}
+	return pruned, pruneErr
+}
+
+// prune applies policy to the exports of a channel of the job. Exports named
+// by the job's output template are found through the exports recorded by
+// earlier runs; default file names are found in the output directory.
+func (job BackupJob) prune(channelName string, policy RetentionPolicy, now time.Time, dryRun bool, recorded []backupExport) ([]string, error) {
+	if job.OutputTemplate != "" {
+		files := recordedExports(job.OutputDir, recorded)
+		return pruneExports(job.OutputDir, channelName, files, policy, now, dryRun, recorded)
+	}
+	files, err := ListExports(job.OutputDir, channelName)
+	if err != nil {
+		return nil, err
+	}
+	return pruneExports(job.OutputDir, channelName, files, policy, now, dryRun, recorded)
+}
+
+// ListExports returns the export files of a channel in dir, oldest first
+func ListExports(dir, channelName string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output directory: %w", err)
+	}
+
+	prefix := exportPrefix(channelName)
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+
+	// File names embed a sortable timestamp
+	sort.Strings(files)
+
+	return files, nil
+}
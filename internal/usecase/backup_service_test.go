@@ -0,0 +1,341 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestBackupService_Run(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	service := NewBackupService(mockClient, "1.0.0-test")
+	dir := t.TempDir()
+
+	job := BackupJob{
+		Channels:       []string{"#general", "missing"},
+		OutputDir:      dir,
+		Format:         "json",
+		IncludeThreads: true,
+		Incremental:    true,
+	}
+
+	results, err := service.Run(job)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Error != "" {
+		t.Errorf("Expected general to export successfully, got %s", results[0].Error)
+	}
+	if results[0].Messages != 4 { // 2 messages + 2 thread replies
+		t.Errorf("Expected 4 messages, got %d", results[0].Messages)
+	}
+	if _, err := os.Stat(results[0].OutputFile); err != nil {
+		t.Errorf("Expected output file to exist: %v", err)
+	}
+
+	if results[1].Error == "" {
+		t.Error("Expected error for missing channel")
+	}
+
+	// Incremental state should be recorded for the exported channel
+	state := service.loadState(dir)
+	if _, ok := state.Channels["C123456"]; !ok {
+		t.Error("Expected incremental state for C123456")
+	}
+}
+
+func TestBackupService_RunTracksChanges(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	service := NewBackupService(mockClient, "1.0.0-test")
+	job := BackupJob{
+		Channels:    []string{"general"},
+		OutputDir:   t.TempDir(),
+		Format:      "json",
+		Incremental: true,
+	}
+
+	if _, err := service.Run(job); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Edit the first message and delete the second before the next run
+	mockClient.messages[0].Text = "Hello everyone, edited"
+	mockClient.messages = mockClient.messages[:1]
+
+	// Both messages are older than the last run, so this run does not fetch
+	// them and must not report them
+	results, err := service.Run(job)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if results[0].Edited != 0 || results[0].Deleted != 0 {
+		t.Errorf("Expected no changes outside the fetched range, got %+v", results[0])
+	}
+	history := service.loadHistory(job.OutputDir, "C123456")
+	if history == nil || len(history.Messages) != 2 {
+		t.Fatalf("Expected history to keep the messages not fetched, got %+v", history)
+	}
+
+	// Move the watermark back so the next run fetches both again
+	state := service.loadState(job.OutputDir)
+	state.Channels["C123456"] = time.Unix(1704067000, 0)
+	if err := service.saveState(job.OutputDir, state); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	results, err = service.Run(job)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if results[0].Edited != 1 || results[0].Deleted != 1 {
+		t.Errorf("Expected 1 edited and 1 deleted message, got %+v", results[0])
+	}
+
+	history = service.loadHistory(job.OutputDir, "C123456")
+	if history == nil || history.Messages["1704067200.123456"].Text != "Hello everyone, edited" {
+		t.Errorf("Expected history to record the edited text, got %+v", history)
+	}
+}
+
+func TestBackupService_RunIncrementalRetention(t *testing.T) {
+	service := NewBackupService(NewMockSlackClient(), "1.0.0-test")
+	now := time.Date(2024, 3, 1, 2, 0, 0, 0, time.Local)
+	service.now = func() time.Time { return now }
+	dir := t.TempDir()
+	job := BackupJob{
+		Channels:    []string{"general"},
+		OutputDir:   dir,
+		Format:      "json",
+		Incremental: true,
+		Keep:        2,
+	}
+
+	var removed []string
+	for run := 0; run < 6; run++ {
+		results, err := service.Run(job)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if results[0].Error != "" {
+			t.Fatalf("Run %d failed: %s", run+1, results[0].Error)
+		}
+		removed = append(removed, results[0].Removed...)
+		now = now.AddDate(0, 0, 1)
+	}
+
+	// Every second run starts a new chain, so the older chains expire
+	if len(removed) != 4 {
+		t.Errorf("Expected the two oldest chains to be removed, got %v", removed)
+	}
+	remaining, err := ListExports(dir, "general")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 2 || filepath.Base(remaining[0]) != "general-export-20240305-020000.json" {
+		t.Errorf("Expected the newest full export and its delta to remain, got %v", remaining)
+	}
+	exports := readBackupState(dir).Exports["general"]
+	if len(exports) != 2 || exports[0].Delta || !exports[1].Delta {
+		t.Errorf("Expected the state to record the remaining chain, got %+v", exports)
+	}
+}
+
+func TestBackupService_RunParallel(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	mockClient.channels = append(mockClient.channels, models.Channel{ID: "C654321", Name: "random"})
+	service := NewBackupService(mockClient, "1.0.0-test")
+	dir := t.TempDir()
+
+	job := BackupJob{
+		Channels:    []string{"general", "missing", "random"},
+		OutputDir:   dir,
+		Format:      "json",
+		Incremental: true,
+		Concurrency: 3,
+	}
+
+	results, err := service.Run(job)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for i, name := range job.Channels {
+		if results[i].Channel != name {
+			t.Errorf("Expected result %d for #%s, got #%s", i, name, results[i].Channel)
+		}
+	}
+	if results[0].Error != "" || results[2].Error != "" {
+		t.Errorf("Expected general and random to export, got %+v", results)
+	}
+	if results[1].Error == "" {
+		t.Error("Expected error for missing channel")
+	}
+
+	state := service.loadState(dir)
+	for _, id := range []string{"C123456", "C654321"} {
+		if _, ok := state.Channels[id]; !ok {
+			t.Errorf("Expected incremental state for %s", id)
+		}
+	}
+}
+
+func TestBackupService_RunRequiresChannels(t *testing.T) {
+	service := NewBackupService(NewMockSlackClient(), "1.0.0-test")
+
+	if _, err := service.Run(BackupJob{OutputDir: t.TempDir()}); err == nil {
+		t.Error("Expected error when no channels are configured")
+	}
+}
+
+func TestRotateExports(t *testing.T) {
+	dir := t.TempDir()
+
+	names := []string{
+		"general-export-20240101-020000.json",
+		"general-export-20240102-020000.json",
+		"general-export-20240103-020000.json.gz",
+		"general-chat-export-20240101-020000.json", // different channel
+		"notes.txt",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := RotateExports(dir, "general", 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(removed) != 1 || filepath.Base(removed[0]) != "general-export-20240101-020000.json" {
+		t.Errorf("Expected oldest general export to be removed, got %v", removed)
+	}
+
+	remaining, _ := ListExports(dir, "general")
+	if len(remaining) != 2 {
+		t.Errorf("Expected 2 remaining exports, got %d", len(remaining))
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "general-chat-export-20240101-020000.json")); err != nil {
+		t.Error("Expected other channel's export to be kept")
+	}
+}
+
+func TestPruneExportsKeepsDeltaChains(t *testing.T) {
+	dir := t.TempDir()
+
+	names := []string{
+		"general-export-20240101-020000.json", // full
+		"general-export-20240102-020000.json", // delta
+		"general-export-20240103-020000.json", // full
+		"general-export-20240104-020000.json", // delta
+		"general-export-20240105-020000.json", // delta
+	}
+	state := &backupState{Channels: map[string]time.Time{}}
+	for i, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		state.record("general", backupExport{File: name, Delta: i%2 == 1 || i == 4})
+	}
+	if err := writeBackupState(dir, state); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keeping the last two files keeps the whole chain they extend
+	pruned, err := PruneExports(dir, "general", RetentionPolicy{KeepLast: 2}, time.Now(), false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pruned) != 2 || filepath.Base(pruned[0]) != names[0] || filepath.Base(pruned[1]) != names[1] {
+		t.Errorf("Expected the first chain to be removed, got %v", pruned)
+	}
+	if remaining, _ := ListExports(dir, "general"); len(remaining) != 3 {
+		t.Errorf("Expected the full export of the kept deltas to remain, got %v", remaining)
+	}
+}
+
+func TestPruneBackupOutputTemplate(t *testing.T) {
+	dir := t.TempDir()
+
+	state := &backupState{Channels: map[string]time.Time{}}
+	names := []string{"2024/01/general.json", "2024/02/general.json", "2024/03/general.json"}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		state.record("general", backupExport{File: name})
+	}
+	if err := writeBackupState(dir, state); err != nil {
+		t.Fatal(err)
+	}
+
+	job := BackupJob{Channels: []string{"general"}, OutputDir: dir, OutputTemplate: "{{.Date}}/{{.Channel}}.json", Keep: 2}
+	pruned, err := PruneBackup(job, time.Now(), false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pruned["general"]) != 1 || pruned["general"][0] != filepath.Join(dir, names[0]) {
+		t.Errorf("Expected the oldest recorded export to be removed, got %v", pruned)
+	}
+	if exports := readBackupState(dir).Exports["general"]; len(exports) != 2 {
+		t.Errorf("Expected the removed export to leave the state, got %v", exports)
+	}
+}
+
+func TestPruneExports(t *testing.T) {
+	dir := t.TempDir()
+
+	names := []string{
+		"general-export-20230101-020000.json",
+		"general-export-20240101-020000.json",
+		"general-export-20240301-020000.json",
+		"general-export-20240302-020000.json.gz",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)
+
+	// keep_days removes exports older than the cutoff; dry runs delete nothing
+	pruned, err := PruneExports(dir, "general", RetentionPolicy{KeepDays: 30}, now, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pruned) != 2 {
+		t.Errorf("Expected 2 expired exports, got %v", pruned)
+	}
+	if remaining, _ := ListExports(dir, "general"); len(remaining) != 4 {
+		t.Errorf("Expected dry run to keep all files, got %d", len(remaining))
+	}
+
+	// Both limits apply; the newest export always survives
+	pruned, err = PruneExports(dir, "general", RetentionPolicy{KeepLast: 3, KeepDays: 1}, now, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pruned) != 3 {
+		t.Errorf("Expected 3 removed exports, got %v", pruned)
+	}
+	remaining, _ := ListExports(dir, "general")
+	if len(remaining) != 1 || filepath.Base(remaining[0]) != "general-export-20240302-020000.json.gz" {
+		t.Errorf("Expected only the newest export to remain, got %v", remaining)
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// BotClientInterface defines the Slack API operation needed to describe the
+// bots and apps that posted messages
+type BotClientInterface interface {
+	GetBots(ctx context.Context, botIDs []string) (map[string]models.Bot, error)
+}
+
+// SetBotClient adds a directory of the bots that posted messages to exports
+// and names bot messages that carry only a bot ID
+func (s *ExportService) SetBotClient(client BotClientInterface) {
+	s.botClient = client
+	s.botScopeMissing.Store(false)
+}
+
+// collectBots looks up the bots that posted messages or replies, by bot ID,
+// with one GetBots call, and names the bot messages that carry only a bot
+// ID. The bots found before a failed lookup are kept.
+func (s *ExportService) collectBots(ctx context.Context, messages []models.ExportMessage) (map[string]models.Bot, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	var walk func([]models.ExportMessage, func(*models.ExportMessage))
+	walk = func(msgs []models.ExportMessage, visit func(*models.ExportMessage)) {
+		for i := range msgs {
+			if msgs[i].BotID != "" {
+				visit(&msgs[i])
+			}
+			walk(msgs[i].Replies, visit)
+		}
+	}
+	walk(messages, func(msg *models.ExportMessage) {
+		if !seen[msg.BotID] {
+			seen[msg.BotID] = true
+			ids = append(ids, msg.BotID)
+		}
+	})
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	bots, err := s.botClient.GetBots(ctx, ids)
+	walk(messages, func(msg *models.ExportMessage) {
+		bot, ok := bots[msg.BotID]
+		if !ok {
+			return
+		}
+		if msg.Username == "" {
+			msg.Username = bot.Name
+		}
+		if workflow := msg.Workflow; workflow != nil {
+			if workflow.Name == "" {
+				workflow.Name = bot.Name
+			}
+			if workflow.AppID == "" {
+				workflow.AppID = bot.AppID
+			}
+		}
+	})
+	if len(bots) == 0 {
+		return nil, err
+	}
+	return bots, err
+}
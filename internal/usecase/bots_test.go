@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockBotClient returns fixed bots and counts the lookups
+type MockBotClient struct {
+	bots  map[string]models.Bot
+	err   error
+	calls int
+}
+
+func (m *MockBotClient) GetBots(ctx context.Context, botIDs []string) (map[string]models.Bot, error) {
+	m.calls++
+	bots := make(map[string]models.Bot)
+	for _, id := range botIDs {
+		bot, ok := m.bots[id]
+		if !ok {
+			return bots, cmp.Or(m.err, errors.New("bot_not_found"))
+		}
+		bots[id] = bot
+	}
+	return bots, nil
+}
+
+func TestExportService_collectBots(t *testing.T) {
+	client := &MockBotClient{bots: map[string]models.Bot{
+		"B1": {ID: "B1", Name: "deploybot", AppID: "A1", Image48: "https://example.com/deploy.png"},
+	}}
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	service.SetBotClient(client)
+
+	messages := []models.ExportMessage{
+		{Text: "human"},
+		{Text: "deployed", BotID: "B1"},
+		{Text: "parent", Replies: []models.ExportMessage{
+			{Text: "custom name", BotID: "B1", Username: "Release train"},
+			{Text: "standup", BotID: "B1", Workflow: &models.Workflow{BotID: "B1", EventType: "standup_posted"}},
+		}},
+	}
+	bots, err := service.collectBots(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(bots) != 1 || bots["B1"].Image48 == "" {
+		t.Errorf("Expected the bot with its icon, got %v", bots)
+	}
+	if client.calls != 1 {
+		t.Errorf("Expected one lookup for all bots, got %d", client.calls)
+	}
+	if msg := messages[1]; msg.Username != "deploybot" || msg.Workflow != nil {
+		t.Errorf("Expected the bot name on the message, got %+v", msg)
+	}
+	if name := messages[2].Replies[0].Username; name != "Release train" {
+		t.Errorf("Expected the posted username to be kept, got %q", name)
+	}
+	if workflow := messages[2].Replies[1].Workflow; workflow.Name != "deploybot" || workflow.AppID != "A1" {
+		t.Errorf("Expected the bot name and app on the workflow, got %+v", workflow)
+	}
+
+	client = &MockBotClient{bots: map[string]models.Bot{"B1": {ID: "B1", Name: "deploybot"}}}
+	service.SetBotClient(client)
+	messages = []models.ExportMessage{
+		{BotID: "B1"},
+		{BotID: "B2"},
+	}
+	bots, err = service.collectBots(context.Background(), messages)
+	if err == nil || len(bots) != 1 || messages[0].Username != "deploybot" {
+		t.Errorf("Expected the lookup error with the bots found before it, got %v, %v", bots, err)
+	}
+}
+
+func TestExportService_BotsMissingScope(t *testing.T) {
+	client := NewMockSlackClient()
+	client.messages = append(client.messages, models.Message{Type: "message", Timestamp: "1704067400.000000", BotID: "B1"})
+	bots := &MockBotClient{err: models.NewExportError(models.ErrorCategoryAuth, "bots.info requires the bots:read scope", models.ErrMissingScope)}
+	service := NewExportService(client, "1.0.0-test")
+	service.SetBotClient(bots)
+
+	for i := 0; i < 2; i++ {
+		result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+			ChannelID:  "C123456",
+			OutputFile: filepath.Join(t.TempDir(), "general.json"),
+			Format:     "json",
+		}, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Partial {
+			t.Error("Expected a missing bots:read scope not to mark the export partial")
+		}
+		if notes := len(result.Warnings); i == 0 && notes != 1 || i == 1 && notes != 0 {
+			t.Errorf("Expected one notice for the first export only, got %q in export %d", result.Warnings, i+1)
+		}
+	}
+	if bots.calls != 1 {
+		t.Errorf("Expected later exports to skip the lookups, got %d calls", bots.calls)
+	}
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// CanvasClientInterface defines the Slack API operation needed to export
+// channel canvases
+type CanvasClientInterface interface {
+	GetChannelCanvases(ctx context.Context, channelID string) ([]models.ExportCanvas, []string, error)
+}
+
+// SetCanvasClient enables ExportOptions.IncludeCanvas
+func (s *ExportService) SetCanvasClient(client CanvasClientInterface) {
+	s.canvasClient = client
+}
+
+// collectCanvases fetches the canvases and posts of a channel, with a
+// warning for each canvas that could not be read. The error is for a channel
+// whose canvases could not be listed.
+func (s *ExportService) collectCanvases(ctx context.Context, channelID string) ([]models.ExportCanvas, []string, error) {
+	if s.canvasClient == nil {
+		return nil, nil, fmt.Errorf("no canvas client configured")
+	}
+	return s.canvasClient.GetChannelCanvases(ctx, channelID)
+}
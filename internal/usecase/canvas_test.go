@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockCanvasClient returns fixed canvases, warnings and an optional error
+type MockCanvasClient struct {
+	canvases []models.ExportCanvas
+	warnings []string
+	err      error
+}
+
+func (m *MockCanvasClient) GetChannelCanvases(ctx context.Context, channelID string) ([]models.ExportCanvas, []string, error) {
+	return m.canvases, m.warnings, m.err
+}
+
+func TestExportService_IncludeCanvas(t *testing.T) {
+	tests := []struct {
+		name        string
+		client      *MockCanvasClient
+		wantPartial bool
+	}{
+		{"all canvases", &MockCanvasClient{canvases: []models.ExportCanvas{{ID: "F1", ChannelCanvas: true, Markdown: "# Welcome"}}}, false},
+		{"failed canvas", &MockCanvasClient{canvases: []models.ExportCanvas{{ID: "F1", Markdown: "# Welcome"}}, warnings: []string{"canvas F2: failed to download: status 403"}}, true},
+		{"unlisted canvases", &MockCanvasClient{canvases: []models.ExportCanvas{{ID: "F1", Markdown: "# Welcome"}}, err: fmt.Errorf("failed to list canvases")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+			service.SetCanvasClient(tt.client)
+
+			result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+				ChannelID:     "C123456",
+				OutputFile:    filepath.Join(t.TempDir(), "general.json"),
+				Format:        "json",
+				IncludeCanvas: true,
+			}, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			export, err := ReadExportFile(result.OutputFile)
+			if err != nil {
+				t.Fatalf("Failed to read export: %v", err)
+			}
+
+			if len(export.Canvases) != 1 || export.Canvases[0].Markdown != "# Welcome" {
+				t.Errorf("Expected the canvas in the export, got %+v", export.Canvases)
+			}
+			if export.ExportInfo.Partial != tt.wantPartial {
+				t.Errorf("Expected partial=%v, got %v", tt.wantPartial, export.ExportInfo.Partial)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"sort"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// tombstoneSubtype marks a deleted thread parent that Slack keeps so its
+// replies remain reachable
+const tombstoneSubtype = "tombstone"
+
+// snapshotMessages records the current version of every top-level message,
+// keyed by timestamp. Tombstones are left out so they count as deleted.
+func snapshotMessages(messages []models.Message) map[string]models.MessageVersion {
+	snapshot := make(map[string]models.MessageVersion, len(messages))
+	for _, msg := range messages {
+		if msg.Subtype == tombstoneSubtype {
+			continue
+		}
+		version := models.MessageVersion{User: msg.User, Text: msg.Text}
+		if msg.Edited != nil {
+			version.Edited = msg.Edited.Timestamp
+		}
+		snapshot[msg.Timestamp] = version
+	}
+	return snapshot
+}
+
+// snapshotWindow is the range of message timestamps an export fetched.
+// Messages outside it were not looked at, so they cannot count as deleted or
+// edited. An empty bound leaves that side open.
+type snapshotWindow struct {
+	oldest string
+	latest string
+}
+
+// fetchedWindow returns the window of messages, fetched for options. When a
+// limit stopped the history early, only messages from the oldest fetched one
+// on were seen. It reports false when nothing of the history was seen.
+func fetchedWindow(options models.ExportOptions, messages []models.Message, truncated bool) (snapshotWindow, bool) {
+	var window snapshotWindow
+	if options.DateFrom != nil {
+		window.oldest = FormatSlackTimestamp(*options.DateFrom)
+	}
+	if options.DateTo != nil {
+		window.latest = FormatSlackTimestamp(*options.DateTo)
+	}
+	if truncated {
+		if len(messages) == 0 {
+			return window, false
+		}
+		if oldest := messages[0].Timestamp; oldest > window.oldest {
+			window.oldest = oldest
+		}
+	}
+	return window, true
+}
+
+// contains reports whether the message at ts lies in the window
+func (w snapshotWindow) contains(ts string) bool {
+	return ts >= w.oldest && (w.latest == "" || ts <= w.latest)
+}
+
+// mergeSnapshots returns the versions of baseline outside window updated
+// with current, the versions fetched in it
+func mergeSnapshots(baseline, current map[string]models.MessageVersion, window snapshotWindow) map[string]models.MessageVersion {
+	merged := make(map[string]models.MessageVersion, len(baseline)+len(current))
+	for ts, version := range baseline {
+		if !window.contains(ts) {
+			merged[ts] = version
+		}
+	}
+	for ts, version := range current {
+		merged[ts] = version
+	}
+	return merged
+}
+
+// diffSnapshots reports the messages of baseline in window that were deleted
+// or whose text changed in current. Messages new in current are not changes.
+func diffSnapshots(baseline, current map[string]models.MessageVersion, window snapshotWindow) *models.ExportChanges {
+	changes := &models.ExportChanges{}
+
+	for ts, previous := range baseline {
+		if !window.contains(ts) {
+			continue
+		}
+		version, ok := current[ts]
+		if !ok {
+			changes.Deleted = append(changes.Deleted, models.DeletedChange{
+				Timestamp: ts,
+				User:      previous.User,
+				Text:      previous.Text,
+			})
+			continue
+		}
+		if version.Text != previous.Text {
+			changes.Edited = append(changes.Edited, models.EditedChange{
+				Timestamp:    ts,
+				User:         version.User,
+				PreviousText: previous.Text,
+				Text:         version.Text,
+				EditedAt:     version.Edited,
+			})
+		}
+	}
+
+	sort.Slice(changes.Deleted, func(i, j int) bool {
+		return changes.Deleted[i].Timestamp < changes.Deleted[j].Timestamp
+	})
+	sort.Slice(changes.Edited, func(i, j int) bool {
+		return changes.Edited[i].Timestamp < changes.Edited[j].Timestamp
+	})
+
+	return changes
+}
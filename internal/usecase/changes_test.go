@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	baseline := snapshotMessages([]models.Message{
+		{User: "U1", Text: "kept", Timestamp: "1704067200.000000"},
+		{User: "U1", Text: "original", Timestamp: "1704067201.000000"},
+		{User: "U2", Text: "removed", Timestamp: "1704067202.000000"},
+		{User: "U2", Text: "parent", Timestamp: "1704067203.000000"},
+	})
+	current := snapshotMessages([]models.Message{
+		{User: "U1", Text: "kept", Timestamp: "1704067200.000000"},
+		{User: "U1", Text: "changed", Timestamp: "1704067201.000000", Edited: &models.Edited{User: "U1", Timestamp: "1704067300.000000"}},
+		{Text: "This message was deleted.", Timestamp: "1704067203.000000", Subtype: "tombstone"},
+		{User: "U3", Text: "new", Timestamp: "1704067204.000000"},
+	})
+
+	changes := diffSnapshots(baseline, current, snapshotWindow{})
+
+	if len(changes.Edited) != 1 {
+		t.Fatalf("Expected 1 edited message, got %d", len(changes.Edited))
+	}
+	edited := changes.Edited[0]
+	if edited.PreviousText != "original" || edited.Text != "changed" || edited.EditedAt != "1704067300.000000" {
+		t.Errorf("Unexpected edit record: %+v", edited)
+	}
+
+	if len(changes.Deleted) != 2 {
+		t.Fatalf("Expected 2 deleted messages, got %d", len(changes.Deleted))
+	}
+	if changes.Deleted[0].Text != "removed" || changes.Deleted[1].Text != "parent" {
+		t.Errorf("Expected removed message and tombstoned parent, got %+v", changes.Deleted)
+	}
+}
+
+func TestDiffSnapshotsWindow(t *testing.T) {
+	baseline := snapshotMessages([]models.Message{
+		{User: "U1", Text: "old", Timestamp: "1704067100.000000"},
+		{User: "U1", Text: "original", Timestamp: "1704067200.000000"},
+		{User: "U2", Text: "removed", Timestamp: "1704067300.000000"},
+	})
+	current := snapshotMessages([]models.Message{
+		{User: "U1", Text: "changed", Timestamp: "1704067200.000000"},
+		{User: "U3", Text: "new", Timestamp: "1704067400.000000"},
+	})
+
+	// A capped export fetched only from its oldest message on
+	from := time.Unix(1704067000, 0)
+	window, ok := fetchedWindow(models.ExportOptions{DateFrom: &from}, []models.Message{{Timestamp: "1704067200.000000"}}, true)
+	if !ok || window.oldest != "1704067200.000000" {
+		t.Fatalf("Expected window from the oldest fetched message, got %+v", window)
+	}
+
+	changes := diffSnapshots(baseline, current, window)
+	if len(changes.Edited) != 1 || changes.Edited[0].Timestamp != "1704067200.000000" {
+		t.Errorf("Expected the edit in the window, got %+v", changes.Edited)
+	}
+	if len(changes.Deleted) != 1 || changes.Deleted[0].Text != "removed" {
+		t.Errorf("Expected only the deletion in the window, got %+v", changes.Deleted)
+	}
+
+	merged := mergeSnapshots(baseline, current, window)
+	if len(merged) != 3 || merged["1704067100.000000"].Text != "old" || merged["1704067200.000000"].Text != "changed" {
+		t.Errorf("Expected messages outside the window to be carried over, got %+v", merged)
+	}
+	if _, ok := merged["1704067300.000000"]; ok {
+		t.Error("Expected the deleted message to be dropped from the snapshot")
+	}
+
+	if _, ok := fetchedWindow(models.ExportOptions{}, nil, true); ok {
+		t.Error("Expected no window when a limit stopped the export before any message")
+	}
+}
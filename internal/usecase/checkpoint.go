@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// Infixes of the files an interrupted export leaves behind
+const (
+	partialInfix    = ".partial"
+	checkpointInfix = ".checkpoint"
+)
+
+// PartialFileName returns where an interrupted export writes what it fetched:
+// general.json becomes general.partial.json. Stdout stays stdout.
+func PartialFileName(outputFile string) string {
+	if outputFile == StdoutOutput {
+		return outputFile
+	}
+	base, ext := splitExportExt(outputFile)
+	return base + partialInfix + ext
+}
+
+// CheckpointFileName returns the checkpoint file of an export to outputFile:
+// general.json becomes general.checkpoint.json
+func CheckpointFileName(outputFile string) string {
+	base, _ := splitExportExt(outputFile)
+	return base + checkpointInfix + ".json"
+}
+
+// splitExportExt splits outputFile into its name and its extension,
+// including a trailing .gz
+func splitExportExt(outputFile string) (string, string) {
+	name, gz := strings.CutSuffix(outputFile, ".gz")
+	slash := strings.LastIndexAny(name, `/\`)
+	dot := strings.LastIndex(name, ".")
+	if dot <= slash+1 {
+		dot = len(name)
+	}
+	ext := name[dot:]
+	if gz {
+		ext += ".gz"
+	}
+	return name[:dot], ext
+}
+
+// ReadCheckpoint loads the checkpoint of an interrupted export
+func ReadCheckpoint(path string) (*models.ExportCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, models.NewExportError(models.ErrorCategoryIO, "failed to read checkpoint", err)
+	}
+	var checkpoint models.ExportCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	return &checkpoint, nil
+}
+
+// writeCheckpoint records where an interrupted export of channelID to
+// outputFile stopped and returns the checkpoint path. messages are the
+// messages fetched so far, before the user and text filters.
+func writeCheckpoint(channelID, outputFile, partialFile string, messages []models.Message, limits *exportLimits) (string, error) {
+	checkpoint := models.ExportCheckpoint{
+		ChannelID:      channelID,
+		OutputFile:     outputFile,
+		PartialFile:    partialFile,
+		InterruptedAt:  time.Now().UTC(),
+		Cursor:         limits.cursor,
+		PendingThreads: limits.pendingThreads,
+		Messages:       messages,
+	}
+	path := CheckpointFileName(outputFile)
+	_, err := writeFileAtomic(path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(checkpoint)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return path, nil
+}
+
+// resumedThreads returns the threads whose replies a checkpoint already holds
+func resumedThreads(checkpoint *models.ExportCheckpoint) map[string]bool {
+	if checkpoint == nil {
+		return nil
+	}
+	pending := make(map[string]bool, len(checkpoint.PendingThreads))
+	for _, ts := range checkpoint.PendingThreads {
+		pending[ts] = true
+	}
+	fetched := make(map[string]bool)
+	for _, msg := range checkpoint.Messages {
+		if msg.ReplyCount > 0 && msg.ThreadTS != "" && !pending[msg.ThreadTS] {
+			fetched[msg.ThreadTS] = true
+		}
+	}
+	return fetched
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestPartialAndCheckpointFileName(t *testing.T) {
+	tests := []struct {
+		output, partial, checkpoint string
+	}{
+		{"general.json", "general.partial.json", "general.checkpoint.json"},
+		{"exports/general.json.gz", "exports/general.partial.json.gz", "exports/general.checkpoint.json"},
+		{"exports.d/general", "exports.d/general.partial", "exports.d/general.checkpoint.json"},
+		{StdoutOutput, StdoutOutput, ""},
+	}
+	for _, tt := range tests {
+		if got := PartialFileName(tt.output); got != tt.partial {
+			t.Errorf("PartialFileName(%q) = %q, want %q", tt.output, got, tt.partial)
+		}
+		if tt.checkpoint == "" {
+			continue
+		}
+		if got := CheckpointFileName(tt.output); got != tt.checkpoint {
+			t.Errorf("CheckpointFileName(%q) = %q, want %q", tt.output, got, tt.checkpoint)
+		}
+	}
+}
+
+func TestExportService_InterruptAndResume(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "general.json")
+	stop := make(chan struct{})
+	close(stop)
+
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:      "C123456",
+		OutputFile:     output,
+		Format:         "json",
+		IncludeThreads: true,
+		Stop:           stop,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Truncated == nil || result.Truncated.Reason != models.TruncatedByInterrupt {
+		t.Fatalf("Expected an interrupted export, got %+v", result.Truncated)
+	}
+	if result.OutputFile != filepath.Join(dir, "general.partial.json") {
+		t.Errorf("Expected the partial file, got %s", result.OutputFile)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("Expected no file under the final name, got %v", err)
+	}
+
+	checkpoint, err := ReadCheckpoint(result.Checkpoint)
+	if err != nil {
+		t.Fatalf("Expected a checkpoint, got %v", err)
+	}
+	if checkpoint.ChannelID != "C123456" || checkpoint.OutputFile != output || len(checkpoint.PendingThreads) != 1 {
+		t.Errorf("Unexpected checkpoint %+v", checkpoint)
+	}
+
+	// Resuming fetches the missing thread and writes the complete export
+	result, err = service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:      "C123456",
+		OutputFile:     checkpoint.OutputFile,
+		Format:         "json",
+		IncludeThreads: true,
+		Resume:         checkpoint,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Truncated != nil || result.OutputFile != output || result.Checkpoint != "" {
+		t.Errorf("Expected a complete export to %s, got %+v", output, result)
+	}
+	if result.Statistics.TotalReplies == 0 {
+		t.Error("Expected the resumed export to include thread replies")
+	}
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"math"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// ChannelComparison compares the activity of channels side by side, to
+// help decide whether they should be merged
+type ChannelComparison struct {
+	From     *time.Time        `json:"from,omitempty"`
+	Channels []ChannelActivity `json:"channels"`
+	// Overlap compares the participants of each pair of channels
+	Overlap []ParticipantOverlap `json:"overlap"`
+}
+
+// ChannelActivity measures the volume and responsiveness of one channel
+type ChannelActivity struct {
+	Channel   string `json:"channel"`
+	ChannelID string `json:"channel_id"`
+	Messages  int    `json:"messages"`
+	Replies   int    `json:"replies"`
+	// Days is the period the messages per day are spread over: since From,
+	// or from the first to the last message. Replies count as messages
+	// per day.
+	Days           int     `json:"days"`
+	MessagesPerDay float64 `json:"messages_per_day"`
+	ActiveDays     int     `json:"active_days"`
+	// Participants is the number of people who posted a message or reply;
+	// bots are not counted
+	Participants int `json:"participants"`
+	Threads      int `json:"threads"`
+	Answered     int `json:"answered"`
+	// FirstReply is the time from a message to the first reply by someone
+	// else, over answered threads
+	FirstReply models.DurationStats `json:"first_reply"`
+
+	participants map[string]bool
+}
+
+// ParticipantOverlap counts the participants two channels share
+type ParticipantOverlap struct {
+	Channels []string `json:"channels"`
+	Shared   int      `json:"shared"`
+	// OnlyFirst and OnlySecond post in one of the two channels only
+	OnlyFirst  int `json:"only_first"`
+	OnlySecond int `json:"only_second"`
+	// Similarity is Shared over the participants of either channel
+	Similarity float64 `json:"similarity"`
+}
+
+// CompareChannels compares the messages of exports posted since from (nil
+// = all), one export per channel. Times are measured against now, and days
+// are counted in its zone.
+func CompareChannels(exports []*models.ChannelExport, from *time.Time, now time.Time) *ChannelComparison {
+	comparison := &ChannelComparison{From: from, Channels: []ChannelActivity{}, Overlap: []ParticipantOverlap{}}
+	for _, export := range exports {
+		comparison.Channels = append(comparison.Channels, channelActivity(export, from, now))
+	}
+
+	for i := range comparison.Channels {
+		for j := i + 1; j < len(comparison.Channels); j++ {
+			a, b := comparison.Channels[i], comparison.Channels[j]
+			overlap := ParticipantOverlap{Channels: []string{a.Channel, b.Channel}}
+			for user := range a.participants {
+				if b.participants[user] {
+					overlap.Shared++
+				}
+			}
+			overlap.OnlyFirst = len(a.participants) - overlap.Shared
+			overlap.OnlySecond = len(b.participants) - overlap.Shared
+			if union := overlap.Shared + overlap.OnlyFirst + overlap.OnlySecond; union > 0 {
+				overlap.Similarity = float64(overlap.Shared) / float64(union)
+			}
+			comparison.Overlap = append(comparison.Overlap, overlap)
+		}
+	}
+	return comparison
+}
+
+// channelActivity measures the messages of export posted since from
+func channelActivity(export *models.ChannelExport, from *time.Time, now time.Time) ChannelActivity {
+	activity := ChannelActivity{
+		Channel:      export.Channel.Name,
+		ChannelID:    export.Channel.ID,
+		participants: make(map[string]bool),
+	}
+	days := make(map[string]bool)
+	var first, last time.Time
+	var firstReplies []time.Duration
+
+	count := func(msg models.ExportMessage) {
+		if first.IsZero() || msg.Timestamp.Before(first) {
+			first = msg.Timestamp
+		}
+		if msg.Timestamp.After(last) {
+			last = msg.Timestamp
+		}
+		days[msg.Timestamp.In(now.Location()).Format("2006-01-02")] = true
+		if msg.User != "" && !export.Users[msg.User].IsBot {
+			activity.participants[msg.User] = true
+		}
+	}
+
+	for _, msg := range export.Messages {
+		// Broadcast copies are counted as replies of their thread
+		if msg.InThread || from != nil && msg.Timestamp.Before(*from) {
+			continue
+		}
+		// An omitted parent counts by its thread; with its author unknown
+		// the thread cannot tell when it was answered
+		if !msg.Omitted {
+			activity.Messages++
+			count(msg)
+		}
+		if len(msg.Replies) == 0 {
+			continue
+		}
+		activity.Threads++
+		var answer time.Time
+		for _, reply := range msg.Replies {
+			activity.Replies++
+			count(reply)
+			if reply.User != msg.User && (answer.IsZero() || reply.Timestamp.Before(answer)) {
+				answer = reply.Timestamp
+			}
+		}
+		if !answer.IsZero() && !msg.Omitted {
+			activity.Answered++
+			firstReplies = append(firstReplies, answer.Sub(msg.Timestamp))
+		}
+	}
+
+	activity.ActiveDays = len(days)
+	activity.Participants = len(activity.participants)
+	activity.FirstReply = summarizeDurations(firstReplies)
+
+	if from != nil {
+		activity.Days = max(int(math.Ceil(now.Sub(*from).Hours()/24)), 1)
+	} else {
+		// Both the first and the last day count
+		activity.Days = int(last.Sub(first).Hours()/24) + 1
+	}
+	if activity.Messages > 0 {
+		activity.MessagesPerDay = float64(activity.Messages+activity.Replies) / float64(activity.Days)
+	}
+	return activity
+}
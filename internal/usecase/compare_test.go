@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestCompareChannels(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2024, 1, d, h, 0, 0, 0, time.UTC) }
+	support := &models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C1", Name: "support"},
+		Users:   map[string]models.ExportUser{"B1": {ID: "B1", IsBot: true}},
+		Messages: []models.ExportMessage{
+			{User: "U1", Timestamp: day(1, 9), Replies: []models.ExportMessage{
+				{User: "U1", Timestamp: day(1, 9)},
+				{User: "U2", Timestamp: day(1, 11)},
+			}},
+			{User: "U3", Timestamp: day(3, 9), Replies: []models.ExportMessage{{User: "U3", Timestamp: day(3, 10)}}},
+			{User: "B1", Timestamp: day(4, 9)},
+			// Broadcast copies are counted with their thread
+			{User: "U2", Timestamp: day(1, 11), InThread: true},
+		},
+	}
+	help := &models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C2", Name: "help"},
+		Messages: []models.ExportMessage{
+			{User: "U2", Timestamp: day(10, 9)},
+			{User: "U4", Timestamp: day(11, 9)},
+		},
+	}
+
+	comparison := CompareChannels([]*models.ChannelExport{support, help}, nil, day(20, 0))
+	if len(comparison.Channels) != 2 {
+		t.Fatalf("Expected two channels, got %+v", comparison.Channels)
+	}
+	got := comparison.Channels[0]
+	if got.Channel != "support" || got.Messages != 3 || got.Replies != 3 || got.Participants != 3 || got.ActiveDays != 3 {
+		t.Errorf("Unexpected activity of support: %+v", got)
+	}
+	if got.Threads != 2 || got.Answered != 1 || got.FirstReply.Median != 2*time.Hour {
+		t.Errorf("Expected one thread answered after 2h, got %+v", got)
+	}
+	if got.Days != 4 || got.MessagesPerDay != 1.5 {
+		t.Errorf("Expected 6 messages over 4 days, got %d days and %.2f per day", got.Days, got.MessagesPerDay)
+	}
+
+	if len(comparison.Overlap) != 1 {
+		t.Fatalf("Expected one pair, got %+v", comparison.Overlap)
+	}
+	overlap := comparison.Overlap[0]
+	if overlap.Shared != 1 || overlap.OnlyFirst != 2 || overlap.OnlySecond != 1 || overlap.Similarity != 0.25 {
+		t.Errorf("Expected U2 shared of four participants, got %+v", overlap)
+	}
+
+	// A period spreads the messages over its days
+	from := day(10, 0)
+	comparison = CompareChannels([]*models.ChannelExport{support, help}, &from, day(20, 0))
+	if support := comparison.Channels[0]; support.Messages != 0 || support.MessagesPerDay != 0 {
+		t.Errorf("Expected no support messages since the 10th, got %+v", support)
+	}
+	if help := comparison.Channels[1]; help.Days != 10 || help.MessagesPerDay != 0.2 {
+		t.Errorf("Expected 2 messages over 10 days, got %+v", help)
+	}
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// MessageCursor remembers the newest message of a channel a script has seen,
+// so that the next run only reports messages posted after it
+type MessageCursor struct {
+	ChannelID string    `json:"channel_id"`
+	Latest    string    `json:"latest_ts"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoadMessageCursor reads the cursor file at path. It returns nil without an
+// error when the file does not exist yet.
+func LoadMessageCursor(path string) (*MessageCursor, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cursor file: %w", err)
+	}
+	var cursor MessageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor file '%s': %w", path, err)
+	}
+	return &cursor, nil
+}
+
+// Advance moves the cursor to ts if it is newer and reports whether it moved
+func (c *MessageCursor) Advance(ts string) bool {
+	if ts <= c.Latest {
+		return false
+	}
+	c.Latest = ts
+	return true
+}
+
+// Save writes the cursor to path. The file is replaced atomically so that a
+// script killed mid-write never leaves a broken cursor behind.
+func (c *MessageCursor) Save(path string) error {
+	c.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write cursor file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cursor file: %w", err)
+	}
+	return nil
+}
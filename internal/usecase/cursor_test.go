@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMessageCursor_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "general.cursor")
+
+	cursor, err := LoadMessageCursor(path)
+	if err != nil || cursor != nil {
+		t.Fatalf("Expected no cursor before the first run, got %+v (%v)", cursor, err)
+	}
+
+	cursor = &MessageCursor{ChannelID: "C123456"}
+	if !cursor.Advance("1704067260.000000") {
+		t.Error("Expected the cursor to advance")
+	}
+	if cursor.Advance("1704067200.123456") {
+		t.Error("Expected the cursor not to move backwards")
+	}
+	if err := cursor.Save(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded, err := LoadMessageCursor(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.ChannelID != "C123456" || loaded.Latest != "1704067260.000000" || loaded.UpdatedAt.IsZero() {
+		t.Errorf("Unexpected cursor %+v", loaded)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Expected no temporary file to be left behind")
+	}
+}
+
+func TestLoadMessageCursor_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.cursor")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMessageCursor(path); err == nil {
+		t.Error("Expected an error for an invalid cursor file")
+	}
+}
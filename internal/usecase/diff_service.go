@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// ExportDiff describes the differences between two exports of a channel
+type ExportDiff struct {
+	OldFile string `json:"old_file"`
+	NewFile string `json:"new_file"`
+	Channel string `json:"channel"`
+	// Window is the date range both exports cover, set when one of them is
+	// limited to a range, e.g. an incremental export. Messages outside it are
+	// not compared.
+	Window *models.DateRange `json:"window,omitempty"`
+
+	Added   []DiffMessage `json:"added"`
+	Removed []DiffMessage `json:"removed"`
+	Edited  []DiffEdit    `json:"edited"`
+
+	UsersAdded   []string     `json:"users_added"`
+	UsersRemoved []string     `json:"users_removed"`
+	UsersChanged []UserChange `json:"users_changed"`
+
+	Statistics StatisticsDelta `json:"statistics"`
+}
+
+// DiffMessage is a message present in only one of the exports
+type DiffMessage struct {
+	ID       string `json:"id"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+	User     string `json:"user"`
+	Text     string `json:"text"`
+}
+
+// DiffEdit is a message whose text differs between the exports
+type DiffEdit struct {
+	ID      string `json:"id"`
+	User    string `json:"user"`
+	OldText string `json:"old_text"`
+	NewText string `json:"new_text"`
+}
+
+// UserChange lists the profile fields of a user that changed
+type UserChange struct {
+	ID     string   `json:"id"`
+	Fields []string `json:"fields"`
+}
+
+// StatisticsDelta holds new minus old export statistics
+type StatisticsDelta struct {
+	Messages    int `json:"messages"`
+	Threads     int `json:"threads"`
+	Replies     int `json:"replies"`
+	Users       int `json:"users"`
+	Attachments int `json:"attachments"`
+	Files       int `json:"files"`
+	Reactions   int `json:"reactions"`
+}
+
+// Empty reports whether the exports contain the same messages and users
+func (d *ExportDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Edited) == 0 &&
+		len(d.UsersAdded) == 0 && len(d.UsersRemoved) == 0 && len(d.UsersChanged) == 0
+}
+
+// StdinInput is the input path that reads an export from standard input
+const StdinInput = "-"
+
+// ReadExportFile loads an export written by 'slacker export', transparently
+// decompressing gzip files and zip archives holding one export. The path "-"
+// reads standard input.
+func ReadExportFile(path string) (*models.ChannelExport, error) {
+	if path == StdinInput {
+		return ReadExport(os.Stdin, "stdin")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, models.NewExportError(models.ErrorCategoryIO, "failed to open export", err)
+	}
+	defer file.Close()
+	return ReadExport(file, path)
+}
+
+// ReadExport decodes an export from r, named name in errors. Gzip data and
+// zip archives are recognized by their header, so compressed exports can be
+// piped in.
+func ReadExport(r io.Reader, name string) (*models.ChannelExport, error) {
+	buffered := bufio.NewReader(r)
+	var reader io.Reader = buffered
+	if magic, err := buffered.Peek(4); err == nil && string(magic) == "PK\x03\x04" {
+		return readZipExport(buffered, name)
+	}
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip export %s: %w", name, err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	var export models.ChannelExport
+	if err := json.NewDecoder(reader).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse export %s: %w", name, err)
+	}
+	return &export, nil
+}
+
+// readZipExport reads the export in a zip archive, which must hold exactly one
+// .json or .json.gz file. Zip needs random access, so the archive is read
+// into memory.
+func readZipExport(r io.Reader, name string) (*models.ChannelExport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip export %s: %w", name, err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip export %s: %w", name, err)
+	}
+
+	var exports []*zip.File
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		if strings.HasSuffix(file.Name, ".json") || strings.HasSuffix(file.Name, ".json.gz") {
+			exports = append(exports, file)
+		}
+	}
+	if len(exports) != 1 {
+		return nil, fmt.Errorf("zip export %s must hold exactly one .json or .json.gz file, found %d", name, len(exports))
+	}
+
+	file, err := exports[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip export %s: %w", name, err)
+	}
+	defer file.Close()
+	return ReadExport(file, name+":"+exports[0].Name)
+}
+
+// DiffExports compares two exports. Thread replies are compared like
+// top-level messages. When either export is limited to a date range, only
+// messages in the range both cover are compared, so an incremental export
+// does not report the history before it as removed.
+func DiffExports(oldExport, newExport *models.ChannelExport) *ExportDiff {
+	diff := &ExportDiff{
+		Channel:      newExport.Channel.Name,
+		Added:        []DiffMessage{},
+		Removed:      []DiffMessage{},
+		Edited:       []DiffEdit{},
+		UsersAdded:   []string{},
+		UsersRemoved: []string{},
+		UsersChanged: []UserChange{},
+	}
+
+	oldMessages := indexExportMessages(oldExport.Messages)
+	newMessages := indexExportMessages(newExport.Messages)
+	if window := overlapWindow(oldExport.ExportInfo.DateRange, newExport.ExportInfo.DateRange); window != nil {
+		diff.Window = window
+		oldMessages = messagesInWindow(oldMessages, *window)
+		newMessages = messagesInWindow(newMessages, *window)
+	}
+
+	for id, msg := range newMessages {
+		previous, ok := oldMessages[id]
+		if !ok {
+			diff.Added = append(diff.Added, toDiffMessage(msg))
+			continue
+		}
+		if previous.Text != msg.Text {
+			diff.Edited = append(diff.Edited, DiffEdit{ID: id, User: msg.User, OldText: previous.Text, NewText: msg.Text})
+		}
+	}
+	for id, msg := range oldMessages {
+		if _, ok := newMessages[id]; !ok {
+			diff.Removed = append(diff.Removed, toDiffMessage(msg))
+		}
+	}
+
+	for id, user := range newExport.Users {
+		previous, ok := oldExport.Users[id]
+		if !ok {
+			diff.UsersAdded = append(diff.UsersAdded, id)
+			continue
+		}
+		if fields := changedUserFields(previous, user); len(fields) > 0 {
+			diff.UsersChanged = append(diff.UsersChanged, UserChange{ID: id, Fields: fields})
+		}
+	}
+	for id := range oldExport.Users {
+		if _, ok := newExport.Users[id]; !ok {
+			diff.UsersRemoved = append(diff.UsersRemoved, id)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].ID < diff.Added[j].ID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].ID < diff.Removed[j].ID })
+	sort.Slice(diff.Edited, func(i, j int) bool { return diff.Edited[i].ID < diff.Edited[j].ID })
+	sort.Strings(diff.UsersAdded)
+	sort.Strings(diff.UsersRemoved)
+	sort.Slice(diff.UsersChanged, func(i, j int) bool { return diff.UsersChanged[i].ID < diff.UsersChanged[j].ID })
+
+	oldStats, newStats := oldExport.Statistics, newExport.Statistics
+	diff.Statistics = StatisticsDelta{
+		Messages:    newStats.TotalMessages - oldStats.TotalMessages,
+		Threads:     newStats.TotalThreads - oldStats.TotalThreads,
+		Replies:     newStats.TotalReplies - oldStats.TotalReplies,
+		Users:       newStats.TotalUsers - oldStats.TotalUsers,
+		Attachments: newStats.TotalAttachments - oldStats.TotalAttachments,
+		Files:       newStats.TotalFiles - oldStats.TotalFiles,
+		Reactions:   newStats.TotalReactions - oldStats.TotalReactions,
+	}
+
+	return diff
+}
+
+// indexExportMessages maps message IDs to messages, including thread replies
+func indexExportMessages(messages []models.ExportMessage) map[string]models.ExportMessage {
+	index := make(map[string]models.ExportMessage)
+	for _, msg := range messages {
+		index[msg.ID] = msg
+		for _, reply := range msg.Replies {
+			index[reply.ID] = reply
+		}
+	}
+	return index
+}
+
+// overlapWindow returns the date range two exports both cover, or nil when
+// neither is limited to a range. Unset bounds are open.
+func overlapWindow(a, b models.DateRange) *models.DateRange {
+	if a.From == nil && a.To == nil && b.From == nil && b.To == nil {
+		return nil
+	}
+	window := models.DateRange{From: a.From, To: a.To}
+	if b.From != nil && (window.From == nil || b.From.After(*window.From)) {
+		window.From = b.From
+	}
+	if b.To != nil && (window.To == nil || b.To.Before(*window.To)) {
+		window.To = b.To
+	}
+	return &window
+}
+
+// messagesInWindow keeps the messages posted within window
+func messagesInWindow(messages map[string]models.ExportMessage, window models.DateRange) map[string]models.ExportMessage {
+	kept := make(map[string]models.ExportMessage, len(messages))
+	for id, msg := range messages {
+		if inDateRange(msg.Timestamp, window) {
+			kept[id] = msg
+		}
+	}
+	return kept
+}
+
+// inDateRange reports whether t falls within the bounds of window
+func inDateRange(t time.Time, window models.DateRange) bool {
+	return (window.From == nil || !t.Before(*window.From)) && (window.To == nil || !t.After(*window.To))
+}
+
+// toDiffMessage reduces a message to the fields shown in a diff
+func toDiffMessage(msg models.ExportMessage) DiffMessage {
+	return DiffMessage{ID: msg.ID, ThreadTS: msg.ThreadTimestamp, User: msg.User, Text: msg.Text}
+}
+
+// changedUserFields names the user fields that differ between two versions
+func changedUserFields(oldUser, newUser models.ExportUser) []string {
+	var fields []string
+	if oldUser.Name != newUser.Name {
+		fields = append(fields, "name")
+	}
+	if oldUser.RealName != newUser.RealName {
+		fields = append(fields, "real_name")
+	}
+	if oldUser.Profile.DisplayName != newUser.Profile.DisplayName {
+		fields = append(fields, "display_name")
+	}
+	if oldUser.Profile.Email != newUser.Profile.Email {
+		fields = append(fields, "email")
+	}
+	if oldUser.Deleted != newUser.Deleted {
+		fields = append(fields, "deleted")
+	}
+	if oldUser.IsAdmin != newUser.IsAdmin {
+		fields = append(fields, "is_admin")
+	}
+	return fields
+}
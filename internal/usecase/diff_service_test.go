@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestDiffExports(t *testing.T) {
+	oldExport := &models.ChannelExport{
+		Channel: models.ChannelInfo{Name: "general"},
+		Messages: []models.ExportMessage{
+			{ID: "1.0", User: "U1", Text: "kept"},
+			{ID: "2.0", User: "U1", Text: "draft", Replies: []models.ExportMessage{
+				{ID: "2.1", User: "U2", Text: "reply"},
+			}},
+			{ID: "3.0", User: "U2", Text: "removed"},
+		},
+		Users: map[string]models.ExportUser{
+			"U1": {ID: "U1", Name: "alice"},
+			"U2": {ID: "U2", Name: "bob"},
+			"U3": {ID: "U3", Name: "carol"},
+		},
+		Statistics: models.ExportStatistics{TotalMessages: 4, TotalUsers: 3},
+	}
+	newExport := &models.ChannelExport{
+		Channel: models.ChannelInfo{Name: "general"},
+		Messages: []models.ExportMessage{
+			{ID: "1.0", User: "U1", Text: "kept"},
+			{ID: "2.0", User: "U1", Text: "final"},
+			{ID: "4.0", User: "U4", Text: "new"},
+		},
+		Users: map[string]models.ExportUser{
+			"U1": {ID: "U1", Name: "alice"},
+			"U2": {ID: "U2", Name: "robert", Deleted: true},
+			"U4": {ID: "U4", Name: "dave"},
+		},
+		Statistics: models.ExportStatistics{TotalMessages: 3, TotalUsers: 3},
+	}
+
+	diff := DiffExports(oldExport, newExport)
+
+	if len(diff.Added) != 1 || diff.Added[0].ID != "4.0" {
+		t.Errorf("Expected message 4.0 added, got %+v", diff.Added)
+	}
+	if len(diff.Removed) != 2 || diff.Removed[0].ID != "2.1" || diff.Removed[1].ID != "3.0" {
+		t.Errorf("Expected reply 2.1 and message 3.0 removed, got %+v", diff.Removed)
+	}
+	if len(diff.Edited) != 1 || diff.Edited[0].OldText != "draft" || diff.Edited[0].NewText != "final" {
+		t.Errorf("Expected message 2.0 edited, got %+v", diff.Edited)
+	}
+	if len(diff.UsersAdded) != 1 || diff.UsersAdded[0] != "U4" {
+		t.Errorf("Expected U4 added, got %v", diff.UsersAdded)
+	}
+	if len(diff.UsersRemoved) != 1 || diff.UsersRemoved[0] != "U3" {
+		t.Errorf("Expected U3 removed, got %v", diff.UsersRemoved)
+	}
+	if len(diff.UsersChanged) != 1 || len(diff.UsersChanged[0].Fields) != 2 {
+		t.Errorf("Expected name and deleted changes for U2, got %+v", diff.UsersChanged)
+	}
+	if diff.Statistics.Messages != -1 {
+		t.Errorf("Expected message delta -1, got %d", diff.Statistics.Messages)
+	}
+	if diff.Empty() {
+		t.Error("Expected diff not to be empty")
+	}
+	if !DiffExports(oldExport, oldExport).Empty() {
+		t.Error("Expected diff of an export with itself to be empty")
+	}
+}
+
+func TestReadExportFileGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "general-export.json.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipWriter := gzip.NewWriter(file)
+	json.NewEncoder(gzipWriter).Encode(models.ChannelExport{Channel: models.ChannelInfo{Name: "general"}})
+	gzipWriter.Close()
+	file.Close()
+
+	export, err := ReadExportFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if export.Channel.Name != "general" {
+		t.Errorf("Expected channel general, got %s", export.Channel.Name)
+	}
+}
+
+func TestReadExport(t *testing.T) {
+	var plain, compressed bytes.Buffer
+	json.NewEncoder(&plain).Encode(models.ChannelExport{Channel: models.ChannelInfo{Name: "general"}})
+	gzipWriter := gzip.NewWriter(&compressed)
+	gzipWriter.Write(plain.Bytes())
+	gzipWriter.Close()
+
+	// Piped exports have no file name, so gzip is recognized by its header
+	for name, data := range map[string][]byte{"plain": plain.Bytes(), "gzip": compressed.Bytes()} {
+		export, err := ReadExport(bytes.NewReader(data), "stdin")
+		if err != nil {
+			t.Errorf("%s: expected no error, got %v", name, err)
+			continue
+		}
+		if export.Channel.Name != "general" {
+			t.Errorf("%s: expected channel general, got %s", name, export.Channel.Name)
+		}
+	}
+
+	if _, err := ReadExport(strings.NewReader("not json"), "stdin"); err == nil || !strings.Contains(err.Error(), "stdin") {
+		t.Errorf("Expected a parse error naming stdin, got %v", err)
+	}
+}
+
+func TestReadExportZip(t *testing.T) {
+	var archive bytes.Buffer
+	zipWriter := zip.NewWriter(&archive)
+	entry, _ := zipWriter.Create("exports/general-export.json.gz")
+	gzipWriter := gzip.NewWriter(entry)
+	json.NewEncoder(gzipWriter).Encode(models.ChannelExport{Channel: models.ChannelInfo{Name: "general"}})
+	gzipWriter.Close()
+	zipWriter.Create("exports/README.txt")
+	zipWriter.Close()
+
+	export, err := ReadExport(bytes.NewReader(archive.Bytes()), "general.zip")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if export.Channel.Name != "general" {
+		t.Errorf("Expected channel general, got %s", export.Channel.Name)
+	}
+
+	// An archive of several exports is ambiguous
+	archive.Reset()
+	zipWriter = zip.NewWriter(&archive)
+	for _, name := range []string{"a.json", "b.json"} {
+		entry, _ := zipWriter.Create(name)
+		entry.Write([]byte("{}"))
+	}
+	zipWriter.Close()
+	if _, err := ReadExport(bytes.NewReader(archive.Bytes()), "exports.zip"); err == nil || !strings.Contains(err.Error(), "found 2") {
+		t.Errorf("Expected an error for two exports, got %v", err)
+	}
+}
+
+func TestDiffExportsIncremental(t *testing.T) {
+	at := func(day int) time.Time { return time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC) }
+	from := at(10)
+	full := &models.ChannelExport{Messages: []models.ExportMessage{
+		{ID: "1.0", Text: "old history", Timestamp: at(1)},
+		{ID: "2.0", Text: "kept", Timestamp: at(11)},
+		{ID: "3.0", Text: "deleted later", Timestamp: at(12)},
+	}}
+	incremental := &models.ChannelExport{
+		ExportInfo: models.ExportMetadata{DateRange: models.DateRange{From: &from}},
+		Messages: []models.ExportMessage{
+			{ID: "2.0", Text: "kept", Timestamp: at(11)},
+			{ID: "4.0", Text: "new", Timestamp: at(13)},
+		},
+	}
+
+	diff := DiffExports(full, incremental)
+	if diff.Window == nil || !diff.Window.From.Equal(from) || diff.Window.To != nil {
+		t.Errorf("Expected a window from %v, got %+v", from, diff.Window)
+	}
+	// History before the incremental export is not reported as removed
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != "3.0" {
+		t.Errorf("Expected only 3.0 removed, got %+v", diff.Removed)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].ID != "4.0" {
+		t.Errorf("Expected 4.0 added, got %+v", diff.Added)
+	}
+	if DiffExports(full, full).Window != nil {
+		t.Error("Expected no window for unbounded exports")
+	}
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// EmojiClientInterface defines the Slack API operations needed to resolve
+// and download custom emoji
+type EmojiClientInterface interface {
+	GetCustomEmoji(ctx context.Context) (map[string]string, error)
+	Download(ctx context.Context, url string) ([]byte, error)
+}
+
+// emojiCode matches :name: emoji codes in message text
+var emojiCode = regexp.MustCompile(`:([a-z0-9_+'\-]+):`)
+
+// SetEmojiClient enables ExportOptions.IncludeEmoji
+func (s *ExportService) SetEmojiClient(client EmojiClientInterface) {
+	s.emojiClient = client
+}
+
+// collectEmoji resolves the custom emoji used in reactions and message text.
+// For local exports the images are downloaded to <name>-emoji/ next to the
+// output file; image download failures are returned as warnings.
+func (s *ExportService) collectEmoji(ctx context.Context, exportData models.ChannelExport, options models.ExportOptions) (map[string]models.ExportEmoji, []string, error) {
+	if s.emojiClient == nil {
+		return nil, nil, fmt.Errorf("no emoji client configured")
+	}
+	custom, err := s.emojiClient.GetCustomEmoji(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	emoji := make(map[string]models.ExportEmoji)
+	for _, name := range usedEmoji(exportData.Messages) {
+		target, ok := custom[name]
+		if !ok {
+			continue
+		}
+		entry := models.ExportEmoji{}
+		// Aliases point at another custom emoji; follow them to the image
+		for i := 0; i < 5 && strings.HasPrefix(target, "alias:"); i++ {
+			entry.AliasFor = strings.TrimPrefix(target, "alias:")
+			target = custom[entry.AliasFor]
+		}
+		if target == "" {
+			// Alias of a standard emoji
+			emoji[name] = entry
+			continue
+		}
+		entry.URL = target
+		emoji[name] = entry
+	}
+
+	if !IsLocalOutput(options.OutputFile) {
+		return emoji, nil, nil
+	}
+
+	var warnings []string
+	base := strings.TrimSuffix(options.OutputFile, filepath.Ext(options.OutputFile))
+	dir := base + "-emoji"
+	names := make([]string, 0, len(emoji))
+	for name := range emoji {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		entry := emoji[name]
+		if entry.URL == "" {
+			continue
+		}
+		data, err := s.emojiClient.Download(ctx, entry.URL)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to download emoji :%s: %v", name, err))
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return emoji, warnings, fmt.Errorf("failed to create emoji directory: %w", err)
+		}
+		file := SafeFileName(name) + path.Ext(entry.URL)
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0644); err != nil {
+			return emoji, warnings, fmt.Errorf("failed to write emoji :%s: %w", name, err)
+		}
+		entry.File = filepath.Base(dir) + "/" + file
+		emoji[name] = entry
+	}
+
+	return emoji, warnings, nil
+}
+
+// usedEmoji returns the emoji names used in reactions and message text,
+// without skin tone modifiers
+func usedEmoji(messages []models.ExportMessage) []string {
+	seen := make(map[string]bool)
+	var add func(msgs []models.ExportMessage)
+	add = func(msgs []models.ExportMessage) {
+		for _, msg := range msgs {
+			for _, reaction := range msg.Reactions {
+				seen[strings.SplitN(reaction.Name, "::", 2)[0]] = true
+			}
+			for _, match := range emojiCode.FindAllStringSubmatch(msg.Text, -1) {
+				seen[match[1]] = true
+			}
+			add(msg.Replies)
+		}
+	}
+	add(messages)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
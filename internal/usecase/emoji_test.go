@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockEmojiClient serves custom emoji and image downloads from memory
+type MockEmojiClient struct {
+	emoji  map[string]string
+	images map[string][]byte
+}
+
+func (m *MockEmojiClient) GetCustomEmoji(ctx context.Context) (map[string]string, error) {
+	return m.emoji, nil
+}
+
+func (m *MockEmojiClient) Download(ctx context.Context, url string) ([]byte, error) {
+	if data, ok := m.images[url]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("download returned status 404")
+}
+
+func TestExportService_IncludeEmoji(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	mockClient.messages[0].Text = "ship it :party-blob: :smile:"
+	mockClient.messages[1].Reactions = []models.Reaction{{Name: "blob::skin-tone-2", Count: 1}, {Name: "broken", Count: 1}}
+
+	service := NewExportService(mockClient, "1.0.0-test")
+	service.SetEmojiClient(&MockEmojiClient{
+		emoji: map[string]string{
+			"party-blob": "https://emoji.example/party-blob.gif",
+			"blob":       "alias:party-blob",
+			"broken":     "https://emoji.example/broken.png",
+			"unused":     "https://emoji.example/unused.png",
+		},
+		images: map[string][]byte{"https://emoji.example/party-blob.gif": []byte("GIF89a")},
+	})
+
+	dir := t.TempDir()
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:        "C123456",
+		IncludeReactions: true,
+		OutputFile:       filepath.Join(dir, "general.json"),
+		Format:           "json",
+		IncludeEmoji:     true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if len(export.Emoji) != 3 {
+		t.Fatalf("Expected the 3 used custom emoji, got %v", export.Emoji)
+	}
+	if blob := export.Emoji["blob"]; blob.AliasFor != "party-blob" || blob.File != "general-emoji/blob.gif" {
+		t.Errorf("Expected alias resolved to a downloaded image, got %+v", blob)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "general-emoji", "party-blob.gif")); err != nil {
+		t.Errorf("Expected downloaded image: %v", err)
+	}
+
+	// A failed download keeps the URL and is reported as a warning
+	if export.Emoji["broken"].File != "" || len(result.Warnings) != 1 {
+		t.Errorf("Expected one download warning, got %v", result.Warnings)
+	}
+}
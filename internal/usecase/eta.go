@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"math"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// etaTracker estimates how long the fetch stages of an export still take
+// from the observed request rate. Slack does not report how many messages a
+// channel has, so the share of the history fetched so far is approximated by
+// how far back into the channel's lifetime the pages reach: history pages
+// arrive newest first. A nil tracker estimates nothing.
+type etaTracker struct {
+	historyStart time.Time // channel creation, zero when unknown
+	historyEnd   time.Time
+	start        time.Time
+	requests     int
+}
+
+// newETATracker starts tracking the fetch of a channel's history
+func newETATracker(channel *models.Channel, now time.Time) *etaTracker {
+	eta := &etaTracker{historyEnd: now, start: now}
+	if channel != nil && channel.Created > 0 {
+		eta.historyStart = time.Unix(channel.Created, 0)
+	}
+	return eta
+}
+
+// restrict narrows the estimated history to the date range of an export.
+// Pages arrive newest first, so they start at to and end at from.
+func (e *etaTracker) restrict(from, to *time.Time) {
+	if e == nil {
+		return
+	}
+	if from != nil && from.After(e.historyStart) {
+		e.historyStart = *from
+	}
+	if to != nil && to.Before(e.historyEnd) {
+		e.historyEnd = *to
+	}
+}
+
+// averageRequest records a finished request and returns the running average
+// time per request, including the delay between requests
+func (e *etaTracker) averageRequest() time.Duration {
+	e.requests++
+	return time.Since(e.start) / time.Duration(e.requests)
+}
+
+// historyRemaining records a history page that reaches back to oldest and
+// estimates the time left for the remaining pages and for the threads
+// projected from the threads seen so far. complete marks the last page. It
+// returns false while no estimate is possible.
+func (e *etaTracker) historyRemaining(pages int, oldest time.Time, threads int, complete bool) (time.Duration, bool) {
+	if e == nil {
+		return 0, false
+	}
+	average := e.averageRequest()
+
+	share := 1.0
+	if !complete {
+		if e.historyStart.IsZero() || oldest.IsZero() {
+			return 0, false
+		}
+		span := e.historyEnd.Sub(e.historyStart)
+		covered := e.historyEnd.Sub(oldest)
+		if span <= 0 || covered <= 0 {
+			return 0, false
+		}
+		// Until the last page arrives some history is assumed to be left
+		share = math.Min(float64(covered)/float64(span), 0.99)
+	}
+
+	remainingPages := float64(pages) * (1 - share) / share
+	projectedThreads := float64(threads) / share
+	return time.Duration((remainingPages + projectedThreads) * float64(average)), true
+}
+
+// threadsRemaining records a fetched thread and estimates the time left for
+// the other threads
+func (e *etaTracker) threadsRemaining(done, total int) time.Duration {
+	if e == nil {
+		return 0
+	}
+	return e.averageRequest() * time.Duration(total-done)
+}
+
+// setEstimate fills progress.EstimatedTotal from the time still remaining
+func setEstimate(progress *models.ExportProgress, remaining time.Duration) {
+	progress.EstimatedTotal = progress.ElapsedTime + remaining
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestETATracker_HistoryRemaining(t *testing.T) {
+	now := time.Now()
+	eta := newETATracker(&models.Channel{Created: now.Add(-100 * 24 * time.Hour).Unix()}, now.Add(-2*time.Second))
+
+	// Two pages took 2s and reach a quarter of the way back: about six more
+	// pages and, with 3 threads seen, about 12 thread requests remain
+	remaining, ok := eta.historyRemaining(1, now.Add(-10*24*time.Hour), 0, false)
+	if !ok || remaining <= 0 {
+		t.Fatalf("Expected an estimate after the first page, got %s, %v", remaining, ok)
+	}
+	remaining, ok = eta.historyRemaining(2, now.Add(-25*24*time.Hour), 3, false)
+	if !ok {
+		t.Fatal("Expected an estimate")
+	}
+	if remaining < 17*time.Second || remaining > 19*time.Second {
+		t.Errorf("Expected about 18s remaining, got %s", remaining)
+	}
+
+	// After the last page only the threads are left
+	remaining, ok = eta.historyRemaining(3, now.Add(-90*24*time.Hour), 4, true)
+	if !ok || remaining < 2*time.Second || remaining > 3*time.Second {
+		t.Errorf("Expected about 4 requests of ~0.7s after the last page, got %s, %v", remaining, ok)
+	}
+
+	if remaining := eta.threadsRemaining(1, 4); remaining <= 0 {
+		t.Errorf("Expected time left for 3 threads, got %s", remaining)
+	}
+}
+
+func TestETATracker_Unknown(t *testing.T) {
+	// Without a creation date the history share is unknown
+	eta := newETATracker(&models.Channel{}, time.Now())
+	if _, ok := eta.historyRemaining(1, time.Now().Add(-time.Hour), 0, false); ok {
+		t.Error("Expected no estimate without the channel creation date")
+	}
+
+	var none *etaTracker
+	if _, ok := none.historyRemaining(1, time.Now(), 0, true); ok {
+		t.Error("Expected a nil tracker to estimate nothing")
+	}
+	if remaining := none.threadsRemaining(1, 2); remaining != 0 {
+		t.Errorf("Expected a nil tracker to estimate nothing, got %s", remaining)
+	}
+}
+
+func TestExportProgress_Remaining(t *testing.T) {
+	progress := models.ExportProgress{ElapsedTime: 10 * time.Second}
+	if _, ok := progress.Remaining(); ok {
+		t.Error("Expected no estimate while EstimatedTotal is unset")
+	}
+	setEstimate(&progress, 5*time.Second)
+	if remaining, ok := progress.Remaining(); !ok || remaining != 5*time.Second {
+		t.Errorf("Expected 5s remaining, got %s, %v", remaining, ok)
+	}
+}
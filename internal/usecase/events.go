@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/itcaat/slacker/internal/telemetry"
+	"github.com/itcaat/slacker/models"
+)
+
+// ExportEvent is something that happened during an export. Sinks switch on
+// the concrete type: StageChanged, PageFetched, ThreadFetched, RateLimited or
+// Warning. Every event carries a snapshot of the export's progress.
+type ExportEvent interface {
+	// Snapshot returns the progress of the export when the event happened
+	Snapshot() models.ExportProgress
+}
+
+// StageChanged is sent when the export enters a pipeline stage, e.g.
+// message_fetch or file_generation
+type StageChanged struct {
+	Stage       string
+	Description string
+	Progress    models.ExportProgress
+}
+
+// PageFetched is sent after each page of channel history
+type PageFetched struct {
+	Page     int
+	Messages int // Messages on this page within the date range
+	Total    int // Messages fetched so far
+	Progress models.ExportProgress
+}
+
+// ThreadFetched is sent after the replies of a thread were fetched
+type ThreadFetched struct {
+	ThreadTS string
+	Replies  int
+	Current  int
+	Total    int
+	Progress models.ExportProgress
+}
+
+// RateLimited is sent when Slack rate limited an API call and the export
+// waits before retrying it
+type RateLimited struct {
+	Method   string
+	Wait     time.Duration
+	Progress models.ExportProgress
+}
+
+// Warning is sent for every problem that makes the export partial but does
+// not stop it, with the text recorded in the export's warnings
+type Warning struct {
+	Message  string
+	Progress models.ExportProgress
+}
+
+// Snapshot implements ExportEvent
+func (e StageChanged) Snapshot() models.ExportProgress { return e.Progress }
+
+// Snapshot implements ExportEvent
+func (e PageFetched) Snapshot() models.ExportProgress { return e.Progress }
+
+// Snapshot implements ExportEvent
+func (e ThreadFetched) Snapshot() models.ExportProgress { return e.Progress }
+
+// Snapshot implements ExportEvent
+func (e RateLimited) Snapshot() models.ExportProgress { return e.Progress }
+
+// Snapshot implements ExportEvent
+func (e Warning) Snapshot() models.ExportProgress { return e.Progress }
+
+// EventSink receives the events of an export. Events are delivered from the
+// exporting goroutine, so sinks should return quickly.
+type EventSink interface {
+	HandleEvent(event ExportEvent)
+}
+
+// EventSinkFunc adapts a function to EventSink
+type EventSinkFunc func(event ExportEvent)
+
+// HandleEvent calls f
+func (f EventSinkFunc) HandleEvent(event ExportEvent) {
+	f(event)
+}
+
+// EventSinks sends every event to each sink in turn; nil sinks are skipped
+type EventSinks []EventSink
+
+// HandleEvent implements EventSink
+func (sinks EventSinks) HandleEvent(event ExportEvent) {
+	for _, sink := range sinks {
+		if sink != nil {
+			sink.HandleEvent(event)
+		}
+	}
+}
+
+// ChannelEvents follows the channels of a batch export, such as export-all
+// or a backup run, which may export several channels at once
+type ChannelEvents interface {
+	// ChannelStarted is called when the export of a channel starts and
+	// returns the sink for its events, or nil
+	ChannelStarted(channel string) EventSink
+	// ChannelFinished is called when the export of a channel ends, with the
+	// error that failed it
+	ChannelFinished(channel string, err error)
+}
+
+// channelStarted reports the start of a channel export to events, which may
+// be nil, and returns the sink for its events
+func channelStarted(events ChannelEvents, channel string) EventSink {
+	if events == nil {
+		return nil
+	}
+	return events.ChannelStarted(channel)
+}
+
+// channelFinished reports the end of a channel export to events, which may
+// be nil
+func channelFinished(events ChannelEvents, channel string, err error) {
+	if events != nil {
+		events.ChannelFinished(channel, err)
+	}
+}
+
+// ProgressSink adapts a progress callback to EventSink. It is called with the
+// snapshot of every stage, page and thread event, the granularity of a
+// progress bar.
+func ProgressSink(callback func(models.ExportProgress)) EventSink {
+	return EventSinkFunc(func(event ExportEvent) {
+		switch event.(type) {
+		case StageChanged, PageFetched, ThreadFetched:
+			callback(event.Snapshot())
+		}
+	})
+}
+
+// NewLogSink returns a sink that writes events as structured log records, so
+// with the json log format every event is a JSON line. Progress events are
+// logged at debug level, rate limit waits at info and warnings at warn. The
+// export does not log its warnings itself: they reach the log through this
+// sink and the caller through ExportResult.Warnings.
+func NewLogSink(logger *slog.Logger) EventSink {
+	return EventSinkFunc(func(event ExportEvent) {
+		progress := event.Snapshot()
+		elapsed := slog.Duration("elapsed", progress.ElapsedTime)
+		switch e := event.(type) {
+		case StageChanged:
+			logger.Debug("export stage", "event", "stage_changed", "stage", e.Stage, "progress", progress.Progress, elapsed)
+		case PageFetched:
+			logger.Debug("history page fetched", "event", "page_fetched", "page", e.Page, "messages", e.Messages, "total", e.Total, elapsed)
+		case ThreadFetched:
+			logger.Debug("thread fetched", "event", "thread_fetched", "thread_ts", e.ThreadTS, "replies", e.Replies, "current", e.Current, "total", e.Total, elapsed)
+		case RateLimited:
+			logger.Info("rate limited by Slack", "event", "rate_limited", "method", e.Method, "wait", e.Wait, "stage", progress.Stage, elapsed)
+		case Warning:
+			logger.Warn("export warning", "event", "warning", "message", e.Message, "stage", progress.Stage, elapsed)
+		}
+	})
+}
+
+// exportEvents tracks the progress of one export and sends it along with its
+// events. A nil sink receives nothing.
+type exportEvents struct {
+	sink     EventSink
+	start    time.Time
+	progress models.ExportProgress
+}
+
+// newExportEvents starts tracking an export that reports to sink
+func newExportEvents(sink EventSink) *exportEvents {
+	return &exportEvents{sink: sink, start: time.Now()}
+}
+
+// emit sends event with the elapsed time updated
+func (e *exportEvents) emit(build func(models.ExportProgress) ExportEvent) {
+	if e.sink == nil {
+		return
+	}
+	e.progress.ElapsedTime = time.Since(e.start)
+	e.sink.HandleEvent(build(e.progress))
+}
+
+// estimate sets the estimated total duration from the time still remaining
+func (e *exportEvents) estimate(remaining time.Duration) {
+	e.progress.ElapsedTime = time.Since(e.start)
+	setEstimate(&e.progress, remaining)
+}
+
+// stage moves the export to a new stage
+func (e *exportEvents) stage(stage, description string, fraction float64) {
+	e.progress.Stage = stage
+	e.progress.CurrentStep = description
+	e.progress.Progress = fraction
+	e.emit(func(p models.ExportProgress) ExportEvent {
+		return StageChanged{Stage: stage, Description: description, Progress: p}
+	})
+}
+
+// warn reports warnings that were added to the export
+func (e *exportEvents) warn(messages ...string) {
+	for _, message := range messages {
+		e.emit(func(p models.ExportProgress) ExportEvent {
+			return Warning{Message: message, Progress: p}
+		})
+	}
+}
+
+// observeRateLimits returns a context whose Slack API calls report their rate
+// limit waits as events
+func (e *exportEvents) observeRateLimits(ctx context.Context) context.Context {
+	if e.sink == nil {
+		return ctx
+	}
+	return telemetry.WithRateLimitObserver(ctx, func(method string, wait time.Duration) {
+		e.emit(func(p models.ExportProgress) ExportEvent {
+			return RateLimited{Method: method, Wait: wait, Progress: p}
+		})
+	})
+}
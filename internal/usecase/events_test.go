@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/internal/telemetry"
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_ExportChannelEvents(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	mockClient.threadErr = errors.New("thread_not_found")
+	service := NewExportService(mockClient, "1.0.0-test")
+	service.retryBackoff = time.Millisecond
+
+	var events []ExportEvent
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+		BestEffort:     true,
+	}
+	if _, err := service.ExportChannel(context.Background(), options, EventSinkFunc(func(event ExportEvent) {
+		events = append(events, event)
+	})); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var stages []string
+	var pages, warnings int
+	for _, event := range events {
+		switch e := event.(type) {
+		case StageChanged:
+			stages = append(stages, e.Stage)
+			if e.Progress.Stage != e.Stage {
+				t.Errorf("Expected the snapshot to be in stage %s, got %s", e.Stage, e.Progress.Stage)
+			}
+		case PageFetched:
+			pages++
+			if e.Total != 2 || e.Progress.MessagesTotal != 2 {
+				t.Errorf("Expected 2 messages on the page, got %+v", e)
+			}
+		case Warning:
+			warnings++
+			if !strings.Contains(e.Message, "thread_not_found") || e.Progress.Stage != "thread_fetch" {
+				t.Errorf("Expected the thread failure during thread_fetch, got %+v", e)
+			}
+		}
+	}
+
+	want := "initializing,channel_fetch,message_fetch,thread_fetch,user_fetch,data_processing,file_generation,complete"
+	if got := strings.Join(stages, ","); got != want {
+		t.Errorf("Expected stages %s, got %s", want, got)
+	}
+	if pages != 1 || warnings != 1 {
+		t.Errorf("Expected 1 page and 1 warning, got %d and %d", pages, warnings)
+	}
+}
+
+func TestExportService_ExportChannelThreadEvents(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	var threads []ThreadFetched
+	var progress []models.ExportProgress
+	sink := EventSinks{
+		EventSinkFunc(func(event ExportEvent) {
+			if e, ok := event.(ThreadFetched); ok {
+				threads = append(threads, e)
+			}
+		}),
+		nil,
+		ProgressSink(func(p models.ExportProgress) { progress = append(progress, p) }),
+	}
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+	}
+	if _, err := service.ExportChannel(context.Background(), options, sink); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(threads) != 1 || threads[0].ThreadTS != "1704067260.000000" || threads[0].Replies != 2 || threads[0].Current != 1 || threads[0].Total != 1 {
+		t.Errorf("Expected one thread with 2 replies, got %+v", threads)
+	}
+	// 8 stages, 1 page and 1 thread
+	if len(progress) != 10 || progress[len(progress)-1].Progress != 1.0 {
+		t.Errorf("Expected 10 progress updates ending complete, got %d", len(progress))
+	}
+}
+
+func TestExportEventsRateLimited(t *testing.T) {
+	var buf bytes.Buffer
+	events := newExportEvents(NewLogSink(slog.New(slog.NewJSONHandler(&buf, nil))))
+	events.stage("message_fetch", "Fetching channel messages", 0.2)
+
+	ctx := events.observeRateLimits(context.Background())
+	telemetry.ObserveRateLimit(ctx, "conversations.history", 30*time.Second)
+
+	output := buf.String()
+	if strings.Contains(output, "stage_changed") {
+		t.Errorf("Expected progress events below info level, got %s", output)
+	}
+	if !strings.Contains(output, `"event":"rate_limited"`) || !strings.Contains(output, `"method":"conversations.history"`) || !strings.Contains(output, `"stage":"message_fetch"`) {
+		t.Errorf("Expected a JSON rate limit record, got %s", output)
+	}
+}
+
+func TestLogSinkWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	events := newExportEvents(NewLogSink(slog.New(slog.NewJSONHandler(&buf, nil))))
+	events.stage("user_fetch", "Fetching users", 0.6)
+	events.warn("user directory unavailable: ratelimited")
+
+	output := buf.String()
+	if !strings.Contains(output, `"level":"WARN"`) || !strings.Contains(output, `"message":"user directory unavailable: ratelimited"`) {
+		t.Errorf("Expected the warning as a warn record, got %s", output)
+	}
+}
@@ -0,0 +1,344 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/storage"
+	"github.com/itcaat/slacker/models"
+)
+
+// runsDir is the directory, relative to the output directory, that holds the
+// state and report of every export-all run
+const runsDir = ".slacker-runs"
+
+// Status of a channel within an export-all run
+const (
+	RunStatusPending = "pending"
+	RunStatusDone    = "done"
+	RunStatusFailed  = "failed"
+)
+
+// ExportRun is the persisted state and report of an export-all run. It is
+// saved after every channel so an interrupted run can be resumed.
+type ExportRun struct {
+	ID             string            `json:"id"`
+	StartedAt      time.Time         `json:"started_at"`
+	FinishedAt     *time.Time        `json:"finished_at,omitempty"`
+	OutputDir      string            `json:"output_dir"`
+	Format         string            `json:"format"`
+	Compression    string            `json:"compression,omitempty"`
+	IncludeThreads bool              `json:"include_threads"`
+	SubtypePolicy  map[string]string `json:"subtype_policy,omitempty"`
+	Channels       []RunChannel      `json:"channels"`
+}
+
+// RunChannel is the state of one channel within a run
+type RunChannel struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	BackupChannelResult
+}
+
+// NewExportRun plans a run that exports channels to local files in outputDir.
+// The run ID is derived from now.
+func NewExportRun(channels []models.Channel, outputDir, format string, now time.Time) (*ExportRun, error) {
+	if storage.IsRemote(outputDir) {
+		return nil, fmt.Errorf("export-all requires a local output directory")
+	}
+	if outputDir == "" {
+		outputDir = "."
+	}
+	if format == "" {
+		format = "json"
+	}
+
+	run := &ExportRun{
+		ID:        now.Format("20060102-150405"),
+		StartedAt: now,
+		OutputDir: outputDir,
+		Format:    format,
+	}
+	for _, channel := range channels {
+		run.Channels = append(run.Channels, RunChannel{
+			ID:                  channel.ID,
+			Status:              RunStatusPending,
+			BackupChannelResult: BackupChannelResult{Channel: channel.Name},
+		})
+	}
+	return run, nil
+}
+
+// LoadExportRun reads the state of run id from outputDir
+func LoadExportRun(outputDir, id string) (*ExportRun, error) {
+	data, err := os.ReadFile(runPath(outputDir, id))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("run '%s' not found in %s", id, outputDir)
+	}
+	if err != nil {
+		return nil, models.NewExportError(models.ErrorCategoryIO, "failed to read run state", err)
+	}
+
+	var run ExportRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("invalid run state %s: %w", runPath(outputDir, id), err)
+	}
+	run.OutputDir = outputDir
+	return &run, nil
+}
+
+// runPath is the state file of run id
+func runPath(outputDir, id string) string {
+	return filepath.Join(outputDir, runsDir, id+".json")
+}
+
+// Path returns the file the run's state and report are saved to
+func (run *ExportRun) Path() string {
+	return runPath(run.OutputDir, run.ID)
+}
+
+// Counts returns how many channels are done, failed and still pending
+func (run *ExportRun) Counts() (done, failed, pending int) {
+	for _, channel := range run.Channels {
+		switch channel.Status {
+		case RunStatusDone:
+			done++
+		case RunStatusFailed:
+			failed++
+		default:
+			pending++
+		}
+	}
+	return done, failed, pending
+}
+
+// Results returns the result of every channel that was attempted
+func (run *ExportRun) Results() []BackupChannelResult {
+	var results []BackupChannelResult
+	for _, channel := range run.Channels {
+		if channel.Status != RunStatusPending {
+			results = append(results, channel.BackupChannelResult)
+		}
+	}
+	return results
+}
+
+// ExportAllService exports many channels with a pool of workers that share
+// one Slack client, and therefore its rate limit budget
+type ExportAllService struct {
+	exportService *ExportService
+	pageSize      int
+	threadDelay   time.Duration
+	location      *time.Location
+	logger        *slog.Logger
+	channelEvents ChannelEvents
+}
+
+// NewExportAllService creates a new export-all service
+func NewExportAllService(slackClient SlackClientInterface, version string) *ExportAllService {
+	return &ExportAllService{
+		exportService: NewExportService(slackClient, version),
+		logger:        slog.Default(),
+	}
+}
+
+// SetLogger sets the logger used for run diagnostics and export warnings
+func (s *ExportAllService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+	s.exportService.SetLogger(logger)
+}
+
+// SetWorkspaceClient records the workspace and exporting identity in every export
+func (s *ExportAllService) SetWorkspaceClient(client WorkspaceClientInterface) {
+	s.exportService.SetWorkspaceClient(client)
+}
+
+// SetAuditLog records the export of every channel in log
+func (s *ExportAllService) SetAuditLog(log *audit.Log) {
+	s.exportService.SetAuditLog(log)
+}
+
+// SetBotClient adds the bots that posted messages to every export
+func (s *ExportAllService) SetBotClient(client BotClientInterface) {
+	s.exportService.SetBotClient(client)
+}
+
+// AddHook adds a hook to the export of every channel
+func (s *ExportAllService) AddHook(hook ExportHook) {
+	s.exportService.AddHook(hook)
+}
+
+// SetChannelEvents reports the start, events and end of every channel
+// export to events, e.g. to draw a progress bar per channel
+func (s *ExportAllService) SetChannelEvents(events ChannelEvents) {
+	s.channelEvents = events
+}
+
+// SetPaging sets the history page size and the pause between thread
+// requests. Zero values keep the defaults.
+func (s *ExportAllService) SetPaging(pageSize int, threadDelay time.Duration) {
+	s.pageSize = pageSize
+	s.threadDelay = threadDelay
+}
+
+// SetLocation sets the zone export timestamps are rendered in; nil selects
+// the machine's zone
+func (s *ExportAllService) SetLocation(loc *time.Location) {
+	s.location = loc
+}
+
+// Run exports every channel of the run that is not done yet with the given
+// number of workers. Failed channels of a resumed run are retried. The run
+// state is saved after each channel; progress, if set, is called with each
+// finished channel.
+func (s *ExportAllService) Run(ctx context.Context, run *ExportRun, workers int, progress func(RunChannel)) error {
+	if err := ValidateSubtypePolicy(run.SubtypePolicy); err != nil {
+		return err
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if err := os.MkdirAll(filepath.Join(run.OutputDir, runsDir), 0755); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to create run directory", err)
+	}
+	if err := saveRun(run); err != nil {
+		return err
+	}
+
+	var (
+		mu      sync.Mutex
+		saveErr error
+		wg      sync.WaitGroup
+	)
+	jobs := make(chan RunChannel)
+	finish := func(index int, channel RunChannel) {
+		mu.Lock()
+		defer mu.Unlock()
+		run.Channels[index] = channel
+		if err := saveRun(run); err != nil && saveErr == nil {
+			saveErr = err
+		}
+		if progress != nil {
+			progress(channel)
+		}
+	}
+
+	indexes := make(map[string]int, len(run.Channels))
+	for i, channel := range run.Channels {
+		indexes[channel.ID] = i
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for channel := range jobs {
+				result := s.exportChannel(ctx, run, channel)
+				// An interrupted channel stays pending for the resumed run
+				if result.Status == RunStatusPending {
+					continue
+				}
+				finish(indexes[channel.ID], result)
+			}
+		}()
+	}
+
+	var pending []RunChannel
+	for _, channel := range run.Channels {
+		if channel.Status != RunStatusDone {
+			pending = append(pending, channel)
+		}
+	}
+	s.logger.Info("export run started", "run", run.ID, "channels", len(pending), "workers", workers)
+
+queue:
+	for _, channel := range pending {
+		select {
+		case jobs <- channel:
+		case <-ctx.Done():
+			break queue
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	return saveRun(run)
+}
+
+// exportChannel runs a single channel export of a run. The output file name
+// embeds the run ID so a resumed run overwrites its own partial files. A
+// channel whose export is cancelled through ctx is returned unchanged.
+func (s *ExportAllService) exportChannel(ctx context.Context, run *ExportRun, channel RunChannel) RunChannel {
+	name := channel.Channel
+	result := RunChannel{ID: channel.ID, BackupChannelResult: BackupChannelResult{Channel: name}}
+
+	options := models.ExportOptions{
+		ChannelID:        channel.ID,
+		ChannelName:      name,
+		IncludeThreads:   run.IncludeThreads,
+		IncludeFiles:     true,
+		IncludeReactions: true,
+		OutputFile:       filepath.Join(run.OutputDir, ExportFileName(name, run.ID, run.Format)),
+		Format:           run.Format,
+		Compression:      run.Compression,
+		SubtypePolicy:    run.SubtypePolicy,
+		PageSize:         s.pageSize,
+		ThreadDelay:      s.threadDelay,
+		Location:         s.location,
+	}
+
+	events := EventSinks{NewLogSink(s.logger.With("run", run.ID, "channel", name)), channelStarted(s.channelEvents, name)}
+	exportResult, err := s.exportService.ExportChannel(ctx, options, events)
+	channelFinished(s.channelEvents, name, err)
+	if err != nil && ctx.Err() != nil {
+		return channel
+	}
+	if err != nil {
+		s.logger.Warn("channel export failed", "run", run.ID, "channel", name, "error", err)
+		result.Status = RunStatusFailed
+		result.Error = err.Error()
+		result.ErrorCategory = models.ErrorCategoryOf(err)
+		return result
+	}
+
+	result.Status = RunStatusDone
+	result.OutputFile = exportResult.OutputFile
+	result.Messages = exportResult.Statistics.TotalMessages
+	result.FileSize = exportResult.FileSize
+	result.Duration = exportResult.Duration
+	return result
+}
+
+// saveRun persists the run state. The file is replaced atomically, so an
+// interrupted write never leaves a run that cannot be resumed.
+func saveRun(run *ExportRun) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal run state: %w", err)
+	}
+
+	if _, err := writeFileAtomic(run.Path(), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		return models.NewExportError(models.ErrorCategoryIO, "failed to write run state", err)
+	}
+
+	return nil
+}
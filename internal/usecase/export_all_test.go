@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportAllService_RunAndResume(t *testing.T) {
+	dir := t.TempDir()
+	client := NewMockSlackClient()
+	channels := []models.Channel{
+		{ID: "C123456", Name: "general"},
+		{ID: "CMISSING", Name: "missing"},
+	}
+
+	run, err := NewExportRun(channels, dir, "json", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.ID != "20240115-103000" {
+		t.Errorf("Expected run ID from the start time, got %s", run.ID)
+	}
+
+	service := NewExportAllService(client, "1.0.0-test")
+	if err := service.Run(context.Background(), run, 2, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if done, failed, pending := run.Counts(); done != 1 || failed != 1 || pending != 0 {
+		t.Fatalf("Expected 1 done and 1 failed channel, got %d done, %d failed, %d pending", done, failed, pending)
+	}
+	if run.FinishedAt == nil {
+		t.Error("Expected a finished run to record its end time")
+	}
+	if _, err := os.Stat(run.Channels[0].OutputFile); err != nil {
+		t.Errorf("Expected the export file of #general: %v", err)
+	}
+
+	// The channel becomes readable; resuming retries only the failed channel
+	client.channels = append(client.channels, models.Channel{ID: "CMISSING", Name: "missing"})
+	resumed, err := LoadExportRun(dir, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to load run: %v", err)
+	}
+	var exported []string
+	if err := service.Run(context.Background(), resumed, 2, func(channel RunChannel) {
+		exported = append(exported, channel.Channel)
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(exported) != 1 || exported[0] != "missing" {
+		t.Errorf("Expected only #missing to be exported again, got %v", exported)
+	}
+	if done, failed, _ := resumed.Counts(); done != 2 || failed != 0 {
+		t.Errorf("Expected both channels done after resuming, got %d done, %d failed", done, failed)
+	}
+
+	if _, err := LoadExportRun(dir, "unknown"); err == nil {
+		t.Error("Expected an error for an unknown run")
+	}
+}
+
+// recordingChannelEvents records the channel exports of a batch export
+type recordingChannelEvents struct {
+	mu       sync.Mutex
+	events   map[string]int
+	finished map[string]error
+}
+
+func (r *recordingChannelEvents) ChannelStarted(channel string) EventSink {
+	return EventSinkFunc(func(event ExportEvent) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events[channel]++
+	})
+}
+
+func (r *recordingChannelEvents) ChannelFinished(channel string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished[channel] = err
+}
+
+func TestExportAllService_ChannelEvents(t *testing.T) {
+	channels := []models.Channel{
+		{ID: "C123456", Name: "general"},
+		{ID: "CMISSING", Name: "missing"},
+	}
+	run, err := NewExportRun(channels, t.TempDir(), "json", time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	events := &recordingChannelEvents{events: make(map[string]int), finished: make(map[string]error)}
+	service := NewExportAllService(NewMockSlackClient(), "1.0.0-test")
+	service.SetChannelEvents(events)
+	if err := service.Run(context.Background(), run, 2, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(events.finished) != 2 {
+		t.Fatalf("Expected both channels to finish, got %v", events.finished)
+	}
+	if err := events.finished["general"]; err != nil {
+		t.Errorf("Expected #general to succeed, got %v", err)
+	}
+	if events.finished["missing"] == nil {
+		t.Error("Expected #missing to finish with its error")
+	}
+	if events.events["general"] == 0 {
+		t.Error("Expected the events of #general to reach its sink")
+	}
+}
+
+// cancellingChannelEvents cancels the run as soon as a channel starts
+type cancellingChannelEvents struct {
+	cancel context.CancelFunc
+}
+
+func (c cancellingChannelEvents) ChannelStarted(string) EventSink {
+	c.cancel()
+	return nil
+}
+
+func (c cancellingChannelEvents) ChannelFinished(string, error) {}
+
+func TestExportAllService_RunCancelled(t *testing.T) {
+	dir := t.TempDir()
+	run, err := NewExportRun([]models.Channel{{ID: "C123456", Name: "general"}}, dir, "json", time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service := NewExportAllService(NewMockSlackClient(), "1.0.0-test")
+	service.SetChannelEvents(cancellingChannelEvents{cancel: cancel})
+	if err := service.Run(ctx, run, 1, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the run to be cancelled, got %v", err)
+	}
+
+	// The interrupted channel is left for the resumed run
+	saved, err := LoadExportRun(dir, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to load run: %v", err)
+	}
+	if _, _, pending := saved.Counts(); pending != 1 {
+		t.Errorf("Expected the cancelled channel to stay pending, got %+v", saved.Channels)
+	}
+}
+
+func TestNewExportRun_RemoteOutput(t *testing.T) {
+	if _, err := NewExportRun(nil, "s3://bucket/slack", "json", time.Now()); err == nil {
+		t.Error("Expected remote output directories to be rejected")
+	}
+}
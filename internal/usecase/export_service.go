@@ -4,174 +4,517 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/itcaat/slacker/internal/audit"
+	"github.com/itcaat/slacker/internal/i18n"
+	"github.com/itcaat/slacker/internal/telemetry"
 	"github.com/itcaat/slacker/models"
 )
 
 // SlackClientInterface defines the interface for Slack API operations
 type SlackClientInterface interface {
 	GetChannels(ctx context.Context) ([]models.Channel, error)
-	GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) ([]models.Message, string, error)
+	GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) (*models.HistoryPage, error)
+	// GetChannelHistoryRange is GetChannelHistory restricted to messages
+	// between the Slack timestamps oldest and latest (inclusive; empty = open)
+	GetChannelHistoryRange(ctx context.Context, channelID string, limit int, cursor, oldest, latest string) (*models.HistoryPage, error)
 	GetThreadReplies(ctx context.Context, channelID, threadTS string) ([]models.Message, error)
 	GetUsers(ctx context.Context) ([]models.User, error)
 }
 
+// bestEffortAttempts is how often a failing fetch is tried in best-effort
+// mode, waiting bestEffortBackoff after the first failure and twice as long
+// after each further one
+const (
+	bestEffortAttempts = 3
+	bestEffortBackoff  = 2 * time.Second
+)
+
+// Defaults for ExportOptions.PageSize and ExportOptions.ThreadDelay
+const (
+	defaultPageSize     = 1000
+	defaultRequestDelay = 100 * time.Millisecond
+)
+
 // ExportService handles the export of Slack channel data
 type ExportService struct {
-	slackClient SlackClientInterface
-	version     string
+	slackClient    SlackClientInterface
+	emojiClient    EmojiClientInterface
+	avatarClient   AvatarClientInterface
+	snapshotClient LinkSnapshotClientInterface
+	fileClient     FileClientInterface
+	canvasClient   CanvasClientInterface
+	groupClient    UserGroupClientInterface
+	botClient      BotClientInterface
+	infoClient     ChannelInfoClientInterface
+	version        string
+	logger         *slog.Logger
+
+	workspaceClient WorkspaceClientInterface
+	summaryClient   SummaryClientInterface
+	hooks           []ExportHook
+	transform       *Transform
+	auditLog        *audit.Log
+	stdout          io.Writer
+	retryBackoff    time.Duration
+
+	// botScopeMissing is set once bots.info was refused for a missing
+	// scope, so later exports of a batch skip the lookups
+	botScopeMissing atomic.Bool
 }
 
 // NewExportService creates a new export service
 func NewExportService(slackClient SlackClientInterface, version string) *ExportService {
 	return &ExportService{
-		slackClient: slackClient,
-		version:     version,
+		slackClient:  slackClient,
+		version:      version,
+		logger:       slog.Default(),
+		retryBackoff: bestEffortBackoff,
 	}
 }
 
-// ExportChannel exports a complete Slack channel with all messages and threads
-func (s *ExportService) ExportChannel(options models.ExportOptions, progressCallback func(models.ExportProgress)) (*models.ExportResult, error) {
+// SetLogger sets the logger used for export warnings
+func (s *ExportService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetTransform runs a transform script over every exported message
+func (s *ExportService) SetTransform(transform *Transform) {
+	s.transform = transform
+}
+
+// SetAuditLog records every export, successful or not, in log
+func (s *ExportService) SetAuditLog(log *audit.Log) {
+	s.auditLog = log
+}
+
+// ExportChannel exports a complete Slack channel with all messages and
+// threads. sink, which may be nil, receives the export's events. Problems
+// that make the export partial are not printed or logged here: they are sent
+// to sink as Warning events and listed in the result's Warnings.
+func (s *ExportService) ExportChannel(ctx context.Context, options models.ExportOptions, sink EventSink) (*models.ExportResult, error) {
+	events := newExportEvents(sink)
+	ctx = context.WithValue(ctx, hookChannelKey{}, options.ChannelID)
+	ctx = events.observeRateLimits(ctx)
+	ctx, span := telemetry.StartSpan(ctx, "export.channel", "slack.channel_id", options.ChannelID)
+	s.removeStaleTempFiles(options.OutputFile)
+	result, err := s.exportChannel(ctx, options, events)
+	span.End(err)
+
+	if err != nil {
+		telemetry.ExportsTotal.Inc("failure")
+		if result != nil {
+			result.ErrorCategory = models.ErrorCategoryOf(err)
+		}
+	} else {
+		telemetry.ExportsTotal.Inc("success")
+		telemetry.ExportDuration.Observe(result.Duration.Seconds())
+		telemetry.ExportedMessages.Add(float64(result.Statistics.TotalMessages))
+		telemetry.LastExportTime.Set(float64(time.Now().Unix()))
+	}
+	if result != nil {
+		s.runCompleteHooks(ctx, options.ChannelID, result)
+	}
+	// An export that cannot be recorded fails, so none goes unaudited
+	if auditErr := s.auditLog.Record(audit.ForExport(audit.ActionExport, options, result, err)); auditErr != nil {
+		if err != nil {
+			s.logger.Warn("failed to write audit log", "error", auditErr)
+		} else {
+			err = models.NewExportError(models.ErrorCategoryIO, "failed to write audit log", auditErr)
+		}
+	}
+
+	return result, err
+}
+
+// removeStaleTempFiles deletes the temp files of failed exports next to a
+// local output file before a new export is written there
+func (s *ExportService) removeStaleTempFiles(outputFile string) {
+	if outputFile == "" || !IsLocalOutput(outputFile) {
+		return
+	}
+	removed, err := RemoveStaleTempFiles(filepath.Dir(outputFile), time.Now())
+	if err != nil {
+		s.logger.Warn("failed to remove temp files of failed exports", "dir", filepath.Dir(outputFile), "error", err)
+	}
+	for _, path := range removed {
+		s.logger.Info("removed temp file of a failed export", "path", path)
+	}
+}
+
+// startStage begins a traced pipeline stage. The returned function ends it,
+// records its duration metric and returns the duration.
+func startStage(ctx context.Context, stage string) (context.Context, func(error) time.Duration) {
+	start := time.Now()
+	ctx, span := telemetry.StartSpan(ctx, "export."+stage)
+	return ctx, func(err error) time.Duration {
+		duration := time.Since(start)
+		span.End(err)
+		telemetry.StageDuration.Observe(duration.Seconds(), stage)
+		return duration
+	}
+}
+
+// exportChannel runs the export pipeline within the trace context ctx
+func (s *ExportService) exportChannel(ctx context.Context, options models.ExportOptions, events *exportEvents) (*models.ExportResult, error) {
 	startTime := time.Now()
 
-	// Initialize progress tracking
-	progress := models.ExportProgress{
-		Stage:       "initializing",
-		CurrentStep: "Starting export",
-		Progress:    0.0,
-		ElapsedTime: 0,
+	keep, err := messagePredicate(options)
+	if err != nil {
+		return &models.ExportResult{
+			Success: false,
+			Error:   err.Error(),
+		}, err
 	}
 
-	if progressCallback != nil {
-		progressCallback(progress)
+	// Record who exports from which workspace before fetching any data
+	identity, workspace, accountWarnings := s.lookupAccount(ctx, options)
+
+	// Warnings describe data missing from the export and mark it partial;
+	// notices describe metadata only and are listed without doing so
+	var warnings []string
+	partial := false
+	notice := func(messages ...string) {
+		warnings = append(warnings, messages...)
+		events.warn(messages...)
+	}
+	warn := func(messages ...string) {
+		partial = partial || len(messages) > 0
+		notice(messages...)
 	}
 
+	events.stage("initializing", i18n.Sprintf("Starting export"), 0.0)
+
 	// Step 1: Fetch channel information
-	progress.Stage = "channel_fetch"
-	progress.CurrentStep = "Fetching channel information"
-	progress.Progress = 0.1
-	progress.ElapsedTime = time.Since(startTime)
-	if progressCallback != nil {
-		progressCallback(progress)
-	}
+	events.stage("channel_fetch", i18n.Sprintf("Fetching channel information"), 0.1)
 
-	channelFetchStart := time.Now()
-	channel, err := s.fetchChannelInfo(options.ChannelID)
+	stageCtx, endStage := startStage(ctx, "channel_fetch")
+	channel, err := s.fetchChannelInfo(stageCtx, options.ChannelID)
+	channelFetchDuration := endStage(err)
 	if err != nil {
 		return &models.ExportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to fetch channel info: %v", err),
 		}, err
 	}
-	channelFetchDuration := time.Since(channelFetchStart)
 
 	// Step 2: Fetch all messages
-	progress.Stage = "message_fetch"
-	progress.CurrentStep = "Fetching channel messages"
-	progress.Progress = 0.2
-	progress.ElapsedTime = time.Since(startTime)
-	if progressCallback != nil {
-		progressCallback(progress)
+	events.stage("message_fetch", i18n.Sprintf("Fetching channel messages"), 0.2)
+
+	eta := newETATracker(channel, time.Now())
+	limits := newExportLimits(options, startTime)
+	stageCtx, endStage = startStage(ctx, "message_fetch")
+	messages, pageWarnings, err := s.fetchAllMessages(stageCtx, options, events, eta, limits)
+	messageFetchDuration := endStage(err)
+	historyTruncated := limits.truncated()
+	if err != nil && options.BestEffort && len(messages) > 0 {
+		warn(fmt.Sprintf("message history incomplete after %d messages: %v", len(messages), err))
+		err = nil
 	}
-
-	messageFetchStart := time.Now()
-	messages, err := s.fetchAllMessages(options, &progress, progressCallback, startTime)
 	if err != nil {
 		return &models.ExportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to fetch messages: %v", err),
 		}, err
 	}
-	messageFetchDuration := time.Since(messageFetchStart)
+
+	// Compare the fetched messages with the previous export. Only the window
+	// of history fetched is compared; messages outside it are carried over.
+	var changes *models.ExportChanges
+	var snapshot map[string]models.MessageVersion
+	if options.TrackChanges {
+		window, fetched := fetchedWindow(options, messages, historyTruncated)
+		switch {
+		case len(warnings) > 0:
+			warn("change tracking skipped because the message history is incomplete")
+		case !fetched:
+			snapshot = options.Baseline
+		default:
+			current := snapshotMessages(messages)
+			if options.Baseline != nil {
+				changes = diffSnapshots(options.Baseline, current, window)
+				if !options.BaselineTaken.IsZero() {
+					changes.Since = options.BaselineTaken
+				}
+			}
+			snapshot = mergeSnapshots(options.Baseline, current, window)
+		}
+	}
+	// Missing details such as huddle rooms leave the history itself complete,
+	// so they are reported after change tracking
+	warn(pageWarnings...)
+
+	// Remove page-boundary duplicates before fetching threads
+	messages, duplicates := normalizeMessages(messages)
+
+	// Channel events feed the timeline even when the filters drop them
+	var channelEvents []models.Message
+	if options.IncludeTimeline {
+		channelEvents = channelEventMessages(messages)
+	}
+
+	// Drop noise such as join messages before fetching their threads. The
+	// parents of threads still to be fetched stay as placeholders.
+	messages = excludeNoise(messages, options, options.IncludeThreads)
 
 	// Step 3: Fetch thread replies if enabled
 	var threadFetchDuration time.Duration
 	if options.IncludeThreads {
-		progress.Stage = "thread_fetch"
-		progress.CurrentStep = "Fetching thread replies"
-		progress.Progress = 0.6
-		progress.ElapsedTime = time.Since(startTime)
-		if progressCallback != nil {
-			progressCallback(progress)
-		}
+		events.stage("thread_fetch", i18n.Sprintf("Fetching thread replies"), 0.6)
 
-		threadFetchStart := time.Now()
-		err = s.fetchThreadReplies(messages, options.ChannelID, &progress, progressCallback, startTime)
+		stageCtx, endStage = startStage(ctx, "thread_fetch")
+		threadWarnings, err := s.fetchThreadReplies(stageCtx, messages, options, events, eta, limits)
+		threadFetchDuration = endStage(err)
+		// Failed threads were reported as they happened
+		warnings = append(warnings, threadWarnings...)
 		if err != nil {
 			return &models.ExportResult{
 				Success: false,
 				Error:   fmt.Sprintf("Failed to fetch thread replies: %v", err),
 			}, err
 		}
-		threadFetchDuration = time.Since(threadFetchStart)
 	}
 
-	// Step 4: Fetch user information
-	progress.Stage = "user_fetch"
-	progress.CurrentStep = "Fetching user information"
-	progress.Progress = 0.8
-	progress.ElapsedTime = time.Since(startTime)
-	if progressCallback != nil {
-		progressCallback(progress)
+	// Remove duplicate replies and top-level copies of thread replies
+	messages, removed := normalizeMessages(messages)
+	duplicates += removed
+
+	// The fetched replies go through the same noise filters
+	messages = excludeNoise(messages, options, false)
+
+	// An interrupted export keeps what it fetched for its checkpoint
+	var fetchedMessages []models.Message
+	if limits.interrupted() {
+		fetchedMessages = append(fetchedMessages, messages...)
 	}
 
-	userFetchStart := time.Now()
-	users, err := s.fetchUserInfo(messages)
+	// Apply user and text filters once threads are known
+	if keep != nil {
+		messages = FilterMessages(messages, keep)
+	}
+	if options.ParticipatedThreads {
+		messages = ParticipatedThreads(messages, options.Users)
+	}
+	if options.ThreadsOnly {
+		messages = ThreadedMessages(messages)
+	}
+	if messages, err = s.runMessageHooks(ctx, messages); err != nil {
+		return &models.ExportResult{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
+
+	// Step 4: Fetch user information
+	events.stage("user_fetch", i18n.Sprintf("Fetching user information"), 0.8)
+
+	stageCtx, endStage = startStage(ctx, "user_fetch")
+	// The directory names the members of the timeline as well
+	userMessages := messages
+	if len(channelEvents) > 0 {
+		userMessages = append(append([]models.Message(nil), messages...), channelEvents...)
+	}
+	// Authors from other workspaces are told apart by the exporting one
+	homeTeam := ""
+	if identity != nil {
+		homeTeam = identity.TeamID
+	}
+	var users map[string]models.User
+	err = s.retry(stageCtx, options, func() (err error) {
+		users, err = s.fetchUserInfo(stageCtx, userMessages, homeTeam)
+		return err
+	})
+	userFetchDuration := endStage(err)
+	if err != nil && options.BestEffort {
+		warn(fmt.Sprintf("user directory unavailable: %v", err))
+		users, err = make(map[string]models.User), nil
+	}
 	if err != nil {
 		return &models.ExportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to fetch user info: %v", err),
 		}, err
 	}
-	userFetchDuration := time.Since(userFetchStart)
+	if options.ExcludeExternal {
+		messages = ExcludeExternal(messages, users)
+	}
 
 	// Step 5: Process and structure data
-	progress.Stage = "data_processing"
-	progress.CurrentStep = "Processing and structuring data"
-	progress.Progress = 0.9
-	progress.ElapsedTime = time.Since(startTime)
-	if progressCallback != nil {
-		progressCallback(progress)
-	}
+	events.stage("data_processing", i18n.Sprintf("Processing and structuring data"), 0.9)
 
-	dataProcessingStart := time.Now()
+	_, endStage = startStage(ctx, "data_processing")
 	exportData, statistics := s.processExportData(channel, messages, users, options, startTime)
-	dataProcessingDuration := time.Since(dataProcessingStart)
+	exportData.Changes = changes
+	exportData.ExportInfo.Truncated = limits.result(messages)
+	exportData.Statistics.DuplicatesRemoved = duplicates
+	statistics.DuplicatesRemoved = duplicates
+	if s.transform != nil {
+		if exportData.Messages, err = s.transform.Apply(exportData.Messages, exportData.Channel); err != nil {
+			endStage(err)
+			return &models.ExportResult{
+				Success: false,
+				Error:   err.Error(),
+			}, err
+		}
+		// Statistics describe the messages the script kept
+		statistics = s.calculateStatistics(keptMessages(messages, exportData.Messages), users, options.Location)
+		statistics.DuplicatesRemoved = duplicates
+		exportData.Statistics = statistics
+		exportData.ExportInfo.Transform = s.transform.Name()
+	}
+	notice(accountWarnings...)
+	exportData.ExportInfo.Workspace = workspace
+	if identity != nil {
+		exportData.ExportInfo.ExportedBy = identity.User
+		exportData.ExportInfo.Exporter = identity
+	}
+	if options.IncludeFileInfo {
+		fileWarnings, err := s.enrichFiles(ctx, exportData.Messages, options)
+		if err != nil {
+			fileWarnings = append(fileWarnings, fmt.Sprintf("file details unavailable: %v", err))
+		}
+		warn(fileWarnings...)
+	}
+	if options.IncludeUserGroups {
+		groups, err := s.collectUserGroups(ctx, exportData.Messages)
+		if err != nil {
+			warn(fmt.Sprintf("user groups unavailable: %v", err))
+		}
+		exportData.UserGroups = groups
+	}
+	if s.botClient != nil && !s.botScopeMissing.Load() {
+		bots, err := s.collectBots(ctx, exportData.Messages)
+		switch {
+		case errors.Is(err, models.ErrMissingScope):
+			// Bot details are optional; say so once and stop asking
+			if s.botScopeMissing.CompareAndSwap(false, true) {
+				notice("bot details unavailable: the token lacks the bots:read scope")
+			}
+		case err != nil:
+			warn(fmt.Sprintf("bot details unavailable: %v", err))
+		}
+		exportData.Bots = bots
+	}
+	transformSystemMessages(exportData.Messages, options.SubtypePolicy, exportData.Users, exportData.UserGroups)
+	if options.IncludeLinks {
+		exportData.Links = ExtractLinks(exportData.Messages, exportData.Users)
+	}
+	if options.IncludeTimeline {
+		exportData.Timeline = BuildTimeline(channelEvents, exportData.Users, options.Location)
+	}
+	if options.IncludeCanvas {
+		canvases, canvasWarnings, err := s.collectCanvases(ctx, channel.ID)
+		if err != nil {
+			canvasWarnings = append(canvasWarnings, fmt.Sprintf("canvases unavailable: %v", err))
+		}
+		for i := range canvases {
+			if created := canvases[i].Created; created != nil {
+				local := created.In(models.OrLocal(options.Location))
+				canvases[i].Created = &local
+			}
+		}
+		exportData.Canvases = canvases
+		warn(canvasWarnings...)
+	}
+	if options.IncludeEmoji {
+		emoji, emojiWarnings, err := s.collectEmoji(ctx, exportData, options)
+		if err != nil {
+			emojiWarnings = append(emojiWarnings, fmt.Sprintf("custom emoji unavailable: %v", err))
+		}
+		exportData.Emoji = emoji
+		warn(emojiWarnings...)
+	}
+	if options.IncludeAvatars {
+		avatarWarnings, err := s.collectAvatars(ctx, exportData.Users, options)
+		if err != nil {
+			avatarWarnings = append(avatarWarnings, fmt.Sprintf("avatars unavailable: %v", err))
+		}
+		warn(avatarWarnings...)
+	}
+	if options.SnapshotLinks {
+		if err := s.snapshotLinks(ctx, exportData.Messages, options); err != nil {
+			warn(fmt.Sprintf("link snapshots unavailable: %v", err))
+		}
+	}
+	if options.SummarizeBy != "" {
+		summaries, summaryWarnings, err := s.summarize(ctx, exportData, options.SummarizeBy)
+		if err != nil {
+			summaryWarnings = append(summaryWarnings, fmt.Sprintf("summaries unavailable: %v", err))
+		}
+		exportData.Summaries = summaries
+		warn(summaryWarnings...)
+	}
+	exportData.ExportInfo.Partial = partial
+	exportData.ExportInfo.Warnings = warnings
+	// Everything above walks nested threads; the flat layout comes last
+	if options.FlatReplies {
+		exportData.Messages = FlattenReplies(exportData.Messages)
+		exportData.ExportInfo.FlatReplies = true
+	}
+	err = s.runBeforeWriteHooks(ctx, &exportData)
+	dataProcessingDuration := endStage(err)
+	if err != nil {
+		return &models.ExportResult{
+			Success: false,
+			Error:   err.Error(),
+		}, err
+	}
 
-	// Step 6: Generate output file
-	progress.Stage = "file_generation"
-	progress.CurrentStep = "Generating output file"
-	progress.Progress = 0.95
-	progress.ElapsedTime = time.Since(startTime)
-	if progressCallback != nil {
-		progressCallback(progress)
+	// Step 6: Generate output file. An interrupted export writes what it
+	// fetched under a partial name.
+	events.stage("file_generation", i18n.Sprintf("Generating output file"), 0.95)
+
+	finalOutput := options.OutputFile
+	if limits.interrupted() {
+		options.OutputFile = PartialFileName(options.OutputFile)
 	}
 
-	fileGenerationStart := time.Now()
-	outputFile, fileSize, err := s.generateOutputFile(exportData, options)
+	stageCtx, endStage = startStage(ctx, "file_generation")
+	var outputFile string
+	var fileSize int64
+	var parts []string
+	if options.Layout == LayoutSlackNative {
+		outputFile, fileSize, parts, err = s.generateNativeOutput(stageCtx, exportData, options)
+	} else if options.SplitBy != "" {
+		var spec SplitSpec
+		if spec, err = ParseSplitBy(options.SplitBy); err == nil {
+			outputFile, fileSize, parts, err = s.generateSplitOutput(stageCtx, exportData, options, spec)
+		}
+	} else {
+		outputFile, fileSize, err = s.generateOutputFile(stageCtx, exportData, options)
+	}
+	var manifestFile string
+	if err == nil && options.Manifest && IsLocalOutput(outputFile) {
+		manifestFile, err = writeManifest(exportData, options, append(parts, outputFile), warnings)
+	}
+	var checkpointFile string
+	if err == nil && limits.interrupted() && IsLocalOutput(finalOutput) {
+		checkpointFile, err = writeCheckpoint(options.ChannelID, finalOutput, outputFile, fetchedMessages, limits)
+	}
+	fileGenerationDuration := endStage(err)
 	if err != nil {
+		if models.ErrorCategoryOf(err) == models.ErrorCategoryUnknown {
+			err = models.NewExportError(models.ErrorCategoryIO, "", err)
+		}
 		return &models.ExportResult{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to generate output file: %v", err),
 		}, err
 	}
-	fileGenerationDuration := time.Since(fileGenerationStart)
 
 	// Complete
 	totalDuration := time.Since(startTime)
-	progress.Stage = "complete"
-	progress.CurrentStep = "Export completed successfully"
-	progress.Progress = 1.0
-	progress.ElapsedTime = totalDuration
-	if progressCallback != nil {
-		progressCallback(progress)
-	}
+	events.stage("complete", i18n.Sprintf("Export completed successfully"), 1.0)
 
 	// Update processing times in statistics
 	statistics.ExportDuration = totalDuration
@@ -190,12 +533,75 @@ func (s *ExportService) ExportChannel(options models.ExportOptions, progressCall
 		FileSize:   fileSize,
 		Statistics: statistics,
 		Duration:   totalDuration,
+		Warnings:   warnings,
+		Partial:    partial,
+		Changes:    changes,
+		Parts:      parts,
+		Truncated:  exportData.ExportInfo.Truncated,
+		Manifest:   manifestFile,
+		Checkpoint: checkpointFile,
+		Snapshot:   snapshot,
 	}, nil
 }
 
+// exportPageSize returns the number of messages requested per history page
+func exportPageSize(options models.ExportOptions) int {
+	if options.PageSize > 0 {
+		return options.PageSize
+	}
+	return defaultPageSize
+}
+
+// requestDelay returns the pause between paginated and thread requests
+func requestDelay(options models.ExportOptions) time.Duration {
+	switch {
+	case options.ThreadDelay < 0:
+		return 0
+	case options.ThreadDelay > 0:
+		return options.ThreadDelay
+	}
+	return defaultRequestDelay
+}
+
+// retry calls fetch until it succeeds, up to bestEffortAttempts times in
+// best-effort mode and once otherwise, backing off exponentially between
+// attempts. It returns the error of the last attempt.
+func (s *ExportService) retry(ctx context.Context, options models.ExportOptions, fetch func() error) error {
+	attempts := 1
+	if options.BestEffort {
+		attempts = bestEffortAttempts
+	}
+	wait := s.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fetch()
+		if err == nil || attempt >= attempts {
+			return err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
+
+// ChannelInfoClientInterface defines the Slack API operation that describes
+// a channel missing from the channel list
+type ChannelInfoClientInterface interface {
+	GetChannelInfo(ctx context.Context, channelID string) (*models.Channel, error)
+}
+
+// SetChannelInfoClient lets exports by channel ID find archived channels and
+// channels the token is not a member of, which the channel list leaves out
+func (s *ExportService) SetChannelInfoClient(client ChannelInfoClientInterface) {
+	s.infoClient = client
+}
+
 // fetchChannelInfo retrieves detailed channel information
-func (s *ExportService) fetchChannelInfo(channelID string) (*models.Channel, error) {
-	ctx := context.Background()
+func (s *ExportService) fetchChannelInfo(ctx context.Context, channelID string) (*models.Channel, error) {
 	channels, err := s.slackClient.GetChannels(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get channels: %w", err)
@@ -207,75 +613,175 @@ func (s *ExportService) fetchChannelInfo(channelID string) (*models.Channel, err
 		}
 	}
 
-	return nil, fmt.Errorf("channel with ID %s not found", channelID)
+	if s.infoClient != nil {
+		channel, err := s.infoClient.GetChannelInfo(ctx, channelID)
+		if err == nil {
+			return channel, nil
+		}
+		if models.ErrorCategoryOf(err) != models.ErrorCategoryChannelNotFound {
+			return nil, err
+		}
+	}
+
+	return nil, models.NewExportError(models.ErrorCategoryChannelNotFound, fmt.Sprintf("channel with ID %s not found", channelID), nil)
 }
 
-// fetchAllMessages retrieves all messages from the channel with pagination
-func (s *ExportService) fetchAllMessages(options models.ExportOptions, progress *models.ExportProgress, progressCallback func(models.ExportProgress), startTime time.Time) ([]models.Message, error) {
+// fetchAllMessages retrieves all messages from the channel with pagination,
+// along with warnings about message details the pages were missing. On
+// failure it returns the messages fetched so far along with the error.
+func (s *ExportService) fetchAllMessages(ctx context.Context, options models.ExportOptions, events *exportEvents, eta *etaTracker, limits *exportLimits) ([]models.Message, []string, error) {
 	var allMessages []models.Message
+	var warnings []string
 	var cursor string
+	var fetchErr error
 	pageCount := 0
+	threads := 0
+
+	// A resumed export continues after the messages of its checkpoint
+	if resume := options.Resume; resume != nil {
+		allMessages = append(allMessages, resume.Messages...)
+		if resume.Cursor == "" {
+			sortMessages(allMessages)
+			return allMessages, nil, nil
+		}
+		cursor = resume.Cursor
+	}
+
+	// Let Slack apply the date range
+	var oldest, latest string
+	if options.DateFrom != nil {
+		oldest = FormatSlackTimestamp(*options.DateFrom)
+	}
+	if options.DateTo != nil {
+		latest = FormatSlackTimestamp(*options.DateTo)
+	}
+	eta.restrict(options.DateFrom, options.DateTo)
 
 	for {
 		// Fetch a page of messages
-		ctx := context.Background()
-		messages, nextCursor, err := s.slackClient.GetChannelHistory(ctx, options.ChannelID, 1000, cursor)
+		page, err := s.slackClient.GetChannelHistoryRange(ctx, options.ChannelID, exportPageSize(options), cursor, oldest, latest)
 		if err != nil {
-			return nil, fmt.Errorf("failed to fetch messages (page %d): %w", pageCount+1, err)
+			fetchErr = fmt.Errorf("failed to fetch messages (page %d): %w", pageCount+1, err)
+			break
 		}
+		messages := page.Messages
+		last := page.Last()
+		warnings = append(warnings, page.Warnings...)
+		pageStart := pageOldest(page)
 
 		// Filter messages by date range if specified
 		filteredMessages := s.filterMessagesByDate(messages, options.DateFrom, options.DateTo)
 		allMessages = append(allMessages, filteredMessages...)
 
+		// History is returned newest first, so the caps keep the newest messages
+		var limitReached bool
+		allMessages, limitReached = limits.capMessages(allMessages)
+		if !limitReached && !last {
+			limitReached = limits.expired(time.Now())
+		}
+
+		// Older pages cannot hold messages in range once a page reaches past
+		// its start, even from sources that ignore the range
+		if oldest != "" && pageStart.Before(*options.DateFrom) {
+			last = true
+		}
+
 		pageCount++
+		if options.IncludeThreads {
+			for _, msg := range filteredMessages {
+				if msg.ReplyCount > 0 && msg.ThreadTS != "" {
+					threads++
+				}
+			}
+		}
+		remaining, estimated := eta.historyRemaining(pageCount, pageStart, threads, last || limitReached)
 
 		// Update progress
-		if progressCallback != nil {
-			progress.CurrentStep = fmt.Sprintf("Fetched %d messages (%d pages)", len(allMessages), pageCount)
-			progress.MessagesTotal = len(allMessages)
-			progress.MessagesCurrent = len(allMessages)
-			progress.ElapsedTime = time.Since(startTime)
-			progressCallback(*progress)
+		events.progress.CurrentStep = i18n.Sprintf("Fetched %d messages (%d pages)", len(allMessages), pageCount)
+		events.progress.MessagesTotal = len(allMessages)
+		events.progress.MessagesCurrent = len(allMessages)
+		if estimated {
+			events.estimate(remaining)
 		}
+		events.emit(func(p models.ExportProgress) ExportEvent {
+			return PageFetched{Page: pageCount, Messages: len(filteredMessages), Total: len(allMessages), Progress: p}
+		})
 
 		// Check if we have more pages
-		if nextCursor == "" {
+		if last || limitReached {
+			if !last {
+				limits.stopHistory(page.NextCursor)
+			}
 			break
 		}
-		cursor = nextCursor
+		cursor = page.NextCursor
 
 		// Rate limiting - small delay between requests
-		time.Sleep(100 * time.Millisecond)
+		time.Sleep(requestDelay(options))
 	}
 
-	// Sort messages by timestamp (oldest first)
-	sort.Slice(allMessages, func(i, j int) bool {
-		return allMessages[i].Timestamp < allMessages[j].Timestamp
+	sortMessages(allMessages)
+	return allMessages, warnings, fetchErr
+}
+
+// sortMessages sorts messages by timestamp, oldest first
+func sortMessages(messages []models.Message) {
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Timestamp < messages[j].Timestamp
 	})
+}
 
-	return allMessages, nil
+// pageOldest returns the time of the oldest message of a history page, zero
+// for an empty page
+func pageOldest(page *models.HistoryPage) time.Time {
+	if page.Oldest == "" {
+		return time.Time{}
+	}
+	oldest, _ := models.ParseSlackTimestamp(page.Oldest)
+	return oldest
 }
 
-// fetchThreadReplies fetches replies for all threaded messages
-func (s *ExportService) fetchThreadReplies(messages []models.Message, channelID string, progress *models.ExportProgress, progressCallback func(models.ExportProgress), startTime time.Time) error {
-	// Find all messages that have threads
+// fetchThreadReplies fetches replies for all threaded messages. A thread
+// that fails ends the fetch with its error, unless options.BestEffort is
+// set: then each thread is retried and threads that still fail are skipped
+// and returned as warnings. Once the time limit is reached the remaining
+// threads are left without replies.
+func (s *ExportService) fetchThreadReplies(ctx context.Context, messages []models.Message, options models.ExportOptions, events *exportEvents, eta *etaTracker, limits *exportLimits) ([]string, error) {
+	var warnings []string
+	channelID := options.ChannelID
+
+	// Find all messages that have threads. A resumed export keeps the
+	// replies its checkpoint has.
+	fetched := resumedThreads(options.Resume)
 	var threadedMessages []*models.Message
 	for i := range messages {
-		if messages[i].ReplyCount > 0 && messages[i].ThreadTS != "" {
+		if messages[i].ReplyCount > 0 && messages[i].ThreadTS != "" && !fetched[messages[i].ThreadTS] {
 			threadedMessages = append(threadedMessages, &messages[i])
 		}
 	}
 
-	progress.ThreadsTotal = len(threadedMessages)
+	events.progress.ThreadsTotal = len(threadedMessages)
 
 	// Fetch replies for each threaded message
 	for i, msg := range threadedMessages {
-		ctx := context.Background()
-		replies, err := s.slackClient.GetThreadReplies(ctx, channelID, msg.ThreadTS)
+		if limits.expired(time.Now()) {
+			limits.skipThreads(threadedMessages[i:])
+			break
+		}
+
+		var replies []models.Message
+		err := s.retry(ctx, options, func() (err error) {
+			replies, err = s.slackClient.GetThreadReplies(ctx, channelID, msg.ThreadTS)
+			return err
+		})
+		if err != nil && !options.BestEffort {
+			return warnings, fmt.Errorf("thread %s: %w", msg.ThreadTS, err)
+		}
 		if err != nil {
-			// Log warning but continue with export
-			fmt.Printf("Warning: Failed to fetch replies for thread %s: %v\n", msg.ThreadTS, err)
+			// Record a warning but continue with export
+			warning := fmt.Sprintf("thread %s: replies unavailable: %v", msg.ThreadTS, err)
+			warnings = append(warnings, warning)
+			events.warn(warning)
 			continue
 		}
 
@@ -290,25 +796,31 @@ func (s *ExportService) fetchThreadReplies(messages []models.Message, channelID
 		})
 
 		msg.Thread = replies
+		remaining := eta.threadsRemaining(i+1, len(threadedMessages))
 
 		// Update progress
-		if progressCallback != nil {
-			progress.ThreadsCurrent = i + 1
-			progress.CurrentStep = fmt.Sprintf("Fetched replies for %d/%d threads", i+1, len(threadedMessages))
-			progress.ElapsedTime = time.Since(startTime)
-			progressCallback(*progress)
+		events.progress.ThreadsCurrent = i + 1
+		events.progress.CurrentStep = i18n.Sprintf("Fetched replies for %d/%d threads", i+1, len(threadedMessages))
+		if eta != nil {
+			events.estimate(remaining)
 		}
+		events.emit(func(p models.ExportProgress) ExportEvent {
+			return ThreadFetched{ThreadTS: msg.ThreadTS, Replies: len(replies), Current: i + 1, Total: len(threadedMessages), Progress: p}
+		})
 
 		// Rate limiting
-		time.Sleep(100 * time.Millisecond)
+		time.Sleep(requestDelay(options))
 	}
 
-	return nil
+	return warnings, nil
 }
 
-// fetchUserInfo retrieves user information for all users mentioned in messages
-func (s *ExportService) fetchUserInfo(messages []models.Message) (map[string]models.User, error) {
+// fetchUserInfo retrieves user information for all users mentioned in
+// messages. homeTeam is the exporting workspace from auth.test; without it,
+// authors missing from the directory cannot be told external.
+func (s *ExportService) fetchUserInfo(ctx context.Context, messages []models.Message, homeTeam string) (map[string]models.User, error) {
 	userIDs := make(map[string]bool)
+	teams := make(map[string]string)
 
 	// Collect all unique user IDs from messages and threads
 	var collectUserIDs func([]models.Message)
@@ -316,6 +828,9 @@ func (s *ExportService) fetchUserInfo(messages []models.Message) (map[string]mod
 		for _, msg := range msgs {
 			if msg.User != "" {
 				userIDs[msg.User] = true
+				if msg.Team != "" {
+					teams[msg.User] = msg.Team
+				}
 			}
 			// Collect from thread replies
 			collectUserIDs(msg.Thread)
@@ -325,7 +840,6 @@ func (s *ExportService) fetchUserInfo(messages []models.Message) (map[string]mod
 	collectUserIDs(messages)
 
 	// Fetch all users from workspace
-	ctx := context.Background()
 	allUsers, err := s.slackClient.GetUsers(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch users: %w", err)
@@ -335,18 +849,33 @@ func (s *ExportService) fetchUserInfo(messages []models.Message) (map[string]mod
 	users := make(map[string]models.User)
 	for _, user := range allUsers {
 		if userIDs[user.ID] {
+			user.IsExternal = user.IsStranger
 			users[user.ID] = user
 		}
 	}
 
-	// Create placeholder users for any missing user IDs
+	// Create placeholder users for any missing user IDs. The directory only
+	// lists the workspace's members, so authors from another workspace are
+	// members of other organizations, e.g. in Slack Connect channels.
 	for userID := range userIDs {
 		if _, exists := users[userID]; !exists {
+			team := teams[userID]
+			if team != "" && homeTeam != "" && team != homeTeam {
+				users[userID] = models.User{
+					ID:         userID,
+					Name:       fmt.Sprintf("user_%s", userID),
+					RealName:   "External User",
+					TeamID:     team,
+					IsExternal: true,
+				}
+				continue
+			}
 			users[userID] = models.User{
 				ID:       userID,
 				Name:     fmt.Sprintf("user_%s", userID),
 				RealName: "Unknown User",
 				Deleted:  true,
+				TeamID:   team,
 			}
 		}
 	}
@@ -383,10 +912,15 @@ func (s *ExportService) filterMessagesByDate(messages []models.Message, dateFrom
 
 // processExportData converts raw data into export format and calculates statistics
 func (s *ExportService) processExportData(channel *models.Channel, messages []models.Message, users map[string]models.User, options models.ExportOptions, startTime time.Time) (models.ChannelExport, models.ExportStatistics) {
+	loc := models.OrLocal(options.Location)
+
 	// Convert messages to export format
 	var exportMessages []models.ExportMessage
 	for _, msg := range messages {
-		exportMsg := models.ConvertToExportMessage(msg)
+		exportMsg := models.ConvertToExportMessage(msg, loc)
+		if options.WorkspaceURL != "" {
+			addPermalinks(&exportMsg, options.WorkspaceURL, channel.ID)
+		}
 		exportMessages = append(exportMessages, exportMsg)
 	}
 
@@ -397,7 +931,7 @@ func (s *ExportService) processExportData(channel *models.Channel, messages []mo
 	}
 
 	// Calculate statistics
-	statistics := s.calculateStatistics(messages, users)
+	statistics := s.calculateStatistics(messages, users, loc)
 
 	// Create channel info
 	channelInfo := models.ChannelInfo{
@@ -405,7 +939,11 @@ func (s *ExportService) processExportData(channel *models.Channel, messages []mo
 		Name:       channel.Name,
 		IsPrivate:  channel.IsPrivate,
 		IsArchived: channel.IsArchived,
+		State:      channel.State(),
 		NumMembers: channel.NumMembers,
+
+		IsExtShared:      channel.IsExtShared,
+		ConnectedTeamIDs: channel.ConnectedTeamIDs,
 	}
 
 	if channel.Topic.Value != "" {
@@ -415,7 +953,7 @@ func (s *ExportService) processExportData(channel *models.Channel, messages []mo
 		channelInfo.Purpose = channel.Purpose.Value
 	}
 	if channel.Created > 0 {
-		channelInfo.CreatedAt = time.Unix(channel.Created, 0)
+		channelInfo.CreatedAt = time.Unix(channel.Created, 0).In(loc)
 	}
 	if channel.Creator != "" {
 		channelInfo.Creator = channel.Creator
@@ -423,11 +961,28 @@ func (s *ExportService) processExportData(channel *models.Channel, messages []mo
 
 	// Create export metadata
 	exportInfo := models.ExportMetadata{
-		ExportedAt:     time.Now(),
+		SchemaURL:      models.ExportSchemaURL,
+		SchemaVersion:  models.ExportSchemaVersion,
+		ExportedAt:     time.Now().In(loc),
 		ExportedBy:     "slacker-cli",
 		SlackerVersion: s.version,
 		ExportFormat:   options.Format,
 		IncludeThreads: options.IncludeThreads,
+		Timezone:       loc.String(),
+	}
+
+	if len(options.Users) > 0 || options.Match != "" || len(options.ExcludeSubtypes) > 0 || len(options.SubtypePolicy) > 0 || options.Bots != "" || options.MinReactions > 0 || options.ExcludeExternal || options.ThreadsOnly || options.ParticipatedThreads {
+		exportInfo.Filters = &models.ExportFilters{
+			Users:               options.Users,
+			Match:               options.Match,
+			ExcludeSubtypes:     options.ExcludeSubtypes,
+			SubtypePolicy:       options.SubtypePolicy,
+			Bots:                options.Bots,
+			MinReactions:        options.MinReactions,
+			ExcludeExternal:     options.ExcludeExternal,
+			ThreadsOnly:         options.ThreadsOnly,
+			ParticipatedThreads: options.ParticipatedThreads,
+		}
 	}
 
 	if options.DateFrom != nil || options.DateTo != nil {
@@ -449,51 +1004,89 @@ func (s *ExportService) processExportData(channel *models.Channel, messages []mo
 	return exportData, statistics
 }
 
+// addPermalinks sets the permalink of a message and its thread replies
+func addPermalinks(msg *models.ExportMessage, workspaceURL, channelID string) {
+	msg.Permalink = models.Permalink(workspaceURL, channelID, msg.ID, msg.ThreadTimestamp)
+	for i := range msg.Replies {
+		reply := &msg.Replies[i]
+		reply.Permalink = models.Permalink(workspaceURL, channelID, reply.ID, msg.ID)
+	}
+}
+
 // calculateStatistics computes various statistics about the export
-func (s *ExportService) calculateStatistics(messages []models.Message, users map[string]models.User) models.ExportStatistics {
+func (s *ExportService) calculateStatistics(messages []models.Message, users map[string]models.User, loc *time.Location) models.ExportStatistics {
+	loc = models.OrLocal(loc)
 	stats := models.ExportStatistics{
 		MessagesByUser: make(map[string]int),
 		MessagesByDate: make(map[string]int),
 	}
 
+	files := newFileUsage()
+	mentions := newMentionCounter()
 	var countMessages func([]models.Message)
 	countMessages = func(msgs []models.Message) {
 		for _, msg := range msgs {
+			// Broadcast links are counted as replies in their thread
+			if msg.InThread {
+				continue
+			}
+			// Count threads and replies
+			if len(msg.Thread) > 0 {
+				stats.TotalThreads++
+				stats.TotalReplies += len(msg.Thread)
+				countMessages(msg.Thread) // Recursively count thread messages
+			}
+			// A parent omitted by the filters counts by its thread only
+			if msg.Omitted {
+				continue
+			}
+
+			if msg.Subtype == models.SubtypeThreadBroadcast {
+				stats.BroadcastReplies++
+			}
 			stats.TotalMessages++
+			if users[msg.User].IsExternal {
+				stats.ExternalMessages++
+			}
+			if msg.IsBot() {
+				stats.BotMessages++
+			} else {
+				stats.HumanMessages++
+			}
 
 			// Count by user
 			if msg.User != "" {
 				stats.MessagesByUser[msg.User]++
 			}
+			mentions.add(msg.User, msg.Text)
 
 			// Count by date
 			if timestamp, err := models.ParseSlackTimestamp(msg.Timestamp); err == nil {
-				dateKey := timestamp.Format("2006-01-02")
+				dateKey := timestamp.In(loc).Format("2006-01-02")
 				stats.MessagesByDate[dateKey]++
 			}
 
 			// Count attachments
 			stats.TotalAttachments += len(msg.Attachments)
 
-			// Count files
+			// Count files and their sizes
 			stats.TotalFiles += len(msg.Files)
+			for _, file := range msg.Files {
+				files.add(msg.User, file.Filetype, file.Size)
+			}
 
 			// Count reactions
 			for _, reaction := range msg.Reactions {
 				stats.TotalReactions += reaction.Count
 			}
-
-			// Count threads and replies
-			if len(msg.Thread) > 0 {
-				stats.TotalThreads++
-				stats.TotalReplies += len(msg.Thread)
-				countMessages(msg.Thread) // Recursively count thread messages
-			}
 		}
 	}
 
 	countMessages(messages)
 	stats.TotalUsers = len(users)
+	stats.ThreadActivity = threadStatistics(messages)
+	files.apply(&stats)
+	stats.Mentions = mentions.result()
 
 	// Calculate top reactions
 	reactionCounts := make(map[string]int)
@@ -537,102 +1130,124 @@ func (s *ExportService) calculateStatistics(messages []models.Message, users map
 }
 
 // generateOutputFile creates the final export file
-func (s *ExportService) generateOutputFile(exportData models.ChannelExport, options models.ExportOptions) (string, int64, error) {
+func (s *ExportService) generateOutputFile(ctx context.Context, exportData models.ChannelExport, options models.ExportOptions) (string, int64, error) {
 	// Ensure output directory exists
 	outputDir := filepath.Dir(options.OutputFile)
-	if outputDir != "." {
+	if outputDir != "." && IsLocalOutput(options.OutputFile) {
 		if err := os.MkdirAll(outputDir, 0755); err != nil {
 			return "", 0, fmt.Errorf("failed to create output directory: %w", err)
 		}
 	}
 
-	// Marshal JSON based on format
+	if options.Format == "pdf" {
+		data, err := renderPDF(exportData)
+		if err != nil {
+			return "", 0, err
+		}
+		return s.writeOutput(ctx, options.OutputFile, data, options)
+	}
+	if options.Format == FormatLLMJSONL {
+		chunks, err := renderLLMChunks(exportData, options.ChunkTokens, options.TextOnly)
+		if err != nil {
+			return "", 0, err
+		}
+		return s.writeOutput(ctx, options.OutputFile, chunks, options)
+	}
+
+	if err := addIntegrity(&exportData); err != nil {
+		return "", 0, err
+	}
+	jsonData, err := marshalExport(exportData, options.Format)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return s.writeOutput(ctx, options.OutputFile, jsonData, options)
+}
+
+// ConvertExport writes an existing export in options.Format to
+// options.OutputFile. The integrity footer of JSON output is recomputed, so
+// callers check the input with ReadVerifiedExport first.
+func (s *ExportService) ConvertExport(ctx context.Context, export *models.ChannelExport, options models.ExportOptions) (string, int64, error) {
+	converted := *export
+	converted.Integrity = nil
+	return s.generateOutputFile(ctx, converted, options)
+}
+
+// FormatExtension returns the file extension for an export format
+func FormatExtension(format string) string {
+	switch format {
+	case "pdf":
+		return ".pdf"
+	case FormatLLMJSONL:
+		return ".jsonl"
+	}
+	return ".json"
+}
+
+// marshalExport encodes v in the requested JSON format
+func marshalExport(v interface{}, format string) ([]byte, error) {
 	var jsonData []byte
 	var err error
 
-	switch options.Format {
+	switch format {
 	case "json-pretty":
-		jsonData, err = json.MarshalIndent(exportData, "", "  ")
+		jsonData, err = json.MarshalIndent(v, "", "  ")
 	case "json-compact":
-		jsonData, err = json.Marshal(exportData)
+		jsonData, err = json.Marshal(v)
 	default: // "json"
-		jsonData, err = json.MarshalIndent(exportData, "", "  ")
+		jsonData, err = json.MarshalIndent(v, "", "  ")
 	}
 
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to marshal export data: %w", err)
+		return nil, fmt.Errorf("failed to marshal export data: %w", err)
 	}
+	return jsonData, nil
+}
 
-	// Handle compression
-	outputFile := options.OutputFile
+// writeOutput writes data to its destination through an OutputSink,
+// applying the requested compression
+func (s *ExportService) writeOutput(ctx context.Context, outputFile string, data []byte, options models.ExportOptions) (string, int64, error) {
 	switch options.Compression {
 	case "gzip":
-		if !strings.HasSuffix(outputFile, ".gz") {
+		if outputFile != StdoutOutput && !strings.HasSuffix(outputFile, ".gz") {
 			outputFile += ".gz"
 		}
-		return s.writeGzipFile(outputFile, jsonData)
 	case "zip":
-		if !strings.HasSuffix(outputFile, ".zip") {
-			outputFile += ".zip"
-		}
-		return s.writeZipFile(outputFile, jsonData, filepath.Base(options.OutputFile))
-	default: // "none" or empty
-		return s.writeFile(outputFile, jsonData)
+		return "", 0, fmt.Errorf("zip compression not yet implemented")
 	}
-}
 
-// writeFile writes data to a regular file
-func (s *ExportService) writeFile(filename string, data []byte) (string, int64, error) {
-	err := os.WriteFile(filename, data, 0644)
-	if err != nil {
-		return "", 0, fmt.Errorf("failed to write file: %w", err)
-	}
-
-	fileInfo, err := os.Stat(filename)
-	if err != nil {
-		return "", 0, fmt.Errorf("failed to get file info: %w", err)
+	if dir := filepath.Dir(outputFile); dir != "." && IsLocalOutput(outputFile) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", 0, fmt.Errorf("failed to create output directory: %w", err)
+		}
 	}
 
-	return filename, fileInfo.Size(), nil
-}
-
-// writeGzipFile writes data to a gzip-compressed file
-func (s *ExportService) writeGzipFile(filename string, data []byte) (string, int64, error) {
-	file, err := os.Create(filename)
+	sink, err := s.openSink(ctx, outputFile, options)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to create gzip file: %w", err)
+		return "", 0, err
 	}
-	defer file.Close()
 
-	gzipWriter := gzip.NewWriter(file)
-	defer gzipWriter.Close()
-
-	_, err = gzipWriter.Write(data)
-	if err != nil {
-		return "", 0, fmt.Errorf("failed to write gzip data: %w", err)
+	var out io.Writer = sink
+	var gzipWriter *gzip.Writer
+	if options.Compression == "gzip" {
+		gzipWriter = gzip.NewWriter(sink)
+		out = gzipWriter
 	}
-
-	err = gzipWriter.Close()
-	if err != nil {
-		return "", 0, fmt.Errorf("failed to close gzip writer: %w", err)
+	if _, err := out.Write(data); err != nil {
+		sink.Abort()
+		return "", 0, fmt.Errorf("failed to write export: %w", err)
 	}
-
-	err = file.Close()
-	if err != nil {
-		return "", 0, fmt.Errorf("failed to close file: %w", err)
+	if gzipWriter != nil {
+		if err := gzipWriter.Close(); err != nil {
+			sink.Abort()
+			return "", 0, fmt.Errorf("failed to close gzip writer: %w", err)
+		}
 	}
 
-	fileInfo, err := os.Stat(filename)
+	size, err := sink.Commit()
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to get file info: %w", err)
+		return "", 0, err
 	}
-
-	return filename, fileInfo.Size(), nil
-}
-
-// writeZipFile writes data to a zip-compressed file
-func (s *ExportService) writeZipFile(filename string, data []byte, entryName string) (string, int64, error) {
-	// For now, we'll implement a simple approach without the archive/zip package
-	// This can be enhanced later if zip compression is needed
-	return "", 0, fmt.Errorf("zip compression not yet implemented")
+	return outputFile, size, nil
 }
@@ -2,9 +2,15 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/itcaat/slacker/internal/audit"
 	"github.com/itcaat/slacker/models"
 )
 
@@ -14,6 +20,17 @@ type MockSlackClient struct {
 	messages []models.Message
 	users    []models.User
 	threads  map[string][]models.Message
+
+	// Errors returned instead of data, for failure tests
+	usersErr  error
+	threadErr error
+	// threadCalls counts GetThreadReplies calls
+	threadCalls int
+
+	// historyLimit records the page size of the last history request
+	historyLimit int
+	// historyOldest and historyLatest record the range of the last history request
+	historyOldest, historyLatest string
 }
 
 func NewMockSlackClient() *MockSlackClient {
@@ -128,15 +145,40 @@ func (m *MockSlackClient) GetChannels(ctx context.Context) ([]models.Channel, er
 	return m.channels, nil
 }
 
-func (m *MockSlackClient) GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) ([]models.Message, string, error) {
+func (m *MockSlackClient) GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) (*models.HistoryPage, error) {
+	m.historyLimit = limit
 	// Simple implementation - return all messages for the first call
 	if cursor == "" {
-		return m.messages, "", nil
+		return models.NewHistoryPage(m.messages, "", false), nil
+	}
+	return models.NewHistoryPage([]models.Message{}, "", false), nil
+}
+
+func (m *MockSlackClient) GetChannelHistoryRange(ctx context.Context, channelID string, limit int, cursor, oldest, latest string) (*models.HistoryPage, error) {
+	m.historyOldest, m.historyLatest = oldest, latest
+	// Like an HTTP request, a call with a cancelled context fails
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	page, err := m.GetChannelHistory(ctx, channelID, limit, cursor)
+	if err != nil {
+		return nil, err
 	}
-	return []models.Message{}, "", nil
+	// Apply the range like the Slack API does
+	var inRange []models.Message
+	for _, msg := range page.Messages {
+		if (oldest == "" || msg.Timestamp >= oldest) && (latest == "" || msg.Timestamp <= latest) {
+			inRange = append(inRange, msg)
+		}
+	}
+	return models.NewHistoryPage(inRange, page.NextCursor, page.HasMore), nil
 }
 
 func (m *MockSlackClient) GetThreadReplies(ctx context.Context, channelID, threadTS string) ([]models.Message, error) {
+	m.threadCalls++
+	if m.threadErr != nil {
+		return nil, m.threadErr
+	}
 	if replies, exists := m.threads[threadTS]; exists {
 		return replies, nil
 	}
@@ -144,6 +186,9 @@ func (m *MockSlackClient) GetThreadReplies(ctx context.Context, channelID, threa
 }
 
 func (m *MockSlackClient) GetUsers(ctx context.Context) ([]models.User, error) {
+	if m.usersErr != nil {
+		return nil, m.usersErr
+	}
 	return m.users, nil
 }
 
@@ -151,7 +196,7 @@ func TestExportService_fetchChannelInfo(t *testing.T) {
 	mockClient := NewMockSlackClient()
 	service := NewExportService(mockClient, "1.0.0-test")
 
-	channel, err := service.fetchChannelInfo("C123456")
+	channel, err := service.fetchChannelInfo(context.Background(), "C123456")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -170,10 +215,32 @@ func TestExportService_fetchChannelInfo(t *testing.T) {
 	}
 
 	// Test non-existent channel
-	_, err = service.fetchChannelInfo("C999999")
+	_, err = service.fetchChannelInfo(context.Background(), "C999999")
 	if err == nil {
 		t.Error("Expected error for non-existent channel")
 	}
+
+	// Archived channels missing from the list are looked up by ID
+	service.SetChannelInfoClient(channelInfoFunc(func(ctx context.Context, channelID string) (*models.Channel, error) {
+		if channelID != "C777777" {
+			return nil, models.NewExportError(models.ErrorCategoryChannelNotFound, "channel_not_found", nil)
+		}
+		return &models.Channel{ID: channelID, Name: "old-project", IsArchived: true}, nil
+	}))
+	channel, err = service.fetchChannelInfo(context.Background(), "C777777")
+	if err != nil || channel.State() != models.ChannelStateArchived {
+		t.Errorf("Expected the archived channel, got %+v (%v)", channel, err)
+	}
+	if _, err = service.fetchChannelInfo(context.Background(), "C999999"); models.ErrorCategoryOf(err) != models.ErrorCategoryChannelNotFound {
+		t.Errorf("Expected channel_not_found, got %v", err)
+	}
+}
+
+// channelInfoFunc adapts a function to ChannelInfoClientInterface
+type channelInfoFunc func(ctx context.Context, channelID string) (*models.Channel, error)
+
+func (f channelInfoFunc) GetChannelInfo(ctx context.Context, channelID string) (*models.Channel, error) {
+	return f(ctx, channelID)
 }
 
 func TestExportService_fetchAllMessages(t *testing.T) {
@@ -184,8 +251,7 @@ func TestExportService_fetchAllMessages(t *testing.T) {
 		ChannelID: "C123456",
 	}
 
-	progress := models.ExportProgress{}
-	messages, err := service.fetchAllMessages(options, &progress, nil, time.Now())
+	messages, _, err := service.fetchAllMessages(context.Background(), options, newExportEvents(nil), nil, nil)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -216,11 +282,13 @@ func TestExportService_fetchThreadReplies(t *testing.T) {
 		},
 	}
 
-	progress := models.ExportProgress{}
-	err := service.fetchThreadReplies(messages, "C123456", &progress, nil, time.Now())
+	warnings, err := service.fetchThreadReplies(context.Background(), messages, models.ExportOptions{ChannelID: "C123456"}, newExportEvents(nil), nil, nil)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
 
 	// Check that thread replies were added
 	if len(messages[0].Thread) != 2 {
@@ -245,7 +313,7 @@ func TestExportService_fetchUserInfo(t *testing.T) {
 		{User: "U999999", Text: "Unknown user"}, // This user doesn't exist in mock
 	}
 
-	users, err := service.fetchUserInfo(messages)
+	users, err := service.fetchUserInfo(context.Background(), messages, "")
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -342,6 +410,7 @@ func TestExportService_calculateStatistics(t *testing.T) {
 		{
 			User:      "U789012",
 			Timestamp: "1704067300.000000",
+			BotID:     "B123456",
 		},
 	}
 
@@ -350,7 +419,7 @@ func TestExportService_calculateStatistics(t *testing.T) {
 		"U789012": {ID: "U789012", Name: "bob"},
 	}
 
-	stats := service.calculateStatistics(messages, users)
+	stats := service.calculateStatistics(messages, users, time.UTC)
 
 	// Check basic counts
 	if stats.TotalMessages != 3 { // 2 main messages + 1 thread reply
@@ -381,6 +450,10 @@ func TestExportService_calculateStatistics(t *testing.T) {
 		t.Errorf("Expected 6 total reactions, got %d", stats.TotalReactions)
 	}
 
+	if stats.BotMessages != 1 || stats.HumanMessages != 2 {
+		t.Errorf("Expected 1 bot and 2 human messages, got %d and %d", stats.BotMessages, stats.HumanMessages)
+	}
+
 	// Check messages by user
 	if stats.MessagesByUser["U123456"] != 1 {
 		t.Errorf("Expected 1 message from U123456, got %d", stats.MessagesByUser["U123456"])
@@ -404,6 +477,55 @@ func TestExportService_calculateStatistics(t *testing.T) {
 	}
 }
 
+func TestExportService_calculateStatisticsBroadcast(t *testing.T) {
+	service := NewExportService(nil, "1.0.0-test")
+
+	messages, _ := normalizeMessages([]models.Message{
+		{User: "U1", Timestamp: "1704067200.000000", ThreadTS: "1704067200.000000", Thread: []models.Message{
+			{User: "U2", Text: "decided", Timestamp: "1704067260.000000", ThreadTS: "1704067200.000000", Subtype: models.SubtypeThreadBroadcast},
+		}},
+		{User: "U2", Text: "decided", Timestamp: "1704067260.000000", ThreadTS: "1704067200.000000", Subtype: models.SubtypeThreadBroadcast},
+		// Channel copy of a reply whose thread started before the export range
+		{User: "U3", Text: "old thread", Timestamp: "1704067300.000000", ThreadTS: "1704000000.000000", Subtype: models.SubtypeThreadBroadcast},
+	})
+
+	stats := service.calculateStatistics(messages, nil, time.UTC)
+	if stats.TotalMessages != 3 || stats.MessagesByUser["U2"] != 1 {
+		t.Errorf("Expected the linked broadcast counted once, got %d messages and %d by U2", stats.TotalMessages, stats.MessagesByUser["U2"])
+	}
+	if stats.BroadcastReplies != 2 {
+		t.Errorf("Expected 2 broadcast replies, got %d", stats.BroadcastReplies)
+	}
+
+	exported := models.ConvertToExportMessage(messages[1], nil)
+	if !exported.InThread || exported.BroadcastOf != "1704067200.000000" {
+		t.Errorf("Expected the channel copy linked to its thread, got %+v", exported)
+	}
+}
+
+func TestExportService_calculateStatisticsOmittedParent(t *testing.T) {
+	service := NewExportService(nil, "1.0.0-test")
+
+	messages := ExcludeBots([]models.Message{
+		{BotID: "B1", Text: "deploy failed", Timestamp: "1704067200.000000", ThreadTS: "1704067200.000000", ReplyCount: 2, Thread: []models.Message{
+			{User: "U1", Text: "on it", Timestamp: "1704067260.000000"},
+			{BotID: "B1", Text: "retrying", Timestamp: "1704067320.000000"},
+		}},
+		{User: "U2", Text: "morning", Timestamp: "1704067400.000000"},
+	})
+
+	stats := service.calculateStatistics(messages, nil, time.UTC)
+	if stats.TotalMessages != 2 || stats.BotMessages != 0 || stats.HumanMessages != 2 {
+		t.Errorf("Expected the reply and the message counted, got %d messages, %d bot, %d human", stats.TotalMessages, stats.BotMessages, stats.HumanMessages)
+	}
+	if stats.TotalThreads != 1 || stats.TotalReplies != 1 {
+		t.Errorf("Expected the thread of the omitted parent counted, got %d threads, %d replies", stats.TotalThreads, stats.TotalReplies)
+	}
+	if activity := stats.ThreadActivity; activity == nil || activity.Threads != 0 || activity.Unanswered != 1 {
+		t.Errorf("Expected only the human message in thread activity, got %+v", activity)
+	}
+}
+
 func TestExportService_processExportData(t *testing.T) {
 	mockClient := NewMockSlackClient()
 	service := NewExportService(mockClient, "1.0.0-test")
@@ -486,3 +608,449 @@ func TestExportService_processExportData(t *testing.T) {
 		t.Errorf("Expected 1 total user in statistics, got %d", statistics.TotalUsers)
 	}
 }
+
+func TestExportService_ExportChannelBestEffort(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	mockClient.threadErr = errors.New("conversations.replies failed")
+	service := NewExportService(mockClient, "1.0.0-test")
+	service.retryBackoff = time.Millisecond
+
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+	}
+
+	// Without best-effort a thread failure aborts the export, as does the
+	// user fetch failure
+	if _, err := service.ExportChannel(context.Background(), options, nil); err == nil {
+		t.Fatal("Expected error for a failed thread without best-effort mode")
+	}
+	if mockClient.threadCalls != 1 {
+		t.Errorf("Expected no retries without best-effort mode, got %d calls", mockClient.threadCalls)
+	}
+	mockClient.usersErr = errors.New("users.list failed")
+	mockClient.threadErr = nil
+	if _, err := service.ExportChannel(context.Background(), options, nil); err == nil {
+		t.Fatal("Expected error without best-effort mode")
+	}
+
+	mockClient.threadErr = errors.New("conversations.replies failed")
+	mockClient.threadCalls = 0
+	options.BestEffort = true
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error in best-effort mode, got %v", err)
+	}
+	if mockClient.threadCalls != bestEffortAttempts {
+		t.Errorf("Expected the thread to be tried %d times, got %d", bestEffortAttempts, mockClient.threadCalls)
+	}
+	if !result.Partial {
+		t.Error("Expected export to be marked partial")
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Expected 2 warnings (thread and users), got %v", result.Warnings)
+	}
+
+	data, err := os.ReadFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Expected output file, got %v", err)
+	}
+	var export models.ChannelExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if !export.ExportInfo.Partial || len(export.ExportInfo.Warnings) != 2 {
+		t.Errorf("Expected partial metadata with 2 warnings, got %+v", export.ExportInfo)
+	}
+}
+
+func TestExportService_fetchAllMessagesPageSize(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	service := NewExportService(mockClient, "1.0.0-test")
+
+	if _, _, err := service.fetchAllMessages(context.Background(), models.ExportOptions{ChannelID: "C123456"}, newExportEvents(nil), nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockClient.historyLimit != defaultPageSize {
+		t.Errorf("Expected default page size %d, got %d", defaultPageSize, mockClient.historyLimit)
+	}
+
+	options := models.ExportOptions{ChannelID: "C123456", PageSize: 150}
+	if _, _, err := service.fetchAllMessages(context.Background(), options, newExportEvents(nil), nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockClient.historyLimit != 150 {
+		t.Errorf("Expected page size 150, got %d", mockClient.historyLimit)
+	}
+}
+
+func TestRequestDelay(t *testing.T) {
+	for delay, want := range map[time.Duration]time.Duration{
+		0:                    defaultRequestDelay,
+		time.Second:          time.Second,
+		models.NoThreadDelay: 0,
+	} {
+		if got := requestDelay(models.ExportOptions{ThreadDelay: delay}); got != want {
+			t.Errorf("requestDelay(%v) = %v, want %v", delay, got, want)
+		}
+	}
+}
+
+func TestExportService_fetchAllMessagesMaxMessages(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	service := NewExportService(mockClient, "1.0.0-test")
+
+	options := models.ExportOptions{ChannelID: "C123456", MaxMessages: 1}
+	limits := newExportLimits(options, time.Now())
+	messages, _, err := service.fetchAllMessages(context.Background(), options, newExportEvents(nil), nil, limits)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(messages))
+	}
+	// The first message of a history page is the newest one
+	if messages[0].Timestamp != mockClient.messages[0].Timestamp {
+		t.Errorf("Expected message %s to be kept, got %s", mockClient.messages[0].Timestamp, messages[0].Timestamp)
+	}
+}
+
+func TestExportService_fetchAllMessagesDateRange(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	service := NewExportService(mockClient, "1.0.0-test")
+
+	from := time.Unix(1704067230, 0)
+	to := time.Unix(1704067300, 0)
+	options := models.ExportOptions{ChannelID: "C123456", DateFrom: &from, DateTo: &to}
+	messages, _, err := service.fetchAllMessages(context.Background(), options, newExportEvents(nil), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockClient.historyOldest != FormatSlackTimestamp(from) || mockClient.historyLatest != FormatSlackTimestamp(to) {
+		t.Errorf("Expected range %s-%s, got %s-%s", FormatSlackTimestamp(from), FormatSlackTimestamp(to), mockClient.historyOldest, mockClient.historyLatest)
+	}
+	if len(messages) != 1 || messages[0].Timestamp != "1704067260.000000" {
+		t.Errorf("Expected only the message in range, got %+v", messages)
+	}
+
+	// Change tracking fetches only the range as well
+	options.TrackChanges = true
+	if _, _, err := service.fetchAllMessages(context.Background(), options, newExportEvents(nil), nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockClient.historyOldest != FormatSlackTimestamp(from) {
+		t.Errorf("Expected range from %s with change tracking, got %s", FormatSlackTimestamp(from), mockClient.historyOldest)
+	}
+}
+
+// pagedClient serves fixed history pages and ignores the requested range
+type pagedClient struct {
+	*MockSlackClient
+	pages    [][]models.Message
+	requests int
+}
+
+func (p *pagedClient) GetChannelHistoryRange(ctx context.Context, channelID string, limit int, cursor, oldest, latest string) (*models.HistoryPage, error) {
+	page := p.requests
+	p.requests++
+	next := ""
+	if page+1 < len(p.pages) {
+		next = fmt.Sprintf("page-%d", page+1)
+	}
+	return models.NewHistoryPage(p.pages[page], next, next != ""), nil
+}
+
+func TestExportService_fetchAllMessagesStopsPastRange(t *testing.T) {
+	client := &pagedClient{
+		MockSlackClient: NewMockSlackClient(),
+		pages: [][]models.Message{
+			{{Timestamp: "1704067500.000000"}, {Timestamp: "1704067400.000000"}},
+			{{Timestamp: "1704067300.000000"}, {Timestamp: "1704067200.000000"}},
+			{{Timestamp: "1704067100.000000"}, {Timestamp: "1704067000.000000"}},
+		},
+	}
+	service := NewExportService(client, "1.0.0-test")
+
+	from := time.Unix(1704067250, 0)
+	options := models.ExportOptions{ChannelID: "C123456", DateFrom: &from, ThreadDelay: time.Millisecond}
+	messages, _, err := service.fetchAllMessages(context.Background(), options, newExportEvents(nil), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.requests != 2 {
+		t.Errorf("Expected pagination to stop after 2 pages, got %d requests", client.requests)
+	}
+	if len(messages) != 3 {
+		t.Errorf("Expected 3 messages in range, got %d", len(messages))
+	}
+}
+
+// finalCursorClient returns a cursor with has_more unset, as Slack does for
+// the last page of some ranges
+type finalCursorClient struct {
+	*MockSlackClient
+	requests int
+}
+
+func (c *finalCursorClient) GetChannelHistoryRange(ctx context.Context, channelID string, limit int, cursor, oldest, latest string) (*models.HistoryPage, error) {
+	c.requests++
+	return models.NewHistoryPage(c.messages, "stale-cursor", false), nil
+}
+
+func TestExportService_fetchAllMessagesStopsWithoutMore(t *testing.T) {
+	client := &finalCursorClient{MockSlackClient: NewMockSlackClient()}
+	service := NewExportService(client, "1.0.0-test")
+
+	options := models.ExportOptions{ChannelID: "C123456", ThreadDelay: time.Millisecond}
+	if _, _, err := service.fetchAllMessages(context.Background(), options, newExportEvents(nil), nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.requests != 1 {
+		t.Errorf("Expected has_more=false to end pagination, got %d requests", client.requests)
+	}
+}
+
+func TestExportService_ExportChannelUserFilter(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+		Users:          []string{"U345678"},
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	// Only the thread with a reply by U345678 remains, with just that reply
+	if len(export.Messages) != 1 || len(export.Messages[0].Replies) != 1 {
+		t.Fatalf("Expected 1 parent with 1 reply, got %+v", export.Messages)
+	}
+	if export.Messages[0].Replies[0].User != "U345678" {
+		t.Errorf("Expected reply by U345678, got %s", export.Messages[0].Replies[0].User)
+	}
+	if export.ExportInfo.Filters == nil || export.ExportInfo.Filters.Users[0] != "U345678" {
+		t.Errorf("Expected user filter in metadata, got %+v", export.ExportInfo.Filters)
+	}
+}
+
+func TestExportService_ExportChannelThreadLayout(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+		ThreadsOnly:    true,
+		FlatReplies:    true,
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	// Only the thread remains, its replies flat after the parent
+	if len(export.Messages) < 2 || export.Messages[0].ID != "1704067260.000000" {
+		t.Fatalf("Expected the thread parent followed by its replies, got %+v", export.Messages)
+	}
+	for _, msg := range export.Messages {
+		if len(msg.Replies) > 0 || msg.ThreadTimestamp != "1704067260.000000" {
+			t.Errorf("Expected a flat message of the thread, got %+v", msg)
+		}
+	}
+	if !export.ExportInfo.FlatReplies || export.ExportInfo.Filters == nil || !export.ExportInfo.Filters.ThreadsOnly {
+		t.Errorf("Expected the layout in the metadata, got %+v", export.ExportInfo)
+	}
+	if result.Statistics.TotalThreads != 1 || result.Statistics.TotalReplies != len(export.Messages)-1 {
+		t.Errorf("Expected statistics of the nested thread, got %+v", result.Statistics)
+	}
+}
+
+func TestExportService_ExportChannelPermalinks(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+		WorkspaceURL:   "https://acme.slack.com/",
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	for _, msg := range export.Messages {
+		if msg.ID != "1704067260.000000" {
+			continue
+		}
+		if want := "https://acme.slack.com/archives/C123456/p1704067260000000"; msg.Permalink != want {
+			t.Errorf("Expected parent permalink %s, got %s", want, msg.Permalink)
+		}
+		if len(msg.Replies) == 0 {
+			t.Fatal("Expected thread replies")
+		}
+		want := "https://acme.slack.com/archives/C123456/p1704067280000000?thread_ts=1704067260.000000&cid=C123456"
+		if msg.Replies[0].Permalink != want {
+			t.Errorf("Expected reply permalink %s, got %s", want, msg.Replies[0].Permalink)
+		}
+		return
+	}
+	t.Fatal("Expected the thread parent in the export")
+}
+
+func TestExportService_ExportChannelExternalMembers(t *testing.T) {
+	newClient := func() *MockSlackClient {
+		mockClient := NewMockSlackClient()
+		mockClient.channels[0].IsExtShared = true
+		mockClient.channels[0].ConnectedTeamIDs = []string{"T2"}
+		// Most of the directory belongs to another workspace of the
+		// organization, so only auth.test names the exporting one
+		for i := range mockClient.users {
+			mockClient.users[i].TeamID = "T3"
+		}
+		mockClient.users[0].TeamID = "T1"
+		mockClient.messages = append(mockClient.messages, models.Message{
+			Type:       "message",
+			User:       "W999999",
+			Team:       "T2",
+			Text:       "Hi from our partner",
+			Timestamp:  "1704067400.000000",
+			ThreadTS:   "1704067400.000000",
+			ReplyCount: 1,
+		})
+		mockClient.threads["1704067400.000000"] = []models.Message{
+			{Type: "message", User: "W999999", Team: "T2", Text: "Hi from our partner", Timestamp: "1704067400.000000", ThreadTS: "1704067400.000000"},
+			{Type: "message", User: "U123456", Team: "T1", Text: "Welcome!", Timestamp: "1704067460.000000", ThreadTS: "1704067400.000000"},
+		}
+		return mockClient
+	}
+
+	export := func(options models.ExportOptions) *models.ChannelExport {
+		t.Helper()
+		options.ChannelID = "C123456"
+		options.OutputFile = filepath.Join(t.TempDir(), "general.json")
+		options.Format = "json"
+		options.IncludeThreads = true
+		service := NewExportService(newClient(), "1.0.0-test")
+		service.SetWorkspaceClient(&MockWorkspaceClient{identity: &models.ExportIdentity{UserID: "U123456", TeamID: "T1"}})
+		result, err := service.ExportChannel(context.Background(), options, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		exportData, err := ReadExportFile(result.OutputFile)
+		if err != nil {
+			t.Fatalf("Failed to read export: %v", err)
+		}
+		return exportData
+	}
+
+	full := export(models.ExportOptions{})
+	if !full.Channel.IsExtShared || len(full.Channel.ConnectedTeamIDs) != 1 {
+		t.Errorf("Expected the channel marked as shared with T2, got %+v", full.Channel)
+	}
+	partner := full.Users["W999999"]
+	if !partner.IsExternal || partner.TeamID != "T2" || partner.Deleted {
+		t.Errorf("Expected an external partner user, got %+v", partner)
+	}
+	if full.Users["U123456"].IsExternal {
+		t.Error("Expected workspace members to be internal")
+	}
+	if full.Statistics.ExternalMessages != 1 {
+		t.Errorf("Expected 1 external message, got %d", full.Statistics.ExternalMessages)
+	}
+
+	internal := export(models.ExportOptions{ExcludeExternal: true})
+	if internal.Statistics.ExternalMessages != 0 {
+		t.Errorf("Expected no external messages, got %d", internal.Statistics.ExternalMessages)
+	}
+	var parent *models.ExportMessage
+	for i, msg := range internal.Messages {
+		if msg.ID == "1704067400.000000" {
+			parent = &internal.Messages[i]
+		} else if msg.User == "W999999" {
+			t.Errorf("Expected the partner's messages dropped, got %+v", msg)
+		}
+	}
+	if parent == nil || !parent.Omitted || parent.Text != "" || len(parent.Replies) != 1 || parent.Replies[0].Text != "Welcome!" {
+		t.Errorf("Expected the internal reply kept under the omitted partner message, got %+v", parent)
+	}
+	if _, ok := internal.Users["W999999"]; ok {
+		t.Error("Expected the partner left out of the user directory")
+	}
+	if internal.ExportInfo.Filters == nil || !internal.ExportInfo.Filters.ExcludeExternal {
+		t.Errorf("Expected the filter recorded, got %+v", internal.ExportInfo.Filters)
+	}
+}
+
+func TestExportService_ExportChannelAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	log := audit.New(filepath.Join(dir, "audit.log"))
+	log.SetIdentity(&models.ExportIdentity{UserID: "U999", User: "auditor"})
+
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	service.SetAuditLog(log)
+
+	options := models.ExportOptions{
+		ChannelID:   "C123456",
+		ChannelName: "general",
+		OutputFile:  filepath.Join(dir, "general.json"),
+		Format:      "json",
+	}
+	if _, err := service.ExportChannel(context.Background(), options, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(log.Path())
+	if err != nil {
+		t.Fatalf("Expected an audit log, got %v", err)
+	}
+	var entry audit.Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Expected one JSON entry, got %q: %v", data, err)
+	}
+	if entry.Action != audit.ActionExport || entry.UserID != "U999" || entry.ChannelID != "C123456" ||
+		entry.Output != options.OutputFile || !entry.Success || entry.Messages == 0 {
+		t.Errorf("Unexpected audit entry: %+v", entry)
+	}
+}
+
+func TestExportService_ExportChannelAuditLogFailure(t *testing.T) {
+	dir := t.TempDir()
+	// A directory cannot be appended to
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	service.SetAuditLog(audit.New(dir))
+
+	options := models.ExportOptions{
+		ChannelID:   "C123456",
+		ChannelName: "general",
+		OutputFile:  filepath.Join(dir, "general.json"),
+		Format:      "json",
+	}
+	_, err := service.ExportChannel(context.Background(), options, nil)
+	if models.ErrorCategoryOf(err) != models.ErrorCategoryIO {
+		t.Fatalf("Expected an I/O error for the unwritable audit log, got %v", err)
+	}
+}
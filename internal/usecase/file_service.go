@@ -0,0 +1,386 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// FileListClientInterface defines the Slack API operations needed to list
+// and download the files of a channel
+type FileListClientInterface interface {
+	ListFiles(ctx context.Context, channelID, types string, from, to *time.Time) ([]models.ExportFile, error)
+	OpenDownload(ctx context.Context, url string, offset int64) (io.ReadCloser, int64, error)
+}
+
+// FileService lists the files shared in a channel and downloads them
+// independently of a message export
+type FileService struct {
+	client FileListClientInterface
+	logger *slog.Logger
+}
+
+// NewFileService creates a new file service
+func NewFileService(client FileListClientInterface) *FileService {
+	return &FileService{client: client, logger: slog.Default()}
+}
+
+// SetLogger sets the logger used for download warnings
+func (s *FileService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// fileTypeGroups maps the names accepted by FileListOptions.Types to the
+// files.list types. Other names are matched against the file type, such as
+// png or mp4.
+var fileTypeGroups = map[string]string{
+	"image": "images", "images": "images",
+	"pdf": "pdfs", "pdfs": "pdfs",
+	"snippet": "snippets", "snippets": "snippets",
+	"zip": "zips", "zips": "zips",
+	"gdoc": "gdocs", "gdocs": "gdocs",
+	"canvas": "canvas", "post": "spaces", "spaces": "spaces",
+}
+
+// FileListOptions selects the files of a channel
+type FileListOptions struct {
+	ChannelID string
+	// Types are files.list types (image, pdf, snippet, zip, gdoc, canvas,
+	// post) or file types (png, mp4, ...); empty = all
+	Types    []string
+	From, To *time.Time
+	// MinSize and MaxSize bound the file size in bytes (0 = no bound)
+	MinSize, MaxSize int64
+}
+
+// ListFiles returns the files of a channel matching opts, oldest first
+func (s *FileService) ListFiles(ctx context.Context, opts FileListOptions) ([]models.ExportFile, error) {
+	var groups []string
+	filetypes := make(map[string]bool)
+	for _, name := range opts.Types {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if group, ok := fileTypeGroups[name]; ok {
+			groups = append(groups, group)
+		} else if name != "" {
+			filetypes[name] = true
+		}
+	}
+	// File types are filtered here; files.list can only narrow by group
+	types := strings.Join(groups, ",")
+	if len(filetypes) > 0 {
+		types = ""
+	}
+
+	listed, err := s.client.ListFiles(ctx, opts.ChannelID, types, opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []models.ExportFile{}
+	for _, file := range listed {
+		if len(filetypes) > 0 && !filetypes[strings.ToLower(file.Filetype)] && !matchesGroup(file, groups) {
+			continue
+		}
+		if opts.MinSize > 0 && int64(file.Size) < opts.MinSize || opts.MaxSize > 0 && int64(file.Size) > opts.MaxSize {
+			continue
+		}
+		files = append(files, file)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Timestamp.Before(files[j].Timestamp)
+	})
+	return files, nil
+}
+
+// matchesGroup reports whether file belongs to one of the files.list type
+// groups, for listings that had to fetch all types
+func matchesGroup(file models.ExportFile, groups []string) bool {
+	for _, group := range groups {
+		switch group {
+		case "images":
+			if strings.HasPrefix(file.Mimetype, "image/") {
+				return true
+			}
+		case "pdfs", "zips", "gdocs":
+			if file.Filetype == strings.TrimSuffix(group, "s") {
+				return true
+			}
+		case "snippets":
+			if file.Mode == "snippet" {
+				return true
+			}
+		case "canvas", "spaces":
+			if file.Filetype == group || file.Filetype == "quip" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteFilesCSV writes a file listing as CSV with a header row
+func WriteFilesCSV(w io.Writer, files []models.ExportFile) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "name", "title", "filetype", "size", "user", "timestamp", "permalink"}); err != nil {
+		return err
+	}
+	for _, file := range files {
+		record := []string{
+			file.ID,
+			file.Name,
+			file.Title,
+			file.Filetype,
+			strconv.Itoa(file.Size),
+			file.User,
+			file.Timestamp.UTC().Format(time.RFC3339),
+			file.Permalink,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// DownloadQueueFile is the queue a download keeps in its directory, so an
+// interrupted run resumes where it stopped
+const DownloadQueueFile = ".slacker-downloads.json"
+
+// Download statuses of a QueuedFile
+const (
+	DownloadPending = "pending"
+	DownloadDone    = "done"
+	DownloadFailed  = "failed"
+	DownloadSkipped = "skipped"
+)
+
+// partSuffix marks a file that is still being downloaded
+const partSuffix = ".part"
+
+// QueuedFile is a file in the download queue. Path is relative to the
+// download directory and fixed when the file is queued.
+type QueuedFile struct {
+	models.ExportFile
+	Path           string `json:"path"`
+	DownloadStatus string `json:"download_status"`
+	Error          string `json:"error,omitempty"`
+}
+
+// DownloadQueue is the content of DownloadQueueFile
+type DownloadQueue struct {
+	ChannelID string       `json:"channel_id"`
+	Updated   time.Time    `json:"updated"`
+	Files     []QueuedFile `json:"files"`
+}
+
+// DownloadResult summarizes a download run
+type DownloadResult struct {
+	Dir        string `json:"dir"`
+	Downloaded int    `json:"downloaded"`
+	Resumed    int    `json:"resumed"`
+	Existing   int    `json:"existing"`
+	Skipped    int    `json:"skipped"`
+	Failed     int    `json:"failed"`
+	Bytes      int64  `json:"bytes"`
+}
+
+// ReadDownloadQueue reads the queue of a download directory; a directory
+// without one has an empty queue
+func ReadDownloadQueue(dir string) (*DownloadQueue, error) {
+	data, err := os.ReadFile(filepath.Join(dir, DownloadQueueFile))
+	if os.IsNotExist(err) {
+		return &DownloadQueue{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download queue: %w", err)
+	}
+	var queue DownloadQueue
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("failed to parse download queue %s: %w", filepath.Join(dir, DownloadQueueFile), err)
+	}
+	return &queue, nil
+}
+
+// writeDownloadQueue saves the queue of dir
+func writeDownloadQueue(dir string, queue *DownloadQueue) error {
+	queue.Updated = time.Now()
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = writeFileAtomic(filepath.Join(dir, DownloadQueueFile), func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+	return err
+}
+
+// Download adds files to the queue of dir and downloads every file of the
+// queue that is not done yet. Finished files are skipped on later runs, and
+// a file that was cut off continues from its .part file where the server
+// allows it. progress, when set, is called after each file. A failed file is
+// recorded in the queue and does not stop the run.
+func (s *FileService) Download(ctx context.Context, dir, channelID string, files []models.ExportFile, progress func(QueuedFile)) (*DownloadResult, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, models.NewExportError(models.ErrorCategoryIO, "failed to create download directory", err)
+	}
+	queue, err := ReadDownloadQueue(dir)
+	if err != nil {
+		return nil, err
+	}
+	queue.ChannelID = channelID
+	enqueue(queue, files)
+	if err := writeDownloadQueue(dir, queue); err != nil {
+		return nil, models.NewExportError(models.ErrorCategoryIO, "failed to write download queue", err)
+	}
+
+	result := &DownloadResult{Dir: dir}
+	for i := range queue.Files {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		queued := &queue.Files[i]
+		switch {
+		case queued.DownloadStatus == DownloadDone && fileExists(filepath.Join(dir, queued.Path)):
+			result.Existing++
+			continue
+		case queued.IsExternal || queued.URLPrivateDownload == "" && queued.URLPrivate == "":
+			// Google Drive and other external files have nothing to download
+			queued.DownloadStatus = DownloadSkipped
+			result.Skipped++
+		default:
+			size, resumed, err := s.downloadFile(ctx, dir, queued)
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			if err != nil {
+				s.logger.Warn("file download failed", "file_id", queued.ID, "name", queued.Name, "error", err)
+				queued.DownloadStatus = DownloadFailed
+				queued.Error = err.Error()
+				result.Failed++
+			} else {
+				queued.DownloadStatus = DownloadDone
+				queued.Error = ""
+				result.Downloaded++
+				result.Bytes += size
+				if resumed {
+					result.Resumed++
+				}
+			}
+		}
+		if err := writeDownloadQueue(dir, queue); err != nil {
+			return result, models.NewExportError(models.ErrorCategoryIO, "failed to write download queue", err)
+		}
+		if progress != nil {
+			progress(*queued)
+		}
+	}
+	return result, nil
+}
+
+// enqueue adds the files that are not queued yet, giving each a file name
+// that no other queued file uses
+func enqueue(queue *DownloadQueue, files []models.ExportFile) {
+	queued := make(map[string]bool, len(queue.Files))
+	used := make(map[string]bool, len(queue.Files))
+	for _, file := range queue.Files {
+		queued[file.ID] = true
+		used[strings.ToLower(file.Path)] = true
+	}
+	for _, file := range files {
+		if queued[file.ID] {
+			continue
+		}
+		name := file.Name
+		if name == "" {
+			name = file.ID
+		}
+		path := SafeFileName(name)
+		if used[strings.ToLower(path)] {
+			path = SafeFileName(file.ID + "-" + name)
+		}
+		queue.Files = append(queue.Files, QueuedFile{ExportFile: file, Path: path, DownloadStatus: DownloadPending})
+		queued[file.ID] = true
+		used[strings.ToLower(path)] = true
+	}
+}
+
+// downloadFile downloads one queued file through its .part file and returns
+// the bytes fetched and whether an earlier partial download was continued
+func (s *FileService) downloadFile(ctx context.Context, dir string, queued *QueuedFile) (int64, bool, error) {
+	url := queued.URLPrivateDownload
+	if url == "" {
+		url = queued.URLPrivate
+	}
+	return downloadToFile(ctx, s.client, url, filepath.Join(dir, queued.Path), int64(queued.Size))
+}
+
+// downloadOpener starts url_private downloads
+type downloadOpener interface {
+	OpenDownload(ctx context.Context, url string, offset int64) (io.ReadCloser, int64, error)
+}
+
+// downloadToFile streams url to target through a .part file, continuing a
+// .part file left by an earlier run where the server allows it. size, when
+// known, is checked against the bytes received. It returns the bytes fetched
+// and whether the download was continued.
+func downloadToFile(ctx context.Context, client downloadOpener, url, target string, size int64) (int64, bool, error) {
+	part := target + partSuffix
+
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	body, start, err := client.OpenDownload(ctx, url, offset)
+	if err != nil {
+		return 0, false, err
+	}
+	defer body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if start > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return 0, false, err
+	}
+	written, err := io.Copy(file, body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, false, err
+	}
+
+	if total := start + written; size > 0 && total != size {
+		// A mismatched resume is unusable; start over on the next run
+		if start > 0 {
+			os.Remove(part)
+		}
+		return written, false, fmt.Errorf("downloaded %d of %d bytes", total, size)
+	}
+	if err := os.Rename(part, target); err != nil {
+		return written, false, err
+	}
+	return written, start > 0, nil
+}
+
+// fileExists reports whether path is an existing file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
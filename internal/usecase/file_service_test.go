@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockFileListClient lists a fixed set of files and serves their content by URL.
+// Ranges are honoured unless noRange is set; failing URLs return an error.
+type MockFileListClient struct {
+	files   []models.ExportFile
+	content map[string]string
+	failing map[string]bool
+	noRange bool
+	types   []string
+	offsets map[string]int64
+}
+
+func (m *MockFileListClient) ListFiles(ctx context.Context, channelID, types string, from, to *time.Time) ([]models.ExportFile, error) {
+	m.types = append(m.types, types)
+	return m.files, nil
+}
+
+func (m *MockFileListClient) OpenDownload(ctx context.Context, url string, offset int64) (io.ReadCloser, int64, error) {
+	if m.failing[url] {
+		return nil, 0, fmt.Errorf("download returned status 500")
+	}
+	if m.offsets == nil {
+		m.offsets = make(map[string]int64)
+	}
+	m.offsets[url] = offset
+	content := m.content[url]
+	if m.noRange || offset == 0 {
+		return io.NopCloser(strings.NewReader(content)), 0, nil
+	}
+	return io.NopCloser(strings.NewReader(content[offset:])), offset, nil
+}
+
+func listedFile(id, name, filetype string, size int, day int) models.ExportFile {
+	return models.ExportFile{
+		ID: id, Name: name, Filetype: filetype, Size: size,
+		Mimetype:           map[string]string{"png": "image/png", "pdf": "application/pdf"}[filetype],
+		URLPrivateDownload: "https://files.example/" + id,
+		Timestamp:          time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestFileService_ListFiles(t *testing.T) {
+	client := &MockFileListClient{files: []models.ExportFile{
+		listedFile("F3", "c.pdf", "pdf", 3000, 3),
+		listedFile("F1", "a.png", "png", 100, 1),
+		listedFile("F2", "b.mp4", "mp4", 2000, 2),
+	}}
+	service := NewFileService(client)
+
+	files, err := service.ListFiles(context.Background(), FileListOptions{Types: []string{"image", "PDF"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.types[0] != "images,pdfs" || len(files) != 3 || files[0].ID != "F1" {
+		t.Errorf("Expected files.list types and files oldest first, got %q and %v", client.types[0], files)
+	}
+
+	// File types are filtered locally, together with any groups
+	files, _ = service.ListFiles(context.Background(), FileListOptions{Types: []string{"image", "mp4"}, MaxSize: 2500})
+	if client.types[1] != "" || len(files) != 2 || files[0].ID != "F1" || files[1].ID != "F2" {
+		t.Errorf("Expected the png and the mp4, got %q and %v", client.types[1], files)
+	}
+	files, _ = service.ListFiles(context.Background(), FileListOptions{MinSize: 1000})
+	if len(files) != 2 {
+		t.Errorf("Expected the two large files, got %v", files)
+	}
+}
+
+func TestFileService_DownloadResumes(t *testing.T) {
+	dir := t.TempDir()
+	client := &MockFileListClient{
+		content: map[string]string{
+			"https://files.example/F1": "first",
+			"https://files.example/F2": "second file",
+			"https://files.example/F3": "third",
+		},
+		failing: map[string]bool{"https://files.example/F3": true},
+	}
+	files := []models.ExportFile{
+		listedFile("F1", "report.pdf", "pdf", 5, 1),
+		listedFile("F2", "report.pdf", "pdf", 11, 2),
+		listedFile("F3", "notes.txt", "text", 5, 3),
+		{ID: "F4", Name: "doc", IsExternal: true},
+	}
+	service := NewFileService(client)
+
+	// An earlier run was cut off halfway through F2
+	if err := os.WriteFile(filepath.Join(dir, "F2-report.pdf.part"), []byte("second"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var seen []string
+	result, err := service.Download(context.Background(), dir, "C1", files, func(file QueuedFile) {
+		seen = append(seen, file.ID+":"+file.DownloadStatus)
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Downloaded != 2 || result.Resumed != 1 || result.Failed != 1 || result.Skipped != 1 || result.Bytes != 10 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if strings.Join(seen, ",") != "F1:done,F2:done,F3:failed,F4:skipped" {
+		t.Errorf("Unexpected progress %v", seen)
+	}
+	if client.offsets["https://files.example/F2"] != 6 {
+		t.Errorf("Expected F2 to resume at byte 6, got %d", client.offsets["https://files.example/F2"])
+	}
+	for name, want := range map[string]string{"report.pdf": "first", "F2-report.pdf": "second file"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("Expected %s to hold %q, got %q (%v)", name, want, data, err)
+		}
+	}
+
+	// The next run only retries what failed
+	delete(client.failing, "https://files.example/F3")
+	client.offsets = nil
+	result, err = service.Download(context.Background(), dir, "C1", nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Downloaded != 1 || result.Existing != 2 || len(client.offsets) != 1 {
+		t.Errorf("Expected only F3 to be fetched again, got %+v after %v", result, client.offsets)
+	}
+	queue, err := ReadDownloadQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queue.Files) != 4 || queue.Files[2].DownloadStatus != DownloadDone || queue.Files[2].Error != "" {
+		t.Errorf("Expected the queue to record F3 as done, got %+v", queue.Files)
+	}
+}
+
+func TestFileService_DownloadRestartsWithoutRange(t *testing.T) {
+	dir := t.TempDir()
+	client := &MockFileListClient{content: map[string]string{"https://files.example/F1": "complete"}, noRange: true}
+	if err := os.WriteFile(filepath.Join(dir, "a.bin.part"), []byte("compl"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewFileService(client).Download(context.Background(), dir, "C1", []models.ExportFile{listedFile("F1", "a.bin", "binary", 8, 1)}, nil)
+	if err != nil || result.Downloaded != 1 || result.Resumed != 0 {
+		t.Fatalf("Expected a fresh download, got %+v, %v", result, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.bin")); string(data) != "complete" {
+		t.Errorf("Expected the whole file, got %q", data)
+	}
+}
+
+func TestWriteFilesCSV(t *testing.T) {
+	var out bytes.Buffer
+	file := listedFile("F1", "a, b.png", "png", 100, 1)
+	file.Permalink = "https://example.slack.com/files/F1"
+	if err := WriteFilesCSV(&out, []models.ExportFile{file}); err != nil {
+		t.Fatalf("WriteFilesCSV: %v", err)
+	}
+	want := "id,name,title,filetype,size,user,timestamp,permalink\nF1,\"a, b.png\",,png,100,,2024-01-01T00:00:00Z,https://example.slack.com/files/F1\n"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
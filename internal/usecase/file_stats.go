@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"sort"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// maxFileSharers is how many users ExportStatistics.TopFileSharers lists
+const maxFileSharers = 10
+
+// unknownFiletype groups files Slack reported no type for
+const unknownFiletype = "unknown"
+
+// fileUsage adds up shared files by type and by the user who shared them
+type fileUsage struct {
+	total   int64
+	types   map[string]*models.FileTypeStat
+	sharers map[string]*models.FileSharerStat
+}
+
+func newFileUsage() *fileUsage {
+	return &fileUsage{
+		types:   make(map[string]*models.FileTypeStat),
+		sharers: make(map[string]*models.FileSharerStat),
+	}
+}
+
+// add counts one file of filetype and size shared by user
+func (u *fileUsage) add(user, filetype string, size int) {
+	if filetype == "" {
+		filetype = unknownFiletype
+	}
+	bytes := int64(size)
+	u.total += bytes
+
+	byType, ok := u.types[filetype]
+	if !ok {
+		byType = &models.FileTypeStat{Filetype: filetype}
+		u.types[filetype] = byType
+	}
+	byType.Count++
+	byType.Bytes += bytes
+
+	if user == "" {
+		return
+	}
+	sharer, ok := u.sharers[user]
+	if !ok {
+		sharer = &models.FileSharerStat{User: user}
+		u.sharers[user] = sharer
+	}
+	sharer.Files++
+	sharer.Bytes += bytes
+}
+
+// apply sets the file statistics of stats: all file types and the top
+// sharers, largest first
+func (u *fileUsage) apply(stats *models.ExportStatistics) {
+	stats.TotalFileBytes = u.total
+	stats.FileTypes = nil
+	for _, byType := range u.types {
+		stats.FileTypes = append(stats.FileTypes, *byType)
+	}
+	sort.Slice(stats.FileTypes, func(i, j int) bool {
+		a, b := stats.FileTypes[i], stats.FileTypes[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Filetype < b.Filetype
+	})
+
+	stats.TopFileSharers = nil
+	for _, sharer := range u.sharers {
+		stats.TopFileSharers = append(stats.TopFileSharers, *sharer)
+	}
+	sort.Slice(stats.TopFileSharers, func(i, j int) bool {
+		a, b := stats.TopFileSharers[i], stats.TopFileSharers[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		if a.Files != b.Files {
+			return a.Files > b.Files
+		}
+		return a.User < b.User
+	})
+	if len(stats.TopFileSharers) > maxFileSharers {
+		stats.TopFileSharers = stats.TopFileSharers[:maxFileSharers]
+	}
+}
+
+// ApplyFileStatistics recomputes the file statistics of export from its
+// messages and thread replies, e.g. for exports written before slacker
+// recorded them
+func ApplyFileStatistics(export *models.ChannelExport) {
+	usage := newFileUsage()
+	var count func([]models.ExportMessage)
+	count = func(messages []models.ExportMessage) {
+		for _, msg := range messages {
+			// Broadcast links are counted with their thread
+			if msg.InThread {
+				continue
+			}
+			for _, file := range msg.Files {
+				user := file.User
+				if user == "" {
+					user = msg.User
+				}
+				usage.add(user, file.Filetype, file.Size)
+			}
+			count(msg.Replies)
+		}
+	}
+	count(export.Messages)
+	usage.apply(&export.Statistics)
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_calculateFileStatistics(t *testing.T) {
+	service := NewExportService(nil, "1.0.0-test")
+	messages := []models.Message{
+		{User: "U1", Timestamp: "1704067200.000000", Files: []models.File{
+			{ID: "F1", Filetype: "pdf", Size: 3000},
+			{ID: "F2", Filetype: "png", Size: 500},
+		}, Thread: []models.Message{
+			{User: "U2", Timestamp: "1704067260.000000", Files: []models.File{{ID: "F3", Filetype: "pdf", Size: 1000}}},
+		}},
+		{User: "U2", Timestamp: "1704067300.000000", Files: []models.File{{ID: "F4", Size: 10}}},
+	}
+
+	stats := service.calculateStatistics(messages, nil, time.UTC)
+	if stats.TotalFileBytes != 4510 {
+		t.Errorf("Expected 4510 bytes of files, got %d", stats.TotalFileBytes)
+	}
+	want := []models.FileTypeStat{
+		{Filetype: "pdf", Count: 2, Bytes: 4000},
+		{Filetype: "png", Count: 1, Bytes: 500},
+		{Filetype: unknownFiletype, Count: 1, Bytes: 10},
+	}
+	if len(stats.FileTypes) != len(want) {
+		t.Fatalf("Expected %d file types, got %+v", len(want), stats.FileTypes)
+	}
+	for i := range want {
+		if stats.FileTypes[i] != want[i] {
+			t.Errorf("Expected %+v at %d, got %+v", want[i], i, stats.FileTypes[i])
+		}
+	}
+	if len(stats.TopFileSharers) != 2 || stats.TopFileSharers[0] != (models.FileSharerStat{User: "U1", Files: 2, Bytes: 3500}) {
+		t.Errorf("Expected U1 to share the most, got %+v", stats.TopFileSharers)
+	}
+}
+
+func TestApplyFileStatistics(t *testing.T) {
+	export := &models.ChannelExport{Messages: []models.ExportMessage{
+		{User: "U1", Files: []models.ExportFile{{ID: "F1", Filetype: "zip", Size: 2048}}, Replies: []models.ExportMessage{
+			{User: "U2", Files: []models.ExportFile{{ID: "F2", Filetype: "zip", Size: 1024, User: "U2"}}},
+		}},
+		// The channel copy of a broadcast reply is counted in its thread
+		{User: "U2", InThread: true, Files: []models.ExportFile{{ID: "F2", Filetype: "zip", Size: 1024}}},
+	}}
+
+	ApplyFileStatistics(export)
+	stats := export.Statistics
+	if stats.TotalFileBytes != 3072 || len(stats.FileTypes) != 1 || stats.FileTypes[0].Count != 2 {
+		t.Errorf("Expected two zip files of 3072 bytes, got %+v", stats)
+	}
+	if len(stats.TopFileSharers) != 2 || stats.TopFileSharers[1].User != "U2" {
+		t.Errorf("Expected U1 and U2 as sharers, got %+v", stats.TopFileSharers)
+	}
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxNameLength caps, in bytes, a channel or workspace name used in a
+// generated file or directory name. Longer names are shortened and get a
+// hash suffix so that different long names stay distinct.
+const maxNameLength = 100
+
+// windowsReserved are device names Windows refuses as file names, with or
+// without an extension
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SafeFileName turns name into a single path element that is valid on
+// Windows, macOS and Linux: path separators, characters Windows forbids and
+// control characters become "-", trailing dots and spaces are dropped,
+// reserved device names get a "_" prefix and long names are shortened.
+func SafeFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '-'
+		}
+		return r
+	}, name)
+	safe = strings.TrimRight(safe, ". ")
+
+	if len(safe) > maxNameLength {
+		sum := sha256.Sum256([]byte(name))
+		suffix := "-" + hex.EncodeToString(sum[:4])
+		cut := maxNameLength - len(suffix)
+		for cut > 0 && !utf8.RuneStart(safe[cut]) {
+			cut--
+		}
+		safe = strings.TrimRight(safe[:cut], ". ") + suffix
+	}
+
+	if safe == "" {
+		return "_"
+	}
+	base, _, _ := strings.Cut(safe, ".")
+	if windowsReserved[strings.ToUpper(base)] {
+		safe = "_" + safe
+	}
+	return safe
+}
+
+// ExportFileName is the default file name of an export of channelName taken
+// at stamp (20060102-150405 or a run ID)
+func ExportFileName(channelName, stamp, format string) string {
+	return fmt.Sprintf("%s-export-%s%s", SafeFileName(channelName), stamp, FormatExtension(format))
+}
+
+// exportPrefix is the start of the default file names of a channel's exports
+func exportPrefix(channelName string) string {
+	return SafeFileName(channelName) + "-export-"
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeFileName(t *testing.T) {
+	long := strings.Repeat("проект-", 30)
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"general", "general"},
+		{"team/ops", "team-ops"},
+		{`a<b>c:d"e\f|g?h*i`, "a-b-c-d-e-f-g-h-i"},
+		{"tab\there", "tab-here"},
+		{"trailing. ", "trailing"},
+		{"con", "_con"},
+		{"LPT1.json", "_LPT1.json"},
+		{"console", "console"},
+		{"...", "_"},
+		{"", "_"},
+	}
+	for _, tt := range tests {
+		if got := SafeFileName(tt.name); got != tt.want {
+			t.Errorf("SafeFileName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	got := SafeFileName(long)
+	if len(got) > maxNameLength || !strings.HasPrefix(got, "проект-") {
+		t.Errorf("Expected a long name to be shortened to %d bytes, got %q (%d bytes)", maxNameLength, got, len(got))
+	}
+	if !strings.HasPrefix(long, strings.TrimRight(got[:len(got)-9], "-")) {
+		t.Errorf("Expected the shortened name to keep whole characters, got %q", got)
+	}
+	if other := SafeFileName(long + "x"); other == got {
+		t.Errorf("Expected different long names to stay distinct, got %q twice", got)
+	}
+}
+
+func TestExportFileName(t *testing.T) {
+	if got := ExportFileName("ops:alerts", "20240115-103000", "json"); got != "ops-alerts-export-20240115-103000.json" {
+		t.Errorf("Unexpected file name %q", got)
+	}
+	if got := ExportFileName("con", "20240115-103000", "pdf"); got != "_con-export-20240115-103000.pdf" {
+		t.Errorf("Unexpected file name %q", got)
+	}
+}
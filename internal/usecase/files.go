@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// FileClientInterface defines the Slack API operations needed to enrich file
+// metadata and download the files of a local export
+type FileClientInterface interface {
+	GetFileInfo(ctx context.Context, fileID string) (*models.ExportFile, error)
+	OpenDownload(ctx context.Context, url string, offset int64) (io.ReadCloser, int64, error)
+}
+
+// SetFileClient enables ExportOptions.IncludeFileInfo
+func (s *ExportService) SetFileClient(client FileClientInterface) {
+	s.fileClient = client
+}
+
+// maxListedFiles is the number of file IDs a failure warning names
+const maxListedFiles = 5
+
+// fileDir returns the directory the files of a local export are saved to
+// and the prefix of the paths that refer to them: <name>-files/ next to an
+// export file, or files/ inside a slack-native export directory
+func fileDir(options models.ExportOptions) (string, string) {
+	if options.Layout == LayoutSlackNative {
+		root := strings.TrimSuffix(strings.TrimSuffix(options.OutputFile, "/"), ".json")
+		return filepath.Join(root, "files"), "files/"
+	}
+	base, _ := splitExportExt(options.OutputFile)
+	return base + "-files", filepath.Base(base) + "-files/"
+}
+
+// enrichFiles replaces the files of every message and reply with their
+// files.info metadata, looking each file up once. A failed lookup keeps the
+// message payload fields and marks the file FileStatusFailed. Files of a
+// local export are also downloaded next to it, once each, and the outcome is
+// recorded in their DownloadStatus. Failed lookups and failed downloads are
+// returned as one warning each.
+func (s *ExportService) enrichFiles(ctx context.Context, messages []models.ExportMessage, options models.ExportOptions) ([]string, error) {
+	if s.fileClient == nil {
+		return nil, fmt.Errorf("no file client configured")
+	}
+
+	download := IsLocalOutput(options.OutputFile)
+	dir, prefix := fileDir(options)
+	loc := models.OrLocal(options.Location)
+
+	var lookupFailed, downloadFailed []string
+	var lookupErr, downloadErr error
+	lookups := make(map[string]*models.ExportFile)
+	downloads := make(map[string]models.ExportFile)
+	var enrich func(msgs []models.ExportMessage) error
+	enrich = func(msgs []models.ExportMessage) error {
+		for i := range msgs {
+			for j := range msgs[i].Files {
+				file := &msgs[i].Files[j]
+				info, ok := lookups[file.ID]
+				if !ok {
+					var err error
+					info, err = s.fileClient.GetFileInfo(ctx, file.ID)
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					if err != nil {
+						s.logger.Debug("file lookup failed", "file_id", file.ID, "error", err)
+						lookupFailed = append(lookupFailed, file.ID)
+						lookupErr = cmp.Or(lookupErr, err)
+						info = nil
+					}
+					lookups[file.ID] = info
+				}
+
+				switch {
+				case info == nil:
+					file.Status = models.FileStatusFailed
+				case info.Status == models.FileStatusOK:
+					*file = *info
+					file.Timestamp = file.Timestamp.In(loc)
+				default:
+					file.Status = info.Status
+				}
+				if !download {
+					continue
+				}
+
+				saved, ok := downloads[file.ID]
+				if !ok {
+					path, err := s.downloadExportFile(ctx, *file, dir)
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					switch {
+					case err != nil:
+						s.logger.Debug("file download failed", "file_id", file.ID, "error", err)
+						downloadFailed = append(downloadFailed, file.ID)
+						downloadErr = cmp.Or(downloadErr, err)
+						saved.DownloadStatus = models.FileDownloadFailed
+					case path == "":
+						saved.DownloadStatus = models.FileDownloadSkipped
+					default:
+						saved.DownloadStatus = models.FileDownloaded
+						saved.LocalPath = prefix + path
+					}
+					downloads[file.ID] = saved
+				}
+				file.DownloadStatus = saved.DownloadStatus
+				file.LocalPath = saved.LocalPath
+			}
+			if err := enrich(msgs[i].Replies); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err := enrich(messages)
+	var warnings []string
+	if len(lookupFailed) > 0 {
+		warnings = append(warnings, fileFailureWarning("file lookup", lookupFailed, lookupErr))
+	}
+	if len(downloadFailed) > 0 {
+		warnings = append(warnings, fileFailureWarning("file download", downloadFailed, downloadErr))
+	}
+	return warnings, err
+}
+
+// downloadExportFile saves the content of file in dir and returns its name
+// there. Deleted and external files, and files without a download URL, are
+// skipped with an empty name.
+func (s *ExportService) downloadExportFile(ctx context.Context, file models.ExportFile, dir string) (string, error) {
+	url := cmp.Or(file.URLPrivateDownload, file.URLPrivate)
+	if file.Status == models.FileStatusDeleted || file.IsExternal || url == "" {
+		return "", nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create file directory: %w", err)
+	}
+	name := SafeFileName(file.ID + "-" + cmp.Or(file.Name, file.Title, "file"))
+	if _, _, err := downloadToFile(ctx, s.fileClient, url, filepath.Join(dir, name), int64(file.Size)); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// fileFailureWarning summarizes the files an action failed for, naming the
+// first few and the first error
+func fileFailureWarning(action string, ids []string, err error) string {
+	listed := strings.Join(ids, ", ")
+	if len(ids) > maxListedFiles {
+		listed = fmt.Sprintf("%s and %d more", strings.Join(ids[:maxListedFiles], ", "), len(ids)-maxListedFiles)
+	}
+	noun := "files"
+	if len(ids) == 1 {
+		noun = "file"
+	}
+	return fmt.Sprintf("%s failed for %d %s (%s): %v", action, len(ids), noun, listed, err)
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockFileClient serves files.info lookups and downloads from memory
+type MockFileClient struct {
+	files     map[string]*models.ExportFile
+	contents  map[string]string
+	calls     int
+	downloads int
+}
+
+func (m *MockFileClient) GetFileInfo(ctx context.Context, fileID string) (*models.ExportFile, error) {
+	m.calls++
+	if file, ok := m.files[fileID]; ok {
+		return file, nil
+	}
+	return nil, fmt.Errorf("files.info failed")
+}
+
+func (m *MockFileClient) OpenDownload(ctx context.Context, url string, offset int64) (io.ReadCloser, int64, error) {
+	m.downloads++
+	if content, ok := m.contents[url]; ok {
+		return io.NopCloser(strings.NewReader(content)), 0, nil
+	}
+	return nil, 0, fmt.Errorf("download returned status 404")
+}
+
+func TestExportService_IncludeFileInfo(t *testing.T) {
+	client := NewMockSlackClient()
+	client.messages = append(client.messages,
+		models.Message{Type: "message", User: "U123456", Timestamp: "1704067400.000000", Files: []models.File{{ID: "F1", Name: "chart.png"}, {ID: "F2", Name: "old.txt"}}},
+		models.Message{Type: "message", User: "U123456", Timestamp: "1704067500.000000", Files: []models.File{{ID: "F1", Name: "chart.png"}, {ID: "F3", Name: "broken.pdf", URL: "https://files.example/F3"}}},
+		models.Message{Type: "message", User: "U123456", Timestamp: "1704067600.000000", Files: []models.File{{ID: "F4", Name: "gone.txt"}}},
+	)
+	files := &MockFileClient{
+		files: map[string]*models.ExportFile{
+			"F1": {ID: "F1", Name: "chart.png", OriginalW: 800, OriginalH: 600, Thumb360: "https://files.example/F1-360.png", URLPrivateDownload: "https://files.example/F1", Status: models.FileStatusOK},
+			"F2": {ID: "F2", Status: models.FileStatusDeleted},
+			"F4": {ID: "F4", Name: "gone.txt", URLPrivateDownload: "https://files.example/F4", Status: models.FileStatusOK},
+		},
+		contents: map[string]string{"https://files.example/F1": "png"},
+	}
+	service := NewExportService(client, "1.0.0-test")
+	service.SetFileClient(files)
+
+	dir := t.TempDir()
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:       "C123456",
+		OutputFile:      filepath.Join(dir, "general.json"),
+		Format:          "json",
+		IncludeFileInfo: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	status := make(map[string]string)
+	downloaded := make(map[string]string)
+	for _, msg := range export.Messages {
+		for _, file := range msg.Files {
+			status[file.ID] = file.Status
+			downloaded[file.ID] = file.DownloadStatus
+			if file.ID == "F1" && file.LocalPath != "general-files/F1-chart.png" {
+				t.Errorf("Expected F1 saved next to the export, got %q", file.LocalPath)
+			}
+			if file.ID == "F1" && (file.OriginalW != 800 || file.Thumb360 == "") {
+				t.Errorf("Expected files.info metadata for F1, got %+v", file)
+			}
+			if file.ID == "F2" && file.Name != "old.txt" {
+				t.Errorf("Expected deleted file to keep its name, got %q", file.Name)
+			}
+		}
+	}
+	if status["F1"] != models.FileStatusOK || status["F2"] != models.FileStatusDeleted || status["F3"] != models.FileStatusFailed {
+		t.Errorf("Unexpected file statuses %v", status)
+	}
+	if files.calls != 4 {
+		t.Errorf("Expected one lookup per file, got %d", files.calls)
+	}
+
+	// The failed lookup still downloads from the message payload URL
+	if downloaded["F1"] != models.FileDownloaded || downloaded["F2"] != models.FileDownloadSkipped ||
+		downloaded["F3"] != models.FileDownloadFailed || downloaded["F4"] != models.FileDownloadFailed {
+		t.Errorf("Unexpected download statuses %v", downloaded)
+	}
+	if files.downloads != 3 {
+		t.Errorf("Expected one download per file, got %d", files.downloads)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "general-files", "F1-chart.png")); err != nil || string(data) != "png" {
+		t.Errorf("Expected the downloaded file, got %q, %v", data, err)
+	}
+
+	if !export.ExportInfo.Partial {
+		t.Error("Expected the failed lookup to mark the export partial")
+	}
+	want := []string{
+		"file lookup failed for 1 file (F3): files.info failed",
+		"file download failed for 2 files (F3, F4): download returned status 404",
+	}
+	if !reflect.DeepEqual(export.ExportInfo.Warnings, want) {
+		t.Errorf("Expected one warning per failure kind, got %q", export.ExportInfo.Warnings)
+	}
+}
+
+func TestFileFailureWarning(t *testing.T) {
+	ids := []string{"F1", "F2", "F3", "F4", "F5", "F6", "F7"}
+	got := fileFailureWarning("file download", ids, fmt.Errorf("timeout"))
+	if want := "file download failed for 7 files (F1, F2, F3, F4, F5 and 2 more): timeout"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
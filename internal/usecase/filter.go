@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// ResolveUserIDs maps user references to user IDs. A reference is a user ID,
+// a user name or a display name, optionally prefixed with @. IDs take
+// precedence over user names and user names over display names; a reference
+// matching several users is an error listing them.
+func ResolveUserIDs(users []models.User, refs []string) ([]string, error) {
+	var ids []string
+	for _, ref := range refs {
+		name := strings.TrimPrefix(strings.TrimSpace(ref), "@")
+		if name == "" {
+			continue
+		}
+
+		var byID, byName, byDisplayName []models.User
+		for _, user := range users {
+			switch {
+			case user.ID == name:
+				byID = append(byID, user)
+			case strings.EqualFold(user.Name, name):
+				byName = append(byName, user)
+			case strings.EqualFold(user.Profile.DisplayName, name):
+				byDisplayName = append(byDisplayName, user)
+			}
+		}
+
+		var matches []models.User
+		for _, candidates := range [][]models.User{byID, byName, byDisplayName} {
+			if len(candidates) > 0 {
+				matches = candidates
+				break
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("user '%s' not found", ref)
+		case 1:
+			ids = append(ids, matches[0].ID)
+		default:
+			candidates := make([]string, 0, len(matches))
+			for _, user := range matches {
+				candidates = append(candidates, fmt.Sprintf("%s (@%s)", user.ID, user.Name))
+			}
+			return nil, fmt.Errorf("user '%s' is ambiguous, it matches %s; use a user ID", ref, strings.Join(candidates, ", "))
+		}
+	}
+	return ids, nil
+}
+
+// FilterMessagesByUser keeps the messages and thread replies authored by the
+// given users. An empty user list keeps everything.
+func FilterMessagesByUser(messages []models.Message, userIDs []string) []models.Message {
+	if len(userIDs) == 0 {
+		return messages
+	}
+	return FilterMessages(messages, userPredicate(userIDs))
+}
+
+// FilterMessages keeps the messages and thread replies for which keep returns
+// true. A thread parent that does not match is kept for context when it has
+// matching replies.
+func FilterMessages(messages []models.Message, keep func(models.Message) bool) []models.Message {
+	var filtered []models.Message
+	for _, msg := range messages {
+		var replies []models.Message
+		for _, reply := range msg.Thread {
+			if keep(reply) {
+				replies = append(replies, reply)
+			}
+		}
+		if (msg.Omitted || !keep(msg)) && len(replies) == 0 {
+			continue
+		}
+		msg.Thread = replies
+		filtered = append(filtered, msg)
+	}
+	return filtered
+}
+
+// ExcludeSubtypes drops messages and thread replies with one of the given
+// subtypes, such as channel_join or bot_message
+func ExcludeSubtypes(messages []models.Message, subtypes []string) []models.Message {
+	if len(subtypes) == 0 {
+		return messages
+	}
+
+	return dropMessages(messages, subtypePredicate(subtypes), false)
+}
+
+// ExcludeBots drops messages and thread replies posted by bots and apps
+func ExcludeBots(messages []models.Message) []models.Message {
+	return dropMessages(messages, models.Message.IsBot, false)
+}
+
+// ExcludeBotsBeforeThreads drops messages posted by bots and apps like
+// ExcludeBots, but keeps bot thread parents as omitted placeholders until
+// their replies are fetched and filtered
+func ExcludeBotsBeforeThreads(messages []models.Message) []models.Message {
+	return dropMessages(messages, models.Message.IsBot, true)
+}
+
+// subtypePredicate matches messages with one of the given subtypes
+func subtypePredicate(subtypes []string) func(models.Message) bool {
+	excluded := make(map[string]bool, len(subtypes))
+	for _, subtype := range subtypes {
+		excluded[strings.TrimSpace(subtype)] = true
+	}
+	return func(msg models.Message) bool {
+		return excluded[msg.Subtype]
+	}
+}
+
+// excludeNoise drops the excluded subtypes and, with --no-bots, bot messages
+// of an export. With awaitReplies, thread parents whose replies are still to
+// be fetched are kept as omitted placeholders.
+func excludeNoise(messages []models.Message, options models.ExportOptions, awaitReplies bool) []models.Message {
+	subtypes := excludedSubtypes(options)
+	bots := options.Bots == models.BotFilterExclude
+	if len(subtypes) == 0 && !bots {
+		return messages
+	}
+	excluded := subtypePredicate(subtypes)
+	return dropMessages(messages, func(msg models.Message) bool {
+		return excluded(msg) || bots && msg.IsBot()
+	}, awaitReplies)
+}
+
+// ExcludeExternal drops messages and thread replies by members of other
+// organizations and removes them from users
+func ExcludeExternal(messages []models.Message, users map[string]models.User) []models.Message {
+	external := make(map[string]bool)
+	for id, user := range users {
+		if user.IsExternal {
+			external[id] = true
+			delete(users, id)
+		}
+	}
+	return dropMessages(messages, func(msg models.Message) bool {
+		return external[msg.User]
+	}, false)
+}
+
+// dropMessages removes the messages and thread replies for which drop returns
+// true. A dropped thread parent is replaced by an omitted placeholder while
+// it has replies left, or with awaitReplies replies still to be fetched, so
+// the kept replies stay in their thread.
+func dropMessages(messages []models.Message, drop func(models.Message) bool, awaitReplies bool) []models.Message {
+	var filtered []models.Message
+	for _, msg := range messages {
+		var replies []models.Message
+		for _, reply := range msg.Thread {
+			if !drop(reply) {
+				replies = append(replies, reply)
+			}
+		}
+		if msg.Omitted || drop(msg) {
+			pending := awaitReplies && len(msg.Thread) == 0 && msg.ReplyCount > 0
+			if len(replies) == 0 && !pending {
+				continue
+			}
+			msg = omittedParent(msg)
+		}
+		msg.Thread = replies
+		filtered = append(filtered, msg)
+	}
+	return filtered
+}
+
+// omittedParent reduces a thread parent dropped by a filter to what places
+// its thread: the content and author are left out of the export
+func omittedParent(msg models.Message) models.Message {
+	return models.Message{
+		Type:            msg.Type,
+		Timestamp:       msg.Timestamp,
+		ThreadTS:        msg.ThreadTS,
+		ReplyCount:      msg.ReplyCount,
+		ReplyUsers:      msg.ReplyUsers,
+		ReplyUsersCount: msg.ReplyUsersCount,
+		LatestReply:     msg.LatestReply,
+		Thread:          msg.Thread,
+		Omitted:         true,
+	}
+}
+
+// ThreadedMessages keeps the messages that started a thread, with their
+// replies. Messages whose replies could not be fetched count by their
+// reply_count.
+func ThreadedMessages(messages []models.Message) []models.Message {
+	var threaded []models.Message
+	for _, msg := range messages {
+		if msg.InThread || (len(msg.Thread) == 0 && msg.ReplyCount == 0) {
+			continue
+		}
+		threaded = append(threaded, msg)
+	}
+	return threaded
+}
+
+// ParticipatedThreads keeps the messages by the given users together with
+// every thread they took part in: a thread is kept whole, parent and all
+// replies, when one of the users started it or replied to it
+func ParticipatedThreads(messages []models.Message, userIDs []string) []models.Message {
+	if len(userIDs) == 0 {
+		return messages
+	}
+	byUser := userPredicate(userIDs)
+	var kept []models.Message
+	for _, msg := range messages {
+		participated := byUser(msg)
+		for _, reply := range msg.Thread {
+			if participated {
+				break
+			}
+			participated = byUser(reply)
+		}
+		if participated {
+			kept = append(kept, msg)
+		}
+	}
+	return kept
+}
+
+// userPredicate matches messages authored by one of the given users
+func userPredicate(userIDs []string) func(models.Message) bool {
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	return func(msg models.Message) bool {
+		return wanted[msg.User]
+	}
+}
+
+// messagePredicate combines the user, bot, reaction and text filters of an export. It
+// returns nil when no filter is set.
+func messagePredicate(options models.ExportOptions) (func(models.Message) bool, error) {
+	var predicates []func(models.Message) bool
+	// With ParticipatedThreads the users select whole threads instead
+	if len(options.Users) > 0 && !options.ParticipatedThreads {
+		predicates = append(predicates, userPredicate(options.Users))
+	}
+	if options.Bots == models.BotFilterOnly {
+		predicates = append(predicates, models.Message.IsBot)
+	}
+	if options.MinReactions > 0 {
+		predicates = append(predicates, func(msg models.Message) bool {
+			return msg.ReactionCount() >= options.MinReactions
+		})
+	}
+	if options.Match != "" {
+		pattern, err := regexp.Compile(options.Match)
+		if err != nil {
+			return nil, fmt.Errorf("invalid match pattern '%s': %w", options.Match, err)
+		}
+		predicates = append(predicates, func(msg models.Message) bool {
+			return pattern.MatchString(msg.Text)
+		})
+	}
+
+	if len(predicates) == 0 {
+		return nil, nil
+	}
+	return func(msg models.Message) bool {
+		for _, predicate := range predicates {
+			if !predicate(msg) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestResolveUserIDs(t *testing.T) {
+	users := []models.User{
+		{ID: "U123456", Name: "alice", Profile: models.Profile{DisplayName: "Alice"}},
+		{ID: "U789012", Name: "bob.jones", Profile: models.Profile{DisplayName: "Bob"}},
+	}
+
+	ids, err := ResolveUserIDs(users, []string{"@alice", "bob", "U789012"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(ids) != 3 || ids[0] != "U123456" || ids[1] != "U789012" || ids[2] != "U789012" {
+		t.Errorf("Unexpected IDs: %v", ids)
+	}
+
+	if _, err := ResolveUserIDs(users, []string{"@carol"}); err == nil {
+		t.Error("Expected error for unknown user")
+	}
+
+	// Display names are not unique; user names win over them
+	users = append(users, models.User{ID: "U345678", Name: "bob.smith", Profile: models.Profile{DisplayName: "Bob"}},
+		models.User{ID: "U901234", Name: "alice.b", Profile: models.Profile{DisplayName: "alice"}})
+	_, err = ResolveUserIDs(users, []string{"@Bob"})
+	if err == nil || !strings.Contains(err.Error(), "U789012 (@bob.jones), U345678 (@bob.smith)") {
+		t.Errorf("Expected an error listing both Bobs, got %v", err)
+	}
+	if ids, err := ResolveUserIDs(users, []string{"alice"}); err != nil || len(ids) != 1 || ids[0] != "U123456" {
+		t.Errorf("Expected the user name to win over a display name, got %v, %v", ids, err)
+	}
+}
+
+func TestFilterMessagesByUser(t *testing.T) {
+	messages := []models.Message{
+		{User: "U1", Text: "by alice", Thread: []models.Message{{User: "U2", Text: "bob reply"}}},
+		{User: "U2", Text: "by bob"},
+		{User: "U3", Text: "by carol", Thread: []models.Message{{User: "U1", Text: "alice reply"}}},
+	}
+
+	filtered := FilterMessagesByUser(messages, []string{"U1"})
+	if len(filtered) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(filtered))
+	}
+	if len(filtered[0].Thread) != 0 {
+		t.Errorf("Expected replies by others to be dropped, got %+v", filtered[0].Thread)
+	}
+	if filtered[1].Text != "by carol" || len(filtered[1].Thread) != 1 {
+		t.Errorf("Expected carol's thread kept for alice's reply, got %+v", filtered[1])
+	}
+
+	if len(FilterMessagesByUser(messages, nil)) != 3 {
+		t.Error("Expected no filtering without users")
+	}
+}
+
+func TestParticipatedThreads(t *testing.T) {
+	messages := []models.Message{
+		{User: "U1", Text: "by alice", Thread: []models.Message{{User: "U2", Text: "bob reply"}}},
+		{User: "U2", Text: "by bob"},
+		{User: "U3", Text: "by carol", Thread: []models.Message{{User: "U2", Text: "bob reply"}, {User: "U1", Text: "alice reply"}, {User: "U3", Text: "carol reply"}}},
+		{User: "U3", Text: "carol and bob", Thread: []models.Message{{User: "U2", Text: "bob reply"}}},
+	}
+
+	kept := ParticipatedThreads(messages, []string{"U1"})
+	if len(kept) != 2 || kept[0].Text != "by alice" || kept[1].Text != "by carol" {
+		t.Fatalf("Expected alice's message and the thread they replied to, got %+v", kept)
+	}
+	if len(kept[0].Thread) != 1 || len(kept[1].Thread) != 3 {
+		t.Errorf("Expected threads kept whole, got %+v", kept)
+	}
+
+	// Combined with other filters the users select threads, not messages
+	keep, err := messagePredicate(models.ExportOptions{Users: []string{"U1"}, ParticipatedThreads: true})
+	if err != nil || keep != nil {
+		t.Errorf("Expected no per-message user filter, got %v", err)
+	}
+}
+
+func TestExcludeSubtypes(t *testing.T) {
+	messages := []models.Message{
+		{Subtype: "channel_join", Text: "joined"},
+		{Text: "hello", Thread: []models.Message{{Subtype: "bot_message", Text: "bot"}, {Text: "reply"}}},
+	}
+
+	filtered := ExcludeSubtypes(messages, []string{"channel_join", "bot_message"})
+	if len(filtered) != 1 || filtered[0].Text != "hello" {
+		t.Fatalf("Expected only the plain message, got %+v", filtered)
+	}
+	if len(filtered[0].Thread) != 1 || filtered[0].Thread[0].Text != "reply" {
+		t.Errorf("Expected bot reply to be dropped, got %+v", filtered[0].Thread)
+	}
+}
+
+func TestExcludeNoiseOmitsParents(t *testing.T) {
+	messages := []models.Message{
+		{Subtype: "channel_join", Timestamp: "1.000000", Text: "joined"},
+		{Subtype: "bot_message", Timestamp: "2.000000", ThreadTS: "2.000000", ReplyCount: 1, Text: "deploy finished"},
+		{BotID: "B1", Timestamp: "3.000000", ThreadTS: "3.000000", ReplyCount: 1, Text: "alert", Thread: []models.Message{{BotID: "B1", Text: "resolved"}}},
+	}
+	options := models.ExportOptions{ExcludeSubtypes: []string{"channel_join"}, Bots: models.BotFilterExclude}
+
+	// Before the thread fetch the bot parents wait for their replies
+	pending := excludeNoise(messages, options, true)
+	if len(pending) != 1 || !pending[0].Omitted || pending[0].Text != "" || pending[0].ThreadTS != "2.000000" || pending[0].ReplyCount != 1 {
+		t.Fatalf("Expected the unfetched thread parent as a placeholder, got %+v", pending)
+	}
+
+	pending[0].Thread = []models.Message{{User: "U1", Text: "thanks"}}
+	filtered := excludeNoise(pending, options, false)
+	if len(filtered) != 1 || !filtered[0].Omitted || len(filtered[0].Thread) != 1 || filtered[0].Thread[0].Text != "thanks" {
+		t.Errorf("Expected the human reply kept under the omitted parent, got %+v", filtered)
+	}
+
+	// Without replies left the placeholder goes too
+	if filtered := excludeNoise(messages, options, false); len(filtered) != 0 {
+		t.Errorf("Expected no messages left, got %+v", filtered)
+	}
+	if filtered := FilterMessages(pending, func(models.Message) bool { return true }); len(filtered) != 1 {
+		t.Errorf("Expected the placeholder kept for its reply, got %+v", filtered)
+	}
+}
+
+func TestMessagePredicate(t *testing.T) {
+	keep, err := messagePredicate(models.ExportOptions{Users: []string{"U1"}, Match: "(?i)incident|outage"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !keep(models.Message{User: "U1", Text: "Major Incident"}) {
+		t.Error("Expected matching message by U1 to be kept")
+	}
+	if keep(models.Message{User: "U2", Text: "outage"}) || keep(models.Message{User: "U1", Text: "lunch"}) {
+		t.Error("Expected both user and text to be required")
+	}
+
+	if keep, _ := messagePredicate(models.ExportOptions{}); keep != nil {
+		t.Error("Expected no predicate without filters")
+	}
+	if _, err := messagePredicate(models.ExportOptions{Match: "("}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestExcludeBots(t *testing.T) {
+	messages := []models.Message{
+		{User: "U1", Text: "human", Thread: []models.Message{{BotID: "B1", Text: "bot reply"}}},
+		{Subtype: "bot_message", Text: "integration"},
+		{BotID: "B2", User: "U2", Text: "app"},
+	}
+
+	filtered := ExcludeBots(messages)
+	if len(filtered) != 1 || len(filtered[0].Thread) != 0 {
+		t.Errorf("Expected only the human message without bot replies, got %+v", filtered)
+	}
+
+	// Before the thread fetch a bot parent waits for its replies
+	pending := ExcludeBotsBeforeThreads(append(messages, models.Message{BotID: "B3", Text: "alert", ThreadTS: "1.000000", ReplyCount: 2}))
+	if len(pending) != 2 || !pending[1].Omitted || pending[1].ThreadTS != "1.000000" {
+		t.Errorf("Expected the bot thread parent as a placeholder, got %+v", pending)
+	}
+
+	only := FilterMessages(messages, models.Message.IsBot)
+	if len(only) != 3 || only[0].Text != "human" || len(only[0].Thread) != 1 {
+		t.Errorf("Expected bot messages plus the parent of a bot reply, got %+v", only)
+	}
+}
+
+func TestMessagePredicateMinReactions(t *testing.T) {
+	keep, err := messagePredicate(models.ExportOptions{MinReactions: 3})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	messages := []models.Message{
+		{Text: "popular", Reactions: []models.Reaction{{Name: "tada", Count: 2}, {Name: "heart", Count: 1}}},
+		{Text: "quiet", Reactions: []models.Reaction{{Name: "eyes", Count: 1}}},
+		{Text: "parent", Thread: []models.Message{{Text: "great reply", Reactions: []models.Reaction{{Name: "100", Count: 4}}}}},
+	}
+
+	filtered := FilterMessages(messages, keep)
+	if len(filtered) != 2 || filtered[0].Text != "popular" || filtered[1].Text != "parent" {
+		t.Errorf("Expected popular message and parent of popular reply, got %+v", filtered)
+	}
+}
+
+func TestExcludeExternal(t *testing.T) {
+	users := map[string]models.User{
+		"U1": {ID: "U1", TeamID: "T1"},
+		"W2": {ID: "W2", TeamID: "T2", IsExternal: true},
+	}
+	messages := []models.Message{
+		{User: "U1", Text: "internal", Thread: []models.Message{{User: "W2", Text: "external reply"}, {User: "U1", Text: "reply"}}},
+		{User: "W2", Text: "external", Thread: []models.Message{{User: "U1", Text: "reply to external"}}},
+	}
+
+	filtered := ExcludeExternal(messages, users)
+	if len(filtered) != 2 || len(filtered[0].Thread) != 1 || filtered[0].Thread[0].Text != "reply" {
+		t.Fatalf("Expected only internal messages, got %+v", filtered)
+	}
+	if parent := filtered[1]; !parent.Omitted || parent.User != "" || parent.Text != "" || len(parent.Thread) != 1 {
+		t.Errorf("Expected the external parent omitted with the internal reply kept, got %+v", parent)
+	}
+	if _, ok := users["W2"]; ok || len(users) != 1 {
+		t.Errorf("Expected the external user removed from the directory, got %+v", users)
+	}
+}
+
+func TestThreadedMessages(t *testing.T) {
+	messages := []models.Message{
+		{Text: "single"},
+		{Text: "thread", Thread: []models.Message{{Text: "reply"}}},
+		{Text: "replies not fetched", ReplyCount: 2},
+		{Text: "", InThread: true, ThreadTS: "1704067200.000000"},
+	}
+
+	threaded := ThreadedMessages(messages)
+	if len(threaded) != 2 || threaded[0].Text != "thread" || threaded[1].Text != "replies not fetched" {
+		t.Errorf("Expected the two threads, got %+v", threaded)
+	}
+}
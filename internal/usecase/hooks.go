@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// ExportHook lets code outside the exporter filter and enrich an export at
+// each stage of the pipeline. Hooks run in the order they were added; an
+// error from OnMessageFetched or OnBeforeWrite fails the export.
+type ExportHook interface {
+	// OnMessageFetched is called for every fetched message and thread reply
+	// once filters are applied. It may change msg; returning false drops it
+	// (with its replies, for a thread parent).
+	OnMessageFetched(ctx context.Context, msg *models.Message) (bool, error)
+	// OnBeforeWrite is called with the complete export before it is written
+	OnBeforeWrite(ctx context.Context, export *models.ChannelExport) error
+	// OnComplete is called with the result of every finished or failed
+	// export. Its errors are only logged.
+	OnComplete(ctx context.Context, result *models.ExportResult) error
+}
+
+// HookFuncs implements ExportHook with optional functions, so Go code only
+// provides the stages it needs
+type HookFuncs struct {
+	MessageFetched func(ctx context.Context, msg *models.Message) (bool, error)
+	BeforeWrite    func(ctx context.Context, export *models.ChannelExport) error
+	Complete       func(ctx context.Context, result *models.ExportResult) error
+}
+
+// OnMessageFetched calls MessageFetched, keeping the message when it is nil
+func (h HookFuncs) OnMessageFetched(ctx context.Context, msg *models.Message) (bool, error) {
+	if h.MessageFetched == nil {
+		return true, nil
+	}
+	return h.MessageFetched(ctx, msg)
+}
+
+// OnBeforeWrite calls BeforeWrite if set
+func (h HookFuncs) OnBeforeWrite(ctx context.Context, export *models.ChannelExport) error {
+	if h.BeforeWrite == nil {
+		return nil
+	}
+	return h.BeforeWrite(ctx, export)
+}
+
+// OnComplete calls Complete if set
+func (h HookFuncs) OnComplete(ctx context.Context, result *models.ExportResult) error {
+	if h.Complete == nil {
+		return nil
+	}
+	return h.Complete(ctx, result)
+}
+
+// AddHook adds a hook to the export pipeline
+func (s *ExportService) AddHook(hook ExportHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// hookChannelKey carries the ID of the exported channel to hooks
+type hookChannelKey struct{}
+
+// HookChannel returns the ID of the channel being exported, for hooks
+func HookChannel(ctx context.Context) string {
+	channelID, _ := ctx.Value(hookChannelKey{}).(string)
+	return channelID
+}
+
+// runMessageHooks passes every message and thread reply through the
+// OnMessageFetched hooks
+func (s *ExportService) runMessageHooks(ctx context.Context, messages []models.Message) ([]models.Message, error) {
+	if len(s.hooks) == 0 {
+		return messages, nil
+	}
+
+	keep := func(msg *models.Message) (bool, error) {
+		for _, hook := range s.hooks {
+			ok, err := hook.OnMessageFetched(ctx, msg)
+			if err != nil {
+				return false, fmt.Errorf("message hook failed on %s: %w", msg.Timestamp, err)
+			}
+			if !ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	var kept []models.Message
+	for _, msg := range messages {
+		// Hooks see each message on its own; replies follow separately
+		thread := msg.Thread
+		msg.Thread = nil
+		ok, err := keep(&msg)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		var replies []models.Message
+		for _, reply := range thread {
+			ok, err := keep(&reply)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				replies = append(replies, reply)
+			}
+		}
+		msg.Thread = replies
+		kept = append(kept, msg)
+	}
+	return kept, nil
+}
+
+// runBeforeWriteHooks passes the export through the OnBeforeWrite hooks
+func (s *ExportService) runBeforeWriteHooks(ctx context.Context, exportData *models.ChannelExport) error {
+	for _, hook := range s.hooks {
+		if err := hook.OnBeforeWrite(ctx, exportData); err != nil {
+			return fmt.Errorf("before-write hook failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// runCompleteHooks reports the export result to the OnComplete hooks
+func (s *ExportService) runCompleteHooks(ctx context.Context, channelID string, result *models.ExportResult) {
+	for _, hook := range s.hooks {
+		if err := hook.OnComplete(ctx, result); err != nil {
+			s.logger.Warn("completion hook failed", "channel_id", channelID, "error", err)
+		}
+	}
+}
+
+// DefaultHookTimeout bounds each run of a shell hook command
+const DefaultHookTimeout = time.Minute
+
+// ShellHook runs configured shell commands at the pipeline stages. Each
+// command gets the stage's data as JSON on stdin:
+//
+//   - MessageFetched runs once per message and reply with the message. Empty
+//     output keeps the message, a JSON object replaces it and "null" drops it.
+//   - BeforeWrite runs once with the whole export. Empty output keeps it,
+//     otherwise the output replaces it.
+//   - Complete runs once with the export result; its output is ignored.
+//
+// SLACKER_HOOK names the stage and SLACKER_CHANNEL_ID the exported channel.
+type ShellHook struct {
+	MessageFetched string
+	BeforeWrite    string
+	Complete       string
+	Timeout        time.Duration
+}
+
+// OnMessageFetched runs the MessageFetched command with the message
+func (h *ShellHook) OnMessageFetched(ctx context.Context, msg *models.Message) (bool, error) {
+	if h.MessageFetched == "" {
+		return true, nil
+	}
+	output, err := h.run(ctx, h.MessageFetched, "message_fetched", msg)
+	if err != nil || len(output) == 0 {
+		return err == nil, err
+	}
+	if string(output) == "null" {
+		return false, nil
+	}
+	var changed models.Message
+	if err := json.Unmarshal(output, &changed); err != nil {
+		return false, fmt.Errorf("invalid message from hook: %w", err)
+	}
+	*msg = changed
+	return true, nil
+}
+
+// OnBeforeWrite runs the BeforeWrite command with the export
+func (h *ShellHook) OnBeforeWrite(ctx context.Context, export *models.ChannelExport) error {
+	if h.BeforeWrite == "" {
+		return nil
+	}
+	output, err := h.run(ctx, h.BeforeWrite, "before_write", export)
+	if err != nil || len(output) == 0 {
+		return err
+	}
+	var changed models.ChannelExport
+	if err := json.Unmarshal(output, &changed); err != nil {
+		return fmt.Errorf("invalid export from hook: %w", err)
+	}
+	*export = changed
+	return nil
+}
+
+// OnComplete runs the Complete command with the result
+func (h *ShellHook) OnComplete(ctx context.Context, result *models.ExportResult) error {
+	if h.Complete == "" {
+		return nil
+	}
+	_, err := h.run(ctx, h.Complete, "complete", result)
+	return err
+}
+
+// run executes command with the JSON encoding of input on stdin and returns
+// its trimmed output
+func (h *ShellHook) run(ctx context.Context, command, stage string, input interface{}) ([]byte, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(), "SLACKER_HOOK="+stage, "SLACKER_CHANNEL_ID="+HookChannel(ctx))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("%s hook: %w: %s", stage, err, msg)
+		}
+		return nil, fmt.Errorf("%s hook: %w", stage, err)
+	}
+	return bytes.TrimSpace(stdout.Bytes()), nil
+}
+
+// shellCommand runs command with the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_ExportChannelHooks(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	var seen []string
+	var completed *models.ExportResult
+	service.AddHook(HookFuncs{
+		MessageFetched: func(ctx context.Context, msg *models.Message) (bool, error) {
+			seen = append(seen, HookChannel(ctx)+"/"+msg.Timestamp)
+			if msg.User == "U345678" {
+				return false, nil
+			}
+			msg.Text = strings.ToUpper(msg.Text)
+			return true, nil
+		},
+		BeforeWrite: func(ctx context.Context, export *models.ChannelExport) error {
+			export.Channel.Purpose = "archived by hook"
+			return nil
+		},
+		Complete: func(ctx context.Context, result *models.ExportResult) error {
+			completed = result
+			return nil
+		},
+	})
+
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(seen) != 4 || seen[0] != "C123456/1704067200.123456" {
+		t.Errorf("Expected the hook to see 2 messages and 2 replies of C123456, got %v", seen)
+	}
+	if completed != result {
+		t.Error("Expected the completion hook to get the export result")
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if export.Messages[0].Text != "HELLO EVERYONE!" {
+		t.Errorf("Expected the hook to change the text, got %q", export.Messages[0].Text)
+	}
+	if replies := export.Messages[1].Replies; len(replies) != 1 || replies[0].User != "U123456" {
+		t.Errorf("Expected the reply by U345678 dropped, got %+v", replies)
+	}
+	if export.Channel.Purpose != "archived by hook" {
+		t.Errorf("Expected the before-write hook to change the export, got %q", export.Channel.Purpose)
+	}
+}
+
+func TestShellHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell hooks are tested with sh")
+	}
+	ctx := context.WithValue(context.Background(), hookChannelKey{}, "C123456")
+
+	hook := &ShellHook{
+		MessageFetched: `if grep -q secret; then echo null; fi`,
+		BeforeWrite:    `sed "s/General/Renamed/"`,
+		Complete:       `test "$SLACKER_HOOK" = complete && test "$SLACKER_CHANNEL_ID" = C123456`,
+	}
+
+	keep, err := hook.OnMessageFetched(ctx, &models.Message{Text: "a secret plan"})
+	if err != nil || keep {
+		t.Errorf("Expected the message dropped, got %v, %v", keep, err)
+	}
+	msg := models.Message{Text: "hello", Timestamp: "1704067200.000100"}
+	keep, err = hook.OnMessageFetched(ctx, &msg)
+	if err != nil || !keep || msg.Text != "hello" {
+		t.Errorf("Expected the message kept unchanged, got %v, %v, %+v", keep, err, msg)
+	}
+
+	export := models.ChannelExport{Channel: models.ChannelInfo{ID: "C123456", Name: "General"}}
+	if err := hook.OnBeforeWrite(ctx, &export); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if export.Channel.Name != "Renamed" {
+		t.Errorf("Expected the export replaced by the hook output, got %q", export.Channel.Name)
+	}
+
+	if err := hook.OnComplete(ctx, &models.ExportResult{Success: true}); err != nil {
+		t.Errorf("Expected stage and channel in the environment, got %v", err)
+	}
+
+	failing := &ShellHook{BeforeWrite: `echo broken >&2; exit 2`}
+	if err := failing.OnBeforeWrite(ctx, &export); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected the command's error output, got %v", err)
+	}
+}
@@ -0,0 +1,371 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// defaultIndexBatchSize is the number of documents sent per bulk request
+const defaultIndexBatchSize = 500
+
+// IndexOptions describes where exported messages are indexed
+type IndexOptions struct {
+	// URL is the Elasticsearch or OpenSearch endpoint, e.g. https://host:9200.
+	// Credentials may be given as user info in the URL.
+	URL   string
+	Index string
+	// APIKey is sent as "Authorization: ApiKey"; Username and Password use
+	// basic auth
+	APIKey   string
+	Username string
+	Password string
+	// BatchSize is the number of documents per bulk request (0 = 500)
+	BatchSize int
+}
+
+// IndexResult summarizes an indexing run
+type IndexResult struct {
+	Index    string        `json:"index"`
+	Created  bool          `json:"created"`
+	Indexed  int           `json:"indexed"`
+	Failed   int           `json:"failed"`
+	Errors   []string      `json:"errors,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// MessageDocument is the search document for a message or thread reply
+type MessageDocument struct {
+	ChannelID     string    `json:"channel_id"`
+	ChannelName   string    `json:"channel_name"`
+	User          string    `json:"user"`
+	UserName      string    `json:"user_name,omitempty"`
+	Text          string    `json:"text"`
+	Timestamp     time.Time `json:"ts"`
+	MessageID     string    `json:"message_id"`
+	ThreadTS      string    `json:"thread_ts,omitempty"`
+	IsReply       bool      `json:"is_reply"`
+	ReplyCount    int       `json:"reply_count,omitempty"`
+	Subtype       string    `json:"subtype,omitempty"`
+	Edited        bool      `json:"edited"`
+	Reactions     []string  `json:"reactions,omitempty"`
+	ReactionCount int       `json:"reaction_count"`
+	Files         []string  `json:"files,omitempty"`
+	Permalink     string    `json:"permalink,omitempty"`
+}
+
+// indexMapping analyzes message text and keeps identifiers as keywords so
+// they can be aggregated in Kibana or OpenSearch Dashboards
+var indexMapping = map[string]interface{}{
+	"mappings": map[string]interface{}{
+		"properties": map[string]interface{}{
+			"channel_id":     map[string]string{"type": "keyword"},
+			"channel_name":   map[string]string{"type": "keyword"},
+			"user":           map[string]string{"type": "keyword"},
+			"user_name":      map[string]string{"type": "keyword"},
+			"text":           map[string]string{"type": "text"},
+			"ts":             map[string]string{"type": "date"},
+			"message_id":     map[string]string{"type": "keyword"},
+			"thread_ts":      map[string]string{"type": "keyword"},
+			"is_reply":       map[string]string{"type": "boolean"},
+			"reply_count":    map[string]string{"type": "integer"},
+			"subtype":        map[string]string{"type": "keyword"},
+			"edited":         map[string]string{"type": "boolean"},
+			"reactions":      map[string]string{"type": "keyword"},
+			"reaction_count": map[string]string{"type": "integer"},
+			"files":          map[string]string{"type": "keyword"},
+			"permalink":      map[string]interface{}{"type": "keyword", "index": false},
+		},
+	},
+}
+
+// IndexService pushes exported messages to Elasticsearch or OpenSearch
+type IndexService struct {
+	httpClient *http.Client
+	logger     *slog.Logger
+}
+
+// NewIndexService creates a new index service
+func NewIndexService() *IndexService {
+	return &IndexService{
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		logger:     slog.Default(),
+	}
+}
+
+// SetTransport sets the transport requests to the search cluster go through
+func (s *IndexService) SetTransport(transport http.RoundTripper) {
+	s.httpClient.Transport = transport
+}
+
+// SetLogger sets the logger used for indexing diagnostics
+func (s *IndexService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// Index creates the index with the message mapping if it does not exist and
+// bulk-indexes every message and reply of the export. Documents are keyed
+// by channel and message ID, so indexing an export again updates them.
+func (s *IndexService) Index(ctx context.Context, export *models.ChannelExport, opts IndexOptions) (*IndexResult, error) {
+	start := time.Now()
+	base, err := parseIndexURL(&opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Index == "" {
+		return nil, fmt.Errorf("no index name given")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultIndexBatchSize
+	}
+
+	result := &IndexResult{Index: opts.Index}
+	if result.Created, err = s.ensureIndex(ctx, base, opts); err != nil {
+		return nil, err
+	}
+
+	docs := MessageDocuments(export)
+	for i := 0; i < len(docs); i += opts.BatchSize {
+		end := min(i+opts.BatchSize, len(docs))
+		indexed, errs, err := s.bulk(ctx, base, opts, export.Channel.ID, docs[i:end])
+		if err != nil {
+			return result, err
+		}
+		result.Indexed += indexed
+		result.Failed += len(errs)
+		result.Errors = append(result.Errors, errs...)
+		s.logger.Debug("bulk request finished", "index", opts.Index, "indexed", indexed, "failed", len(errs))
+	}
+
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// MessageDocuments flattens the messages and thread replies of an export
+// into search documents
+func MessageDocuments(export *models.ChannelExport) []MessageDocument {
+	var docs []MessageDocument
+	add := func(msg models.ExportMessage, reply bool) {
+		doc := MessageDocument{
+			ChannelID:   export.Channel.ID,
+			ChannelName: export.Channel.Name,
+			User:        msg.User,
+			UserName:    userDisplayName(msg.User, export.Users),
+			Text:        msg.Text,
+			Timestamp:   msg.Timestamp,
+			MessageID:   msg.ID,
+			ThreadTS:    msg.ThreadTimestamp,
+			IsReply:     reply,
+			ReplyCount:  msg.ReplyCount,
+			Subtype:     msg.Subtype,
+			Edited:      msg.Edited != nil,
+			Permalink:   msg.Permalink,
+		}
+		for _, reaction := range msg.Reactions {
+			doc.Reactions = append(doc.Reactions, reaction.Name)
+			doc.ReactionCount += reaction.Count
+		}
+		for _, file := range msg.Files {
+			doc.Files = append(doc.Files, file.Name)
+		}
+		docs = append(docs, doc)
+	}
+
+	for _, msg := range export.Messages {
+		// Broadcast links share the ID of the reply they point to, and
+		// omitted parents have no content
+		if !msg.InThread && !msg.Omitted {
+			add(msg, false)
+		}
+		for _, reply := range msg.Replies {
+			add(reply, true)
+		}
+	}
+	return docs
+}
+
+// parseIndexURL validates the endpoint and moves credentials from its user
+// info into opts
+func parseIndexURL(opts *IndexOptions) (*url.URL, error) {
+	base, err := url.Parse(strings.TrimSuffix(opts.URL, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid search URL '%s': use http(s)://host:port", opts.URL)
+	}
+	if base.User != nil {
+		if opts.Username == "" {
+			opts.Username = base.User.Username()
+		}
+		if password, ok := base.User.Password(); ok && opts.Password == "" {
+			opts.Password = password
+		}
+		base.User = nil
+	}
+	return base, nil
+}
+
+// ensureIndex creates the index with the message mapping unless it exists
+func (s *IndexService) ensureIndex(ctx context.Context, base *url.URL, opts IndexOptions) (bool, error) {
+	resp, err := s.do(ctx, http.MethodHead, base, "/"+url.PathEscape(opts.Index), opts, "", nil)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return false, nil
+	case resp.StatusCode != http.StatusNotFound:
+		return false, indexHTTPError("check index", resp)
+	}
+
+	body, err := json.Marshal(indexMapping)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal index mapping: %w", err)
+	}
+	resp, err = s.do(ctx, http.MethodPut, base, "/"+url.PathEscape(opts.Index), opts, "application/json", body)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, indexHTTPError("create index", resp)
+	}
+	return true, nil
+}
+
+// bulkResponse is the part of a _bulk response used to count failures
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		ID     string          `json:"_id"`
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// bulkRetryBackoff is the first pause before documents a bulk request
+// rejected with status 429 are sent again; it doubles with every retry
+var bulkRetryBackoff = time.Second
+
+// bulk indexes a batch of documents and returns the number indexed and the
+// per-document errors. Documents the cluster rejects with status 429 because
+// its queues are full are sent again after a pause, up to
+// maxRateLimitRetries times.
+func (s *IndexService) bulk(ctx context.Context, base *url.URL, opts IndexOptions, channelID string, docs []MessageDocument) (int, []string, error) {
+	indexed := 0
+	var errs []string
+	backoff := bulkRetryBackoff
+	for attempt := 0; ; attempt++ {
+		n, failed, rejected, err := s.bulkOnce(ctx, base, opts, channelID, docs)
+		indexed += n
+		errs = append(errs, failed...)
+		if err != nil || len(rejected) == 0 {
+			return indexed, errs, err
+		}
+		if attempt >= maxRateLimitRetries {
+			for _, doc := range rejected {
+				errs = append(errs, fmt.Sprintf("%s-%s: rejected with status 429", channelID, doc.MessageID))
+			}
+			return indexed, errs, nil
+		}
+
+		s.logger.Debug("bulk documents rejected, retrying", "index", opts.Index, "rejected", len(rejected), "wait", backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return indexed, errs, ctx.Err()
+		case <-timer.C:
+		}
+		docs = rejected
+		backoff *= 2
+	}
+}
+
+// bulkOnce sends one _bulk request and returns the number of documents
+// indexed, the per-document errors and the documents rejected with status
+// 429, which can be sent again
+func (s *IndexService) bulkOnce(ctx context.Context, base *url.URL, opts IndexOptions, channelID string, docs []MessageDocument) (int, []string, []MessageDocument, error) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range docs {
+		action := map[string]map[string]string{"index": {"_index": opts.Index, "_id": channelID + "-" + doc.MessageID}}
+		if err := encoder.Encode(action); err != nil {
+			return 0, nil, nil, fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return 0, nil, nil, fmt.Errorf("failed to encode document %s: %w", doc.MessageID, err)
+		}
+	}
+
+	resp, err := s.do(ctx, http.MethodPost, base, "/_bulk", opts, "application/x-ndjson", body.Bytes())
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, nil, nil, indexHTTPError("bulk request", resp)
+	}
+
+	var parsed bulkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to parse bulk response: %w", err)
+	}
+
+	// Items are listed in the order of the documents
+	var errs []string
+	var rejected []MessageDocument
+	for i, item := range parsed.Items {
+		for _, outcome := range item {
+			switch {
+			case outcome.Status == http.StatusTooManyRequests && i < len(docs):
+				rejected = append(rejected, docs[i])
+			case outcome.Status >= 300 || len(outcome.Error) > 0:
+				errs = append(errs, fmt.Sprintf("%s: %s", outcome.ID, outcome.Error))
+			}
+		}
+	}
+	return len(docs) - len(errs) - len(rejected), errs, rejected, nil
+}
+
+// do sends a request to the search endpoint with the configured credentials,
+// retrying it while the endpoint answers HTTP 429
+func (s *IndexService) do(ctx context.Context, method string, base *url.URL, path string, opts IndexOptions, contentType string, body []byte) (*http.Response, error) {
+	resp, err := doWithRetry(ctx, s.httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, base.String()+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create search request: %w", err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		switch {
+		case opts.APIKey != "":
+			req.Header.Set("Authorization", "ApiKey "+opts.APIKey)
+		case opts.Username != "":
+			req.SetBasicAuth(opts.Username, opts.Password)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// indexHTTPError describes a failed search API call
+func indexHTTPError(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if len(body) > 0 {
+		return fmt.Errorf("failed to %s: status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return fmt.Errorf("failed to %s: status %d", action, resp.StatusCode)
+}
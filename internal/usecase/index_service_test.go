@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestIndexService_Index(t *testing.T) {
+	var mapping map[string]interface{}
+	var ids []string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/slack-general":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPut && r.URL.Path == "/slack-general":
+			json.NewDecoder(r.Body).Decode(&mapping)
+		case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+			if r.Header.Get("Content-Type") != "application/x-ndjson" {
+				t.Errorf("Expected NDJSON bulk body, got %s", r.Header.Get("Content-Type"))
+			}
+			var items []string
+			scanner := bufio.NewScanner(r.Body)
+			for i := 0; scanner.Scan(); i++ {
+				if i%2 == 0 {
+					var action map[string]map[string]string
+					json.Unmarshal(scanner.Bytes(), &action)
+					ids = append(ids, action["index"]["_id"])
+					items = append(items, `{"index":{"_id":"`+action["index"]["_id"]+`","status":201}}`)
+				}
+			}
+			// Reject the last document of the batch
+			items[len(items)-1] = `{"index":{"_id":"x","status":400,"error":{"type":"mapper_parsing_exception"}}}`
+			w.Write([]byte(`{"errors":true,"items":[` + strings.Join(items, ",") + `]}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	export := &models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C1", Name: "general"},
+		Users:   map[string]models.ExportUser{"U1": {ID: "U1", Name: "alice"}},
+		Messages: []models.ExportMessage{
+			{ID: "1.0", User: "U1", Text: "hello", Timestamp: time.Unix(1, 0), Reactions: []models.ExportReaction{{Name: "tada", Count: 2}},
+				Replies: []models.ExportMessage{{ID: "2.0", User: "U2", Text: "hi", Timestamp: time.Unix(2, 0)}}},
+			{ID: "3.0", User: "U1", Text: "bye", Timestamp: time.Unix(3, 0)},
+		},
+	}
+
+	service := NewIndexService()
+	url := strings.Replace(server.URL, "http://", "http://elastic:secret@", 1)
+	result, err := service.Index(context.Background(), export, IndexOptions{URL: url, Index: "slack-general", BatchSize: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !result.Created || mapping == nil {
+		t.Error("Expected index to be created with a mapping")
+	}
+	if len(ids) != 3 || ids[0] != "C1-1.0" || ids[1] != "C1-2.0" {
+		t.Errorf("Expected documents keyed by channel and message, got %v", ids)
+	}
+	// Two batches, each with its last document rejected
+	if result.Indexed != 1 || result.Failed != 2 {
+		t.Errorf("Expected 1 indexed and 2 failed, got %d and %d", result.Indexed, result.Failed)
+	}
+	if !strings.HasPrefix(auth, "Basic ") {
+		t.Errorf("Expected basic auth from URL credentials, got %q", auth)
+	}
+}
+
+func TestIndexService_IndexRateLimited(t *testing.T) {
+	defer func(backoff time.Duration) { bulkRetryBackoff = backoff }(bulkRetryBackoff)
+	bulkRetryBackoff = 0
+
+	var requests int
+	var sent [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPost && r.URL.Path == "/_bulk":
+			requests++
+			if requests == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			var ids, items []string
+			scanner := bufio.NewScanner(r.Body)
+			for i := 0; scanner.Scan(); i++ {
+				if i%2 == 0 {
+					var action map[string]map[string]string
+					json.Unmarshal(scanner.Bytes(), &action)
+					ids = append(ids, action["index"]["_id"])
+					items = append(items, `{"index":{"_id":"`+action["index"]["_id"]+`","status":201}}`)
+				}
+			}
+			sent = append(sent, ids)
+			// The queue is full for the second document on the first try
+			if len(sent) == 1 {
+				items[1] = `{"index":{"_id":"` + ids[1] + `","status":429,"error":{"type":"es_rejected_execution_exception"}}}`
+			}
+			w.Write([]byte(`{"errors":true,"items":[` + strings.Join(items, ",") + `]}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	export := &models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C1", Name: "general"},
+		Messages: []models.ExportMessage{
+			{ID: "1.0", Text: "hello", Timestamp: time.Unix(1, 0)},
+			{ID: "2.0", Text: "bye", Timestamp: time.Unix(2, 0)},
+		},
+	}
+
+	result, err := NewIndexService().Index(context.Background(), export, IndexOptions{URL: server.URL, Index: "slack-general"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Indexed != 2 || result.Failed != 0 {
+		t.Errorf("Expected 2 indexed after retries, got %d indexed and %d failed", result.Indexed, result.Failed)
+	}
+	if requests != 3 || len(sent) != 2 || len(sent[1]) != 1 || sent[1][0] != "C1-2.0" {
+		t.Errorf("Expected the request retried and only the rejected document sent again, got %d requests sending %v", requests, sent)
+	}
+}
+
+func TestMessageDocuments(t *testing.T) {
+	export := &models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C1", Name: "general"},
+		Users:   map[string]models.ExportUser{"U1": {ID: "U1", Name: "alice", Profile: models.ExportProfile{DisplayName: "Alice"}}},
+		Messages: []models.ExportMessage{
+			{ID: "1.0", User: "U1", Reactions: []models.ExportReaction{{Name: "tada", Count: 2}, {Name: "eyes", Count: 1}},
+				Replies: []models.ExportMessage{{ID: "2.0", User: "U1"}}},
+		},
+	}
+
+	docs := MessageDocuments(export)
+	if len(docs) != 2 || docs[0].UserName != "Alice" || docs[0].ReactionCount != 3 || !docs[1].IsReply {
+		t.Errorf("Unexpected documents %+v", docs)
+	}
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// Integrity check statuses reported by VerifyExport
+const (
+	IntegrityStatusOK       = "ok"
+	IntegrityStatusMismatch = "mismatch"
+	IntegrityStatusUnsigned = "unsigned"
+	IntegrityStatusInvalid  = "invalid"
+)
+
+// IntegrityCheck is the result of checking an export file against its
+// integrity footer
+type IntegrityCheck struct {
+	File             string `json:"file"`
+	Status           string `json:"status"`
+	Messages         int    `json:"messages"`
+	ExpectedMessages int    `json:"expected_messages,omitempty"`
+	Expected         string `json:"expected_sha256,omitempty"`
+	Actual           string `json:"actual_sha256,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// addIntegrity sets the integrity footer of exportData from its messages
+func addIntegrity(exportData *models.ChannelExport) error {
+	data, err := json.Marshal(exportData.Messages)
+	if err != nil {
+		return fmt.Errorf("failed to marshal messages: %w", err)
+	}
+	sum, err := messagesDigest(data)
+	if err != nil {
+		return err
+	}
+	exportData.Integrity = &models.ExportIntegrity{Messages: len(exportData.Messages), SHA256: sum}
+	return nil
+}
+
+// messagesDigest hashes the compact form of a JSON messages array, so the
+// digest does not depend on the output format
+func messagesDigest(raw []byte) (string, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifyExport checks a JSON export, optionally gzip-compressed, against its
+// integrity footer. Files that cannot be parsed, such as truncated ones, are
+// reported as invalid; the error is only set when the file cannot be read.
+func VerifyExport(path string) (*IntegrityCheck, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return &IntegrityCheck{File: path, Status: IntegrityStatusInvalid, Error: err.Error()}, nil
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return &IntegrityCheck{File: path, Status: IntegrityStatusInvalid, Error: err.Error()}, nil
+	}
+	return verifyExportData(path, data), nil
+}
+
+// ReadVerifiedExport reads an export like ReadExportFile, stdin included,
+// and checks it against its integrity footer. The input is read once, so the
+// check covers exactly the export returned.
+func ReadVerifiedExport(path string) (*models.ChannelExport, *IntegrityCheck, error) {
+	var in io.Reader = os.Stdin
+	name := "stdin"
+	if path != StdinInput {
+		name = path
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, nil, models.NewExportError(models.ErrorCategoryIO, "failed to open export", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, nil, models.NewExportError(models.ErrorCategoryIO, "failed to read export", err)
+	}
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read gzip export %s: %w", name, err)
+		}
+		defer gzipReader.Close()
+		if data, err = io.ReadAll(gzipReader); err != nil {
+			return nil, nil, fmt.Errorf("failed to read gzip export %s: %w", name, err)
+		}
+	}
+
+	export, err := ReadExport(bytes.NewReader(data), name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return export, verifyExportData(name, data), nil
+}
+
+// verifyExportData checks the decompressed JSON of an export named name
+func verifyExportData(name string, data []byte) *IntegrityCheck {
+	check := &IntegrityCheck{File: name}
+	var export struct {
+		Messages  json.RawMessage         `json:"messages"`
+		Integrity *models.ExportIntegrity `json:"integrity"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		check.Status, check.Error = IntegrityStatusInvalid, fmt.Sprintf("not a complete JSON export: %v", err)
+		return check
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(export.Messages, &messages); err != nil {
+		check.Status, check.Error = IntegrityStatusInvalid, fmt.Sprintf("invalid messages: %v", err)
+		return check
+	}
+	check.Messages = len(messages)
+	if export.Integrity == nil {
+		check.Status = IntegrityStatusUnsigned
+		return check
+	}
+
+	check.ExpectedMessages = export.Integrity.Messages
+	check.Expected = export.Integrity.SHA256
+	var err error
+	if check.Actual, err = messagesDigest(export.Messages); err != nil {
+		check.Status, check.Error = IntegrityStatusInvalid, err.Error()
+		return check
+	}
+	check.Status = IntegrityStatusOK
+	if check.Actual != check.Expected || check.Messages != check.ExpectedMessages {
+		check.Status = IntegrityStatusMismatch
+	}
+	return check
+}
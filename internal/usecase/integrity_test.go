@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestVerifyExport(t *testing.T) {
+	dir := t.TempDir()
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	export := func(name, format, compression string) string {
+		result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+			ChannelID:      "C123456",
+			ChannelName:    "general",
+			IncludeThreads: true,
+			OutputFile:     filepath.Join(dir, name),
+			Format:         format,
+			Compression:    compression,
+		}, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return result.OutputFile
+	}
+
+	pretty := export("pretty.json", "json-pretty", "")
+	compact := export("compact.json", "json-compact", "gzip")
+	var digests []string
+	for _, path := range []string{pretty, compact} {
+		check, err := VerifyExport(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if check.Status != IntegrityStatusOK || check.Messages != 2 || check.ExpectedMessages != 2 {
+			t.Errorf("%s: expected a valid export of 2 messages, got %+v", path, check)
+		}
+		digests = append(digests, check.Actual)
+	}
+	if digests[0] != digests[1] {
+		t.Errorf("Expected the digest not to depend on the format, got %v", digests)
+	}
+
+	data, err := os.ReadFile(pretty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(content string) *IntegrityCheck {
+		path := filepath.Join(dir, "changed.json")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		check, err := VerifyExport(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return check
+	}
+
+	if check := write(strings.Replace(string(data), "Hello everyone!", "Hello nobody!", 1)); check.Status != IntegrityStatusMismatch {
+		t.Errorf("Expected an edited message to be detected, got %+v", check)
+	}
+	if check := write(string(data[:len(data)/2])); check.Status != IntegrityStatusInvalid {
+		t.Errorf("Expected a truncated export to be invalid, got %+v", check)
+	}
+	unsigned := string(data[:strings.LastIndex(string(data), `,
+  "integrity"`)]) + "\n}"
+	if check := write(unsigned); check.Status != IntegrityStatusUnsigned || check.Messages != 2 {
+		t.Errorf("Expected an export without footer to be unsigned, got %+v", check)
+	}
+
+	if _, err := VerifyExport(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestReadVerifiedExport(t *testing.T) {
+	dir := t.TempDir()
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:   "C123456",
+		OutputFile:  filepath.Join(dir, "general.json"),
+		Format:      "json-compact",
+		Compression: "gzip",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, check, err := ReadVerifiedExport(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if check.Status != IntegrityStatusOK || len(export.Messages) != 2 {
+		t.Errorf("Expected a valid export of 2 messages, got %+v", check)
+	}
+
+	export.Messages[0].Text = "Hello nobody!"
+	data, err := marshalExport(*export, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := filepath.Join(dir, "changed.json")
+	if err := os.WriteFile(changed, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, check, err := ReadVerifiedExport(changed); err != nil || check.Status != IntegrityStatusMismatch {
+		t.Errorf("Expected an edited export to mismatch, got %+v, %v", check, err)
+	}
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// exportLimits enforces the --max-messages and --max-duration caps and the
+// stop signal of an export and records which one cut it short. A nil
+// *exportLimits imposes no limits.
+type exportLimits struct {
+	maxMessages int
+	maxDuration time.Duration
+	deadline    time.Time
+	stop        <-chan struct{}
+	truncation  *models.ExportTruncation
+
+	// cursor and pendingThreads record where an interrupted export stopped
+	cursor         string
+	pendingThreads []string
+}
+
+// newExportLimits returns the limits of options for an export that started
+// at start, or nil when options sets none
+func newExportLimits(options models.ExportOptions, start time.Time) *exportLimits {
+	if options.MaxMessages <= 0 && options.MaxDuration <= 0 && options.Stop == nil {
+		return nil
+	}
+	limits := &exportLimits{maxMessages: options.MaxMessages, maxDuration: options.MaxDuration, stop: options.Stop}
+	if options.MaxDuration > 0 {
+		limits.deadline = start.Add(options.MaxDuration)
+	}
+	return limits
+}
+
+// capMessages trims messages, newest first, to the message cap and reports
+// whether the cap was reached
+func (l *exportLimits) capMessages(messages []models.Message) ([]models.Message, bool) {
+	if l == nil || l.maxMessages <= 0 || len(messages) < l.maxMessages {
+		return messages, false
+	}
+	l.truncate(models.TruncatedByMaxMessages, strconv.Itoa(l.maxMessages))
+	return messages[:l.maxMessages], true
+}
+
+// expired reports whether the time budget is used up at now or the export
+// was stopped
+func (l *exportLimits) expired(now time.Time) bool {
+	if l == nil {
+		return false
+	}
+	select {
+	case <-l.stop:
+		l.truncate(models.TruncatedByInterrupt, "signal")
+		return true
+	default:
+	}
+	if l.deadline.IsZero() || now.Before(l.deadline) {
+		return false
+	}
+	l.truncate(models.TruncatedByMaxDuration, l.maxDuration.String())
+	return true
+}
+
+// truncate records the first limit that stopped the export
+func (l *exportLimits) truncate(reason, limit string) {
+	if l.truncation == nil {
+		l.truncation = &models.ExportTruncation{Reason: reason, Limit: limit}
+	}
+}
+
+// truncated reports whether a limit has stopped the export so far
+func (l *exportLimits) truncated() bool {
+	return l != nil && l.truncation != nil
+}
+
+// interrupted reports whether the stop signal ended the export
+func (l *exportLimits) interrupted() bool {
+	return l != nil && l.truncation != nil && l.truncation.Reason == models.TruncatedByInterrupt
+}
+
+// stopHistory records the history page an interrupted export stopped before
+func (l *exportLimits) stopHistory(cursor string) {
+	if l != nil {
+		l.cursor = cursor
+	}
+}
+
+// skipThreads records threads left without replies because time ran out or
+// the export was stopped
+func (l *exportLimits) skipThreads(threads []*models.Message) {
+	if l == nil || l.truncation == nil {
+		return
+	}
+	l.truncation.ThreadsSkipped += len(threads)
+	for _, msg := range threads {
+		l.pendingThreads = append(l.pendingThreads, msg.ThreadTS)
+	}
+}
+
+// result returns the truncation record, completed with the oldest exported
+// message, or nil when no limit was hit
+func (l *exportLimits) result(messages []models.Message) *models.ExportTruncation {
+	if l == nil || l.truncation == nil {
+		return nil
+	}
+	if len(messages) > 0 {
+		l.truncation.OldestMessage = messages[0].Timestamp
+	}
+	return l.truncation
+}
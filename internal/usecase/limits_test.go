@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_ExportChannelMaxMessages(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	options := models.ExportOptions{
+		ChannelID:   "C123456",
+		OutputFile:  filepath.Join(t.TempDir(), "general.json"),
+		Format:      "json",
+		MaxMessages: 1,
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Partial {
+		t.Errorf("Expected a truncated export not to be partial, got warnings %v", result.Warnings)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if len(export.Messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(export.Messages))
+	}
+	truncated := export.ExportInfo.Truncated
+	if truncated == nil {
+		t.Fatal("Expected truncation in metadata")
+	}
+	if truncated.Reason != models.TruncatedByMaxMessages || truncated.Limit != "1" {
+		t.Errorf("Expected max_messages limit 1, got %+v", truncated)
+	}
+	if truncated.OldestMessage != export.Messages[0].ID {
+		t.Errorf("Expected oldest message %s, got %s", export.Messages[0].ID, truncated.OldestMessage)
+	}
+}
+
+func TestExportService_ExportChannelMaxDuration(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+		MaxDuration:    time.Nanosecond,
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	// The history fits on one page; the thread is skipped once time is up
+	if len(export.Messages) != 2 {
+		t.Errorf("Expected 2 messages, got %d", len(export.Messages))
+	}
+	truncated := result.Truncated
+	if truncated == nil || truncated.Reason != models.TruncatedByMaxDuration {
+		t.Fatalf("Expected max_duration truncation, got %+v", truncated)
+	}
+	if truncated.ThreadsSkipped != 1 {
+		t.Errorf("Expected 1 skipped thread, got %d", truncated.ThreadsSkipped)
+	}
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// linkSnapshotTimeout bounds the fetch of one page or archive copy and
+// linkSnapshotDeadline all of them, so slow sites do not hold up the export.
+// Up to linkSnapshotWorkers URLs are fetched at once.
+const (
+	linkSnapshotTimeout  = 20 * time.Second
+	linkSnapshotDeadline = 2 * time.Minute
+	linkSnapshotWorkers  = 8
+)
+
+// LinkSnapshotClientInterface defines the page fetches needed for
+// ExportOptions.SnapshotLinks. Pages are fetched without the token.
+type LinkSnapshotClientInterface interface {
+	FetchLinkSnapshot(ctx context.Context, url string) (*models.ExportLinkSnapshot, error)
+	ArchivePage(ctx context.Context, requestURL string) (string, error)
+}
+
+// SetLinkSnapshotClient enables ExportOptions.SnapshotLinks
+func (s *ExportService) SetLinkSnapshotClient(client LinkSnapshotClientInterface) {
+	s.snapshotClient = client
+}
+
+// ArchiveRequestURL returns the request that asks service to archive
+// target: service with {url} replaced by target, or with target appended
+// when service has no {url}
+func ArchiveRequestURL(service, target string) string {
+	if strings.Contains(service, "{url}") {
+		return strings.ReplaceAll(service, "{url}", target)
+	}
+	return service + target
+}
+
+// ArchivesDomain reports whether URLs of host are archived when domains are
+// the domains archiving was enabled for. Subdomains are included.
+func ArchivesDomain(domains []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshotLinks stores a snapshot of every URL shared in messages and their
+// replies with the message. Each URL is fetched once, several at a time,
+// and archived when options.SnapshotService is set and its domain is one of
+// options.SnapshotArchiveDomains. URLs not fetched by the deadline, and pages
+// that cannot be fetched, keep a snapshot with the error, since broken links
+// are what the snapshots are for; they are not warnings.
+func (s *ExportService) snapshotLinks(ctx context.Context, messages []models.ExportMessage, options models.ExportOptions) error {
+	if s.snapshotClient == nil {
+		return fmt.Errorf("no link snapshot client configured")
+	}
+
+	var urls []string
+	seen := make(map[string]bool)
+	var collect func([]models.ExportMessage)
+	collect = func(messages []models.ExportMessage) {
+		for _, msg := range messages {
+			for _, link := range messageLinks(msg, nil) {
+				if !seen[link.URL] {
+					seen[link.URL] = true
+					urls = append(urls, link.URL)
+				}
+			}
+			collect(msg.Replies)
+		}
+	}
+	collect(messages)
+
+	deadlineCtx, cancel := context.WithTimeout(ctx, linkSnapshotDeadline)
+	defer cancel()
+	snapshots := make(map[string]models.ExportLinkSnapshot, len(urls))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+	for i := 0; i < min(linkSnapshotWorkers, len(urls)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range queue {
+				var snapshot models.ExportLinkSnapshot
+				if deadlineCtx.Err() != nil {
+					snapshot = models.ExportLinkSnapshot{URL: link, FetchedAt: time.Now().UTC(),
+						Error: fmt.Sprintf("not fetched within the %s snapshot deadline", linkSnapshotDeadline)}
+				} else {
+					snapshot = s.snapshotLink(deadlineCtx, link, options)
+				}
+				mu.Lock()
+				snapshots[link] = snapshot
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, link := range urls {
+		queue <- link
+	}
+	close(queue)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var attach func([]models.ExportMessage)
+	attach = func(messages []models.ExportMessage) {
+		for i := range messages {
+			msg := &messages[i]
+			msg.LinkSnapshots = nil
+			for _, link := range messageLinks(*msg, nil) {
+				msg.LinkSnapshots = append(msg.LinkSnapshots, snapshots[link.URL])
+			}
+			attach(msg.Replies)
+		}
+	}
+	attach(messages)
+	s.logger.Debug("snapshotted links", "count", len(snapshots))
+	return nil
+}
+
+// snapshotLink fetches one URL and archives it when options allow
+func (s *ExportService) snapshotLink(ctx context.Context, link string, options models.ExportOptions) models.ExportLinkSnapshot {
+	fetchCtx, cancel := context.WithTimeout(ctx, linkSnapshotTimeout)
+	fetched, err := s.snapshotClient.FetchLinkSnapshot(fetchCtx, link)
+	cancel()
+	snapshot := models.ExportLinkSnapshot{URL: link, FetchedAt: time.Now().UTC()}
+	if err != nil {
+		s.logger.Warn("link snapshot failed", "url", link, "error", err)
+		snapshot.Error = err.Error()
+	} else {
+		snapshot = *fetched
+	}
+
+	if options.SnapshotService != "" && archivable(link, options.SnapshotArchiveDomains) {
+		archiveCtx, cancel := context.WithTimeout(ctx, linkSnapshotTimeout)
+		archived, err := s.snapshotClient.ArchivePage(archiveCtx, ArchiveRequestURL(options.SnapshotService, link))
+		cancel()
+		if err != nil {
+			s.logger.Warn("link archive failed", "url", link, "error", err)
+			if snapshot.Error == "" {
+				snapshot.Error = err.Error()
+			}
+		} else {
+			snapshot.ArchiveURL = archived
+		}
+	}
+	return snapshot
+}
+
+// archivable reports whether link is on one of domains
+func archivable(link string, domains []string) bool {
+	parsed, err := url.Parse(link)
+	return err == nil && ArchivesDomain(domains, parsed.Hostname())
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockLinkSnapshotClient serves page snapshots and counts the fetches
+type MockLinkSnapshotClient struct {
+	mu       sync.Mutex
+	pages    map[string]models.ExportLinkSnapshot
+	fetches  map[string]int
+	archived []string
+}
+
+func (m *MockLinkSnapshotClient) FetchLinkSnapshot(ctx context.Context, url string) (*models.ExportLinkSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetches[url]++
+	page, ok := m.pages[url]
+	if !ok {
+		return nil, fmt.Errorf("page returned status 404")
+	}
+	page.URL = url
+	return &page, nil
+}
+
+func (m *MockLinkSnapshotClient) ArchivePage(ctx context.Context, requestURL string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.archived = append(m.archived, requestURL)
+	return "https://archive.example/copy/" + fmt.Sprint(len(m.archived)), nil
+}
+
+func TestExportService_SnapshotLinks(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	mockClient.messages[0].Text = "Notes: <https://example.com/notes|notes> and <https://docs.example.com/gone>"
+	mockClient.messages[1].Text = "Same notes again: https://example.com/notes, see https://intranet.example.net/plan"
+
+	service := NewExportService(mockClient, "1.0.0-test")
+	pages := &MockLinkSnapshotClient{
+		pages:   map[string]models.ExportLinkSnapshot{"https://example.com/notes": {Title: "Meeting notes"}},
+		fetches: make(map[string]int),
+	}
+	service.SetLinkSnapshotClient(pages)
+
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:       "C123456",
+		IncludeThreads:  true,
+		OutputFile:      filepath.Join(t.TempDir(), "general.json"),
+		Format:          "json",
+		SnapshotLinks:   true,
+		SnapshotService: "https://archive.example/save/{url}",
+		// Only example.com and its subdomains are sent to the archive
+		SnapshotArchiveDomains: []string{"example.com"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected broken links not to be warnings, got %v", result.Warnings)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	first := export.Messages[0].LinkSnapshots
+	if len(first) != 2 || first[0].Title != "Meeting notes" || first[0].ArchiveURL == "" {
+		t.Fatalf("Expected a titled and archived snapshot of the notes, got %+v", first)
+	}
+	if first[1].URL != "https://docs.example.com/gone" || first[1].Error == "" || first[1].FetchedAt.IsZero() {
+		t.Errorf("Expected the broken link to keep its error, got %+v", first[1])
+	}
+	if second := export.Messages[1].LinkSnapshots; len(second) != 2 || second[0].Title != "Meeting notes" || second[1].ArchiveURL != "" {
+		t.Errorf("Expected the repeated URL to reuse its snapshot, got %+v", second)
+	}
+	if pages.fetches["https://example.com/notes"] != 1 || len(pages.archived) != 2 {
+		t.Errorf("Expected each URL fetched and archived once, got %v and %v", pages.fetches, pages.archived)
+	}
+	if pages.archived[0] != "https://archive.example/save/https://example.com/notes" {
+		t.Errorf("Unexpected archive request %s", pages.archived[0])
+	}
+}
+
+func TestArchiveRequestURL(t *testing.T) {
+	if got := ArchiveRequestURL("https://web.archive.org/save/", "https://example.com"); got != "https://web.archive.org/save/https://example.com" {
+		t.Errorf("Expected the URL appended, got %s", got)
+	}
+	if got := ArchiveRequestURL("https://archive.example/?url={url}&wait=1", "https://example.com"); got != "https://archive.example/?url=https://example.com&wait=1" {
+		t.Errorf("Expected {url} replaced, got %s", got)
+	}
+}
+
+func TestArchivesDomain(t *testing.T) {
+	domains := []string{"example.com", " Docs.Example.org. "}
+	for host, want := range map[string]bool{
+		"example.com":      true,
+		"wiki.example.com": true,
+		"docs.example.org": true,
+		"example.org":      false,
+		"notexample.com":   false,
+		"intranet.local":   false,
+	} {
+		if got := ArchivesDomain(domains, host); got != want {
+			t.Errorf("ArchivesDomain(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
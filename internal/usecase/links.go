@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// bareLink matches URLs written without Slack's angle brackets, as in
+// messages imported from other tools
+var bareLink = regexp.MustCompile(`https?://[^\s<>|]+`)
+
+// ExtractLinks returns the http(s) URLs shared in messages and replies,
+// oldest first. A URL is listed once per message even if the message also
+// carries an unfurl of it.
+func ExtractLinks(messages []models.ExportMessage, users map[string]models.ExportUser) []models.ExportLink {
+	links := []models.ExportLink{}
+	var add func(msgs []models.ExportMessage)
+	add = func(msgs []models.ExportMessage) {
+		for _, msg := range msgs {
+			if !msg.InThread {
+				links = append(links, messageLinks(msg, users)...)
+			}
+			add(msg.Replies)
+		}
+	}
+	add(messages)
+
+	sort.SliceStable(links, func(a, b int) bool {
+		return links[a].Timestamp.Before(links[b].Timestamp)
+	})
+	return links
+}
+
+// messageLinks returns the links of a single message
+func messageLinks(msg models.ExportMessage, users map[string]models.ExportUser) []models.ExportLink {
+	var urls []string
+	urls = append(urls, textURLs(msg.Text)...)
+	for _, attachment := range msg.Attachments {
+		urls = append(urls, attachment.OriginalURL, attachment.FromURL)
+	}
+
+	reactions := 0
+	for _, reaction := range msg.Reactions {
+		reactions += reaction.Count
+	}
+	userName := ""
+	if _, ok := users[msg.User]; ok {
+		userName = userDisplayName(msg.User, users)
+	}
+
+	var links []models.ExportLink
+	seen := make(map[string]bool)
+	for _, raw := range urls {
+		parsed, err := url.Parse(raw)
+		if raw == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		links = append(links, models.ExportLink{
+			URL:       raw,
+			Domain:    strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www."),
+			User:      msg.User,
+			UserName:  userName,
+			MessageTS: msg.ID,
+			ThreadTS:  replyThreadTS(msg),
+			Timestamp: msg.Timestamp,
+			Reactions: reactions,
+			Permalink: msg.Permalink,
+		})
+	}
+	return links
+}
+
+// textURLs returns the URLs in Slack message text
+func textURLs(text string) []string {
+	var urls []string
+	for _, match := range slackLink.FindAllStringSubmatch(text, -1) {
+		if strings.HasPrefix(match[1], "http://") || strings.HasPrefix(match[1], "https://") {
+			urls = append(urls, unescapeSlack(match[1]))
+		}
+	}
+	for _, match := range bareLink.FindAllString(slackLink.ReplaceAllString(text, " "), -1) {
+		urls = append(urls, unescapeSlack(strings.TrimRight(match, `.,;:!?)'"*_~`)))
+	}
+	return urls
+}
+
+// unescapeSlack reverts the HTML escaping Slack applies to message text
+func unescapeSlack(text string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">").Replace(text)
+}
+
+// replyThreadTS returns the thread of a reply, or "" for top-level messages
+func replyThreadTS(msg models.ExportMessage) string {
+	if msg.ThreadTimestamp == msg.ID {
+		return ""
+	}
+	return msg.ThreadTimestamp
+}
+
+// WriteLinksCSV writes links as CSV with a header row
+func WriteLinksCSV(w io.Writer, links []models.ExportLink) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"url", "domain", "user", "user_name", "ts", "thread_ts", "timestamp", "reactions", "permalink"}); err != nil {
+		return err
+	}
+	for _, link := range links {
+		record := []string{
+			link.URL,
+			link.Domain,
+			link.User,
+			link.UserName,
+			link.MessageTS,
+			link.ThreadTS,
+			link.Timestamp.UTC().Format(time.RFC3339),
+			strconv.Itoa(link.Reactions),
+			link.Permalink,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExtractLinks(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	users := map[string]models.ExportUser{
+		"U1": {ID: "U1", Name: "alice", Profile: models.ExportProfile{DisplayName: "Alice"}},
+	}
+	messages := []models.ExportMessage{
+		{
+			ID:        "1705312800.000100",
+			User:      "U1",
+			Text:      "Docs: <https://www.Example.com/docs?a=1&amp;b=2|the docs> and <@U2> <#C1|general> <mailto:a@b.c|mail>",
+			Timestamp: base,
+			Reactions: []models.ExportReaction{{Name: "tada", Count: 2}, {Name: "+1", Count: 3}},
+			Attachments: []models.ExportAttachment{
+				{OriginalURL: "https://www.Example.com/docs?a=1&b=2"},
+			},
+			ThreadTimestamp: "1705312800.000100",
+			Replies: []models.ExportMessage{
+				{
+					ID:              "1705312900.000200",
+					User:            "U2",
+					Text:            "Also see https://go.dev/blog.",
+					Timestamp:       base.Add(100 * time.Second),
+					ThreadTimestamp: "1705312800.000100",
+				},
+			},
+		},
+		{
+			ID:        "1705312850.000300",
+			User:      "U2",
+			Text:      "no links here",
+			Timestamp: base.Add(50 * time.Second),
+		},
+		{
+			ID:        "1705312900.000200",
+			User:      "U2",
+			Text:      "Also see https://go.dev/blog.",
+			Timestamp: base.Add(100 * time.Second),
+			InThread:  true,
+		},
+	}
+
+	links := ExtractLinks(messages, users)
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links, got %d: %+v", len(links), links)
+	}
+
+	docs := links[0]
+	if docs.URL != "https://www.Example.com/docs?a=1&b=2" || docs.Domain != "example.com" {
+		t.Errorf("Unexpected first link: %+v", docs)
+	}
+	if docs.User != "U1" || docs.UserName != "Alice" || docs.Reactions != 5 || docs.MessageTS != "1705312800.000100" || docs.ThreadTS != "" {
+		t.Errorf("Unexpected first link details: %+v", docs)
+	}
+
+	reply := links[1]
+	if reply.URL != "https://go.dev/blog" || reply.ThreadTS != "1705312800.000100" || reply.UserName != "" {
+		t.Errorf("Unexpected reply link: %+v", reply)
+	}
+}
+
+func TestWriteLinksCSV(t *testing.T) {
+	links := []models.ExportLink{{
+		URL:       "https://example.com/a,b",
+		Domain:    "example.com",
+		User:      "U1",
+		UserName:  "Alice",
+		MessageTS: "1705312800.000100",
+		Timestamp: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		Reactions: 3,
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteLinksCSV(&buf, links); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || lines[0] != "url,domain,user,user_name,ts,thread_ts,timestamp,reactions,permalink" {
+		t.Fatalf("Unexpected CSV: %q", buf.String())
+	}
+	if lines[1] != `"https://example.com/a,b",example.com,U1,Alice,1705312800.000100,,2024-01-15T10:00:00Z,3,` {
+		t.Errorf("Unexpected CSV row: %q", lines[1])
+	}
+}
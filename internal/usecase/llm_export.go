@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// FormatLLMJSONL writes conversation chunks for retrieval and fine-tuning
+// pipelines, one JSON object per line
+const FormatLLMJSONL = "llm-jsonl"
+
+// defaultChunkTokens is the chunk size used when ExportOptions.ChunkTokens is unset
+const defaultChunkTokens = 1000
+
+// LLMChunk is one line of an llm-jsonl export: a run of consecutive messages,
+// or part of a single thread, rendered as a speaker-labelled transcript
+type LLMChunk struct {
+	ID            string    `json:"id"`
+	ChannelID     string    `json:"channel_id"`
+	Channel       string    `json:"channel"`
+	ThreadTS      string    `json:"thread_ts,omitempty"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Participants  []string  `json:"participants"`
+	MessageIDs    []string  `json:"message_ids"`
+	TokenEstimate int       `json:"token_estimate"`
+	Text          string    `json:"text"`
+}
+
+// llmLine is a rendered message of a chunk
+type llmLine struct {
+	id      string
+	speaker string
+	at      time.Time
+	header  string
+	text    string
+	tokens  int
+}
+
+// llmUnit is a message together with its thread replies. Units are packed
+// into chunks whole whenever they fit.
+type llmUnit struct {
+	threadTS string
+	lines    []llmLine
+}
+
+// minLLMPartRunes is the smallest part a long message is split into, for
+// chunk sizes too small to hold even its "[time] Speaker:" header
+const minLLMPartRunes = 64
+
+// estimateTokens approximates the token count of text at four characters
+// per token, the usual ratio for English text
+func estimateTokens(text string) int {
+	return (len([]rune(text)) + 3) / 4
+}
+
+// renderLLMChunks splits an export into chunks of at most maxTokens
+// estimated tokens. A thread is never mixed with channel messages it does not
+// fit next to; threads larger than a chunk are split, and every continuation
+// repeats the thread's first message for context when it fits. Messages
+// larger than a chunk are split into parts.
+func renderLLMChunks(exportData models.ChannelExport, maxTokens int, textOnly bool) ([]byte, error) {
+	if maxTokens <= 0 {
+		maxTokens = defaultChunkTokens
+	}
+
+	var units []llmUnit
+	for _, msg := range exportData.Messages {
+		if msg.InThread {
+			continue
+		}
+		// An omitted parent has no line but its replies still form a thread
+		var unit llmUnit
+		line, ok := renderLLMLine(msg, exportData, textOnly)
+		if ok {
+			unit.lines = append(unit.lines, splitLLMLine(line, maxTokens)...)
+		} else if !msg.Omitted {
+			continue
+		}
+		if len(msg.Replies) > 0 {
+			unit.threadTS = msg.ID
+			for _, reply := range msg.Replies {
+				if line, ok := renderLLMLine(reply, exportData, textOnly); ok {
+					unit.lines = append(unit.lines, splitLLMLine(line, maxTokens)...)
+				}
+			}
+		}
+		if len(unit.lines) == 0 {
+			continue
+		}
+		units = append(units, unit)
+	}
+
+	var chunks []LLMChunk
+	var current []llmLine
+	tokens := 0
+	// thread is set while the current chunk holds exactly one thread
+	thread := ""
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, newLLMChunk(exportData.Channel, len(chunks)+1, thread, current))
+		}
+		current, tokens, thread = nil, 0, ""
+	}
+	add := func(unit llmUnit, size int) {
+		if len(current) == 0 {
+			thread = unit.threadTS
+		} else {
+			thread = ""
+		}
+		current = append(current, unit.lines...)
+		tokens += size
+	}
+
+	for _, unit := range units {
+		size := 0
+		for _, line := range unit.lines {
+			size += line.tokens
+		}
+
+		switch {
+		case tokens+size <= maxTokens:
+			add(unit, size)
+		case size <= maxTokens:
+			flush()
+			add(unit, size)
+		default:
+			// Split a large thread or message into chunks of its own
+			flush()
+			parent := unit.lines[0]
+			thread = unit.threadTS
+			for _, line := range unit.lines {
+				if tokens+line.tokens > maxTokens && len(current) > 0 {
+					flush()
+					thread = unit.threadTS
+					if unit.threadTS != "" && line.id != parent.id && parent.tokens+line.tokens <= maxTokens {
+						current, tokens = []llmLine{parent}, parent.tokens
+					}
+				}
+				current = append(current, line)
+				tokens += line.tokens
+			}
+			flush()
+		}
+	}
+	flush()
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	for _, chunk := range chunks {
+		if err := encoder.Encode(chunk); err != nil {
+			return nil, fmt.Errorf("failed to marshal chunk %s: %w", chunk.ID, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// renderLLMLine renders a message as "[time] Speaker: text". Files,
+// attachments and reactions are appended in brackets unless textOnly is set.
+// Messages without any content are skipped.
+func renderLLMLine(msg models.ExportMessage, exportData models.ChannelExport, textOnly bool) (llmLine, bool) {
+	parts := []string{}
+	if text := strings.TrimSpace(plainText(msg.Text, exportData.Users, exportData.UserGroups)); text != "" {
+		parts = append(parts, text)
+	}
+	if !textOnly {
+		for _, file := range msg.Files {
+			parts = append(parts, fmt.Sprintf("[file: %s]", cmp.Or(file.Title, file.Name)))
+		}
+		for _, attachment := range msg.Attachments {
+			if title := cmp.Or(attachment.Title, attachment.Fallback); title != "" {
+				parts = append(parts, fmt.Sprintf("[attachment: %s]", plainText(title, exportData.Users, exportData.UserGroups)))
+			}
+		}
+		if len(msg.Reactions) > 0 {
+			var reactions []string
+			for _, reaction := range msg.Reactions {
+				reactions = append(reactions, fmt.Sprintf(":%s: %d", reaction.Name, reaction.Count))
+			}
+			parts = append(parts, fmt.Sprintf("[reactions: %s]", strings.Join(reactions, ", ")))
+		}
+	}
+	if len(parts) == 0 {
+		return llmLine{}, false
+	}
+
+	speaker := userDisplayName(msg.User, exportData.Users)
+	if msg.User == "" && msg.Workflow != nil && msg.Workflow.Name != "" {
+		speaker = msg.Workflow.Name
+	}
+	at := msg.Timestamp
+	header := fmt.Sprintf("[%s] %s: ", at.Format("2006-01-02 15:04"), speaker)
+	text := header + strings.Join(parts, " ")
+	return llmLine{id: msg.ID, speaker: speaker, at: msg.Timestamp, header: header, text: text, tokens: estimateTokens(text) + 1}, true
+}
+
+// splitLLMLine splits a line estimated above maxTokens into parts that fit a
+// chunk, breaking at whitespace where possible. Every part repeats the
+// header of the message and keeps its ID.
+func splitLLMLine(line llmLine, maxTokens int) []llmLine {
+	if line.tokens <= maxTokens {
+		return []llmLine{line}
+	}
+	// A part of n runes is estimated at (n+3)/4 tokens, plus one for its newline
+	size := max(4*(maxTokens-1)-len([]rune(line.header)), minLLMPartRunes)
+
+	var parts []llmLine
+	body := []rune(strings.TrimPrefix(line.text, line.header))
+	for len(body) > 0 {
+		n := min(size, len(body))
+		if n < len(body) {
+			for i := n - 1; i > n/2; i-- {
+				if unicode.IsSpace(body[i]) {
+					n = i + 1
+					break
+				}
+			}
+		}
+		text := strings.TrimSpace(string(body[:n]))
+		body = body[n:]
+		if text == "" {
+			continue
+		}
+		part := line
+		part.text = line.header + text
+		part.tokens = estimateTokens(part.text) + 1
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// newLLMChunk builds the chunk with sequence number n from lines
+func newLLMChunk(channel models.ChannelInfo, n int, threadTS string, lines []llmLine) LLMChunk {
+	chunk := LLMChunk{
+		ID:        fmt.Sprintf("%s-%04d", channel.ID, n),
+		ChannelID: channel.ID,
+		Channel:   channel.Name,
+		ThreadTS:  threadTS,
+		Start:     lines[0].at,
+		End:       lines[0].at,
+	}
+
+	seen := make(map[string]bool)
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line.at.Before(chunk.Start) {
+			chunk.Start = line.at
+		}
+		if line.at.After(chunk.End) {
+			chunk.End = line.at
+		}
+		if !seen[line.speaker] {
+			seen[line.speaker] = true
+			chunk.Participants = append(chunk.Participants, line.speaker)
+		}
+		// The parts of a split message are listed once
+		if n := len(chunk.MessageIDs); n == 0 || chunk.MessageIDs[n-1] != line.id {
+			chunk.MessageIDs = append(chunk.MessageIDs, line.id)
+		}
+		texts = append(texts, line.text)
+	}
+	sort.Strings(chunk.Participants)
+	chunk.Text = strings.Join(texts, "\n")
+	chunk.TokenEstimate = estimateTokens(chunk.Text)
+	return chunk
+}
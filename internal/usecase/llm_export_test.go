@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestRenderLLMChunks(t *testing.T) {
+	at := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	reply := func(id, user, text string) models.ExportMessage {
+		at = at.Add(time.Minute)
+		return models.ExportMessage{ID: id, User: user, Text: text, Timestamp: at}
+	}
+	exportData := models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C1", Name: "general"},
+		Users: map[string]models.ExportUser{
+			"U1": {ID: "U1", Profile: models.ExportProfile{DisplayName: "Alice"}},
+			"U2": {ID: "U2", Profile: models.ExportProfile{DisplayName: "Bob"}},
+		},
+		Messages: []models.ExportMessage{
+			reply("1.0", "U1", "morning <@U2>"),
+			{ID: "2.0", User: "U2", Text: "see chart", Timestamp: at.Add(time.Minute), Files: []models.ExportFile{{Name: "chart.png"}}},
+			{
+				ID: "3.0", User: "U1", Text: "incident review " + strings.Repeat("details ", 20), Timestamp: at.Add(2 * time.Minute),
+				Replies: []models.ExportMessage{
+					reply("3.1", "U2", strings.Repeat("root cause ", 20)),
+					reply("3.2", "U1", strings.Repeat("follow up ", 20)),
+				},
+			},
+		},
+	}
+
+	data, err := renderLLMChunks(exportData, 120, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	chunks := decodeChunks(t, data)
+
+	// The two short messages share a chunk; the thread is split in two, each
+	// continuation starting with the parent
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	if !strings.Contains(chunks[0].Text, "Alice: morning @Bob") || !strings.Contains(chunks[0].Text, "[file: chart.png]") {
+		t.Errorf("Expected speaker labels, resolved mentions and files, got %q", chunks[0].Text)
+	}
+	if chunks[0].ThreadTS != "" || strings.Join(chunks[0].Participants, ",") != "Alice,Bob" {
+		t.Errorf("Unexpected channel chunk metadata %+v", chunks[0])
+	}
+	for _, chunk := range chunks[1:] {
+		if chunk.ThreadTS != "3.0" || chunk.MessageIDs[0] != "3.0" {
+			t.Errorf("Expected thread chunks to start with the parent, got %+v", chunk)
+		}
+	}
+	if chunks[0].ID != "C1-0001" || chunks[2].ID != "C1-0003" {
+		t.Errorf("Expected sequential chunk IDs, got %s and %s", chunks[0].ID, chunks[2].ID)
+	}
+
+	data, err = renderLLMChunks(exportData, 0, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	chunks = decodeChunks(t, data)
+	if len(chunks) != 1 || strings.Contains(chunks[0].Text, "[file:") || chunks[0].ThreadTS != "" {
+		t.Errorf("Expected one text-only chunk with the default size, got %+v", chunks)
+	}
+}
+
+func decodeChunks(t *testing.T, data []byte) []LLMChunk {
+	t.Helper()
+	var chunks []LLMChunk
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var chunk LLMChunk
+		if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestRenderLLMChunksOmittedParent(t *testing.T) {
+	at := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	exportData := models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C1", Name: "general"},
+		Users:   map[string]models.ExportUser{"U1": {ID: "U1", Profile: models.ExportProfile{DisplayName: "Alice"}}},
+		Messages: []models.ExportMessage{{
+			ID: "1.0", Timestamp: at, Omitted: true, ReplyCount: 1,
+			Replies: []models.ExportMessage{{ID: "1.1", User: "U1", Text: "deploy looks good", Timestamp: at.Add(time.Minute)}},
+		}},
+	}
+
+	data, err := renderLLMChunks(exportData, 0, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	chunks := decodeChunks(t, data)
+	if len(chunks) != 1 || chunks[0].ThreadTS != "1.0" || strings.Join(chunks[0].MessageIDs, ",") != "1.1" {
+		t.Errorf("Expected the reply under its omitted parent, got %+v", chunks)
+	}
+}
+
+func TestRenderLLMChunksLongMessage(t *testing.T) {
+	at := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	exportData := models.ChannelExport{
+		Channel: models.ChannelInfo{ID: "C1", Name: "general"},
+		Users:   map[string]models.ExportUser{"U1": {ID: "U1", Profile: models.ExportProfile{DisplayName: "Alice"}}},
+		Messages: []models.ExportMessage{
+			{ID: "1.0", User: "U1", Text: strings.Repeat("postmortem ", 100), Timestamp: at},
+			{
+				ID: "2.0", User: "U1", Text: strings.Repeat("incident ", 100), Timestamp: at.Add(time.Minute),
+				Replies: []models.ExportMessage{{ID: "2.1", User: "U1", Text: "fixed", Timestamp: at.Add(2 * time.Minute)}},
+			},
+		},
+	}
+
+	data, err := renderLLMChunks(exportData, 100, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	chunks := decodeChunks(t, data)
+
+	words := 0
+	for _, chunk := range chunks {
+		if chunk.TokenEstimate > 100 {
+			t.Errorf("Expected chunks of at most 100 tokens, got %d in %s", chunk.TokenEstimate, chunk.ID)
+		}
+		if len(chunk.MessageIDs) != 1 && strings.Join(chunk.MessageIDs, ",") != "2.0,2.1" {
+			t.Errorf("Expected each part listed once, got %v", chunk.MessageIDs)
+		}
+		words += strings.Count(chunk.Text, "postmortem")
+	}
+	if words != 100 {
+		t.Errorf("Expected the split message to keep all 100 words, got %d", words)
+	}
+	last := chunks[len(chunks)-1]
+	if last.ThreadTS != "2.0" || !strings.Contains(last.Text, "Alice: fixed") {
+		t.Errorf("Expected the reply in the last thread chunk, got %+v", last)
+	}
+}
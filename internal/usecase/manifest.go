@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// Manifest check statuses reported by VerifyManifest
+const (
+	ManifestStatusOK       = "ok"
+	ManifestStatusMissing  = "missing"
+	ManifestStatusMismatch = "mismatch"
+)
+
+// ErrManifestUnsigned is returned when a manifest without an HMAC is verified
+// with a key
+var ErrManifestUnsigned = errors.New("manifest is not signed")
+
+// ManifestCheck is the verification result of one file listed in a manifest
+type ManifestCheck struct {
+	File     string `json:"file"`
+	Status   string `json:"status"`
+	Expected string `json:"expected_sha256"`
+	Actual   string `json:"actual_sha256,omitempty"`
+}
+
+// ManifestPath returns the manifest file written next to an export:
+// general.json and general.pdf become general.manifest.json
+func ManifestPath(outputFile string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(outputFile, ".json"), ".pdf") + ".manifest.json"
+}
+
+// writeManifest records the SHA-256 checksum of every produced file together
+// with the export options, version and warnings, signed with
+// options.ManifestKey when it is set
+func writeManifest(exportData models.ChannelExport, options models.ExportOptions, files []string, warnings []string) (string, error) {
+	manifestFile := ManifestPath(options.OutputFile)
+	dir := filepath.Dir(manifestFile)
+
+	manifest := models.ExportManifest{
+		CreatedAt:      time.Now(),
+		SlackerVersion: exportData.ExportInfo.SlackerVersion,
+		Channel:        exportData.Channel,
+		Options:        options,
+		Warnings:       warnings,
+	}
+	for _, file := range files {
+		sum, size, err := fileChecksum(file)
+		if err != nil {
+			return "", err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			rel = file
+		}
+		manifest.Files = append(manifest.Files, models.ManifestFile{
+			Path:   filepath.ToSlash(rel),
+			Size:   size,
+			SHA256: sum,
+		})
+	}
+
+	if len(options.ManifestKey) > 0 {
+		sum, err := manifestHMAC(manifest, options.ManifestKey)
+		if err != nil {
+			return "", err
+		}
+		manifest.HMAC = sum
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(manifestFile, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return manifestFile, nil
+}
+
+// ReadManifest loads a manifest written by an export
+func ReadManifest(path string) (*models.ExportManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var manifest models.ExportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("manifest %s lists no files", path)
+	}
+	return &manifest, nil
+}
+
+// VerifyManifest recomputes the checksums of the files listed in a manifest.
+// Paths are resolved relative to the manifest's directory. With a key, the
+// manifest must carry a matching HMAC, so checksums rewritten after a change
+// are caught; without one, a signature is not checked.
+func VerifyManifest(path string, key []byte) (*models.ExportManifest, []ManifestCheck, error) {
+	manifest, err := ReadManifest(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(key) > 0 {
+		if manifest.HMAC == "" {
+			return nil, nil, fmt.Errorf("%s: %w", path, ErrManifestUnsigned)
+		}
+		expected, err := manifestHMAC(*manifest, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !hmac.Equal([]byte(expected), []byte(manifest.HMAC)) {
+			return nil, nil, fmt.Errorf("%s: manifest signature does not match; it was modified or signed with another key", path)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	var checks []ManifestCheck
+	for _, file := range manifest.Files {
+		check := ManifestCheck{File: file.Path, Expected: file.SHA256}
+		sum, _, err := fileChecksum(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		switch {
+		case err != nil:
+			check.Status = ManifestStatusMissing
+		case sum != file.SHA256:
+			check.Status = ManifestStatusMismatch
+			check.Actual = sum
+		default:
+			check.Status = ManifestStatusOK
+			check.Actual = sum
+		}
+		checks = append(checks, check)
+	}
+	return manifest, checks, nil
+}
+
+// manifestHMAC returns the hex HMAC-SHA256 of manifest without its HMAC
+func manifestHMAC(manifest models.ExportManifest, key []byte) (string, error) {
+	manifest.HMAC = ""
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// fileChecksum returns the hex SHA-256 and size of a file
+func fileChecksum(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
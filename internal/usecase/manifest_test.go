@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_ManifestAndVerify(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	dir := t.TempDir()
+
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(dir, "general.json"),
+		Format:         "json",
+		Manifest:       true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := filepath.Join(dir, "general.manifest.json")
+	if result.Manifest != want {
+		t.Fatalf("Expected manifest %s, got %s", want, result.Manifest)
+	}
+
+	manifest, checks, err := VerifyManifest(result.Manifest, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if manifest.SlackerVersion != "1.0.0-test" || !manifest.Options.IncludeThreads {
+		t.Errorf("Expected version and options in manifest, got %+v", manifest)
+	}
+	if len(checks) != 1 || checks[0].File != "general.json" || checks[0].Status != ManifestStatusOK {
+		t.Fatalf("Expected general.json to verify, got %+v", checks)
+	}
+
+	if err := os.WriteFile(result.OutputFile, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, checks, _ = VerifyManifest(result.Manifest, nil); checks[0].Status != ManifestStatusMismatch {
+		t.Errorf("Expected mismatch after tampering, got %s", checks[0].Status)
+	}
+
+	os.Remove(result.OutputFile)
+	if _, checks, _ = VerifyManifest(result.Manifest, nil); checks[0].Status != ManifestStatusMissing {
+		t.Errorf("Expected missing file, got %s", checks[0].Status)
+	}
+}
+
+func TestExportService_SignedManifest(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	dir := t.TempDir()
+	key := []byte("archive-key")
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:   "C123456",
+		DateFrom:    &from,
+		OutputFile:  filepath.Join(dir, "general.json"),
+		Format:      "json",
+		Manifest:    true,
+		ManifestKey: key,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	manifest, checks, err := VerifyManifest(result.Manifest, key)
+	if err != nil {
+		t.Fatalf("Expected the signed manifest to verify, got %v", err)
+	}
+	if manifest.HMAC == "" || len(checks) != 1 || checks[0].Status != ManifestStatusOK {
+		t.Fatalf("Expected a signed manifest with a verified file, got %+v %+v", manifest, checks)
+	}
+	if _, _, err := VerifyManifest(result.Manifest, []byte("other-key")); err == nil {
+		t.Error("Expected a different key to fail")
+	}
+
+	// Rewriting a checksum after a change breaks the signature
+	manifest.Files[0].SHA256 = strings.Repeat("0", 64)
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(result.Manifest, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyManifest(result.Manifest, key); err == nil {
+		t.Error("Expected a modified manifest to fail")
+	}
+
+	manifest.HMAC = ""
+	if data, err = json.Marshal(manifest); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(result.Manifest, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyManifest(result.Manifest, key); !errors.Is(err, ErrManifestUnsigned) {
+		t.Errorf("Expected an unsigned manifest to fail with a key, got %v", err)
+	}
+}
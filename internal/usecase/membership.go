@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// Views of a MembershipReport
+const (
+	MembershipByUser    = "users"
+	MembershipByChannel = "channels"
+	MembershipMatrix    = "matrix"
+)
+
+// MembershipOptions selects the channels of a membership report
+type MembershipOptions struct {
+	// Channels limits the report to these channel names; empty = all
+	// channels the token is a member of
+	Channels []string
+	// IncludeArchived also reports archived channels
+	IncludeArchived bool
+}
+
+// MembershipReport lists who is a member of which channel, for access
+// reviews
+type MembershipReport struct {
+	Channels []MembershipChannel `json:"channels"`
+	Users    []MembershipUser    `json:"users"`
+	// Failed are the channels whose members could not be read
+	Failed []MembershipChannel `json:"failed,omitempty"`
+}
+
+// MembershipChannel is a channel with its member IDs
+type MembershipChannel struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	IsPrivate  bool     `json:"is_private,omitempty"`
+	IsArchived bool     `json:"is_archived,omitempty"`
+	Members    []string `json:"members"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// MembershipUser is a member of at least one reported channel with the
+// names of its channels
+type MembershipUser struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name,omitempty"`
+	RealName string   `json:"real_name,omitempty"`
+	IsBot    bool     `json:"is_bot,omitempty"`
+	Deleted  bool     `json:"deleted,omitempty"`
+	Channels []string `json:"channels"`
+}
+
+// Membership reads the members of every selected channel with
+// conversations.members. Channels are sorted by name, and users by name with
+// their channels in channel order. A channel whose members cannot be read is
+// reported in Failed and does not stop the report.
+func (s *ReportService) Membership(ctx context.Context, opts MembershipOptions, progress func(done, total int)) (*MembershipReport, error) {
+	channels, err := s.client.GetChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(opts.Channels))
+	for _, name := range opts.Channels {
+		wanted[strings.TrimPrefix(strings.TrimSpace(name), "#")] = true
+	}
+	var selected []models.Channel
+	for _, channel := range channels {
+		if channel.IsIM || len(wanted) > 0 && !wanted[channel.Name] || len(wanted) == 0 && channel.IsArchived && !opts.IncludeArchived {
+			continue
+		}
+		delete(wanted, channel.Name)
+		selected = append(selected, channel)
+	}
+	for _, name := range opts.Channels {
+		if name = strings.TrimPrefix(strings.TrimSpace(name), "#"); wanted[name] {
+			return nil, models.NewChannelNotFoundError(channels, name, "")
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+
+	users, err := s.client.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]models.User, len(users))
+	for _, user := range users {
+		known[user.ID] = user
+	}
+
+	report := &MembershipReport{Channels: []MembershipChannel{}, Users: []MembershipUser{}}
+	byUser := make(map[string]*MembershipUser)
+	for i, channel := range selected {
+		entry := MembershipChannel{
+			ID:         channel.ID,
+			Name:       channel.Name,
+			IsPrivate:  channel.IsPrivate,
+			IsArchived: channel.IsArchived,
+		}
+		members, err := s.client.GetChannelMembers(ctx, channel.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			s.logger.Warn("channel members unavailable", "channel_id", channel.ID, "error", err)
+			entry.Error = err.Error()
+			report.Failed = append(report.Failed, entry)
+		} else {
+			entry.Members = members
+			report.Channels = append(report.Channels, entry)
+			for _, id := range members {
+				user, ok := byUser[id]
+				if !ok {
+					info := known[id]
+					user = &MembershipUser{ID: id, Name: info.Name, RealName: info.RealName, IsBot: info.IsBot, Deleted: info.Deleted}
+					byUser[id] = user
+				}
+				user.Channels = append(user.Channels, channel.Name)
+			}
+		}
+		if progress != nil {
+			progress(i+1, len(selected))
+		}
+	}
+
+	for _, user := range byUser {
+		report.Users = append(report.Users, *user)
+	}
+	sort.Slice(report.Users, func(i, j int) bool {
+		a, b := report.Users[i], report.Users[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+	return report, nil
+}
+
+// WriteMembershipCSV writes a membership report as CSV with a header row:
+// one row per user with their channels, one row per channel with its
+// members, or a matrix with a column per channel
+func WriteMembershipCSV(w io.Writer, report *MembershipReport, view string) error {
+	writer := csv.NewWriter(w)
+	names := make(map[string]string, len(report.Users))
+	for _, user := range report.Users {
+		names[user.ID] = user.Name
+	}
+
+	var rows [][]string
+	switch view {
+	case MembershipByChannel:
+		rows = append(rows, []string{"channel_id", "channel", "private", "archived", "members", "member_ids", "member_names"})
+		for _, channel := range report.Channels {
+			memberNames := make([]string, len(channel.Members))
+			for i, id := range channel.Members {
+				memberNames[i] = names[id]
+			}
+			rows = append(rows, []string{
+				channel.ID,
+				channel.Name,
+				strconv.FormatBool(channel.IsPrivate),
+				strconv.FormatBool(channel.IsArchived),
+				strconv.Itoa(len(channel.Members)),
+				strings.Join(channel.Members, ";"),
+				strings.Join(memberNames, ";"),
+			})
+		}
+	case MembershipMatrix:
+		header := []string{"user_id", "name", "real_name", "bot", "deleted"}
+		for _, channel := range report.Channels {
+			header = append(header, channel.Name)
+		}
+		rows = append(rows, header)
+		for _, user := range report.Users {
+			member := make(map[string]bool, len(user.Channels))
+			for _, name := range user.Channels {
+				member[name] = true
+			}
+			row := []string{user.ID, user.Name, user.RealName, strconv.FormatBool(user.IsBot), strconv.FormatBool(user.Deleted)}
+			for _, channel := range report.Channels {
+				cell := ""
+				if member[channel.Name] {
+					cell = "1"
+				}
+				row = append(row, cell)
+			}
+			rows = append(rows, row)
+		}
+	default:
+		rows = append(rows, []string{"user_id", "name", "real_name", "bot", "deleted", "channel_count", "channels"})
+		for _, user := range report.Users {
+			rows = append(rows, []string{
+				user.ID,
+				user.Name,
+				user.RealName,
+				strconv.FormatBool(user.IsBot),
+				strconv.FormatBool(user.Deleted),
+				strconv.Itoa(len(user.Channels)),
+				strings.Join(user.Channels, ";"),
+			})
+		}
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
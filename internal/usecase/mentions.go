@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// Slack markup for mentions: <@U123>, <#C123|general> and <!here>,
+// <!channel> or <!everyone>, each optionally with a |label
+var (
+	userMention      = regexp.MustCompile(`<@([UW][A-Z0-9]+)(?:\|[^>]*)?>`)
+	channelReference = regexp.MustCompile(`<#(C[A-Z0-9]+)(?:\|[^>]*)?>`)
+	broadcastMention = regexp.MustCompile(`<!(here|channel|everyone)(?:\|[^>]*)?>`)
+)
+
+// mentionCounter adds up the mentions in message texts
+type mentionCounter struct {
+	stats models.MentionStats
+	edges map[[2]string]int
+}
+
+func newMentionCounter() *mentionCounter {
+	return &mentionCounter{
+		stats: models.MentionStats{
+			ByUser:   make(map[string]models.UserMentionStats),
+			Channels: make(map[string]int),
+		},
+		edges: make(map[[2]string]int),
+	}
+}
+
+// add counts the mentions in text, written by author
+func (c *mentionCounter) add(author, text string) {
+	if !strings.Contains(text, "<") {
+		return
+	}
+	authored := c.stats.ByUser[author]
+
+	for _, match := range userMention.FindAllStringSubmatch(text, -1) {
+		mentioned := match[1]
+		c.stats.UserMentions++
+		authored.Mentions++
+		if mentioned == author {
+			continue
+		}
+		target := c.stats.ByUser[mentioned]
+		target.MentionedBy++
+		c.stats.ByUser[mentioned] = target
+		if author != "" {
+			c.edges[[2]string{author, mentioned}]++
+		}
+	}
+	for _, match := range channelReference.FindAllStringSubmatch(text, -1) {
+		c.stats.ChannelReferences++
+		authored.ChannelReferences++
+		c.stats.Channels[match[1]]++
+	}
+	broadcasts := len(broadcastMention.FindAllStringIndex(text, -1))
+	c.stats.Broadcasts += broadcasts
+	authored.Broadcasts += broadcasts
+
+	if author != "" && authored != (models.UserMentionStats{}) {
+		c.stats.ByUser[author] = authored
+	}
+}
+
+// result returns the counted mentions, or nil when there were none
+func (c *mentionCounter) result() *models.MentionStats {
+	stats := c.stats
+	if stats.UserMentions == 0 && stats.ChannelReferences == 0 && stats.Broadcasts == 0 {
+		return nil
+	}
+	if len(stats.Channels) == 0 {
+		stats.Channels = nil
+	}
+	for edge, count := range c.edges {
+		stats.Graph = append(stats.Graph, models.MentionEdge{From: edge[0], To: edge[1], Count: count})
+	}
+	sort.Slice(stats.Graph, func(i, j int) bool {
+		a, b := stats.Graph[i], stats.Graph[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return &stats
+}
+
+// ApplyMentionStatistics recomputes the mention statistics of export from
+// its messages and thread replies, e.g. for exports written before slacker
+// recorded them
+func ApplyMentionStatistics(export *models.ChannelExport) {
+	counter := newMentionCounter()
+	var count func([]models.ExportMessage)
+	count = func(messages []models.ExportMessage) {
+		for _, msg := range messages {
+			// Broadcast links are counted with their thread
+			if msg.InThread {
+				continue
+			}
+			counter.add(msg.User, msg.Text)
+			count(msg.Replies)
+		}
+	}
+	count(export.Messages)
+	export.Statistics.Mentions = counter.result()
+}
+
+// WriteMentionGraph writes the mention graph of stats in Graphviz DOT
+// format. Users are labeled with their names from users where known; edge
+// labels and widths follow the number of mentions.
+func WriteMentionGraph(w io.Writer, stats *models.MentionStats, users map[string]models.ExportUser) error {
+	var b strings.Builder
+	b.WriteString("digraph mentions {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+
+	if stats != nil {
+		nodes := make(map[string]bool)
+		for _, edge := range stats.Graph {
+			nodes[edge.From], nodes[edge.To] = true, true
+		}
+		ids := make([]string, 0, len(nodes))
+		for id := range nodes {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			label := id
+			if user, ok := users[id]; ok && user.Name != "" {
+				label = "@" + user.Name
+			}
+			fmt.Fprintf(&b, "  %s [label=%s];\n", dotQuote(id), dotQuote(label))
+		}
+
+		most := 1
+		for _, edge := range stats.Graph {
+			if edge.Count > most {
+				most = edge.Count
+			}
+		}
+		for _, edge := range stats.Graph {
+			width := 1 + 4*float64(edge.Count-1)/float64(most)
+			fmt.Fprintf(&b, "  %s -> %s [label=\"%d\", penwidth=%.1f];\n", dotQuote(edge.From), dotQuote(edge.To), edge.Count, width)
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes s as a DOT identifier
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
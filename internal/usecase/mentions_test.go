@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_calculateMentionStatistics(t *testing.T) {
+	service := NewExportService(nil, "1.0.0-test")
+	messages := []models.Message{
+		{User: "U1", Timestamp: "1704067200.000000", Text: "<@U2> <@U3|carol> see <#C9|deploys> <!here>", Thread: []models.Message{
+			{User: "U2", Timestamp: "1704067260.000000", Text: "thanks <@U1>, and <@U1> again"},
+		}},
+		{User: "U3", Timestamp: "1704067300.000000", Text: "note to self <@U3> <!channel>"},
+		{User: "U2", Timestamp: "1704067400.000000", Text: "no markup here"},
+	}
+
+	mentions := service.calculateStatistics(messages, nil, time.UTC).Mentions
+	if mentions == nil {
+		t.Fatal("Expected mention statistics")
+	}
+	if mentions.UserMentions != 5 || mentions.ChannelReferences != 1 || mentions.Broadcasts != 2 {
+		t.Errorf("Expected 5 user mentions, 1 channel reference and 2 broadcasts, got %+v", mentions)
+	}
+	if mentions.Channels["C9"] != 1 {
+		t.Errorf("Expected a reference to C9, got %v", mentions.Channels)
+	}
+	if got := mentions.ByUser["U1"]; got.Mentions != 2 || got.MentionedBy != 2 || got.Broadcasts != 1 {
+		t.Errorf("Unexpected mentions of U1: %+v", got)
+	}
+	// Self-mentions are counted but do not make U3 mentioned by others
+	if got := mentions.ByUser["U3"]; got.Mentions != 1 || got.MentionedBy != 1 {
+		t.Errorf("Unexpected mentions of U3: %+v", got)
+	}
+	want := []models.MentionEdge{{From: "U2", To: "U1", Count: 2}, {From: "U1", To: "U2", Count: 1}, {From: "U1", To: "U3", Count: 1}}
+	if len(mentions.Graph) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, mentions.Graph)
+	}
+	for i := range want {
+		if mentions.Graph[i] != want[i] {
+			t.Errorf("Expected %v at %d, got %v", want[i], i, mentions.Graph[i])
+		}
+	}
+
+	if stats := service.calculateStatistics(messages[2:], nil, time.UTC); stats.Mentions != nil {
+		t.Errorf("Expected no mention statistics without mentions, got %+v", stats.Mentions)
+	}
+}
+
+func TestWriteMentionGraph(t *testing.T) {
+	export := &models.ChannelExport{
+		Messages: []models.ExportMessage{{User: "U1", Text: "<@U2> <@U2>"}, {User: "U2", Text: "<@U1>"}},
+		Users:    map[string]models.ExportUser{"U1": {ID: "U1", Name: "alice"}},
+	}
+	ApplyMentionStatistics(export)
+
+	var out bytes.Buffer
+	if err := WriteMentionGraph(&out, export.Statistics.Mentions, export.Users); err != nil {
+		t.Fatal(err)
+	}
+	dot := out.String()
+	for _, want := range []string{"digraph mentions {", `"U1" [label="@alice"];`, `"U2" [label="U2"];`, `"U1" -> "U2" [label="2"`, `"U2" -> "U1" [label="1"`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected %q in\n%s", want, dot)
+		}
+	}
+}
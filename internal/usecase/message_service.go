@@ -3,21 +3,51 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
-	"github.com/itcaat/slacker/internal/api"
 	"github.com/itcaat/slacker/models"
 )
 
+// MessageClientInterface defines the operations the message service needs.
+// It is implemented by the Slack API client and by the local message store.
+type MessageClientInterface interface {
+	SlackClientInterface
+	GetChannelByName(ctx context.Context, channelName string) (*models.Channel, error)
+}
+
 // MessageService handles message-related business logic
 type MessageService struct {
-	slackClient *api.SlackClient
+	slackClient  MessageClientInterface
+	logger       *slog.Logger
+	pageSize     int
+	requestDelay time.Duration
 }
 
 // NewMessageService creates a new message service
-func NewMessageService(slackClient *api.SlackClient) *MessageService {
+func NewMessageService(slackClient MessageClientInterface) *MessageService {
 	return &MessageService{
-		slackClient: slackClient,
+		slackClient:  slackClient,
+		logger:       slog.Default(),
+		pageSize:     200,
+		requestDelay: 50 * time.Millisecond,
+	}
+}
+
+// SetLogger sets the logger used for message retrieval diagnostics
+func (ms *MessageService) SetLogger(logger *slog.Logger) {
+	ms.logger = logger
+}
+
+// SetPaging sets the history page size and the pause between paginated and
+// thread requests. Zero values keep the defaults and models.NoThreadDelay
+// turns the pause off.
+func (ms *MessageService) SetPaging(pageSize int, requestDelay time.Duration) {
+	if pageSize > 0 {
+		ms.pageSize = pageSize
+	}
+	if requestDelay != 0 {
+		ms.requestDelay = max(requestDelay, 0)
 	}
 }
 
@@ -38,6 +68,9 @@ type MessageResult struct {
 	Users    map[string]models.User
 	Channel  *models.Channel
 	Count    int
+	// Warnings lists the thread replies that could not be fetched; the
+	// caller decides how to show them
+	Warnings []string
 }
 
 // GetChannelMessages retrieves messages from a channel with the specified options
@@ -67,7 +100,7 @@ func (ms *MessageService) GetChannelMessages(ctx context.Context, opts MessageRe
 		}
 
 		if channel == nil {
-			return nil, fmt.Errorf("channel with ID '%s' not found", opts.ChannelID)
+			return nil, models.NewExportError(models.ErrorCategoryChannelNotFound, fmt.Sprintf("channel with ID '%s' not found", opts.ChannelID), nil)
 		}
 	} else {
 		return nil, fmt.Errorf("either channel name or channel ID must be provided")
@@ -85,11 +118,9 @@ func (ms *MessageService) GetChannelMessages(ctx context.Context, opts MessageRe
 	}
 
 	// Enrich with thread replies if requested
+	var warnings []string
 	if opts.IncludeThreads {
-		messages, err = ms.enrichWithThreadReplies(ctx, channel.ID, messages)
-		if err != nil {
-			return nil, fmt.Errorf("failed to retrieve thread replies: %w", err)
-		}
+		warnings = ms.enrichWithThreadReplies(ctx, channel.ID, messages)
 	}
 
 	// Get user information if requested
@@ -111,6 +142,7 @@ func (ms *MessageService) GetChannelMessages(ctx context.Context, opts MessageRe
 		Users:    userMap,
 		Channel:  channel,
 		Count:    len(messages),
+		Warnings: warnings,
 	}, nil
 }
 
@@ -121,30 +153,27 @@ func (ms *MessageService) GetAllChannelHistory(ctx context.Context, channelID st
 	cursor := ""
 
 	for {
-		messages, nextCursor, err := ms.slackClient.GetChannelHistory(ctx, channelID, 200, cursor)
+		page, err := ms.slackClient.GetChannelHistory(ctx, channelID, ms.pageSize, cursor)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get channel history: %w", err)
 		}
 
-		allMessages = append(allMessages, messages...)
+		allMessages = append(allMessages, page.Messages...)
 
-		if nextCursor == "" || len(messages) == 0 {
+		if page.Last() || len(page.Messages) == 0 {
 			break
 		}
 
-		cursor = nextCursor
+		cursor = page.NextCursor
 
 		// Add a small delay to respect rate limits
-		time.Sleep(100 * time.Millisecond)
+		time.Sleep(ms.requestDelay)
 	}
 
 	// Enrich with thread replies if requested
+	var warnings []string
 	if includeThreads {
-		var err error
-		allMessages, err = ms.enrichWithThreadReplies(ctx, channelID, allMessages)
-		if err != nil {
-			return nil, fmt.Errorf("failed to retrieve thread replies: %w", err)
-		}
+		warnings = ms.enrichWithThreadReplies(ctx, channelID, allMessages)
 	}
 
 	// Get user information
@@ -177,6 +206,7 @@ func (ms *MessageService) GetAllChannelHistory(ctx context.Context, channelID st
 		Users:    userMap,
 		Channel:  channel,
 		Count:    len(allMessages),
+		Warnings: warnings,
 	}, nil
 }
 
@@ -187,26 +217,26 @@ func (ms *MessageService) retrieveMessagesWithPagination(ctx context.Context, ch
 	remaining := limit
 
 	for remaining > 0 {
-		// Calculate batch size (max 200 per API call)
+		// Calculate batch size (at most one page per API call)
 		batchSize := remaining
-		if batchSize > 200 {
-			batchSize = 200
+		if batchSize > ms.pageSize {
+			batchSize = ms.pageSize
 		}
 
-		messages, nextCursor, err := ms.slackClient.GetChannelHistory(ctx, channelID, batchSize, cursor)
+		page, err := ms.slackClient.GetChannelHistory(ctx, channelID, batchSize, cursor)
 		if err != nil {
 			return nil, err
 		}
 
 		// Filter messages by time range if specified
-		filteredMessages := ms.filterMessagesByTime(messages, before, after)
+		filteredMessages := ms.filterMessagesByTime(page.Messages, before, after)
 		allMessages = append(allMessages, filteredMessages...)
 
 		remaining -= len(filteredMessages)
-		cursor = nextCursor
+		cursor = page.NextCursor
 
 		// Stop if no more messages or no cursor for next page
-		if nextCursor == "" || len(messages) == 0 {
+		if page.Last() || len(page.Messages) == 0 {
 			break
 		}
 
@@ -216,7 +246,7 @@ func (ms *MessageService) retrieveMessagesWithPagination(ctx context.Context, ch
 		}
 
 		// Add a small delay to respect rate limits
-		time.Sleep(50 * time.Millisecond)
+		time.Sleep(ms.requestDelay)
 	}
 
 	// Trim to exact limit if we got more than requested
@@ -228,13 +258,16 @@ func (ms *MessageService) retrieveMessagesWithPagination(ctx context.Context, ch
 }
 
 // enrichWithThreadReplies fetches thread replies for messages that have them
-func (ms *MessageService) enrichWithThreadReplies(ctx context.Context, channelID string, messages []models.Message) ([]models.Message, error) {
+// and returns a warning for each thread whose replies could not be fetched
+func (ms *MessageService) enrichWithThreadReplies(ctx context.Context, channelID string, messages []models.Message) []string {
+	var warnings []string
 	for i, msg := range messages {
 		if msg.ReplyCount > 0 && msg.ThreadTS != "" {
 			replies, err := ms.slackClient.GetThreadReplies(ctx, channelID, msg.ThreadTS)
 			if err != nil {
-				// Log error but continue with other messages
-				fmt.Printf("Warning: Failed to get replies for message %s: %v\n", msg.Timestamp, err)
+				// Record a warning but continue with other messages
+				ms.logger.Debug("failed to get thread replies", "channel_id", channelID, "ts", msg.Timestamp, "error", err)
+				warnings = append(warnings, fmt.Sprintf("thread %s: replies unavailable: %v", msg.ThreadTS, err))
 				continue
 			}
 			messages[i].Thread = replies
@@ -242,10 +275,10 @@ func (ms *MessageService) enrichWithThreadReplies(ctx context.Context, channelID
 
 		// Add a small delay between thread requests to respect rate limits
 		if msg.ReplyCount > 0 {
-			time.Sleep(50 * time.Millisecond)
+			time.Sleep(ms.requestDelay)
 		}
 	}
-	return messages, nil
+	return warnings
 }
 
 // filterMessagesByTime filters messages based on before/after timestamps
@@ -292,15 +325,17 @@ func (ms *MessageService) filterMessagesByTime(messages []models.Message, before
 // GetChannelStats returns statistics about a channel's messages
 func (ms *MessageService) GetChannelStats(ctx context.Context, channelID string) (*ChannelStats, error) {
 	// Get a sample of recent messages to calculate stats
-	messages, _, err := ms.slackClient.GetChannelHistory(ctx, channelID, 100, "")
+	page, err := ms.slackClient.GetChannelHistory(ctx, channelID, 100, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get channel history for stats: %w", err)
 	}
+	messages := page.Messages
 
 	stats := &ChannelStats{
 		ChannelID:    channelID,
 		SampleSize:   len(messages),
-		MessageCount: len(messages), // This is just a sample, not total
+		MessageCount: len(messages), // The total only when the sample is complete
+		Complete:     !page.HasMore,
 	}
 
 	// Calculate basic statistics
@@ -337,4 +372,6 @@ type ChannelStats struct {
 	UniqueUsers    int    `json:"unique_users"`
 	ThreadCount    int    `json:"thread_count"`
 	MostActiveUser string `json:"most_active_user"`
+	// Complete is set when the sample holds the channel's whole history
+	Complete bool `json:"complete"`
 }
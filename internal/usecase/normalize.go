@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"sort"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// normalizeMessages removes duplicate messages and orders everything
+// chronologically. Duplicates are messages repeated at page boundaries,
+// replies listed twice in a thread, and thread replies that also appear at
+// top level, which are kept only inside their thread. The channel copy of an
+// "also send to channel" broadcast is replaced by a link to the reply (see
+// broadcastLink). It returns the number of messages removed.
+func normalizeMessages(messages []models.Message) ([]models.Message, int) {
+	removed := 0
+
+	// Deduplicate top-level messages, keeping the copy with thread replies
+	index := make(map[string]int, len(messages))
+	var unique []models.Message
+	for _, msg := range messages {
+		if i, ok := index[msg.Timestamp]; ok {
+			if len(unique[i].Thread) == 0 && len(msg.Thread) > 0 {
+				unique[i] = msg
+			}
+			removed++
+			continue
+		}
+		index[msg.Timestamp] = len(unique)
+		unique = append(unique, msg)
+	}
+
+	// Deduplicate and order replies, remembering which ones live in a thread
+	inThread := make(map[string]bool)
+	for i := range unique {
+		if len(unique[i].Thread) == 0 {
+			continue
+		}
+		seen := make(map[string]bool, len(unique[i].Thread))
+		var replies []models.Message
+		for _, reply := range unique[i].Thread {
+			if seen[reply.Timestamp] || reply.Timestamp == unique[i].Timestamp {
+				removed++
+				continue
+			}
+			seen[reply.Timestamp] = true
+			inThread[reply.Timestamp] = true
+			replies = append(replies, reply)
+		}
+		sort.SliceStable(replies, func(a, b int) bool {
+			return replies[a].Timestamp < replies[b].Timestamp
+		})
+		unique[i].Thread = replies
+	}
+
+	// Drop top-level copies of replies that are already nested
+	normalized := unique[:0]
+	for _, msg := range unique {
+		if msg.ThreadTS != "" && msg.ThreadTS != msg.Timestamp && inThread[msg.Timestamp] {
+			if msg.Subtype == models.SubtypeThreadBroadcast {
+				normalized = append(normalized, broadcastLink(msg))
+				continue
+			}
+			removed++
+			continue
+		}
+		normalized = append(normalized, msg)
+	}
+
+	sort.SliceStable(normalized, func(a, b int) bool {
+		return normalized[a].Timestamp < normalized[b].Timestamp
+	})
+
+	return normalized, removed
+}
+
+// FlattenReplies moves thread replies next to the top-level messages, linked
+// to their parent by thread_ts, and orders everything chronologically as in
+// Slack's own exports. Broadcast links are dropped since the reply they point
+// to is now in the main flow.
+func FlattenReplies(messages []models.ExportMessage) []models.ExportMessage {
+	var flat []models.ExportMessage
+	for _, msg := range messages {
+		if msg.InThread {
+			continue
+		}
+		replies := msg.Replies
+		msg.Replies = nil
+		flat = append(flat, msg)
+		for _, reply := range replies {
+			if reply.ThreadTimestamp == "" {
+				reply.ThreadTimestamp = msg.ID
+			}
+			flat = append(flat, reply)
+		}
+	}
+	sort.SliceStable(flat, func(a, b int) bool {
+		return flat[a].Timestamp.Before(flat[b].Timestamp)
+	})
+	return flat
+}
+
+// broadcastLink reduces the channel copy of a thread_broadcast reply to a
+// reference, so the main flow shows where the reply was broadcast while its
+// content is exported once inside the thread
+func broadcastLink(msg models.Message) models.Message {
+	return models.Message{
+		Type:      msg.Type,
+		User:      msg.User,
+		Timestamp: msg.Timestamp,
+		ThreadTS:  msg.ThreadTS,
+		Subtype:   msg.Subtype,
+		BotID:     msg.BotID,
+		Username:  msg.Username,
+		InThread:  true,
+	}
+}
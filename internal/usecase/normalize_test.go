@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestNormalizeMessages(t *testing.T) {
+	messages := []models.Message{
+		{Text: "second", Timestamp: "1704067300.000000"},
+		{Text: "parent", Timestamp: "1704067200.000000", ThreadTS: "1704067200.000000", Thread: []models.Message{
+			{Text: "reply 2", Timestamp: "1704067260.000000", ThreadTS: "1704067200.000000"},
+			{Text: "reply 1", Timestamp: "1704067230.000000", ThreadTS: "1704067200.000000"},
+			{Text: "reply 1", Timestamp: "1704067230.000000", ThreadTS: "1704067200.000000"},
+		}},
+		{Text: "second", Timestamp: "1704067300.000000"}, // page boundary duplicate
+		{Text: "reply 2", Timestamp: "1704067260.000000", ThreadTS: "1704067200.000000", Subtype: "thread_broadcast"},
+		{Text: "reply 1", Timestamp: "1704067230.000000", ThreadTS: "1704067200.000000"},
+	}
+
+	normalized, removed := normalizeMessages(messages)
+
+	if removed != 3 {
+		t.Errorf("Expected 3 duplicates removed, got %d", removed)
+	}
+	if len(normalized) != 3 || normalized[0].Text != "parent" || normalized[2].Text != "second" {
+		t.Fatalf("Expected parent, broadcast link and second, got %+v", normalized)
+	}
+	if link := normalized[1]; !link.InThread || link.Timestamp != "1704067260.000000" || link.Text != "" {
+		t.Errorf("Expected the broadcast reduced to a link to its thread, got %+v", link)
+	}
+	replies := normalized[0].Thread
+	if len(replies) != 2 || replies[0].Text != "reply 1" || replies[1].Text != "reply 2" {
+		t.Errorf("Expected two ordered replies, got %+v", replies)
+	}
+}
+
+func TestFlattenReplies(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2024, 1, 1, 10, minute, 0, 0, time.UTC) }
+	messages := []models.ExportMessage{
+		{ID: "parent", Timestamp: at(0), ThreadTimestamp: "parent", Replies: []models.ExportMessage{
+			{ID: "reply 1", Timestamp: at(1), ThreadTimestamp: "parent"},
+			{ID: "reply 3", Timestamp: at(3), Subtype: models.SubtypeThreadBroadcast},
+		}},
+		{ID: "second", Timestamp: at(2)},
+		{ID: "reply 3", Timestamp: at(3), InThread: true},
+	}
+
+	flat := FlattenReplies(messages)
+	want := []string{"parent", "reply 1", "second", "reply 3"}
+	if len(flat) != len(want) {
+		t.Fatalf("Expected %v, got %+v", want, flat)
+	}
+	for i, id := range want {
+		if flat[i].ID != id || len(flat[i].Replies) != 0 || flat[i].InThread {
+			t.Errorf("Expected flat %s at %d, got %+v", id, i, flat[i])
+		}
+	}
+	if flat[3].ThreadTimestamp != "parent" {
+		t.Errorf("Expected replies linked to their parent, got %q", flat[3].ThreadTimestamp)
+	}
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// NotifyClientInterface defines the Slack API operations needed to post notifications
+type NotifyClientInterface interface {
+	PostMessage(ctx context.Context, channelID, text string) error
+}
+
+// ExportNotification summarizes the outcome of a single channel export. The
+// duration is sent to webhooks as duration_seconds.
+type ExportNotification struct {
+	Channel    string        `json:"channel"`
+	Success    bool          `json:"success"`
+	OutputFile string        `json:"output_file,omitempty"`
+	Messages   int           `json:"messages"`
+	FileSize   int64         `json:"file_size"`
+	Duration   time.Duration `json:"-"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// MarshalJSON writes the duration in seconds rather than nanoseconds
+func (n ExportNotification) MarshalJSON() ([]byte, error) {
+	type notification ExportNotification
+	return json.Marshal(struct {
+		notification
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{notification(n), n.Duration.Seconds()})
+}
+
+// webhookPayload is the body posted to notification webhooks. The text field
+// makes it directly usable with Slack incoming webhooks.
+type webhookPayload struct {
+	Text    string               `json:"text"`
+	Success bool                 `json:"success"`
+	Exports []ExportNotification `json:"exports"`
+}
+
+// NotifyService posts export summaries to a webhook and/or a Slack channel
+type NotifyService struct {
+	slackClient NotifyClientInterface
+	httpClient  *http.Client
+	webhookURL  string
+	channelID   string
+}
+
+// NewNotifyService creates a new notify service. Either destination may be empty.
+func NewNotifyService(slackClient NotifyClientInterface, webhookURL, channelID string) *NotifyService {
+	return &NotifyService{
+		slackClient: slackClient,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		webhookURL:  webhookURL,
+		channelID:   channelID,
+	}
+}
+
+// SetTransport sets the transport webhook notifications are sent through
+func (s *NotifyService) SetTransport(transport http.RoundTripper) {
+	s.httpClient.Transport = transport
+}
+
+// Enabled reports whether any notification destination is configured
+func (s *NotifyService) Enabled() bool {
+	return s != nil && (s.webhookURL != "" || s.channelID != "")
+}
+
+// Notify sends the summary to every configured destination. Delivery errors
+// are collected so that one failing destination does not skip the other.
+func (s *NotifyService) Notify(ctx context.Context, notifications []ExportNotification) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	text := FormatNotification(notifications)
+	var errs []string
+
+	if s.webhookURL != "" {
+		if err := s.postWebhook(ctx, text, notifications); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if s.channelID != "" {
+		if s.slackClient == nil {
+			errs = append(errs, "no Slack client configured for channel notifications")
+		} else if err := s.slackClient.PostMessage(ctx, s.channelID, text); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notification failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// postWebhook posts the JSON summary to the webhook URL
+func (s *NotifyService) postWebhook(ctx context.Context, text string, notifications []ExportNotification) error {
+	body, err := json.Marshal(webhookPayload{
+		Text:    text,
+		Success: countFailedNotifications(notifications) == 0,
+		Exports: notifications,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FormatNotification renders a human-readable summary of the exports
+func FormatNotification(notifications []ExportNotification) string {
+	var b strings.Builder
+
+	failed := countFailedNotifications(notifications)
+	if failed == 0 {
+		b.WriteString(fmt.Sprintf(":white_check_mark: Slacker export completed (%d channels)", len(notifications)))
+	} else {
+		b.WriteString(fmt.Sprintf(":x: Slacker export failed for %d of %d channels", failed, len(notifications)))
+	}
+
+	for _, n := range notifications {
+		if !n.Success {
+			b.WriteString(fmt.Sprintf("\n• #%s: %s", n.Channel, n.Error))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("\n• #%s: %d messages, %s in %s -> %s",
+			n.Channel, n.Messages, FormatFileSize(n.FileSize), n.Duration.Round(time.Millisecond), n.OutputFile))
+	}
+
+	return b.String()
+}
+
+// NotificationFromExport builds a notification from the result of ExportChannel
+func NotificationFromExport(channel string, result *models.ExportResult, err error) ExportNotification {
+	notification := ExportNotification{Channel: channel}
+
+	switch {
+	case err != nil:
+		notification.Error = err.Error()
+	case result == nil:
+		notification.Error = "no export result"
+	case !result.Success:
+		notification.Error = result.Error
+	default:
+		notification.Success = true
+		notification.OutputFile = result.OutputFile
+		notification.Messages = result.Statistics.TotalMessages
+		notification.FileSize = result.FileSize
+		notification.Duration = result.Duration
+	}
+
+	return notification
+}
+
+// NotificationsFromBackup builds notifications from the results of a backup run
+func NotificationsFromBackup(results []BackupChannelResult) []ExportNotification {
+	notifications := make([]ExportNotification, 0, len(results))
+	for _, result := range results {
+		notifications = append(notifications, ExportNotification{
+			Channel:    result.Channel,
+			Success:    result.Error == "",
+			OutputFile: result.OutputFile,
+			Messages:   result.Messages,
+			FileSize:   result.FileSize,
+			Duration:   result.Duration,
+			Error:      result.Error,
+		})
+	}
+	return notifications
+}
+
+func countFailedNotifications(notifications []ExportNotification) int {
+	failed := 0
+	for _, n := range notifications {
+		if !n.Success {
+			failed++
+		}
+	}
+	return failed
+}
+
+// FormatFileSize formats a size in bytes for people, e.g. "1.5 KB"
+func FormatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockNotifyClient records messages posted to Slack
+type MockNotifyClient struct {
+	channelID string
+	text      string
+	err       error
+}
+
+func (m *MockNotifyClient) PostMessage(ctx context.Context, channelID, text string) error {
+	m.channelID = channelID
+	m.text = text
+	return m.err
+}
+
+func TestNotifyService_Enabled(t *testing.T) {
+	if NewNotifyService(nil, "", "").Enabled() {
+		t.Error("Expected service without destinations to be disabled")
+	}
+	if !NewNotifyService(nil, "http://example.com", "").Enabled() {
+		t.Error("Expected service with webhook to be enabled")
+	}
+	var nilService *NotifyService
+	if nilService.Enabled() {
+		t.Error("Expected nil service to be disabled")
+	}
+}
+
+func TestNotifyService_Notify(t *testing.T) {
+	var payload webhookPayload
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+	}))
+	defer server.Close()
+
+	client := &MockNotifyClient{}
+	service := NewNotifyService(client, server.URL, "C123")
+
+	notifications := []ExportNotification{
+		{Channel: "general", Success: true, OutputFile: "general.json", Messages: 42, FileSize: 2048, Duration: time.Second},
+		{Channel: "random", Error: "channel 'random' not found"},
+	}
+	if err := service.Notify(context.Background(), notifications); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if payload.Success {
+		t.Error("Expected webhook payload to report failure")
+	}
+	if len(payload.Exports) != 2 || payload.Exports[0].Messages != 42 {
+		t.Errorf("Unexpected webhook exports: %+v", payload.Exports)
+	}
+	if !strings.Contains(string(body), `"duration_seconds":1}`) {
+		t.Errorf("Expected the duration in seconds, got %s", body)
+	}
+	if client.channelID != "C123" {
+		t.Errorf("Expected Slack message to C123, got '%s'", client.channelID)
+	}
+	if !strings.Contains(client.text, "failed for 1 of 2 channels") || !strings.Contains(client.text, "42 messages, 2.0 KB") {
+		t.Errorf("Unexpected Slack message:\n%s", client.text)
+	}
+}
+
+func TestNotifyService_NotifyErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := &MockNotifyClient{err: errors.New("channel_not_found")}
+	service := NewNotifyService(client, server.URL, "C123")
+
+	err := service.Notify(context.Background(), []ExportNotification{{Channel: "general", Success: true}})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "status 500") || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Expected both destination errors, got %v", err)
+	}
+	if client.text == "" {
+		t.Error("Expected Slack message to be attempted after webhook failure")
+	}
+}
+
+func TestNotificationFromExport(t *testing.T) {
+	result := &models.ExportResult{
+		Success:    true,
+		OutputFile: "general.json",
+		FileSize:   100,
+		Duration:   time.Second,
+		Statistics: models.ExportStatistics{TotalMessages: 7},
+	}
+
+	n := NotificationFromExport("general", result, nil)
+	if !n.Success || n.Messages != 7 || n.OutputFile != "general.json" {
+		t.Errorf("Unexpected notification: %+v", n)
+	}
+
+	n = NotificationFromExport("general", &models.ExportResult{Error: "boom"}, errors.New("boom"))
+	if n.Success || n.Error != "boom" {
+		t.Errorf("Expected failed notification, got %+v", n)
+	}
+}
+
+func TestFormatFileSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytes    int64
+		expected string
+	}{
+		{
+			name:     "Bytes",
+			bytes:    512,
+			expected: "512 B",
+		},
+		{
+			name:     "Kilobytes",
+			bytes:    1536, // 1.5 KB
+			expected: "1.5 KB",
+		},
+		{
+			name:     "Megabytes",
+			bytes:    2097152, // 2 MB
+			expected: "2.0 MB",
+		},
+		{
+			name:     "Gigabytes",
+			bytes:    3221225472, // 3 GB
+			expected: "3.0 GB",
+		},
+		{
+			name:     "Zero bytes",
+			bytes:    0,
+			expected: "0 B",
+		},
+		{
+			name:     "One byte",
+			bytes:    1,
+			expected: "1 B",
+		},
+		{
+			name:     "Exactly 1 KB",
+			bytes:    1024,
+			expected: "1.0 KB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FormatFileSize(tt.bytes)
+			if result != tt.expected {
+				t.Errorf("Expected '%s' for %d bytes, got '%s'", tt.expected, tt.bytes, result)
+			}
+		})
+	}
+}
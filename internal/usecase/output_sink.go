@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itcaat/slacker/internal/storage"
+	"github.com/itcaat/slacker/models"
+)
+
+// StdoutOutput is the output path that writes an export to standard output
+const StdoutOutput = "-"
+
+// OutputSink receives the bytes of one export file. Nothing is visible at the
+// destination until Commit returns without error; Abort discards what was
+// written so far.
+type OutputSink interface {
+	io.Writer
+	// Commit finishes the output and returns its size in bytes
+	Commit() (int64, error)
+	Abort() error
+}
+
+// SinkOpener opens the sink for an output destination
+type SinkOpener func(ctx context.Context, destination string, options models.ExportOptions) (OutputSink, error)
+
+var (
+	sinkMu      sync.RWMutex
+	sinkOpeners = map[string]SinkOpener{}
+)
+
+func init() {
+	RegisterNetworkSinks(http.DefaultTransport)
+}
+
+// RegisterNetworkSinks registers the object storage and HTTP(S) sinks to
+// upload through transport, such as one with the configured proxy and CAs
+func RegisterNetworkSinks(transport http.RoundTripper) {
+	storageSink := func(ctx context.Context, destination string, options models.ExportOptions) (OutputSink, error) {
+		return openStorageSink(ctx, destination, options, transport)
+	}
+	for _, scheme := range []string{"s3", "gs", "azblob"} {
+		RegisterOutputSink(scheme, storageSink)
+	}
+	httpSink := func(ctx context.Context, destination string, options models.ExportOptions) (OutputSink, error) {
+		return openHTTPSink(ctx, destination, options, transport)
+	}
+	RegisterOutputSink("http", httpSink)
+	RegisterOutputSink("https", httpSink)
+}
+
+// RegisterOutputSink makes outputs starting with "<scheme>://" open through
+// opener. A later registration for the same scheme replaces the earlier one.
+func RegisterOutputSink(scheme string, opener SinkOpener) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	sinkOpeners[scheme] = opener
+}
+
+// sinkOpenerFor returns the registered opener for destination, or nil for
+// local paths
+func sinkOpenerFor(destination string) SinkOpener {
+	scheme, _, ok := strings.Cut(destination, "://")
+	if !ok {
+		return nil
+	}
+	sinkMu.RLock()
+	defer sinkMu.RUnlock()
+	return sinkOpeners[scheme]
+}
+
+// IsLocalOutput reports whether destination is a path on the local
+// filesystem rather than stdout or a registered remote scheme
+func IsLocalOutput(destination string) bool {
+	return destination != StdoutOutput && sinkOpenerFor(destination) == nil
+}
+
+// SetStdout sets where exports to StdoutOutput are written. It defaults to
+// os.Stdout.
+func (s *ExportService) SetStdout(w io.Writer) {
+	s.stdout = w
+}
+
+// openSink opens the sink for destination: stdout for "-", the registered
+// opener for URLs and an atomically written file otherwise
+func (s *ExportService) openSink(ctx context.Context, destination string, options models.ExportOptions) (OutputSink, error) {
+	if destination == StdoutOutput {
+		stdout := s.stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		return &streamSink{w: stdout}, nil
+	}
+	if opener := sinkOpenerFor(destination); opener != nil {
+		return opener(ctx, destination, options)
+	}
+	return newFileSink(destination)
+}
+
+// fileSink writes a local file through <name>.tmp and renames it into place
+// on Commit, so a crash mid-write never leaves a truncated file that looks
+// finished
+type fileSink struct {
+	file     *os.File
+	filename string
+}
+
+func newFileSink(filename string) (*fileSink, error) {
+	file, err := os.OpenFile(filename+tempSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return &fileSink{file: file, filename: filename}, nil
+}
+
+func (f *fileSink) Write(p []byte) (int, error) {
+	return f.file.Write(p)
+}
+
+func (f *fileSink) Commit() (int64, error) {
+	tmp := f.file.Name()
+	if err := f.file.Sync(); err != nil {
+		f.Abort()
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := f.file.Close(); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to close file: %w", err)
+	}
+	if err := os.Rename(tmp, f.filename); err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("failed to move file into place: %w", err)
+	}
+
+	info, err := os.Stat(f.filename)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get file info: %w", err)
+	}
+	return info.Size(), nil
+}
+
+func (f *fileSink) Abort() error {
+	f.file.Close()
+	return os.Remove(f.file.Name())
+}
+
+// streamSink writes straight to a stream such as stdout. Data cannot be
+// taken back once written, so Abort only stops counting.
+type streamSink struct {
+	w io.Writer
+	n int64
+}
+
+func (s *streamSink) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	s.n += int64(n)
+	return n, err
+}
+
+func (s *streamSink) Commit() (int64, error) {
+	return s.n, nil
+}
+
+func (s *streamSink) Abort() error {
+	return nil
+}
+
+// storageSink uploads to object storage through a storage.Writer
+type storageSink struct {
+	writer storage.Writer
+	n      int64
+}
+
+func openStorageSink(ctx context.Context, destination string, options models.ExportOptions, transport http.RoundTripper) (OutputSink, error) {
+	writer, err := storage.NewWriter(ctx, destination, storage.Options{
+		ServerSideEncryption: options.ServerSideEncryption,
+		KMSKeyID:             options.EncryptionKeyID,
+		HTTPClient:           &http.Client{Timeout: 5 * time.Minute, Transport: transport},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &storageSink{writer: writer}, nil
+}
+
+func (s *storageSink) Write(p []byte) (int, error) {
+	n, err := s.writer.Write(p)
+	s.n += int64(n)
+	return n, err
+}
+
+func (s *storageSink) Commit() (int64, error) {
+	if err := s.writer.Close(); err != nil {
+		return 0, fmt.Errorf("failed to upload export: %w", err)
+	}
+	return s.n, nil
+}
+
+func (s *storageSink) Abort() error {
+	return s.writer.Abort()
+}
+
+// httpSink buffers the export and sends it with an HTTP PUT on Commit. The
+// export is already in memory when it is written, and buffering it lets the
+// request carry a Content-Length, which presigned upload URLs require.
+type httpSink struct {
+	ctx     context.Context
+	url     string
+	user    *url.Userinfo
+	headers map[string]string
+	client  *http.Client
+	buf     bytes.Buffer
+}
+
+func openHTTPSink(ctx context.Context, destination string, options models.ExportOptions, transport http.RoundTripper) (OutputSink, error) {
+	target, err := url.Parse(destination)
+	if err != nil || target.Host == "" {
+		return nil, fmt.Errorf("invalid destination '%s': use http(s)://host/path", destination)
+	}
+	// Credentials in the URL are sent as basic auth, not as part of the URL
+	user := target.User
+	target.User = nil
+
+	return &httpSink{
+		ctx:     ctx,
+		url:     target.String(),
+		user:    user,
+		headers: options.OutputHeaders,
+		client:  &http.Client{Timeout: 5 * time.Minute, Transport: transport},
+	}, nil
+}
+
+func (h *httpSink) Write(p []byte) (int, error) {
+	return h.buf.Write(p)
+}
+
+func (h *httpSink) Commit() (int64, error) {
+	defer h.Abort()
+	n := int64(h.buf.Len())
+	req, err := http.NewRequestWithContext(h.ctx, http.MethodPut, h.url, bytes.NewReader(h.buf.Bytes()))
+	if err != nil {
+		return 0, fmt.Errorf("invalid destination '%s': %w", h.url, err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if h.user != nil {
+		password, _ := h.user.Password()
+		req.SetBasicAuth(h.user.Username(), password)
+	}
+	for name, value := range h.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upload export: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return 0, fmt.Errorf("failed to upload export: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return n, nil
+}
+
+// Abort drops the buffered export. It is safe to call again after Commit
+// or an earlier Abort.
+func (h *httpSink) Abort() error {
+	h.buf = bytes.Buffer{}
+	return nil
+}
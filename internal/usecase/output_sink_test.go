@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_OutputToStdout(t *testing.T) {
+	var stdout bytes.Buffer
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	service.SetStdout(&stdout)
+
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:  "C123456",
+		OutputFile: StdoutOutput,
+		Format:     "json-compact",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.OutputFile != StdoutOutput || result.FileSize != int64(stdout.Len()) {
+		t.Errorf("Expected %d bytes on stdout, got %s with %d bytes", stdout.Len(), result.OutputFile, result.FileSize)
+	}
+
+	var export models.ChannelExport
+	if err := json.Unmarshal(stdout.Bytes(), &export); err != nil {
+		t.Fatalf("Expected a JSON export on stdout, got %v", err)
+	}
+	if export.Channel.ID != "C123456" || len(export.Messages) == 0 {
+		t.Errorf("Expected the messages of C123456, got %+v", export.Channel)
+	}
+}
+
+func TestExportService_OutputToStdoutGzip(t *testing.T) {
+	var stdout bytes.Buffer
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	service.SetStdout(&stdout)
+
+	// "-" stays "-" instead of gaining a .gz suffix
+	name, _, err := service.writeOutput(context.Background(), StdoutOutput, []byte(`{"ok":true}`), models.ExportOptions{Compression: "gzip"})
+	if err != nil || name != StdoutOutput {
+		t.Fatalf("Expected a gzip stream on stdout, got %s, %v", name, err)
+	}
+	reader, err := gzip.NewReader(&stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(reader); string(data) != `{"ok":true}` {
+		t.Errorf("Expected the compressed export, got %s", data)
+	}
+
+	if _, _, _, err := service.generateSplitOutput(context.Background(), models.ChannelExport{}, models.ExportOptions{OutputFile: StdoutOutput}, SplitSpec{Period: "month"}); err == nil {
+		t.Error("Expected split exports to stdout to be rejected")
+	}
+}
+
+func TestExportService_OutputToHTTP(t *testing.T) {
+	var method, auth, basic string
+	var length int64
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		length = r.ContentLength
+		auth = r.Header.Get("Authorization")
+		if user, password, ok := r.BasicAuth(); ok {
+			basic = user + ":" + password
+		}
+		body, _ = io.ReadAll(r.Body)
+		if r.URL.Path == "/denied.json" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	options := models.ExportOptions{OutputHeaders: map[string]string{"Authorization": "Bearer secret"}}
+	name, size, err := service.writeOutput(context.Background(), server.URL+"/general.json", []byte(`{"ok":true}`), options)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if method != http.MethodPut || string(body) != `{"ok":true}` || size != int64(len(body)) || length != size {
+		t.Errorf("Expected a PUT of the export with its length, got %s %s (%d bytes)", method, body, length)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Expected the configured Authorization header, got %q", auth)
+	}
+	if name != server.URL+"/general.json" {
+		t.Errorf("Expected the URL as output, got %s", name)
+	}
+
+	if _, _, err := service.writeOutput(context.Background(), server.URL+"/denied.json", []byte(`{}`), models.ExportOptions{}); err == nil {
+		t.Error("Expected an error for a rejected upload")
+	}
+	sink, err := openHTTPSink(context.Background(), server.URL+"/denied.json", models.ExportOptions{}, http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sink.Write([]byte(`{}`))
+	if _, err := sink.Commit(); err == nil {
+		t.Error("Expected an error for a rejected upload")
+	}
+	if err := sink.Abort(); err != nil {
+		t.Errorf("Expected Abort after a failed Commit to succeed, got %v", err)
+	}
+
+	withUser := strings.Replace(server.URL, "http://", "http://uploader:pw@", 1) + "/general.json"
+	if _, _, err := service.writeOutput(context.Background(), withUser, []byte(`{}`), models.ExportOptions{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if basic != "uploader:pw" {
+		t.Errorf("Expected URL credentials sent as basic auth, got %q", basic)
+	}
+}
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRegisterNetworkSinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	RegisterNetworkSinks(transport)
+	defer RegisterNetworkSinks(http.DefaultTransport)
+
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	if _, _, err := service.writeOutput(context.Background(), server.URL+"/general.json", []byte(`{}`), models.ExportOptions{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("Expected the upload to go through the registered transport, got %d requests", transport.requests)
+	}
+}
+
+// memorySink collects an export in memory
+type memorySink struct {
+	bytes.Buffer
+	committed bool
+}
+
+func (m *memorySink) Commit() (int64, error) {
+	m.committed = true
+	return int64(m.Len()), nil
+}
+
+func (m *memorySink) Abort() error {
+	return nil
+}
+
+func TestRegisterOutputSink(t *testing.T) {
+	sink := &memorySink{}
+	RegisterOutputSink("mem", func(ctx context.Context, destination string, options models.ExportOptions) (OutputSink, error) {
+		return sink, nil
+	})
+	defer func() {
+		sinkMu.Lock()
+		delete(sinkOpeners, "mem")
+		sinkMu.Unlock()
+	}()
+
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	if _, _, err := service.writeOutput(context.Background(), "mem://general.json", []byte(`{}`), models.ExportOptions{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !sink.committed || sink.String() != `{}` {
+		t.Errorf("Expected the export in the registered sink, got %q", sink.String())
+	}
+}
+
+func TestIsLocalOutput(t *testing.T) {
+	tests := map[string]bool{
+		"exports/general.json":         true,
+		"C:\\exports\\general.json":    true,
+		StdoutOutput:                   false,
+		"s3://bucket/general.json":     false,
+		"gs://bucket/general.json":     false,
+		"azblob://container/a.json":    false,
+		"https://example.com/a.json":   false,
+		"unknown://example.com/a.json": true,
+	}
+	for destination, want := range tests {
+		if got := IsLocalOutput(destination); got != want {
+			t.Errorf("IsLocalOutput(%q) = %v, want %v", destination, got, want)
+		}
+	}
+}
+
+func TestExportService_ConvertExport(t *testing.T) {
+	dir := t.TempDir()
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:  "C123456",
+		OutputFile: filepath.Join(dir, "general.json"),
+		Format:     "json",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	converter := NewExportService(nil, "1.0.0-test")
+	converter.SetStdout(&stdout)
+	if _, _, err := converter.ConvertExport(context.Background(), export, models.ExportOptions{
+		OutputFile: StdoutOutput,
+		Format:     FormatLLMJSONL,
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lines := strings.Count(stdout.String(), "\n"); lines == 0 {
+		t.Errorf("Expected llm-jsonl chunks on stdout, got %q", stdout.String())
+	}
+
+	// A compact copy keeps a valid integrity footer
+	compact := filepath.Join(dir, "general-compact.json")
+	if _, _, err := converter.ConvertExport(context.Background(), export, models.ExportOptions{OutputFile: compact, Format: "json-compact"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	check, err := VerifyExport(compact)
+	if err != nil || check.Status != IntegrityStatusOK {
+		t.Errorf("Expected a verified export, got %+v, %v", check, err)
+	}
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// OutputNameData holds the variables available to output templates
+type OutputNameData struct {
+	Channel   string
+	ChannelID string
+	Workspace string
+	// From and To are the requested date range (YYYY-MM-DD). From is "start"
+	// and To is the run date when the range is open.
+	From string
+	To   string
+	// Date and Timestamp describe when the export ran
+	Date      string
+	Timestamp string
+}
+
+// NewOutputNameData fills the template variables for an export run
+func NewOutputNameData(channelID, channelName, workspace string, from, to *time.Time, now time.Time) OutputNameData {
+	data := OutputNameData{
+		Channel:   SafeFileName(channelName),
+		ChannelID: channelID,
+		Workspace: SafeFileName(workspace),
+		From:      "start",
+		To:        now.Format("2006-01-02"),
+		Date:      now.Format("2006-01-02"),
+		Timestamp: now.Format("20060102-150405"),
+	}
+	if from != nil {
+		data.From = from.Format("2006-01-02")
+	}
+	if to != nil {
+		data.To = to.Format("2006-01-02")
+	}
+	return data
+}
+
+// ParseOutputTemplate parses an output template such as
+// "{{.Channel}}/{{.Date}}/{{.Channel}}-{{.From}}-{{.To}}.json"
+func ParseOutputTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	if _, err := renderTemplate(tmpl, OutputNameData{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// RenderOutputTemplate expands an output template into a file path
+func RenderOutputTemplate(text string, data OutputNameData) (string, error) {
+	tmpl, err := ParseOutputTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	path, err := renderTemplate(tmpl, data)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(path) == "" || strings.HasSuffix(path, "/") {
+		return "", fmt.Errorf("output template '%s' does not produce a file name", text)
+	}
+	return path, nil
+}
+
+// TemplateUsesWorkspace reports whether the template needs the workspace
+// name, which costs an extra API call to look up
+func TemplateUsesWorkspace(text string) bool {
+	return strings.Contains(text, ".Workspace")
+}
+
+func renderTemplate(tmpl *template.Template, data OutputNameData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid output template: %w", err)
+	}
+	return buf.String(), nil
+}
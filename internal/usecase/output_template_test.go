@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRenderOutputTemplate(t *testing.T) {
+	now := time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := NewOutputNameData("C123456", "general", "Acme/Corp", &from, nil, now)
+
+	tests := []struct {
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{template: "{{.Channel}}/{{.Date}}/{{.Channel}}-{{.From}}-{{.To}}.json", want: "general/2024-02-03/general-2024-01-01-2024-02-03.json"},
+		{template: "{{.Workspace}}/{{.ChannelID}}-{{.Timestamp}}.json", want: "Acme-Corp/C123456-20240203-040506.json"},
+		{template: "{{.Channel}}/", wantErr: true},
+		{template: "{{.Unknown}}.json", wantErr: true},
+		{template: "{{.Channel", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := RenderOutputTemplate(tt.template, data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("RenderOutputTemplate(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RenderOutputTemplate(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	if data := NewOutputNameData("C1", "general", "", nil, nil, now); data.From != "start" || data.To != "2024-02-03" {
+		t.Errorf("Expected open range to render as start..run date, got %s..%s", data.From, data.To)
+	}
+}
+
+func TestBackupService_RunOutputTemplate(t *testing.T) {
+	service := NewBackupService(NewMockSlackClient(), "1.0.0-test")
+	dir := t.TempDir()
+
+	results, err := service.Run(BackupJob{
+		Channels:       []string{"general"},
+		OutputDir:      dir,
+		OutputTemplate: "{{.Workspace}}/{{.Channel}}/{{.ChannelID}}.json",
+		Workspace:      "acme",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := filepath.Join(dir, "acme", "general", "C123456.json")
+	if results[0].OutputFile != want {
+		t.Errorf("Expected %s, got %s", want, results[0].OutputFile)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("Expected output file to exist: %v", err)
+	}
+}
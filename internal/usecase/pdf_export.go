@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/pdf"
+	"github.com/itcaat/slacker/models"
+)
+
+// slackLink matches Slack markup such as <@U123>, <#C123|general> and
+// <https://example.com|label>
+var slackLink = regexp.MustCompile(`<([^<>|]+)(?:\|([^<>]*))?>`)
+
+// renderPDF lays out an export as a paginated transcript with a cover page
+// showing the export metadata. It fails when the export holds characters the
+// PDF fonts cannot show.
+func renderPDF(exportData models.ChannelExport) ([]byte, error) {
+	channel := exportData.Channel
+	doc := pdf.New("#" + channel.Name)
+
+	writePDFCover(doc, exportData)
+
+	doc.AddPage()
+	day := ""
+	for _, msg := range exportData.Messages {
+		if current := msg.Timestamp.Format("Monday, January 2, 2006"); current != day {
+			if day != "" {
+				doc.Space(8)
+			}
+			day = current
+			doc.Text(day, pdf.Style{Size: 12, Bold: true})
+			doc.Space(4)
+		}
+
+		writePDFMessage(doc, msg, exportData.Users, exportData.UserGroups, false)
+		for _, reply := range msg.Replies {
+			writePDFMessage(doc, reply, exportData.Users, exportData.UserGroups, true)
+		}
+	}
+	if len(exportData.Messages) == 0 {
+		doc.Text("No messages in the exported range.", pdf.Style{Muted: true})
+	}
+
+	data, err := doc.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("cannot render #%s as PDF: %w; use another format", channel.Name, err)
+	}
+	return data, nil
+}
+
+// writePDFCover writes the cover page
+func writePDFCover(doc *pdf.Document, exportData models.ChannelExport) {
+	info := exportData.ExportInfo
+	channel := exportData.Channel
+	stats := exportData.Statistics
+
+	doc.Space(160)
+	doc.Text("Slack Channel Export", pdf.Style{Size: 24, Bold: true, Center: true})
+	doc.Space(10)
+	doc.Text("#"+channel.Name, pdf.Style{Size: 16, Center: true})
+	doc.Space(60)
+
+	rangeText := "All messages"
+	if info.DateRange.From != nil || info.DateRange.To != nil {
+		from, to := "beginning", "now"
+		if info.DateRange.From != nil {
+			from = info.DateRange.From.Format("2006-01-02 15:04")
+		}
+		if info.DateRange.To != nil {
+			to = info.DateRange.To.Format("2006-01-02 15:04")
+		}
+		rangeText = from + " to " + to
+	}
+
+	rows := [][2]string{
+		{"Channel ID", channel.ID},
+		{"Visibility", map[bool]string{true: "Private", false: "Public"}[channel.IsPrivate]},
+		{"Topic", channel.Topic},
+		{"Purpose", channel.Purpose},
+		{"Workspace", describeWorkspace(info.Workspace)},
+		{"Exported at", info.ExportedAt.Format("2006-01-02 15:04:05 MST")},
+		{"Exported by", info.ExportedBy},
+		{"Slacker version", info.SlackerVersion},
+		{"Date range", rangeText},
+		{"Timezone", info.Timezone},
+		{"Filters", describeFilters(info.Filters)},
+		{"Messages", fmt.Sprintf("%d (%d threads, %d replies)", stats.TotalMessages, stats.TotalThreads, stats.TotalReplies)},
+		{"Users", fmt.Sprintf("%d", stats.TotalUsers)},
+		{"Files", fmt.Sprintf("%d", stats.TotalFiles)},
+		{"Reactions", fmt.Sprintf("%d", stats.TotalReactions)},
+	}
+	if channel.IsArchived {
+		rows[1][1] += ", archived"
+	}
+	for _, row := range rows {
+		if row[1] == "" {
+			continue
+		}
+		doc.Text(row[0]+": "+row[1], pdf.Style{Size: 11, Indent: 40})
+	}
+
+	if info.Partial {
+		doc.Space(16)
+		doc.Text("Partial export - some data could not be fetched:", pdf.Style{Size: 11, Bold: true, Indent: 40})
+		for _, warning := range info.Warnings {
+			doc.Text("- "+warning, pdf.Style{Size: 10, Indent: 52})
+		}
+	}
+}
+
+// writePDFMessage writes one message with its files, attachments and a
+// reactions summary
+func writePDFMessage(doc *pdf.Document, msg models.ExportMessage, users map[string]models.ExportUser, groups map[string]models.UserGroup, reply bool) {
+	indent := 0.0
+	stamp := msg.Timestamp.Format("15:04:05")
+	if reply {
+		indent = 24
+		stamp = msg.Timestamp.Format("2006-01-02 15:04:05")
+	}
+	if msg.Omitted {
+		doc.Text(stamp+"  Message omitted by export filters", pdf.Style{Muted: true, Indent: indent})
+		doc.Space(5)
+		return
+	}
+
+	header := userDisplayName(msg.User, users) + "  " + stamp
+	if reply {
+		header = "> " + header
+	}
+	if msg.Edited != nil {
+		header += " (edited)"
+	}
+	doc.Text(header, pdf.Style{Bold: true, Indent: indent})
+
+	if text := plainText(msg.Text, users, groups); text != "" {
+		doc.Text(text, pdf.Style{Indent: indent + 8})
+	}
+
+	for _, attachment := range msg.Attachments {
+		title := attachment.Title
+		if title == "" {
+			title = attachment.Fallback
+		}
+		if title != "" {
+			doc.Text("Attachment: "+plainText(title, users, groups), pdf.Style{Size: 9, Muted: true, Indent: indent + 8})
+		}
+	}
+	if msg.Call != nil {
+		doc.Text(describeCall(*msg.Call, users), pdf.Style{Size: 9, Muted: true, Indent: indent + 8})
+	}
+	if msg.Workflow != nil && msg.Workflow.Name != "" {
+		doc.Text("Posted by: "+msg.Workflow.Name, pdf.Style{Size: 9, Muted: true, Indent: indent + 8})
+	} else if msg.Username != "" {
+		doc.Text("Posted by: "+msg.Username, pdf.Style{Size: 9, Muted: true, Indent: indent + 8})
+	}
+	if msg.Canvas != nil {
+		doc.Text("Canvas: "+msg.Canvas.Title, pdf.Style{Size: 9, Muted: true, Indent: indent + 8})
+	}
+	for _, file := range msg.Files {
+		line := fmt.Sprintf("File: %s (%s, %d bytes)", file.Name, file.Filetype, file.Size)
+		if file.Permalink != "" {
+			line += " " + file.Permalink
+		}
+		doc.Text(line, pdf.Style{Size: 9, Muted: true, Indent: indent + 8})
+	}
+	if len(msg.Reactions) > 0 {
+		parts := make([]string, 0, len(msg.Reactions))
+		for _, reaction := range msg.Reactions {
+			parts = append(parts, fmt.Sprintf(":%s: %d", reaction.Name, reaction.Count))
+		}
+		doc.Text("Reactions: "+strings.Join(parts, "  "), pdf.Style{Size: 9, Muted: true, Indent: indent + 8})
+	}
+
+	doc.Space(5)
+}
+
+// describeCall summarizes a huddle or call with its duration and participants
+func describeCall(call models.ExportCall, users map[string]models.ExportUser) string {
+	text := "Huddle"
+	switch {
+	case call.Duration > 0:
+		text += " lasted " + (time.Duration(call.Duration) * time.Second).String()
+	case call.StartedAt != nil && call.EndedAt == nil:
+		text += " started " + call.StartedAt.Format("15:04")
+	}
+	if len(call.Participants) > 0 {
+		names := make([]string, len(call.Participants))
+		for i, id := range call.Participants {
+			names[i] = userDisplayName(id, users)
+		}
+		text += " with " + strings.Join(names, ", ")
+	}
+	return text
+}
+
+// userDisplayName returns the display name, real name or user name of a user,
+// falling back to the ID
+func userDisplayName(id string, users map[string]models.ExportUser) string {
+	user, ok := users[id]
+	switch {
+	case !ok:
+		if id == "" {
+			return "unknown"
+		}
+		return id
+	case user.Profile.DisplayName != "":
+		return user.Profile.DisplayName
+	case user.RealName != "":
+		return user.RealName
+	default:
+		return user.Name
+	}
+}
+
+// plainText replaces Slack markup with readable text: mentions become @name,
+// user group mentions @handle, channel links #name and URLs "label (url)"
+func plainText(text string, users map[string]models.ExportUser, groups map[string]models.UserGroup) string {
+	text = slackLink.ReplaceAllStringFunc(text, func(match string) string {
+		parts := slackLink.FindStringSubmatch(match)
+		target, label := parts[1], parts[2]
+		switch {
+		case strings.HasPrefix(target, "@"):
+			return "@" + userDisplayName(strings.TrimPrefix(target, "@"), users)
+		case strings.HasPrefix(target, "#"):
+			if label != "" {
+				return "#" + label
+			}
+			return target
+		case strings.HasPrefix(target, "!subteam^"):
+			if group, ok := groups[strings.TrimPrefix(target, "!subteam^")]; ok {
+				return "@" + group.Handle
+			}
+			if label != "" {
+				return label
+			}
+			return target
+		case strings.HasPrefix(target, "!"):
+			return "@" + strings.TrimPrefix(target, "!")
+		case label != "" && label != target:
+			return label + " (" + target + ")"
+		default:
+			return target
+		}
+	})
+	return unescapeSlack(text)
+}
+
+// describeWorkspace names a workspace with its domain, e.g. "Acme (acme.slack.com)"
+func describeWorkspace(workspace *models.ExportWorkspace) string {
+	if workspace == nil {
+		return ""
+	}
+	if workspace.Domain == "" {
+		return workspace.Name
+	}
+	return fmt.Sprintf("%s (%s.slack.com)", workspace.Name, workspace.Domain)
+}
+
+// describeFilters summarizes the message filters of an export
+func describeFilters(filters *models.ExportFilters) string {
+	if filters == nil {
+		return ""
+	}
+	var parts []string
+	if len(filters.Users) > 0 {
+		parts = append(parts, "users "+strings.Join(filters.Users, ", "))
+	}
+	if filters.Match != "" {
+		parts = append(parts, "text matching "+filters.Match)
+	}
+	if len(filters.ExcludeSubtypes) > 0 {
+		parts = append(parts, "excluding "+strings.Join(filters.ExcludeSubtypes, ", "))
+	}
+	switch filters.Bots {
+	case models.BotFilterExclude:
+		parts = append(parts, "no bots")
+	case models.BotFilterOnly:
+		parts = append(parts, "bots only")
+	}
+	if filters.MinReactions > 0 {
+		parts = append(parts, fmt.Sprintf("at least %d reactions", filters.MinReactions))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_ExportChannelPDF(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	outputFile := filepath.Join(t.TempDir(), "general.pdf")
+
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     outputFile,
+		Format:         "pdf",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Expected PDF file, got %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatal("Expected a PDF document")
+	}
+	for _, want := range []string{"Slack Channel Export", "Slacker version: 1.0.0-test", "Page 2 of 2"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("Expected PDF to contain %q", want)
+		}
+	}
+}
+
+func TestRenderPDFRefusesUnsupportedCharacters(t *testing.T) {
+	exportData := models.ChannelExport{
+		Channel:  models.ChannelInfo{ID: "C1", Name: "general"},
+		Messages: []models.ExportMessage{{User: "U1", Text: "shipped 🚀"}},
+	}
+	_, err := renderPDF(exportData)
+	if err == nil || !strings.Contains(err.Error(), "U+1F680") {
+		t.Fatalf("Expected an error naming the character, got %v", err)
+	}
+}
+
+func TestPDFText(t *testing.T) {
+	users := map[string]models.ExportUser{
+		"U1": {ID: "U1", Name: "alice", Profile: models.ExportProfile{DisplayName: "Alice"}},
+	}
+	got := plainText("hi <@U1> see <#C1|general> and <https://example.com|docs> &amp; <!here>", users, nil)
+	want := "hi @Alice see #general and docs (https://example.com) & @here"
+	if got != want {
+		t.Errorf("plainText() = %q, want %q", got, want)
+	}
+}
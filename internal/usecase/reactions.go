@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// Views of a ReactionLeaderboard
+const (
+	ReactionsByEmoji  = "emoji"
+	ReactionsGiven    = "given"
+	ReactionsReceived = "received"
+)
+
+// ReactionLeaderboard ranks the emoji reactions of one or more exports
+type ReactionLeaderboard struct {
+	Channels []string   `json:"channels"`
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	// Total is the number of reactions on the ranked messages
+	Total int `json:"total"`
+	// Emoji ranks the emoji by how often they were used
+	Emoji []EmojiRank `json:"emoji"`
+	// Given ranks users by the reactions they added and Received by the
+	// reactions on their messages, self-reactions excluded
+	Given    []UserReactionRank `json:"given"`
+	Received []UserReactionRank `json:"received"`
+}
+
+// EmojiRank counts the uses of one emoji
+type EmojiRank struct {
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+	Messages int    `json:"messages"`
+	// Users is the number of people who reacted with it
+	Users int `json:"users"`
+}
+
+// UserReactionRank counts the reactions one user gave or received
+type UserReactionRank struct {
+	User     string         `json:"user"`
+	UserName string         `json:"user_name,omitempty"`
+	Count    int            `json:"count"`
+	TopEmoji string         `json:"top_emoji"`
+	Emoji    map[string]int `json:"emoji"`
+}
+
+// RankReactions ranks the reactions on the messages and thread replies of
+// exports posted within from and to; nil bounds are open. Slack lists at most
+// a sample of the users behind each reaction, so Given can add up to less
+// than Total.
+func RankReactions(exports []*models.ChannelExport, from, to *time.Time) *ReactionLeaderboard {
+	board := &ReactionLeaderboard{From: from, To: to}
+	emoji := make(map[string]*EmojiRank)
+	givers := make(map[string]map[string]bool)
+	given := make(map[string]map[string]int)
+	received := make(map[string]map[string]int)
+	names := make(map[string]string)
+
+	count := func(users map[string]models.ExportUser, msg models.ExportMessage) {
+		if (from != nil && msg.Timestamp.Before(*from)) || (to != nil && msg.Timestamp.After(*to)) {
+			return
+		}
+		for _, reaction := range msg.Reactions {
+			board.Total += reaction.Count
+			rank, ok := emoji[reaction.Name]
+			if !ok {
+				rank = &EmojiRank{Name: reaction.Name}
+				emoji[reaction.Name] = rank
+				givers[reaction.Name] = make(map[string]bool)
+			}
+			rank.Count += reaction.Count
+			rank.Messages++
+
+			self := 0
+			for _, user := range reaction.Users {
+				givers[reaction.Name][user] = true
+				addReaction(given, user, reaction.Name, 1)
+				nameUser(names, user, users)
+				if user == msg.User {
+					self++
+				}
+			}
+			if msg.User != "" && reaction.Count > self {
+				addReaction(received, msg.User, reaction.Name, reaction.Count-self)
+				nameUser(names, msg.User, users)
+			}
+		}
+	}
+
+	for _, export := range exports {
+		board.Channels = append(board.Channels, export.Channel.Name)
+		var walk func([]models.ExportMessage)
+		walk = func(messages []models.ExportMessage) {
+			for _, msg := range messages {
+				// Broadcast links are counted with their thread
+				if !msg.InThread {
+					count(export.Users, msg)
+				}
+				walk(msg.Replies)
+			}
+		}
+		walk(export.Messages)
+	}
+
+	board.Emoji = []EmojiRank{}
+	for name, rank := range emoji {
+		rank.Users = len(givers[name])
+		board.Emoji = append(board.Emoji, *rank)
+	}
+	sort.Slice(board.Emoji, func(i, j int) bool {
+		if board.Emoji[i].Count != board.Emoji[j].Count {
+			return board.Emoji[i].Count > board.Emoji[j].Count
+		}
+		return board.Emoji[i].Name < board.Emoji[j].Name
+	})
+	board.Given = rankUsers(given, names)
+	board.Received = rankUsers(received, names)
+	return board
+}
+
+// addReaction adds n reactions with emoji to user in counts
+func addReaction(counts map[string]map[string]int, user, emoji string, n int) {
+	if counts[user] == nil {
+		counts[user] = make(map[string]int)
+	}
+	counts[user][emoji] += n
+}
+
+// nameUser records the display name of user from the export's directory
+func nameUser(names map[string]string, user string, users map[string]models.ExportUser) {
+	if _, ok := users[user]; ok && names[user] == "" {
+		names[user] = userDisplayName(user, users)
+	}
+}
+
+// rankUsers turns per-user emoji counts into a ranking, most reactions first
+func rankUsers(counts map[string]map[string]int, names map[string]string) []UserReactionRank {
+	ranks := []UserReactionRank{}
+	for user, emoji := range counts {
+		rank := UserReactionRank{User: user, UserName: names[user], Emoji: emoji}
+		top := 0
+		for name, n := range emoji {
+			rank.Count += n
+			if n > top || (n == top && name < rank.TopEmoji) {
+				top, rank.TopEmoji = n, name
+			}
+		}
+		ranks = append(ranks, rank)
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].Count != ranks[j].Count {
+			return ranks[i].Count > ranks[j].Count
+		}
+		return ranks[i].User < ranks[j].User
+	})
+	return ranks
+}
+
+// Truncate keeps the top limit entries of every ranking; limit <= 0 keeps all
+func (b *ReactionLeaderboard) Truncate(limit int) {
+	if limit <= 0 {
+		return
+	}
+	if len(b.Emoji) > limit {
+		b.Emoji = b.Emoji[:limit]
+	}
+	if len(b.Given) > limit {
+		b.Given = b.Given[:limit]
+	}
+	if len(b.Received) > limit {
+		b.Received = b.Received[:limit]
+	}
+}
+
+// WriteReactionsCSV writes one view of board as CSV with a header row
+func WriteReactionsCSV(w io.Writer, board *ReactionLeaderboard, view string) error {
+	writer := csv.NewWriter(w)
+	var records [][]string
+	switch view {
+	case ReactionsByEmoji:
+		records = append(records, []string{"rank", "emoji", "count", "messages", "users"})
+		for i, rank := range board.Emoji {
+			records = append(records, []string{strconv.Itoa(i + 1), rank.Name, strconv.Itoa(rank.Count), strconv.Itoa(rank.Messages), strconv.Itoa(rank.Users)})
+		}
+	case ReactionsGiven, ReactionsReceived:
+		ranks := board.Given
+		if view == ReactionsReceived {
+			ranks = board.Received
+		}
+		records = append(records, []string{"rank", "user", "user_name", "count", "top_emoji"})
+		for i, rank := range ranks {
+			records = append(records, []string{strconv.Itoa(i + 1), rank.User, rank.UserName, strconv.Itoa(rank.Count), rank.TopEmoji})
+		}
+	default:
+		return fmt.Errorf("unknown reaction view '%s'", view)
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
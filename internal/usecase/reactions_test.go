@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestRankReactions(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.UTC) }
+	general := &models.ChannelExport{
+		Channel: models.ChannelInfo{Name: "general"},
+		Users:   map[string]models.ExportUser{"U1": {ID: "U1", Name: "alice", RealName: "Alice"}},
+		Messages: []models.ExportMessage{
+			{User: "U1", Timestamp: day(1), Reactions: []models.ExportReaction{
+				{Name: "thumbsup", Count: 3, Users: []string{"U1", "U2", "U3"}},
+				{Name: "tada", Count: 1, Users: []string{"U2"}},
+			}, Replies: []models.ExportMessage{
+				{User: "U2", Timestamp: day(2), Reactions: []models.ExportReaction{{Name: "thumbsup", Count: 1, Users: []string{"U1"}}}},
+			}},
+			// Broadcast links are counted with their thread
+			{User: "U2", Timestamp: day(2), InThread: true, Reactions: []models.ExportReaction{{Name: "thumbsup", Count: 1, Users: []string{"U1"}}}},
+		},
+	}
+	random := &models.ChannelExport{
+		Channel: models.ChannelInfo{Name: "random"},
+		Messages: []models.ExportMessage{
+			{User: "U3", Timestamp: day(10), Reactions: []models.ExportReaction{{Name: "tada", Count: 2, Users: []string{"U1", "U2"}}}},
+		},
+	}
+
+	board := RankReactions([]*models.ChannelExport{general, random}, nil, nil)
+	if board.Total != 7 || strings.Join(board.Channels, ",") != "general,random" {
+		t.Fatalf("Expected 7 reactions in general and random, got %d in %v", board.Total, board.Channels)
+	}
+	wantEmoji := []EmojiRank{{Name: "thumbsup", Count: 4, Messages: 2, Users: 3}, {Name: "tada", Count: 3, Messages: 2, Users: 2}}
+	if len(board.Emoji) != len(wantEmoji) {
+		t.Fatalf("Expected %v, got %v", wantEmoji, board.Emoji)
+	}
+	for i := range wantEmoji {
+		if board.Emoji[i] != wantEmoji[i] {
+			t.Errorf("Expected %v at %d, got %v", wantEmoji[i], i, board.Emoji[i])
+		}
+	}
+
+	// Ties are ranked by user ID
+	if len(board.Given) != 3 || board.Given[0].User != "U1" || board.Given[0].UserName != "Alice" || board.Given[0].Count != 3 {
+		t.Errorf("Expected U1, named from the export, to give the most reactions, got %+v", board.Given)
+	}
+	if board.Given[1].User != "U2" || board.Given[1].Count != 3 || board.Given[1].TopEmoji != "tada" {
+		t.Errorf("Expected U2 to give mostly tada, got %+v", board.Given[1])
+	}
+	// U1's reaction to their own message is not received
+	received := map[string]int{}
+	for _, rank := range board.Received {
+		received[rank.User] = rank.Count
+	}
+	if received["U1"] != 3 || received["U2"] != 1 || received["U3"] != 2 {
+		t.Errorf("Unexpected received reactions: %v", received)
+	}
+
+	from, to := day(2), day(5)
+	board = RankReactions([]*models.ChannelExport{general, random}, &from, &to)
+	if board.Total != 1 || len(board.Received) != 1 || board.Received[0].User != "U2" {
+		t.Errorf("Expected only the reply within the range, got %+v", board)
+	}
+
+	board = RankReactions(nil, nil, nil)
+	if board.Emoji == nil || board.Given == nil || board.Received == nil {
+		t.Errorf("Expected empty rankings rather than nil, got %+v", board)
+	}
+}
+
+func TestWriteReactionsCSV(t *testing.T) {
+	board := &ReactionLeaderboard{
+		Emoji:    []EmojiRank{{Name: "tada", Count: 3, Messages: 2, Users: 2}},
+		Received: []UserReactionRank{{User: "U1", UserName: "Alice, A.", Count: 3, TopEmoji: "tada"}},
+	}
+
+	var out bytes.Buffer
+	if err := WriteReactionsCSV(&out, board, ReactionsByEmoji); err != nil {
+		t.Fatalf("WriteReactionsCSV: %v", err)
+	}
+	if want := "rank,emoji,count,messages,users\n1,tada,3,2,2\n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+	if err := WriteReactionsCSV(&out, board, ReactionsReceived); err != nil {
+		t.Fatalf("WriteReactionsCSV: %v", err)
+	}
+	if want := "rank,user,user_name,count,top_emoji\n1,U1,\"Alice, A.\",3,tada\n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	board.Truncate(0)
+	if err := WriteReactionsCSV(&out, board, "favourite"); err == nil {
+		t.Error("Expected an error for an unknown view")
+	}
+}
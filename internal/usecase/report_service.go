@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// ReportClientInterface defines the Slack API operations needed for the
+// workspace reports
+type ReportClientInterface interface {
+	GetChannels(ctx context.Context) ([]models.Channel, error)
+	GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) (*models.HistoryPage, error)
+	GetChannelMembers(ctx context.Context, channelID string) ([]string, error)
+	GetUsers(ctx context.Context) ([]models.User, error)
+}
+
+// ReportService builds reports across the channels of a workspace
+type ReportService struct {
+	client ReportClientInterface
+	logger *slog.Logger
+}
+
+// NewReportService creates a new report service
+func NewReportService(client ReportClientInterface) *ReportService {
+	return &ReportService{client: client, logger: slog.Default()}
+}
+
+// SetLogger sets the logger used for channels that could not be checked
+func (s *ReportService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// inactivityPageSize is the history page fetched per channel. Only the
+// newest message matters, but joins and departures do not count as activity
+// and are skipped.
+const inactivityPageSize = 20
+
+// inactivitySubtypes are the system messages that do not make a channel
+// active
+var inactivitySubtypes = map[string]bool{
+	"channel_join":  true,
+	"channel_leave": true,
+	"group_join":    true,
+	"group_leave":   true,
+}
+
+// InactiveOptions selects the channels of an inactivity report
+type InactiveOptions struct {
+	// OlderThan is how long a channel must have been quiet to be listed
+	OlderThan time.Duration
+	// IncludeArchived also checks archived channels
+	IncludeArchived bool
+	// Now is the time inactivity is measured against
+	Now time.Time
+}
+
+// InactiveChannel is a channel of an inactivity report
+type InactiveChannel struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	IsPrivate  bool      `json:"is_private,omitempty"`
+	IsArchived bool      `json:"is_archived,omitempty"`
+	Members    int       `json:"members"`
+	Created    time.Time `json:"created"`
+	// LastMessage is the newest message other than joins and departures;
+	// nil when the channel has none
+	LastMessage *time.Time `json:"last_message,omitempty"`
+	LastUser    string     `json:"last_user,omitempty"`
+	// IdleDays counts the days since the last message, or since the
+	// channel was created when it has none
+	IdleDays int `json:"idle_days"`
+	// Error tells why the channel could not be checked
+	Error string `json:"error,omitempty"`
+}
+
+// InactiveReport lists the channels that have been quiet for longer than
+// a threshold
+type InactiveReport struct {
+	OlderThan time.Duration     `json:"older_than"`
+	Checked   int               `json:"checked"`
+	Channels  []InactiveChannel `json:"channels"`
+	// Failed are the channels whose history could not be read
+	Failed []InactiveChannel `json:"failed,omitempty"`
+}
+
+// InactiveChannels checks the newest page of history of every channel the
+// token is a member of and reports those without messages for longer than
+// opts.OlderThan, the longest idle first. progress, when set, is called
+// after each channel. A channel that cannot be read is reported in Failed
+// and does not stop the report.
+func (s *ReportService) InactiveChannels(ctx context.Context, opts InactiveOptions, progress func(done, total int)) (*InactiveReport, error) {
+	channels, err := s.client.GetChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var checked []models.Channel
+	for _, channel := range channels {
+		if channel.IsArchived && !opts.IncludeArchived || channel.IsIM {
+			continue
+		}
+		checked = append(checked, channel)
+	}
+	sort.Slice(checked, func(i, j int) bool { return checked[i].Name < checked[j].Name })
+
+	report := &InactiveReport{OlderThan: opts.OlderThan, Checked: len(checked), Channels: []InactiveChannel{}}
+	for i, channel := range checked {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		entry := InactiveChannel{
+			ID:         channel.ID,
+			Name:       channel.Name,
+			IsPrivate:  channel.IsPrivate,
+			IsArchived: channel.IsArchived,
+			Members:    channel.NumMembers,
+			Created:    time.Unix(channel.Created, 0).UTC(),
+		}
+		page, err := s.client.GetChannelHistory(ctx, channel.ID, inactivityPageSize, "")
+		if err != nil {
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+			s.logger.Warn("channel activity unavailable", "channel_id", channel.ID, "error", err)
+			entry.Error = err.Error()
+			report.Failed = append(report.Failed, entry)
+		} else {
+			last := entry.Created
+			if message := lastActivity(page); message != nil {
+				if posted, err := models.ParseSlackTimestamp(message.Timestamp); err == nil {
+					posted = posted.UTC()
+					entry.LastMessage = &posted
+					entry.LastUser = message.User
+					last = posted
+				}
+			}
+			idle := opts.Now.Sub(last)
+			entry.IdleDays = int(idle.Hours() / 24)
+			if idle >= opts.OlderThan {
+				report.Channels = append(report.Channels, entry)
+			}
+		}
+		if progress != nil {
+			progress(i+1, len(checked))
+		}
+	}
+
+	sort.SliceStable(report.Channels, func(i, j int) bool {
+		return report.Channels[i].IdleDays > report.Channels[j].IdleDays
+	})
+	return report, nil
+}
+
+// lastActivity returns the newest message of a history page that is not a
+// join or departure. When the page holds nothing else but older history
+// remains, the oldest message of the page stands in: the channel has been
+// quiet at least since then.
+func lastActivity(page *models.HistoryPage) *models.Message {
+	for i := range page.Messages {
+		if !inactivitySubtypes[page.Messages[i].Subtype] {
+			return &page.Messages[i]
+		}
+	}
+	if page.HasMore && len(page.Messages) > 0 {
+		return &page.Messages[len(page.Messages)-1]
+	}
+	return nil
+}
+
+// WriteInactiveCSV writes the channels of an inactivity report as CSV with
+// a header row
+func WriteInactiveCSV(w io.Writer, report *InactiveReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"id", "name", "private", "archived", "members", "created", "last_message", "last_user", "idle_days"}); err != nil {
+		return err
+	}
+	for _, channel := range report.Channels {
+		last := ""
+		if channel.LastMessage != nil {
+			last = channel.LastMessage.Format(time.RFC3339)
+		}
+		record := []string{
+			channel.ID,
+			channel.Name,
+			strconv.FormatBool(channel.IsPrivate),
+			strconv.FormatBool(channel.IsArchived),
+			strconv.Itoa(channel.Members),
+			channel.Created.Format(time.RFC3339),
+			last,
+			channel.LastUser,
+			strconv.Itoa(channel.IdleDays),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
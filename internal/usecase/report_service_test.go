@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockReportClient serves one history page and the members per channel
+type MockReportClient struct {
+	channels []models.Channel
+	history  map[string][]models.Message
+	limits   []int
+	members  map[string][]string
+	users    []models.User
+}
+
+func (m *MockReportClient) GetChannels(ctx context.Context) ([]models.Channel, error) {
+	return m.channels, nil
+}
+
+func (m *MockReportClient) GetChannelHistory(ctx context.Context, channelID string, limit int, cursor string) (*models.HistoryPage, error) {
+	m.limits = append(m.limits, limit)
+	messages, ok := m.history[channelID]
+	if !ok {
+		return nil, fmt.Errorf("not_in_channel")
+	}
+	return models.NewHistoryPage(messages, "", false), nil
+}
+
+func (m *MockReportClient) GetChannelMembers(ctx context.Context, channelID string) ([]string, error) {
+	members, ok := m.members[channelID]
+	if !ok {
+		return nil, fmt.Errorf("not_in_channel")
+	}
+	return members, nil
+}
+
+func (m *MockReportClient) GetUsers(ctx context.Context) ([]models.User, error) {
+	return m.users, nil
+}
+
+func TestReportService_InactiveChannels(t *testing.T) {
+	now := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	ts := func(daysAgo int) string {
+		return fmt.Sprintf("%d.000000", now.AddDate(0, 0, -daysAgo).Unix())
+	}
+	created := now.AddDate(-2, 0, 0).Unix()
+	client := &MockReportClient{
+		channels: []models.Channel{
+			{ID: "C1", Name: "busy", Created: created},
+			{ID: "C2", Name: "stale", Created: created, NumMembers: 4},
+			{ID: "C3", Name: "joins-only", Created: created},
+			{ID: "C4", Name: "empty", Created: now.AddDate(0, -1, 0).Unix()},
+			{ID: "C5", Name: "old-archive", Created: created, IsArchived: true},
+			{ID: "C6", Name: "locked", Created: created},
+		},
+		history: map[string][]models.Message{
+			"C1": {{Timestamp: ts(1), User: "U1"}},
+			// A join does not count as activity
+			"C2": {{Timestamp: ts(5), User: "U9", Subtype: "channel_join"}, {Timestamp: ts(200), User: "U2"}},
+			"C3": {{Timestamp: ts(3), User: "U9", Subtype: "channel_join"}},
+			"C4": {},
+			"C5": {{Timestamp: ts(400)}},
+		},
+	}
+
+	service := NewReportService(client)
+	var progress []int
+	report, err := service.InactiveChannels(context.Background(), InactiveOptions{OlderThan: 180 * 24 * time.Hour, Now: now}, func(done, total int) {
+		progress = append(progress, done)
+		if total != 5 {
+			t.Errorf("Expected 5 channels to check, got %d", total)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report.Checked != 5 || len(progress) != 5 || client.limits[0] != inactivityPageSize {
+		t.Errorf("Expected the archived channel skipped and one page per channel, got %d checked, %v", report.Checked, client.limits)
+	}
+
+	var names []string
+	for _, channel := range report.Channels {
+		names = append(names, channel.Name)
+	}
+	// A channel with only joins counts from its creation
+	if strings.Join(names, ",") != "joins-only,stale" {
+		t.Fatalf("Expected joins-only and stale, longest idle first, got %v", names)
+	}
+	stale := report.Channels[1]
+	if stale.IdleDays != 200 || stale.LastUser != "U2" || stale.LastMessage == nil || stale.Members != 4 {
+		t.Errorf("Unexpected stale channel: %+v", stale)
+	}
+	if report.Channels[0].LastMessage != nil || report.Channels[0].IdleDays != 731 {
+		t.Errorf("Expected joins-only to be idle since creation, got %+v", report.Channels[0])
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Name != "locked" || report.Failed[0].Error == "" {
+		t.Errorf("Expected the unreadable channel in Failed, got %+v", report.Failed)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteInactiveCSV(&buf, report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "id,name,") || !strings.HasPrefix(lines[2], "C2,stale,false,false,4,") {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestReportService_Membership(t *testing.T) {
+	client := &MockReportClient{
+		channels: []models.Channel{
+			{ID: "C1", Name: "payroll", IsPrivate: true},
+			{ID: "C2", Name: "general"},
+			{ID: "C3", Name: "old", IsArchived: true},
+			{ID: "C4", Name: "locked"},
+		},
+		members: map[string][]string{
+			"C1": {"U2"},
+			"C2": {"U1", "U2", "U3"},
+			"C3": {"U1"},
+		},
+		users: []models.User{
+			{ID: "U1", Name: "bob"},
+			{ID: "U2", Name: "alice", RealName: "Alice"},
+			{ID: "U3", Name: "deploy-bot", IsBot: true},
+		},
+	}
+	service := NewReportService(client)
+
+	report, err := service.Membership(context.Background(), MembershipOptions{}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Channels) != 2 || report.Channels[0].Name != "general" || report.Channels[1].Name != "payroll" {
+		t.Fatalf("Expected general and payroll without the archived channel, got %+v", report.Channels)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].Name != "locked" || report.Failed[0].Error == "" {
+		t.Errorf("Expected locked to fail, got %+v", report.Failed)
+	}
+	if len(report.Users) != 3 || report.Users[0].Name != "alice" {
+		t.Fatalf("Expected 3 users sorted by name, got %+v", report.Users)
+	}
+	if got := strings.Join(report.Users[0].Channels, ","); got != "general,payroll" {
+		t.Errorf("Expected alice in general and payroll, got %s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMembershipCSV(&buf, report, MembershipMatrix); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := "user_id,name,real_name,bot,deleted,general,payroll\n" +
+		"U2,alice,Alice,false,false,1,1\n" +
+		"U1,bob,,false,false,1,\n" +
+		"U3,deploy-bot,,true,false,1,\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected matrix:\n%s", buf.String())
+	}
+
+	// Named channels are reported even when archived
+	report, err = service.Membership(context.Background(), MembershipOptions{Channels: []string{"#old"}}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report.Channels) != 1 || report.Channels[0].Name != "old" || len(report.Users) != 1 {
+		t.Errorf("Expected only the old channel, got %+v", report)
+	}
+
+	if _, err := service.Membership(context.Background(), MembershipOptions{Channels: []string{"missing"}}, nil); err == nil {
+		t.Error("Expected an error for an unknown channel")
+	}
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy decides which export files of a channel are kept. A zero
+// value keeps everything.
+type RetentionPolicy struct {
+	// KeepLast is the number of newest exports kept (0 = no limit)
+	KeepLast int
+	// KeepDays removes exports older than this many days (0 = no limit)
+	KeepDays int
+}
+
+// Empty reports whether the policy keeps every export
+func (p RetentionPolicy) Empty() bool {
+	return p.KeepLast <= 0 && p.KeepDays <= 0
+}
+
+// String describes the policy for listings
+func (p RetentionPolicy) String() string {
+	var parts []string
+	if p.KeepLast > 0 {
+		parts = append(parts, fmt.Sprintf("keep last %d", p.KeepLast))
+	}
+	if p.KeepDays > 0 {
+		parts = append(parts, fmt.Sprintf("keep %d days", p.KeepDays))
+	}
+	if len(parts) == 0 {
+		return "keep all"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PruneExports removes the export files of a channel that fall outside the
+// policy and returns their paths. The newest export is always kept, and so
+// is every export an incremental backup kept in dir builds on. With dryRun
+// set nothing is removed.
+func PruneExports(dir, channelName string, policy RetentionPolicy, now time.Time, dryRun bool) ([]string, error) {
+	files, err := ListExports(dir, channelName)
+	if err != nil {
+		return nil, err
+	}
+	return pruneExports(dir, channelName, files, policy, now, dryRun, readBackupState(dir).Exports[channelName])
+}
+
+// recordedExports returns the files of recorded that still exist in dir,
+// oldest first. Exports named by an output template are only found this way.
+func recordedExports(dir string, recorded []backupExport) []string {
+	var files []string
+	for _, export := range recorded {
+		file := filepath.Join(dir, export.File)
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// pruneExports applies policy to files, the exports of a channel oldest
+// first, with the exports recorded by backup runs. Incremental exports only
+// hold the messages posted since the export before them, so a full export
+// and the deltas after it form a chain that is removed as a whole, once
+// every file of it falls outside the policy.
+func pruneExports(dir, channelName string, files []string, policy RetentionPolicy, now time.Time, dryRun bool, recorded []backupExport) ([]string, error) {
+	if policy.Empty() {
+		return nil, nil
+	}
+
+	var cutoff time.Time
+	if policy.KeepDays > 0 {
+		cutoff = now.AddDate(0, 0, -policy.KeepDays)
+	}
+	expired := func(i int) bool {
+		if policy.KeepLast > 0 && i < len(files)-policy.KeepLast {
+			return true
+		}
+		return !cutoff.IsZero() && exportTime(files[i], channelName).Before(cutoff)
+	}
+
+	deltas := make(map[string]bool)
+	for _, export := range recorded {
+		if export.Delta {
+			deltas[filepath.Join(dir, export.File)] = true
+		}
+	}
+
+	// Split the files, oldest first, into chains that start at a full export
+	var chains [][]int
+	for i, file := range files {
+		if len(chains) == 0 || !deltas[filepath.Clean(file)] {
+			chains = append(chains, nil)
+		}
+		chains[len(chains)-1] = append(chains[len(chains)-1], i)
+	}
+
+	var pruned []string
+	for _, chain := range chains[:max(len(chains)-1, 0)] {
+		remove := true
+		for _, i := range chain {
+			remove = remove && expired(i)
+		}
+		if !remove {
+			continue
+		}
+
+		for _, i := range chain {
+			if !dryRun {
+				if err := os.Remove(files[i]); err != nil {
+					return pruned, fmt.Errorf("failed to remove %s: %w", files[i], err)
+				}
+			}
+			pruned = append(pruned, files[i])
+		}
+	}
+
+	return pruned, nil
+}
+
+// chainComplete reports whether the newest chain of recorded, the last full
+// export and the deltas after it, has reached policy. The next incremental
+// backup then starts a new chain with a full export; otherwise one chain
+// would grow forever and, since the newest chain is never pruned, nothing
+// would ever be removed.
+func chainComplete(dir, channelName string, recorded []backupExport, policy RetentionPolicy, now time.Time) bool {
+	if policy.Empty() || len(recorded) == 0 {
+		return false
+	}
+	start := len(recorded) - 1
+	for start > 0 && recorded[start].Delta {
+		start--
+	}
+	chain := recorded[start:]
+	if policy.KeepLast > 0 && len(chain) >= policy.KeepLast {
+		return true
+	}
+	return policy.KeepDays > 0 && exportTime(filepath.Join(dir, chain[0].File), channelName).Before(now.AddDate(0, 0, -policy.KeepDays))
+}
+
+// exportTime returns when an export was written, from the timestamp in its
+// file name or, failing that, its modification time
+func exportTime(file, channelName string) time.Time {
+	name := strings.TrimPrefix(filepath.Base(file), exportPrefix(channelName))
+	if len(name) >= len("20060102-150405") {
+		if t, err := time.ParseInLocation("20060102-150405", name[:len("20060102-150405")], time.Local); err == nil {
+			return t
+		}
+	}
+	if info, err := os.Stat(file); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRateLimitRetries is how often a request to an HTTP API other than
+// Slack's is retried when it is answered with HTTP 429, and maxRetryAfter
+// caps the wait before each retry
+const (
+	maxRateLimitRetries = 3
+	maxRetryAfter       = time.Minute
+)
+
+// doWithRetry sends the request built by newRequest and retries it while the
+// server answers HTTP 429, waiting as long as its Retry-After header asks or
+// backing off exponentially from a second without one. newRequest is called
+// for every attempt, since a request body can only be sent once.
+func doWithRetry(ctx context.Context, client *http.Client, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRateLimitRetries {
+			return resp, err
+		}
+		wait := retryAfter(resp, backoff)
+		resp.Body.Close()
+		backoff *= 2
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter returns the wait a response asks for in its Retry-After header,
+// in seconds or as a date, capped at maxRetryAfter; fallback without one
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	wait := fallback
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = max(time.Until(at), 0)
+	}
+	return min(wait, maxRetryAfter)
+}
@@ -0,0 +1,306 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/internal/storage"
+	"github.com/itcaat/slacker/models"
+)
+
+// LayoutSlackNative writes an export in the directory layout of Slack's own
+// workspace exports: users.json, channels.json and one file per channel and
+// day
+const LayoutSlackNative = "slack-native"
+
+// nativeMessage is a message as it appears in the day files of a Slack
+// export. Thread replies are separate messages linked by thread_ts.
+type nativeMessage struct {
+	Type            string                  `json:"type"`
+	Subtype         string                  `json:"subtype,omitempty"`
+	User            string                  `json:"user,omitempty"`
+	Text            string                  `json:"text"`
+	TS              string                  `json:"ts"`
+	ThreadTS        string                  `json:"thread_ts,omitempty"`
+	ParentUserID    string                  `json:"parent_user_id,omitempty"`
+	ReplyCount      int                     `json:"reply_count,omitempty"`
+	ReplyUsersCount int                     `json:"reply_users_count,omitempty"`
+	LatestReply     string                  `json:"latest_reply,omitempty"`
+	ReplyUsers      []string                `json:"reply_users,omitempty"`
+	Replies         []nativeReply           `json:"replies,omitempty"`
+	Edited          *nativeEdit             `json:"edited,omitempty"`
+	UserProfile     *nativeUserProfile      `json:"user_profile,omitempty"`
+	Attachments     []nativeAttachment      `json:"attachments,omitempty"`
+	Files           []nativeFile            `json:"files,omitempty"`
+	Reactions       []models.ExportReaction `json:"reactions,omitempty"`
+	ClientMsgID     string                  `json:"client_msg_id,omitempty"`
+
+	posted time.Time
+}
+
+// nativeReply lists a reply on its thread parent
+type nativeReply struct {
+	User string `json:"user"`
+	TS   string `json:"ts"`
+}
+
+// nativeEdit records the last edit of a message
+type nativeEdit struct {
+	User string `json:"user"`
+	TS   string `json:"ts"`
+}
+
+// nativeUserProfile is the author summary Slack embeds in each message
+type nativeUserProfile struct {
+	Image72     string `json:"image_72,omitempty"`
+	RealName    string `json:"real_name"`
+	DisplayName string `json:"display_name"`
+	Team        string `json:"team,omitempty"`
+	Name        string `json:"name"`
+}
+
+// nativeAttachment and nativeFile use Slack's numeric IDs and Unix times in
+// place of the export's strings and RFC 3339 times
+type nativeAttachment struct {
+	models.ExportAttachment
+	ID int   `json:"id,omitempty"`
+	TS int64 `json:"ts,omitempty"`
+}
+
+type nativeFile struct {
+	models.ExportFile
+	Created   int64 `json:"created,omitempty"`
+	Timestamp int64 `json:"timestamp,omitempty"`
+}
+
+// nativeTopic is the topic or purpose of a channel in channels.json
+type nativeTopic struct {
+	Value   string `json:"value"`
+	Creator string `json:"creator"`
+	LastSet int64  `json:"last_set"`
+}
+
+// nativeChannel is a channel in channels.json
+type nativeChannel struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Created    int64       `json:"created"`
+	Creator    string      `json:"creator"`
+	IsArchived bool        `json:"is_archived"`
+	IsGeneral  bool        `json:"is_general"`
+	Members    []string    `json:"members"`
+	Topic      nativeTopic `json:"topic"`
+	Purpose    nativeTopic `json:"purpose"`
+}
+
+// nativeMessages converts messages to Slack's flat message list, replies
+// next to their parents in chronological order
+func nativeMessages(messages []models.ExportMessage, users map[string]models.ExportUser) []nativeMessage {
+	var native []nativeMessage
+	for _, msg := range messages {
+		// The broadcast reply itself is written among the messages
+		if msg.InThread {
+			continue
+		}
+		parent := nativeMessageOf(msg, users)
+		for _, reply := range msg.Replies {
+			parent.Replies = append(parent.Replies, nativeReply{User: reply.User, TS: reply.ID})
+		}
+		native = append(native, parent)
+		for _, reply := range msg.Replies {
+			converted := nativeMessageOf(reply, users)
+			if converted.ThreadTS == "" {
+				converted.ThreadTS = msg.ID
+			}
+			if converted.ParentUserID == "" {
+				converted.ParentUserID = msg.User
+			}
+			native = append(native, converted)
+		}
+	}
+	sort.SliceStable(native, func(i, j int) bool {
+		return native[i].posted.Before(native[j].posted)
+	})
+	return native
+}
+
+// nativeMessageOf converts one message without its replies
+func nativeMessageOf(msg models.ExportMessage, users map[string]models.ExportUser) nativeMessage {
+	native := nativeMessage{
+		Type:            msg.Type,
+		Subtype:         msg.Subtype,
+		User:            msg.User,
+		Text:            msg.Text,
+		TS:              msg.ID,
+		ThreadTS:        msg.ThreadTimestamp,
+		ParentUserID:    msg.ParentUserID,
+		ReplyCount:      msg.ReplyCount,
+		ReplyUsersCount: msg.ReplyUsersCount,
+		ReplyUsers:      msg.ReplyUsers,
+		Reactions:       msg.Reactions,
+		ClientMsgID:     msg.ClientMsgID,
+		posted:          msg.Timestamp,
+	}
+	if native.Type == "" {
+		native.Type = "message"
+	}
+	if msg.LatestReply != nil {
+		native.LatestReply = FormatSlackTimestamp(*msg.LatestReply)
+	}
+	if msg.Edited != nil {
+		native.Edited = &nativeEdit{User: msg.Edited.User, TS: FormatSlackTimestamp(msg.Edited.Timestamp)}
+	}
+	if user, ok := users[msg.User]; ok {
+		native.UserProfile = &nativeUserProfile{
+			Image72:     user.Profile.Image72,
+			RealName:    user.RealName,
+			DisplayName: user.Profile.DisplayName,
+			Team:        user.TeamID,
+			Name:        user.Name,
+		}
+	}
+	for _, attachment := range msg.Attachments {
+		converted := nativeAttachment{ExportAttachment: attachment}
+		converted.ID, _ = strconv.Atoi(attachment.ID)
+		if attachment.Timestamp != nil {
+			converted.TS = attachment.Timestamp.Unix()
+		}
+		native.Attachments = append(native.Attachments, converted)
+	}
+	for _, file := range msg.Files {
+		converted := nativeFile{ExportFile: file}
+		if !file.Timestamp.IsZero() {
+			converted.Created = file.Timestamp.Unix()
+			converted.Timestamp = file.Timestamp.Unix()
+		}
+		native.Files = append(native.Files, converted)
+	}
+	return native
+}
+
+// nativeChannelOf converts the channel of an export for channels.json
+func nativeChannelOf(channel models.ChannelInfo) nativeChannel {
+	native := nativeChannel{
+		ID:         channel.ID,
+		Name:       channel.Name,
+		Creator:    channel.Creator,
+		IsArchived: channel.IsArchived,
+		IsGeneral:  channel.Name == "general",
+		Members:    channel.Members,
+		Topic:      nativeTopic{Value: channel.Topic},
+		Purpose:    nativeTopic{Value: channel.Purpose},
+	}
+	if !channel.CreatedAt.IsZero() {
+		native.Created = channel.CreatedAt.Unix()
+	}
+	if native.Members == nil {
+		native.Members = []string{}
+	}
+	return native
+}
+
+// generateNativeOutput writes exportData in the Slack export layout below
+// the options.OutputFile directory: <channel>/YYYY-MM-DD.json with the
+// messages posted that day, users.json and channels.json. Local users.json
+// and channels.json files are merged with what is already there, so several
+// channels can be exported into one directory. It returns the directory,
+// the total size written and the files.
+func (s *ExportService) generateNativeOutput(ctx context.Context, exportData models.ChannelExport, options models.ExportOptions) (string, int64, []string, error) {
+	if options.OutputFile == StdoutOutput {
+		return "", 0, nil, fmt.Errorf("slack-native exports cannot be written to stdout")
+	}
+	root := strings.TrimSuffix(strings.TrimSuffix(options.OutputFile, "/"), ".json")
+	channelName := exportData.Channel.Name
+	if channelName == "" {
+		channelName = exportData.Channel.ID
+	}
+
+	var totalSize int64
+	var files []string
+	write := func(name string, v interface{}) error {
+		data, err := marshalExport(v, options.Format)
+		if err != nil {
+			return err
+		}
+		file, size, err := s.writeOutput(ctx, storage.Join(root, name), data, options)
+		if err != nil {
+			return err
+		}
+		totalSize += size
+		files = append(files, file)
+		return nil
+	}
+
+	messages := nativeMessages(exportData.Messages, exportData.Users)
+	for start := 0; start < len(messages); {
+		day := messages[start].posted.Format("2006-01-02")
+		end := start
+		for end < len(messages) && messages[end].posted.Format("2006-01-02") == day {
+			end++
+		}
+		if err := write(channelName+"/"+day+".json", messages[start:end]); err != nil {
+			return "", 0, files, err
+		}
+		start = end
+	}
+
+	users := make(map[string]models.ExportUser)
+	channels := make(map[string]nativeChannel)
+	if IsLocalOutput(root) {
+		if err := readNativeList(storage.Join(root, "users.json"), func(user models.ExportUser) { users[user.ID] = user }); err != nil {
+			return "", 0, files, err
+		}
+		if err := readNativeList(storage.Join(root, "channels.json"), func(channel nativeChannel) { channels[channel.ID] = channel }); err != nil {
+			return "", 0, files, err
+		}
+	}
+	for id, user := range exportData.Users {
+		users[id] = user
+	}
+	channels[exportData.Channel.ID] = nativeChannelOf(exportData.Channel)
+
+	userList := make([]models.ExportUser, 0, len(users))
+	for _, user := range users {
+		userList = append(userList, user)
+	}
+	sort.Slice(userList, func(i, j int) bool { return userList[i].ID < userList[j].ID })
+	channelList := make([]nativeChannel, 0, len(channels))
+	for _, channel := range channels {
+		channelList = append(channelList, channel)
+	}
+	sort.Slice(channelList, func(i, j int) bool { return channelList[i].Name < channelList[j].Name })
+
+	if err := write("users.json", userList); err != nil {
+		return "", 0, files, err
+	}
+	if err := write("channels.json", channelList); err != nil {
+		return "", 0, files, err
+	}
+	return root, totalSize, files, nil
+}
+
+// readNativeList calls add for every entry of the JSON array in file. A
+// missing file is an empty list.
+func readNativeList[T any](file string, add func(T)) error {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	var entries []T
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	for _, entry := range entries {
+		add(entry)
+	}
+	return nil
+}
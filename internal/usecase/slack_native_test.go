@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestExportService_ExportChannelSlackNative(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "slack-export")
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+
+	options := models.ExportOptions{
+		ChannelID:        "C123456",
+		IncludeThreads:   true,
+		IncludeReactions: true,
+		OutputFile:       dir,
+		Format:           "json",
+		Layout:           LayoutSlackNative,
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.OutputFile != dir || len(result.Parts) != 3 {
+		t.Fatalf("Expected a day file, users.json and channels.json in %s, got %s with %v", dir, result.OutputFile, result.Parts)
+	}
+
+	posted, _ := models.ParseSlackTimestamp("1704067200.123456")
+	day := filepath.Join(dir, "general", posted.Format("2006-01-02")+".json")
+	var messages []map[string]interface{}
+	readJSON(t, day, &messages)
+	if len(messages) < 3 {
+		t.Fatalf("Expected messages and flat replies in %s, got %v", day, messages)
+	}
+	parent, reply := messages[1], messages[2]
+	if parent["ts"] != "1704067260.000000" || parent["type"] != "message" || parent["user_profile"] == nil {
+		t.Errorf("Expected the thread parent with a user profile, got %v", parent)
+	}
+	if replies, ok := parent["replies"].([]interface{}); !ok || len(replies) == 0 {
+		t.Errorf("Expected the parent to list its replies, got %v", parent["replies"])
+	}
+	if reply["thread_ts"] != "1704067260.000000" || reply["parent_user_id"] != "U789012" {
+		t.Errorf("Expected a flat reply linked to its parent, got %v", reply)
+	}
+	if reactions, ok := messages[0]["reactions"].([]interface{}); !ok || len(reactions) != 1 {
+		t.Errorf("Expected Slack-shaped reactions, got %v", messages[0]["reactions"])
+	}
+
+	var users []models.ExportUser
+	readJSON(t, filepath.Join(dir, "users.json"), &users)
+	if len(users) == 0 || users[0].ID == "" {
+		t.Errorf("Expected users in users.json, got %v", users)
+	}
+
+	// A second channel in the same directory adds to channels.json
+	channels := []map[string]interface{}{{"id": "C999", "name": "random"}}
+	data, _ := json.Marshal(channels)
+	if err := os.WriteFile(filepath.Join(dir, "channels.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ExportChannel(context.Background(), options, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	readJSON(t, filepath.Join(dir, "channels.json"), &channels)
+	if len(channels) != 2 || channels[0]["name"] != "general" || channels[0]["is_general"] != true || channels[1]["id"] != "C999" {
+		t.Errorf("Expected general merged with random, got %v", channels)
+	}
+}
+
+func readJSON(t *testing.T, file string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", file, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Failed to parse %s: %v", file, err)
+	}
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// SplitSpec describes how an export is divided into several files
+type SplitSpec struct {
+	// Period is "month" or "day" for calendar splits
+	Period string
+	// MaxBytes limits the uncompressed size of each part for size splits
+	MaxBytes int64
+}
+
+// ParseSplitBy parses a --split-by value: month, day or size=<n>[KB|MB|GB]
+func ParseSplitBy(value string) (SplitSpec, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "month", "day":
+		return SplitSpec{Period: value}, nil
+	}
+
+	if size, ok := strings.CutPrefix(value, "size="); ok {
+		multiplier := int64(1)
+		for suffix, m := range map[string]int64{"kb": 1 << 10, "mb": 1 << 20, "gb": 1 << 30} {
+			if strings.HasSuffix(size, suffix) {
+				size, multiplier = strings.TrimSuffix(size, suffix), m
+				break
+			}
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+		if err == nil && n > 0 {
+			return SplitSpec{MaxBytes: n * multiplier}, nil
+		}
+	}
+
+	return SplitSpec{}, fmt.Errorf("invalid split '%s'. Use month, day or size=<n>MB", value)
+}
+
+// splitPart is a group of messages written to one part file
+type splitPart struct {
+	key      string
+	messages []models.ExportMessage
+}
+
+// splitMessages groups messages into parts. Thread replies always stay with
+// their parent. Size splits measure messages as they are written in format.
+func splitMessages(messages []models.ExportMessage, spec SplitSpec, format string) ([]splitPart, error) {
+	var parts []splitPart
+
+	if spec.MaxBytes > 0 {
+		var size int64
+		for _, msg := range messages {
+			n, err := messageSize(msg, format)
+			if err != nil {
+				return nil, fmt.Errorf("failed to measure message %s: %w", msg.ID, err)
+			}
+			if len(parts) == 0 || (size+n > spec.MaxBytes && len(parts[len(parts)-1].messages) > 0) {
+				parts = append(parts, splitPart{key: fmt.Sprintf("part-%03d", len(parts)+1)})
+				size = 0
+			}
+			parts[len(parts)-1].messages = append(parts[len(parts)-1].messages, msg)
+			size += n
+		}
+		return parts, nil
+	}
+
+	layout := "2006-01"
+	if spec.Period == "day" {
+		layout = "2006-01-02"
+	}
+	for _, msg := range messages {
+		key := msg.Timestamp.Format(layout)
+		if len(parts) == 0 || parts[len(parts)-1].key != key {
+			parts = append(parts, splitPart{key: key})
+		}
+		parts[len(parts)-1].messages = append(parts[len(parts)-1].messages, msg)
+	}
+	return parts, nil
+}
+
+// messageIndent is the indentation of a message in the messages array of
+// indented JSON exports
+const messageIndent = "    "
+
+// messageSize returns the bytes msg adds to a file written in format by
+// marshalExport: the message with its indentation and the separator before
+// it in the messages array
+func messageSize(msg models.ExportMessage, format string) (int64, error) {
+	if format == "json-compact" {
+		data, err := json.Marshal(msg)
+		return int64(len(data) + len(",")), err
+	}
+	data, err := json.MarshalIndent(msg, messageIndent, "  ")
+	return int64(len(",\n") + len(messageIndent) + len(data)), err
+}
+
+// generateSplitOutput writes one file per part and an index file listing the
+// parts with the statistics of the whole export. It returns the index path,
+// the total size written and the part paths.
+func (s *ExportService) generateSplitOutput(ctx context.Context, exportData models.ChannelExport, options models.ExportOptions, spec SplitSpec) (string, int64, []string, error) {
+	if options.OutputFile == StdoutOutput {
+		return "", 0, nil, fmt.Errorf("split exports cannot be written to stdout")
+	}
+	parts, err := splitMessages(exportData.Messages, spec, options.Format)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	base := strings.TrimSuffix(options.OutputFile, ".json")
+	index := models.ExportIndex{
+		ExportInfo: exportData.ExportInfo,
+		Channel:    exportData.Channel,
+		SplitBy:    options.SplitBy,
+		Statistics: exportData.Statistics,
+		Changes:    exportData.Changes,
+		Parts:      []models.ExportPart{},
+	}
+	index.ExportInfo.SchemaURL = models.IndexSchemaURL
+
+	var totalSize int64
+	var files []string
+	for _, part := range parts {
+		partData := models.ChannelExport{
+			ExportInfo: exportData.ExportInfo,
+			Channel:    exportData.Channel,
+			Messages:   part.messages,
+			Users:      partUsers(part.messages, exportData.Users),
+			Statistics: partStatistics(part.messages),
+			Emoji:      exportData.Emoji,
+		}
+
+		partOptions := options
+		partOptions.OutputFile = base + "-" + part.key + ".json"
+		outputFile, size, err := s.generateOutputFile(ctx, partData, partOptions)
+		if err != nil {
+			return "", 0, files, err
+		}
+
+		totalSize += size
+		files = append(files, outputFile)
+		index.Parts = append(index.Parts, models.ExportPart{
+			File:     path.Base(strings.ReplaceAll(outputFile, "\\", "/")),
+			From:     part.messages[0].Timestamp,
+			To:       part.messages[len(part.messages)-1].Timestamp,
+			Messages: partData.Statistics.TotalMessages,
+			FileSize: size,
+		})
+	}
+
+	indexData, err := marshalExport(index, options.Format)
+	if err != nil {
+		return "", 0, files, err
+	}
+	indexFile, size, err := s.writeOutput(ctx, base+"-index.json", indexData, options)
+	if err != nil {
+		return "", 0, files, err
+	}
+
+	return indexFile, totalSize + size, files, nil
+}
+
+// partUsers returns the users who wrote a message or reply in the part
+func partUsers(messages []models.ExportMessage, users map[string]models.ExportUser) map[string]models.ExportUser {
+	result := make(map[string]models.ExportUser)
+	add := func(id string) {
+		if user, ok := users[id]; ok {
+			result[id] = user
+		}
+	}
+	for _, msg := range messages {
+		add(msg.User)
+		for _, reply := range msg.Replies {
+			add(reply.User)
+		}
+	}
+	return result
+}
+
+// partStatistics counts the messages, threads and replies of a part
+func partStatistics(messages []models.ExportMessage) models.ExportStatistics {
+	var stats models.ExportStatistics
+	for _, msg := range messages {
+		// Broadcast links are counted as replies in their thread
+		if msg.InThread {
+			continue
+		}
+		// Omitted parents count by their thread only
+		if !msg.Omitted {
+			stats.TotalMessages++
+		}
+		if len(msg.Replies) > 0 {
+			stats.TotalThreads++
+			stats.TotalReplies += len(msg.Replies)
+			stats.TotalMessages += len(msg.Replies)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestParseSplitBy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    SplitSpec
+		wantErr bool
+	}{
+		{value: "month", want: SplitSpec{Period: "month"}},
+		{value: "Day", want: SplitSpec{Period: "day"}},
+		{value: "size=100MB", want: SplitSpec{MaxBytes: 100 << 20}},
+		{value: "size=512kb", want: SplitSpec{MaxBytes: 512 << 10}},
+		{value: "size=2048", want: SplitSpec{MaxBytes: 2048}},
+		{value: "size=0MB", wantErr: true},
+		{value: "week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSplitBy(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSplitBy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSplitBy(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSplitMessagesBySize(t *testing.T) {
+	var messages []models.ExportMessage
+	for i := 0; i < 5; i++ {
+		messages = append(messages, models.ExportMessage{ID: "1.0", Text: "0123456789"})
+	}
+	size, err := messageSize(messages[0], "json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	parts, err := splitMessages(messages, SplitSpec{MaxBytes: size * 2}, "json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(parts) != 3 || len(parts[0].messages) != 2 || len(parts[2].messages) != 1 {
+		t.Errorf("Expected parts of 2, 2 and 1 messages, got %d parts", len(parts))
+	}
+	if parts[0].key != "part-001" {
+		t.Errorf("Expected key part-001, got %s", parts[0].key)
+	}
+}
+
+func TestMessageSize(t *testing.T) {
+	msg := models.ExportMessage{
+		ID: "1704067200.000000", User: "U1", Text: "incident review",
+		Replies: []models.ExportMessage{{ID: "1704067260.000000", User: "U2", Text: "on it"}},
+	}
+
+	// A message adds exactly its measured size to the written file
+	for _, format := range []string{"json", "json-pretty", "json-compact"} {
+		one, err := marshalExport(models.ChannelExport{Messages: []models.ExportMessage{msg}}, format)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		two, err := marshalExport(models.ChannelExport{Messages: []models.ExportMessage{msg, msg}}, format)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		size, err := messageSize(msg, format)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if want := int64(len(two) - len(one)); size != want {
+			t.Errorf("%s: expected message size %d, got %d", format, want, size)
+		}
+	}
+}
+
+func TestExportService_ExportChannelSplitByMonth(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	// Move the second message (and its thread) into February
+	mockClient.messages[1].Timestamp = "1707000000.000000"
+	service := NewExportService(mockClient, "1.0.0-test")
+
+	dir := t.TempDir()
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(dir, "general.json"),
+		Format:         "json",
+		SplitBy:        "month",
+		Location:       time.UTC,
+	}
+
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.OutputFile != filepath.Join(dir, "general-index.json") || len(result.Parts) != 2 {
+		t.Fatalf("Expected index and 2 parts, got %s and %v", result.OutputFile, result.Parts)
+	}
+
+	data, err := os.ReadFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Expected index file, got %v", err)
+	}
+	var index models.ExportIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("Failed to parse index: %v", err)
+	}
+	if len(index.Parts) != 2 || index.Parts[0].File != "general-2024-01.json" || index.Parts[1].File != "general-2024-02.json" {
+		t.Errorf("Unexpected parts: %+v", index.Parts)
+	}
+	if index.Statistics.TotalMessages != 4 || index.Parts[1].Messages != 3 {
+		t.Errorf("Expected whole-range statistics in index, got %d total and %d in February", index.Statistics.TotalMessages, index.Parts[1].Messages)
+	}
+
+	part, err := ReadExportFile(filepath.Join(dir, "general-2024-02.json"))
+	if err != nil {
+		t.Fatalf("Failed to read part: %v", err)
+	}
+	if len(part.Messages) != 1 || len(part.Messages[0].Replies) != 2 {
+		t.Errorf("Expected February part with the thread, got %+v", part.Messages)
+	}
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// ParseSubtypePolicy parses "subtype=action" entries such as
+// channel_join=exclude or channel_topic=transform
+func ParseSubtypePolicy(entries []string) (map[string]string, error) {
+	policy := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		subtype, action, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid subtype policy '%s': use subtype=include|exclude|transform", entry)
+		}
+		policy[strings.TrimSpace(subtype)] = strings.TrimSpace(action)
+	}
+	if err := ValidateSubtypePolicy(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ValidateSubtypePolicy checks the actions of a subtype policy
+func ValidateSubtypePolicy(policy map[string]string) error {
+	for subtype, action := range policy {
+		if subtype == "" {
+			return fmt.Errorf("subtype policy has an empty subtype")
+		}
+		switch action {
+		case models.SubtypeInclude, models.SubtypeExclude, models.SubtypeTransform:
+		default:
+			return fmt.Errorf("invalid action '%s' for subtype %s: use include, exclude or transform", action, subtype)
+		}
+	}
+	return nil
+}
+
+// MergeSubtypePolicy returns base with the entries of override applied on top
+func MergeSubtypePolicy(base, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for subtype, action := range base {
+		merged[subtype] = action
+	}
+	for subtype, action := range override {
+		merged[subtype] = action
+	}
+	return merged
+}
+
+// excludedSubtypes combines --exclude-subtype with the excluded subtypes of
+// the policy
+func excludedSubtypes(options models.ExportOptions) []string {
+	excluded := append([]string(nil), options.ExcludeSubtypes...)
+	for subtype, action := range options.SubtypePolicy {
+		if action == models.SubtypeExclude {
+			excluded = append(excluded, subtype)
+		}
+	}
+	sort.Strings(excluded)
+	return excluded
+}
+
+// transformSystemMessages rewrites messages whose subtype the policy
+// transforms as compact system events: Slack markup in the text becomes
+// plain names and attachments, files and reactions are dropped
+func transformSystemMessages(messages []models.ExportMessage, policy map[string]string, users map[string]models.ExportUser, groups map[string]models.UserGroup) {
+	if len(policy) == 0 {
+		return
+	}
+	for i := range messages {
+		msg := &messages[i]
+		if msg.Subtype != "" && policy[msg.Subtype] == models.SubtypeTransform {
+			msg.System = true
+			msg.Text = plainText(msg.Text, users, groups)
+			msg.Attachments = nil
+			msg.Files = nil
+			msg.Reactions = nil
+		}
+		transformSystemMessages(msg.Replies, policy, users, groups)
+	}
+}
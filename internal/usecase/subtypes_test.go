@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestParseSubtypePolicy(t *testing.T) {
+	policy, err := ParseSubtypePolicy([]string{"channel_join=exclude", " channel_topic = transform"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if policy["channel_join"] != models.SubtypeExclude || policy["channel_topic"] != models.SubtypeTransform {
+		t.Errorf("Unexpected policy %v", policy)
+	}
+
+	for _, entry := range []string{"channel_join", "channel_join=hide", "=exclude"} {
+		if _, err := ParseSubtypePolicy([]string{entry}); err == nil {
+			t.Errorf("Expected error for %q", entry)
+		}
+	}
+}
+
+func TestExportService_SubtypePolicy(t *testing.T) {
+	client := NewMockSlackClient()
+	client.messages = append(client.messages,
+		models.Message{Type: "message", Subtype: "channel_join", User: "U123456", Text: "<@U123456> has joined the channel", Timestamp: "1704067400.000000"},
+		models.Message{Type: "message", Subtype: "channel_topic", User: "U123456", Text: "<@U123456> set the channel topic: Releases", Timestamp: "1704067500.000000"},
+	)
+	service := NewExportService(client, "1.0.0-test")
+
+	result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:     "C123456",
+		OutputFile:    filepath.Join(t.TempDir(), "general.json"),
+		Format:        "json",
+		SubtypePolicy: map[string]string{"channel_join": models.SubtypeExclude, "channel_topic": models.SubtypeTransform},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+
+	var topic *models.ExportMessage
+	for i, msg := range data.Messages {
+		switch msg.Subtype {
+		case "channel_join":
+			t.Error("Expected channel_join to be excluded")
+		case "channel_topic":
+			topic = &data.Messages[i]
+		}
+	}
+	if topic == nil {
+		t.Fatal("Expected the channel_topic message")
+	}
+	if !topic.System || topic.Text != "@Alice set the channel topic: Releases" {
+		t.Errorf("Expected a transformed system message, got system=%v text=%q", topic.System, topic.Text)
+	}
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// SummaryClientInterface defines the operations needed to summarize
+// conversation transcripts
+type SummaryClientInterface interface {
+	Summarize(ctx context.Context, instructions, transcript string) (string, error)
+	Model() string
+}
+
+// maxSummaryTokens caps the estimated size of the transcript sent in one
+// summary request; later messages are left out
+const maxSummaryTokens = 12000
+
+// summaryInstructions is the system prompt of every summary request
+const summaryInstructions = "You summarize Slack conversations for an archive. Summarize the following conversation from #%s in a few sentences or bullet points. " +
+	"Name decisions, action items with their owners and open questions. Only use information from the conversation."
+
+// SetSummaryClient enables ExportOptions.SummarizeBy
+func (s *ExportService) SetSummaryClient(client SummaryClientInterface) {
+	s.summaryClient = client
+}
+
+// summaryGroup is a day or thread of messages to summarize
+type summaryGroup struct {
+	summary models.ExportSummary
+	lines   []llmLine
+}
+
+// summarize writes one summary per day or thread. Failed requests are
+// returned as warnings; the remaining groups are still summarized.
+func (s *ExportService) summarize(ctx context.Context, exportData models.ChannelExport, by string) ([]models.ExportSummary, []string, error) {
+	if s.summaryClient == nil {
+		return nil, nil, fmt.Errorf("no summary client configured")
+	}
+
+	var groups []*summaryGroup
+	byDate := make(map[string]*summaryGroup)
+	for _, msg := range exportData.Messages {
+		if msg.InThread {
+			continue
+		}
+		var lines []llmLine
+		for _, m := range append([]models.ExportMessage{msg}, msg.Replies...) {
+			if line, ok := renderLLMLine(m, exportData, true); ok {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		switch by {
+		case models.SummarizeByThread:
+			if len(msg.Replies) == 0 {
+				continue
+			}
+			groups = append(groups, &summaryGroup{summary: models.ExportSummary{Scope: by, ThreadTS: msg.ID}, lines: lines})
+		default:
+			date := msg.Timestamp.Format("2006-01-02")
+			group, ok := byDate[date]
+			if !ok {
+				group = &summaryGroup{summary: models.ExportSummary{Scope: models.SummarizeByDay, Date: date}}
+				byDate[date] = group
+				groups = append(groups, group)
+			}
+			group.lines = append(group.lines, lines...)
+		}
+	}
+
+	instructions := fmt.Sprintf(summaryInstructions, exportData.Channel.Name)
+	var summaries []models.ExportSummary
+	var warnings []string
+	for _, group := range groups {
+		if err := ctx.Err(); err != nil {
+			return summaries, warnings, err
+		}
+
+		// The summary covers the messages that fit in the transcript
+		transcript, sent := summaryTranscript(group.lines)
+		summary := group.summary
+		summary.Messages = sent
+		summary.Start, summary.End = group.lines[0].at, group.lines[0].at
+		for _, line := range group.lines[:sent] {
+			if line.at.Before(summary.Start) {
+				summary.Start = line.at
+			}
+			if line.at.After(summary.End) {
+				summary.End = line.at
+			}
+		}
+
+		text, err := s.summaryClient.Summarize(ctx, instructions, transcript)
+		if err != nil {
+			name := summary.Date
+			if summary.ThreadTS != "" {
+				name = "thread " + summary.ThreadTS
+			}
+			warnings = append(warnings, fmt.Sprintf("summary of %s failed: %v", name, err))
+			continue
+		}
+		summary.Summary = strings.TrimSpace(text)
+		summary.Model = s.summaryClient.Model()
+		summaries = append(summaries, summary)
+	}
+	return summaries, warnings, nil
+}
+
+// summaryTranscript joins lines up to maxSummaryTokens and returns how many
+// of them it holds
+func summaryTranscript(lines []llmLine) (string, int) {
+	var b strings.Builder
+	tokens := 0
+	for i, line := range lines {
+		if tokens+line.tokens > maxSummaryTokens && i > 0 {
+			fmt.Fprintf(&b, "[%d more messages omitted]\n", len(lines)-i)
+			return b.String(), i
+		}
+		b.WriteString(line.text)
+		b.WriteByte('\n')
+		tokens += line.tokens
+	}
+	return b.String(), len(lines)
+}
+
+// IsOpenAIEndpoint reports whether endpoint is OpenAI's own API, the only
+// endpoint the OPENAI_API_KEY environment variable is sent to
+func IsOpenAIEndpoint(endpoint string) bool {
+	parsed, err := url.Parse(endpoint)
+	return err == nil && parsed.Scheme == "https" && strings.EqualFold(parsed.Hostname(), "api.openai.com")
+}
+
+// LLMClient summarizes transcripts with an OpenAI-compatible chat
+// completions endpoint
+type LLMClient struct {
+	httpClient *http.Client
+	endpoint   string
+	apiKey     string
+	model      string
+}
+
+// NewLLMClient creates a client for endpoint, which is either an API base
+// URL such as https://api.openai.com/v1 or the full /chat/completions URL.
+// An empty apiKey sends no Authorization header, as local servers expect.
+func NewLLMClient(endpoint, apiKey, model string) *LLMClient {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/chat/completions") {
+		endpoint += "/chat/completions"
+	}
+	return &LLMClient{
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+		endpoint:   endpoint,
+		apiKey:     apiKey,
+		model:      model,
+	}
+}
+
+// SetTransport sets the transport requests to the endpoint go through
+func (c *LLMClient) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
+// Model returns the model summaries are requested from
+func (c *LLMClient) Model() string {
+	return c.model
+}
+
+// chatMessage is a message of a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatResponse is the part of a chat completions response slacker reads
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Summarize sends the instructions and transcript as a chat completion and
+// returns the reply
+func (c *LLMClient) Summarize(ctx context.Context, instructions, transcript string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": c.model,
+		"messages": []chatMessage{
+			{Role: "system", Content: instructions},
+			{Role: "user", Content: transcript},
+		},
+		"temperature": 0.2,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal summary request: %w", err)
+	}
+
+	// Rate-limited requests are retried after the wait the endpoint asks for
+	resp, err := doWithRetry(ctx, c.httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create summary request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("summary request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read summary response: %w", err)
+	}
+	var parsed chatResponse
+	if err := json.Unmarshal(data, &parsed); err != nil && resp.StatusCode < 300 {
+		return "", fmt.Errorf("invalid summary response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		if parsed.Error != nil && parsed.Error.Message != "" {
+			return "", fmt.Errorf("summary endpoint returned %s: %s", resp.Status, parsed.Error.Message)
+		}
+		return "", fmt.Errorf("summary endpoint returned %s", resp.Status)
+	}
+	if len(parsed.Choices) == 0 || parsed.Choices[0].Message.Content == "" {
+		return "", fmt.Errorf("summary endpoint returned no choices")
+	}
+	return parsed.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockSummaryClient records transcripts and answers with their line count
+type MockSummaryClient struct {
+	transcripts []string
+	fail        bool
+}
+
+func (m *MockSummaryClient) Summarize(ctx context.Context, instructions, transcript string) (string, error) {
+	m.transcripts = append(m.transcripts, transcript)
+	if m.fail {
+		return "", errors.New("endpoint unavailable")
+	}
+	return fmt.Sprintf("summary of %d lines\n", strings.Count(transcript, "\n")), nil
+}
+
+func (m *MockSummaryClient) Model() string {
+	return "test-model"
+}
+
+func TestExportService_ExportChannelSummaries(t *testing.T) {
+	for _, by := range []string{models.SummarizeByDay, models.SummarizeByThread} {
+		t.Run(by, func(t *testing.T) {
+			summaries := &MockSummaryClient{}
+			service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+			service.SetSummaryClient(summaries)
+
+			options := models.ExportOptions{
+				ChannelID:      "C123456",
+				IncludeThreads: true,
+				OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+				Format:         "json",
+				SummarizeBy:    by,
+			}
+			result, err := service.ExportChannel(context.Background(), options, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			export, err := ReadExportFile(result.OutputFile)
+			if err != nil {
+				t.Fatalf("Failed to read export: %v", err)
+			}
+
+			if len(export.Summaries) == 0 || len(export.Summaries) != len(summaries.transcripts) {
+				t.Fatalf("Expected one summary per request, got %d summaries for %d requests", len(export.Summaries), len(summaries.transcripts))
+			}
+			for _, summary := range export.Summaries {
+				if summary.Scope != by || summary.Model != "test-model" || summary.Messages == 0 {
+					t.Errorf("Unexpected summary %+v", summary)
+				}
+				if strings.HasSuffix(summary.Summary, "\n") {
+					t.Errorf("Expected trimmed summary, got %q", summary.Summary)
+				}
+				if by == models.SummarizeByDay && summary.Date == "" {
+					t.Errorf("Expected day summaries to have a date, got %+v", summary)
+				}
+				if by == models.SummarizeByThread && summary.ThreadTS == "" {
+					t.Errorf("Expected thread summaries to have a thread, got %+v", summary)
+				}
+			}
+			if export.ExportInfo.Partial {
+				t.Errorf("Expected complete export, got warnings %v", export.ExportInfo.Warnings)
+			}
+		})
+	}
+}
+
+func TestExportService_ExportChannelSummaryFailure(t *testing.T) {
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	service.SetSummaryClient(&MockSummaryClient{fail: true})
+
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+		SummarizeBy:    models.SummarizeByDay,
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected failed summaries not to abort the export, got %v", err)
+	}
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if len(export.Summaries) != 0 || !export.ExportInfo.Partial {
+		t.Fatalf("Expected a partial export without summaries, got %+v", export.ExportInfo)
+	}
+	if !strings.Contains(strings.Join(export.ExportInfo.Warnings, "\n"), "endpoint unavailable") {
+		t.Errorf("Expected the failure as a warning, got %v", export.ExportInfo.Warnings)
+	}
+}
+
+func TestLLMClient_Summarize(t *testing.T) {
+	var received struct {
+		Model    string        `json:"model"`
+		Messages []chatMessage `json:"messages"`
+	}
+	var auth string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		// The first request is rate limited; "fail" always is
+		if requests == 1 || strings.Contains(received.Messages[1].Content, "fail") {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"quota exceeded"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Decided to ship."}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewLLMClient(server.URL+"/v1/", "secret", "small-model")
+	summary, err := client.Summarize(context.Background(), "Summarize", "[2024-01-15 09:00] Alice: ship it")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if summary != "Decided to ship." || requests != 2 {
+		t.Errorf("Expected the completion after a retry, got %q after %d requests", summary, requests)
+	}
+	if auth != "Bearer secret" || received.Model != "small-model" || len(received.Messages) != 2 || received.Messages[0].Role != "system" {
+		t.Errorf("Unexpected request: auth %q, body %+v", auth, received)
+	}
+
+	requests = 0
+	if _, err := client.Summarize(context.Background(), "Summarize", "fail"); err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("Expected the endpoint's error message, got %v", err)
+	}
+	if requests != maxRateLimitRetries+1 {
+		t.Errorf("Expected %d attempts, got %d", maxRateLimitRetries+1, requests)
+	}
+}
+
+func TestSummaryTranscriptCountsSentMessages(t *testing.T) {
+	lines := []llmLine{
+		{text: "first", tokens: maxSummaryTokens - 10},
+		{text: "second", tokens: 5},
+		{text: "third", tokens: 50},
+		{text: "fourth", tokens: 1},
+	}
+	transcript, sent := summaryTranscript(lines)
+	if sent != 2 || !strings.Contains(transcript, "[2 more messages omitted]") {
+		t.Errorf("Expected 2 messages sent, got %d in %q", sent, transcript)
+	}
+}
+
+func TestIsOpenAIEndpoint(t *testing.T) {
+	for endpoint, want := range map[string]bool{
+		"https://api.openai.com/v1":                  true,
+		"https://API.openai.com/v1/chat/completions": true,
+		"http://api.openai.com/v1":                   false,
+		"https://api.openai.com.evil.example/v1":     false,
+		"http://localhost:11434/v1":                  false,
+	} {
+		if got := IsOpenAIEndpoint(endpoint); got != want {
+			t.Errorf("IsOpenAIEndpoint(%q) = %v, want %v", endpoint, got, want)
+		}
+	}
+}
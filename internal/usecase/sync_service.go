@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/itcaat/slacker/internal/store"
+	"github.com/itcaat/slacker/models"
+)
+
+// SyncClientInterface defines the Slack API operations needed to sync channels
+type SyncClientInterface interface {
+	GetChannelByName(ctx context.Context, channelName string) (*models.Channel, error)
+	GetChannelInfo(ctx context.Context, channelID string) (*models.Channel, error)
+	GetMessagesSince(ctx context.Context, channelID, oldest string) ([]models.Message, error)
+	GetThreadReplies(ctx context.Context, channelID, threadTS string) ([]models.Message, error)
+	GetUsers(ctx context.Context) ([]models.User, error)
+}
+
+// SyncService keeps the local message store up to date, fetching only
+// messages posted since the previous sync of each channel
+type SyncService struct {
+	slackClient SyncClientInterface
+	store       *store.Store
+	logger      *slog.Logger
+}
+
+// NewSyncService creates a new sync service writing to st
+func NewSyncService(slackClient SyncClientInterface, st *store.Store) *SyncService {
+	return &SyncService{
+		slackClient: slackClient,
+		store:       st,
+		logger:      slog.Default(),
+	}
+}
+
+// SetLogger sets the logger used for sync warnings
+func (s *SyncService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SyncOptions defines what a sync run fetches
+type SyncOptions struct {
+	Channels       []string
+	IncludeThreads bool
+	// Full refetches the whole history instead of only new messages
+	Full bool
+	// ThreadDelay is the pause between thread requests (0 = 100ms,
+	// models.NoThreadDelay = none)
+	ThreadDelay time.Duration
+}
+
+// SyncResult summarizes the sync of a single channel
+type SyncResult struct {
+	Channel     string        `json:"channel"`
+	NewMessages int           `json:"new_messages"`
+	Total       int           `json:"total_messages"`
+	Duration    time.Duration `json:"duration"`
+	Error       string        `json:"error,omitempty"`
+}
+
+// Sync fetches new messages for each channel into the store. Failures of a
+// single channel are recorded in its result and do not stop the run.
+func (s *SyncService) Sync(ctx context.Context, opts SyncOptions) ([]SyncResult, error) {
+	if len(opts.Channels) == 0 {
+		return nil, fmt.Errorf("no channels to sync")
+	}
+
+	users, err := s.slackClient.GetUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	if err := s.store.SaveUsers(users); err != nil {
+		return nil, fmt.Errorf("failed to store users: %w", err)
+	}
+
+	var results []SyncResult
+	for _, name := range opts.Channels {
+		start := time.Now()
+		result, err := s.syncChannel(ctx, name, opts)
+		result.Channel = name
+		result.Duration = time.Since(start)
+		if err != nil {
+			result.Error = err.Error()
+			s.logger.Warn("channel sync failed", "channel", name, "error", err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// storedChannel looks up a channel that is missing from the channel list by
+// the ID stored at an earlier sync. Archived channels are synced as usual.
+// Slack answers channel_not_found both for deleted channels and for private
+// channels the token was removed from, so only a public channel is marked
+// deleted in the store; a private one is marked inaccessible. Either way the
+// stored copy of the history stays exportable offline. notFound is returned
+// for channels that were never synced.
+func (s *SyncService) storedChannel(ctx context.Context, name string, notFound error) (*models.Channel, error) {
+	stored, err := s.store.GetChannelByName(ctx, name)
+	if err != nil {
+		return nil, notFound
+	}
+	channel, err := s.slackClient.GetChannelInfo(ctx, stored.ID)
+	if err == nil {
+		return channel, nil
+	}
+	if models.ErrorCategoryOf(err) != models.ErrorCategoryChannelNotFound {
+		return nil, err
+	}
+
+	if stored.IsPrivate {
+		if !stored.IsInaccessible {
+			stored.IsInaccessible = true
+			if err := s.store.SaveChannel(*stored); err != nil {
+				return nil, fmt.Errorf("failed to store channel: %w", err)
+			}
+			s.logger.Warn("channel is no longer accessible", "channel", name, "channel_id", stored.ID)
+		}
+		return nil, models.NewExportError(models.ErrorCategoryChannelNotFound,
+			fmt.Sprintf("channel #%s (%s) is no longer accessible: it was deleted or the token was removed from it; its stored history can still be exported with --offline", stored.Name, stored.ID), nil)
+	}
+
+	if !stored.IsDeleted {
+		stored.IsDeleted = true
+		if err := s.store.SaveChannel(*stored); err != nil {
+			return nil, fmt.Errorf("failed to store channel: %w", err)
+		}
+		s.logger.Warn("channel was deleted in Slack", "channel", name, "channel_id", stored.ID)
+	}
+	return nil, models.NewExportError(models.ErrorCategoryChannelNotFound,
+		fmt.Sprintf("channel #%s (%s) was deleted in Slack; its stored history can still be exported with --offline", stored.Name, stored.ID), nil)
+}
+
+// syncChannel fetches and stores the new messages of one channel
+func (s *SyncService) syncChannel(ctx context.Context, name string, opts SyncOptions) (SyncResult, error) {
+	var result SyncResult
+
+	channel, err := s.slackClient.GetChannelByName(ctx, name)
+	if models.ErrorCategoryOf(err) == models.ErrorCategoryChannelNotFound {
+		channel, err = s.storedChannel(ctx, name, err)
+	}
+	if err != nil {
+		return result, err
+	}
+	if err := s.store.SaveChannel(*channel); err != nil {
+		return result, fmt.Errorf("failed to store channel: %w", err)
+	}
+
+	oldest := ""
+	if !opts.Full {
+		state, err := s.store.State(channel.ID)
+		if err != nil {
+			return result, fmt.Errorf("failed to read sync state: %w", err)
+		}
+		if state != nil {
+			oldest = state.LatestTS
+		}
+	}
+
+	messages, err := s.slackClient.GetMessagesSince(ctx, channel.ID, oldest)
+	if err != nil {
+		return result, err
+	}
+
+	// The oldest bound is inclusive on some API paths; drop what we already have
+	var fresh []models.Message
+	for _, message := range messages {
+		if oldest == "" || message.Timestamp > oldest {
+			fresh = append(fresh, message)
+		}
+	}
+
+	if opts.IncludeThreads {
+		delay := opts.ThreadDelay
+		switch {
+		case delay < 0:
+			delay = 0
+		case delay == 0:
+			delay = 100 * time.Millisecond
+		}
+		for i := range fresh {
+			if fresh[i].ReplyCount == 0 || fresh[i].ThreadTS == "" {
+				continue
+			}
+			replies, err := s.slackClient.GetThreadReplies(ctx, channel.ID, fresh[i].ThreadTS)
+			if err != nil {
+				s.logger.Warn("failed to fetch thread replies", "channel", name, "thread_ts", fresh[i].ThreadTS, "error", err)
+				continue
+			}
+			fresh[i].Thread = replies
+			time.Sleep(delay)
+		}
+	}
+
+	if err := s.store.SaveMessages(*channel, fresh); err != nil {
+		return result, fmt.Errorf("failed to store messages: %w", err)
+	}
+
+	state, err := s.store.State(channel.ID)
+	if err != nil {
+		return result, fmt.Errorf("failed to read sync state: %w", err)
+	}
+	result.NewMessages = len(fresh)
+	if state != nil {
+		result.Total = state.Messages
+	}
+	return result, nil
+}
+
+// EventStreamInterface defines the Socket Mode stream followed by Follow
+type EventStreamInterface interface {
+	StreamMessageEvents(ctx context.Context, appToken string, channelIDs []string, handler func(models.MessageEvent)) error
+}
+
+// Follow applies new, edited and deleted messages of the synced channels and
+// changes to their reactions to the store as Slack reports them, until ctx
+// is cancelled. report, when set,
+// is called for every applied event with whether a revision was recorded.
+func (s *SyncService) Follow(ctx context.Context, stream EventStreamInterface, appToken string, channelNames []string, report func(event models.MessageEvent, revised bool)) error {
+	channels := make(map[string]models.Channel, len(channelNames))
+	var ids []string
+	for _, name := range channelNames {
+		channel, err := s.store.GetChannelByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("channel #%s is not in the local store: %w", name, err)
+		}
+		channels[channel.ID] = *channel
+		ids = append(ids, channel.ID)
+	}
+
+	return stream.StreamMessageEvents(ctx, appToken, ids, func(event models.MessageEvent) {
+		revised, err := s.ApplyEvent(channels[event.ChannelID], event)
+		if err != nil {
+			s.logger.Warn("failed to apply message event", "channel", channels[event.ChannelID].Name, "kind", event.Kind, "ts", event.TS, "error", err)
+			return
+		}
+		if report != nil {
+			report(event, revised)
+		}
+	})
+}
+
+// ApplyEvent applies a message event of channel to the store. New thread
+// replies are added to their stored parent; edits and deletions keep the
+// replaced version as a revision; reactions update the counts of the stored
+// message. It returns whether a revision was recorded.
+func (s *SyncService) ApplyEvent(channel models.Channel, event models.MessageEvent) (bool, error) {
+	at := event.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	switch event.Kind {
+	case models.EventMessagePosted:
+		if event.Message == nil {
+			return false, nil
+		}
+		if event.ThreadTS != "" && event.ThreadTS != event.TS {
+			_, err := s.store.SaveReply(channel.ID, *event.Message)
+			return false, err
+		}
+		return false, s.store.SaveMessages(channel, []models.Message{*event.Message})
+	case models.EventMessageChanged:
+		if event.Message == nil {
+			return false, nil
+		}
+		return s.store.EditMessage(channel.ID, *event.Message, event.Previous, at)
+	case models.EventMessageDeleted:
+		return s.store.DeleteMessage(channel.ID, event.TS, event.ThreadTS, event.Previous, at)
+	case models.EventReactionAdded:
+		_, err := s.store.AddReaction(channel.ID, event.TS, event.Reaction, event.User)
+		return false, err
+	case models.EventReactionRemoved:
+		_, err := s.store.RemoveReaction(channel.ID, event.TS, event.Reaction, event.User)
+		return false, err
+	}
+	return false, nil
+}
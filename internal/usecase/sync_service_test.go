@@ -0,0 +1,277 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/internal/store"
+	"github.com/itcaat/slacker/models"
+)
+
+// MockSyncClient serves a fixed channel history, filtered by oldest. With
+// archived set the channel leaves the channel list; with deleted it is gone.
+type MockSyncClient struct {
+	messages []models.Message
+	replies  map[string][]models.Message
+	oldest   []string
+	archived bool
+	deleted  bool
+	private  bool
+}
+
+func (m *MockSyncClient) GetChannelByName(ctx context.Context, channelName string) (*models.Channel, error) {
+	if m.archived || m.deleted {
+		return nil, models.NewExportError(models.ErrorCategoryChannelNotFound, "channel not found", nil)
+	}
+	return &models.Channel{ID: "C123456", Name: channelName, IsPrivate: m.private}, nil
+}
+
+func (m *MockSyncClient) GetChannelInfo(ctx context.Context, channelID string) (*models.Channel, error) {
+	if m.deleted {
+		return nil, models.NewExportError(models.ErrorCategoryChannelNotFound, "channel_not_found", nil)
+	}
+	return &models.Channel{ID: channelID, Name: "general", IsArchived: m.archived, IsPrivate: m.private}, nil
+}
+
+func (m *MockSyncClient) GetMessagesSince(ctx context.Context, channelID, oldest string) ([]models.Message, error) {
+	m.oldest = append(m.oldest, oldest)
+	var result []models.Message
+	for _, msg := range m.messages {
+		if msg.Timestamp >= oldest {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockSyncClient) GetThreadReplies(ctx context.Context, channelID, threadTS string) ([]models.Message, error) {
+	return m.replies[threadTS], nil
+}
+
+func (m *MockSyncClient) GetUsers(ctx context.Context) ([]models.User, error) {
+	return []models.User{{ID: "U123456", Name: "alice"}}, nil
+}
+
+func TestSyncService_SyncFetchesDeltas(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "messages.db"), false)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	mockClient := &MockSyncClient{
+		messages: []models.Message{
+			{User: "U123456", Text: "first", Timestamp: "1704067200.000001", ThreadTS: "1704067200.000001", ReplyCount: 1},
+			{User: "U123456", Text: "second", Timestamp: "1704067201.000000"},
+		},
+		replies: map[string][]models.Message{
+			"1704067200.000001": {{User: "U123456", Text: "reply", Timestamp: "1704067200.500000"}},
+		},
+	}
+	service := NewSyncService(mockClient, st)
+	opts := SyncOptions{Channels: []string{"general"}, IncludeThreads: true, ThreadDelay: 1}
+
+	results, err := service.Sync(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if results[0].NewMessages != 2 || results[0].Total != 2 {
+		t.Errorf("Expected 2 new and 2 stored messages, got %+v", results[0])
+	}
+
+	// The second run only asks for messages after the latest stored one
+	mockClient.messages = append(mockClient.messages, models.Message{User: "U123456", Text: "third", Timestamp: "1704067202.000000"})
+	results, err = service.Sync(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mockClient.oldest[1] != "1704067201.000000" {
+		t.Errorf("Expected delta sync from latest timestamp, got %q", mockClient.oldest[1])
+	}
+	if results[0].NewMessages != 1 || results[0].Total != 3 {
+		t.Errorf("Expected 1 new and 3 stored messages, got %+v", results[0])
+	}
+
+	replies, err := st.GetThreadReplies(context.Background(), "C123456", "1704067200.000001")
+	if err != nil {
+		t.Fatalf("Failed to read replies: %v", err)
+	}
+	if len(replies) != 1 || replies[0].Text != "reply" {
+		t.Errorf("Expected stored thread reply, got %+v", replies)
+	}
+}
+
+func TestSyncService_SyncRequiresChannels(t *testing.T) {
+	service := NewSyncService(&MockSyncClient{}, nil)
+
+	if _, err := service.Sync(context.Background(), SyncOptions{}); err == nil {
+		t.Error("Expected error when no channels are given")
+	}
+}
+
+func TestSyncService_SyncArchivedAndDeletedChannels(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "messages.db"), false)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	mockClient := &MockSyncClient{messages: []models.Message{
+		{User: "U123456", Text: "first", Timestamp: "1704067200.000001"},
+	}}
+	service := NewSyncService(mockClient, st)
+	opts := SyncOptions{Channels: []string{"general"}}
+	if _, err := service.Sync(context.Background(), opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Archived channels leave the channel list but are found by ID
+	mockClient.archived = true
+	mockClient.messages = append(mockClient.messages, models.Message{User: "U123456", Text: "last words", Timestamp: "1704067300.000000"})
+	results, err := service.Sync(context.Background(), opts)
+	if err != nil || results[0].Error != "" || results[0].NewMessages != 1 {
+		t.Fatalf("Expected the archived channel to sync, got %+v (%v)", results, err)
+	}
+
+	mockClient.deleted = true
+	results, err = service.Sync(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(results[0].Error, "deleted") {
+		t.Errorf("Expected a deleted channel error, got %q", results[0].Error)
+	}
+
+	// The stored history is still exportable and labeled deleted
+	exportService := NewExportService(st, "1.0.0-test")
+	result, err := exportService.ExportChannel(context.Background(), models.ExportOptions{
+		ChannelID:  "C123456",
+		OutputFile: filepath.Join(t.TempDir(), "general.json"),
+		Format:     "json",
+	}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if export.Channel.State != models.ChannelStateDeleted || !export.Channel.IsArchived || len(export.Messages) != 2 {
+		t.Errorf("Expected 2 messages of a deleted channel, got state %q and %d messages", export.Channel.State, len(export.Messages))
+	}
+}
+
+func TestSyncService_SyncInaccessiblePrivateChannel(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "messages.db"), false)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	mockClient := &MockSyncClient{private: true, messages: []models.Message{
+		{User: "U123456", Text: "first", Timestamp: "1704067200.000001"},
+	}}
+	service := NewSyncService(mockClient, st)
+	opts := SyncOptions{Channels: []string{"general"}}
+	if _, err := service.Sync(context.Background(), opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A private channel that is not found may only have dropped the token
+	mockClient.deleted = true
+	results, err := service.Sync(context.Background(), opts)
+	if err != nil || !strings.Contains(results[0].Error, "no longer accessible") {
+		t.Fatalf("Expected an inaccessible channel error, got %+v (%v)", results, err)
+	}
+	channel, err := st.GetChannelByName(context.Background(), "general")
+	if err != nil || channel.IsDeleted || channel.State() != models.ChannelStateInaccessible {
+		t.Fatalf("Expected the channel to be marked inaccessible, got %+v (%v)", channel, err)
+	}
+
+	// Access comes back with the channel
+	mockClient.deleted = false
+	if results, err := service.Sync(context.Background(), opts); err != nil || results[0].Error != "" {
+		t.Fatalf("Expected the channel to sync again, got %+v (%v)", results, err)
+	}
+	if channel, _ := st.GetChannelByName(context.Background(), "general"); channel.IsInaccessible {
+		t.Error("Expected the inaccessible mark to be cleared")
+	}
+}
+
+// MockEventStream delivers a fixed list of message events
+type MockEventStream struct {
+	events   []models.MessageEvent
+	channels []string
+}
+
+func (m *MockEventStream) StreamMessageEvents(ctx context.Context, appToken string, channelIDs []string, handler func(models.MessageEvent)) error {
+	m.channels = channelIDs
+	for _, event := range m.events {
+		handler(event)
+	}
+	return nil
+}
+
+func TestSyncService_FollowAppliesEvents(t *testing.T) {
+	st, err := store.Open(filepath.Join(t.TempDir(), "messages.db"), false)
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer st.Close()
+
+	mockClient := &MockSyncClient{messages: []models.Message{
+		{User: "U123456", Text: "question", Timestamp: "1704067200.000001", ThreadTS: "1704067200.000001", ReplyCount: 1},
+	}}
+	service := NewSyncService(mockClient, st)
+	if _, err := service.Sync(context.Background(), SyncOptions{Channels: []string{"general"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	posted := models.Message{User: "U123456", Text: "hello", Timestamp: "1704067300.000000"}
+	reply := models.Message{User: "U123456", Text: "answer", Timestamp: "1704067301.000000", ThreadTS: "1704067200.000001"}
+	edited := models.Message{User: "U123456", Text: "hello, world", Timestamp: "1704067300.000000"}
+	stream := &MockEventStream{events: []models.MessageEvent{
+		{Kind: models.EventMessagePosted, ChannelID: "C123456", Message: &posted, TS: posted.Timestamp},
+		{Kind: models.EventMessagePosted, ChannelID: "C123456", Message: &reply, TS: reply.Timestamp, ThreadTS: reply.ThreadTS},
+		{Kind: models.EventMessageChanged, ChannelID: "C123456", Message: &edited, TS: edited.Timestamp},
+		{Kind: models.EventReactionAdded, ChannelID: "C123456", TS: edited.Timestamp, Reaction: "tada", User: "U123456"},
+		{Kind: models.EventMessageDeleted, ChannelID: "C123456", TS: "1704067200.000001"},
+	}}
+
+	var revised []string
+	err = service.Follow(context.Background(), stream, "xapp-test", []string{"general"}, func(event models.MessageEvent, revision bool) {
+		if revision {
+			revised = append(revised, event.Kind)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(stream.channels) != 1 || stream.channels[0] != "C123456" {
+		t.Errorf("Expected to follow the stored channel, got %v", stream.channels)
+	}
+	if strings.Join(revised, ",") != "message_changed,message_deleted" {
+		t.Errorf("Expected the edit and the deletion to be revisions, got %v", revised)
+	}
+
+	page, err := st.GetChannelHistory(context.Background(), "C123456", 0, "")
+	if err != nil {
+		t.Fatalf("Failed to read history: %v", err)
+	}
+	if len(page.Messages) != 1 || page.Messages[0].Text != "hello, world" {
+		t.Fatalf("Expected only the edited new message, got %+v", page.Messages)
+	}
+	if reactions := page.Messages[0].Reactions; len(reactions) != 1 || reactions[0].Name != "tada" || reactions[0].Count != 1 {
+		t.Errorf("Expected the added reaction to be stored, got %+v", reactions)
+	}
+	revisions, _ := st.Revisions("C123456", "1704067200.000001")
+	if len(revisions) != 1 || len(revisions[0].Message.Thread) != 1 || revisions[0].Message.Thread[0].Text != "answer" {
+		t.Errorf("Expected the deleted thread to be kept with its new reply, got %+v", revisions)
+	}
+
+	if err := service.Follow(context.Background(), stream, "xapp-test", []string{"random"}, nil); err == nil {
+		t.Error("Expected an error for a channel that is not in the store")
+	}
+}
@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// Thread document formats
+const (
+	DocFormatMarkdown = "markdown"
+	DocFormatText     = "text"
+)
+
+// maxDocTitle bounds the length of a document title taken from the thread's
+// first message
+const maxDocTitle = 80
+
+var (
+	mrkdwnBold   = regexp.MustCompile(`(^|[\s(])\*([^*\n]+)\*([\s).,!?:;]|$)`)
+	mrkdwnStrike = regexp.MustCompile(`(^|[\s(])~([^~\n]+)~([\s).,!?:;]|$)`)
+	quoteSpace   = regexp.MustCompile(`\s+`)
+)
+
+// ThreadClientInterface defines the Slack operations needed to turn a thread
+// into a document
+type ThreadClientInterface interface {
+	GetChannels(ctx context.Context) ([]models.Channel, error)
+	GetThread(ctx context.Context, channelID, threadTS string) ([]models.Message, error)
+	GetUsers(ctx context.Context) ([]models.User, error)
+}
+
+// ThreadDocument is a single thread with the users needed to resolve its names
+type ThreadDocument struct {
+	Channel   models.ChannelInfo
+	Permalink string
+	// Messages holds the parent followed by the replies
+	Messages []models.ExportMessage
+	Users    map[string]models.ExportUser
+}
+
+// ThreadDocService converts Slack threads into standalone documents
+type ThreadDocService struct {
+	client   ThreadClientInterface
+	logger   *slog.Logger
+	location *time.Location
+}
+
+// NewThreadDocService creates a new thread document service
+func NewThreadDocService(client ThreadClientInterface) *ThreadDocService {
+	return &ThreadDocService{
+		client: client,
+		logger: slog.Default(),
+	}
+}
+
+// SetLogger sets the logger used for warnings
+func (s *ThreadDocService) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetLocation sets the zone message times are rendered in; nil selects the
+// machine's zone
+func (s *ThreadDocService) SetLocation(loc *time.Location) {
+	s.location = loc
+}
+
+// FetchThread fetches the thread a message permalink points to. A link to a
+// reply fetches the whole thread.
+func (s *ThreadDocService) FetchThread(ctx context.Context, permalink string) (*ThreadDocument, error) {
+	channelID, _, threadTS, err := models.ParsePermalink(permalink)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.client.GetThread(ctx, channelID, threadTS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch thread: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil, models.NewExportError(models.ErrorCategoryChannelNotFound, fmt.Sprintf("thread %s not found in channel %s", threadTS, channelID), nil)
+	}
+
+	doc := &ThreadDocument{
+		Channel:   models.ChannelInfo{ID: channelID, Name: channelID},
+		Permalink: permalink,
+		Users:     make(map[string]models.ExportUser),
+	}
+	for _, msg := range messages {
+		doc.Messages = append(doc.Messages, models.ConvertToExportMessage(msg, s.location))
+	}
+
+	// Names are a nicety; a document with IDs is still useful
+	if channels, err := s.client.GetChannels(ctx); err != nil {
+		s.logger.Warn("channel name unavailable", "channel_id", channelID, "error", err)
+	} else {
+		for _, channel := range channels {
+			if channel.ID == channelID {
+				doc.Channel.Name = channel.Name
+				doc.Channel.IsPrivate = channel.IsPrivate
+				break
+			}
+		}
+	}
+	if users, err := s.client.GetUsers(ctx); err != nil {
+		s.logger.Warn("user names unavailable", "channel_id", channelID, "error", err)
+	} else {
+		for _, user := range users {
+			doc.Users[user.ID] = models.ConvertToExportUser(user)
+		}
+	}
+
+	return doc, nil
+}
+
+// RenderThreadDoc renders a thread as a Markdown or plain text document:
+// a title and summary, the discussion with consecutive messages of the same
+// person grouped, and a list of the attached files. Quoted lines that repeat
+// an earlier message and repeated messages are left out.
+func RenderThreadDoc(doc *ThreadDocument, format string) ([]byte, error) {
+	if format != DocFormatMarkdown && format != DocFormatText {
+		return nil, fmt.Errorf("invalid document format '%s'. Valid formats: markdown, text", format)
+	}
+	if len(doc.Messages) == 0 {
+		return nil, fmt.Errorf("thread has no messages")
+	}
+	md := format == DocFormatMarkdown
+	parent := doc.Messages[0]
+
+	var b bytes.Buffer
+	title := docTitle(parent, doc)
+	if md {
+		fmt.Fprintf(&b, "# %s\n\n", title)
+	} else {
+		fmt.Fprintf(&b, "%s\n%s\n\n", title, strings.Repeat("=", len([]rune(title))))
+	}
+
+	participants := make(map[string]bool)
+	var files []docFile
+	for _, msg := range doc.Messages {
+		participants[speakerName(msg, doc.Users)] = true
+		for _, file := range msg.Files {
+			files = append(files, docFile{file: file, sharedBy: speakerName(msg, doc.Users)})
+		}
+	}
+	names := make([]string, 0, len(participants))
+	for name := range participants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	started := parent.Timestamp.Format("2006-01-02 15:04 MST")
+	summary := [][2]string{
+		{"Channel", "#" + doc.Channel.Name},
+		{"Started by", speakerName(parent, doc.Users) + " on " + started},
+		{"Replies", fmt.Sprintf("%d", len(doc.Messages)-1)},
+		{"Participants", strings.Join(names, ", ")},
+	}
+	if doc.Permalink != "" {
+		summary = append(summary, [2]string{"Source", doc.Permalink})
+	}
+	for _, row := range summary {
+		if md {
+			fmt.Fprintf(&b, "- **%s:** %s\n", row[0], row[1])
+		} else {
+			fmt.Fprintf(&b, "%s: %s\n", row[0], row[1])
+		}
+	}
+
+	writeSection(&b, "Discussion", md)
+	var seen []string
+	lastSpeaker := ""
+	for _, msg := range doc.Messages {
+		kept, duplicate := dedupeQuotes(msg.Text, doc.Users, seen)
+		if duplicate && len(msg.Files) == 0 {
+			continue
+		}
+		seen = append(seen, comparableText(msg.Text, doc.Users))
+		if kept != msg.Text {
+			seen = append(seen, comparableText(kept, doc.Users))
+		}
+		text := plainText(kept, doc.Users, nil)
+		if md {
+			text = markdownText(kept, doc.Users)
+		}
+
+		speaker := speakerName(msg, doc.Users)
+		at := msg.Timestamp.Format("2006-01-02 15:04")
+		if speaker != lastSpeaker {
+			if md {
+				fmt.Fprintf(&b, "**%s** · %s\n\n", speaker, at)
+			} else {
+				fmt.Fprintf(&b, "%s (%s):\n", speaker, at)
+			}
+			lastSpeaker = speaker
+		}
+		if text != "" {
+			b.WriteString(text)
+			b.WriteString("\n\n")
+		}
+		for _, attachment := range msg.Attachments {
+			label := cmp.Or(attachment.Title, attachment.Fallback)
+			if label == "" {
+				continue
+			}
+			label = plainText(label, doc.Users, nil)
+			if md && attachment.TitleLink != "" {
+				fmt.Fprintf(&b, "*Link:* [%s](%s)\n\n", label, attachment.TitleLink)
+			} else {
+				fmt.Fprintf(&b, "Link: %s\n\n", label)
+			}
+		}
+		for _, file := range msg.Files {
+			if md {
+				fmt.Fprintf(&b, "*Attached:* %s\n\n", fileName(file))
+			} else {
+				fmt.Fprintf(&b, "Attached: %s\n\n", fileName(file))
+			}
+		}
+	}
+
+	if len(files) > 0 {
+		writeSection(&b, "Files", md)
+		for _, f := range files {
+			details := []string{"shared by " + f.sharedBy}
+			if kind := cmp.Or(f.file.PrettyType, strings.ToUpper(f.file.Filetype)); kind != "" {
+				details = append(details, kind)
+			}
+			if f.file.Size > 0 {
+				details = append(details, FormatFileSize(int64(f.file.Size)))
+			}
+			link := cmp.Or(f.file.Permalink, f.file.URLPrivate)
+			switch {
+			case md && link != "":
+				fmt.Fprintf(&b, "- [%s](%s) (%s)\n", fileName(f.file), link, strings.Join(details, ", "))
+			case link != "":
+				fmt.Fprintf(&b, "- %s (%s): %s\n", fileName(f.file), strings.Join(details, ", "), link)
+			default:
+				fmt.Fprintf(&b, "- %s (%s)\n", fileName(f.file), strings.Join(details, ", "))
+			}
+		}
+	}
+
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
+
+// docFile is a file attached to a thread message
+type docFile struct {
+	file     models.ExportFile
+	sharedBy string
+}
+
+// writeSection starts a document section
+func writeSection(b *bytes.Buffer, title string, md bool) {
+	if md {
+		fmt.Fprintf(b, "\n## %s\n\n", title)
+	} else {
+		fmt.Fprintf(b, "\n%s\n%s\n\n", title, strings.Repeat("-", len(title)))
+	}
+}
+
+// docTitle is the first line of the thread's first message, shortened
+func docTitle(parent models.ExportMessage, doc *ThreadDocument) string {
+	title := strings.TrimSpace(plainText(parent.Text, doc.Users, nil))
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = strings.TrimSpace(title[:i])
+	}
+	title = strings.Trim(title, "*_~` ")
+	if title == "" {
+		return "Thread in #" + doc.Channel.Name
+	}
+	if runes := []rune(title); len(runes) > maxDocTitle {
+		title = strings.TrimSpace(string(runes[:maxDocTitle-1])) + "…"
+	}
+	return title
+}
+
+// speakerName names the author of a message, using the workflow or bot name
+// for bot posts without a user
+func speakerName(msg models.ExportMessage, users map[string]models.ExportUser) string {
+	if msg.User == "" && msg.Workflow != nil && msg.Workflow.Name != "" {
+		return msg.Workflow.Name
+	}
+	if msg.User == "" && msg.Username != "" {
+		return msg.Username
+	}
+	return userDisplayName(msg.User, users)
+}
+
+// fileName returns the title of a file, falling back to its name
+func fileName(file models.ExportFile) string {
+	return cmp.Or(file.Title, file.Name, file.ID)
+}
+
+// dedupeQuotes drops the quoted lines ("> ...") of a message's Slack markup
+// that repeat an earlier message, and reports whether the whole message
+// repeats one
+func dedupeQuotes(raw string, users map[string]models.ExportUser, seen []string) (string, bool) {
+	normalized := comparableText(raw, users)
+	if normalized == "" {
+		return "", false
+	}
+	for _, earlier := range seen {
+		if earlier == normalized {
+			return raw, true
+		}
+	}
+
+	var kept []string
+	for _, line := range strings.Split(raw, "\n") {
+		if quote := strings.TrimSpace(line); strings.HasPrefix(quote, "&gt;") || strings.HasPrefix(quote, ">") {
+			quote = strings.TrimPrefix(strings.TrimPrefix(quote, "&gt;"), ">")
+			quote = comparableText(quote, users)
+			if quote == "" || repeats(quote, seen) {
+				continue
+			}
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n")), false
+}
+
+// repeats reports whether quote occurs in one of the earlier messages
+func repeats(quote string, seen []string) bool {
+	for _, earlier := range seen {
+		if strings.Contains(earlier, quote) {
+			return true
+		}
+	}
+	return false
+}
+
+// comparableText reduces Slack markup to lowercase words for comparing
+// quotes: links become their labels and formatting marks are dropped
+func comparableText(raw string, users map[string]models.ExportUser) string {
+	raw = slackLink.ReplaceAllStringFunc(raw, func(match string) string {
+		parts := slackLink.FindStringSubmatch(match)
+		if parts[2] != "" && (strings.HasPrefix(parts[1], "http://") || strings.HasPrefix(parts[1], "https://")) {
+			return parts[2]
+		}
+		return match
+	})
+	text := strings.NewReplacer("*", "", "_", "", "~", "", "`", "").Replace(plainText(raw, users, nil))
+	return strings.ToLower(strings.TrimSpace(quoteSpace.ReplaceAllString(text, " ")))
+}
+
+// markdownText converts the Slack markup of a message to Markdown
+func markdownText(raw string, users map[string]models.ExportUser) string {
+	// Labelled web links become Markdown links
+	raw = slackLink.ReplaceAllStringFunc(raw, func(match string) string {
+		parts := slackLink.FindStringSubmatch(match)
+		target, label := parts[1], parts[2]
+		if label == "" || label == target || !(strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")) {
+			return match
+		}
+		return "[" + label + "](" + target + ")"
+	})
+	text := plainText(raw, users, nil)
+	text = mrkdwnBold.ReplaceAllString(text, "$1**$2**$3")
+	return mrkdwnStrike.ReplaceAllString(text, "$1~~$2~~$3")
+}
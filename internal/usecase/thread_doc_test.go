@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockThreadClient serves a single thread
+type MockThreadClient struct {
+	thread   []models.Message
+	threadTS string
+}
+
+func (m *MockThreadClient) GetChannels(ctx context.Context) ([]models.Channel, error) {
+	return []models.Channel{{ID: "C123", Name: "decisions"}}, nil
+}
+
+func (m *MockThreadClient) GetThread(ctx context.Context, channelID, threadTS string) ([]models.Message, error) {
+	m.threadTS = threadTS
+	return m.thread, nil
+}
+
+func (m *MockThreadClient) GetUsers(ctx context.Context) ([]models.User, error) {
+	return []models.User{
+		{ID: "U1", Name: "alice", Profile: models.Profile{DisplayName: "Alice"}},
+		{ID: "U2", Name: "bob", Profile: models.Profile{DisplayName: "Bob"}},
+	}, nil
+}
+
+func TestThreadDocService_FetchAndRender(t *testing.T) {
+	client := &MockThreadClient{thread: []models.Message{
+		{Timestamp: "1705312800.000100", User: "U1", Text: "*Move to Postgres 16?*\nWe need <https://wiki.example.com/db|the plan> reviewed by <@U2>"},
+		{Timestamp: "1705312900.000200", User: "U2", Text: "&gt; We need the plan reviewed by @Bob\nLooks good, ~maybe~ ship it", Files: []models.File{
+			{ID: "F1", Name: "plan.pdf", Title: "Migration plan", Filetype: "pdf", Size: 2048, URL: "https://files.slack.com/files-pri/T1-F1/plan.pdf"},
+		}},
+		{Timestamp: "1705313000.000300", User: "U2", Text: "Looks good, maybe ship it"},
+		{Timestamp: "1705313100.000400", User: "U1", Text: "Decided: migrating on Friday"},
+	}}
+	service := NewThreadDocService(client)
+
+	// A link to a reply fetches the whole thread
+	doc, err := service.FetchThread(context.Background(), "https://acme.slack.com/archives/C123/p1705312900000200?thread_ts=1705312800.000100&cid=C123")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.threadTS != "1705312800.000100" || doc.Channel.Name != "decisions" {
+		t.Fatalf("Expected the parent thread of #decisions, got %s in %+v", client.threadTS, doc.Channel)
+	}
+
+	data, err := RenderThreadDoc(doc, DocFormatMarkdown)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	markdown := string(data)
+	for _, want := range []string{
+		"# Move to Postgres 16?",
+		"- **Channel:** #decisions",
+		"- **Participants:** Alice, Bob",
+		"[the plan](https://wiki.example.com/db) reviewed by @Bob",
+		"Looks good, ~~maybe~~ ship it",
+		"- [Migration plan](https://files.slack.com/files-pri/T1-F1/plan.pdf) (shared by Bob, PDF, 2.0 KB)",
+		"Decided: migrating on Friday",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected document to contain %q, got:\n%s", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "> We need") {
+		t.Errorf("Expected the repeated quote to be dropped, got:\n%s", markdown)
+	}
+	if strings.Count(markdown, "ship it") != 1 {
+		t.Errorf("Expected the repeated message to be dropped, got:\n%s", markdown)
+	}
+
+	text, err := RenderThreadDoc(doc, DocFormatText)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(string(text), "**") || !strings.Contains(string(text), "Files\n-----") {
+		t.Errorf("Expected plain text without Markdown, got:\n%s", text)
+	}
+}
+
+func TestThreadDocService_FetchThreadInvalidLink(t *testing.T) {
+	service := NewThreadDocService(&MockThreadClient{})
+	if _, err := service.FetchThread(context.Background(), "https://acme.slack.com/team/U1"); err == nil {
+		t.Error("Expected an error for a link that is not a message link")
+	}
+	if _, err := service.FetchThread(context.Background(), "https://acme.slack.com/archives/C123/p1705312800000100"); err == nil {
+		t.Error("Expected an error for a thread without messages")
+	}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// conversationalSubtypes are the subtypes of messages people write and
+// expect an answer to; other subtypes are system events
+var conversationalSubtypes = map[string]bool{
+	"":                            true,
+	"file_share":                  true,
+	"me_message":                  true,
+	models.SubtypeThreadBroadcast: true,
+}
+
+// threadStatistics measures how the top-level messages were answered in
+// threads. It returns nil when there are no top-level messages.
+func threadStatistics(messages []models.Message) *models.ThreadStats {
+	stats := &models.ThreadStats{Participants: make(map[int]int)}
+	var firstReplies, durations []time.Duration
+	topLevel := 0
+
+	for _, msg := range messages {
+		// Broadcast links are counted with their thread, and an omitted
+		// parent has no author to be answered
+		if msg.InThread || msg.Omitted {
+			continue
+		}
+		topLevel++
+
+		answered := msg.ReplyCount > 0 && len(msg.Thread) == 0 // replies not fetched
+		if len(msg.Thread) > 0 {
+			stats.Threads++
+			posted, postedErr := models.ParseSlackTimestamp(msg.Timestamp)
+			author := messageAuthor(msg)
+			participants := map[string]bool{author: true}
+			var first, last time.Time
+			for _, reply := range msg.Thread {
+				participants[messageAuthor(reply)] = true
+				replied, err := models.ParseSlackTimestamp(reply.Timestamp)
+				if err != nil {
+					continue
+				}
+				if replied.After(last) {
+					last = replied
+				}
+				if messageAuthor(reply) != author && (first.IsZero() || replied.Before(first)) {
+					first = replied
+				}
+			}
+			stats.Participants[len(participants)]++
+
+			answered = !first.IsZero()
+			if answered {
+				stats.Answered++
+			}
+			if postedErr == nil {
+				if answered {
+					firstReplies = append(firstReplies, first.Sub(posted))
+				}
+				if !last.IsZero() {
+					durations = append(durations, last.Sub(posted))
+				}
+			}
+		}
+
+		if !answered && !msg.IsBot() && conversationalSubtypes[msg.Subtype] {
+			stats.Unanswered++
+		}
+	}
+
+	if topLevel == 0 {
+		return nil
+	}
+	stats.FirstReply = summarizeDurations(firstReplies)
+	stats.Duration = summarizeDurations(durations)
+	return stats
+}
+
+// messageAuthor identifies who posted msg, a user or a bot
+func messageAuthor(msg models.Message) string {
+	if msg.User != "" {
+		return msg.User
+	}
+	return msg.BotID
+}
+
+// summarizeDurations returns the average, median, 90th percentile and
+// maximum of durations
+func summarizeDurations(durations []time.Duration) models.DurationStats {
+	if len(durations) == 0 {
+		return models.DurationStats{}
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return models.DurationStats{
+		Average: total / time.Duration(len(sorted)),
+		Median:  percentile(sorted, 50),
+		P90:     percentile(sorted, 90),
+		Max:     sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the p-th percentile of sorted by the nearest-rank method
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestThreadStatistics(t *testing.T) {
+	messages := []models.Message{
+		// Answered after 2 minutes, last reply after 10
+		{User: "U1", Timestamp: "1704067200.000000", ReplyCount: 3, Thread: []models.Message{
+			{User: "U1", Timestamp: "1704067260.000000"},
+			{User: "U2", Timestamp: "1704067320.000000"},
+			{User: "U3", Timestamp: "1704067800.000000"},
+		}},
+		// Answered after an hour
+		{User: "U2", Timestamp: "1704070800.000000", ReplyCount: 1, Thread: []models.Message{
+			{User: "U1", Timestamp: "1704074400.000000"},
+		}},
+		// Only the author replied
+		{User: "U3", Timestamp: "1704078000.000000", ReplyCount: 1, Thread: []models.Message{
+			{User: "U3", Timestamp: "1704078060.000000"},
+		}},
+		// No replies
+		{User: "U4", Timestamp: "1704081600.000000"},
+		// Bots and system events expect no answer
+		{BotID: "B1", Timestamp: "1704081700.000000"},
+		{User: "U5", Subtype: "channel_join", Timestamp: "1704081800.000000"},
+		// Replies exist but were not fetched
+		{User: "U5", Timestamp: "1704081900.000000", ReplyCount: 2},
+	}
+
+	stats := threadStatistics(messages)
+	if stats == nil {
+		t.Fatal("Expected thread statistics")
+	}
+	if stats.Threads != 3 || stats.Answered != 2 || stats.Unanswered != 2 {
+		t.Errorf("Expected 3 threads, 2 answered and 2 unanswered, got %+v", stats)
+	}
+	if stats.FirstReply.Median != 2*time.Minute || stats.FirstReply.Max != time.Hour {
+		t.Errorf("Unexpected time to first reply %+v", stats.FirstReply)
+	}
+	if stats.Duration.Max != time.Hour || stats.Duration.Median != 10*time.Minute {
+		t.Errorf("Unexpected thread duration %+v", stats.Duration)
+	}
+	if stats.Participants[3] != 1 || stats.Participants[2] != 1 || stats.Participants[1] != 1 {
+		t.Errorf("Unexpected participant distribution %v", stats.Participants)
+	}
+
+	if threadStatistics(nil) != nil {
+		t.Error("Expected no thread statistics without messages")
+	}
+}
+
+func TestSummarizeDurations(t *testing.T) {
+	var durations []time.Duration
+	for i := 10; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Second)
+	}
+	stats := summarizeDurations(durations)
+	if stats.Median != 5*time.Second || stats.P90 != 9*time.Second || stats.Max != 10*time.Second || stats.Average != 5500*time.Millisecond {
+		t.Errorf("Unexpected summary %+v", stats)
+	}
+}
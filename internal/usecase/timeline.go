@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// channelEventTypes maps the subtypes of channel event messages to timeline
+// event types. Private channels used group_* subtypes in older history.
+var channelEventTypes = map[string]string{
+	"channel_join":      models.ChannelEventJoin,
+	"group_join":        models.ChannelEventJoin,
+	"channel_leave":     models.ChannelEventLeave,
+	"group_leave":       models.ChannelEventLeave,
+	"channel_topic":     models.ChannelEventTopic,
+	"group_topic":       models.ChannelEventTopic,
+	"channel_purpose":   models.ChannelEventPurpose,
+	"group_purpose":     models.ChannelEventPurpose,
+	"channel_name":      models.ChannelEventRename,
+	"group_name":        models.ChannelEventRename,
+	"channel_archive":   models.ChannelEventArchive,
+	"group_archive":     models.ChannelEventArchive,
+	"channel_unarchive": models.ChannelEventUnarchive,
+	"group_unarchive":   models.ChannelEventUnarchive,
+}
+
+// channelEventMessages returns the channel event messages of a history, so
+// the timeline can be built after subtype filters dropped them
+func channelEventMessages(messages []models.Message) []models.Message {
+	var events []models.Message
+	for _, msg := range messages {
+		if _, ok := channelEventTypes[msg.Subtype]; ok {
+			events = append(events, msg)
+		}
+	}
+	return events
+}
+
+// BuildTimeline compiles channel event messages into the channel timeline,
+// oldest first. Topic and purpose changes name the value they replaced when
+// an earlier change is part of the history; renames always do.
+func BuildTimeline(messages []models.Message, users map[string]models.ExportUser, loc *time.Location) []models.ChannelEvent {
+	sorted := append([]models.Message(nil), messages...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Timestamp < sorted[b].Timestamp
+	})
+
+	timeline := []models.ChannelEvent{}
+	current := make(map[string]string)
+	for _, msg := range sorted {
+		eventType, ok := channelEventTypes[msg.Subtype]
+		if !ok {
+			continue
+		}
+		timestamp, _ := models.ParseSlackTimestamp(msg.Timestamp)
+		timestamp = timestamp.In(models.OrLocal(loc))
+		event := models.ChannelEvent{
+			Type:      eventType,
+			User:      msg.User,
+			Inviter:   msg.Inviter,
+			MessageTS: msg.Timestamp,
+			Timestamp: timestamp,
+		}
+		if _, ok := users[msg.User]; ok {
+			event.UserName = userDisplayName(msg.User, users)
+		}
+
+		switch eventType {
+		case models.ChannelEventTopic:
+			event.Value = eventValue(msg.Topic, msg.Text, "set the channel topic: ")
+			event.Previous, current[eventType] = current[eventType], event.Value
+		case models.ChannelEventPurpose:
+			event.Value = eventValue(msg.Purpose, msg.Text, "set the channel purpose: ")
+			event.Previous, current[eventType] = current[eventType], event.Value
+		case models.ChannelEventRename:
+			event.Value = msg.Name
+			event.Previous = msg.OldName
+		}
+		timeline = append(timeline, event)
+	}
+	return timeline
+}
+
+// eventValue returns the new topic or purpose of an event message. Messages
+// stored without the structured field carry it in their text after prefix.
+func eventValue(field, text, prefix string) string {
+	if field != "" {
+		return field
+	}
+	if _, value, ok := strings.Cut(text, prefix); ok {
+		return unescapeSlack(value)
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	users := map[string]models.ExportUser{
+		"U1": {ID: "U1", Name: "alice", Profile: models.ExportProfile{DisplayName: "Alice"}},
+	}
+	messages := []models.Message{
+		{Subtype: "channel_name", User: "U1", Timestamp: "1704067500.000000", Name: "eng", OldName: "general"},
+		{Subtype: "channel_topic", User: "U1", Timestamp: "1704067300.000000", Topic: "Release planning"},
+		{Subtype: "channel_join", User: "U2", Inviter: "U1", Timestamp: "1704067200.000000"},
+		// Stored before topics were recorded as fields
+		{Subtype: "channel_topic", User: "U1", Timestamp: "1704067400.000000", Text: "<@U1> set the channel topic: Q&amp;A on Fridays"},
+		{Subtype: "channel_leave", User: "U2", Timestamp: "1704067600.000000"},
+	}
+
+	timeline := BuildTimeline(messages, users, time.UTC)
+
+	want := []models.ChannelEvent{
+		{Type: models.ChannelEventJoin, User: "U2", Inviter: "U1", MessageTS: "1704067200.000000"},
+		{Type: models.ChannelEventTopic, User: "U1", UserName: "Alice", Value: "Release planning", MessageTS: "1704067300.000000"},
+		{Type: models.ChannelEventTopic, User: "U1", UserName: "Alice", Value: "Q&A on Fridays", Previous: "Release planning", MessageTS: "1704067400.000000"},
+		{Type: models.ChannelEventRename, User: "U1", UserName: "Alice", Value: "eng", Previous: "general", MessageTS: "1704067500.000000"},
+		{Type: models.ChannelEventLeave, User: "U2", MessageTS: "1704067600.000000"},
+	}
+	if len(timeline) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), timeline)
+	}
+	for i, event := range timeline {
+		if event.Timestamp.IsZero() {
+			t.Errorf("Expected event %d to have a timestamp", i)
+		}
+		event.Timestamp = want[i].Timestamp
+		if event != want[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, want[i], event)
+		}
+	}
+}
+
+func TestExportService_ExportChannelTimeline(t *testing.T) {
+	mockClient := NewMockSlackClient()
+	mockClient.users = append(mockClient.users, models.User{ID: "U555555", Name: "carol", Profile: models.Profile{DisplayName: "Carol"}})
+	mockClient.messages = append(mockClient.messages, models.Message{
+		Type:      "message",
+		Subtype:   "channel_join",
+		User:      "U555555",
+		Text:      "<@U555555> has joined the channel",
+		Timestamp: "1704067230.000000",
+	})
+	service := NewExportService(mockClient, "1.0.0-test")
+
+	options := models.ExportOptions{
+		ChannelID:       "C123456",
+		IncludeTimeline: true,
+		ExcludeSubtypes: []string{"channel_join"},
+		OutputFile:      filepath.Join(t.TempDir(), "general.json"),
+		Format:          "json",
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if len(export.Messages) != 2 {
+		t.Errorf("Expected the join message excluded, got %d messages", len(export.Messages))
+	}
+	if len(export.Timeline) != 1 || export.Timeline[0].Type != models.ChannelEventJoin || export.Timeline[0].UserName != "Carol" {
+		t.Errorf("Expected Carol's join in the timeline, got %+v", export.Timeline)
+	}
+	if _, ok := export.Users["U555555"]; !ok {
+		t.Error("Expected the timeline member in the user directory")
+	}
+}
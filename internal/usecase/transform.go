@@ -0,0 +1,351 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// maxTransformSteps bounds the Starlark computation per message, so a
+// runaway loop in a script fails the export instead of hanging it
+const maxTransformSteps = 1_000_000
+
+// Transform is a Starlark script that rewrites, drops or tags exported
+// messages. The script defines
+//
+//	def transform(msg):
+//	    ...
+//	    return msg
+//
+// which is called with every message and thread reply as a dict shaped like
+// the export JSON (replies are passed separately). Returning the dict keeps
+// it with any changes, returning None drops the message. tag(msg, "name")
+// adds to msg["tags"]. The predeclared channel dict holds the channel's id
+// and name.
+type Transform struct {
+	name   string
+	source []byte
+	logger *slog.Logger
+}
+
+// LoadTransform reads and runs the script at path and returns its transform
+// function
+func LoadTransform(path string) (*Transform, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transform script: %w", err)
+	}
+	return NewTransform(filepath.Base(path), source)
+}
+
+// NewTransform compiles a transform script. name is used in error messages
+// and recorded in the export metadata.
+func NewTransform(name string, source []byte) (*Transform, error) {
+	t := &Transform{name: name, source: source, logger: slog.Default()}
+	if _, err := t.load(models.ChannelInfo{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// load runs the script for channel and returns its transform function
+func (t *Transform) load(channel models.ChannelInfo) (starlark.Callable, error) {
+	channelDict := starlark.NewDict(2)
+	channelDict.SetKey(starlark.String("id"), starlark.String(channel.ID))
+	channelDict.SetKey(starlark.String("name"), starlark.String(channel.Name))
+	predeclared := starlark.StringDict{
+		"channel": channelDict,
+		"tag":     starlark.NewBuiltin("tag", tagBuiltin),
+	}
+
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, t.thread(), t.name, t.source, predeclared)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transform script: %w", scriptError(err))
+	}
+	fn, ok := globals["transform"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("invalid transform script %s: no transform(msg) function", t.name)
+	}
+	return fn, nil
+}
+
+// tagBuiltin implements tag(msg, *names), which appends names to msg["tags"]
+// unless already present
+func tagBuiltin(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if len(args) < 1 || len(kwargs) > 0 {
+		return nil, fmt.Errorf("%s: expected tag(msg, name, ...)", b.Name())
+	}
+	msg, ok := args[0].(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("%s: msg must be a dict, got %s", b.Name(), args[0].Type())
+	}
+
+	tags := starlark.NewList(nil)
+	if existing, found, _ := msg.Get(starlark.String("tags")); found {
+		if list, ok := existing.(*starlark.List); ok {
+			tags = list
+		}
+	}
+	for _, arg := range args[1:] {
+		name, ok := arg.(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("%s: tag names must be strings, got %s", b.Name(), arg.Type())
+		}
+		present := false
+		for i := 0; i < tags.Len(); i++ {
+			if tags.Index(i) == name {
+				present = true
+			}
+		}
+		if !present {
+			if err := tags.Append(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := msg.SetKey(starlark.String("tags"), tags); err != nil {
+		return nil, err
+	}
+	return starlark.None, nil
+}
+
+// Name returns the file name of the script
+func (t *Transform) Name() string {
+	return t.name
+}
+
+// SetLogger sets the logger that receives the script's print output
+func (t *Transform) SetLogger(logger *slog.Logger) {
+	t.logger = logger
+}
+
+// thread returns a Starlark thread whose print output goes to the logger
+func (t *Transform) thread() *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: "transform",
+		Print: func(_ *starlark.Thread, msg string) {
+			t.logger.Info("transform script", "script", t.name, "message", msg)
+		},
+	}
+	thread.SetMaxExecutionSteps(maxTransformSteps)
+	return thread
+}
+
+// Apply runs the script over messages and their replies. A dropped thread
+// parent takes its replies with it.
+func (t *Transform) Apply(messages []models.ExportMessage, channel models.ChannelInfo) ([]models.ExportMessage, error) {
+	fn, err := t.load(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := []models.ExportMessage{}
+	for _, msg := range messages {
+		replies := msg.Replies
+		msg.Replies = nil
+		changed, ok, err := t.call(fn, msg)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		changed.Replies = nil
+		for _, reply := range replies {
+			changedReply, ok, err := t.call(fn, reply)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				changed.Replies = append(changed.Replies, changedReply)
+			}
+		}
+		kept = append(kept, changed)
+	}
+	return kept, nil
+}
+
+// call runs transform(msg) for one message
+func (t *Transform) call(fn starlark.Callable, msg models.ExportMessage) (models.ExportMessage, bool, error) {
+	value, err := toStarlark(msg)
+	if err != nil {
+		return msg, false, err
+	}
+
+	result, err := starlark.Call(t.thread(), fn, starlark.Tuple{value}, nil)
+	if err != nil {
+		return msg, false, fmt.Errorf("transform script %s failed on message %s: %w", t.name, msg.ID, scriptError(err))
+	}
+	if result == starlark.None {
+		return msg, false, nil
+	}
+	if _, ok := result.(*starlark.Dict); !ok {
+		return msg, false, fmt.Errorf("transform script %s returned %s for message %s, expected a dict or None", t.name, result.Type(), msg.ID)
+	}
+
+	var changed models.ExportMessage
+	if err := fromStarlark(result, &changed); err != nil {
+		return msg, false, fmt.Errorf("transform script %s returned an invalid message %s: %w", t.name, msg.ID, err)
+	}
+	return changed, true, nil
+}
+
+// keptMessages returns the fetched messages and replies that are still in
+// the transformed export, so statistics can be recomputed
+func keptMessages(messages []models.Message, exported []models.ExportMessage) []models.Message {
+	ids := make(map[string]bool)
+	for _, msg := range exported {
+		ids[msg.ID] = true
+		for _, reply := range msg.Replies {
+			ids[msg.ID+"/"+reply.ID] = true
+		}
+	}
+
+	var kept []models.Message
+	for _, msg := range messages {
+		if !ids[msg.Timestamp] {
+			continue
+		}
+		var replies []models.Message
+		for _, reply := range msg.Thread {
+			if ids[msg.Timestamp+"/"+reply.Timestamp] {
+				replies = append(replies, reply)
+			}
+		}
+		msg.Thread = replies
+		kept = append(kept, msg)
+	}
+	return kept
+}
+
+// scriptError adds the Starlark backtrace to evaluation errors
+func scriptError(err error) error {
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		return fmt.Errorf("%s", evalErr.Backtrace())
+	}
+	return err
+}
+
+// toStarlark converts v to Starlark values through its JSON encoding
+func toStarlark(v interface{}) (starlark.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return jsonToStarlark(decoded)
+}
+
+// jsonToStarlark converts a decoded JSON value into a mutable Starlark value
+func jsonToStarlark(v interface{}) (starlark.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return starlark.MakeInt64(n), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return starlark.Float(f), nil
+	case []interface{}:
+		elems := make([]starlark.Value, len(v))
+		for i, elem := range v {
+			value, err := jsonToStarlark(elem)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = value
+		}
+		return starlark.NewList(elems), nil
+	case map[string]interface{}:
+		dict := starlark.NewDict(len(v))
+		for key, elem := range v {
+			value, err := jsonToStarlark(elem)
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(key), value); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	}
+	return nil, fmt.Errorf("unsupported value %T", v)
+}
+
+// fromStarlark decodes a Starlark value into out through JSON
+func fromStarlark(v starlark.Value, out interface{}) error {
+	decoded, err := starlarkToJSON(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(decoded)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// starlarkToJSON converts a Starlark value into a JSON-encodable value
+func starlarkToJSON(v starlark.Value) (interface{}, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Int:
+		if n, ok := v.Int64(); ok {
+			return n, nil
+		}
+		return nil, fmt.Errorf("integer %s out of range", v)
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.Indexable: // list and tuple
+		elems := make([]interface{}, v.Len())
+		for i := range elems {
+			elem, err := starlarkToJSON(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = elem
+		}
+		return elems, nil
+	case *starlark.Dict:
+		m := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			key, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("dict key %s is not a string", item[0])
+			}
+			elem, err := starlarkToJSON(item[1])
+			if err != nil {
+				return nil, err
+			}
+			m[string(key)] = elem
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported value of type %s", v.Type())
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+const testTransformScript = `
+def transform(msg):
+    if "secret" in msg["text"]:
+        return None
+    msg["text"] = msg["text"].replace("@example.com", "@[redacted]")
+    if msg.get("reactions"):
+        tag(msg, "popular", "popular")
+    tag(msg, channel["name"])
+    return msg
+`
+
+func TestTransformApply(t *testing.T) {
+	transform, err := NewTransform("redact.star", []byte(testTransformScript))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	messages := []models.ExportMessage{
+		{ID: "1", Text: "mail bob@example.com", Reactions: []models.ExportReaction{{Name: "+1", Count: 2}}, Replies: []models.ExportMessage{
+			{ID: "2", Text: "the secret is out"},
+			{ID: "3", Text: "thanks"},
+		}},
+		{ID: "4", Text: "secret parent", Replies: []models.ExportMessage{{ID: "5", Text: "dropped with its parent"}}},
+	}
+	result, err := transform.Apply(messages, models.ChannelInfo{ID: "C1", Name: "general"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 message, got %+v", result)
+	}
+	msg := result[0]
+	if msg.Text != "mail bob@[redacted]" || msg.Reactions[0].Count != 2 {
+		t.Errorf("Expected redacted text with reactions kept, got %+v", msg)
+	}
+	if strings.Join(msg.Tags, ",") != "popular,general" {
+		t.Errorf("Expected tags popular,general, got %v", msg.Tags)
+	}
+	if len(msg.Replies) != 1 || msg.Replies[0].ID != "3" || strings.Join(msg.Replies[0].Tags, ",") != "general" {
+		t.Errorf("Expected only the tagged reply 3, got %+v", msg.Replies)
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	if _, err := NewTransform("empty.star", []byte("x = 1\n")); err == nil || !strings.Contains(err.Error(), "no transform(msg) function") {
+		t.Errorf("Expected a missing function error, got %v", err)
+	}
+	if _, err := NewTransform("syntax.star", []byte("def transform(msg)\n")); err == nil {
+		t.Error("Expected a syntax error")
+	}
+
+	loop, err := NewTransform("loop.star", []byte("def transform(msg):\n    for i in range(100000000):\n        pass\n    return msg\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loop.Apply([]models.ExportMessage{{ID: "1"}}, models.ChannelInfo{}); err == nil {
+		t.Error("Expected the step limit to stop a runaway script")
+	}
+
+	wrong, err := NewTransform("wrong.star", []byte("def transform(msg):\n    return 42\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrong.Apply([]models.ExportMessage{{ID: "1"}}, models.ChannelInfo{}); err == nil || !strings.Contains(err.Error(), "expected a dict or None") {
+		t.Errorf("Expected a return type error, got %v", err)
+	}
+}
+
+func TestExportService_ExportChannelTransform(t *testing.T) {
+	transform, err := NewTransform("drop-user.star", []byte("def transform(msg):\n    if msg[\"user\"] == \"U345678\":\n        return None\n    return msg\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	service.SetTransform(transform)
+
+	options := models.ExportOptions{
+		ChannelID:      "C123456",
+		IncludeThreads: true,
+		OutputFile:     filepath.Join(t.TempDir(), "general.json"),
+		Format:         "json",
+	}
+	result, err := service.ExportChannel(context.Background(), options, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	export, err := ReadExportFile(result.OutputFile)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if export.ExportInfo.Transform != "drop-user.star" {
+		t.Errorf("Expected the script recorded in the metadata, got %q", export.ExportInfo.Transform)
+	}
+	if export.Statistics.TotalMessages != 3 || export.Statistics.MessagesByUser["U345678"] != 0 {
+		t.Errorf("Expected statistics of the kept messages, got %d messages by %v", export.Statistics.TotalMessages, export.Statistics.MessagesByUser)
+	}
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// UserGroupClientInterface defines the Slack API operation needed to resolve
+// user group mentions
+type UserGroupClientInterface interface {
+	GetUserGroups(ctx context.Context) ([]models.UserGroup, error)
+}
+
+// subteamMention matches <!subteam^S123> and <!subteam^S123|@handle>
+var subteamMention = regexp.MustCompile(`<!subteam\^([A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// SetUserGroupClient enables ExportOptions.IncludeUserGroups
+func (s *ExportService) SetUserGroupClient(client UserGroupClientInterface) {
+	s.groupClient = client
+}
+
+// collectUserGroups returns the user groups mentioned in message text and
+// attachments, by ID. The group list is only fetched when a mention exists.
+func (s *ExportService) collectUserGroups(ctx context.Context, messages []models.ExportMessage) (map[string]models.UserGroup, error) {
+	mentioned := make(map[string]bool)
+	var collect func([]models.ExportMessage)
+	collect = func(msgs []models.ExportMessage) {
+		for _, msg := range msgs {
+			texts := []string{msg.Text}
+			for _, attachment := range msg.Attachments {
+				texts = append(texts, attachment.Text, attachment.Pretext)
+			}
+			for _, text := range texts {
+				for _, match := range subteamMention.FindAllStringSubmatch(text, -1) {
+					mentioned[match[1]] = true
+				}
+			}
+			collect(msg.Replies)
+		}
+	}
+	collect(messages)
+	if len(mentioned) == 0 {
+		return nil, nil
+	}
+
+	if s.groupClient == nil {
+		return nil, fmt.Errorf("no user group client configured")
+	}
+	groups, err := s.groupClient.GetUserGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]models.UserGroup, len(mentioned))
+	for _, group := range groups {
+		if mentioned[group.ID] {
+			result[group.ID] = group
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockUserGroupClient returns fixed user groups and counts the lookups
+type MockUserGroupClient struct {
+	groups []models.UserGroup
+	calls  int
+}
+
+func (m *MockUserGroupClient) GetUserGroups(ctx context.Context) ([]models.UserGroup, error) {
+	m.calls++
+	return m.groups, nil
+}
+
+func TestExportService_collectUserGroups(t *testing.T) {
+	client := &MockUserGroupClient{groups: []models.UserGroup{
+		{ID: "S1", Handle: "oncall", Name: "On-call", Users: []string{"U1", "U2"}},
+		{ID: "S2", Handle: "design", Name: "Design"},
+	}}
+	service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+	service.SetUserGroupClient(client)
+
+	groups, err := service.collectUserGroups(context.Background(), []models.ExportMessage{
+		{Text: "no mentions"},
+		{Text: "parent", Replies: []models.ExportMessage{{Text: "paging <!subteam^S1|@oncall>"}}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(groups) != 1 || groups["S1"].Handle != "oncall" {
+		t.Errorf("Expected only the mentioned group, got %v", groups)
+	}
+
+	groups, err = service.collectUserGroups(context.Background(), []models.ExportMessage{{Text: "no mentions"}})
+	if err != nil || groups != nil {
+		t.Errorf("Expected no groups without mentions, got %v, %v", groups, err)
+	}
+	if client.calls != 1 {
+		t.Errorf("Expected the group list to be fetched only when mentioned, got %d calls", client.calls)
+	}
+
+	text := plainText("ping <!subteam^S1> and <!subteam^S9|@ghosts>", nil, map[string]models.UserGroup{"S1": {ID: "S1", Handle: "oncall"}})
+	if text != "ping @oncall and @ghosts" {
+		t.Errorf("Expected group mentions as handles, got %q", text)
+	}
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// WatchClientInterface defines the Slack API operations needed to follow a channel
+type WatchClientInterface interface {
+	GetMessagesSince(ctx context.Context, channelID, oldest string) ([]models.Message, error)
+}
+
+// WatchService follows a channel and reports new messages as they appear
+type WatchService struct {
+	slackClient WatchClientInterface
+}
+
+// NewWatchService creates a new watch service
+func NewWatchService(slackClient WatchClientInterface) *WatchService {
+	return &WatchService{
+		slackClient: slackClient,
+	}
+}
+
+// WatchOptions defines options for following a channel
+type WatchOptions struct {
+	ChannelID string
+	Interval  time.Duration
+	// Since is the Slack timestamp after which messages are reported.
+	// When empty, only messages posted after the watch starts are reported.
+	Since string
+}
+
+// Watch polls the channel history until ctx is cancelled, calling handler for
+// every new message in chronological order
+func (s *WatchService) Watch(ctx context.Context, opts WatchOptions, handler func(models.Message)) error {
+	if opts.ChannelID == "" {
+		return fmt.Errorf("channel ID must be provided")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+
+	oldest := opts.Since
+	if oldest == "" {
+		oldest = FormatSlackTimestamp(time.Now())
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		messages, err := s.slackClient.GetMessagesSince(ctx, opts.ChannelID, oldest)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to poll channel: %w", err)
+		}
+
+		for _, msg := range messages {
+			if msg.Timestamp <= oldest {
+				continue
+			}
+			handler(msg)
+			oldest = msg.Timestamp
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// FormatSlackTimestamp formats a time as a Slack timestamp string
+func FormatSlackTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/1000)
+}
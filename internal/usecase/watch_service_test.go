@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockWatchClient returns a new batch of messages on every poll
+type MockWatchClient struct {
+	batches [][]models.Message
+	polls   int
+	oldest  []string
+}
+
+func (m *MockWatchClient) GetMessagesSince(ctx context.Context, channelID, oldest string) ([]models.Message, error) {
+	m.oldest = append(m.oldest, oldest)
+	if m.polls >= len(m.batches) {
+		return nil, nil
+	}
+	batch := m.batches[m.polls]
+	m.polls++
+	return batch, nil
+}
+
+func TestWatchService_Watch(t *testing.T) {
+	mockClient := &MockWatchClient{
+		batches: [][]models.Message{
+			{
+				{User: "U123456", Text: "first", Timestamp: "1704067200.000001"},
+				{User: "U789012", Text: "second", Timestamp: "1704067201.000000"},
+			},
+			{
+				{User: "U789012", Text: "second", Timestamp: "1704067201.000000"}, // duplicate from the boundary
+				{User: "U123456", Text: "third", Timestamp: "1704067202.000000"},
+			},
+		},
+	}
+	service := NewWatchService(mockClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received []models.Message
+	opts := WatchOptions{
+		ChannelID: "C123456",
+		Interval:  time.Millisecond,
+		Since:     "1704067200.000000",
+	}
+	err := service.Watch(ctx, opts, func(msg models.Message) {
+		received = append(received, msg)
+		if len(received) == 3 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(received) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(received))
+	}
+	if received[2].Text != "third" {
+		t.Errorf("Expected last message 'third', got %s", received[2].Text)
+	}
+
+	if mockClient.oldest[0] != "1704067200.000000" {
+		t.Errorf("Expected first poll from Since timestamp, got %s", mockClient.oldest[0])
+	}
+	if mockClient.oldest[1] != "1704067201.000000" {
+		t.Errorf("Expected second poll from last seen timestamp, got %s", mockClient.oldest[1])
+	}
+}
+
+func TestWatchService_WatchRequiresChannel(t *testing.T) {
+	service := NewWatchService(&MockWatchClient{})
+
+	err := service.Watch(context.Background(), WatchOptions{}, func(models.Message) {})
+	if err == nil {
+		t.Error("Expected error when channel ID is missing")
+	}
+}
+
+func TestFormatSlackTimestamp(t *testing.T) {
+	ts := time.Unix(1704067200, 123456000)
+	if got := FormatSlackTimestamp(ts); got != "1704067200.123456" {
+		t.Errorf("Expected '1704067200.123456', got %s", got)
+	}
+}
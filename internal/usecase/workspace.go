@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// WorkspaceClientInterface defines the Slack API operations needed to record
+// the workspace and the exporting user in export metadata
+type WorkspaceClientInterface interface {
+	GetWorkspace(ctx context.Context) (*models.ExportWorkspace, error)
+	GetIdentity(ctx context.Context) (*models.ExportIdentity, error)
+}
+
+// SetWorkspaceClient records the workspace and the authenticated user or bot
+// in the metadata of every export
+func (s *ExportService) SetWorkspaceClient(client WorkspaceClientInterface) {
+	s.workspaceClient = client
+}
+
+// lookupAccount describes the authenticated identity and its workspace.
+// Failed lookups are returned as warnings; the export continues without them.
+func (s *ExportService) lookupAccount(ctx context.Context, options models.ExportOptions) (*models.ExportIdentity, *models.ExportWorkspace, []string) {
+	if s.workspaceClient == nil {
+		return nil, nil, nil
+	}
+
+	var warnings []string
+	identity, err := s.workspaceClient.GetIdentity(ctx)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("exporting identity unavailable: %v", err))
+	}
+	workspace, err := s.workspaceClient.GetWorkspace(ctx)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("workspace info unavailable: %v", err))
+	}
+	return identity, workspace, warnings
+}
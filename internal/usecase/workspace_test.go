@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/itcaat/slacker/models"
+)
+
+// MockWorkspaceClient returns a fixed workspace and identity and an
+// optional error
+type MockWorkspaceClient struct {
+	workspace *models.ExportWorkspace
+	identity  *models.ExportIdentity
+	err       error
+}
+
+func (m *MockWorkspaceClient) GetWorkspace(ctx context.Context) (*models.ExportWorkspace, error) {
+	return m.workspace, m.err
+}
+
+func (m *MockWorkspaceClient) GetIdentity(ctx context.Context) (*models.ExportIdentity, error) {
+	return m.identity, m.err
+}
+
+func TestExportService_Workspace(t *testing.T) {
+	tests := []struct {
+		name          string
+		client        *MockWorkspaceClient
+		wantWorkspace string
+		wantBy        string
+		wantPartial   bool
+	}{
+		{
+			"workspace and identity",
+			&MockWorkspaceClient{
+				workspace: &models.ExportWorkspace{ID: "T1", Name: "Acme", Domain: "acme"},
+				identity:  &models.ExportIdentity{UserID: "U1", User: "archiver", BotID: "B1", TeamID: "T1", Team: "Acme"},
+			},
+			"T1", "archiver", false,
+		},
+		{"lookup failed", &MockWorkspaceClient{err: fmt.Errorf("auth.test failed")}, "", "slacker-cli", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewExportService(NewMockSlackClient(), "1.0.0-test")
+			service.SetWorkspaceClient(tt.client)
+
+			result, err := service.ExportChannel(context.Background(), models.ExportOptions{
+				ChannelID:  "C123456",
+				OutputFile: filepath.Join(t.TempDir(), "general.json"),
+				Format:     "json",
+			}, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			export, err := ReadExportFile(result.OutputFile)
+			if err != nil {
+				t.Fatalf("Failed to read export: %v", err)
+			}
+
+			workspace := ""
+			if export.ExportInfo.Workspace != nil {
+				workspace = export.ExportInfo.Workspace.ID
+			}
+			if workspace != tt.wantWorkspace {
+				t.Errorf("Expected workspace %q, got %+v", tt.wantWorkspace, export.ExportInfo.Workspace)
+			}
+			if export.ExportInfo.ExportedBy != tt.wantBy {
+				t.Errorf("Expected exported_by %q, got %q", tt.wantBy, export.ExportInfo.ExportedBy)
+			}
+			if tt.wantBy != "slacker-cli" && (export.ExportInfo.Exporter == nil || export.ExportInfo.Exporter.BotID != "B1") {
+				t.Errorf("Expected the exporting bot identity, got %+v", export.ExportInfo.Exporter)
+			}
+			if export.ExportInfo.Partial != tt.wantPartial {
+				t.Errorf("Expected partial=%v, got %v", tt.wantPartial, export.ExportInfo.Partial)
+			}
+			// Failed lookups are listed without marking the export partial
+			if tt.client.err != nil && (len(export.ExportInfo.Warnings) != 2 || result.Partial) {
+				t.Errorf("Expected 2 non-partial warnings, got %v (partial=%v)", export.ExportInfo.Warnings, result.Partial)
+			}
+		})
+	}
+}
@@ -5,11 +5,12 @@ import (
 	"os"
 
 	"github.com/itcaat/slacker/cmd"
+	"github.com/itcaat/slacker/models"
 )
 
 func main() {
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(models.ExitCodeOf(err))
 	}
 }
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxChannelSuggestions is how many similar names a not-found error offers
+const maxChannelSuggestions = 3
+
+// FindChannel finds a channel by name. A leading "#" is ignored and names
+// match case-insensitively when no channel has the exact name.
+func FindChannel(channels []Channel, name string) *Channel {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "#")
+	for i := range channels {
+		if channels[i].Name == name {
+			return &channels[i]
+		}
+	}
+	for i := range channels {
+		if strings.EqualFold(channels[i].Name, name) {
+			return &channels[i]
+		}
+	}
+	return nil
+}
+
+// SuggestChannels returns the names of up to three channels that look like
+// name, closest first: names containing it, then names within a few typos
+func SuggestChannels(channels []Channel, name string) []string {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	if name == "" {
+		return nil
+	}
+
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	var suggestions []suggestion
+	maxDistance := max(2, len(name)/3)
+	for _, channel := range channels {
+		candidate := strings.ToLower(channel.Name)
+		distance := editDistance(name, candidate)
+		if strings.Contains(candidate, name) || strings.Contains(name, candidate) {
+			distance = 0
+		} else if distance > maxDistance {
+			continue
+		}
+		suggestions = append(suggestions, suggestion{name: channel.Name, distance: distance})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].distance != suggestions[j].distance {
+			return suggestions[i].distance < suggestions[j].distance
+		}
+		return suggestions[i].name < suggestions[j].name
+	})
+
+	var names []string
+	for i := 0; i < len(suggestions) && i < maxChannelSuggestions; i++ {
+		names = append(names, suggestions[i].name)
+	}
+	return names
+}
+
+// NewChannelNotFoundError reports that no channel is called name, offering
+// similar channel names and then hint, if any
+func NewChannelNotFoundError(channels []Channel, name, hint string) error {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "#")
+	message := fmt.Sprintf("channel '%s' not found", name)
+	if suggestions := SuggestChannels(channels, name); len(suggestions) > 0 {
+		message += fmt.Sprintf(" (did you mean #%s?)", strings.Join(suggestions, ", #"))
+	}
+	if hint != "" {
+		message += ". " + hint
+	}
+	return NewExportError(ErrorCategoryChannelNotFound, message, nil)
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
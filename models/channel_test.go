@@ -0,0 +1,78 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindChannel(t *testing.T) {
+	channels := []Channel{
+		{ID: "C1", Name: "general"},
+		{ID: "C2", Name: "general-chat"},
+		{ID: "C3", Name: "Random"},
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Exact name", input: "general", expected: "C1"},
+		{name: "Leading hash", input: "#general-chat", expected: "C2"},
+		{name: "Case-insensitive", input: "random", expected: "C3"},
+		{name: "Unknown", input: "genral", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := FindChannel(channels, tt.input)
+			if tt.expected == "" {
+				if channel != nil {
+					t.Errorf("Expected no channel for '%s', got %s", tt.input, channel.ID)
+				}
+				return
+			}
+			if channel == nil || channel.ID != tt.expected {
+				t.Errorf("Expected %s for '%s', got %+v", tt.expected, tt.input, channel)
+			}
+		})
+	}
+}
+
+func TestSuggestChannels(t *testing.T) {
+	channels := []Channel{
+		{Name: "general"},
+		{Name: "general-chat"},
+		{Name: "random"},
+		{Name: "dev-ops"},
+	}
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "generl", expected: []string{"general"}},
+		{input: "chat", expected: []string{"general-chat"}},
+		{input: "#devops", expected: []string{"dev-ops"}},
+		{input: "marketing", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := SuggestChannels(channels, tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v for '%s', got %v", tt.expected, tt.input, got)
+			}
+		})
+	}
+}
+
+func TestNewChannelNotFoundError(t *testing.T) {
+	err := NewChannelNotFoundError([]Channel{{Name: "general-chat"}}, "#general-cht", "")
+	if ErrorCategoryOf(err) != ErrorCategoryChannelNotFound {
+		t.Errorf("Expected channel_not_found, got %v", ErrorCategoryOf(err))
+	}
+	if !strings.Contains(err.Error(), "did you mean #general-chat?") {
+		t.Errorf("Expected a suggestion, got %q", err.Error())
+	}
+}
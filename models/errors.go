@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCategory classifies failures so automation can react without parsing messages
+type ErrorCategory string
+
+const (
+	ErrorCategoryUnknown         ErrorCategory = "unknown"
+	ErrorCategoryAuth            ErrorCategory = "auth"
+	ErrorCategoryChannelNotFound ErrorCategory = "channel_not_found"
+	ErrorCategoryRateLimited     ErrorCategory = "rate_limited"
+	ErrorCategoryPartialExport   ErrorCategory = "partial_export"
+	ErrorCategoryIO              ErrorCategory = "io"
+	ErrorCategoryInterrupted     ErrorCategory = "interrupted"
+)
+
+// Process exit codes for each error category
+const (
+	ExitCodeSuccess         = 0
+	ExitCodeError           = 1
+	ExitCodeAuth            = 3
+	ExitCodeChannelNotFound = 4
+	ExitCodeRateLimited     = 5
+	ExitCodePartialExport   = 6
+	ExitCodeIO              = 7
+	ExitCodeInterrupted     = 8
+	// ExitCodeDiffers is returned by 'slacker diff --exit-code' when the
+	// exports differ, so scripts can tell differences from failures
+	ExitCodeDiffers = 9
+)
+
+// ExitCode returns the process exit code for the category
+func (c ErrorCategory) ExitCode() int {
+	switch c {
+	case ErrorCategoryAuth:
+		return ExitCodeAuth
+	case ErrorCategoryChannelNotFound:
+		return ExitCodeChannelNotFound
+	case ErrorCategoryRateLimited:
+		return ExitCodeRateLimited
+	case ErrorCategoryPartialExport:
+		return ExitCodePartialExport
+	case ErrorCategoryIO:
+		return ExitCodeIO
+	case ErrorCategoryInterrupted:
+		return ExitCodeInterrupted
+	default:
+		return ExitCodeError
+	}
+}
+
+// ErrMissingScope is wrapped by errors of API calls the token lacks the
+// OAuth scope for, so optional data can be skipped without failing an export
+var ErrMissingScope = errors.New("missing OAuth scope")
+
+// ExportError is an error tagged with a category
+type ExportError struct {
+	Category ErrorCategory
+	Message  string
+	Err      error
+}
+
+// NewExportError creates a categorized error. Message describes the failed
+// operation and err, if not nil, is the underlying cause.
+func NewExportError(category ErrorCategory, message string, err error) *ExportError {
+	return &ExportError{
+		Category: category,
+		Message:  message,
+		Err:      err,
+	}
+}
+
+func (e *ExportError) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	if e.Message == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Message, e.Err)
+}
+
+func (e *ExportError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCategoryOf returns the category of the first ExportError in err's
+// chain, or ErrorCategoryUnknown
+func ErrorCategoryOf(err error) ErrorCategory {
+	var exportErr *ExportError
+	if errors.As(err, &exportErr) {
+		return exportErr.Category
+	}
+	return ErrorCategoryUnknown
+}
+
+// ExitCodeOf returns the process exit code for err
+func ExitCodeOf(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+	return ErrorCategoryOf(err).ExitCode()
+}
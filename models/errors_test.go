@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExportError(t *testing.T) {
+	cause := errors.New("invalid_auth")
+	err := NewExportError(ErrorCategoryAuth, "failed to get channels", cause)
+
+	if err.Error() != "failed to get channels: invalid_auth" {
+		t.Errorf("Unexpected error message: %s", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected error to unwrap to its cause")
+	}
+
+	wrapped := fmt.Errorf("export failed: %w", err)
+	if category := ErrorCategoryOf(wrapped); category != ErrorCategoryAuth {
+		t.Errorf("Expected auth category through wrapping, got %s", category)
+	}
+}
+
+func TestExitCodeOf(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitCodeSuccess},
+		{errors.New("boom"), ExitCodeError},
+		{NewExportError(ErrorCategoryAuth, "token expired", nil), ExitCodeAuth},
+		{NewExportError(ErrorCategoryChannelNotFound, "channel 'x' not found", nil), ExitCodeChannelNotFound},
+		{NewExportError(ErrorCategoryRateLimited, "", errors.New("ratelimited")), ExitCodeRateLimited},
+		{NewExportError(ErrorCategoryPartialExport, "1 of 2 channels failed", nil), ExitCodePartialExport},
+		{fmt.Errorf("write: %w", NewExportError(ErrorCategoryIO, "", errors.New("disk full"))), ExitCodeIO},
+		{NewExportError(ErrorCategoryInterrupted, "export interrupted", nil), ExitCodeInterrupted},
+	}
+
+	for _, tt := range tests {
+		if code := ExitCodeOf(tt.err); code != tt.expected {
+			t.Errorf("Expected exit code %d for %v, got %d", tt.expected, tt.err, code)
+		}
+	}
+}
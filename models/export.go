@@ -1,7 +1,10 @@
 package models
 
 import (
+	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,18 +24,320 @@ type ChannelExport struct {
 
 	// Export statistics
 	Statistics ExportStatistics `json:"statistics"`
+
+	// Changes to earlier messages since the previous tracked export
+	Changes *ExportChanges `json:"changes,omitempty"`
+
+	// Custom emoji used in reactions and message text, by name
+	Emoji map[string]ExportEmoji `json:"emoji,omitempty"`
+
+	// Canvases and posts shared in the channel, with their content
+	Canvases []ExportCanvas `json:"canvases,omitempty"`
+
+	// User groups mentioned in message text, by ID
+	UserGroups map[string]UserGroup `json:"usergroups,omitempty"`
+
+	// Bots and apps that posted messages, by bot ID
+	Bots map[string]Bot `json:"bots,omitempty"`
+
+	// LLM-generated summaries of each day or thread
+	Summaries []ExportSummary `json:"summaries,omitempty"`
+
+	// URLs shared in messages and replies, oldest first
+	Links []ExportLink `json:"links,omitempty"`
+
+	// Joins, departures, topic, purpose and name changes, oldest first
+	Timeline []ChannelEvent `json:"channel_timeline,omitempty"`
+
+	// Count and hash of the messages. It is the last field so that it is
+	// written last.
+	Integrity *ExportIntegrity `json:"integrity,omitempty"`
+}
+
+// ExportIntegrity lets consumers detect truncated or modified exports without
+// a manifest. SHA256 is the hex SHA-256 of the compact JSON encoding of the
+// messages array and Messages is its length.
+type ExportIntegrity struct {
+	Messages int    `json:"messages"`
+	SHA256   string `json:"sha256"`
+}
+
+// Channel timeline event types
+const (
+	ChannelEventJoin      = "join"
+	ChannelEventLeave     = "leave"
+	ChannelEventTopic     = "topic"
+	ChannelEventPurpose   = "purpose"
+	ChannelEventRename    = "rename"
+	ChannelEventArchive   = "archive"
+	ChannelEventUnarchive = "unarchive"
+)
+
+// ChannelEvent is a change to the channel recorded in its history. Value is
+// the new topic, purpose or name; Previous is the one it replaced when the
+// history shows it.
+type ChannelEvent struct {
+	Type      string    `json:"type"`
+	User      string    `json:"user,omitempty"`
+	UserName  string    `json:"user_name,omitempty"`
+	Inviter   string    `json:"inviter,omitempty"`
+	Value     string    `json:"value,omitempty"`
+	Previous  string    `json:"previous,omitempty"`
+	MessageTS string    `json:"ts"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ExportLink is a URL shared in a message. Reactions counts the reactions of
+// the message, as a measure of how well the link was received.
+type ExportLink struct {
+	URL       string    `json:"url"`
+	Domain    string    `json:"domain"`
+	User      string    `json:"user,omitempty"`
+	UserName  string    `json:"user_name,omitempty"`
+	MessageTS string    `json:"ts"`
+	ThreadTS  string    `json:"thread_ts,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Reactions int       `json:"reactions"`
+	Permalink string    `json:"permalink,omitempty"`
+}
+
+// Summary scopes for ExportOptions.SummarizeBy
+const (
+	SummarizeByDay    = "day"
+	SummarizeByThread = "thread"
+)
+
+// ExportSummary is the summary of a day or a thread of the channel, written
+// by the configured LLM endpoint
+type ExportSummary struct {
+	Scope    string    `json:"scope"`
+	Date     string    `json:"date,omitempty"`      // Day summaries, YYYY-MM-DD
+	ThreadTS string    `json:"thread_ts,omitempty"` // Thread summaries
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Messages int       `json:"messages"`
+	Model    string    `json:"model,omitempty"`
+	Summary  string    `json:"summary"`
+}
+
+// ExportCanvas is a channel canvas or post with its content converted to
+// Markdown. ChannelCanvas marks the canvas shown in the channel header.
+type ExportCanvas struct {
+	ID            string     `json:"id"`
+	Title         string     `json:"title,omitempty"`
+	Filetype      string     `json:"filetype,omitempty"`
+	User          string     `json:"user,omitempty"`
+	Created       *time.Time `json:"created,omitempty"`
+	Permalink     string     `json:"permalink,omitempty"`
+	ChannelCanvas bool       `json:"channel_canvas,omitempty"`
+	Markdown      string     `json:"markdown"`
+}
+
+// ExportEmoji describes a custom emoji used in the export. File is the
+// downloaded image relative to the export file.
+type ExportEmoji struct {
+	URL      string `json:"url,omitempty"`
+	AliasFor string `json:"alias_for,omitempty"`
+	File     string `json:"file,omitempty"`
+}
+
+// ExportIndex lists the part files of a split export. Statistics cover the
+// whole export.
+type ExportIndex struct {
+	ExportInfo ExportMetadata   `json:"export_info"`
+	Channel    ChannelInfo      `json:"channel"`
+	SplitBy    string           `json:"split_by"`
+	Parts      []ExportPart     `json:"parts"`
+	Statistics ExportStatistics `json:"statistics"`
+	Changes    *ExportChanges   `json:"changes,omitempty"`
+}
+
+// ExportPart describes one file of a split export
+type ExportPart struct {
+	File     string    `json:"file"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Messages int       `json:"messages"`
+	FileSize int64     `json:"file_size"`
+}
+
+// ExportManifest records the files produced by an export with their SHA-256
+// checksums, so archives can be verified later. The checksums alone only
+// detect corruption, since anyone who modifies a file can update them;
+// HMAC, when the export had a key, also detects deliberate changes.
+type ExportManifest struct {
+	CreatedAt      time.Time      `json:"created_at"`
+	SlackerVersion string         `json:"slacker_version"`
+	Channel        ChannelInfo    `json:"channel"`
+	Options        ExportOptions  `json:"options"`
+	Warnings       []string       `json:"warnings,omitempty"`
+	Files          []ManifestFile `json:"files"`
+	// HMAC is the hex HMAC-SHA256 of the manifest's compact JSON encoding
+	// without this field
+	HMAC string `json:"hmac_sha256,omitempty"`
+}
+
+// ManifestFile is one file listed in an export manifest. Path is relative to
+// the manifest.
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
 }
 
+// MessageVersion is the recorded state of a message, used to detect edits
+// and deletions between exports
+type MessageVersion struct {
+	User   string `json:"user,omitempty"`
+	Text   string `json:"text"`
+	Edited string `json:"edited,omitempty"`
+}
+
+// ExportChanges lists messages that were deleted or edited since the previous export
+type ExportChanges struct {
+	Since   time.Time       `json:"since,omitempty"`
+	Deleted []DeletedChange `json:"deleted,omitempty"`
+	Edited  []EditedChange  `json:"edited,omitempty"`
+}
+
+// DeletedChange records a message that disappeared or became a tombstone
+type DeletedChange struct {
+	Timestamp string `json:"ts"`
+	User      string `json:"user,omitempty"`
+	Text      string `json:"text"`
+}
+
+// EditedChange records the previous and current text of an edited message
+type EditedChange struct {
+	Timestamp    string `json:"ts"`
+	User         string `json:"user,omitempty"`
+	PreviousText string `json:"previous_text"`
+	Text         string `json:"text"`
+	EditedAt     string `json:"edited_at,omitempty"`
+}
+
+// Version and location of the JSON Schema of export files. The version
+// changes whenever the format changes incompatibly.
+const (
+	ExportSchemaVersion = "1"
+	ExportSchemaURL     = "https://raw.githubusercontent.com/itcaat/slacker/main/schema/channel-export.schema.json"
+	IndexSchemaURL      = "https://raw.githubusercontent.com/itcaat/slacker/main/schema/export-index.schema.json"
+)
+
 // ExportMetadata contains information about the export itself
 type ExportMetadata struct {
+	// SchemaURL and SchemaVersion identify the JSON Schema the file follows
+	SchemaURL     string `json:"schema_url,omitempty"`
+	SchemaVersion string `json:"schema_version,omitempty"`
+
 	ExportedAt     time.Time `json:"exported_at"`
-	ExportedBy     string    `json:"exported_by"`
+	ExportedBy     string    `json:"exported_by"` // Authenticated user name, or "slacker-cli" offline
 	SlackerVersion string    `json:"slacker_version"`
 	ExportFormat   string    `json:"export_format"`
 	IncludeThreads bool      `json:"include_threads"`
 	DateRange      DateRange `json:"date_range,omitempty"`
+	Timezone       string    `json:"timezone,omitempty"`
+
+	// FlatReplies is set when thread replies are top-level messages linked to
+	// their parent by thread_ts instead of nested under replies
+	FlatReplies bool `json:"flat_replies,omitempty"`
+
+	// Workspace identifies the Slack workspace the channel belongs to and
+	// Exporter the user or bot whose token took the export
+	Workspace *ExportWorkspace `json:"workspace,omitempty"`
+	Exporter  *ExportIdentity  `json:"exporter,omitempty"`
+
+	// Partial is set when some data could not be fetched; Warnings lists what is missing
+	Partial  bool     `json:"partial,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Filters records the message filters applied to the export
+	Filters *ExportFilters `json:"filters,omitempty"`
+	// Transform is the file name of the transform script applied to messages
+	Transform string `json:"transform,omitempty"`
+
+	// Truncated is set when a message or time limit ended the export early
+	Truncated *ExportTruncation `json:"truncated,omitempty"`
 }
 
+// Reasons an export was truncated
+const (
+	TruncatedByMaxMessages = "max_messages"
+	TruncatedByMaxDuration = "max_duration"
+	TruncatedByInterrupt   = "interrupt"
+)
+
+// ExportTruncation records which limit cut an export short. The export holds
+// the newest messages down to OldestMessage.
+type ExportTruncation struct {
+	Reason         string `json:"reason"`
+	Limit          string `json:"limit"`
+	OldestMessage  string `json:"oldest_message,omitempty"`
+	ThreadsSkipped int    `json:"threads_skipped,omitempty"`
+}
+
+// ExportCheckpoint is the state of an export stopped by a signal. It is
+// written next to the partial output so the export can be resumed.
+type ExportCheckpoint struct {
+	ChannelID string `json:"channel_id"`
+	// OutputFile is the output the completed export is written to
+	OutputFile    string    `json:"output_file"`
+	PartialFile   string    `json:"partial_file"`
+	InterruptedAt time.Time `json:"interrupted_at"`
+	// Cursor is the next page of the channel history, empty once the
+	// history was complete
+	Cursor string `json:"cursor,omitempty"`
+	// PendingThreads are the threads whose replies were not fetched yet
+	PendingThreads []string `json:"pending_threads,omitempty"`
+	// Messages are the messages fetched so far with their replies
+	Messages []Message `json:"messages"`
+}
+
+// ExportWorkspace describes the Slack workspace (team) an export was taken from
+type ExportWorkspace struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Domain string `json:"domain,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// ExportIdentity is the user or bot a token authenticates as, as reported by
+// auth.test
+type ExportIdentity struct {
+	UserID       string `json:"user_id"`
+	User         string `json:"user"`
+	BotID        string `json:"bot_id,omitempty"`
+	TeamID       string `json:"team_id"`
+	Team         string `json:"team"`
+	EnterpriseID string `json:"enterprise_id,omitempty"`
+}
+
+// ExportFilters describes which messages an export was restricted to
+type ExportFilters struct {
+	Users               []string          `json:"users,omitempty"`
+	Match               string            `json:"match,omitempty"`
+	ExcludeSubtypes     []string          `json:"exclude_subtypes,omitempty"`
+	SubtypePolicy       map[string]string `json:"subtype_policy,omitempty"`
+	Bots                string            `json:"bots,omitempty"`
+	MinReactions        int               `json:"min_reactions,omitempty"`
+	ExcludeExternal     bool              `json:"exclude_external,omitempty"`
+	ThreadsOnly         bool              `json:"threads_only,omitempty"`
+	ParticipatedThreads bool              `json:"participated_threads,omitempty"`
+}
+
+// Bot filters for ExportOptions.Bots
+const (
+	BotFilterExclude = "exclude"
+	BotFilterOnly    = "only"
+)
+
+// Subtype policy actions for ExportOptions.SubtypePolicy
+const (
+	SubtypeInclude   = "include"
+	SubtypeExclude   = "exclude"
+	SubtypeTransform = "transform"
+)
+
 // DateRange represents the time range of exported messages
 type DateRange struct {
 	From *time.Time `json:"from,omitempty"`
@@ -41,16 +346,22 @@ type DateRange struct {
 
 // ChannelInfo contains detailed information about the exported channel
 type ChannelInfo struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	IsPrivate  bool      `json:"is_private"`
-	IsArchived bool      `json:"is_archived"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsPrivate  bool   `json:"is_private"`
+	IsArchived bool   `json:"is_archived"`
+	// State is active, archived or deleted (exported from the local store)
+	State      string    `json:"state,omitempty"`
 	Topic      string    `json:"topic,omitempty"`
 	Purpose    string    `json:"purpose,omitempty"`
 	CreatedAt  time.Time `json:"created_at,omitempty"`
 	Creator    string    `json:"creator,omitempty"`
 	NumMembers int       `json:"num_members"`
 	Members    []string  `json:"members,omitempty"`
+
+	// Slack Connect channels shared with other organizations
+	IsExtShared      bool     `json:"is_ext_shared,omitempty"`
+	ConnectedTeamIDs []string `json:"connected_team_ids,omitempty"`
 }
 
 // ExportMessage represents a message in the export with enhanced structure
@@ -66,6 +377,11 @@ type ExportMessage struct {
 	Subtype string    `json:"subtype,omitempty"`
 	Edited  *EditInfo `json:"edited,omitempty"`
 
+	// Bot messages: the bot that posted the message and the name it posted
+	// under, or the bot's name from the bots directory
+	BotID    string `json:"bot_id,omitempty"`
+	Username string `json:"username,omitempty"`
+
 	// Thread information
 	ThreadTimestamp string     `json:"thread_ts,omitempty"`
 	ParentUserID    string     `json:"parent_user_id,omitempty"`
@@ -77,16 +393,70 @@ type ExportMessage struct {
 	// Thread replies (nested structure)
 	Replies []ExportMessage `json:"replies,omitempty"`
 
+	// InThread marks the channel copy of a thread_broadcast reply. Its
+	// content is exported once, among the replies of the thread_ts parent.
+	InThread bool `json:"in_thread,omitempty"`
+	// BroadcastOf is the thread_ts of the thread a thread_broadcast channel
+	// copy was sent from. Copies whose thread is not part of the export keep
+	// their content, so the field is the only link to the thread.
+	BroadcastOf string `json:"broadcast_of,omitempty"`
+	// Omitted marks a thread parent left out by the export filters. Only
+	// its timestamp and thread details are kept, so the replies that
+	// passed the filters stay in their thread.
+	Omitted bool `json:"omitted,omitempty"`
+	// Tags are labels added by a transform script
+	Tags []string `json:"tags,omitempty"`
+	// System marks system messages transformed by the subtype policy
+	System bool `json:"system,omitempty"`
+
 	// Rich content
 	Attachments []ExportAttachment `json:"attachments,omitempty"`
 	Files       []ExportFile       `json:"files,omitempty"`
 	Reactions   []ExportReaction   `json:"reactions,omitempty"`
 
+	// Huddle, call, workflow and canvas details
+	Call     *ExportCall `json:"call,omitempty"`
+	Workflow *Workflow   `json:"workflow,omitempty"`
+	Canvas   *Canvas     `json:"canvas,omitempty"`
+
+	// LinkSnapshots preserve what the URLs shared in the message pointed
+	// to at export time (see ExportOptions.SnapshotLinks)
+	LinkSnapshots []ExportLinkSnapshot `json:"link_snapshots,omitempty"`
+
 	// Message context
 	Permalink   string `json:"permalink,omitempty"`
 	ClientMsgID string `json:"client_msg_id,omitempty"`
 }
 
+// ExportLinkSnapshot is the title and description of a shared page at export
+// time, and optionally a web archive copy, so the archive keeps the context
+// of links that later break
+type ExportLinkSnapshot struct {
+	URL string `json:"url"`
+	// FinalURL is where the URL redirected to, when it differs
+	FinalURL    string `json:"final_url,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	// ArchiveURL is the copy made by ExportOptions.SnapshotService
+	ArchiveURL string    `json:"archive_url,omitempty"`
+	FetchedAt  time.Time `json:"fetched_at"`
+	// Error tells why the page or its archive copy could not be fetched
+	Error string `json:"error,omitempty"`
+}
+
+// ExportCall describes a huddle or call in the export
+type ExportCall struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name,omitempty"`
+	CreatedBy    string     `json:"created_by,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	Duration     int64      `json:"duration_seconds,omitempty"`
+	Participants []string   `json:"participants,omitempty"`
+}
+
 // EditInfo contains information about message edits
 type EditInfo struct {
 	User      string    `json:"user"`
@@ -111,6 +481,12 @@ type ExportAttachment struct {
 	FooterIcon string            `json:"footer_icon,omitempty"`
 	Timestamp  *time.Time        `json:"ts,omitempty"`
 	Fields     []AttachmentField `json:"fields,omitempty"`
+
+	// Link unfurl source
+	ServiceName string `json:"service_name,omitempty"`
+	ServiceIcon string `json:"service_icon,omitempty"`
+	FromURL     string `json:"from_url,omitempty"`
+	OriginalURL string `json:"original_url,omitempty"`
 }
 
 // AttachmentField represents a field in an attachment
@@ -158,8 +534,32 @@ type ExportFile struct {
 	OriginalH          int       `json:"original_h,omitempty"`
 	ThumbW             int       `json:"thumb_w,omitempty"`
 	ThumbH             int       `json:"thumb_h,omitempty"`
+	// Status is the result of the files.info lookup (FileStatusOK,
+	// FileStatusDeleted or FileStatusFailed); empty when it was not requested
+	Status string `json:"status,omitempty"`
+
+	// DownloadStatus is the outcome of saving the file next to a local
+	// export (FileDownloaded, FileDownloadFailed or FileDownloadSkipped), and
+	// LocalPath the saved copy relative to the export; both are empty when
+	// the files were not downloaded
+	DownloadStatus string `json:"download_status,omitempty"`
+	LocalPath      string `json:"local_path,omitempty"`
 }
 
+// File lookup statuses for ExportFile.Status
+const (
+	FileStatusOK      = "ok"
+	FileStatusDeleted = "deleted"
+	FileStatusFailed  = "failed"
+)
+
+// File download outcomes for ExportFile.DownloadStatus
+const (
+	FileDownloaded      = "downloaded"
+	FileDownloadFailed  = "failed"
+	FileDownloadSkipped = "skipped"
+)
+
 // ExportReaction represents a reaction in the export
 type ExportReaction struct {
 	Name  string   `json:"name"`
@@ -180,6 +580,11 @@ type ExportUser struct {
 	TZ       string        `json:"tz,omitempty"`
 	TZLabel  string        `json:"tz_label,omitempty"`
 	TZOffset int           `json:"tz_offset,omitempty"`
+
+	// Members of other organizations, e.g. in Slack Connect channels
+	TeamID     string `json:"team_id,omitempty"`
+	IsStranger bool   `json:"is_stranger,omitempty"`
+	IsExternal bool   `json:"is_external,omitempty"`
 }
 
 // ExportProfile represents a user profile in the export
@@ -204,18 +609,60 @@ type ExportProfile struct {
 
 // ExportStatistics contains statistics about the export
 type ExportStatistics struct {
-	TotalMessages    int                 `json:"total_messages"`
-	TotalThreads     int                 `json:"total_threads"`
-	TotalReplies     int                 `json:"total_replies"`
-	TotalUsers       int                 `json:"total_users"`
-	TotalAttachments int                 `json:"total_attachments"`
-	TotalFiles       int                 `json:"total_files"`
-	TotalReactions   int                 `json:"total_reactions"`
-	MessagesByUser   map[string]int      `json:"messages_by_user"`
-	MessagesByDate   map[string]int      `json:"messages_by_date"`
-	TopReactions     []ReactionStat      `json:"top_reactions"`
-	ExportDuration   time.Duration       `json:"export_duration"`
-	ProcessingTime   ProcessingTimeStats `json:"processing_time"`
+	TotalMessages     int            `json:"total_messages"`
+	TotalThreads      int            `json:"total_threads"`
+	TotalReplies      int            `json:"total_replies"`
+	TotalUsers        int            `json:"total_users"`
+	TotalAttachments  int            `json:"total_attachments"`
+	TotalFiles        int            `json:"total_files"`
+	TotalReactions    int            `json:"total_reactions"`
+	BotMessages       int            `json:"bot_messages"`
+	BroadcastReplies  int            `json:"broadcast_replies,omitempty"` // Replies also sent to the channel, counted once
+	ExternalMessages  int            `json:"external_messages,omitempty"` // By members of other organizations
+	HumanMessages     int            `json:"human_messages"`
+	DuplicatesRemoved int            `json:"duplicates_removed"`
+	MessagesByUser    map[string]int `json:"messages_by_user"`
+	MessagesByDate    map[string]int `json:"messages_by_date"`
+	TopReactions      []ReactionStat `json:"top_reactions"`
+	// TotalFileBytes is the size of all shared files; FileTypes and
+	// TopFileSharers break it down by file type and by the user who shared
+	TotalFileBytes int64            `json:"total_file_bytes,omitempty"`
+	FileTypes      []FileTypeStat   `json:"file_types,omitempty"`
+	TopFileSharers []FileSharerStat `json:"top_file_sharers,omitempty"`
+	// Mentions counts mentions and channel references in message text; nil
+	// when there are none
+	Mentions *MentionStats `json:"mentions,omitempty"`
+	// ThreadActivity measures how messages were answered; nil when the
+	// export has no top-level messages
+	ThreadActivity *ThreadStats        `json:"thread_activity,omitempty"`
+	ExportDuration time.Duration       `json:"export_duration"`
+	ProcessingTime ProcessingTimeStats `json:"processing_time"`
+}
+
+// ThreadStats measures the responsiveness of a channel from its threads.
+// A thread is answered once someone other than its author replies.
+type ThreadStats struct {
+	Threads  int `json:"threads"`
+	Answered int `json:"answered"`
+	// Unanswered counts top-level messages by people, not bots or system
+	// events, that nobody else replied to in a thread
+	Unanswered int `json:"unanswered"`
+	// FirstReply is the time from a message to the first reply by someone
+	// else, over answered threads
+	FirstReply DurationStats `json:"first_reply"`
+	// Duration is the time from a message to the last reply in its thread
+	Duration DurationStats `json:"duration"`
+	// Participants maps a number of distinct participants, the author
+	// included, to the number of threads with that many
+	Participants map[int]int `json:"participants"`
+}
+
+// DurationStats summarizes a set of durations
+type DurationStats struct {
+	Average time.Duration `json:"average"`
+	Median  time.Duration `json:"median"`
+	P90     time.Duration `json:"p90"`
+	Max     time.Duration `json:"max"`
 }
 
 // ReactionStat represents statistics for a reaction
@@ -224,6 +671,53 @@ type ReactionStat struct {
 	Count int    `json:"count"`
 }
 
+// MentionStats counts @user mentions, #channel references and @here,
+// @channel and @everyone broadcasts in message text and thread replies
+type MentionStats struct {
+	UserMentions      int `json:"user_mentions"`
+	ChannelReferences int `json:"channel_references"`
+	Broadcasts        int `json:"broadcasts"`
+	// ByUser counts what each user mentioned and how often they were
+	// mentioned, by user ID
+	ByUser map[string]UserMentionStats `json:"by_user"`
+	// Channels counts references to each channel, by channel ID
+	Channels map[string]int `json:"channels,omitempty"`
+	// Graph lists who mentioned whom, most frequent first. Self-mentions
+	// are left out.
+	Graph []MentionEdge `json:"graph,omitempty"`
+}
+
+// UserMentionStats counts the mentions of one user
+type UserMentionStats struct {
+	// Mentions, ChannelReferences and Broadcasts are in the user's messages
+	Mentions          int `json:"mentions,omitempty"`
+	ChannelReferences int `json:"channel_references,omitempty"`
+	Broadcasts        int `json:"broadcasts,omitempty"`
+	// MentionedBy counts how often others mentioned the user
+	MentionedBy int `json:"mentioned_by,omitempty"`
+}
+
+// MentionEdge counts the mentions of one user by another
+type MentionEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// FileTypeStat counts the files of one type, largest types first
+type FileTypeStat struct {
+	Filetype string `json:"filetype"`
+	Count    int    `json:"count"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// FileSharerStat counts the files one user shared
+type FileSharerStat struct {
+	User  string `json:"user"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
 // ProcessingTimeStats contains timing information for the export process
 type ProcessingTimeStats struct {
 	ChannelFetch   time.Duration `json:"channel_fetch"`
@@ -234,6 +728,10 @@ type ProcessingTimeStats struct {
 	FileGeneration time.Duration `json:"file_generation"`
 }
 
+// NoThreadDelay is the ThreadDelay of --thread-delay 0: no pause at all,
+// where a zero ThreadDelay uses the default pause
+const NoThreadDelay time.Duration = -1
+
 // ExportOptions contains configuration for the export process
 type ExportOptions struct {
 	ChannelID        string     `json:"channel_id"`
@@ -244,8 +742,118 @@ type ExportOptions struct {
 	DateFrom         *time.Time `json:"date_from,omitempty"`
 	DateTo           *time.Time `json:"date_to,omitempty"`
 	OutputFile       string     `json:"output_file"`
-	Format           string     `json:"format"`                // "json", "json-pretty", "json-compact"
+	Format           string     `json:"format"`                // "json", "json-pretty", "json-compact", "pdf", "llm-jsonl"
 	Compression      string     `json:"compression,omitempty"` // "gzip", "zip", "none"
+	// MaxMessages keeps only the newest messages when set; 0 means no limit
+	MaxMessages int `json:"max_messages,omitempty"`
+	// MaxDuration stops fetching once the export has run this long; 0 means no limit
+	MaxDuration time.Duration `json:"max_duration,omitempty"`
+
+	// Remote destination options (s3://, gs://, azblob://)
+	ServerSideEncryption string `json:"server_side_encryption,omitempty"`
+	EncryptionKeyID      string `json:"encryption_key_id,omitempty"`
+
+	// OutputHeaders are sent with http(s):// uploads, e.g. Authorization.
+	// They are left out of serialized options since they hold credentials.
+	OutputHeaders map[string]string `json:"-"`
+
+	// BestEffort records fetch failures as warnings instead of aborting
+	BestEffort bool `json:"best_effort,omitempty"`
+
+	// API tuning; zero values use the defaults and NoThreadDelay turns the
+	// pause off
+	PageSize    int           `json:"page_size,omitempty"`
+	ThreadDelay time.Duration `json:"thread_delay,omitempty"`
+
+	// Location is the zone times are written and days are counted in; nil
+	// selects the machine's zone
+	Location *time.Location `json:"-"`
+
+	// Users restricts the export to messages and replies by these user IDs
+	Users []string `json:"users,omitempty"`
+	// Match restricts the export to messages whose text matches this regular expression
+	Match string `json:"match,omitempty"`
+	// ExcludeSubtypes drops messages with these subtypes (e.g. channel_join)
+	ExcludeSubtypes []string `json:"exclude_subtypes,omitempty"`
+	// SubtypePolicy maps message subtypes to include, exclude or transform.
+	// Transformed messages become compact system events.
+	SubtypePolicy map[string]string `json:"subtype_policy,omitempty"`
+	// WorkspaceURL, when set, is used to fill in message permalinks
+	WorkspaceURL string `json:"workspace_url,omitempty"`
+	// IncludeFileInfo fills file metadata from files.info
+	IncludeFileInfo bool `json:"include_file_info,omitempty"`
+	// IncludeUserGroups adds the user groups mentioned in messages
+	IncludeUserGroups bool `json:"include_usergroups,omitempty"`
+	// IncludeCanvas adds the channel's canvases and posts with their content
+	IncludeCanvas bool `json:"include_canvas,omitempty"`
+	// IncludeEmoji resolves custom emoji and downloads their images
+	IncludeEmoji bool `json:"include_emoji,omitempty"`
+	// IncludeAvatars downloads the users' profile images next to a local
+	// export and points their profiles at the local copies
+	IncludeAvatars bool `json:"include_avatars,omitempty"`
+	// IncludeLinks adds the URLs shared in messages to ChannelExport.Links
+	IncludeLinks bool `json:"include_links,omitempty"`
+	// SnapshotLinks fetches the title and description of every URL shared
+	// in messages and stores them with the message
+	SnapshotLinks bool `json:"snapshot_links,omitempty"`
+	// SnapshotService, when set with SnapshotLinks, also asks a web archive
+	// to copy the URLs of SnapshotArchiveDomains: a request URL in which {url} is replaced by the
+	// shared URL, such as https://web.archive.org/save/{url}
+	SnapshotService string `json:"snapshot_service,omitempty"`
+	// SnapshotArchiveDomains lists the domains whose URLs are sent to
+	// SnapshotService, subdomains included; other URLs are not archived
+	SnapshotArchiveDomains []string `json:"snapshot_archive_domains,omitempty"`
+	// ExcludeExternal drops messages and replies by members of other
+	// organizations and leaves them out of the user directory
+	ExcludeExternal bool `json:"exclude_external,omitempty"`
+	// IncludeTimeline compiles join, leave, topic, purpose and name change
+	// messages into ChannelExport.Timeline, even when they are excluded
+	IncludeTimeline bool `json:"include_timeline,omitempty"`
+	// SplitBy writes one file per "month", "day" or "size=<n>MB" plus an index
+	SplitBy string `json:"split_by,omitempty"`
+	// Layout "slack-native" writes the directory layout of Slack's own
+	// exports below OutputFile instead of a single file
+	Layout string `json:"layout,omitempty"`
+	// SummarizeBy sends each day's or thread's messages to the summary
+	// client and stores the results in ChannelExport.Summaries
+	SummarizeBy string `json:"summarize_by,omitempty"`
+	// ChunkTokens bounds the estimated token count of llm-jsonl chunks
+	// (0 = 1000) and TextOnly leaves files, attachments and reactions out
+	// of them
+	ChunkTokens int  `json:"chunk_tokens,omitempty"`
+	TextOnly    bool `json:"text_only,omitempty"`
+	// Manifest writes <name>.manifest.json with checksums of the produced
+	// files (local destinations only)
+	Manifest bool `json:"manifest,omitempty"`
+	// ManifestKey signs the manifest with an HMAC when set
+	ManifestKey []byte `json:"-"`
+
+	// Bots drops bot messages ("exclude") or keeps only them ("only")
+	Bots string `json:"bots,omitempty"`
+	// MinReactions keeps only messages with at least this many reactions
+	MinReactions int `json:"min_reactions,omitempty"`
+	// ThreadsOnly keeps only the messages that started a thread, with their
+	// replies
+	ThreadsOnly bool `json:"threads_only,omitempty"`
+	// ParticipatedThreads keeps, besides the messages of Users, every
+	// thread one of them started or replied to, with all of its replies
+	ParticipatedThreads bool `json:"participated_threads,omitempty"`
+	// FlatReplies writes thread replies as top-level messages with their
+	// thread_ts, in the shape of Slack's own exports
+	FlatReplies bool `json:"flat_replies,omitempty"`
+
+	// TrackChanges compares the messages fetched with Baseline, the versions
+	// recorded by the previous export, and reports edits and deletions
+	// within the range of history fetched
+	TrackChanges  bool                      `json:"track_changes,omitempty"`
+	Baseline      map[string]MessageVersion `json:"-"`
+	BaselineTaken time.Time                 `json:"-"`
+
+	// Stop ends the export early when closed: fetching stops and the
+	// messages fetched so far are written as a partial export with a
+	// checkpoint. Resume continues from such a checkpoint.
+	Stop   <-chan struct{}   `json:"-"`
+	Resume *ExportCheckpoint `json:"-"`
 }
 
 // ExportProgress represents the current state of an export operation
@@ -262,6 +870,15 @@ type ExportProgress struct {
 	Error           string        `json:"error,omitempty"`
 }
 
+// Remaining returns the estimated time left, or false while no estimate is
+// available
+func (p ExportProgress) Remaining() (time.Duration, bool) {
+	if p.EstimatedTotal <= p.ElapsedTime {
+		return 0, false
+	}
+	return p.EstimatedTotal - p.ElapsedTime, true
+}
+
 // ExportResult contains the result of an export operation
 type ExportResult struct {
 	Success    bool             `json:"success"`
@@ -271,9 +888,67 @@ type ExportResult struct {
 	Duration   time.Duration    `json:"duration"`
 	Error      string           `json:"error,omitempty"`
 	Warnings   []string         `json:"warnings,omitempty"`
+	Partial    bool             `json:"partial,omitempty"`
+	Changes    *ExportChanges   `json:"changes,omitempty"`
+	Parts      []string         `json:"parts,omitempty"`
+	Manifest   string           `json:"manifest,omitempty"`
+
+	// Truncated is set when a message or time limit ended the export early
+	Truncated *ExportTruncation `json:"truncated,omitempty"`
+	// Checkpoint is the checkpoint file of an interrupted export
+	Checkpoint string `json:"checkpoint,omitempty"`
+
+	// Snapshot holds the message versions when TrackChanges is set: those
+	// fetched, merged over the baseline outside the range fetched. It is
+	// the baseline of the next export.
+	Snapshot map[string]MessageVersion `json:"-"`
+
+	// ErrorCategory classifies Error for automation
+	ErrorCategory ErrorCategory `json:"error_category,omitempty"`
 }
 
-// ParseSlackTimestamp parses a Slack timestamp string to time.Time
+// Permalink builds the link to a message in the Slack web client from the
+// workspace URL (e.g. https://acme.slack.com/), as chat.getPermalink does.
+// Thread replies link to the reply within its thread.
+func Permalink(workspaceURL, channelID, ts, threadTS string) string {
+	link := strings.TrimSuffix(workspaceURL, "/") + "/archives/" + channelID + "/p" + strings.Replace(ts, ".", "", 1)
+	if threadTS != "" && threadTS != ts {
+		link += "?thread_ts=" + threadTS + "&cid=" + channelID
+	}
+	return link
+}
+
+// ParsePermalink extracts the channel and message timestamp from a message
+// link such as https://acme.slack.com/archives/C123/p1705312800123456. For a
+// thread reply, threadTS is the parent's timestamp; otherwise it equals ts.
+func ParsePermalink(link string) (channelID, ts, threadTS string, err error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid permalink '%s': %w", link, err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "archives" || parts[1] == "" || !strings.HasPrefix(parts[2], "p") {
+		return "", "", "", fmt.Errorf("invalid permalink '%s': expected .../archives/<channel>/p<timestamp>", link)
+	}
+	digits := strings.TrimPrefix(parts[2], "p")
+	if len(digits) <= 6 {
+		return "", "", "", fmt.Errorf("invalid permalink '%s': malformed message timestamp", link)
+	}
+	if _, err := strconv.ParseUint(digits, 10, 64); err != nil {
+		return "", "", "", fmt.Errorf("invalid permalink '%s': malformed message timestamp", link)
+	}
+
+	channelID = parts[1]
+	ts = digits[:len(digits)-6] + "." + digits[len(digits)-6:]
+	threadTS = ts
+	if parent := u.Query().Get("thread_ts"); parent != "" {
+		threadTS = parent
+	}
+	return channelID, ts, threadTS, nil
+}
+
+// ParseSlackTimestamp parses a Slack timestamp string to time.Time in the
+// machine's zone; renderers convert it to the zone they display
 func ParseSlackTimestamp(ts string) (time.Time, error) {
 	if ts == "" {
 		return time.Time{}, nil
@@ -288,8 +963,10 @@ func ParseSlackTimestamp(ts string) (time.Time, error) {
 	return time.Unix(int64(timestamp), int64((timestamp-float64(int64(timestamp)))*1e9)), nil
 }
 
-// ConvertToExportMessage converts a Slack message to export format
-func ConvertToExportMessage(msg Message) ExportMessage {
+// ConvertToExportMessage converts a Slack message to export format, with its
+// times in loc (the machine's zone when nil)
+func ConvertToExportMessage(msg Message, loc *time.Location) ExportMessage {
+	loc = OrLocal(loc)
 	exportMsg := ExportMessage{
 		ID:              msg.Timestamp, // Use timestamp as ID for now
 		User:            msg.User,
@@ -297,12 +974,28 @@ func ConvertToExportMessage(msg Message) ExportMessage {
 		Type:            msg.Type,
 		Subtype:         msg.Subtype,
 		ThreadTimestamp: msg.ThreadTS,
+		ParentUserID:    msg.ParentUserID,
 		ReplyCount:      msg.ReplyCount,
+		ReplyUsers:      msg.ReplyUsers,
+		ReplyUsersCount: max(msg.ReplyUsersCount, len(msg.ReplyUsers)), // Stored messages may predate the count
+		InThread:        msg.InThread,
+		Omitted:         msg.Omitted,
+		BotID:           msg.BotID,
+		Username:        msg.Username,
+	}
+	if msg.Subtype == SubtypeThreadBroadcast && msg.ThreadTS != "" && msg.ThreadTS != msg.Timestamp {
+		exportMsg.BroadcastOf = msg.ThreadTS
 	}
 
 	// Parse timestamp
 	if timestamp, err := ParseSlackTimestamp(msg.Timestamp); err == nil {
-		exportMsg.Timestamp = timestamp
+		exportMsg.Timestamp = timestamp.In(loc)
+	}
+	if msg.LatestReply != "" {
+		if latest, err := ParseSlackTimestamp(msg.LatestReply); err == nil {
+			latest = latest.In(loc)
+			exportMsg.LatestReply = &latest
+		}
 	}
 
 	// Convert edit info
@@ -310,7 +1003,7 @@ func ConvertToExportMessage(msg Message) ExportMessage {
 		if editTime, err := ParseSlackTimestamp(msg.Edited.Timestamp); err == nil {
 			exportMsg.Edited = &EditInfo{
 				User:      msg.Edited.User,
-				Timestamp: editTime,
+				Timestamp: editTime.In(loc),
 			}
 		}
 	}
@@ -318,13 +1011,31 @@ func ConvertToExportMessage(msg Message) ExportMessage {
 	// Convert attachments
 	for _, att := range msg.Attachments {
 		exportAtt := ExportAttachment{
-			ID:       strconv.Itoa(att.ID),
-			Title:    att.Title,
-			Text:     att.Text,
-			Fallback: att.Fallback,
-			Color:    att.Color,
-			ImageURL: att.ImageURL,
-			ThumbURL: att.ThumbURL,
+			ID:          strconv.Itoa(att.ID),
+			Title:       att.Title,
+			TitleLink:   att.TitleLink,
+			Text:        att.Text,
+			Fallback:    att.Fallback,
+			Color:       att.Color,
+			Pretext:     att.Pretext,
+			AuthorName:  att.AuthorName,
+			AuthorLink:  att.AuthorLink,
+			AuthorIcon:  att.AuthorIcon,
+			ImageURL:    att.ImageURL,
+			ThumbURL:    att.ThumbURL,
+			Footer:      att.Footer,
+			FooterIcon:  att.FooterIcon,
+			Fields:      att.Fields,
+			ServiceName: att.ServiceName,
+			ServiceIcon: att.ServiceIcon,
+			FromURL:     att.FromURL,
+			OriginalURL: att.OriginalURL,
+		}
+		if att.Timestamp != "" {
+			if ts, err := ParseSlackTimestamp(att.Timestamp); err == nil {
+				ts = ts.In(loc)
+				exportAtt.Timestamp = &ts
+			}
 		}
 
 		exportMsg.Attachments = append(exportMsg.Attachments, exportAtt)
@@ -357,14 +1068,45 @@ func ConvertToExportMessage(msg Message) ExportMessage {
 		})
 	}
 
+	if msg.Call != nil {
+		exportMsg.Call = convertCall(*msg.Call, loc)
+	}
+	exportMsg.Workflow = msg.Workflow
+	exportMsg.Canvas = msg.Canvas
+
 	// Convert thread replies recursively
 	for _, reply := range msg.Thread {
-		exportMsg.Replies = append(exportMsg.Replies, ConvertToExportMessage(reply))
+		converted := ConvertToExportMessage(reply, loc)
+		converted.BroadcastOf = "" // Only channel copies need the link
+		exportMsg.Replies = append(exportMsg.Replies, converted)
 	}
 
 	return exportMsg
 }
 
+// convertCall converts call times to loc and computes the duration of calls
+// that have ended
+func convertCall(call Call, loc *time.Location) *ExportCall {
+	exportCall := &ExportCall{
+		ID:           call.ID,
+		Name:         call.Name,
+		CreatedBy:    call.CreatedBy,
+		Participants: call.Participants,
+	}
+	if call.DateStart > 0 {
+		start := time.Unix(call.DateStart, 0).In(loc)
+		exportCall.StartedAt = &start
+	}
+	if call.DateEnd > 0 {
+		end := time.Unix(call.DateEnd, 0).In(loc)
+		exportCall.EndedAt = &end
+	}
+	if call.DateStart > 0 && call.DateEnd >= call.DateStart {
+		exportCall.Duration = call.DateEnd - call.DateStart
+	}
+	return exportCall
+}
+
 // ConvertToExportUser converts a Slack user to export format
 func ConvertToExportUser(user User) ExportUser {
 	return ExportUser{
@@ -373,6 +1115,10 @@ func ConvertToExportUser(user User) ExportUser {
 		RealName: user.RealName,
 		IsBot:    user.IsBot,
 		Deleted:  user.Deleted,
+
+		TeamID:     user.TeamID,
+		IsStranger: user.IsStranger,
+		IsExternal: user.IsExternal,
 		Profile: ExportProfile{
 			DisplayName: user.Profile.DisplayName,
 			RealName:    user.Profile.RealName,
@@ -70,22 +70,29 @@ func TestParseSlackTimestamp(t *testing.T) {
 func TestConvertToExportMessage(t *testing.T) {
 	// Create a test message
 	msg := Message{
-		Type:       "message",
-		User:       "U123456",
-		Text:       "Hello, world!",
-		Timestamp:  "1704067200.123456",
-		ThreadTS:   "1704067100.000000",
-		ReplyCount: 2,
-		Subtype:    "",
+		Type:        "message",
+		User:        "U123456",
+		Text:        "Hello, world!",
+		Timestamp:   "1704067200.123456",
+		ThreadTS:    "1704067100.000000",
+		ReplyCount:  2,
+		ReplyUsers:  []string{"U789012", "U345678"},
+		LatestReply: "1704067250.000000",
+		Subtype:     "",
 		Attachments: []Attachment{
 			{
-				ID:       1,
-				Title:    "Test Attachment",
-				Text:     "Attachment text",
-				Fallback: "Fallback text",
-				Color:    "good",
-				ImageURL: "https://example.com/image.png",
-				ThumbURL: "https://example.com/thumb.png",
+				ID:         1,
+				Title:      "Test Attachment",
+				Text:       "Attachment text",
+				Fallback:   "Fallback text",
+				Color:      "good",
+				ImageURL:   "https://example.com/image.png",
+				ThumbURL:   "https://example.com/thumb.png",
+				TitleLink:  "https://example.com/issue/1",
+				AuthorName: "ci-bot",
+				Footer:     "GitHub",
+				Timestamp:  "1704067150",
+				Fields:     []AttachmentField{{Title: "Status", Value: "passed", Short: true}},
 			},
 		},
 		Files: []File{
@@ -112,16 +119,17 @@ func TestConvertToExportMessage(t *testing.T) {
 		},
 		Thread: []Message{
 			{
-				Type:      "message",
-				User:      "U789012",
-				Text:      "Reply message",
-				Timestamp: "1704067250.000000",
+				Type:         "message",
+				User:         "U789012",
+				Text:         "Reply message",
+				Timestamp:    "1704067250.000000",
+				ParentUserID: "U123456",
 			},
 		},
 	}
 
 	// Convert to export format
-	exportMsg := ConvertToExportMessage(msg)
+	exportMsg := ConvertToExportMessage(msg, nil)
 
 	// Verify basic fields
 	if exportMsg.ID != msg.Timestamp {
@@ -148,6 +156,14 @@ func TestConvertToExportMessage(t *testing.T) {
 		t.Errorf("Expected ReplyCount %d, got %d", msg.ReplyCount, exportMsg.ReplyCount)
 	}
 
+	// Verify thread participation
+	if len(exportMsg.ReplyUsers) != 2 || exportMsg.ReplyUsersCount != 2 {
+		t.Errorf("Expected 2 reply users, got %v (count %d)", exportMsg.ReplyUsers, exportMsg.ReplyUsersCount)
+	}
+	if exportMsg.LatestReply == nil || !exportMsg.LatestReply.Equal(time.Unix(1704067250, 0)) {
+		t.Errorf("Expected LatestReply %v, got %v", time.Unix(1704067250, 0), exportMsg.LatestReply)
+	}
+
 	// Verify timestamp parsing
 	expectedTime := time.Unix(1704067200, 123456000)
 	diff := exportMsg.Timestamp.Sub(expectedTime)
@@ -182,6 +198,15 @@ func TestConvertToExportMessage(t *testing.T) {
 		if att.Title != msg.Attachments[0].Title {
 			t.Errorf("Expected attachment Title %s, got %s", msg.Attachments[0].Title, att.Title)
 		}
+		if att.TitleLink != "https://example.com/issue/1" || att.AuthorName != "ci-bot" || att.Footer != "GitHub" {
+			t.Errorf("Expected title link, author and footer, got %+v", att)
+		}
+		if len(att.Fields) != 1 || att.Fields[0].Value != "passed" {
+			t.Errorf("Expected attachment fields, got %+v", att.Fields)
+		}
+		if att.Timestamp == nil || !att.Timestamp.Equal(time.Unix(1704067150, 0)) {
+			t.Errorf("Expected attachment timestamp, got %v", att.Timestamp)
+		}
 	}
 
 	// Verify files
@@ -221,6 +246,9 @@ func TestConvertToExportMessage(t *testing.T) {
 		if reply.Text != msg.Thread[0].Text {
 			t.Errorf("Expected reply Text %s, got %s", msg.Thread[0].Text, reply.Text)
 		}
+		if reply.ParentUserID != "U123456" {
+			t.Errorf("Expected reply ParentUserID U123456, got %s", reply.ParentUserID)
+		}
 	}
 }
 
@@ -394,3 +422,95 @@ func TestExportOptions(t *testing.T) {
 		t.Errorf("Expected Format %s, got %s", options.Format, unmarshaled.Format)
 	}
 }
+
+func TestPermalink(t *testing.T) {
+	tests := []struct {
+		name     string
+		ts       string
+		threadTS string
+		want     string
+	}{
+		{"message", "1704067200.123456", "", "https://acme.slack.com/archives/C123/p1704067200123456"},
+		{"thread parent", "1704067200.123456", "1704067200.123456", "https://acme.slack.com/archives/C123/p1704067200123456"},
+		{"reply", "1704067300.000100", "1704067200.123456", "https://acme.slack.com/archives/C123/p1704067300000100?thread_ts=1704067200.123456&cid=C123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Permalink("https://acme.slack.com/", "C123", tt.ts, tt.threadTS); got != tt.want {
+				t.Errorf("Permalink() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePermalink(t *testing.T) {
+	tests := []struct {
+		name      string
+		link      string
+		channelID string
+		ts        string
+		threadTS  string
+		wantErr   bool
+	}{
+		{"message", "https://acme.slack.com/archives/C123/p1704067200123456", "C123", "1704067200.123456", "1704067200.123456", false},
+		{"reply", "https://acme.slack.com/archives/C123/p1704067300000100?thread_ts=1704067200.123456&cid=C123", "C123", "1704067300.000100", "1704067200.123456", false},
+		{"not a message link", "https://acme.slack.com/team/U123", "", "", "", true},
+		{"malformed timestamp", "https://acme.slack.com/archives/C123/pabc", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channelID, ts, threadTS, err := ParsePermalink(tt.link)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePermalink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if channelID != tt.channelID || ts != tt.ts || threadTS != tt.threadTS {
+				t.Errorf("ParsePermalink() = %s, %s, %s, want %s, %s, %s", channelID, ts, threadTS, tt.channelID, tt.ts, tt.threadTS)
+			}
+		})
+	}
+}
+
+func TestConvertToExportMessageCall(t *testing.T) {
+	exportMsg := ConvertToExportMessage(Message{
+		Timestamp: "1704067200.000100",
+		Subtype:   SubtypeHuddleThread,
+		Call:      &Call{ID: "R1", DateStart: 1704067200, DateEnd: 1704067950, Participants: []string{"U1", "U2"}},
+	}, nil)
+
+	call := exportMsg.Call
+	if call == nil {
+		t.Fatal("Expected call details")
+	}
+	if call.Duration != 750 {
+		t.Errorf("Expected duration 750s, got %d", call.Duration)
+	}
+	if call.StartedAt == nil || !call.StartedAt.Equal(time.Unix(1704067200, 0)) {
+		t.Errorf("Expected start time, got %v", call.StartedAt)
+	}
+	if len(call.Participants) != 2 {
+		t.Errorf("Expected 2 participants, got %v", call.Participants)
+	}
+
+	ongoing := ConvertToExportMessage(Message{Call: &Call{ID: "R2", DateStart: 1704067200}}, nil)
+	if ongoing.Call.EndedAt != nil || ongoing.Call.Duration != 0 {
+		t.Errorf("Expected no end or duration for an ongoing huddle, got %+v", ongoing.Call)
+	}
+}
+
+func TestConvertToExportMessageBroadcast(t *testing.T) {
+	reply := Message{Text: "decided", Timestamp: "1704067260.000000", ThreadTS: "1704067200.000000", Subtype: SubtypeThreadBroadcast}
+
+	// Orphaned channel copy: its thread is not part of the export
+	orphan := ConvertToExportMessage(reply, nil)
+	if orphan.BroadcastOf != "1704067200.000000" || orphan.Text != "decided" {
+		t.Errorf("Expected the copy to keep its text and link to its thread, got %+v", orphan)
+	}
+
+	// The reply inside its thread needs no link
+	parent := ConvertToExportMessage(Message{Timestamp: "1704067200.000000", ThreadTS: "1704067200.000000", Thread: []Message{reply}}, nil)
+	if parent.BroadcastOf != "" || len(parent.Replies) != 1 || parent.Replies[0].BroadcastOf != "" {
+		t.Errorf("Expected no broadcast_of on the parent and the nested reply, got %+v", parent)
+	}
+}
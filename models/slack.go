@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Channel represents a Slack channel
 type Channel struct {
@@ -17,6 +21,40 @@ type Channel struct {
 	Purpose    Topic  `json:"purpose"`
 	Created    int64  `json:"created"`
 	Creator    string `json:"creator"`
+
+	// Slack Connect: the channel is shared with other organizations, whose
+	// workspace IDs are listed
+	IsExtShared      bool     `json:"is_ext_shared,omitempty"`
+	ConnectedTeamIDs []string `json:"connected_team_ids,omitempty"`
+
+	// IsDeleted marks a channel of the local store that was deleted in Slack
+	// and IsInaccessible one the token can no longer read, which Slack does
+	// not tell apart from a deleted private channel
+	IsDeleted      bool `json:"is_deleted,omitempty"`
+	IsInaccessible bool `json:"is_inaccessible,omitempty"`
+}
+
+// Channel states recorded in exports
+const (
+	ChannelStateActive       = "active"
+	ChannelStateArchived     = "archived"
+	ChannelStateDeleted      = "deleted"
+	ChannelStateInaccessible = "inaccessible"
+)
+
+// State returns whether the channel is active, archived, deleted or no
+// longer accessible
+func (c Channel) State() string {
+	switch {
+	case c.IsDeleted:
+		return ChannelStateDeleted
+	case c.IsInaccessible:
+		return ChannelStateInaccessible
+	case c.IsArchived:
+		return ChannelStateArchived
+	default:
+		return ChannelStateActive
+	}
 }
 
 // Topic represents channel topic or purpose
@@ -28,21 +66,163 @@ type Topic struct {
 
 // Message represents a Slack message
 type Message struct {
-	Type        string       `json:"type"`
-	User        string       `json:"user"`
-	Text        string       `json:"text"`
-	Timestamp   string       `json:"ts"`
-	ThreadTS    string       `json:"thread_ts,omitempty"`
-	ReplyCount  int          `json:"reply_count,omitempty"`
-	Replies     []Reply      `json:"replies,omitempty"`
-	Thread      []Message    `json:"thread,omitempty"` // For our export format
-	Attachments []Attachment `json:"attachments,omitempty"`
-	Files       []File       `json:"files,omitempty"`
-	Reactions   []Reaction   `json:"reactions,omitempty"`
-	Edited      *Edited      `json:"edited,omitempty"`
-	BotID       string       `json:"bot_id,omitempty"`
-	Username    string       `json:"username,omitempty"`
-	Subtype     string       `json:"subtype,omitempty"`
+	Type         string       `json:"type"`
+	User         string       `json:"user"`
+	Text         string       `json:"text"`
+	Timestamp    string       `json:"ts"`
+	ThreadTS     string       `json:"thread_ts,omitempty"`
+	ReplyCount   int          `json:"reply_count,omitempty"`
+	ReplyUsers   []string     `json:"reply_users,omitempty"`    // Set on thread parents
+	LatestReply  string       `json:"latest_reply,omitempty"`   // Set on thread parents
+	ParentUserID string       `json:"parent_user_id,omitempty"` // Set on thread replies
+	Replies      []Reply      `json:"replies,omitempty"`
+	Thread       []Message    `json:"thread,omitempty"`    // For our export format
+	InThread     bool         `json:"in_thread,omitempty"` // Channel copy of a reply exported in its thread
+	Omitted      bool         `json:"omitted,omitempty"`   // Thread parent dropped by a filter, kept for its replies
+	Attachments  []Attachment `json:"attachments,omitempty"`
+	Files        []File       `json:"files,omitempty"`
+	Reactions    []Reaction   `json:"reactions,omitempty"`
+	Edited       *Edited      `json:"edited,omitempty"`
+	BotID        string       `json:"bot_id,omitempty"`
+	Username     string       `json:"username,omitempty"`
+	Subtype      string       `json:"subtype,omitempty"`
+	Team         string       `json:"team,omitempty"` // Workspace of the author
+
+	// ReplyUsersCount is the number of users who replied to a thread parent,
+	// which can exceed the users Slack lists in ReplyUsers
+	ReplyUsersCount int `json:"reply_users_count,omitempty"`
+
+	// Channel event messages: the new topic, purpose or name, the previous
+	// name and who invited a joining member
+	Topic   string `json:"topic,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+	Name    string `json:"name,omitempty"`
+	OldName string `json:"old_name,omitempty"`
+	Inviter string `json:"inviter,omitempty"`
+
+	// Structured content of huddle, call, workflow and canvas messages
+	Call     *Call     `json:"call,omitempty"`
+	Workflow *Workflow `json:"workflow,omitempty"`
+	Canvas   *Canvas   `json:"canvas,omitempty"`
+}
+
+// IsBot reports whether the message was posted by a bot or app integration
+func (m Message) IsBot() bool {
+	return m.BotID != "" || m.Subtype == "bot_message"
+}
+
+// ReactionCount returns the total number of reactions on the message
+func (m Message) ReactionCount() int {
+	total := 0
+	for _, reaction := range m.Reactions {
+		total += reaction.Count
+	}
+	return total
+}
+
+// Message subtypes with structured content
+const (
+	SubtypeHuddleThread    = "huddle_thread"
+	SubtypeRoomCreated     = "sh_room_created"
+	SubtypeChannelCanvas   = "channel_canvas"
+	SubtypeThreadBroadcast = "thread_broadcast"
+)
+
+// IsHuddle reports whether a message subtype announces a huddle
+func IsHuddle(subtype string) bool {
+	return subtype == SubtypeHuddleThread || subtype == SubtypeRoomCreated
+}
+
+// Call describes the huddle or call of a message. Start and end are Unix
+// seconds; Participants lists everyone who joined.
+type Call struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name,omitempty"`
+	CreatedBy    string   `json:"created_by,omitempty"`
+	DateStart    int64    `json:"date_start,omitempty"`
+	DateEnd      int64    `json:"date_end,omitempty"`
+	Participants []string `json:"participants,omitempty"`
+}
+
+// Workflow identifies the workflow or app that posted a bot message
+type Workflow struct {
+	Name      string `json:"name,omitempty"`
+	AppID     string `json:"app_id,omitempty"`
+	BotID     string `json:"bot_id,omitempty"`
+	EventType string `json:"event_type,omitempty"` // Message metadata event type
+}
+
+// HistoryPage is one page of a channel's history, newest message first
+type HistoryPage struct {
+	Messages []Message
+	// NextCursor continues the history; empty on the last page
+	NextCursor string
+	// HasMore reports whether older messages remain in the requested range
+	HasMore bool
+	// Oldest and Latest are the Slack timestamps of the oldest and newest
+	// message on the page, empty for an empty page
+	Oldest string
+	Latest string
+	// Latency is how long the request took, including rate limit waits
+	Latency time.Duration
+	// Warnings describes details of the messages that could not be fetched,
+	// such as huddle rooms
+	Warnings []string
+}
+
+// NewHistoryPage returns a page of messages with its boundaries filled in
+func NewHistoryPage(messages []Message, nextCursor string, hasMore bool) *HistoryPage {
+	page := &HistoryPage{Messages: messages, NextCursor: nextCursor, HasMore: hasMore}
+	for _, msg := range messages {
+		if msg.Timestamp == "" {
+			continue
+		}
+		if page.Oldest == "" || msg.Timestamp < page.Oldest {
+			page.Oldest = msg.Timestamp
+		}
+		if msg.Timestamp > page.Latest {
+			page.Latest = msg.Timestamp
+		}
+	}
+	return page
+}
+
+// Last reports whether no further page follows
+func (p *HistoryPage) Last() bool {
+	return p.NextCursor == "" || !p.HasMore
+}
+
+// Kinds of MessageEvent
+const (
+	EventMessagePosted   = "message"
+	EventMessageChanged  = "message_changed"
+	EventMessageDeleted  = "message_deleted"
+	EventReactionAdded   = "reaction_added"
+	EventReactionRemoved = "reaction_removed"
+)
+
+// MessageEvent is a change to a channel's messages or their reactions
+// delivered in real time by Socket Mode
+type MessageEvent struct {
+	Kind      string    `json:"kind"`
+	ChannelID string    `json:"channel_id"`
+	At        time.Time `json:"at"`
+	// Message is the new or edited message
+	Message *Message `json:"message,omitempty"`
+	// TS and ThreadTS identify the message the event is about
+	TS       string `json:"ts"`
+	ThreadTS string `json:"thread_ts,omitempty"`
+	// Previous is the version before an edit or deletion, when Slack sends it
+	Previous *Message `json:"previous,omitempty"`
+	// Reaction and User are the emoji and the reacting user of reaction events
+	Reaction string `json:"reaction,omitempty"`
+	User     string `json:"user,omitempty"`
+}
+
+// Canvas references the canvas shared by a channel_canvas message
+type Canvas struct {
+	FileID string `json:"file_id"`
+	Title  string `json:"title,omitempty"`
 }
 
 // Reply represents a thread reply reference
@@ -51,15 +231,29 @@ type Reply struct {
 	Timestamp string `json:"ts"`
 }
 
-// Attachment represents a message attachment
+// Attachment represents a message attachment, such as a link unfurl or an
+// integration message
 type Attachment struct {
-	ID       int    `json:"id"`
-	Color    string `json:"color"`
-	Fallback string `json:"fallback"`
-	Title    string `json:"title"`
-	Text     string `json:"text"`
-	ImageURL string `json:"image_url"`
-	ThumbURL string `json:"thumb_url"`
+	ID          int               `json:"id"`
+	Color       string            `json:"color"`
+	Fallback    string            `json:"fallback"`
+	Pretext     string            `json:"pretext,omitempty"`
+	AuthorName  string            `json:"author_name,omitempty"`
+	AuthorLink  string            `json:"author_link,omitempty"`
+	AuthorIcon  string            `json:"author_icon,omitempty"`
+	Title       string            `json:"title"`
+	TitleLink   string            `json:"title_link,omitempty"`
+	Text        string            `json:"text"`
+	Fields      []AttachmentField `json:"fields,omitempty"`
+	ImageURL    string            `json:"image_url"`
+	ThumbURL    string            `json:"thumb_url"`
+	ServiceName string            `json:"service_name,omitempty"`
+	ServiceIcon string            `json:"service_icon,omitempty"`
+	FromURL     string            `json:"from_url,omitempty"`
+	OriginalURL string            `json:"original_url,omitempty"`
+	Footer      string            `json:"footer,omitempty"`
+	FooterIcon  string            `json:"footer_icon,omitempty"`
+	Timestamp   string            `json:"ts,omitempty"`
 }
 
 // File represents an uploaded file
@@ -94,6 +288,36 @@ type User struct {
 	Profile  Profile `json:"profile"`
 	IsBot    bool    `json:"is_bot"`
 	Deleted  bool    `json:"deleted"`
+
+	// TeamID is the user's workspace. IsStranger marks users of other
+	// organizations Slack only shows partially; IsExternal is set by the
+	// exporter for every user outside the exported workspace.
+	TeamID     string `json:"team_id,omitempty"`
+	IsStranger bool   `json:"is_stranger,omitempty"`
+	IsExternal bool   `json:"is_external,omitempty"`
+}
+
+// UserGroup represents a user group (subteam) that can be mentioned as
+// <!subteam^ID>
+type UserGroup struct {
+	ID          string   `json:"id"`
+	Handle      string   `json:"handle"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Users       []string `json:"users,omitempty"`
+	Deleted     bool     `json:"deleted,omitempty"`
+}
+
+// Bot describes the bot or app integration behind a bot_id, from bots.info
+type Bot struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	AppID   string `json:"app_id,omitempty"`
+	UserID  string `json:"user_id,omitempty"` // Bot user of the app, if any
+	Deleted bool   `json:"deleted,omitempty"`
+	Image36 string `json:"image_36,omitempty"`
+	Image48 string `json:"image_48,omitempty"`
+	Image72 string `json:"image_72,omitempty"`
 }
 
 // Profile represents user profile information
@@ -120,8 +344,43 @@ type ExportData struct {
 
 // SlackConfig represents Slack API configuration
 type SlackConfig struct {
-	Token     string `json:"token"`
-	AppToken  string `json:"app_token,omitempty"`
-	BotToken  string `json:"bot_token,omitempty"`
-	UserToken string `json:"user_token,omitempty"`
+	Token     string `json:"token" mapstructure:"token"`
+	AppToken  string `json:"app_token,omitempty" mapstructure:"app_token"`
+	BotToken  string `json:"bot_token,omitempty" mapstructure:"bot_token"`
+	UserToken string `json:"user_token,omitempty" mapstructure:"user_token"`
+}
+
+// TokenType identifies the kind of Slack token used for API calls
+type TokenType string
+
+// Supported token types
+const (
+	TokenTypeAuto TokenType = "auto"
+	TokenTypeBot  TokenType = "bot"
+	TokenTypeUser TokenType = "user"
+)
+
+// ParseTokenType validates a --token-type value
+func ParseTokenType(value string) (TokenType, error) {
+	switch TokenType(strings.ToLower(value)) {
+	case "", TokenTypeAuto:
+		return TokenTypeAuto, nil
+	case TokenTypeBot:
+		return TokenTypeBot, nil
+	case TokenTypeUser:
+		return TokenTypeUser, nil
+	}
+	return "", fmt.Errorf("invalid token type '%s'. Supported: auto, bot, user", value)
+}
+
+// DetectTokenType infers the token type from its prefix. Unknown prefixes
+// return an empty type.
+func DetectTokenType(token string) TokenType {
+	switch {
+	case strings.HasPrefix(token, "xoxb-"):
+		return TokenTypeBot
+	case strings.HasPrefix(token, "xoxp-"):
+		return TokenTypeUser
+	}
+	return ""
 }
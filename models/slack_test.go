@@ -0,0 +1,60 @@
+package models
+
+import "testing"
+
+func TestParseTokenType(t *testing.T) {
+	tests := map[string]TokenType{
+		"":     TokenTypeAuto,
+		"auto": TokenTypeAuto,
+		"bot":  TokenTypeBot,
+		"USER": TokenTypeUser,
+	}
+	for input, expected := range tests {
+		got, err := ParseTokenType(input)
+		if err != nil {
+			t.Errorf("ParseTokenType(%q) returned error: %v", input, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("ParseTokenType(%q) = %s, expected %s", input, got, expected)
+		}
+	}
+
+	if _, err := ParseTokenType("app"); err == nil {
+		t.Error("Expected error for unsupported token type")
+	}
+}
+
+func TestDetectTokenType(t *testing.T) {
+	tests := map[string]TokenType{
+		"xoxb-123": TokenTypeBot,
+		"xoxp-123": TokenTypeUser,
+		"xapp-123": "",
+		"":         "",
+	}
+	for token, expected := range tests {
+		if got := DetectTokenType(token); got != expected {
+			t.Errorf("DetectTokenType(%q) = %q, expected %q", token, got, expected)
+		}
+	}
+}
+
+func TestNewHistoryPage(t *testing.T) {
+	page := NewHistoryPage([]Message{
+		{Timestamp: "1704067300.000100"},
+		{Timestamp: "1704067200.000100"},
+		{Timestamp: ""},
+		{Timestamp: "1704067400.000100"},
+	}, "next", true)
+	if page.Oldest != "1704067200.000100" || page.Latest != "1704067400.000100" {
+		t.Errorf("Expected boundaries 1704067200.000100-1704067400.000100, got %s-%s", page.Oldest, page.Latest)
+	}
+	if page.Last() {
+		t.Error("Expected a page with a cursor and has_more not to be the last")
+	}
+
+	empty := NewHistoryPage(nil, "next", false)
+	if empty.Oldest != "" || empty.Latest != "" || !empty.Last() {
+		t.Errorf("Expected an empty last page, got %+v", empty)
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LoadTimezone resolves an IANA zone name such as Europe/Berlin or UTC. An
+// empty name or "local" selects the machine's zone.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone '%s'. Use an IANA name such as UTC or Europe/Berlin", name)
+	}
+	return loc, nil
+}
+
+// OrLocal returns loc, or the machine's zone when loc is nil
+func OrLocal(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.Local
+	}
+	return loc
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	if loc, err := LoadTimezone(""); err != nil || loc != time.Local {
+		t.Errorf("Expected local zone for empty name, got %v, %v", loc, err)
+	}
+	if _, err := LoadTimezone("Not/AZone"); err == nil {
+		t.Error("Expected error for unknown zone")
+	}
+}
+
+func TestConvertToExportMessageTimezone(t *testing.T) {
+	loc, err := LoadTimezone("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("zone database unavailable: %v", err)
+	}
+
+	// 2024-01-01 20:00 UTC is already January 2nd in Tokyo
+	msg := ConvertToExportMessage(Message{Timestamp: "1704139200.000000", Thread: []Message{{Timestamp: "1704139260.000000"}}}, loc)
+	if got := msg.Timestamp.Format("2006-01-02 15:04"); got != "2024-01-02 05:00" {
+		t.Errorf("Expected Tokyo time, got %s", got)
+	}
+	if got := msg.Replies[0].Timestamp.Location(); got != loc {
+		t.Errorf("Expected replies in Tokyo time, got %v", got)
+	}
+	if OrLocal(nil) != time.Local {
+		t.Error("Expected the machine's zone without a location")
+	}
+}